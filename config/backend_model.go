@@ -0,0 +1,99 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/corestoreio/errors"
+)
+
+// BackendModel gets invoked when the value of a specific route gets written,
+// like the backend_model of a system.xml field. For example a BackendModel
+// calculates and writes the cron expression of a schedule or clears related
+// caches. See Service.RegisterBackendModel.
+type BackendModel interface {
+	// BeforeSave gets called before the value gets written. It can validate
+	// and transform the value and must always return the value or an error.
+	BeforeSave(p Path, value []byte) (newValue []byte, err error)
+	// AfterSave gets called after the value has been written, within the
+	// same write transaction if the Storager implements TxStorager. The
+	// Setter writes further values within that transaction, bypassing the
+	// observers. An error rolls back the transaction.
+	AfterSave(tx Setter, p Path, value []byte) error
+}
+
+// TxStorager is an optional interface of a Storager which writes values within
+// a transaction. Used to run a BackendModel atomically with the write of the
+// value.
+type TxStorager interface {
+	// InTx runs fn within a new write transaction. The Setter argument writes
+	// within the transaction. If fn returns an error, the transaction gets
+	// rolled back.
+	InTx(fn func(tx Setter) error) error
+}
+
+// RegisterBackendModel sets the BackendModel for a route, e.g.
+// "crontab/default/jobs/sitemap_generate/schedule/time". A route can have only
+// one BackendModel, which gets called for writes in all scopes.
+func (s *Service) RegisterBackendModel(route string, bm BackendModel) error {
+	if _, err := MakePath(route); err != nil {
+		return errors.Wrapf(err, "[config] Service.RegisterBackendModel invalid route %q", route)
+	}
+	if bm == nil {
+		return errors.Empty.Newf("[config] Service.RegisterBackendModel BackendModel for route %q cannot be nil", route)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.backendModels[route]; ok {
+		return errors.AlreadyExists.Newf("[config] Service.RegisterBackendModel route %q already has a BackendModel", route)
+	}
+	if s.backendModels == nil {
+		s.backendModels = make(map[string]BackendModel)
+	}
+	s.backendModels[route] = bm
+	return nil
+}
+
+// DeregisterBackendModel removes the BackendModel of a route.
+func (s *Service) DeregisterBackendModel(route string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.backendModels, route)
+}
+
+// setWithBackendModel writes the value to level2 and runs the BackendModel of
+// the route, if any.
+func (s *Service) setWithBackendModel(p Path, v []byte) ([]byte, error) {
+	bm, ok := s.backendModels[string(p.route)]
+	if !ok {
+		return v, errors.WithStack(s.level2.Set(p, v))
+	}
+	v, err := bm.BeforeSave(p, v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[config] BackendModel.BeforeSave for path %q", p.String())
+	}
+	save := func(tx Setter) error {
+		if err := tx.Set(p, v); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := bm.AfterSave(tx, p, v); err != nil {
+			return errors.Wrapf(err, "[config] BackendModel.AfterSave for path %q", p.String())
+		}
+		return nil
+	}
+	if txs, ok := s.level2.(TxStorager); ok {
+		return v, errors.WithStack(txs.InTx(save))
+	}
+	return v, save(s.level2)
+}
@@ -0,0 +1,130 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/config/storage"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+// txStorage applies the writes of InTx only if the function succeeds.
+type txStorage struct {
+	config.Storager
+	txCount int
+}
+
+type txWrite struct {
+	p config.Path
+	v []byte
+}
+
+type txWrites []txWrite
+
+func (w *txWrites) Set(p config.Path, v []byte) error {
+	*w = append(*w, txWrite{p: p, v: v})
+	return nil
+}
+
+func (ts *txStorage) InTx(fn func(tx config.Setter) error) error {
+	ts.txCount++
+	var writes txWrites
+	if err := fn(&writes); err != nil {
+		return err
+	}
+	for _, w := range writes {
+		if err := ts.Storager.Set(w.p, w.v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cronBackendModel writes the cron expression derived from the time value.
+type cronBackendModel struct {
+	afterSaveErr error
+}
+
+func (cronBackendModel) BeforeSave(_ config.Path, value []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, errors.Empty.Newf("time cannot be empty")
+	}
+	return bytes.Replace(value, []byte(","), []byte(":"), 1), nil
+}
+
+func (bm cronBackendModel) AfterSave(tx config.Setter, p config.Path, value []byte) error {
+	if bm.afterSaveErr != nil {
+		return bm.afterSaveErr
+	}
+	hm := bytes.Split(value, []byte(":"))
+	return tx.Set(config.MustMakePathWithScope(p.ScopeID, "crontab/default/cron_expr"), []byte(string(hm[1])+" "+string(hm[0])+" * * *"))
+}
+
+func getStr(t *testing.T, srv *config.Service, p config.Path) string {
+	s, _, err := srv.Get(p).Str()
+	assert.NoError(t, err)
+	return s
+}
+
+func TestService_RegisterBackendModel(t *testing.T) {
+	const route = "sitemap/generate/time"
+	pTime := config.MustMakePath(route).BindWebsite(2)
+	pCron := config.MustMakePath("crontab/default/cron_expr").BindWebsite(2)
+
+	t.Run("invalid", func(t *testing.T) {
+		srv := config.MustNewService(storage.NewMap(), config.Options{})
+		assert.Error(t, srv.RegisterBackendModel("sitemap", cronBackendModel{}))
+		assert.ErrorIsKind(t, errors.Empty, srv.RegisterBackendModel(route, nil))
+		assert.NoError(t, srv.RegisterBackendModel(route, cronBackendModel{}))
+		assert.ErrorIsKind(t, errors.AlreadyExists, srv.RegisterBackendModel(route, cronBackendModel{}))
+	})
+
+	t.Run("within transaction", func(t *testing.T) {
+		ts := &txStorage{Storager: storage.NewMap()}
+		srv := config.MustNewService(ts, config.Options{})
+		assert.NoError(t, srv.RegisterBackendModel(route, cronBackendModel{}))
+
+		assert.NoError(t, srv.Set(pTime, []byte("03,15")))
+		assert.Exactly(t, 1, ts.txCount)
+		assert.Exactly(t, "03:15", getStr(t, srv, pTime))
+		assert.Exactly(t, "15 03 * * *", getStr(t, srv, pCron))
+
+		err := srv.Set(pTime, nil)
+		assert.ErrorIsKind(t, errors.Empty, err)
+		assert.Exactly(t, 1, ts.txCount, "BeforeSave error must not start a transaction")
+
+		srv.DeregisterBackendModel(route)
+		assert.NoError(t, srv.RegisterBackendModel(route, cronBackendModel{afterSaveErr: errors.Fatal.Newf("cache unavailable")}))
+		err = srv.Set(pTime, []byte("04,30"))
+		assert.ErrorIsKind(t, errors.Fatal, err)
+		assert.Exactly(t, "03:15", getStr(t, srv, pTime), "transaction must be rolled back")
+	})
+
+	t.Run("without transaction", func(t *testing.T) {
+		srv := config.MustNewService(storage.NewMap(), config.Options{})
+		assert.NoError(t, srv.RegisterBackendModel(route, cronBackendModel{}))
+		assert.NoError(t, srv.Set(pTime, []byte("23,05")))
+		assert.Exactly(t, "05 23 * * *", getStr(t, srv, pCron))
+
+		// other routes are not affected
+		pOther := config.MustMakePath("sitemap/generate/enabled")
+		assert.NoError(t, srv.Set(pOther, []byte("1")))
+		assert.Exactly(t, "1", getStr(t, srv, pOther))
+	})
+}
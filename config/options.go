@@ -16,6 +16,7 @@ package config
 
 import (
 	"os"
+	"time"
 	"unicode"
 
 	"github.com/corestoreio/errors"
@@ -61,6 +62,15 @@ type Options struct {
 	// HotReloadSignals specifies custom signals to listen to. Defaults to
 	// syscall.SIGUSR2
 	HotReloadSignals []os.Signal
+
+	// Versioner if set, gets queried every VersionPollInterval. Once the
+	// version changes, because another process has written a value, the Level1
+	// cache gets flushed. Guarantees consistency across several nodes without a
+	// message broker.
+	Versioner Versioner
+	// VersionPollInterval defines the duration between two queries of the
+	// Versioner. Zero disables the polling.
+	VersionPollInterval time.Duration
 }
 
 // LoadDataOption allows other storage backends to pump their data into the
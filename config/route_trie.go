@@ -218,6 +218,30 @@ func (trie *trieRoute) process(key string, event uint8, p Path, v []byte, found
 	return v, found, nil
 }
 
+// lookupDefault returns the default value which process applies for the
+// EventOnAfterGet to a not found value, without dispatching the observers.
+func (trie *trieRoute) lookupDefault(key string, p Path) (v []byte, found bool) {
+	if trie == nil {
+		return nil, false
+	}
+	node := trie
+	for part, i := segmentRoute(key, 0); ; part, i = segmentRoute(key, i) {
+		node = node.children[part]
+		if node == nil {
+			return v, found
+		}
+		if node.fm.valid && (len(node.children) == 0 || p.ScopeID == 0 || p.ScopeID == scope.DefaultTypeID) &&
+			!found && node.fm.DefaultValid {
+			v = []byte(node.fm.Default)
+			found = true
+		}
+		if i == -1 {
+			break
+		}
+	}
+	return v, found
+}
+
 func trieGetNode(node *trieRoute, key string, scp scope.TypeID) *trieRoute {
 	key = buildTrieKey(key, scp)
 	for part, i := segmentRoute(key, 0); ; part, i = segmentRoute(key, i) {
@@ -84,12 +84,19 @@ type Service struct {
 	hotReloadSignal chan os.Signal
 	loadDataFns     loadDataOptions
 	envReplacer     *strings.Replacer
+	// version last seen version of Options.Versioner. Must be accessed
+	// atomically.
+	version         uint64
+	versionPollStop chan struct{}
 
 	// more events can be added once needed.
 	mu sync.RWMutex
 	// routeConfig contains essential information about a route like scope for
 	// permission, default value or events.
 	routeConfig *trieRoute
+	// backendModels maps a route to its BackendModel. See
+	// RegisterBackendModel.
+	backendModels map[string]BackendModel
 }
 
 // NewService creates the main new configuration for all scopes: default,
@@ -131,6 +138,10 @@ func NewService(level2 Storager, o Options, fns ...LoadDataOption) (s *Service,
 		return nil, errors.WithStack(err)
 	}
 
+	if err := s.shouldEnableVersionPolling(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	return s, nil
 }
 
@@ -236,6 +247,10 @@ func (s *Service) Close() error {
 		close(s.hotReloadSignal)
 	}
 
+	if s.versionPollStop != nil {
+		close(s.versionPollStop)
+	}
+
 	if s.config.EnablePubSub {
 		if err := s.pubSub.Close(); err != nil {
 			return errors.WithStack(err)
@@ -344,9 +359,11 @@ func (s *Service) Set(p Path, v []byte) (err error) { // TODO v should be an imm
 		s.mu.RUnlock()
 	}()
 
-	if err := s.level2.Set(p, v); err != nil {
+	nv, err := s.setWithBackendModel(p, v)
+	if err != nil {
 		return errors.Wrap(err, "[config] Service.level2.Set")
 	}
+	v = nv
 	if s.pubSub != nil {
 		s.pubSub.sendMsg(p)
 	}
@@ -0,0 +1,169 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/store/scope"
+)
+
+// StorageLayerer can be implemented by a Storager which wraps several
+// backends, for example storage.MakeMulti. Service.Explain queries each
+// backend separately to report which one provides a value.
+type StorageLayerer interface {
+	// StorageLayers returns the wrapped backends in the order of querying.
+	StorageLayers() []Storager
+}
+
+// ExplainLayer describes one storage layer consulted by Service.Explain.
+type ExplainLayer struct {
+	ScopeID scope.TypeID
+	// Source names the layer, for example Level1, Level2 or Default. Backends
+	// of a StorageLayerer are listed as Level2[index] with their type.
+	Source string
+	// Data contains the raw value of the layer. Observers of the
+	// EventOnAfterGet, for example a decryption, are not applied.
+	Data  []byte
+	Found bool
+	Err   error
+	// Won is true for the first layer providing a value, which Scoped.Get
+	// returns.
+	Won bool
+}
+
+// Explanation lists all layers consulted for a route in the order of the
+// scope hierarchy: store, website and default scope. Within a scope Level1 gets
+// consulted before Level2, followed by the default value of the route as
+// defined in the Sections or FieldMeta.
+type Explanation struct {
+	Route  string
+	Layers []ExplainLayer
+}
+
+// Winner returns the layer which provides the value.
+func (e Explanation) Winner() (ExplainLayer, bool) {
+	for _, l := range e.Layers {
+		if l.Won {
+			return l, true
+		}
+	}
+	return ExplainLayer{}, false
+}
+
+// String renders the explanation as a human readable table.
+func (e Explanation) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "route %q\n", e.Route)
+	for _, l := range e.Layers {
+		mark := " "
+		if l.Won {
+			mark = "*"
+		}
+		switch {
+		case l.Err != nil:
+			fmt.Fprintf(&buf, "%s %-12s %-28s error: %s\n", mark, l.ScopeID.String(), l.Source, l.Err)
+		case l.Found:
+			fmt.Fprintf(&buf, "%s %-12s %-28s %q\n", mark, l.ScopeID.String(), l.Source, l.Data)
+		default:
+			fmt.Fprintf(&buf, "%s %-12s %-28s not found\n", mark, l.ScopeID.String(), l.Source)
+		}
+	}
+	return buf.String()
+}
+
+// Explain returns the chain of layers consulted to look up the route of a
+// path for a website and store ID, with the raw value of each layer and the
+// layer which wins. The scope of the path gets ignored. A path with
+// environment awareness, see Path.WithEnvSuffix, gets looked up with the
+// environment name of the Service like Service.Get does. Explain bypasses the
+// observers and does not populate the Level1 cache. Useful when debugging why
+// a store shows the wrong setting.
+//		fmt.Print(srv.Explain(config.MustMakePath("general/locale/timezone"), 1, 2))
+func (s *Service) Explain(p Path, websiteID, storeID uint32) (Explanation, error) {
+	ss := makeScoped(s, websiteID, storeID)
+	if !ss.IsValid() {
+		return Explanation{}, errors.NotValid.Newf("[config] Service.Explain invalid website ID %d and store ID %d", websiteID, storeID)
+	}
+	if p.UseEnvSuffix && p.envSuffix != s.envName {
+		p.envSuffix = s.envName
+	}
+	p.ScopeID = scope.DefaultTypeID
+	if err := p.IsValid(); err != nil {
+		return Explanation{}, errors.WithStack(err)
+	}
+
+	var scopes scope.TypeIDs
+	if ss.isAllowedStore(scope.Absent) {
+		scopes = append(scopes, scope.Store.WithID(storeID))
+	}
+	if ss.isAllowedWebsite(scope.Absent) {
+		scopes = append(scopes, scope.Website.WithID(websiteID))
+	}
+	scopes = append(scopes, scope.DefaultTypeID)
+
+	var e Explanation
+	_, e.Route = p.ScopeRoute()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, scp := range scopes {
+		p.ScopeID = scp
+		if s.config.Level1 != nil {
+			e.Layers = append(e.Layers, explainStorage(p, "Level1", s.config.Level1))
+		}
+		if sl, ok := s.level2.(StorageLayerer); ok {
+			for idx, st := range sl.StorageLayers() {
+				e.Layers = append(e.Layers, explainStorage(p, fmt.Sprintf("Level2[%d] %T", idx, st), st))
+			}
+		} else {
+			e.Layers = append(e.Layers, explainStorage(p, "Level2", s.level2))
+		}
+		// Service.Get applies the default value already in the store or
+		// website scope, if the route has no scope specific default values.
+		v, found := s.routeConfig.lookupDefault(buildTrieKey(p.separatorSuffixRoute(), scp), p)
+		if found || scp == scope.DefaultTypeID {
+			e.Layers = append(e.Layers, ExplainLayer{
+				ScopeID: scp,
+				Source:  "Default",
+				Data:    v,
+				Found:   found,
+			})
+		}
+	}
+
+	for i, l := range e.Layers {
+		if l.Err != nil {
+			break // Scoped.Get stops at the first error
+		}
+		if l.Found {
+			e.Layers[i].Won = true
+			break
+		}
+	}
+	return e, nil
+}
+
+func explainStorage(p Path, source string, st Storager) ExplainLayer {
+	v, found, err := st.Get(p)
+	return ExplainLayer{
+		ScopeID: p.ScopeID,
+		Source:  source,
+		Data:    v,
+		Found:   found,
+		Err:     errors.WithStack(err),
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/config/storage"
+	"github.com/corestoreio/pkg/store/scope"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestService_Explain(t *testing.T) {
+	t.Parallel()
+
+	pUser := config.MustMakePath("carrier/dhl/username")
+	pTimeout := config.MustMakePath("carrier/dhl/timeout")
+	srv := config.MustNewService(
+		storage.MakeMulti(storage.MultiOptions{},
+			storage.NewMap(pUser.BindStore(3).String(), "storeUser3"),
+			storage.NewMap(pTimeout.BindWebsite(1).String(), "60s"),
+		),
+		config.Options{},
+		config.WithFieldMeta(
+			&config.FieldMeta{Route: "carrier/dhl/username", Default: "prdUser0"},
+			&config.FieldMeta{Route: "carrier/dhl/username", ScopeID: scope.Website.WithID(1), Default: "prdUser1"},
+			&config.FieldMeta{Route: "carrier/dhl/timeout", Default: "3600s"},
+		),
+	)
+
+	type layer struct {
+		scp    scope.TypeID
+		source string
+		data   string
+		won    bool
+	}
+	assertExplain := func(t *testing.T, route string, websiteID, storeID uint32, want []layer) {
+		e, err := srv.Explain(config.MustMakePath(route), websiteID, storeID)
+		assert.NoError(t, err)
+		var have []layer
+		for _, l := range e.Layers {
+			assert.NoError(t, l.Err)
+			have = append(have, layer{scp: l.ScopeID, source: l.Source, data: string(l.Data), won: l.Won})
+		}
+		assert.Exactly(t, want, have, e.String())
+
+		winner, ok := e.Winner()
+		assert.True(t, ok)
+		v, ok, err := srv.Scoped(websiteID, storeID).Get(scope.Absent, route).Str()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Exactly(t, v, string(winner.Data), "Explain must match Scoped.Get")
+	}
+
+	const l2a, l2b = "Level2[0] *storage.kvmap", "Level2[1] *storage.kvmap"
+	t.Run("store value wins", func(t *testing.T) {
+		assertExplain(t, "carrier/dhl/username", 1, 3, []layer{
+			{scope.Store.WithID(3), l2a, "storeUser3", true},
+			{scope.Store.WithID(3), l2b, "", false},
+			{scope.Website.WithID(1), l2a, "", false},
+			{scope.Website.WithID(1), l2b, "", false},
+			{scope.Website.WithID(1), "Default", "prdUser1", false},
+			{scope.DefaultTypeID, l2a, "", false},
+			{scope.DefaultTypeID, l2b, "", false},
+			{scope.DefaultTypeID, "Default", "prdUser0", false},
+		})
+	})
+	t.Run("website default wins", func(t *testing.T) {
+		assertExplain(t, "carrier/dhl/username", 1, 2, []layer{
+			{scope.Store.WithID(2), l2a, "", false},
+			{scope.Store.WithID(2), l2b, "", false},
+			{scope.Website.WithID(1), l2a, "", false},
+			{scope.Website.WithID(1), l2b, "", false},
+			{scope.Website.WithID(1), "Default", "prdUser1", true},
+			{scope.DefaultTypeID, l2a, "", false},
+			{scope.DefaultTypeID, l2b, "", false},
+			{scope.DefaultTypeID, "Default", "prdUser0", false},
+		})
+	})
+	t.Run("default scope", func(t *testing.T) {
+		assertExplain(t, "carrier/dhl/username", 0, 0, []layer{
+			{scope.DefaultTypeID, l2a, "", false},
+			{scope.DefaultTypeID, l2b, "", false},
+			{scope.DefaultTypeID, "Default", "prdUser0", true},
+		})
+	})
+	t.Run("route default shadows website value", func(t *testing.T) {
+		assertExplain(t, "carrier/dhl/timeout", 1, 2, []layer{
+			{scope.Store.WithID(2), l2a, "", false},
+			{scope.Store.WithID(2), l2b, "", false},
+			{scope.Store.WithID(2), "Default", "3600s", true},
+			{scope.Website.WithID(1), l2a, "", false},
+			{scope.Website.WithID(1), l2b, "60s", false},
+			{scope.Website.WithID(1), "Default", "3600s", false},
+			{scope.DefaultTypeID, l2a, "", false},
+			{scope.DefaultTypeID, l2b, "", false},
+			{scope.DefaultTypeID, "Default", "3600s", false},
+		})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := srv.Explain(pUser, 0, 2)
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		_, err = srv.Explain(config.Path{}, 1, 2)
+		assert.Error(t, err)
+	})
+}
+
+func TestService_Explain_EnvSuffix(t *testing.T) {
+	t.Parallel()
+
+	srv := config.MustNewService(
+		storage.NewMap(
+			"default/0/carrier/dhl/password", "secret",
+			"default/0/carrier/dhl/password/PRD", "secretPRD",
+		),
+		config.Options{EnvName: "PRD"},
+	)
+	p := config.MustMakePath("carrier/dhl/password").WithEnvSuffix()
+
+	e, err := srv.Explain(p, 0, 0)
+	assert.NoError(t, err)
+	assert.Exactly(t, "carrier/dhl/password/PRD", e.Route)
+	winner, ok := e.Winner()
+	assert.True(t, ok)
+	assert.Exactly(t, "secretPRD", string(winner.Data), e.String())
+
+	v, ok, err := srv.Get(p).Str()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Exactly(t, v, string(winner.Data), "Explain must match Service.Get")
+}
@@ -532,6 +532,42 @@ func TestHotReload(t *testing.T) {
 	assert.Exactly(t, `"3601s"`, srv.Get(pTimeout).String())
 }
 
+type versioner struct {
+	version uint64
+}
+
+func (v *versioner) Version() (uint64, error) {
+	return atomic.LoadUint64(&v.version), nil
+}
+
+func TestService_VersionPolling(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	level2 := storage.NewMap()
+	vs := &versioner{version: 3}
+	// hides the Flush method of the map because a shared Level2 must keep its data
+	srv := config.MustNewService(struct{ config.Storager }{level2}, config.Options{
+		Level1:              storage.NewLRU(5),
+		Versioner:           vs,
+		VersionPollInterval: time.Millisecond,
+	})
+	defer func() { assert.NoError(t, srv.Close()) }()
+	assert.Exactly(t, uint64(3), srv.Version())
+
+	p := config.MustMakePath("carrier/dhl/enabled")
+	assert.NoError(t, srv.Set(p, []byte(`1`)))
+	assert.Exactly(t, `"1"`, srv.Get(p).String()) // fills Level1
+
+	// another process writes into the shared Level2
+	assert.NoError(t, level2.Set(p, []byte(`0`)))
+	assert.Exactly(t, `"1"`, srv.Get(p).String(), "Should return the value from Level1")
+
+	atomic.AddUint64(&vs.version, 1)
+	time.Sleep(time.Millisecond * 20)
+	assert.Exactly(t, uint64(4), srv.Version())
+	assert.Exactly(t, `"0"`, srv.Get(p).String(), "Level1 should have been flushed")
+}
+
 type keyer interface {
 	Keys(ret ...string) []string
 }
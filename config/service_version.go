@@ -0,0 +1,83 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// Versioner returns the version of the configuration data shared between
+// several processes or nodes, for example a row in a database table which gets
+// incremented on each write. Type storage.DB implements this interface with
+// the table `config_version`.
+type Versioner interface {
+	Version() (uint64, error)
+}
+
+// Version returns the last seen version of the Versioner. Returns zero if no
+// Versioner has been configured.
+func (s *Service) Version() uint64 {
+	return atomic.LoadUint64(&s.version)
+}
+
+func (s *Service) shouldEnableVersionPolling() error {
+	if s.config.Versioner == nil || s.config.VersionPollInterval <= 0 {
+		return nil
+	}
+
+	v, err := s.config.Versioner.Version()
+	if err != nil {
+		return errors.Wrap(err, "[config] Service.Versioner.Version")
+	}
+	atomic.StoreUint64(&s.version, v)
+
+	s.versionPollStop = make(chan struct{})
+	ticker := time.NewTicker(s.config.VersionPollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.versionPollStop:
+				return
+			case <-ticker.C:
+				if err := s.checkVersion(); err != nil && s.config.Log != nil && s.config.Log.IsInfo() {
+					s.config.Log.Info("config.Service.VersionPolling.Error", log.Err(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// checkVersion queries the Versioner and flushes the caches via Service.Flush
+// if another process has changed the version.
+func (s *Service) checkVersion() error {
+	v, err := s.config.Versioner.Version()
+	if err != nil {
+		return errors.Wrap(err, "[config] Service.Versioner.Version")
+	}
+	prev := atomic.SwapUint64(&s.version, v)
+	if prev == v {
+		return nil
+	}
+	if s.config.Log != nil && s.config.Log.IsDebug() {
+		s.config.Log.Debug("config.Service.VersionPolling.Flush", log.Uint64("previous_version", prev), log.Uint64("version", v))
+	}
+	return errors.WithStack(s.Flush())
+}
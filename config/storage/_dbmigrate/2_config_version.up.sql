@@ -0,0 +1,6 @@
+CREATE TABLE `config_version` (
+  `id` tinyint(3) UNSIGNED NOT NULL COMMENT 'ID, always 1',
+  `version` bigint(20) UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Incremented on each write to core_configuration',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Config Version';
+INSERT INTO `config_version` (`id`, `version`) VALUES (1, 0);
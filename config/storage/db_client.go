@@ -33,6 +33,10 @@ import (
 
 // TODO https://mariadb.com/kb/en/library/system-versioned-tables/
 
+// TableNameConfigVersion default name of the table which contains the single
+// version row. See DBOptions.VersionTableName.
+const TableNameConfigVersion = "config_version"
+
 // DBOptions applies options to the `DB` type.
 type DBOptions struct {
 	// TableName if set, specifies the alternate table name, default:
@@ -49,6 +53,12 @@ type DBOptions struct {
 	// SkipSchemaValidation disables the validation of the DB schema compared
 	// with the schema stored in Go source files.
 	SkipSchemaValidation bool
+	// VersionTableName if set, enables the version row for the cross process
+	// cache invalidation. Each Set increments the version in this table, see
+	// constant TableNameConfigVersion and the migration in directory
+	// _dbmigrate. Other processes poll the version via DB.Version, configured
+	// as config.Options.Versioner, and flush their caches once it changes.
+	VersionTableName string
 	// TODO implement UseDedicatedDBConnection per prepared statement, bit complicated
 	// UseDedicatedDBConnection *sql.DB
 }
@@ -67,10 +77,15 @@ type dbStats struct {
 // Service connects the MySQL/MariaDB with the config.Service type. Implements
 // interface config.Storager.
 type DB struct {
-	cfg DBOptions
+	cfg  DBOptions
+	tbls *ddl.Tables
 
 	sqlRead  *dml.Select
 	sqlWrite *dml.Insert
+	// sqlVersionBump and sqlVersion are nil if no version table has been
+	// configured.
+	sqlVersionBump *dml.DBR
+	sqlVersion     *dml.DBR
 
 	tickerDaemonStop chan struct{}
 	tickerRead       *time.Ticker
@@ -141,10 +156,15 @@ func NewDB(tbls *ddl.Tables, o DBOptions) (*DB, error) {
 
 	dbs := &DB{
 		cfg:              o,
+		tbls:             tbls,
 		tickerDaemonStop: make(chan struct{}),
 		sqlRead:          qryRead,
 		sqlWrite:         qryWrite,
 	}
+	if vt := o.VersionTableName; vt != "" {
+		dbs.sqlVersionBump = tbls.WithRawSQL("INSERT INTO " + dml.Quoter.Name(vt) + " (`id`, `version`) VALUES (1, 1) ON DUPLICATE KEY UPDATE `version` = `version` + 1")
+		dbs.sqlVersion = tbls.WithRawSQL("SELECT `version` FROM " + dml.Quoter.Name(vt) + " WHERE `id` = 1")
+	}
 	if dbs.cfg.IdleRead == 0 {
 		dbs.cfg.IdleRead = time.Second * 20 // just a guess
 	}
@@ -269,8 +289,64 @@ func (dbs *DB) Set(p config.Path, value []byte) error {
 			log.Int("value_len", len(value)),
 		)
 	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-	return err
+	if dbs.sqlVersionBump != nil {
+		if _, err := dbs.sqlVersionBump.ExecContext(ctx); err != nil {
+			return errors.Wrapf(err, "[config/storage] DB.Set failed to increment the version in table %q", dbs.cfg.VersionTableName)
+		}
+	}
+	return nil
+}
+
+// InTx runs fn within a new transaction. The Setter argument writes values
+// within the transaction and increments the version, if configured. Implements
+// interface config.TxStorager.
+func (dbs *DB) InTx(fn func(tx config.Setter) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbs.cfg.ContextTimeoutWrite)
+	defer cancel()
+	return errors.WithStack(dbs.tbls.Transaction(ctx, nil, func(tx *dml.Tx) error {
+		return fn(dbTxSetter{ctx: ctx, dbs: dbs, tx: tx})
+	}))
+}
+
+type dbTxSetter struct {
+	ctx context.Context
+	dbs *DB
+	tx  *dml.Tx
+}
+
+func (s dbTxSetter) Set(p config.Path, value []byte) error {
+	scp, path := p.ScopeRoute()
+	st, id := scp.Unpack()
+	if _, err := s.tx.WithQueryBuilder(s.dbs.sqlWrite).ExecContext(s.ctx, st.StrType(), id, path, value); err != nil {
+		return errors.Wrapf(err, "[config/storage] DB.InTx failed to write path %q", p.String())
+	}
+	if s.dbs.sqlVersionBump != nil {
+		if _, err := s.dbs.sqlVersionBump.Clone().WithTx(s.tx).ExecContext(s.ctx); err != nil {
+			return errors.Wrapf(err, "[config/storage] DB.InTx failed to increment the version in table %q", s.dbs.cfg.VersionTableName)
+		}
+	}
+	return nil
+}
+
+// Version returns the current version of the configuration data. The version
+// gets incremented by any process which writes a value. Returns zero if the
+// row has not yet been created. Implements interface config.Versioner. Returns
+// an error of kind NotSupported if DBOptions.VersionTableName is empty.
+func (dbs *DB) Version() (uint64, error) {
+	if dbs.sqlVersion == nil {
+		return 0, errors.NotSupported.Newf("[config/storage] DB.Version requires the option VersionTableName")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dbs.cfg.ContextTimeoutRead)
+	defer cancel()
+	nv, _, err := dbs.sqlVersion.LoadNullUint64(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "[config/storage] DB.Version for table %q", dbs.cfg.VersionTableName)
+	}
+	return nv.Uint64, nil
 }
 
 // Get performs a read operation from the database and returns a value from
@@ -303,6 +303,51 @@ func TestService_Set(t *testing.T) {
 	})
 }
 
+func TestDB_Version(t *testing.T) {
+	defer leaktest.CheckTimeout(t, time.Second)()
+
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS").WithArgs().WillReturnRows(
+		dmltest.MustMockRows(dmltest.WithFile("testdata", "core_configuration_columns.csv")),
+	)
+
+	dbs, err := storage.NewDB(mustNewTables(context.TODO(), ddl.WithConnPool(dbc)), storage.DBOptions{
+		SkipSchemaValidation: true,
+		VersionTableName:     storage.TableNameConfigVersion,
+	})
+	assert.NoError(t, err)
+	defer dmltest.Close(t, dbs)
+
+	dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("INSERT INTO `core_configuration` (`scope`,`scope_id`,`path`,`value`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `value`=VALUES(`value`)")).
+		ExpectExec().WithArgs(scope.DefaultTypeID, "aa/bb/cc", []byte("1")).WillReturnResult(sqlmock.NewResult(1, 1))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `config_version` (`id`, `version`) VALUES (1, 1) ON DUPLICATE KEY UPDATE `version` = `version` + 1")).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, dbs.Set(config.MustMakePath("aa/bb/cc"), []byte("1")))
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `version` FROM `config_version` WHERE `id` = 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(8))
+	v, err := dbs.Version()
+	assert.NoError(t, err)
+	assert.Exactly(t, uint64(8), v)
+
+	t.Run("not configured", func(t *testing.T) {
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS").WithArgs().WillReturnRows(
+			dmltest.MustMockRows(dmltest.WithFile("testdata", "core_configuration_columns.csv")),
+		)
+		dbs, err := storage.NewDB(mustNewTables(context.TODO(), ddl.WithConnPool(dbc)), storage.DBOptions{
+			SkipSchemaValidation: true,
+		})
+		assert.NoError(t, err)
+		defer dmltest.Close(t, dbs)
+
+		v, err := dbs.Version()
+		assert.Exactly(t, uint64(0), v)
+		assert.True(t, errors.NotSupported.Match(err), "%+v", err)
+	})
+}
+
 // Test_WithApplyCoreConfigData reads from the MySQL core_configuration table and applies
 // these value to the underlying storage. tries to get back the values from the
 // underlying storage
@@ -111,3 +111,9 @@ func (ms *multi) Get(p config.Path) (v []byte, found bool, err error) {
 	}
 	return nil, false, nil
 }
+
+// StorageLayers returns the wrapped backends. Implements
+// config.StorageLayerer.
+func (ms *multi) StorageLayers() []config.Storager {
+	return append([]config.Storager(nil), ms.backends...)
+}
@@ -26,7 +26,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/corestoreio/errors"
-	"github.com/corestoreio/pkg/util/byteconv"
+	"github.com/corestoreio/pkg/util/conv"
 )
 
 // CSVColumnSeparator separates CSV values. Default value.
@@ -231,7 +231,7 @@ func (v *Value) Bool() (val bool, ok bool, err error) {
 	if ok, err = v.init(); err != nil || !ok {
 		return false, false, errors.WithStack(err)
 	}
-	return byteconv.ParseBool(v.data)
+	return conv.ParseBool(v.data)
 }
 
 // UnsafeFloat64 same as Float64 but ignores errors.
@@ -245,7 +245,7 @@ func (v *Value) Float64() (val float64, ok bool, err error) {
 	if ok, err = v.init(); err != nil || !ok {
 		return 0, false, errors.WithStack(err)
 	}
-	return byteconv.ParseFloat(v.data)
+	return conv.ParseFloat64(v.data)
 }
 
 func (v *Value) countSep() (n int, sep []byte) {
@@ -276,7 +276,7 @@ func (v *Value) Float64s(ret ...float64) (_ []float64, err error) {
 			break
 		}
 
-		if f64, ok, err := byteconv.ParseFloat(s[:m]); err != nil {
+		if f64, ok, err := conv.ParseFloat64(s[:m]); err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Float64s with index %d and entry %q", i, s[:m])
 		} else if ok {
 			ret = append(ret, f64)
@@ -285,7 +285,7 @@ func (v *Value) Float64s(ret ...float64) (_ []float64, err error) {
 		i++
 	}
 
-	if f64, ok, err := byteconv.ParseFloat(s); err != nil {
+	if f64, ok, err := conv.ParseFloat64(s); err != nil {
 		return nil, errors.Wrapf(err, "[config] Value.Float64s with index %d and entry %q", i, s)
 	} else if ok {
 		ret = append(ret, f64)
@@ -305,7 +305,7 @@ func (v *Value) Int() (val int, ok bool, err error) {
 	if ok, err = v.init(); err != nil || !ok {
 		return 0, false, errors.WithStack(err)
 	}
-	i64, ok, err := byteconv.ParseInt(v.data)
+	i64, ok, err := conv.ParseInt64(v.data)
 	return int(i64), ok, err
 }
 
@@ -330,7 +330,7 @@ func (v *Value) Ints(ret ...int) (_ []int, err error) {
 			break
 		}
 
-		if i64, ok, err := byteconv.ParseInt(s[:m]); err != nil {
+		if i64, ok, err := conv.ParseInt64(s[:m]); err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Ints with index %d and entry %q", i, s[:m])
 		} else if ok {
 			ret = append(ret, int(i64))
@@ -339,7 +339,7 @@ func (v *Value) Ints(ret ...int) (_ []int, err error) {
 		i++
 	}
 
-	if i64, ok, err := byteconv.ParseInt(s); err != nil {
+	if i64, ok, err := conv.ParseInt64(s); err != nil {
 		return nil, errors.Wrapf(err, "[config] Value.Ints with index %d and entry %q", i, s)
 	} else if ok {
 		ret = append(ret, int(i64))
@@ -359,7 +359,7 @@ func (v *Value) Int64() (_ int64, ok bool, err error) {
 	if ok, err = v.init(); err != nil || !ok {
 		return 0, false, errors.WithStack(err)
 	}
-	return byteconv.ParseInt(v.data)
+	return conv.ParseInt64(v.data)
 }
 
 // Int64s converts the underlying byte slice into an int64 slice using
@@ -383,7 +383,7 @@ func (v *Value) Int64s(ret ...int64) (_ []int64, err error) {
 			break
 		}
 
-		if i64, ok, err := byteconv.ParseInt(s[:m]); err != nil {
+		if i64, ok, err := conv.ParseInt64(s[:m]); err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Int64s with index %d and entry %q", i, s[:m])
 		} else if ok {
 			ret = append(ret, i64)
@@ -392,7 +392,7 @@ func (v *Value) Int64s(ret ...int64) (_ []int64, err error) {
 		i++
 	}
 
-	if i64, ok, err := byteconv.ParseInt(s); err != nil {
+	if i64, ok, err := conv.ParseInt64(s); err != nil {
 		return nil, errors.Wrapf(err, "[config] Value.Int64s with index %d and entry %q", i, s)
 	} else if ok {
 		ret = append(ret, i64)
@@ -412,7 +412,7 @@ func (v *Value) Uint64() (_ uint64, ok bool, err error) {
 	if ok, err = v.init(); err != nil || !ok {
 		return 0, false, errors.WithStack(err)
 	}
-	return byteconv.ParseUint(v.data, 10, 64)
+	return conv.ParseUint64(v.data)
 }
 
 // Uint64s converts the underlying byte slice into an int64 slice using
@@ -435,7 +435,7 @@ func (v *Value) Uint64s(ret ...uint64) (_ []uint64, err error) {
 		if m < 0 {
 			break
 		}
-		if i64, ok, err := byteconv.ParseUint(s[:m], 10, 64); err != nil {
+		if i64, ok, err := conv.ParseUint64(s[:m]); err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Uint64s with index %d and entry %q", i, s[:m])
 		} else if ok {
 			ret = append(ret, i64)
@@ -444,7 +444,7 @@ func (v *Value) Uint64s(ret ...uint64) (_ []uint64, err error) {
 		i++
 	}
 	if len(s) > 0 {
-		if i64, ok, err := byteconv.ParseUint(s, 10, 64); err != nil {
+		if i64, ok, err := conv.ParseUint64(s); err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Uint64s with index %d and entry %q", i, s)
 		} else if ok {
 			ret = append(ret, i64)
@@ -471,7 +471,7 @@ func (v *Value) Time() (t time.Time, ok bool, err error) {
 	if v.IsEmpty() {
 		return
 	}
-	t, err = parseDateTime(string(v.data), time.UTC)
+	t, _, err = conv.ParseDateTime(string(v.data), time.UTC)
 	ok = err == nil
 	return
 }
@@ -498,7 +498,7 @@ func (v *Value) Times(ret ...time.Time) (t []time.Time, err error) {
 			break
 		}
 		if s[:m] != "" {
-			t, err := parseDateTime(s[:m], time.UTC)
+			t, _, err := conv.ParseDateTime(s[:m], time.UTC)
 			if err != nil {
 				return nil, errors.Wrapf(err, "[config] Value.Times with index %d and entry %q", i, s[:m])
 			}
@@ -508,7 +508,7 @@ func (v *Value) Times(ret ...time.Time) (t []time.Time, err error) {
 		i++
 	}
 	if s != "" {
-		t, err := parseDateTime(s, time.UTC)
+		t, _, err := conv.ParseDateTime(s, time.UTC)
 		if err != nil {
 			return nil, errors.Wrapf(err, "[config] Value.Times with index %d and entry %q", i, s)
 		}
@@ -574,36 +574,6 @@ func (v *Value) Error() string {
 	return v.lastErr.Error()
 }
 
-func parseDateTime(str string, loc *time.Location) (t time.Time, err error) {
-	zeroBase := "0000-00-00 00:00:00.000000000+00:00"
-	base := "2006-01-02 15:04:05.999999999 07:00"
-	if strings.IndexByte(str, 'T') > 0 {
-		base = time.RFC3339Nano
-	}
-
-	switch lStr := len(str); lStr {
-	case 10, 19, 21, 22, 23, 24, 25, 26, 27, 28, 29, 35: // up to "YYYY-MM-DD HH:MM:SS.MMMMMMM+HH:II"
-		if str == zeroBase[:lStr] {
-			return
-		}
-		t, err = time.Parse(base[:lStr], str) // time.RFC3339Nano cannot be used due to the T
-		if err != nil {
-			err = errors.WithStack(err)
-		}
-	default:
-		err = errors.NotValid.Newf("[config] invalid length %d in time string: %q", lStr, str)
-		return
-	}
-
-	// Adjust location
-	if err == nil && loc != time.UTC {
-		y, mo, d := t.Date()
-		h, mi, s := t.Clock()
-		t, err = time.Date(y, mo, d, h, mi, s, t.Nanosecond(), loc), nil
-	}
-	return
-}
-
 // ConstantTimeCompare compares in a constant time manner data with the
 // underlying value retrieved from the config service. Useful for passwords and
 // other hashes.
@@ -0,0 +1,180 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/storage/null"
+)
+
+const selAutoIncrement = `SELECT TABLE_NAME, AUTO_INCREMENT FROM information_schema.TABLES WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME IN ? ORDER BY TABLE_NAME`
+
+// AutoIncrement contains the current auto increment value of a table compared
+// to the maximum value the auto increment column can store.
+type AutoIncrement struct {
+	TableName  string
+	ColumnName string
+	// ColumnType full SQL string of the column type, e.g. int(10) unsigned.
+	ColumnType string
+	// Current contains the next value which gets assigned to a new row.
+	Current uint64
+	// Max contains the largest value of the data type of the column.
+	Max uint64
+}
+
+// Usage returns the used ratio of the auto increment column between 0 and 1.
+func (ai AutoIncrement) Usage() float64 {
+	if ai.Max == 0 {
+		return 0
+	}
+	return float64(ai.Current) / float64(ai.Max)
+}
+
+// Remaining returns the number of IDs left until the column is exhausted.
+func (ai AutoIncrement) Remaining() uint64 {
+	if ai.Current >= ai.Max {
+		return 0
+	}
+	return ai.Max - ai.Current
+}
+
+// autoIncrementMax returns the maximum value for an integer data type.
+func autoIncrementMax(dataType string, unsigned bool) (uint64, bool) {
+	var bits uint
+	switch dataType {
+	case "tinyint":
+		bits = 8
+	case "smallint":
+		bits = 16
+	case "mediumint":
+		bits = 24
+	case "int", "integer":
+		bits = 32
+	case "bigint":
+		bits = 64
+	default:
+		return 0, false
+	}
+	if unsigned {
+		if bits == 64 {
+			return math.MaxUint64, true
+		}
+		return 1<<bits - 1, true
+	}
+	return 1<<(bits-1) - 1, true
+}
+
+// WithAutoIncrementWarning sets a callback which gets called by
+// Tables.AutoIncrementStatus for each table whose auto increment usage is
+// equal or greater than the threshold. The threshold must be between 0 and 1,
+// e.g. 0.8 warns when 80% of the available IDs have been used.
+func WithAutoIncrementWarning(threshold float64, fn func(AutoIncrement)) TableOption {
+	return TableOption{
+		fn: func(tm *Tables) error {
+			if threshold <= 0 || threshold > 1 {
+				return errors.OutOfRange.Newf("[ddl] WithAutoIncrementWarning threshold %.4f must be between 0 and 1", threshold)
+			}
+			tm.mu.Lock()
+			tm.autoIncThreshold = threshold
+			tm.autoIncWarnFn = fn
+			tm.mu.Unlock()
+			return nil
+		},
+	}
+}
+
+// AutoIncrementStatus returns for all registered tables with an auto increment
+// column its current value and the maximum value of the column type. This
+// allows to detect e.g. exhausted int32 primary keys before they cause an
+// outage. The callback of option WithAutoIncrementWarning gets called for each
+// table reaching the threshold. Tables without an auto increment column are
+// skipped. The returned slice is sorted by table name.
+func (tm *Tables) AutoIncrementStatus(ctx context.Context) (ret []AutoIncrement, err error) {
+	tm.mu.RLock()
+	threshold, warnFn := tm.autoIncThreshold, tm.autoIncWarnFn
+	ais := make(map[string]AutoIncrement, len(tm.tm))
+	tblNames := make([]string, 0, len(tm.tm))
+	for tn, tbl := range tm.tm {
+		for _, c := range tbl.Columns {
+			if !c.IsAutoIncrement() {
+				continue
+			}
+			maxVal, ok := autoIncrementMax(c.DataType, c.IsUnsigned())
+			if !ok {
+				continue
+			}
+			ais[tn] = AutoIncrement{
+				TableName:  tn,
+				ColumnName: c.Field,
+				ColumnType: c.ColumnType,
+				Max:        maxVal,
+			}
+			tblNames = append(tblNames, tn)
+			break
+		}
+	}
+	tm.mu.RUnlock()
+
+	if len(tblNames) == 0 {
+		return nil, nil
+	}
+	sort.Strings(tblNames)
+
+	sqlStr, _, err := dml.Interpolate(selAutoIncrement).Strs(tblNames...).ToSQL()
+	if err != nil {
+		return nil, errors.Wrapf(err, "[ddl] AutoIncrementStatus dml.Interpolate for tables %v", tblNames)
+	}
+	rows, err := tm.dcp.DB.QueryContext(ctx, sqlStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[ddl] AutoIncrementStatus QueryContext for tables %v", tblNames)
+	}
+	defer func() {
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = errors.WithStack(err2)
+		}
+	}()
+
+	ret = make([]AutoIncrement, 0, len(tblNames))
+	for rows.Next() {
+		var tn string
+		var current null.Uint64
+		if err = rows.Scan(&tn, &current); err != nil {
+			return nil, errors.Wrapf(err, "[ddl] AutoIncrementStatus Scan for tables %v", tblNames)
+		}
+		ai, ok := ais[tn]
+		if !ok {
+			continue
+		}
+		ai.Current = current.Uint64
+		ret = append(ret, ai)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if warnFn != nil {
+		for _, ai := range ret {
+			if ai.Usage() >= threshold {
+				warnFn(ai)
+			}
+		}
+	}
+	return ret, nil
+}
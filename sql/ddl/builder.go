@@ -0,0 +1,492 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// The builders CreateTable, AlterTable and CreateIndex implement
+// dml.QueryBuilder, so they run through the connection types of package dml
+// including logging, hooks and metrics:
+//		_, err := dbc.WithQueryBuilder(ddl.NewCreateTable("sales_order_log").
+//			AddColumn(
+//				&ddl.Column{Field: "log_id", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+//				&ddl.Column{Field: "message", ColumnType: "varchar(255)", Null: "YES"},
+//			).
+//			PrimaryKey("log_id").
+//			Engine("InnoDB"),
+//		).ExecContext(ctx)
+var (
+	_ dml.QueryBuilder = (*CreateTable)(nil)
+	_ dml.QueryBuilder = (*AlterTable)(nil)
+	_ dml.QueryBuilder = (*CreateIndex)(nil)
+)
+
+// Index types of an index definition.
+const (
+	IndexTypeIndex    = "index"
+	IndexTypeUnique   = "unique"
+	IndexTypeFulltext = "fulltext"
+)
+
+// IndexDef defines an index of a CREATE TABLE or ALTER TABLE statement.
+type IndexDef struct {
+	// Name of the index. If empty, IndexName generates the name.
+	Name string
+	// Type one of the IndexType* constants. Defaults to IndexTypeIndex.
+	Type    string
+	Columns []string
+}
+
+// name validates the index and returns its name.
+func (idx IndexDef) name(tableName string) (string, error) {
+	if len(idx.Columns) == 0 {
+		return "", errors.Empty.Newf("[ddl] Index %q of table %q requires at least one column", idx.Name, tableName)
+	}
+	if err := validateIdentifiers(idx.Columns...); err != nil {
+		return "", errors.WithStack(err)
+	}
+	switch idx.Type {
+	case "", IndexTypeIndex, IndexTypeUnique, IndexTypeFulltext:
+	default:
+		return "", errors.NotSupported.Newf("[ddl] Index type %q of index %q not supported", idx.Type, idx.Name)
+	}
+	if idx.Name == "" {
+		return IndexName(idx.Type, tableName, idx.Columns...), nil
+	}
+	return idx.Name, errors.WithStack(dml.IsValidIdentifier(idx.Name))
+}
+
+// write writes the index definition of CREATE TABLE and ALTER TABLE.
+func (idx IndexDef) write(buf *bytes.Buffer, tableName string) error {
+	name, err := idx.name(tableName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	switch idx.Type {
+	case IndexTypeUnique:
+		buf.WriteString("UNIQUE KEY ")
+	case IndexTypeFulltext:
+		buf.WriteString("FULLTEXT INDEX ")
+	default:
+		buf.WriteString("INDEX ")
+	}
+	dml.Quoter.WriteIdentifier(buf, name)
+	buf.WriteByte(' ')
+	writeIdentifierList(buf, idx.Columns)
+	return nil
+}
+
+// CreateTable builds a CREATE TABLE statement. Create it with NewCreateTable.
+type CreateTable struct {
+	Schema      string
+	Name        string
+	ifNotExists bool
+	temporary   bool
+	columns     Columns
+	primaryKey  []string
+	indexes     []IndexDef
+	options     []string
+}
+
+// NewCreateTable creates a new CREATE TABLE builder.
+func NewCreateTable(name string) *CreateTable {
+	return &CreateTable{Name: name}
+}
+
+// IfNotExists adds IF NOT EXISTS.
+func (ct *CreateTable) IfNotExists() *CreateTable {
+	ct.ifNotExists = true
+	return ct
+}
+
+// Temporary creates a TEMPORARY table.
+func (ct *CreateTable) Temporary() *CreateTable {
+	ct.temporary = true
+	return ct
+}
+
+// AddColumn adds column definitions. Used fields of Column are Field,
+// ColumnType, Null, Default, Extra (auto_increment and on update) and Comment.
+// A string default value gets quoted unless it is numeric or
+// CURRENT_TIMESTAMP.
+func (ct *CreateTable) AddColumn(cols ...*Column) *CreateTable {
+	ct.columns = append(ct.columns, cols...)
+	return ct
+}
+
+// PrimaryKey sets the columns of the primary key.
+func (ct *CreateTable) PrimaryKey(columns ...string) *CreateTable {
+	ct.primaryKey = columns
+	return ct
+}
+
+// AddIndex adds index definitions.
+func (ct *CreateTable) AddIndex(idx ...IndexDef) *CreateTable {
+	ct.indexes = append(ct.indexes, idx...)
+	return ct
+}
+
+// Engine sets the storage engine, e.g. InnoDB.
+func (ct *CreateTable) Engine(engine string) *CreateTable {
+	ct.options = append(ct.options, "ENGINE="+engine)
+	return ct
+}
+
+// Charset sets the default character set, e.g. utf8mb4.
+func (ct *CreateTable) Charset(charset string) *CreateTable {
+	ct.options = append(ct.options, "DEFAULT CHARSET="+charset)
+	return ct
+}
+
+// Collate sets the default collation, e.g. utf8mb4_unicode_ci.
+func (ct *CreateTable) Collate(collation string) *CreateTable {
+	ct.options = append(ct.options, "COLLATE="+collation)
+	return ct
+}
+
+// Comment sets the table comment.
+func (ct *CreateTable) Comment(comment string) *CreateTable {
+	ct.options = append(ct.options, "COMMENT="+quoteLiteral(comment))
+	return ct
+}
+
+// ToSQL renders the statement. Implements dml.QueryBuilder. The arguments are
+// always nil.
+func (ct *CreateTable) ToSQL() (string, []interface{}, error) {
+	if err := validateIdentifiers(ct.Name); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	if len(ct.columns) == 0 {
+		return "", nil, errors.Empty.Newf("[ddl] CreateTable %q requires at least one column", ct.Name)
+	}
+	if err := validateOptions(ct.options); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString("CREATE ")
+	if ct.temporary {
+		buf.WriteString("TEMPORARY ")
+	}
+	buf.WriteString("TABLE ")
+	if ct.ifNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	dml.Quoter.WriteQualifierName(buf, ct.Schema, ct.Name)
+	buf.WriteString(" (\n")
+	for i, c := range ct.columns {
+		if i > 0 {
+			buf.WriteString(",\n")
+		}
+		if err := validateIdentifiers(c.Field); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+		buf.WriteString("  ")
+		buf.WriteString(columnDefinition(c))
+	}
+	if len(ct.primaryKey) > 0 {
+		if err := validateIdentifiers(ct.primaryKey...); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+		buf.WriteString(",\n  PRIMARY KEY ")
+		writeIdentifierList(buf, ct.primaryKey)
+	}
+	for _, idx := range ct.indexes {
+		buf.WriteString(",\n  ")
+		if err := idx.write(buf, ct.Name); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+	}
+	buf.WriteString("\n)")
+	for _, o := range ct.options {
+		buf.WriteByte(' ')
+		buf.WriteString(o)
+	}
+	return buf.String(), nil, nil
+}
+
+// AlterTable builds an ALTER TABLE statement. The specifications get rendered
+// in the order of the method calls. Create it with NewAlterTable.
+type AlterTable struct {
+	Schema string
+	Name   string
+	specs  []string
+	err    error
+}
+
+// NewAlterTable creates a new ALTER TABLE builder.
+func NewAlterTable(name string) *AlterTable {
+	return &AlterTable{Name: name}
+}
+
+func (at *AlterTable) add(spec string, identifiers ...string) *AlterTable {
+	if at.err == nil {
+		at.err = validateIdentifiers(identifiers...)
+	}
+	at.specs = append(at.specs, spec)
+	return at
+}
+
+// AddColumn adds the columns at the end of the table. See
+// CreateTable.AddColumn for the supported fields of Column.
+func (at *AlterTable) AddColumn(cols ...*Column) *AlterTable {
+	for _, c := range cols {
+		at.add("ADD COLUMN "+columnDefinition(c), c.Field)
+	}
+	return at
+}
+
+// AddColumnAfter adds a column after the column `after`. An empty `after` adds
+// the column as the first one.
+func (at *AlterTable) AddColumnAfter(c *Column, after string) *AlterTable {
+	if after == "" {
+		return at.add("ADD COLUMN "+columnDefinition(c)+" FIRST", c.Field)
+	}
+	return at.add("ADD COLUMN "+columnDefinition(c)+" AFTER "+dml.Quoter.Name(after), c.Field, after)
+}
+
+// ModifyColumn changes the definitions of existing columns.
+func (at *AlterTable) ModifyColumn(cols ...*Column) *AlterTable {
+	for _, c := range cols {
+		at.add("MODIFY COLUMN "+columnDefinition(c), c.Field)
+	}
+	return at
+}
+
+// ChangeColumn renames the column `oldName` and changes its definition.
+func (at *AlterTable) ChangeColumn(oldName string, c *Column) *AlterTable {
+	return at.add("CHANGE COLUMN "+dml.Quoter.Name(oldName)+" "+columnDefinition(c), oldName, c.Field)
+}
+
+// DropColumn removes columns.
+func (at *AlterTable) DropColumn(names ...string) *AlterTable {
+	for _, n := range names {
+		at.add("DROP COLUMN "+dml.Quoter.Name(n), n)
+	}
+	return at
+}
+
+// AddIndex adds index definitions.
+func (at *AlterTable) AddIndex(idx ...IndexDef) *AlterTable {
+	for _, i := range idx {
+		buf := bufferpool.Get()
+		if err := i.write(buf, at.Name); err != nil && at.err == nil {
+			at.err = errors.WithStack(err)
+		}
+		at.specs = append(at.specs, "ADD "+buf.String())
+		bufferpool.Put(buf)
+	}
+	return at
+}
+
+// DropIndex removes indexes by their names.
+func (at *AlterTable) DropIndex(names ...string) *AlterTable {
+	for _, n := range names {
+		at.add("DROP INDEX "+dml.Quoter.Name(n), n)
+	}
+	return at
+}
+
+// AddPrimaryKey adds a primary key.
+func (at *AlterTable) AddPrimaryKey(columns ...string) *AlterTable {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString("ADD PRIMARY KEY ")
+	writeIdentifierList(buf, columns)
+	return at.add(buf.String(), columns...)
+}
+
+// DropPrimaryKey removes the primary key.
+func (at *AlterTable) DropPrimaryKey() *AlterTable {
+	return at.add("DROP PRIMARY KEY")
+}
+
+// RenameTo renames the table.
+func (at *AlterTable) RenameTo(newName string) *AlterTable {
+	return at.add("RENAME TO "+dml.Quoter.Name(newName), newName)
+}
+
+// Engine converts the table to another storage engine. See also
+// Tables.ConvertEngine.
+func (at *AlterTable) Engine(engine string) *AlterTable {
+	return at.add("ENGINE="+engine, engine)
+}
+
+// Algorithm sets the ALTER algorithm, e.g. INPLACE, COPY, INSTANT or NOCOPY.
+func (at *AlterTable) Algorithm(algorithm string) *AlterTable {
+	return at.add("ALGORITHM="+algorithm, algorithm)
+}
+
+// Lock sets the lock strategy, e.g. NONE, SHARED or EXCLUSIVE.
+func (at *AlterTable) Lock(lock string) *AlterTable {
+	return at.add("LOCK="+lock, lock)
+}
+
+// ToSQL renders the statement. Implements dml.QueryBuilder. The arguments are
+// always nil.
+func (at *AlterTable) ToSQL() (string, []interface{}, error) {
+	if at.err != nil {
+		return "", nil, errors.WithStack(at.err)
+	}
+	if err := validateIdentifiers(at.Name); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	if len(at.specs) == 0 {
+		return "", nil, errors.Empty.Newf("[ddl] AlterTable %q requires at least one specification", at.Name)
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString("ALTER TABLE ")
+	dml.Quoter.WriteQualifierName(buf, at.Schema, at.Name)
+	buf.WriteByte(' ')
+	buf.WriteString(strings.Join(at.specs, ", "))
+	return buf.String(), nil, nil
+}
+
+// CreateIndex builds a CREATE INDEX statement. Create it with NewCreateIndex.
+type CreateIndex struct {
+	Schema      string
+	Table       string
+	Index       IndexDef
+	ifNotExists bool
+	options     []string
+}
+
+// NewCreateIndex creates a new CREATE INDEX builder. An empty name generates
+// the name with IndexName.
+func NewCreateIndex(name, table string, columns ...string) *CreateIndex {
+	return &CreateIndex{
+		Table: table,
+		Index: IndexDef{Name: name, Columns: columns},
+	}
+}
+
+// Unique creates a UNIQUE index.
+func (ci *CreateIndex) Unique() *CreateIndex {
+	ci.Index.Type = IndexTypeUnique
+	return ci
+}
+
+// Fulltext creates a FULLTEXT index.
+func (ci *CreateIndex) Fulltext() *CreateIndex {
+	ci.Index.Type = IndexTypeFulltext
+	return ci
+}
+
+// IfNotExists adds IF NOT EXISTS. Supported by MariaDB.
+func (ci *CreateIndex) IfNotExists() *CreateIndex {
+	ci.ifNotExists = true
+	return ci
+}
+
+// Algorithm sets the algorithm, e.g. INPLACE or COPY.
+func (ci *CreateIndex) Algorithm(algorithm string) *CreateIndex {
+	ci.options = append(ci.options, "ALGORITHM="+algorithm)
+	return ci
+}
+
+// Lock sets the lock strategy, e.g. NONE, SHARED or EXCLUSIVE.
+func (ci *CreateIndex) Lock(lock string) *CreateIndex {
+	ci.options = append(ci.options, "LOCK="+lock)
+	return ci
+}
+
+// ToSQL renders the statement. Implements dml.QueryBuilder. The arguments are
+// always nil.
+func (ci *CreateIndex) ToSQL() (string, []interface{}, error) {
+	if err := validateIdentifiers(ci.Table); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	if err := validateOptions(ci.options); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	name, err := ci.Index.name(ci.Table)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString("CREATE ")
+	switch ci.Index.Type {
+	case IndexTypeUnique:
+		buf.WriteString("UNIQUE ")
+	case IndexTypeFulltext:
+		buf.WriteString("FULLTEXT ")
+	}
+	buf.WriteString("INDEX ")
+	if ci.ifNotExists {
+		buf.WriteString("IF NOT EXISTS ")
+	}
+	dml.Quoter.WriteIdentifier(buf, name)
+	buf.WriteString(" ON ")
+	dml.Quoter.WriteQualifierName(buf, ci.Schema, ci.Table)
+	buf.WriteByte(' ')
+	writeIdentifierList(buf, ci.Index.Columns)
+	for _, o := range ci.options {
+		buf.WriteByte(' ')
+		buf.WriteString(o)
+	}
+	return buf.String(), nil, nil
+}
+
+func validateIdentifiers(names ...string) error {
+	for _, n := range names {
+		if err := dml.IsValidIdentifier(n); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// validateOptions checks the values of table options like ENGINE=InnoDB.
+// Comments are already quoted.
+func validateOptions(options []string) error {
+	for _, o := range options {
+		if strings.HasPrefix(o, "COMMENT=") {
+			continue
+		}
+		if err := dml.IsValidIdentifier(o[strings.LastIndexByte(o, '=')+1:]); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func writeIdentifierList(buf *bytes.Buffer, names []string) {
+	buf.WriteByte('(')
+	for i, n := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		dml.Quoter.WriteIdentifier(buf, n)
+	}
+	buf.WriteByte(')')
+}
+
+// quoteLiteral quotes a string literal. Backslashes get escaped, which adds a
+// backslash to the value on servers running with the sql_mode
+// NO_BACKSLASH_ESCAPES.
+func quoteLiteral(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(s) + "'"
+}
@@ -0,0 +1,163 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestCreateTable_ToSQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all options", func(t *testing.T) {
+		sqlStr, args, err := ddl.NewCreateTable("sales_order_log").IfNotExists().
+			AddColumn(
+				&ddl.Column{Field: "log_id", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+				&ddl.Column{Field: "order_id", ColumnType: "int(10) unsigned"},
+				&ddl.Column{Field: "status", ColumnType: "varchar(32)", Default: null.MakeString("new"), Comment: "Order's status"},
+				&ddl.Column{Field: "message", ColumnType: "text", Null: "YES"},
+				&ddl.Column{Field: "created_at", ColumnType: "timestamp", Default: null.MakeString("CURRENT_TIMESTAMP"), Extra: "on update CURRENT_TIMESTAMP"},
+			).
+			PrimaryKey("log_id").
+			AddIndex(
+				ddl.IndexDef{Columns: []string{"order_id", "status"}},
+				ddl.IndexDef{Name: "UNQ_SALES_ORDER_LOG_ORDER", Type: ddl.IndexTypeUnique, Columns: []string{"order_id", "created_at"}},
+				ddl.IndexDef{Type: ddl.IndexTypeFulltext, Columns: []string{"message"}},
+			).
+			Engine("InnoDB").Charset("utf8mb4").Collate("utf8mb4_unicode_ci").Comment(`Log\of 'orders'`).
+			ToSQL()
+		assert.NoError(t, err)
+		assert.Nil(t, args)
+		assert.Exactly(t, "CREATE TABLE IF NOT EXISTS `sales_order_log` (\n"+
+			"  `log_id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n"+
+			"  `order_id` int(10) unsigned NOT NULL,\n"+
+			"  `status` varchar(32) NOT NULL DEFAULT 'new' COMMENT 'Order''s status',\n"+
+			"  `message` text NULL,\n"+
+			"  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n"+
+			"  PRIMARY KEY (`log_id`),\n"+
+			"  INDEX `SALES_ORDER_LOG_ORDER_ID_STATUS` (`order_id`,`status`),\n"+
+			"  UNIQUE KEY `UNQ_SALES_ORDER_LOG_ORDER` (`order_id`,`created_at`),\n"+
+			"  FULLTEXT INDEX `SALES_ORDER_LOG_MESSAGE` (`message`)\n"+
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Log\\\\of ''orders'''", sqlStr)
+	})
+
+	t.Run("temporary with schema", func(t *testing.T) {
+		ct := ddl.NewCreateTable("tmp_ids").Temporary().AddColumn(&ddl.Column{Field: "id", ColumnType: "int"})
+		ct.Schema = "shop"
+		sqlStr, _, err := ct.ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "CREATE TEMPORARY TABLE `shop`.`tmp_ids` (\n  `id` int NOT NULL\n)", sqlStr)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := ddl.NewCreateTable("x").ToSQL()
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, _, err = ddl.NewCreateTable("x`y").AddColumn(&ddl.Column{Field: "id", ColumnType: "int"}).ToSQL()
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		_, _, err = ddl.NewCreateTable("x").AddColumn(&ddl.Column{Field: "id", ColumnType: "int"}).Engine("InnoDB; DROP").ToSQL()
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		_, _, err = ddl.NewCreateTable("x").AddColumn(&ddl.Column{Field: "id", ColumnType: "int"}).
+			AddIndex(ddl.IndexDef{Type: "spatial", Columns: []string{"id"}}).ToSQL()
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
+
+func TestAlterTable_ToSQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("specifications", func(t *testing.T) {
+		sqlStr, args, err := ddl.NewAlterTable("sales_order_log").
+			AddColumn(&ddl.Column{Field: "store_id", ColumnType: "smallint(5) unsigned", Default: null.MakeString("0")}).
+			AddColumnAfter(&ddl.Column{Field: "customer_id", ColumnType: "int(10) unsigned", Null: "YES"}, "order_id").
+			AddColumnAfter(&ddl.Column{Field: "website_id", ColumnType: "smallint(5) unsigned"}, "").
+			ModifyColumn(&ddl.Column{Field: "status", ColumnType: "varchar(64)"}).
+			ChangeColumn("message", &ddl.Column{Field: "note", ColumnType: "text", Null: "YES"}).
+			DropColumn("legacy").
+			AddIndex(ddl.IndexDef{Columns: []string{"store_id"}}).
+			DropIndex("IDX_OLD").
+			DropPrimaryKey().
+			AddPrimaryKey("log_id", "store_id").
+			Algorithm("INPLACE").Lock("NONE").
+			ToSQL()
+		assert.NoError(t, err)
+		assert.Nil(t, args)
+		assert.Exactly(t, "ALTER TABLE `sales_order_log` ADD COLUMN `store_id` smallint(5) unsigned NOT NULL DEFAULT 0, "+
+			"ADD COLUMN `customer_id` int(10) unsigned NULL AFTER `order_id`, "+
+			"ADD COLUMN `website_id` smallint(5) unsigned NOT NULL FIRST, "+
+			"MODIFY COLUMN `status` varchar(64) NOT NULL, "+
+			"CHANGE COLUMN `message` `note` text NULL, "+
+			"DROP COLUMN `legacy`, "+
+			"ADD INDEX `SALES_ORDER_LOG_STORE_ID` (`store_id`), "+
+			"DROP INDEX `IDX_OLD`, "+
+			"DROP PRIMARY KEY, "+
+			"ADD PRIMARY KEY (`log_id`,`store_id`), "+
+			"ALGORITHM=INPLACE, LOCK=NONE", sqlStr)
+	})
+
+	t.Run("rename and engine", func(t *testing.T) {
+		sqlStr, _, err := ddl.NewAlterTable("sales_order_log").RenameTo("sales_order_history").Engine("InnoDB").ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "ALTER TABLE `sales_order_log` RENAME TO `sales_order_history`, ENGINE=InnoDB", sqlStr)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := ddl.NewAlterTable("x").ToSQL()
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, _, err = ddl.NewAlterTable("x").DropColumn("a`b").ToSQL()
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		_, _, err = ddl.NewAlterTable("x").AddIndex(ddl.IndexDef{}).ToSQL()
+		assert.ErrorIsKind(t, errors.Empty, err)
+	})
+}
+
+func TestCreateIndex_ToSQL(t *testing.T) {
+	t.Parallel()
+
+	sqlStr, args, err := ddl.NewCreateIndex("", "catalog_product_entity", "sku", "type_id").Unique().IfNotExists().
+		Algorithm("INPLACE").Lock("NONE").ToSQL()
+	assert.NoError(t, err)
+	assert.Nil(t, args)
+	assert.Exactly(t, "CREATE UNIQUE INDEX IF NOT EXISTS `CATALOG_PRODUCT_ENTITY_SKU_TYPE_ID` ON `catalog_product_entity` (`sku`,`type_id`) ALGORITHM=INPLACE LOCK=NONE", sqlStr)
+
+	sqlStr, _, err = ddl.NewCreateIndex("FTI_NAME", "catalog_product_entity_varchar", "value").Fulltext().ToSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, "CREATE FULLTEXT INDEX `FTI_NAME` ON `catalog_product_entity_varchar` (`value`)", sqlStr)
+
+	_, _, err = ddl.NewCreateIndex("x", "t").ToSQL()
+	assert.ErrorIsKind(t, errors.Empty, err)
+}
+
+func TestCreateTable_ExecContext(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE TABLE `sales_order_log` (\n  `log_id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`log_id`)\n) ENGINE=InnoDB")).
+		WithArgs().WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err := dbc.WithQueryBuilder(ddl.NewCreateTable("sales_order_log").
+		AddColumn(&ddl.Column{Field: "log_id", ColumnType: "int(10) unsigned", Extra: "auto_increment"}).
+		PrimaryKey("log_id").
+		Engine("InnoDB"),
+	).ExecContext(context.TODO())
+	assert.NoError(t, err)
+}
@@ -60,6 +60,8 @@ type Column struct {
 	Key                  string      // `COLUMN_KEY` varchar(3) NOT NULL DEFAULT '',
 	Extra                string      // `EXTRA` varchar(30) NOT NULL DEFAULT '',
 	Comment              string      // `COLUMN_COMMENT` varchar(1024) NOT NULL DEFAULT '',
+	CharacterSet         null.String // `CHARACTER_SET_NAME` varchar(32) DEFAULT NULL,
+	Collation            null.String // `COLLATION_NAME` varchar(32) DEFAULT NULL,
 	Generated            string      // `IS_GENERATED` varchar(6) NOT NULL DEFAULT '', MariaDB only https://mariadb.com/kb/en/library/information-schema-columns-table/
 	GenerationExpression null.String // `GENERATION_EXPRESSION` longtext DEFAULT NULL, MariaDB only https://mariadb.com/kb/en/library/information-schema-columns-table/
 	// Aliases specifies different names used for this column. Mainly used when
@@ -80,7 +82,8 @@ const (
 	selTablesColumnsBaseSelect = `SELECT
 	TABLE_NAME, COLUMN_NAME, ORDINAL_POSITION, COLUMN_DEFAULT, IS_NULLABLE,
 		DATA_TYPE, CHARACTER_MAXIMUM_LENGTH, NUMERIC_PRECISION, NUMERIC_SCALE,
-		COLUMN_TYPE, COLUMN_KEY, EXTRA, COLUMN_COMMENT, IS_GENERATED, GENERATION_EXPRESSION
+		COLUMN_TYPE, COLUMN_KEY, EXTRA, COLUMN_COMMENT, CHARACTER_SET_NAME, COLLATION_NAME,
+		IS_GENERATED, GENERATION_EXPRESSION
 	 FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE()`
 	// DMLLoadColumns specifies the data manipulation language for retrieving
 	// all columns in the current database for a specific table. TABLE_NAME is
@@ -389,6 +392,10 @@ func newColumn(rc *dml.ColumnMap) (c *Column, tableName string, err error) {
 			rc.String(&c.Extra)
 		case "COLUMN_COMMENT":
 			rc.String(&c.Comment)
+		case "CHARACTER_SET_NAME":
+			rc.NullString(&c.CharacterSet)
+		case "COLLATION_NAME":
+			rc.NullString(&c.Collation)
 		case "IS_GENERATED":
 			rc.String(&c.Generated)
 		case "GENERATION_EXPRESSION":
@@ -470,6 +477,12 @@ func (c *Column) GoString() string {
 	if c.Comment != "" {
 		fmt.Fprintf(buf, "Comment: %q, ", c.Comment)
 	}
+	if c.CharacterSet.Valid {
+		fmt.Fprintf(buf, "CharacterSet: null.MakeString(%q), ", c.CharacterSet.Data)
+	}
+	if c.Collation.Valid {
+		fmt.Fprintf(buf, "Collation: null.MakeString(%q), ", c.Collation.Data)
+	}
 	if len(c.Aliases) > 0 {
 		fmt.Fprintf(buf, "Aliases: %#v, ", c.Aliases)
 	}
@@ -76,13 +76,13 @@ func TestLoadColumns_Integration(t *testing.T) {
 	}{
 		{
 			"core_config_data_test3",
-			"[{\"Field\":\"config_id\",\"Pos\":1,\"Default\":null,\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"auto_increment\",\"Comment\":\"Config Id\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"scope\",\"Pos\":2,\"Default\":\"'default'\",\"Null\":\"NO\",\"DataType\":\"varchar\",\"CharMaxLength\":8,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"varchar(8)\",\"Key\":\"MUL\",\"Extra\":\"\",\"Comment\":\"Config Scope\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"scope_id\",\"Pos\":3,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Scope Id\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"path\",\"Pos\":4,\"Default\":\"'general'\",\"Null\":\"NO\",\"DataType\":\"varchar\",\"CharMaxLength\":255,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"varchar(255)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Path\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"value\",\"Pos\":5,\"Default\":\"NULL\",\"Null\":\"YES\",\"DataType\":\"text\",\"CharMaxLength\":65535,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"text\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Value\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"}]",
+			"[{\"Field\":\"config_id\",\"Pos\":1,\"Default\":null,\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"auto_increment\",\"Comment\":\"Config Id\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"scope\",\"Pos\":2,\"Default\":\"'default'\",\"Null\":\"NO\",\"DataType\":\"varchar\",\"CharMaxLength\":8,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"varchar(8)\",\"Key\":\"MUL\",\"Extra\":\"\",\"Comment\":\"Config Scope\",\"CharacterSet\":\"utf8\",\"Collation\":\"utf8_general_ci\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"scope_id\",\"Pos\":3,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Scope Id\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"path\",\"Pos\":4,\"Default\":\"'general'\",\"Null\":\"NO\",\"DataType\":\"varchar\",\"CharMaxLength\":255,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"varchar(255)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Path\",\"CharacterSet\":\"utf8\",\"Collation\":\"utf8_general_ci\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"value\",\"Pos\":5,\"Default\":\"NULL\",\"Null\":\"YES\",\"DataType\":\"text\",\"CharMaxLength\":65535,\"Precision\":null,\"Scale\":null,\"ColumnType\":\"text\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Config Value\",\"CharacterSet\":\"utf8\",\"Collation\":\"utf8_general_ci\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"}]",
 			errors.NoKind,
 			"config_id_scope_scope_id_path_value",
 		},
 		{
 			"catalog_category_product_test4",
-			"[{\"Field\":\"entity_id\",\"Pos\":1,\"Default\":null,\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"PRI\",\"Extra\":\"auto_increment\",\"Comment\":\"Entity ID\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"category_id\",\"Pos\":2,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"\",\"Comment\":\"Category ID\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"product_id\",\"Pos\":3,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"\",\"Comment\":\"Product ID\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"position\",\"Pos\":4,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Position\",\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"}]",
+			"[{\"Field\":\"entity_id\",\"Pos\":1,\"Default\":null,\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"PRI\",\"Extra\":\"auto_increment\",\"Comment\":\"Entity ID\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"category_id\",\"Pos\":2,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"\",\"Comment\":\"Category ID\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"product_id\",\"Pos\":3,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(10) unsigned\",\"Key\":\"PRI\",\"Extra\":\"\",\"Comment\":\"Product ID\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"},{\"Field\":\"position\",\"Pos\":4,\"Default\":\"0\",\"Null\":\"NO\",\"DataType\":\"int\",\"CharMaxLength\":null,\"Precision\":10,\"Scale\":0,\"ColumnType\":\"int(11)\",\"Key\":\"\",\"Extra\":\"\",\"Comment\":\"Position\",\"CharacterSet\":null,\"Collation\":null,\"Generated\":\"NEVER\",\"GenerationExpression\":null,\"Aliases\":null,\"Uniquified\":false,\"StructTag\":\"\"}]",
 			errors.NoKind,
 			"entity_id_category_id_product_id_position",
 		},
@@ -0,0 +1,360 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// innoDBMaxRowSize defines the maximum size of a row stored within an InnoDB
+// page with the default innodb_page_size of 16KiB.
+const innoDBMaxRowSize = 8126
+
+// Steps reported by ConvertEngineProgress.
+const (
+	ConvertEngineStepAlter        = "alter"
+	ConvertEngineStepCreateShadow = "create_shadow"
+	ConvertEngineStepCopy         = "copy"
+	ConvertEngineStepSwap         = "swap"
+	ConvertEngineStepCleanup      = "cleanup"
+)
+
+// ConvertEngineOptions configures Tables.ConvertEngine.
+type ConvertEngineOptions struct {
+	Options
+	// ShadowCopy converts the table in the style of pt-online-schema-change:
+	// An empty shadow table with the new engine gets created and kept in sync
+	// via triggers while the rows get copied in chunks of the primary key.
+	// Finally both tables get swapped atomically. The table stays writable
+	// during the conversion. Requires a single column integer primary key. If
+	// false, an ALTER TABLE gets executed, which blocks writes to the table.
+	ShadowCopy bool
+	// ChunkSize defines the number of primary keys per INSERT statement while
+	// copying the rows into the shadow table. Defaults to 1000.
+	ChunkSize uint64
+	// KeepOldTable keeps the old table with the name _<table>_old after the
+	// swap of the shadow copy. Otherwise it gets dropped.
+	KeepOldTable bool
+	// IgnoreBlockers converts the table even if ConvertEngineBlockers found
+	// reasons which prevent a conversion.
+	IgnoreBlockers bool
+	// Progress gets called after each step and each copied chunk.
+	Progress func(ConvertEngineProgress)
+}
+
+// ConvertEngineProgress reports the progress of Tables.ConvertEngine.
+type ConvertEngineProgress struct {
+	TableName string
+	// Step is one of the ConvertEngineStep* constants.
+	Step string
+	// CopiedRows contains the number of rows copied into the shadow table.
+	CopiedRows uint64
+	// TotalRows contains the estimated number of rows of the table. The
+	// estimate gets taken from the table statistics.
+	TotalRows uint64
+}
+
+func (o ConvertEngineOptions) progress(p ConvertEngineProgress) {
+	if o.Progress != nil {
+		o.Progress(p)
+	}
+}
+
+// ConvertEngineBlockers checks if a table can be converted to another engine
+// and returns the reasons which would let the conversion fail. Checks for
+// InnoDB the support of FULLTEXT indexes by the server version and estimates
+// the minimum row size for the DYNAMIC row format, assuming four bytes per
+// character, against the maximum row size of 8126 bytes. The columns of the
+// table must be loaded.
+func (tm *Tables) ConvertEngineBlockers(ctx context.Context, tableName, engine string) ([]string, error) {
+	t, err := tm.Table(tableName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !strings.EqualFold(engine, "InnoDB") {
+		return nil, nil
+	}
+
+	var blockers []string
+	var fulltextIndexes []string
+	if err := tm.dcp.WithRawSQL("SELECT DISTINCT `INDEX_NAME` FROM `information_schema`.`STATISTICS` WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ? AND `INDEX_TYPE` = 'FULLTEXT' ORDER BY `INDEX_NAME`").
+		IterateSerial(ctx, func(cm *dml.ColumnMap) error {
+			var idx string
+			for cm.Next() {
+				cm.String(&idx)
+			}
+			if err := cm.Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			fulltextIndexes = append(fulltextIndexes, idx)
+			return nil
+		}, tableName); err != nil {
+		return nil, errors.Wrapf(err, "[ddl] ConvertEngineBlockers failed to query the FULLTEXT indexes of table %q", tableName)
+	}
+	if len(fulltextIndexes) > 0 {
+		var version string
+		if err := tm.dcp.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+			return nil, errors.Wrapf(err, "[ddl] ConvertEngineBlockers failed to query the version")
+		}
+		if !supportsInnoDBFulltext(version) {
+			blockers = append(blockers, "server version "+version+" does not support the FULLTEXT indexes "+strings.Join(fulltextIndexes, ", ")+" in InnoDB")
+		}
+	}
+
+	var rowSize uint64
+	for _, c := range t.Columns {
+		rowSize += innoDBMinColumnSize(c)
+	}
+	if rowSize > innoDBMaxRowSize {
+		blockers = append(blockers, "estimated row size of "+strconv.FormatUint(rowSize, 10)+" bytes exceeds the InnoDB maximum of "+strconv.Itoa(innoDBMaxRowSize)+" bytes")
+	}
+	return blockers, nil
+}
+
+// supportsInnoDBFulltext reports whether a server with the version string as
+// returned by VERSION() supports FULLTEXT indexes in InnoDB. MariaDB since
+// 10.0.5 and MySQL since 5.6.
+func supportsInnoDBFulltext(version string) bool {
+	isMariaDB := strings.Contains(strings.ToLower(version), "mariadb")
+	if isMariaDB {
+		version = strings.TrimPrefix(version, "5.5.5-") // replication compatibility prefix
+	}
+	if i := strings.IndexAny(version, "-+ "); i > 0 {
+		version = version[:i]
+	}
+	var v [3]int
+	for i, p := range strings.SplitN(version, ".", 3) {
+		v[i], _ = strconv.Atoi(p)
+	}
+	if isMariaDB {
+		return v[0] > 10 || (v[0] == 10 && (v[1] > 0 || v[2] >= 5))
+	}
+	return v[0] > 5 || (v[0] == 5 && v[1] >= 6)
+}
+
+// innoDBMinColumnSize returns the minimum number of bytes a column occupies
+// within an InnoDB page. Variable length columns longer than 40 bytes can be
+// stored off-page with a 20 byte pointer.
+func innoDBMinColumnSize(c *Column) uint64 {
+	charLen := uint64(c.CharMaxLength.Int64)
+	switch c.DataType {
+	case "tinyint", "year":
+		return 1
+	case "smallint", "enum":
+		return 2
+	case "mediumint", "date", "time":
+		return 3
+	case "int", "integer", "float", "timestamp":
+		return 4
+	case "bigint", "double", "real", "datetime", "set":
+		return 8
+	case "decimal", "numeric":
+		return uint64(c.Precision.Int64)/2 + 1
+	case "bit":
+		return (uint64(c.Precision.Int64) + 7) / 8
+	case "binary":
+		return charLen
+	case "char":
+		return charLen * 4
+	case "varbinary", "varchar":
+		if c.DataType == "varchar" {
+			charLen *= 4
+		}
+		if charLen <= 40 {
+			return charLen + 1
+		}
+	}
+	return 20 // TEXT, BLOB, JSON, GEOMETRY and long VARCHAR
+}
+
+// ConvertEngine converts the table to another storage engine, for example
+// from MyISAM to InnoDB. FULLTEXT indexes get preserved. Returns an error of
+// kind NotSupported if ConvertEngineBlockers returns blockers, unless
+// ConvertEngineOptions.IgnoreBlockers has been set. A table which already
+// uses the engine gets skipped. See ConvertEngineOptions.ShadowCopy for an
+// online conversion.
+func (tm *Tables) ConvertEngine(ctx context.Context, tableName, engine string, o ConvertEngineOptions) error {
+	if err := dml.IsValidIdentifier(tableName); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := dml.IsValidIdentifier(engine); err != nil {
+		return errors.WithStack(err)
+	}
+	t, err := tm.Table(tableName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if strings.EqualFold(t.Engine.Data, engine) {
+		return nil
+	}
+
+	if !o.IgnoreBlockers {
+		blockers, err := tm.ConvertEngineBlockers(ctx, tableName, engine)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(blockers) > 0 {
+			return errors.NotSupported.Newf("[ddl] ConvertEngine: Table %q cannot be converted to %q: %s", tableName, engine, strings.Join(blockers, "; "))
+		}
+	}
+
+	prg := ConvertEngineProgress{
+		TableName: tableName,
+		TotalRows: t.TableRows.Uint64,
+	}
+	exec := o.exec(tm.dcp.DB)
+
+	if !o.ShadowCopy {
+		buf := bufferpool.Get()
+		defer bufferpool.Put(buf)
+		buf.WriteString("ALTER TABLE ")
+		dml.Quoter.WriteQualifierName(buf, t.Schema, t.Name)
+		o.sqlAddShouldWait(buf)
+		buf.WriteString(" ENGINE=")
+		buf.WriteString(engine)
+		if _, err := exec.ExecContext(ctx, buf.String()); err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine failed to alter table %q", tableName)
+		}
+		t.Engine.Data, t.Engine.Valid = engine, true
+		prg.Step = ConvertEngineStepAlter
+		o.progress(prg)
+		return nil
+	}
+
+	return tm.convertEngineShadowCopy(ctx, exec, t, engine, o, prg)
+}
+
+// convertEngineShadowCopy copies the rows into a shadow table with the new
+// engine and swaps both tables. The triggers and the shadow table get removed
+// on failure.
+func (tm *Tables) convertEngineShadowCopy(ctx context.Context, exec dml.Execer, t *Table, engine string, o ConvertEngineOptions, prg ConvertEngineProgress) (err error) {
+	pkCols := t.Columns.PrimaryKeys()
+	if len(pkCols) != 1 || !strings.Contains(pkCols[0].DataType, "int") {
+		return errors.NotSupported.Newf("[ddl] ConvertEngine.ShadowCopy requires a single column integer primary key for table %q", t.Name)
+	}
+	pk := pkCols[0].Field
+	if o.ChunkSize == 0 {
+		o.ChunkSize = 1000
+	}
+
+	shadow := "_" + t.Name + "_new"
+	old := "_" + t.Name + "_old"
+	triggers := [...]string{"_" + t.Name + "_ins", "_" + t.Name + "_upd", "_" + t.Name + "_del"}
+	for _, n := range append([]string{shadow, old}, triggers[:]...) {
+		if err := dml.IsValidIdentifier(n); err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy identifier for table %q", t.Name)
+		}
+	}
+	qTable := dml.Quoter.Name(t.Name)
+	qShadow := dml.Quoter.Name(shadow)
+
+	var cols []string
+	for _, c := range t.Columns {
+		if !c.IsGenerated() && !c.IsSystemVersioned() {
+			cols = append(cols, c.Field)
+		}
+	}
+	qCols := make([]string, len(cols))
+	newCols := make([]string, len(cols))
+	for i, c := range cols {
+		qCols[i] = dml.Quoter.Name(c)
+		newCols[i] = "NEW." + qCols[i]
+	}
+	replaceNew := "REPLACE INTO " + qShadow + " (" + strings.Join(qCols, ",") + ") VALUES (" + strings.Join(newCols, ",") + ")"
+	deleteOld := "DELETE IGNORE FROM " + qShadow + " WHERE " + dml.Quoter.Name(pk) + " <=> OLD." + dml.Quoter.Name(pk)
+
+	stmts := []string{
+		"CREATE TABLE " + qShadow + " LIKE " + qTable,
+		"ALTER TABLE " + qShadow + " ENGINE=" + engine,
+		"CREATE TRIGGER " + dml.Quoter.Name(triggers[0]) + " AFTER INSERT ON " + qTable + " FOR EACH ROW " + replaceNew,
+		"CREATE TRIGGER " + dml.Quoter.Name(triggers[1]) + " AFTER UPDATE ON " + qTable + " FOR EACH ROW BEGIN " + deleteOld + "; " + replaceNew + "; END",
+		"CREATE TRIGGER " + dml.Quoter.Name(triggers[2]) + " AFTER DELETE ON " + qTable + " FOR EACH ROW " + deleteOld,
+	}
+	dropTriggers := func() error {
+		for _, trg := range triggers {
+			if _, err := exec.ExecContext(ctx, "DROP TRIGGER IF EXISTS "+dml.Quoter.Name(trg)); err != nil {
+				return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to drop trigger %q", trg)
+			}
+		}
+		return nil
+	}
+	swapped := false
+	defer func() {
+		if err == nil || swapped {
+			return
+		}
+		// best effort, the original error is more important.
+		_ = dropTriggers()
+		_, _ = exec.ExecContext(ctx, "DROP TABLE IF EXISTS "+qShadow)
+	}()
+
+	for _, s := range stmts {
+		if _, err = exec.ExecContext(ctx, s); err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed for table %q", t.Name)
+		}
+	}
+	prg.Step = ConvertEngineStepCreateShadow
+	o.progress(prg)
+
+	var minPK, maxPK sql.NullInt64
+	if err = tm.dcp.DB.QueryRowContext(ctx, "SELECT MIN("+dml.Quoter.Name(pk)+"), MAX("+dml.Quoter.Name(pk)+") FROM "+qTable).Scan(&minPK, &maxPK); err != nil {
+		return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to query the primary key range of table %q", t.Name)
+	}
+
+	prg.Step = ConvertEngineStepCopy
+	for lower := minPK.Int64; minPK.Valid && lower <= maxPK.Int64; lower += int64(o.ChunkSize) {
+		upper := lower + int64(o.ChunkSize) - 1
+		sqlStr, _, err := dml.NewInsert(shadow).Ignore().AddColumns(cols...).FromSelect(
+			dml.NewSelect(cols...).From(t.Name).Where(dml.Column(pk).Between().Int64s(lower, upper)).LockInShareMode(),
+		).ToSQL()
+		if err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to build the copy statement for table %q", t.Name)
+		}
+		res, err := exec.ExecContext(ctx, sqlStr)
+		if err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to copy the rows %d to %d of table %q", lower, upper, t.Name)
+		}
+		ra, _ := res.RowsAffected()
+		prg.CopiedRows += uint64(ra)
+		o.progress(prg)
+	}
+
+	if _, err = exec.ExecContext(ctx, "RENAME TABLE "+qTable+" TO "+dml.Quoter.Name(old)+", "+qShadow+" TO "+qTable); err != nil {
+		return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to swap table %q", t.Name)
+	}
+	swapped = true
+	t.Engine.Data, t.Engine.Valid = engine, true
+	prg.Step = ConvertEngineStepSwap
+	o.progress(prg)
+
+	if err = dropTriggers(); err != nil {
+		return errors.WithStack(err)
+	}
+	if !o.KeepOldTable {
+		if _, err = exec.ExecContext(ctx, "DROP TABLE "+dml.Quoter.Name(old)); err != nil {
+			return errors.Wrapf(err, "[ddl] ConvertEngine.ShadowCopy failed to drop the old table of %q", t.Name)
+		}
+	}
+	prg.Step = ConvertEngineStepCleanup
+	o.progress(prg)
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+const selFulltextIndexes = "SELECT DISTINCT `INDEX_NAME` FROM `information_schema`.`STATISTICS` WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ? AND `INDEX_TYPE` = 'FULLTEXT' ORDER BY `INDEX_NAME`"
+
+func newConvertEngineTables(t *testing.T, cols ...*ddl.Column) (*ddl.Tables, sqlmock.Sqlmock, func()) {
+	dbc, dbMock := dmltest.MockDB(t)
+	if len(cols) == 0 {
+		cols = []*ddl.Column{
+			{Field: "entity_id", Pos: 1, DataType: "int", ColumnType: "int(10) unsigned", Key: "PRI", Extra: "auto_increment"},
+			{Field: "title", Pos: 2, DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+			{Field: "content", Pos: 3, DataType: "text", ColumnType: "text"},
+		}
+	}
+	tbls, err := ddl.NewTables(ddl.WithConnPool(dbc), ddl.WithTable("catalog_search", cols...))
+	assert.NoError(t, err)
+	return tbls, dbMock, func() { dmltest.MockClose(t, dbc, dbMock) }
+}
+
+func TestTables_ConvertEngineBlockers(t *testing.T) {
+	t.Run("FULLTEXT not supported", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t)
+		defer closeFn()
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(selFulltextIndexes)).WithArgs("catalog_search").
+			WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME"}).AddRow("FTI_CONTENT").AddRow("FTI_TITLE"))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("5.5.5-10.0.4-MariaDB"))
+
+		blockers, err := tbls.ConvertEngineBlockers(context.TODO(), "catalog_search", "InnoDB")
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{"server version 5.5.5-10.0.4-MariaDB does not support the FULLTEXT indexes FTI_CONTENT, FTI_TITLE in InnoDB"}, blockers)
+	})
+
+	t.Run("FULLTEXT supported", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t)
+		defer closeFn()
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(selFulltextIndexes)).WithArgs("catalog_search").
+			WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME"}).AddRow("FTI_CONTENT"))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("5.6.10-log"))
+
+		blockers, err := tbls.ConvertEngineBlockers(context.TODO(), "catalog_search", "InnoDB")
+		assert.NoError(t, err)
+		assert.Len(t, blockers, 0)
+	})
+
+	t.Run("row size too large", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t,
+			&ddl.Column{Field: "entity_id", Pos: 1, DataType: "int", Key: "PRI"},
+			&ddl.Column{Field: "sku", Pos: 2, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "name", Pos: 3, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "description", Pos: 4, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "meta", Pos: 5, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "meta2", Pos: 6, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "meta3", Pos: 7, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "meta4", Pos: 8, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+			&ddl.Column{Field: "meta5", Pos: 9, DataType: "char", CharMaxLength: null.MakeInt64(255)},
+		)
+		defer closeFn()
+
+		for i := 0; i < 2; i++ {
+			dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(selFulltextIndexes)).WithArgs("catalog_search").
+				WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME"}))
+		}
+
+		blockers, err := tbls.ConvertEngineBlockers(context.TODO(), "catalog_search", "InnoDB")
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{"estimated row size of 8164 bytes exceeds the InnoDB maximum of 8126 bytes"}, blockers)
+
+		err = tbls.ConvertEngine(context.TODO(), "catalog_search", "InnoDB", ddl.ConvertEngineOptions{})
+		assert.True(t, errors.NotSupported.Match(err), "%+v", err)
+	})
+}
+
+func TestTables_ConvertEngine(t *testing.T) {
+	t.Run("ALTER", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t)
+		defer closeFn()
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("ALTER TABLE `catalog_search` ENGINE=InnoDB")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		var steps []string
+		assert.NoError(t, tbls.ConvertEngine(context.TODO(), "catalog_search", "InnoDB", ddl.ConvertEngineOptions{
+			IgnoreBlockers: true,
+			Progress: func(p ddl.ConvertEngineProgress) {
+				steps = append(steps, p.Step)
+			},
+		}))
+		assert.Exactly(t, []string{ddl.ConvertEngineStepAlter}, steps)
+		assert.Exactly(t, "InnoDB", tbls.MustTable("catalog_search").Engine.Data)
+
+		// already converted
+		assert.NoError(t, tbls.ConvertEngine(context.TODO(), "catalog_search", "InnoDB", ddl.ConvertEngineOptions{}))
+	})
+
+	t.Run("shadow copy", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t)
+		defer closeFn()
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(selFulltextIndexes)).WithArgs("catalog_search").
+			WillReturnRows(sqlmock.NewRows([]string{"INDEX_NAME"}).AddRow("FTI_CONTENT"))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("10.3.2-MariaDB"))
+
+		for _, sqlStr := range []string{
+			"CREATE TABLE `_catalog_search_new` LIKE `catalog_search`",
+			"ALTER TABLE `_catalog_search_new` ENGINE=InnoDB",
+			"CREATE TRIGGER `_catalog_search_ins` AFTER INSERT ON `catalog_search` FOR EACH ROW REPLACE INTO `_catalog_search_new` (`entity_id`,`title`,`content`) VALUES (NEW.`entity_id`,NEW.`title`,NEW.`content`)",
+			"CREATE TRIGGER `_catalog_search_upd` AFTER UPDATE ON `catalog_search` FOR EACH ROW BEGIN DELETE IGNORE FROM `_catalog_search_new` WHERE `entity_id` <=> OLD.`entity_id`; REPLACE INTO `_catalog_search_new` (`entity_id`,`title`,`content`) VALUES (NEW.`entity_id`,NEW.`title`,NEW.`content`); END",
+			"CREATE TRIGGER `_catalog_search_del` AFTER DELETE ON `catalog_search` FOR EACH ROW DELETE IGNORE FROM `_catalog_search_new` WHERE `entity_id` <=> OLD.`entity_id`",
+		} {
+			dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(sqlStr)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT MIN(`entity_id`), MAX(`entity_id`) FROM `catalog_search`")).
+			WillReturnRows(sqlmock.NewRows([]string{"MIN", "MAX"}).AddRow(1, 15))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT IGNORE INTO `_catalog_search_new` (`entity_id`,`title`,`content`) SELECT `entity_id`, `title`, `content` FROM `catalog_search` WHERE (`entity_id` BETWEEN 1 AND 10) LOCK IN SHARE MODE")).
+			WillReturnResult(sqlmock.NewResult(0, 9))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT IGNORE INTO `_catalog_search_new` (`entity_id`,`title`,`content`) SELECT `entity_id`, `title`, `content` FROM `catalog_search` WHERE (`entity_id` BETWEEN 11 AND 20) LOCK IN SHARE MODE")).
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		for _, sqlStr := range []string{
+			"RENAME TABLE `catalog_search` TO `_catalog_search_old`, `_catalog_search_new` TO `catalog_search`",
+			"DROP TRIGGER IF EXISTS `_catalog_search_ins`",
+			"DROP TRIGGER IF EXISTS `_catalog_search_upd`",
+			"DROP TRIGGER IF EXISTS `_catalog_search_del`",
+			"DROP TABLE `_catalog_search_old`",
+		} {
+			dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(sqlStr)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		var progress []ddl.ConvertEngineProgress
+		assert.NoError(t, tbls.ConvertEngine(context.TODO(), "catalog_search", "InnoDB", ddl.ConvertEngineOptions{
+			ShadowCopy: true,
+			ChunkSize:  10,
+			Progress: func(p ddl.ConvertEngineProgress) {
+				progress = append(progress, p)
+			},
+		}))
+		assert.Exactly(t, []ddl.ConvertEngineProgress{
+			{TableName: "catalog_search", Step: ddl.ConvertEngineStepCreateShadow},
+			{TableName: "catalog_search", Step: ddl.ConvertEngineStepCopy, CopiedRows: 9},
+			{TableName: "catalog_search", Step: ddl.ConvertEngineStepCopy, CopiedRows: 14},
+			{TableName: "catalog_search", Step: ddl.ConvertEngineStepSwap, CopiedRows: 14},
+			{TableName: "catalog_search", Step: ddl.ConvertEngineStepCleanup, CopiedRows: 14},
+		}, progress)
+	})
+
+	t.Run("shadow copy failure cleans up", func(t *testing.T) {
+		tbls, dbMock, closeFn := newConvertEngineTables(t)
+		defer closeFn()
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE TABLE `_catalog_search_new` LIKE `catalog_search`")).
+			WillReturnError(errors.AlreadyExists.Newf("Table exists"))
+		for _, sqlStr := range []string{
+			"DROP TRIGGER IF EXISTS `_catalog_search_ins`",
+			"DROP TRIGGER IF EXISTS `_catalog_search_upd`",
+			"DROP TRIGGER IF EXISTS `_catalog_search_del`",
+			"DROP TABLE IF EXISTS `_catalog_search_new`",
+		} {
+			dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(sqlStr)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		err := tbls.ConvertEngine(context.TODO(), "catalog_search", "InnoDB", ddl.ConvertEngineOptions{
+			ShadowCopy:     true,
+			IgnoreBlockers: true,
+		})
+		assert.True(t, errors.AlreadyExists.Match(err), "%+v", err)
+	})
+}
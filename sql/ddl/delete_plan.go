@@ -0,0 +1,214 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// DeleteStatement defines a single DELETE statement of a DeletePlan.
+type DeleteStatement struct {
+	TableName string
+	// Depth defines the distance to the root table in the foreign key graph.
+	// The root table has depth zero.
+	Depth int
+	SQL   string
+}
+
+// DeletePlan contains the DELETE statements to remove rows of a root table
+// together with all rows of the tables which reference them via foreign keys,
+// for example to fulfill a GDPR erasure request. The statements are ordered
+// child-first, so no foreign key constraint gets violated and the foreign key
+// checks can stay enabled.
+type DeletePlan struct {
+	RootTable  string
+	Statements []DeleteStatement
+	// joins, pkColumn and pks are used to build the multi-table DELETE.
+	joins    []deletePlanJoin
+	pkColumn string
+	pks      []uint64
+}
+
+type deletePlanJoin struct {
+	parentAlias, parentColumn string
+	table, alias, column      string
+}
+
+// NewDeletePlan creates the child-first deletion plan for the rows of
+// rootTable identified by the primary key values pks. Argument foreignKeys
+// must contain all foreign keys of the database as returned by
+// LoadKeyColumnUsage without table names. Circular foreign keys, including
+// self referencing tables, and composite foreign keys are not supported. A
+// table reachable via different paths gets one DELETE statement per path.
+func NewDeletePlan(foreignKeys map[string]KeyColumnUsageCollection, rootTable, pkColumn string, pks ...uint64) (*DeletePlan, error) {
+	if len(pks) == 0 {
+		return nil, errors.Empty.Newf("[ddl] NewDeletePlan: no primary keys for table %q provided", rootTable)
+	}
+	for tn, kcuc := range foreignKeys {
+		seen := make(map[string]bool, len(kcuc.Data))
+		for _, kcu := range kcuc.Data {
+			if seen[kcu.ConstraintName] {
+				return nil, errors.NotSupported.Newf("[ddl] NewDeletePlan: composite foreign key %q of table %q not supported", kcu.ConstraintName, tn)
+			}
+			seen[kcu.ConstraintName] = true
+		}
+	}
+
+	dp := &DeletePlan{
+		RootTable: rootTable,
+		pkColumn:  pkColumn,
+		pks:       pks,
+	}
+	rootCond := dml.Column(pkColumn).In().Uint64s(pks...)
+	w := deletePlanWalker{
+		dp:       dp,
+		children: ReverseKeyColumnUsage(foreignKeys),
+		path:     map[string]bool{rootTable: true},
+	}
+	if err := w.walk(rootTable, "t0", rootCond, 1); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sqlStr, _, err := dml.NewDelete(rootTable).Where(rootCond).ToSQL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dp.Statements = append(dp.Statements, DeleteStatement{TableName: rootTable, SQL: sqlStr})
+	return dp, nil
+}
+
+type deletePlanWalker struct {
+	dp       *DeletePlan
+	children map[string]KeyColumnUsageCollection
+	// path contains the tables of the current branch to detect cycles.
+	path map[string]bool
+}
+
+// walk appends depth-first the DELETE statements of all children of the
+// parent table. The parent condition selects the rows of the parent table
+// which get deleted.
+func (w *deletePlanWalker) walk(parent, parentAlias string, parentCond *dml.Condition, depth int) error {
+	refs := append([]*KeyColumnUsage(nil), w.children[parent].Data...)
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].ReferencedTableName.Data != refs[j].ReferencedTableName.Data {
+			return refs[i].ReferencedTableName.Data < refs[j].ReferencedTableName.Data
+		}
+		return refs[i].ReferencedColumnName.Data < refs[j].ReferencedColumnName.Data
+	})
+
+	for _, ref := range refs {
+		// ref.TableName is the parent and ref.ReferencedTableName the child
+		// because the key column usage has been reversed.
+		child, childCol := ref.ReferencedTableName.Data, ref.ReferencedColumnName.Data
+		if w.path[child] {
+			return errors.NotSupported.Newf("[ddl] NewDeletePlan: circular foreign key %q from table %q to %q not supported", ref.ConstraintName, child, parent)
+		}
+
+		var cond *dml.Condition
+		if depth == 1 && ref.ColumnName == w.dp.pkColumn {
+			cond = dml.Column(childCol).In().Uint64s(w.dp.pks...)
+		} else {
+			cond = dml.Column(childCol).In().Sub(
+				dml.NewSelect(ref.ColumnName).From(parent).Where(parentCond),
+			)
+		}
+
+		childAlias := "t" + strconv.Itoa(len(w.dp.joins)+1)
+		w.dp.joins = append(w.dp.joins, deletePlanJoin{
+			parentAlias:  parentAlias,
+			parentColumn: ref.ColumnName,
+			table:        child,
+			alias:        childAlias,
+			column:       childCol,
+		})
+
+		w.path[child] = true
+		if err := w.walk(child, childAlias, cond, depth+1); err != nil {
+			return errors.WithStack(err)
+		}
+		delete(w.path, child)
+
+		sqlStr, _, err := dml.NewDelete(child).Where(cond).ToSQL()
+		if err != nil {
+			return errors.Wrapf(err, "[ddl] NewDeletePlan: failed to build DELETE for table %q", child)
+		}
+		w.dp.Statements = append(w.dp.Statements, DeleteStatement{TableName: child, Depth: depth, SQL: sqlStr})
+	}
+	return nil
+}
+
+// DeletePlan loads all foreign keys of the current database and creates the
+// child-first deletion plan for the rows of rootTable. See NewDeletePlan.
+func (tm *Tables) DeletePlan(ctx context.Context, rootTable, pkColumn string, pks ...uint64) (*DeletePlan, error) {
+	fks, err := LoadKeyColumnUsage(ctx, tm.dcp.DB)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dp, err := NewDeletePlan(fks, rootTable, pkColumn, pks...)
+	return dp, errors.WithStack(err)
+}
+
+// WriteTo writes the planned statements, separated by a semicolon and a new
+// line, to w without executing them. Use it as a dry run.
+func (dp *DeletePlan) WriteTo(w io.Writer) (n int64, err error) {
+	for _, ds := range dp.Statements {
+		n2, err := io.WriteString(w, ds.SQL+";\n")
+		n += int64(n2)
+		if err != nil {
+			return n, errors.WithStack(err)
+		}
+	}
+	return n, nil
+}
+
+// Exec executes the planned statements in order and returns the total number
+// of deleted rows. Run it within a transaction to avoid a partial deletion.
+func (dp *DeletePlan) Exec(ctx context.Context, db dml.Execer) (rowsAffected int64, err error) {
+	for _, ds := range dp.Statements {
+		res, err := db.ExecContext(ctx, ds.SQL)
+		if err != nil {
+			return rowsAffected, errors.Wrapf(err, "[ddl] DeletePlan.Exec failed for table %q", ds.TableName)
+		}
+		ra, err := res.RowsAffected()
+		if err != nil {
+			return rowsAffected, errors.WithStack(err)
+		}
+		rowsAffected += ra
+	}
+	return rowsAffected, nil
+}
+
+// MultiTableSQL generates a single multi-table DELETE statement which joins
+// all planned tables to the root table. InnoDB does not guarantee the deletion
+// order of a multi-table DELETE, so it might fail for foreign keys without ON
+// DELETE CASCADE; prefer Exec in that case.
+func (dp *DeletePlan) MultiTableSQL() (string, error) {
+	del := dml.NewDelete(dp.RootTable).Alias("t0")
+	for _, j := range dp.joins {
+		del.FromTables(j.alias).LeftJoin(
+			dml.MakeIdentifier(j.table).Alias(j.alias),
+			dml.Column(j.alias+"."+j.column).Equal().Column(j.parentAlias+"."+j.parentColumn),
+		)
+	}
+	sqlStr, _, err := del.Where(
+		dml.Column("t0." + dp.pkColumn).In().Uint64s(dp.pks...),
+	).ToSQL()
+	return sqlStr, errors.WithStack(err)
+}
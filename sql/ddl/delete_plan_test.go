@@ -0,0 +1,126 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func newFK(constraint, table, column, refTable, refColumn string) *ddl.KeyColumnUsage {
+	return &ddl.KeyColumnUsage{
+		ConstraintName:       constraint,
+		TableName:            table,
+		ColumnName:           column,
+		ReferencedTableName:  null.MakeString(refTable),
+		ReferencedColumnName: null.MakeString(refColumn),
+	}
+}
+
+func TestNewDeletePlan(t *testing.T) {
+	t.Parallel()
+
+	fks := map[string]ddl.KeyColumnUsageCollection{
+		"customer_address": {Data: []*ddl.KeyColumnUsage{
+			newFK("FK_CA_CE", "customer_address", "parent_id", "customer_entity", "entity_id"),
+		}},
+		"quote_address": {Data: []*ddl.KeyColumnUsage{
+			newFK("FK_QA_CA", "quote_address", "customer_address_id", "customer_address", "entity_id"),
+		}},
+		"sales_order": {Data: []*ddl.KeyColumnUsage{
+			newFK("FK_SO_CE", "sales_order", "customer_id", "customer_entity", "entity_id"),
+		}},
+		"catalog_product_entity": {Data: []*ddl.KeyColumnUsage{
+			newFK("FK_CPE_EAS", "catalog_product_entity", "attribute_set_id", "eav_attribute_set", "attribute_set_id"),
+		}},
+	}
+
+	dp, err := ddl.NewDeletePlan(fks, "customer_entity", "entity_id", 3, 4)
+	assert.NoError(t, err)
+	assert.Exactly(t, []ddl.DeleteStatement{
+		{TableName: "quote_address", Depth: 2, SQL: "DELETE FROM `quote_address` WHERE (`customer_address_id` IN (SELECT `entity_id` FROM `customer_address` WHERE (`parent_id` IN (3,4))))"},
+		{TableName: "customer_address", Depth: 1, SQL: "DELETE FROM `customer_address` WHERE (`parent_id` IN (3,4))"},
+		{TableName: "sales_order", Depth: 1, SQL: "DELETE FROM `sales_order` WHERE (`customer_id` IN (3,4))"},
+		{TableName: "customer_entity", Depth: 0, SQL: "DELETE FROM `customer_entity` WHERE (`entity_id` IN (3,4))"},
+	}, dp.Statements)
+
+	t.Run("dry run", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := dp.WriteTo(&buf)
+		assert.NoError(t, err)
+		assert.Exactly(t, "DELETE FROM `quote_address` WHERE (`customer_address_id` IN (SELECT `entity_id` FROM `customer_address` WHERE (`parent_id` IN (3,4))));\n"+
+			"DELETE FROM `customer_address` WHERE (`parent_id` IN (3,4));\n"+
+			"DELETE FROM `sales_order` WHERE (`customer_id` IN (3,4));\n"+
+			"DELETE FROM `customer_entity` WHERE (`entity_id` IN (3,4));\n", buf.String())
+	})
+
+	t.Run("multi table", func(t *testing.T) {
+		sqlStr, err := dp.MultiTableSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "DELETE `t0`,`t1`,`t2`,`t3` FROM `customer_entity` AS `t0` "+
+			"LEFT JOIN `customer_address` AS `t1` ON (`t1`.`parent_id` = `t0`.`entity_id`) "+
+			"LEFT JOIN `quote_address` AS `t2` ON (`t2`.`customer_address_id` = `t1`.`entity_id`) "+
+			"LEFT JOIN `sales_order` AS `t3` ON (`t3`.`customer_id` = `t0`.`entity_id`) "+
+			"WHERE (`t0`.`entity_id` IN (3,4))", sqlStr)
+	})
+
+	t.Run("Exec", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `quote_address`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer_address`")).WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_order`")).WillReturnResult(sqlmock.NewResult(0, 3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer_entity`")).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		rowsAffected, err := dp.Exec(context.TODO(), dbc.DB)
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(8), rowsAffected)
+	})
+
+	t.Run("circular", func(t *testing.T) {
+		fks := map[string]ddl.KeyColumnUsageCollection{
+			"catalog_category_entity": {Data: []*ddl.KeyColumnUsage{
+				newFK("FK_CCE_PARENT", "catalog_category_entity", "parent_id", "catalog_category_entity", "entity_id"),
+			}},
+		}
+		_, err := ddl.NewDeletePlan(fks, "catalog_category_entity", "entity_id", 1)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+
+	t.Run("composite", func(t *testing.T) {
+		fks := map[string]ddl.KeyColumnUsageCollection{
+			"b": {Data: []*ddl.KeyColumnUsage{
+				newFK("FK_B_A", "b", "x", "a", "x"),
+				newFK("FK_B_A", "b", "y", "a", "y"),
+			}},
+		}
+		_, err := ddl.NewDeletePlan(fks, "a", "x", 1)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+
+	t.Run("no primary keys", func(t *testing.T) {
+		_, err := ddl.NewDeletePlan(fks, "customer_entity", "entity_id")
+		assert.ErrorIsKind(t, errors.Empty, err)
+	})
+}
@@ -0,0 +1,373 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// DiffKind defines the kind of a schema difference.
+type DiffKind uint8
+
+// Kinds of a schema difference.
+const (
+	// DiffTableMissing reports a table which does not exist in the database.
+	DiffTableMissing DiffKind = iota + 1
+	// DiffColumnMissing reports a column which does not exist in the table.
+	DiffColumnMissing
+	// DiffColumnUnexpected reports a column of the database which is not part
+	// of the metadata. It does not render a statement.
+	DiffColumnUnexpected
+	// DiffColumnType reports a different column type, e.g. int(10) vs
+	// bigint(20).
+	DiffColumnType
+	// DiffColumnNull reports a different nullability.
+	DiffColumnNull
+	// DiffColumnDefault reports a different default value.
+	DiffColumnDefault
+	// DiffIndexMissing reports a column which is not part of the expected
+	// primary key, unique key or index.
+	DiffIndexMissing
+)
+
+var diffKindNames = map[DiffKind]string{
+	DiffTableMissing:     "table missing",
+	DiffColumnMissing:    "column missing",
+	DiffColumnUnexpected: "column unexpected",
+	DiffColumnType:       "column type changed",
+	DiffColumnNull:       "column nullability changed",
+	DiffColumnDefault:    "column default changed",
+	DiffIndexMissing:     "index missing",
+}
+
+func (k DiffKind) String() string {
+	if n, ok := diffKindNames[k]; ok {
+		return n
+	}
+	return "DiffKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Difference describes a single difference between the metadata of Tables and
+// the database.
+type Difference struct {
+	Kind   DiffKind
+	Table  string
+	Column string // empty for DiffTableMissing
+	// Expected contains the column of the metadata, nil for
+	// DiffColumnUnexpected.
+	Expected *Column
+	// Actual contains the column of the database, nil for DiffTableMissing and
+	// DiffColumnMissing.
+	Actual *Column
+	// table contains the expected table for rendering statements.
+	table *Table
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffTableMissing:
+		return d.Table + ": " + d.Kind.String()
+	case DiffColumnType:
+		return d.Table + "." + d.Column + ": " + d.Kind.String() + " from " + d.Expected.ColumnType + " to " + d.Actual.ColumnType
+	case DiffIndexMissing:
+		return d.Table + "." + d.Column + ": " + d.Kind.String() + " " + d.Expected.Key
+	}
+	return d.Table + "." + d.Column + ": " + d.Kind.String()
+}
+
+// Differences contains all differences sorted by table name and column
+// position.
+type Differences []Difference
+
+// Diff compares the tables and columns of the metadata with the database of
+// the connection and returns the differences. An empty result means no drift.
+// Views get skipped. Indexes get compared via the column key (PRI, UNI or
+// MUL) because the metadata contains no index definitions.
+//		diffs, err := tbls.Diff(ctx, dbc.DB)
+//		if err != nil {
+//			return err
+//		}
+//		for _, stmt := range diffs.AlterSQL() {
+//			fmt.Println(stmt)
+//		}
+func (tm *Tables) Diff(ctx context.Context, conn dml.Querier) (Differences, error) {
+	names := tm.Tables()
+	sort.Strings(names)
+
+	expected := make([]*Table, 0, len(names))
+	queryNames := make([]string, 0, len(names))
+	for _, n := range names {
+		t, err := tm.Table(n)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if t.IsView() {
+			continue
+		}
+		expected = append(expected, t)
+		queryNames = append(queryNames, n)
+	}
+	if len(queryNames) == 0 {
+		return nil, nil
+	}
+
+	live, err := LoadColumns(ctx, conn, queryNames...)
+	if err != nil && !errors.NotFound.Match(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	var diffs Differences
+	for _, t := range expected {
+		actual, ok := live[t.Name]
+		if !ok {
+			diffs = append(diffs, Difference{Kind: DiffTableMissing, Table: t.Name, table: t})
+			continue
+		}
+		diffs = append(diffs, diffColumns(t, actual)...)
+	}
+	return diffs, nil
+}
+
+func diffColumns(t *Table, actual Columns) Differences {
+	var diffs Differences
+	for _, ec := range t.Columns {
+		d := Difference{Table: t.Name, Column: ec.Field, Expected: ec, table: t}
+		ac := actual.ByField(ec.Field)
+		if ac.Field == "" {
+			d.Kind = DiffColumnMissing
+			diffs = append(diffs, d)
+			continue
+		}
+		d.Actual = ac
+		if normalizeColumnType(ec.ColumnType) != normalizeColumnType(ac.ColumnType) {
+			d.Kind = DiffColumnType
+			diffs = append(diffs, d)
+		}
+		if ec.IsNull() != ac.IsNull() {
+			d.Kind = DiffColumnNull
+			diffs = append(diffs, d)
+		}
+		if eHas, aHas := hasColumnDefault(ec), hasColumnDefault(ac); eHas != aHas || !strings.EqualFold(columnDefault(ec), columnDefault(ac)) {
+			d.Kind = DiffColumnDefault
+			diffs = append(diffs, d)
+		}
+		if ec.Key != "" && ac.Key == "" {
+			d.Kind = DiffIndexMissing
+			diffs = append(diffs, d)
+		}
+	}
+	for _, ac := range actual {
+		if !t.HasColumn(ac.Field) {
+			diffs = append(diffs, Difference{Kind: DiffColumnUnexpected, Table: t.Name, Column: ac.Field, Actual: ac, table: t})
+		}
+	}
+	return diffs
+}
+
+// AlterSQL renders the statements which apply the metadata to the database:
+// CREATE TABLE for missing tables and one ALTER TABLE per table for missing
+// columns, changed columns and missing indexes. Unexpected columns do not get
+// dropped. Review the statements before executing them, the rendered column
+// definitions contain no character set.
+func (ds Differences) AlterSQL() []string {
+	var stmts []string
+	var tableNames []string
+	byTable := map[string]Differences{}
+	for _, d := range ds {
+		if _, ok := byTable[d.Table]; !ok {
+			tableNames = append(tableNames, d.Table)
+		}
+		byTable[d.Table] = append(byTable[d.Table], d)
+	}
+
+	for _, tn := range tableNames {
+		var specs []string
+		modified := map[string]bool{}
+		var pkCols []string
+		for _, d := range byTable[tn] {
+			switch d.Kind {
+			case DiffTableMissing:
+				stmts = append(stmts, createTableSQL(d.table))
+			case DiffColumnMissing:
+				spec := "ADD COLUMN " + columnDefinition(d.Expected)
+				if prev := previousColumn(d.table, d.Column); prev != "" {
+					spec += " AFTER " + dml.Quoter.Name(prev)
+				}
+				specs = append(specs, spec)
+				if d.Expected.Key != "" {
+					if d.Expected.Key == "PRI" {
+						pkCols = append(pkCols, d.Column)
+					} else {
+						specs = append(specs, indexSpec(tn, d.Expected))
+					}
+				}
+			case DiffColumnType, DiffColumnNull, DiffColumnDefault:
+				if !modified[d.Column] {
+					specs = append(specs, "MODIFY COLUMN "+columnDefinition(d.Expected))
+					modified[d.Column] = true
+				}
+			case DiffIndexMissing:
+				if d.Expected.Key == "PRI" {
+					pkCols = append(pkCols, d.Column)
+				} else {
+					specs = append(specs, indexSpec(tn, d.Expected))
+				}
+			}
+		}
+		if len(pkCols) > 0 {
+			specs = append(specs, "ADD PRIMARY KEY ("+quoteNames(pkCols)+")")
+		}
+		if len(specs) > 0 {
+			stmts = append(stmts, "ALTER TABLE "+dml.Quoter.Name(tn)+" "+strings.Join(specs, ", "))
+		}
+	}
+	return stmts
+}
+
+func createTableSQL(t *Table) string {
+	var buf strings.Builder
+	buf.WriteString("CREATE TABLE ")
+	buf.WriteString(dml.Quoter.Name(t.Name))
+	buf.WriteString(" (")
+	var pkCols []string
+	var indexes []string
+	for i, c := range t.Columns {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(columnDefinition(c))
+		switch c.Key {
+		case "":
+		case "PRI":
+			pkCols = append(pkCols, c.Field)
+		default:
+			indexes = append(indexes, strings.TrimPrefix(indexSpec(t.Name, c), "ADD "))
+		}
+	}
+	if len(pkCols) > 0 {
+		buf.WriteString(", PRIMARY KEY (")
+		buf.WriteString(quoteNames(pkCols))
+		buf.WriteByte(')')
+	}
+	for _, idx := range indexes {
+		buf.WriteString(", ")
+		buf.WriteString(idx)
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+func indexSpec(tableName string, c *Column) string {
+	if c.Key == "UNI" {
+		return "ADD UNIQUE KEY " + dml.Quoter.Name(IndexName("unique", tableName, c.Field)) + " (" + dml.Quoter.Name(c.Field) + ")"
+	}
+	return "ADD INDEX " + dml.Quoter.Name(IndexName("index", tableName, c.Field)) + " (" + dml.Quoter.Name(c.Field) + ")"
+}
+
+func previousColumn(t *Table, field string) string {
+	for i, c := range t.Columns {
+		if c.Field == field && i > 0 {
+			return t.Columns[i-1].Field
+		}
+	}
+	return ""
+}
+
+func quoteNames(names []string) string {
+	q := make([]string, len(names))
+	for i, n := range names {
+		q[i] = dml.Quoter.Name(n)
+	}
+	return strings.Join(q, ",")
+}
+
+// columnDefault returns the default value without the quotes MariaDB adds to
+// string literals.
+// hasColumnDefault reports whether the column has a default value. MariaDB
+// reports the missing default value of a nullable column as the unquoted
+// string NULL, MySQL as nil.
+func hasColumnDefault(c *Column) bool {
+	return c.Default.Valid && !strings.EqualFold(c.Default.Data, "NULL")
+}
+
+// normalizeColumnType removes the display width of the integer types, which
+// MySQL 8.0.19 does not report anymore, and lowers the case.
+//		int(10) unsigned => int unsigned
+func normalizeColumnType(ct string) string {
+	ct = strings.ToLower(ct)
+	i := strings.IndexByte(ct, '(')
+	if i < 0 {
+		return ct
+	}
+	switch ct[:i] {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if j := strings.IndexByte(ct[i:], ')'); j > 0 {
+			return ct[:i] + ct[i+j+1:]
+		}
+	}
+	return ct
+}
+
+func columnDefault(c *Column) string {
+	if !hasColumnDefault(c) {
+		return ""
+	}
+	d := c.Default.Data
+	if len(d) > 1 && d[0] == '\'' && d[len(d)-1] == '\'' {
+		d = strings.Replace(d[1:len(d)-1], "''", "'", -1)
+	}
+	return d
+}
+
+// columnDefinition renders the column definition for CREATE TABLE and ALTER
+// TABLE. Used by Differences.AlterSQL and the statement builders.
+func columnDefinition(c *Column) string {
+	var buf strings.Builder
+	buf.WriteString(dml.Quoter.Name(c.Field))
+	buf.WriteByte(' ')
+	buf.WriteString(c.ColumnType)
+	if c.IsNull() {
+		buf.WriteString(" NULL")
+	} else {
+		buf.WriteString(" NOT NULL")
+	}
+	if d := columnDefault(c); hasColumnDefault(c) {
+		buf.WriteString(" DEFAULT ")
+		lower := strings.ToLower(d)
+		if _, err := strconv.ParseFloat(d, 64); err == nil || strings.HasPrefix(lower, "current_timestamp") {
+			buf.WriteString(d)
+		} else {
+			buf.WriteString(quoteLiteral(d))
+		}
+	}
+	extra := strings.ToLower(c.Extra)
+	if strings.Contains(extra, "auto_increment") {
+		buf.WriteString(" AUTO_INCREMENT")
+	}
+	if idx := strings.Index(extra, "on update"); idx >= 0 {
+		buf.WriteString(" " + strings.ToUpper(c.Extra[idx:]))
+	}
+	if c.Comment != "" {
+		buf.WriteString(" COMMENT ")
+		buf.WriteString(quoteLiteral(c.Comment))
+	}
+	return buf.String()
+}
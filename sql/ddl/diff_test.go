@@ -0,0 +1,111 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestTables_Diff(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	t.Run("no drift", func(t *testing.T) {
+		tbls := newCCD(dbc.DB)
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS WHERE").
+			WillReturnRows(
+				dmltest.MustMockRows(dmltest.WithFile("testdata/core_config_data_columns.csv")))
+
+		diffs, err := tbls.Diff(context.Background(), dbc.DB)
+		assert.NoError(t, err)
+		assert.Len(t, diffs, 0)
+		assert.Nil(t, diffs.AlterSQL())
+	})
+
+	t.Run("no drift with display widths and MariaDB NULL default", func(t *testing.T) {
+		tbls := ddl.MustNewTables(
+			ddl.WithTable(
+				"core_config_data",
+				&ddl.Column{Field: `config_id`, ColumnType: `int unsigned`, Null: `NO`, Key: `PRI`, Extra: `auto_increment`},
+				&ddl.Column{Field: `scope`, ColumnType: `varchar(8)`, Null: `NO`, Key: `MUL`, Default: null.MakeString(`default`)},
+				&ddl.Column{Field: `scope_id`, ColumnType: `INT`, Null: `NO`, Default: null.MakeString(`0`)},
+				&ddl.Column{Field: `path`, ColumnType: `varchar(255)`, Null: `NO`, Default: null.MakeString(`'general'`)},
+				&ddl.Column{Field: `value`, ColumnType: `text`, Null: `YES`, Default: null.MakeString(`NULL`)},
+			),
+		)
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS WHERE").
+			WillReturnRows(
+				dmltest.MustMockRows(dmltest.WithFile("testdata/core_config_data_columns.csv")))
+
+		diffs, err := tbls.Diff(context.Background(), dbc.DB)
+		assert.NoError(t, err)
+		assert.Len(t, diffs, 0, "%v", diffs)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		tbls := ddl.MustNewTables(
+			ddl.WithTable(
+				"core_config_data",
+				&ddl.Column{Field: `config_id`, ColumnType: `int(10) unsigned`, Null: `NO`, Key: `PRI`, Extra: `auto_increment`},
+				&ddl.Column{Field: `scope`, ColumnType: `varchar(8)`, Null: `NO`, Key: `MUL`, Default: null.MakeString(`default`)},
+				&ddl.Column{Field: `scope_id`, ColumnType: `int(10) unsigned`, Null: `NO`, Default: null.MakeString(`0`)},
+				&ddl.Column{Field: `path`, ColumnType: `varchar(255)`, Null: `NO`, Key: `UNI`, Default: null.MakeString(`general`)},
+				&ddl.Column{Field: `updated_at`, ColumnType: `timestamp`, Null: `NO`, Default: null.MakeString(`current_timestamp()`), Extra: `on update current_timestamp()`},
+			),
+			ddl.WithTable(
+				"store_website",
+				&ddl.Column{Field: `website_id`, ColumnType: `smallint(5) unsigned`, Null: `NO`, Key: `PRI`, Extra: `auto_increment`},
+				&ddl.Column{Field: `code`, ColumnType: `varchar(32)`, Null: `YES`, Key: `UNI`},
+				&ddl.Column{Field: `name`, ColumnType: `varchar(64)`, Null: `NO`, Default: null.MakeString(`it's`)},
+			),
+		)
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS WHERE").
+			WillReturnRows(
+				dmltest.MustMockRows(dmltest.WithFile("testdata/core_config_data_columns.csv")))
+
+		diffs, err := tbls.Diff(context.Background(), dbc.DB)
+		assert.NoError(t, err)
+
+		var have []string
+		for _, d := range diffs {
+			have = append(have, d.String())
+		}
+		assert.Exactly(t, []string{
+			"core_config_data.scope_id: column type changed from int(10) unsigned to int(11)",
+			"core_config_data.path: index missing UNI",
+			"core_config_data.updated_at: column missing",
+			"core_config_data.value: column unexpected",
+			"store_website: table missing",
+		}, have)
+		assert.Exactly(t, ddl.DiffColumnMissing, diffs[2].Kind)
+		assert.Nil(t, diffs[2].Actual)
+		assert.Nil(t, diffs[3].Expected)
+
+		assert.Exactly(t, []string{
+			"ALTER TABLE `core_config_data` MODIFY COLUMN `scope_id` int(10) unsigned NOT NULL DEFAULT 0, " +
+				"ADD UNIQUE KEY `CORE_CONFIG_DATA_PATH` (`path`), " +
+				"ADD COLUMN `updated_at` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE CURRENT_TIMESTAMP() AFTER `path`",
+			"CREATE TABLE `store_website` (`website_id` smallint(5) unsigned NOT NULL AUTO_INCREMENT, " +
+				"`code` varchar(32) NULL, `name` varchar(64) NOT NULL DEFAULT 'it''s', " +
+				"PRIMARY KEY (`website_id`), UNIQUE KEY `STORE_WEBSITE_CODE` (`code`))",
+		}, diffs.AlterSQL())
+	})
+}
@@ -31,6 +31,7 @@ func init() {
 }
 
 func TestLoadForeignKeys_Integration(t *testing.T) {
+	dmltest.SkipUnsupported(t, dmltest.FeatureForeignKeys)
 	dbc := dmltest.MustConnectDB(t)
 	defer dmltest.Close(t, dbc)
 	defer dmltest.SQLDumpLoad(t, "testdata/testLoadForeignKeys*.sql", nil).Deferred()
@@ -83,6 +84,7 @@ func TestLoadForeignKeys_Integration(t *testing.T) {
 }
 
 func TestLoadKeyRelationships(t *testing.T) {
+	dmltest.SkipUnsupported(t, dmltest.FeatureForeignKeys)
 	dbc := dmltest.MustConnectDB(t)
 	defer dmltest.Close(t, dbc)
 	defer dmltest.SQLDumpLoad(t, "testdata/testLoadForeignKeys*.sql", nil).Deferred()
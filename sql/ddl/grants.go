@@ -0,0 +1,308 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// tablePrivileges contains the privileges which can be granted on table level.
+// Global and database privileges like SUPER, FILE or GRANT OPTION are not
+// supported by GrantMinimal.
+var tablePrivileges = map[string]bool{
+	"ALTER":       true,
+	"CREATE":      true,
+	"CREATE VIEW": true,
+	"DELETE":      true,
+	"DROP":        true,
+	"INDEX":       true,
+	"INSERT":      true,
+	"REFERENCES":  true,
+	"SELECT":      true,
+	"SHOW VIEW":   true,
+	"TRIGGER":     true,
+	"UPDATE":      true,
+}
+
+// User defines a MySQL account for provisioning. The defaults are secure: TLS
+// is required, a password must be set and the host must not contain
+// wildcards.
+type User struct {
+	Name string
+	// Host must be a host name, an IP address or an IP address with netmask.
+	// The wildcards % and _ are not allowed.
+	Host string
+	// Password must not be empty. A backslash is not supported because its
+	// escaping depends on the sql_mode NO_BACKSLASH_ESCAPES.
+	Password string
+	// RequireX509 requires a valid client certificate instead of only an
+	// encrypted connection.
+	RequireX509 bool
+	// AllowInsecureTransport allows unencrypted connections. Use only for
+	// local development.
+	AllowInsecureTransport bool
+	// MaxUserConnections limits the simultaneous connections. Zero applies
+	// the global max_user_connections.
+	MaxUserConnections uint
+}
+
+// quoteAccountString quotes s as a MySQL string literal. Single quotes get
+// doubled which works independent of the sql_mode.
+func quoteAccountString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// account validates and returns the quoted account name, e.g.
+// 'app'@'10.0.0.5'.
+func (u User) account() (string, error) {
+	if u.Name == "" {
+		return "", errors.Empty.Newf("[ddl] User name cannot be empty")
+	}
+	if u.Host == "" {
+		return "", errors.Empty.Newf("[ddl] User %q host cannot be empty", u.Name)
+	}
+	if strings.ContainsAny(u.Host, "%_") {
+		return "", errors.NotAllowed.Newf("[ddl] User %q host %q must not contain the wildcards %% or _", u.Name, u.Host)
+	}
+	if strings.ContainsAny(u.Name+u.Host, "\\\x00") {
+		return "", errors.NotValid.Newf("[ddl] User %q or host %q contains invalid characters", u.Name, u.Host)
+	}
+	return quoteAccountString(u.Name) + "@" + quoteAccountString(u.Host), nil
+}
+
+// CreateSQL returns the CREATE USER statement. An already existing user does
+// not get modified.
+func (u User) CreateSQL() (string, error) {
+	acc, err := u.account()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if u.Password == "" {
+		return "", errors.Empty.Newf("[ddl] User %q password cannot be empty", u.Name)
+	}
+	if strings.ContainsAny(u.Password, "\\\x00") {
+		return "", errors.NotValid.Newf("[ddl] User %q password must not contain a backslash or NUL byte", u.Name)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("CREATE USER IF NOT EXISTS ")
+	buf.WriteString(acc)
+	buf.WriteString(" IDENTIFIED BY ")
+	buf.WriteString(quoteAccountString(u.Password))
+	switch {
+	case u.RequireX509:
+		buf.WriteString(" REQUIRE X509")
+	case !u.AllowInsecureTransport:
+		buf.WriteString(" REQUIRE SSL")
+	}
+	if u.MaxUserConnections > 0 {
+		buf.WriteString(" WITH MAX_USER_CONNECTIONS ")
+		buf.WriteString(strconv.FormatUint(uint64(u.MaxUserConnections), 10))
+	}
+	return buf.String(), nil
+}
+
+// GrantSQL returns one GRANT statement per table. A table name can be
+// qualified with its database name, e.g. "shop.sales_order". Only table level
+// privileges are allowed; without privileges SELECT gets granted. Wildcard
+// tables, ALL PRIVILEGES and GRANT OPTION are rejected.
+func (u User) GrantSQL(tables []string, privileges ...string) ([]string, error) {
+	acc, err := u.account()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(tables) == 0 {
+		return nil, errors.Empty.Newf("[ddl] User %q GrantSQL requires at least one table", u.Name)
+	}
+	if len(privileges) == 0 {
+		privileges = []string{"SELECT"}
+	}
+	privs := make([]string, len(privileges))
+	for i, p := range privileges {
+		p = strings.ToUpper(strings.Join(strings.Fields(p), " "))
+		if !tablePrivileges[p] {
+			return nil, errors.NotAllowed.Newf("[ddl] User %q privilege %q is not allowed on table level", u.Name, privileges[i])
+		}
+		privs[i] = p
+	}
+	privList := strings.Join(privs, ", ")
+
+	stmts := make([]string, 0, len(tables))
+	for _, tbl := range tables {
+		if tbl == "" || strings.ContainsAny(tbl, "*%") {
+			return nil, errors.NotAllowed.Newf("[ddl] User %q table %q must not be empty or contain wildcards", u.Name, tbl)
+		}
+		var schema string
+		if dot := strings.IndexByte(tbl, '.'); dot > 0 {
+			schema, tbl = tbl[:dot], tbl[dot+1:]
+		}
+		stmts = append(stmts, "GRANT "+privList+" ON "+dml.Quoter.QualifierName(schema, tbl)+" TO "+acc)
+	}
+	return stmts, nil
+}
+
+// CreateUser creates the user, see User.CreateSQL.
+func CreateUser(ctx context.Context, db dml.Execer, u User) error {
+	stmt, err := u.CreateSQL()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		return errors.Wrapf(err, "[ddl] Failed to create user %q@%q", u.Name, u.Host)
+	}
+	return nil
+}
+
+// GrantMinimal grants the privileges on the tables to the user, see
+// User.GrantSQL. It stops at the first failing statement.
+func GrantMinimal(ctx context.Context, db dml.Execer, u User, tables []string, privileges ...string) error {
+	stmts, err := u.GrantSQL(tables, privileges...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "[ddl] Failed to execute %q", stmt)
+		}
+	}
+	return nil
+}
+
+var grantRE = regexp.MustCompile(`^GRANT (.+?) ON (\S+) TO (\S+)(.*)$`)
+
+// Grant represents a single row of SHOW GRANTS.
+type Grant struct {
+	// Privileges contains the privileges in upper case, e.g. SELECT or
+	// ALL PRIVILEGES. Column privileges contain the column list.
+	Privileges []string
+	// Object contains the quoted database and table, e.g. `shop`.`sales_order`
+	// or *.* for global privileges.
+	Object      string
+	GrantOption bool
+	// SQL contains the complete GRANT statement as returned by the server.
+	SQL string
+}
+
+// IsGlobal returns true if the grant applies to all databases.
+func (g Grant) IsGlobal() bool { return g.Object == "*.*" }
+
+// Grants contains all grants of a user.
+type Grants []Grant
+
+// Has reports whether the privilege has been granted on the object, directly
+// or via ALL PRIVILEGES. The object must be quoted like in Grant.Object.
+func (gs Grants) Has(privilege, object string) bool {
+	privilege = strings.ToUpper(privilege)
+	for _, g := range gs {
+		if g.Object != object && !g.IsGlobal() {
+			continue
+		}
+		for _, p := range g.Privileges {
+			if p == privilege || p == "ALL PRIVILEGES" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Excessive returns the grants exceeding the minimal privileges of
+// GrantMinimal: global grants except USAGE, ALL PRIVILEGES and GRANT OPTION.
+func (gs Grants) Excessive() Grants {
+	var ret Grants
+	for _, g := range gs {
+		usageOnly := len(g.Privileges) == 1 && g.Privileges[0] == "USAGE"
+		if g.GrantOption || (g.IsGlobal() && !usageOnly) {
+			ret = append(ret, g)
+			continue
+		}
+		for _, p := range g.Privileges {
+			if p == "ALL PRIVILEGES" {
+				ret = append(ret, g)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// parseGrant parses a row of SHOW GRANTS. Role grants like `GRANT r1 TO u1`
+// contain no object.
+func parseGrant(stmt string) Grant {
+	g := Grant{SQL: stmt}
+	m := grantRE.FindStringSubmatch(stmt)
+	if m == nil {
+		return g
+	}
+	g.Object = m[2]
+	g.GrantOption = strings.Contains(m[4], "WITH GRANT OPTION")
+	var depth int
+	start := 0
+	privs := m[1] + ","
+	for i := 0; i < len(privs); i++ {
+		switch privs[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				p := strings.TrimSpace(privs[start:i])
+				if paren := strings.IndexByte(p, '('); paren > 0 {
+					p = strings.ToUpper(p[:paren]) + p[paren:] // keep column names
+				} else {
+					p = strings.ToUpper(p)
+				}
+				g.Privileges = append(g.Privileges, p)
+				start = i + 1
+			}
+		}
+	}
+	return g
+}
+
+// ShowGrants loads the grants of the user with SHOW GRANTS. Only the fields
+// Name and Host of User are used.
+func ShowGrants(ctx context.Context, db dml.Querier, u User) (_ Grants, err error) {
+	acc, err := u.account()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rows, err := db.QueryContext(ctx, "SHOW GRANTS FOR "+acc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[ddl] Failed to show grants for %q@%q", u.Name, u.Host)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+
+	var gs Grants
+	for rows.Next() {
+		var stmt string
+		if err = rows.Scan(&stmt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		gs = append(gs, parseGrant(stmt))
+	}
+	return gs, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,133 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestUser_CreateSQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("secure defaults", func(t *testing.T) {
+		stmt, err := ddl.User{Name: "app", Host: "10.0.0.5", Password: "it's secret"}.CreateSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "CREATE USER IF NOT EXISTS 'app'@'10.0.0.5' IDENTIFIED BY 'it''s secret' REQUIRE SSL", stmt)
+	})
+	t.Run("X509 and max connections", func(t *testing.T) {
+		stmt, err := ddl.User{Name: "app", Host: "db.internal", Password: "pw", RequireX509: true, MaxUserConnections: 20}.CreateSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "CREATE USER IF NOT EXISTS 'app'@'db.internal' IDENTIFIED BY 'pw' REQUIRE X509 WITH MAX_USER_CONNECTIONS 20", stmt)
+	})
+	t.Run("insecure transport", func(t *testing.T) {
+		stmt, err := ddl.User{Name: "dev", Host: "localhost", Password: "pw", AllowInsecureTransport: true}.CreateSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "CREATE USER IF NOT EXISTS 'dev'@'localhost' IDENTIFIED BY 'pw'", stmt)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := ddl.User{Host: "localhost", Password: "pw"}.CreateSQL()
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = ddl.User{Name: "app", Host: "%", Password: "pw"}.CreateSQL()
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+		_, err = ddl.User{Name: "app", Host: "10.0.0._", Password: "pw"}.CreateSQL()
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+		_, err = ddl.User{Name: "app", Host: "localhost"}.CreateSQL()
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = ddl.User{Name: "app", Host: "localhost", Password: `a\b`}.CreateSQL()
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+func TestUser_GrantSQL(t *testing.T) {
+	t.Parallel()
+	u := ddl.User{Name: "app", Host: "10.0.0.5"}
+
+	t.Run("default SELECT", func(t *testing.T) {
+		stmts, err := u.GrantSQL([]string{"shop.sales_order", "core_config_data"})
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{
+			"GRANT SELECT ON `shop`.`sales_order` TO 'app'@'10.0.0.5'",
+			"GRANT SELECT ON `core_config_data` TO 'app'@'10.0.0.5'",
+		}, stmts)
+	})
+	t.Run("normalized privileges", func(t *testing.T) {
+		stmts, err := u.GrantSQL([]string{"shop.sales_order"}, "select", "insert", " show   view")
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{"GRANT SELECT, INSERT, SHOW VIEW ON `shop`.`sales_order` TO 'app'@'10.0.0.5'"}, stmts)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := u.GrantSQL(nil)
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = u.GrantSQL([]string{"shop.*"})
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+		_, err = u.GrantSQL([]string{"sales_order"}, "ALL PRIVILEGES")
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+		_, err = u.GrantSQL([]string{"sales_order"}, "GRANT OPTION")
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+		_, err = u.GrantSQL([]string{"sales_order"}, "SUPER")
+		assert.ErrorIsKind(t, errors.NotAllowed, err)
+	})
+}
+
+func TestCreateUser_GrantMinimal(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	u := ddl.User{Name: "app", Host: "10.0.0.5", Password: "pw"}
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE USER IF NOT EXISTS 'app'@'10.0.0.5' IDENTIFIED BY 'pw' REQUIRE SSL")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("GRANT SELECT, UPDATE ON `shop`.`sales_order` TO 'app'@'10.0.0.5'")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("GRANT SELECT, UPDATE ON `shop`.`sales_order_item` TO 'app'@'10.0.0.5'")).
+		WillReturnError(errors.AlreadyClosed.Newf("Connection gone"))
+
+	assert.NoError(t, ddl.CreateUser(context.TODO(), dbc.DB, u))
+	err := ddl.GrantMinimal(context.TODO(), dbc.DB, u, []string{"shop.sales_order", "shop.sales_order_item"}, "SELECT", "UPDATE")
+	assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+}
+
+func TestShowGrants(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SHOW GRANTS FOR 'app'@'10.0.0.5'")).
+		WillReturnRows(sqlmock.NewRows([]string{"Grants for app@10.0.0.5"}).
+			AddRow("GRANT USAGE ON *.* TO `app`@`10.0.0.5`").
+			AddRow("GRANT SELECT, UPDATE (`status`, `state`) ON `shop`.`sales_order` TO `app`@`10.0.0.5`").
+			AddRow("GRANT ALL PRIVILEGES ON `shop`.`quote` TO `app`@`10.0.0.5` WITH GRANT OPTION"))
+
+	gs, err := ddl.ShowGrants(context.TODO(), dbc.DB, ddl.User{Name: "app", Host: "10.0.0.5"})
+	assert.NoError(t, err)
+	assert.Len(t, gs, 3)
+	assert.True(t, gs[0].IsGlobal())
+	assert.Exactly(t, []string{"SELECT", "UPDATE (`status`, `state`)"}, gs[1].Privileges)
+	assert.Exactly(t, "`shop`.`sales_order`", gs[1].Object)
+	assert.True(t, gs[2].GrantOption)
+
+	assert.True(t, gs.Has("select", "`shop`.`sales_order`"))
+	assert.False(t, gs.Has("DELETE", "`shop`.`sales_order`"))
+	assert.True(t, gs.Has("DELETE", "`shop`.`quote`"))
+
+	ex := gs.Excessive()
+	assert.Len(t, ex, 1)
+	assert.Exactly(t, "`shop`.`quote`", ex[0].Object)
+}
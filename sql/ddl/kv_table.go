@@ -0,0 +1,272 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"sync"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/storage/null"
+)
+
+// KVTableOptions configures NewKVTable.
+type KVTableOptions struct {
+	TableName string
+	// PathColumn contains the key. Must have a primary or unique key.
+	// Defaults to path.
+	PathColumn string
+	// ValueColumn contains the value. Defaults to value.
+	ValueColumn string
+	// DisableCache disables the in-memory read cache.
+	DisableCache bool
+}
+
+const (
+	kvStmtGet = iota
+	kvStmtSet
+	kvStmtDelete
+	kvStmtMax
+)
+
+// KVTable provides a key-value store on top of a table with a path and a value
+// column, like core_config_data. The statements get prepared on first use and
+// prepared again after they failed, for example due to a lost connection.
+// Values read by Get get cached in memory. Set and Delete write through to the
+// table and update the cache. Changes made by other processes are only visible
+// after calling Invalidate. Safe for concurrent use.
+type KVTable struct {
+	dbc     *dml.ConnPool
+	o       KVTableOptions
+	queries [kvStmtMax]string
+
+	stmtMu sync.Mutex
+	stmts  [kvStmtMax]*dml.DBR
+
+	cacheMu sync.RWMutex
+	cache   map[string]null.String
+	// gens counts the changes of each path and epoch the calls of Invalidate
+	// without arguments, so Get does not cache a value which has been loaded
+	// before a concurrent Set, Delete or Invalidate.
+	gens  map[string]uint64
+	epoch uint64
+}
+
+// NewKVTable creates a new KVTable for the table in the options.
+func NewKVTable(dbc *dml.ConnPool, o KVTableOptions) (*KVTable, error) {
+	if o.PathColumn == "" {
+		o.PathColumn = "path"
+	}
+	if o.ValueColumn == "" {
+		o.ValueColumn = "value"
+	}
+	if err := validateIdentifiers(o.TableName, o.PathColumn, o.ValueColumn); err != nil {
+		return nil, errors.Wrapf(err, "[ddl] NewKVTable invalid identifier of table %q", o.TableName)
+	}
+
+	kv := &KVTable{
+		dbc:   dbc,
+		o:     o,
+		cache: make(map[string]null.String),
+		gens:  make(map[string]uint64),
+	}
+	var err error
+	if kv.queries[kvStmtGet], _, err = dml.NewSelect(o.ValueColumn).From(o.TableName).
+		Where(dml.Column(o.PathColumn).PlaceHolder()).ToSQL(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if kv.queries[kvStmtSet], _, err = dml.NewInsert(o.TableName).AddColumns(o.PathColumn, o.ValueColumn).
+		AddOnDuplicateKey(dml.Column(o.ValueColumn).Values()).BuildValues().ToSQL(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if kv.queries[kvStmtDelete], _, err = dml.NewDelete(o.TableName).
+		Where(dml.Column(o.PathColumn).PlaceHolder()).ToSQL(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return kv, nil
+}
+
+// stmt returns a copy of the prepared statement, which can be used
+// concurrently. The statement gets prepared if it does not yet exist.
+func (kv *KVTable) stmt(ctx context.Context, idx int) (prepared, dbr *dml.DBR) {
+	kv.stmtMu.Lock()
+	defer kv.stmtMu.Unlock()
+	if kv.stmts[idx] == nil {
+		kv.stmts[idx] = kv.dbc.WithPrepare(ctx, kv.queries[idx])
+	}
+	return kv.stmts[idx], kv.stmts[idx].Clone()
+}
+
+// discard closes the failed prepared statement, so the next call prepares it
+// again.
+func (kv *KVTable) discard(idx int, prepared *dml.DBR) {
+	kv.stmtMu.Lock()
+	defer kv.stmtMu.Unlock()
+	if kv.stmts[idx] == prepared {
+		_ = prepared.Close()
+		kv.stmts[idx] = nil
+	}
+}
+
+// run executes fn with the prepared statement. If fn fails, the statement gets
+// prepared again. If the connection has been lost, fn runs a second time.
+func (kv *KVTable) run(ctx context.Context, idx int, fn func(*dml.DBR) error) (err error) {
+	for i := 0; i < 2; i++ {
+		prepared, dbr := kv.stmt(ctx, idx)
+		if err = fn(dbr); err == nil {
+			return nil
+		}
+		kv.discard(idx, prepared)
+		if c := errors.Cause(err); c != driver.ErrBadConn && c != sql.ErrConnDone {
+			break
+		}
+	}
+	return errors.WithStack(err)
+}
+
+// Get returns the value of a path. Found is false if the path does not exist
+// in the table.
+func (kv *KVTable) Get(ctx context.Context, path string) (value null.String, found bool, err error) {
+	var gen, epoch uint64
+	if !kv.o.DisableCache {
+		kv.cacheMu.RLock()
+		value, found = kv.cache[path]
+		gen, epoch = kv.gens[path], kv.epoch
+		kv.cacheMu.RUnlock()
+		if found {
+			return value, true, nil
+		}
+	}
+
+	err = kv.run(ctx, kvStmtGet, func(dbr *dml.DBR) (err error) {
+		value, found, err = dbr.LoadNullString(ctx, path)
+		return err
+	})
+	if err != nil {
+		return null.String{}, false, errors.Wrapf(err, "[ddl] KVTable.Get failed for path %q in table %q", path, kv.o.TableName)
+	}
+	if found && !kv.o.DisableCache {
+		kv.cacheMu.Lock()
+		if kv.gens[path] == gen && kv.epoch == epoch {
+			kv.cache[path] = value
+		}
+		kv.cacheMu.Unlock()
+	}
+	return value, found, nil
+}
+
+// String returns the value of a path. A NULL value returns an empty string.
+func (kv *KVTable) String(ctx context.Context, path string) (value string, found bool, err error) {
+	v, found, err := kv.Get(ctx, path)
+	return v.Data, found, err
+}
+
+// Int returns the value of a path as an integer.
+func (kv *KVTable) Int(ctx context.Context, path string) (value int64, found bool, err error) {
+	v, found, err := kv.Get(ctx, path)
+	if err != nil || !found || !v.Valid {
+		return 0, found, err
+	}
+	value, err = strconv.ParseInt(v.Data, 10, 64)
+	if err != nil {
+		return 0, true, errors.NotValid.New(err, "[ddl] KVTable.Int cannot parse value of path %q", path)
+	}
+	return value, true, nil
+}
+
+// Bool returns the value of a path as a boolean. See strconv.ParseBool for the
+// allowed values.
+func (kv *KVTable) Bool(ctx context.Context, path string) (value bool, found bool, err error) {
+	v, found, err := kv.Get(ctx, path)
+	if err != nil || !found || !v.Valid {
+		return false, found, err
+	}
+	value, err = strconv.ParseBool(v.Data)
+	if err != nil {
+		return false, true, errors.NotValid.New(err, "[ddl] KVTable.Bool cannot parse value of path %q", path)
+	}
+	return value, true, nil
+}
+
+// Set inserts or updates the value of a path.
+func (kv *KVTable) Set(ctx context.Context, path string, value null.String) error {
+	err := kv.run(ctx, kvStmtSet, func(dbr *dml.DBR) error {
+		_, err := dbr.ExecContext(ctx, path, value)
+		return err
+	})
+	if err != nil {
+		kv.Invalidate(path)
+		return errors.Wrapf(err, "[ddl] KVTable.Set failed for path %q in table %q", path, kv.o.TableName)
+	}
+	if !kv.o.DisableCache {
+		kv.cacheMu.Lock()
+		kv.gens[path]++
+		kv.cache[path] = value
+		kv.cacheMu.Unlock()
+	}
+	return nil
+}
+
+// Delete removes a path.
+func (kv *KVTable) Delete(ctx context.Context, path string) error {
+	err := kv.run(ctx, kvStmtDelete, func(dbr *dml.DBR) error {
+		_, err := dbr.ExecContext(ctx, path)
+		return err
+	})
+	kv.Invalidate(path)
+	if err != nil {
+		return errors.Wrapf(err, "[ddl] KVTable.Delete failed for path %q in table %q", path, kv.o.TableName)
+	}
+	return nil
+}
+
+// Invalidate removes the paths from the cache, so the next Get reads them
+// from the table. No argument clears the whole cache.
+func (kv *KVTable) Invalidate(paths ...string) {
+	kv.cacheMu.Lock()
+	defer kv.cacheMu.Unlock()
+	if len(paths) == 0 {
+		kv.cache = make(map[string]null.String)
+		kv.gens = make(map[string]uint64)
+		kv.epoch++
+		return
+	}
+	for _, p := range paths {
+		delete(kv.cache, p)
+		kv.gens[p]++
+	}
+}
+
+// Close closes the prepared statements. A later call to Get, Set or Delete
+// prepares the statements again.
+func (kv *KVTable) Close() error {
+	kv.stmtMu.Lock()
+	defer kv.stmtMu.Unlock()
+	var err error
+	for i, dbr := range kv.stmts {
+		if dbr == nil {
+			continue
+		}
+		if cErr := dbr.Close(); cErr != nil && err == nil {
+			err = errors.WithStack(cErr)
+		}
+		kv.stmts[i] = nil
+	}
+	return err
+}
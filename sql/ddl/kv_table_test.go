@@ -0,0 +1,153 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestKVTable(t *testing.T) {
+	const (
+		sqlGet    = "SELECT `value` FROM `core_config_data` WHERE (`path` = ?)"
+		sqlSet    = "INSERT INTO `core_config_data` (`path`,`value`) VALUES (?,?) ON DUPLICATE KEY UPDATE `value`=VALUES(`value`)"
+		sqlDelete = "DELETE FROM `core_config_data` WHERE (`path` = ?)"
+	)
+	ctx := context.TODO()
+
+	t.Run("invalid identifier", func(t *testing.T) {
+		_, err := ddl.NewKVTable(nil, ddl.KVTableOptions{TableName: "core`config"})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+
+	t.Run("get set delete with cache", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		kv, err := ddl.NewKVTable(dbc, ddl.KVTableOptions{TableName: "core_config_data"})
+		assert.NoError(t, err)
+
+		prepGet := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlGet))
+		prepGet.ExpectQuery().WithArgs("web/cookie/lifetime").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("3600"))
+
+		v, found, err := kv.Int(ctx, "web/cookie/lifetime")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, int64(3600), v)
+
+		// second read hits the cache
+		s, found, err := kv.String(ctx, "web/cookie/lifetime")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, "3600", s)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSet)).
+			ExpectExec().WithArgs("web/cookie/secure", "1").WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, kv.Set(ctx, "web/cookie/secure", null.MakeString("1")))
+
+		b, found, err := kv.Bool(ctx, "web/cookie/secure")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.True(t, b)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlDelete)).
+			ExpectExec().WithArgs("web/cookie/secure").WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, kv.Delete(ctx, "web/cookie/secure"))
+
+		prepGet.ExpectQuery().WithArgs("web/cookie/secure").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}))
+		_, found, err = kv.Bool(ctx, "web/cookie/secure")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		kv.Invalidate()
+		prepGet.ExpectQuery().WithArgs("web/cookie/lifetime").
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("x"))
+		_, found, err = kv.Int(ctx, "web/cookie/lifetime")
+		assert.True(t, found)
+		assert.ErrorIsKind(t, errors.NotValid, err)
+
+		assert.NoError(t, kv.Close())
+	})
+
+	t.Run("concurrent Set wins over a slow Get", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		kv, err := ddl.NewKVTable(dbc, ddl.KVTableOptions{TableName: "core_config_data"})
+		assert.NoError(t, err)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlGet)).
+			ExpectQuery().WithArgs("a/b/c").WillDelayFor(100 * time.Millisecond).
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("old"))
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSet)).
+			ExpectExec().WithArgs("a/b/c", "new").WillReturnResult(sqlmock.NewResult(0, 1))
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			v, _, err := kv.String(ctx, "a/b/c")
+			assert.NoError(t, err)
+			assert.Exactly(t, "old", v)
+		}()
+		time.Sleep(20 * time.Millisecond) // Get waits for the delayed rows
+		assert.NoError(t, kv.Set(ctx, "a/b/c", null.MakeString("new")))
+		<-done
+
+		v, _, err := kv.String(ctx, "a/b/c")
+		assert.NoError(t, err)
+		assert.Exactly(t, "new", v, "stale value of Get must not be cached")
+		assert.NoError(t, kv.Close())
+		// Get and Set ran concurrently, hence the pool closes two connections.
+		dbMock.ExpectClose()
+	})
+
+	t.Run("statement gets prepared again", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		kv, err := ddl.NewKVTable(dbc, ddl.KVTableOptions{TableName: "core_config_data", DisableCache: true})
+		assert.NoError(t, err)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlGet)).
+			ExpectQuery().WithArgs("a/b/c").WillReturnError(sql.ErrConnDone)
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlGet)).
+			ExpectQuery().WithArgs("a/b/c").WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(nil))
+
+		v, found, err := kv.Get(ctx, "a/b/c")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.False(t, v.Valid)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlDelete)).
+			ExpectExec().WithArgs("a/b/c").WillReturnError(errors.AlreadyClosed.Newf("Connection gone"))
+		err = kv.Delete(ctx, "a/b/c")
+		assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlDelete)).
+			ExpectExec().WithArgs("a/b/c").WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, kv.Delete(ctx, "a/b/c"))
+	})
+}
@@ -0,0 +1,257 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// LintRule identifies a check of function Lint.
+type LintRule string
+
+// Rules checked by function Lint.
+const (
+	LintMissingPrimaryKey   LintRule = "missing_primary_key"
+	LintUTF8MB3             LintRule = "utf8mb3"
+	LintMissingTimestamps   LintRule = "missing_timestamps"
+	LintUnindexedForeignKey LintRule = "unindexed_foreign_key"
+	LintWideVarchar         LintRule = "wide_varchar"
+)
+
+// LintOptions configures function Lint. The zero value applies the defaults.
+type LintOptions struct {
+	// Skip disables rules for all tables.
+	Skip []LintRule
+	// SkipTables disables rules per table name. An empty rule slice disables
+	// all rules for the table.
+	SkipTables map[string][]LintRule
+	// CreatedAtColumn defaults to created_at.
+	CreatedAtColumn string
+	// UpdatedAtColumn defaults to updated_at.
+	UpdatedAtColumn string
+	// MaxVarcharLength defines the maximum number of characters of a VARCHAR
+	// column. Defaults to 768, the maximum length of an index on a utf8mb4
+	// column with 3072 bytes index prefix limit.
+	MaxVarcharLength int64
+	// ForeignKeys contains the foreign keys per table name as returned by
+	// LoadKeyColumnUsage. If nil and the Tables have a connection pool, the
+	// foreign keys get loaded from the database. Otherwise the rule
+	// LintUnindexedForeignKey gets skipped.
+	ForeignKeys map[string]KeyColumnUsageCollection
+}
+
+func (o LintOptions) skipsAll(r LintRule) bool {
+	for _, s := range o.Skip {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (o LintOptions) skips(table string, r LintRule) bool {
+	if o.skipsAll(r) {
+		return true
+	}
+	rules, ok := o.SkipTables[table]
+	if ok && len(rules) == 0 {
+		return true
+	}
+	for _, s := range rules {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+// LintFinding describes a single issue found by function Lint.
+type LintFinding struct {
+	Rule  LintRule
+	Table string
+	// Column is empty when the finding applies to the whole table.
+	Column  string
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (lf LintFinding) String() string {
+	if lf.Column == "" {
+		return fmt.Sprintf("%s: %s: %s", lf.Table, lf.Rule, lf.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s: %s", lf.Table, lf.Column, lf.Rule, lf.Message)
+}
+
+// LintFindings a list of findings sorted by table and column name.
+type LintFindings []LintFinding
+
+// Rule returns all findings of the rule.
+func (lfs LintFindings) Rule(r LintRule) LintFindings {
+	var ret LintFindings
+	for _, lf := range lfs {
+		if lf.Rule == r {
+			ret = append(ret, lf)
+		}
+	}
+	return ret
+}
+
+// String writes each finding on its own line.
+func (lfs LintFindings) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	for _, lf := range lfs {
+		buf.WriteString(lf.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// Err returns an error of kind NotValid containing all findings or nil if
+// there are no findings. Useful to fail a test in a CI pipeline:
+//		lfs, err := ddl.Lint(ctx, tbls, ddl.LintOptions{})
+//		assert.NoError(t, err)
+//		assert.NoError(t, lfs.Err())
+func (lfs LintFindings) Err() error {
+	if len(lfs) == 0 {
+		return nil
+	}
+	return errors.NotValid.Newf("[ddl] Lint found %d issues:\n%s", len(lfs), lfs.String())
+}
+
+// Lint checks the tables for common schema issues: missing primary keys, utf8
+// (alias utf8mb3) instead of utf8mb4 character sets, missing created_at and
+// updated_at columns, foreign key columns without an index and overly wide
+// VARCHAR columns. Views get only checked for column issues. The tables can be
+// loaded from the database, generated or hand-written. Lint uses only the
+// database connection to load the foreign keys, if LintOptions.ForeignKeys is
+// nil.
+func Lint(ctx context.Context, tm *Tables, o LintOptions) (LintFindings, error) {
+	if o.CreatedAtColumn == "" {
+		o.CreatedAtColumn = "created_at"
+	}
+	if o.UpdatedAtColumn == "" {
+		o.UpdatedAtColumn = "updated_at"
+	}
+	if o.MaxVarcharLength == 0 {
+		o.MaxVarcharLength = 768
+	}
+
+	tm.mu.RLock()
+	tbls := make([]*Table, 0, len(tm.tm))
+	for _, t := range tm.tm {
+		tbls = append(tbls, t)
+	}
+	dcp := tm.dcp
+	tm.mu.RUnlock()
+
+	fks := o.ForeignKeys
+	if fks == nil && dcp != nil && !o.skipsAll(LintUnindexedForeignKey) {
+		var err error
+		if fks, err = LoadKeyColumnUsage(ctx, dcp.DB); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	var lfs LintFindings
+	for _, t := range tbls {
+		lfs = append(lfs, lintTable(t, fks[t.Name], o)...)
+	}
+	sort.SliceStable(lfs, func(i, j int) bool {
+		if lfs[i].Table != lfs[j].Table {
+			return lfs[i].Table < lfs[j].Table
+		}
+		return lfs[i].Column < lfs[j].Column
+	})
+	return lfs, nil
+}
+
+func lintTable(t *Table, fks KeyColumnUsageCollection, o LintOptions) (lfs LintFindings) {
+	add := func(r LintRule, col, format string, args ...interface{}) {
+		if !o.skips(t.Name, r) {
+			lfs = append(lfs, LintFinding{Rule: r, Table: t.Name, Column: col, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	if !t.IsView() {
+		if len(t.Columns.PrimaryKeys()) == 0 {
+			add(LintMissingPrimaryKey, "", "table has no primary key")
+		}
+		if !t.Columns.Contains(o.CreatedAtColumn) {
+			add(LintMissingTimestamps, "", "column %q is missing", o.CreatedAtColumn)
+		}
+		if !t.Columns.Contains(o.UpdatedAtColumn) {
+			add(LintMissingTimestamps, "", "column %q is missing", o.UpdatedAtColumn)
+		}
+		if t.TableCollation.Valid && isUTF8MB3(t.TableCollation.Data) {
+			add(LintUTF8MB3, "", "table collation %q should be utf8mb4", t.TableCollation.Data)
+		}
+	}
+
+	for _, c := range t.Columns {
+		switch ct := strings.ToLower(c.ColumnType); {
+		case c.CharacterSet.Valid && isUTF8MB3(c.CharacterSet.Data):
+			add(LintUTF8MB3, c.Field, "column character set %q should be utf8mb4", c.CharacterSet.Data)
+		case c.Collation.Valid && isUTF8MB3(c.Collation.Data):
+			add(LintUTF8MB3, c.Field, "column collation %q should be utf8mb4", c.Collation.Data)
+		case strings.Contains(ct, "utf8") && !strings.Contains(ct, "utf8mb4"):
+			// hand-written columns without information_schema data
+			add(LintUTF8MB3, c.Field, "column type %q should use utf8mb4", c.ColumnType)
+		}
+		if l, ok := varcharLength(c); ok && l > o.MaxVarcharLength {
+			add(LintWideVarchar, c.Field, "VARCHAR length %d exceeds %d, consider TEXT or a shorter length", l, o.MaxVarcharLength)
+		}
+	}
+
+	for _, fk := range fks.Data {
+		c := t.Columns.ByField(fk.ColumnName)
+		if c == nil || c.Key != "" {
+			continue // first column of an index has PRI, UNI or MUL
+		}
+		add(LintUnindexedForeignKey, fk.ColumnName, "foreign key %q has no index starting with this column", fk.ConstraintName)
+	}
+	return lfs
+}
+
+func isUTF8MB3(collation string) bool {
+	cl := strings.ToLower(collation)
+	return cl == "utf8" || strings.HasPrefix(cl, "utf8_") || strings.HasPrefix(cl, "utf8mb3")
+}
+
+// varcharLength returns the length of a VARCHAR column, either from
+// CharMaxLength or parsed from the ColumnType of a hand-written column.
+func varcharLength(c *Column) (int64, bool) {
+	ct := strings.ToLower(c.ColumnType)
+	if !strings.EqualFold(c.DataType, "varchar") && !strings.HasPrefix(ct, "varchar(") {
+		return 0, false
+	}
+	if c.CharMaxLength.Valid {
+		return c.CharMaxLength.Int64, true
+	}
+	pos := strings.IndexByte(ct, '(')
+	end := strings.IndexByte(ct, ')')
+	if pos < 0 || end < pos {
+		return 0, false
+	}
+	l, err := strconv.ParseInt(ct[pos+1:end], 10, 64)
+	return l, err == nil
+}
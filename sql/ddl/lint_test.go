@@ -0,0 +1,86 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	tbls := ddl.MustNewTables(
+		ddl.WithTable("sales_order",
+			&ddl.Column{Field: "entity_id", DataType: "int", ColumnType: "int(10) unsigned", Key: "PRI"},
+			&ddl.Column{Field: "customer_id", DataType: "int", ColumnType: "int(10) unsigned", Key: "MUL"},
+			&ddl.Column{Field: "store_id", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+			&ddl.Column{Field: "comment", DataType: "varchar", ColumnType: "varchar(2000)", CharMaxLength: null.MakeInt64(2000)},
+			&ddl.Column{Field: "created_at", DataType: "timestamp", ColumnType: "timestamp"},
+			&ddl.Column{Field: "updated_at", DataType: "timestamp", ColumnType: "timestamp"},
+		),
+		ddl.WithTable("log_entry",
+			&ddl.Column{Field: "message", ColumnType: "varchar(1024) character set utf8"},
+			&ddl.Column{Field: "subject", DataType: "text", ColumnType: "text", CharacterSet: null.MakeString("utf8mb3"), Collation: null.MakeString("utf8mb3_general_ci")},
+			&ddl.Column{Field: "title", DataType: "text", ColumnType: "text", Collation: null.MakeString("utf8_unicode_ci")},
+			&ddl.Column{Field: "body", DataType: "text", ColumnType: "text", CharacterSet: null.MakeString("utf8mb4"), Collation: null.MakeString("utf8mb4_unicode_ci")},
+		),
+		ddl.WithTable("view_sales_order",
+			&ddl.Column{Field: "entity_id", DataType: "int", ColumnType: "int(10) unsigned"},
+		),
+	)
+	tbl := tbls.MustTable("sales_order")
+	tbl.TableCollation = null.MakeString("utf8_general_ci")
+
+	fks := map[string]ddl.KeyColumnUsageCollection{
+		"sales_order": {Data: []*ddl.KeyColumnUsage{
+			{ConstraintName: "FK_SALES_ORDER_CUSTOMER", TableName: "sales_order", ColumnName: "customer_id"},
+			{ConstraintName: "FK_SALES_ORDER_STORE", TableName: "sales_order", ColumnName: "store_id"},
+		}},
+	}
+
+	t.Run("all rules", func(t *testing.T) {
+		lfs, err := ddl.Lint(context.TODO(), tbls, ddl.LintOptions{ForeignKeys: fks})
+		assert.NoError(t, err)
+		assert.Exactly(t, `log_entry: missing_primary_key: table has no primary key
+log_entry: missing_timestamps: column "created_at" is missing
+log_entry: missing_timestamps: column "updated_at" is missing
+log_entry.message: utf8mb3: column type "varchar(1024) character set utf8" should use utf8mb4
+log_entry.message: wide_varchar: VARCHAR length 1024 exceeds 768, consider TEXT or a shorter length
+log_entry.subject: utf8mb3: column character set "utf8mb3" should be utf8mb4
+log_entry.title: utf8mb3: column collation "utf8_unicode_ci" should be utf8mb4
+sales_order: utf8mb3: table collation "utf8_general_ci" should be utf8mb4
+sales_order.comment: wide_varchar: VARCHAR length 2000 exceeds 768, consider TEXT or a shorter length
+sales_order.store_id: unindexed_foreign_key: foreign key "FK_SALES_ORDER_STORE" has no index starting with this column
+`, lfs.String())
+		assert.Len(t, lfs.Rule(ddl.LintWideVarchar), 2)
+		assert.ErrorIsKind(t, errors.NotValid, lfs.Err())
+	})
+
+	t.Run("skip rules", func(t *testing.T) {
+		lfs, err := ddl.Lint(context.TODO(), tbls, ddl.LintOptions{
+			Skip:             []ddl.LintRule{ddl.LintUTF8MB3},
+			SkipTables:       map[string][]ddl.LintRule{"log_entry": nil},
+			MaxVarcharLength: 2000,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, lfs.Err())
+	})
+}
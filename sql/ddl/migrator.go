@@ -0,0 +1,504 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// MigrationFunc executes a migration step written in Go. All statements must
+// use argument db, which holds the migration lock.
+type MigrationFunc func(ctx context.Context, db dml.QueryExecPreparer) error
+
+// Migration defines a versioned step of a schema migration. A step runs
+// either SQL or a Go function; the Go function has precedence. MySQL commits
+// DDL statements implicitly, so a failed step with several statements must be
+// repaired manually.
+type Migration struct {
+	// Version must be unique and defines the order, usually a timestamp like
+	// 20190321143000.
+	Version uint64
+	Name    string
+	// UpSQL and DownSQL contain one or more statements. Statements get split
+	// at a semicolon which ends a line.
+	UpSQL   string
+	DownSQL string
+	Up      MigrationFunc
+	Down    MigrationFunc
+}
+
+func (m Migration) String() string {
+	return strconv.FormatUint(m.Version, 10) + "_" + m.Name
+}
+
+func (m Migration) hasDown() bool { return m.Down != nil || m.DownSQL != "" }
+
+// MigratorOptions configures NewMigrator.
+type MigratorOptions struct {
+	// TableName stores the applied versions. Defaults to schema_migrations.
+	// The table gets created if it does not exist.
+	TableName string
+	// LockName defines the name for GET_LOCK. Defaults to the table name. All
+	// deployments of the same database must use the same name.
+	LockName string
+	// LockTimeout defines how long to wait for the lock of another running
+	// migration. Defaults to 30s.
+	LockTimeout time.Duration
+	// DryRun, if set, receives the statements of the pending steps instead of
+	// executing them. Go steps get only listed by name. Nothing gets recorded
+	// and no lock is acquired.
+	DryRun io.Writer
+}
+
+// Migrator applies versioned migration steps and records the applied
+// versions in a table. Concurrent deployments get serialized via GET_LOCK, so
+// each step runs exactly once.
+//		m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{})
+//		if err := m.LoadDir("migrations"); err != nil {
+//			return err
+//		}
+//		err := m.Register(ddl.Migration{Version: 20190321143000, Name: "fill_sku", Up: fillSKU})
+//		applied, err := m.Up(ctx, 0)
+type Migrator struct {
+	db         *sql.DB
+	o          MigratorOptions
+	migrations []Migration // sorted by version
+}
+
+// NewMigrator creates a new Migrator. Register the steps with Register or
+// LoadDir.
+func NewMigrator(db *sql.DB, o MigratorOptions) *Migrator {
+	if o.TableName == "" {
+		o.TableName = "schema_migrations"
+	}
+	if o.LockName == "" {
+		o.LockName = o.TableName
+	}
+	if o.LockTimeout <= 0 {
+		o.LockTimeout = 30 * time.Second
+	}
+	return &Migrator{db: db, o: o}
+}
+
+// Register adds migration steps. A version can only be registered once.
+func (m *Migrator) Register(ms ...Migration) error {
+	for _, mg := range ms {
+		if mg.Version == 0 {
+			return errors.NotValid.Newf("[ddl] Migration %q version cannot be zero", mg.Name)
+		}
+		if mg.Up == nil && mg.UpSQL == "" {
+			return errors.Empty.Newf("[ddl] Migration %q requires an up step", mg)
+		}
+		idx := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= mg.Version })
+		if idx < len(m.migrations) && m.migrations[idx].Version == mg.Version {
+			return errors.AlreadyExists.Newf("[ddl] Migration version %d already registered as %q", mg.Version, m.migrations[idx])
+		}
+		m.migrations = append(m.migrations, Migration{})
+		copy(m.migrations[idx+1:], m.migrations[idx:])
+		m.migrations[idx] = mg
+	}
+	return nil
+}
+
+var migrationFileRE = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadDir registers the SQL files of a directory. The file names must have
+// the format <version>_<name>.up.sql and <version>_<name>.down.sql, e.g.
+// 20190321143000_add_sku_index.up.sql. The down file is optional. Other files
+// get ignored.
+func (m *Migrator) LoadDir(dir string) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	byVersion := map[uint64]*Migration{}
+	var versions []uint64
+	for _, fi := range fis {
+		match := migrationFileRE.FindStringSubmatch(fi.Name())
+		if fi.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return errors.NotValid.New(err, "[ddl] Migration file %q contains an invalid version", fi.Name())
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mg
+			versions = append(versions, version)
+		}
+		if mg.Name != match[2] {
+			return errors.Mismatch.Newf("[ddl] Migration file %q: version %d has already the name %q", fi.Name(), version, mg.Name)
+		}
+		if match[3] == "up" {
+			mg.UpSQL = string(data)
+		} else {
+			mg.DownSQL = string(data)
+		}
+	}
+	for _, v := range versions {
+		if err := m.Register(*byVersion[v]); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// Migrations returns all registered steps sorted by version.
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Applied returns the applied versions in ascending order.
+func (m *Migrator) Applied(ctx context.Context) ([]uint64, error) {
+	return m.applied(ctx, m.db)
+}
+
+func (m *Migrator) applied(ctx context.Context, db dml.QueryExecPreparer) (_ []uint64, err error) {
+	var exists int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM `information_schema`.`TABLES` WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?", m.o.TableName).Scan(&exists); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if exists == 0 {
+		return nil, nil
+	}
+	rows, err := db.QueryContext(ctx, "SELECT `version` FROM "+dml.Quoter.Name(m.o.TableName)+" ORDER BY `version`")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+	var versions []uint64
+	for rows.Next() {
+		var v uint64
+		if err = rows.Scan(&v); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, errors.WithStack(rows.Err())
+}
+
+// Pending returns the registered steps which have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	versions, err := m.Applied(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m.pending(versions, 0), nil
+}
+
+func (m *Migrator) pending(applied []uint64, target uint64) []Migration {
+	done := make(map[uint64]bool, len(applied))
+	for _, v := range applied {
+		done[v] = true
+	}
+	var ret []Migration
+	for _, mg := range m.migrations {
+		if !done[mg.Version] && (target == 0 || mg.Version <= target) {
+			ret = append(ret, mg)
+		}
+	}
+	return ret
+}
+
+// Up applies all pending steps up to and including the target version. A
+// target of zero applies all pending steps. It returns the applied steps; on
+// error the already applied steps stay recorded.
+func (m *Migrator) Up(ctx context.Context, target uint64) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, mg := range m.pending(applied, target) {
+			if err := m.run(ctx, conn, mg, mg.Up, mg.UpSQL); err != nil {
+				return errors.Wrapf(err, "[ddl] Migration %q up failed", mg)
+			}
+			if m.o.DryRun == nil {
+				if _, err := conn.ExecContext(ctx, "INSERT INTO "+dml.Quoter.Name(m.o.TableName)+" (`version`,`name`) VALUES (?,?)", mg.Version, mg.Name); err != nil {
+					return errors.Wrapf(err, "[ddl] Migration %q failed to record the version", mg)
+				}
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last applied steps, newest first. Argument steps defines
+// the number of steps to revert. Applied versions which are not registered
+// or have no down step return an error before anything gets reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var revert []Migration
+		for i := len(applied) - 1; i >= 0 && len(revert) < steps; i-- {
+			idx := sort.Search(len(m.migrations), func(j int) bool { return m.migrations[j].Version >= applied[i] })
+			if idx == len(m.migrations) || m.migrations[idx].Version != applied[i] {
+				return errors.NotFound.Newf("[ddl] Applied migration version %d is not registered", applied[i])
+			}
+			if mg := m.migrations[idx]; !mg.hasDown() {
+				return errors.NotSupported.Newf("[ddl] Migration %q has no down step", mg)
+			}
+			revert = append(revert, m.migrations[idx])
+		}
+		for _, mg := range revert {
+			if err := m.run(ctx, conn, mg, mg.Down, mg.DownSQL); err != nil {
+				return errors.Wrapf(err, "[ddl] Migration %q down failed", mg)
+			}
+			if m.o.DryRun == nil {
+				if _, err := conn.ExecContext(ctx, "DELETE FROM "+dml.Quoter.Name(m.o.TableName)+" WHERE `version` = ?", mg.Version); err != nil {
+					return errors.Wrapf(err, "[ddl] Migration %q failed to delete the version", mg)
+				}
+			}
+			done = append(done, mg)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock runs fn on a single connection which holds the migration lock. In
+// dry-run mode no lock gets acquired and the migration table does not get
+// created.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		if errC := conn.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+	if m.o.DryRun != nil {
+		return fn(conn)
+	}
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?,?)", m.o.LockName, int64(m.o.LockTimeout/time.Second)).Scan(&locked); err != nil {
+		return errors.WithStack(err)
+	}
+	if locked.Int64 != 1 {
+		return errors.Locked.Newf("[ddl] Migrator failed to acquire the lock %q within %s", m.o.LockName, m.o.LockTimeout)
+	}
+	defer func() {
+		// Use a new context because ctx might be canceled.
+		if _, errR := conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", m.o.LockName); err == nil && errR != nil {
+			err = errors.WithStack(errR)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+dml.Quoter.Name(m.o.TableName)+" ("+
+		"`version` BIGINT UNSIGNED NOT NULL PRIMARY KEY, "+
+		"`name` VARCHAR(255) NOT NULL, "+
+		"`applied_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP"+
+		")"); err != nil {
+		return errors.WithStack(err)
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mg Migration, fn MigrationFunc, sqlStr string) error {
+	if m.o.DryRun != nil {
+		if _, err := fmt.Fprintf(m.o.DryRun, "-- %s\n", mg); err != nil {
+			return errors.WithStack(err)
+		}
+		if fn != nil {
+			_, err := fmt.Fprint(m.o.DryRun, "-- Go function\n")
+			return errors.WithStack(err)
+		}
+		for _, stmt := range splitMigrationSQL(sqlStr) {
+			if _, err := fmt.Fprintf(m.o.DryRun, "%s;\n", stmt); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if fn != nil {
+		return errors.WithStack(fn(ctx, conn))
+	}
+	for _, stmt := range splitMigrationSQL(sqlStr) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrapf(err, "[ddl] Failed to execute %q", stmt)
+		}
+	}
+	return nil
+}
+
+// splitMigrationSQL splits the statements at the delimiter, which defaults to
+// a semicolon and can be changed with the client command DELIMITER. Quoted
+// strings and identifiers, block comments and the BEGIN ... END bodies of
+// stored programs do not get split. Line comments and empty statements get
+// removed.
+func splitMigrationSQL(sqlStr string) []string {
+	var (
+		stmts    []string
+		buf      strings.Builder
+		delim    = ";"
+		sawWord  bool // the first word of the statement has been written
+		compound bool // the statement can contain BEGIN ... END blocks
+		depth    int  // open BEGIN ... END and CASE ... END blocks
+	)
+	flush := func() {
+		if s := strings.TrimSpace(buf.String()); s != "" {
+			stmts = append(stmts, s)
+		}
+		buf.Reset()
+		sawWord, compound, depth = false, false, 0
+	}
+	for i := 0; i < len(sqlStr); {
+		c := sqlStr[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := skipSQLQuote(sqlStr, i)
+			buf.WriteString(sqlStr[i:j])
+			i = j
+		case c == '#' || (strings.HasPrefix(sqlStr[i:], "--") && (i+2 == len(sqlStr) || isSQLSpace(sqlStr[i+2]))):
+			if j := strings.IndexByte(sqlStr[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(sqlStr)
+			}
+		case strings.HasPrefix(sqlStr[i:], "/*"):
+			j := len(sqlStr)
+			if k := strings.Index(sqlStr[i+2:], "*/"); k >= 0 {
+				j = i + 2 + k + 2
+			}
+			buf.WriteString(sqlStr[i:j])
+			i = j
+		case depth == 0 && strings.HasPrefix(sqlStr[i:], delim):
+			flush()
+			i += len(delim)
+		case isSQLWordChar(c) && (i == 0 || !isSQLWordChar(sqlStr[i-1])):
+			j := i
+			for j < len(sqlStr) && isSQLWordChar(sqlStr[j]) {
+				j++
+			}
+			word := strings.ToUpper(sqlStr[i:j])
+			switch {
+			case !sawWord && word == "DELIMITER":
+				k := strings.IndexByte(sqlStr[j:], '\n')
+				if k < 0 {
+					k = len(sqlStr) - j
+				}
+				if d := strings.TrimSpace(sqlStr[j : j+k]); d != "" {
+					delim = d
+				}
+				buf.Reset()
+				i = j + k
+				continue
+			case !sawWord:
+				sawWord = true
+				next, _ := nextSQLWord(sqlStr[j:])
+				compound = delim == ";" && (word == "CREATE" || (word == "BEGIN" && next == "NOT"))
+				if compound && word == "BEGIN" {
+					depth++
+				}
+			case !compound:
+			case word == "BEGIN" || word == "CASE":
+				depth++
+			case word == "END" && depth > 0:
+				switch next, n := nextSQLWord(sqlStr[j:]); next {
+				case "IF", "LOOP", "WHILE", "REPEAT":
+					// their start has not been counted
+				case "CASE":
+					depth--
+					j += n
+				default:
+					depth--
+				}
+			}
+			buf.WriteString(sqlStr[i:j])
+			i = j
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return stmts
+}
+
+// skipSQLQuote returns the index after the closing quote of the string or
+// identifier starting at sqlStr[i]. A doubled quote and, except for
+// identifiers, a backslash escape the quote.
+func skipSQLQuote(sqlStr string, i int) int {
+	q := sqlStr[i]
+	for j := i + 1; j < len(sqlStr); j++ {
+		switch sqlStr[j] {
+		case '\\':
+			if q != '`' {
+				j++
+			}
+		case q:
+			if j+1 < len(sqlStr) && sqlStr[j+1] == q {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(sqlStr)
+}
+
+// nextSQLWord returns the next word in upper case and the index after it.
+func nextSQLWord(s string) (string, int) {
+	i := 0
+	for i < len(s) && isSQLSpace(s[i]) {
+		i++
+	}
+	j := i
+	for j < len(s) && isSQLWordChar(s[j]) {
+		j++
+	}
+	return strings.ToUpper(s[i:j]), j
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isSQLWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,88 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"testing"
+
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestSplitMigrationSQL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		sqlStr string
+		want   []string
+	}{
+		{
+			"comments and empty statements",
+			"-- header\n# another comment\nSELECT 1; ;\nSELECT 2 -- trailing\n;",
+			[]string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			"literals",
+			"INSERT INTO `t` VALUES ('a;b', '-- no comment', \"it''s;\", 'x\\';y');\nUPDATE `a;b` SET `c`='  keep  spaces  ';",
+			[]string{
+				"INSERT INTO `t` VALUES ('a;b', '-- no comment', \"it''s;\", 'x\\';y')",
+				"UPDATE `a;b` SET `c`='  keep  spaces  '",
+			},
+		},
+		{
+			"multi line literal",
+			"INSERT INTO `t` VALUES ('line1;\n  line2\n');",
+			[]string{"INSERT INTO `t` VALUES ('line1;\n  line2\n')"},
+		},
+		{
+			"block comment",
+			"/* a; b */ SELECT /*+ NO_ICP(t) */ 1;",
+			[]string{"/* a; b */ SELECT /*+ NO_ICP(t) */ 1"},
+		},
+		{
+			"BEGIN END body",
+			"CREATE TRIGGER `trg` BEFORE INSERT ON `t` FOR EACH ROW BEGIN\n" +
+				"  IF NEW.a > 1 THEN SET NEW.b = 1; END IF;\n" +
+				"  SET NEW.c = CASE WHEN NEW.a = 1 THEN 2 ELSE 3 END;\n" +
+				"  CASE NEW.a WHEN 1 THEN SET NEW.d = 1; ELSE SET NEW.d = 2; END CASE;\n" +
+				"END;\nSELECT 1;",
+			[]string{
+				"CREATE TRIGGER `trg` BEFORE INSERT ON `t` FOR EACH ROW BEGIN\n" +
+					"  IF NEW.a > 1 THEN SET NEW.b = 1; END IF;\n" +
+					"  SET NEW.c = CASE WHEN NEW.a = 1 THEN 2 ELSE 3 END;\n" +
+					"  CASE NEW.a WHEN 1 THEN SET NEW.d = 1; ELSE SET NEW.d = 2; END CASE;\n" +
+					"END",
+				"SELECT 1",
+			},
+		},
+		{
+			"transaction BEGIN",
+			"BEGIN;\nUPDATE `t` SET `a`=1;\nCOMMIT;",
+			[]string{"BEGIN", "UPDATE `t` SET `a`=1", "COMMIT"},
+		},
+		{
+			"DELIMITER",
+			"DELIMITER //\nCREATE PROCEDURE `p`()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND//\nDELIMITER ;\nCALL `p`();",
+			[]string{"CREATE PROCEDURE `p`()\nBEGIN\n  SELECT 1;\n  SELECT 2;\nEND", "CALL `p`()"},
+		},
+		{
+			"DELIMITER with dollar signs",
+			"DELIMITER $$\nCREATE FUNCTION `f`() RETURNS INT RETURN 1$$\nDELIMITER ;",
+			[]string{"CREATE FUNCTION `f`() RETURNS INT RETURN 1"},
+		},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, splitMigrationSQL(test.sqlStr), test.name)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func newTestMigrator(t *testing.T, m *ddl.Migrator) {
+	assert.NoError(t, m.LoadDir("testdata/migrations"))
+	assert.NoError(t, m.Register(ddl.Migration{
+		Version: 20190405000000,
+		Name:    "fill_sku_log",
+		Up: func(ctx context.Context, db dml.QueryExecPreparer) error {
+			_, err := db.ExecContext(ctx, "INSERT INTO `sku_log` (`sku`) SELECT `sku` FROM `catalog_product_entity`")
+			return err
+		},
+	}))
+}
+
+func expectMigratorApplied(dbMock sqlmock.Sqlmock, versions ...uint64) {
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT COUNT(*) FROM `information_schema`.`TABLES` WHERE `TABLE_SCHEMA` = DATABASE() AND `TABLE_NAME` = ?")).
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, v := range versions {
+		rows.AddRow(v)
+	}
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `version` FROM `schema_migrations` ORDER BY `version`")).
+		WillReturnRows(rows)
+}
+
+func expectMigratorLock(dbMock sqlmock.Sqlmock) {
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT GET_LOCK(?,?)")).
+		WithArgs("schema_migrations", 30).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(1))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE TABLE IF NOT EXISTS `schema_migrations`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrator_Register(t *testing.T) {
+	t.Parallel()
+	m := ddl.NewMigrator(nil, ddl.MigratorOptions{})
+	newTestMigrator(t, m)
+
+	ms := m.Migrations()
+	assert.Len(t, ms, 3)
+	assert.Exactly(t, "20190321143000_create_sku_log", ms[0].String())
+	assert.Exactly(t, "DROP TABLE `sku_log`;\n", ms[0].DownSQL)
+	assert.Exactly(t, "20190402090000_add_sku_log_comment", ms[1].String())
+	assert.Exactly(t, "", ms[1].DownSQL)
+
+	err := m.Register(ddl.Migration{Version: 20190402090000, Name: "duplicate", UpSQL: "SELECT 1"})
+	assert.ErrorIsKind(t, errors.AlreadyExists, err)
+	err = m.Register(ddl.Migration{Version: 1, Name: "no_up"})
+	assert.ErrorIsKind(t, errors.Empty, err)
+	err = m.Register(ddl.Migration{Name: "no_version", UpSQL: "SELECT 1"})
+	assert.ErrorIsKind(t, errors.NotValid, err)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{})
+	newTestMigrator(t, m)
+
+	expectMigratorLock(dbMock)
+	expectMigratorApplied(dbMock, 20190321143000)
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("ALTER TABLE `sku_log` ADD COLUMN `comment` TEXT NULL")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `schema_migrations` (`version`,`name`) VALUES (?,?)")).
+		WithArgs(20190402090000, "add_sku_log_comment").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `sku_log` (`sku`) SELECT `sku` FROM `catalog_product_entity`")).
+		WillReturnError(errors.AlreadyClosed.Newf("Connection gone"))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DO RELEASE_LOCK(?)")).
+		WithArgs("schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	done, err := m.Up(context.TODO(), 0)
+	assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+	assert.Len(t, done, 1)
+	assert.Exactly(t, uint64(20190402090000), done[0].Version)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	t.Run("reverts newest first", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{})
+		newTestMigrator(t, m)
+
+		expectMigratorLock(dbMock)
+		expectMigratorApplied(dbMock, 20190321143000)
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DROP TABLE `sku_log`")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `schema_migrations` WHERE `version` = ?")).
+			WithArgs(20190321143000).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DO RELEASE_LOCK(?)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		done, err := m.Down(context.TODO(), 5)
+		assert.NoError(t, err)
+		assert.Len(t, done, 1)
+	})
+
+	t.Run("missing down step", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{})
+		newTestMigrator(t, m)
+
+		expectMigratorLock(dbMock)
+		expectMigratorApplied(dbMock, 20190321143000, 20190402090000)
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DO RELEASE_LOCK(?)")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		done, err := m.Down(context.TODO(), 2)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+		assert.Len(t, done, 0)
+	})
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	var buf bytes.Buffer
+	m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{DryRun: &buf})
+	newTestMigrator(t, m)
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT COUNT(*) FROM `information_schema`.`TABLES`")).
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+
+	done, err := m.Up(context.TODO(), 20190402090000)
+	assert.NoError(t, err)
+	assert.Len(t, done, 2)
+	assert.Exactly(t, "-- 20190321143000_create_sku_log\n"+
+		"CREATE TABLE `sku_log` (\n\t`id` INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,\n\t`sku` VARCHAR(64) NOT NULL\n);\n"+
+		"CREATE INDEX `IDX_SKU_LOG_SKU` ON `sku_log` (`sku`);\n"+
+		"-- 20190402090000_add_sku_log_comment\n"+
+		"ALTER TABLE `sku_log` ADD COLUMN `comment` TEXT NULL;\n", buf.String())
+}
+
+func TestMigrator_Locked(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	m := ddl.NewMigrator(dbc.DB, ddl.MigratorOptions{LockName: "deploy", LockTimeout: 2 * time.Second})
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT GET_LOCK(?,?)")).
+		WithArgs("deploy", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK"}).AddRow(0))
+
+	_, err := m.Up(context.TODO(), 0)
+	assert.ErrorIsKind(t, errors.Locked, err)
+}
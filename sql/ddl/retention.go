@@ -0,0 +1,338 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// RetentionPolicy defines how long the rows of a table are kept. Expired rows
+// get either deleted or moved into an archive table.
+type RetentionPolicy struct {
+	TableName string
+	// TimeColumn contains the point in time when a row has been created.
+	// Defaults to created_at.
+	TimeColumn string
+	// KeepFor defines the age after which a row expires, for example
+	// 90*24*time.Hour. The age gets calculated with the clock of the database
+	// server.
+	KeepFor time.Duration
+	// ArchiveTable, if set, receives a copy of the expired rows before they
+	// get deleted. The table must have the same columns in the same order,
+	// e.g. created with CREATE TABLE x_archive LIKE x.
+	ArchiveTable string
+	// BatchSize defines the number of rows deleted per transaction. Defaults
+	// to 1000.
+	BatchSize uint64
+}
+
+// RetentionOptions configures Tables.NewRetention.
+type RetentionOptions struct {
+	// Interval defines the pause between two runs of Retention.Run. Defaults
+	// to one hour.
+	Interval time.Duration
+	// Progress gets called after each batch and can be used to feed metrics.
+	Progress func(RetentionProgress)
+}
+
+// RetentionProgress reports the progress of a single batch.
+type RetentionProgress struct {
+	TableName string
+	// Batch counts the batches of the current run, starting at one.
+	Batch int
+	// Archived contains the number of rows copied into the archive table.
+	Archived uint64
+	Deleted  uint64
+	Duration time.Duration
+	// Done reports that the table contains no more expired rows.
+	Done bool
+	Err  error
+}
+
+// RetentionStats contains the accumulated numbers of a table since the
+// creation of the Retention.
+type RetentionStats struct {
+	Runs         uint64
+	Batches      uint64
+	Archived     uint64
+	Deleted      uint64
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastErr      error
+}
+
+type retentionPolicy struct {
+	RetentionPolicy
+	pkColumn string
+}
+
+// Retention deletes or archives the expired rows of the registered tables.
+// Each batch runs in its own transaction and claims its rows with SELECT ...
+// FOR UPDATE SKIP LOCKED, so several processes can run the same policies
+// without blocking each other or the application. SKIP LOCKED requires MySQL
+// >= 8.0.1 or MariaDB >= 10.6. Retention replaces the DELETE statements
+// triggered by cron jobs. Retention is safe for concurrent use.
+type Retention struct {
+	tm *Tables
+	o  RetentionOptions
+
+	mu       sync.Mutex
+	policies []retentionPolicy
+	stats    map[string]RetentionStats
+}
+
+// NewRetention creates a new retention engine for the tables. The tables
+// must have a connection pool. Register the policies with Retention.Register.
+//		r := tbls.NewRetention(ddl.RetentionOptions{Interval: time.Hour})
+//		err := r.Register(ddl.RetentionPolicy{
+//			TableName:    "sales_order_grid",
+//			KeepFor:      90 * 24 * time.Hour,
+//			ArchiveTable: "sales_order_grid_archive",
+//		})
+//		go r.Run(ctx)
+func (tm *Tables) NewRetention(o RetentionOptions) *Retention {
+	if o.Interval <= 0 {
+		o.Interval = time.Hour
+	}
+	return &Retention{
+		tm:    tm,
+		o:     o,
+		stats: make(map[string]RetentionStats),
+	}
+}
+
+// Register adds a policy. The table must be known to Tables and must have a
+// single column integer primary key. A table can only be registered once.
+func (r *Retention) Register(p RetentionPolicy) error {
+	if p.TimeColumn == "" {
+		p.TimeColumn = "created_at"
+	}
+	if p.BatchSize == 0 {
+		p.BatchSize = 1000
+	}
+	if p.KeepFor < time.Second {
+		return errors.NotValid.Newf("[ddl] Retention.Register: KeepFor %s of table %q must be at least one second", p.KeepFor, p.TableName)
+	}
+	ids := []string{p.TableName, p.TimeColumn}
+	if p.ArchiveTable != "" {
+		ids = append(ids, p.ArchiveTable)
+	}
+	for _, id := range ids {
+		if err := dml.IsValidIdentifier(id); err != nil {
+			return errors.Wrapf(err, "[ddl] Retention.Register invalid identifier of table %q", p.TableName)
+		}
+	}
+
+	t, err := r.tm.Table(p.TableName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !t.Columns.Contains(p.TimeColumn) {
+		return errors.NotFound.Newf("[ddl] Retention.Register: column %q not found in table %q", p.TimeColumn, p.TableName)
+	}
+	pkCols := t.Columns.PrimaryKeys()
+	if len(pkCols) != 1 || !strings.Contains(pkCols[0].DataType, "int") {
+		return errors.NotSupported.Newf("[ddl] Retention.Register requires a single column integer primary key for table %q", p.TableName)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rp := range r.policies {
+		if rp.TableName == p.TableName {
+			return errors.AlreadyExists.Newf("[ddl] Retention.Register: policy for table %q has already been registered", p.TableName)
+		}
+	}
+	r.policies = append(r.policies, retentionPolicy{RetentionPolicy: p, pkColumn: pkCols[0].Field})
+	sort.Slice(r.policies, func(i, j int) bool { return r.policies[i].TableName < r.policies[j].TableName })
+	return nil
+}
+
+// Stats returns a copy of the statistics per table name.
+func (r *Retention) Stats() map[string]RetentionStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make(map[string]RetentionStats, len(r.stats))
+	for k, v := range r.stats {
+		ret[k] = v
+	}
+	return ret
+}
+
+// Run executes RunOnce immediately and then after each interval until the
+// context gets canceled. Errors of a run get reported via the Progress
+// callback and the Stats but do not stop the scheduler.
+func (r *Retention) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.o.Interval)
+	defer ticker.Stop()
+	for {
+		_ = r.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes all registered tables until no expired rows are left.
+// A failing table does not stop the processing of the other tables; the
+// first error gets returned.
+func (r *Retention) RunOnce(ctx context.Context) error {
+	r.mu.Lock()
+	policies := append([]retentionPolicy(nil), r.policies...)
+	r.mu.Unlock()
+
+	var firstErr error
+	for _, p := range policies {
+		if err := ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := r.runPolicy(ctx, p); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (r *Retention) runPolicy(ctx context.Context, p retentionPolicy) (err error) {
+	start := time.Now()
+	var st RetentionStats
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s := r.stats[p.TableName]
+		s.Runs++
+		s.Batches += st.Batches
+		s.Archived += st.Archived
+		s.Deleted += st.Deleted
+		s.LastRun = start
+		s.LastDuration = time.Since(start)
+		s.LastErr = err
+		r.stats[p.TableName] = s
+	}()
+
+	claimSQL, err := p.claimSQL()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for batch := 1; ; batch++ {
+		if err = ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+		prg := RetentionProgress{TableName: p.TableName, Batch: batch}
+		batchStart := time.Now()
+		var ids []int64
+		err = r.tm.Transaction(ctx, nil, func(tx *dml.Tx) error {
+			var err error
+			if ids, err = retentionClaim(ctx, tx, claimSQL); err != nil || len(ids) == 0 {
+				return errors.WithStack(err)
+			}
+			prg.Archived, prg.Deleted, err = p.archiveDelete(ctx, tx, ids)
+			return errors.WithStack(err)
+		})
+		if err != nil {
+			err = errors.Wrapf(err, "[ddl] Retention failed for table %q in batch %d", p.TableName, batch)
+		}
+		prg.Duration = time.Since(batchStart)
+		prg.Done = err == nil && uint64(len(ids)) < p.BatchSize
+		prg.Err = err
+		if err == nil {
+			st.Batches++
+			st.Archived += prg.Archived
+			st.Deleted += prg.Deleted
+		}
+		if r.o.Progress != nil {
+			r.o.Progress(prg)
+		}
+		if err != nil || prg.Done {
+			return err
+		}
+	}
+}
+
+// claimSQL selects the primary keys of the next batch of expired rows and
+// locks them. Rows locked by other transactions get skipped.
+func (p retentionPolicy) claimSQL() (string, error) {
+	sqlStr, _, err := dml.NewSelect(p.pkColumn).From(p.TableName).Where(
+		dml.Column(p.TimeColumn).Less().Expr("NOW() - INTERVAL "+strconv.FormatInt(int64(p.KeepFor/time.Second), 10)+" SECOND"),
+	).OrderBy(p.pkColumn).Limit(0, p.BatchSize).ForUpdate().ToSQL()
+	if err != nil {
+		return "", errors.Wrapf(err, "[ddl] Retention failed to build the claim statement for table %q", p.TableName)
+	}
+	// dml.Select does not support SKIP LOCKED.
+	return sqlStr + " SKIP LOCKED", nil
+}
+
+func retentionClaim(ctx context.Context, tx *dml.Tx, claimSQL string) (ids []int64, err error) {
+	rows, err := tx.DB.QueryContext(ctx, claimSQL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		if cErr := rows.Close(); err == nil && cErr != nil {
+			err = errors.WithStack(cErr)
+		}
+	}()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, errors.WithStack(rows.Err())
+}
+
+// archiveDelete copies the claimed rows into the archive table, if
+// configured, and deletes them.
+func (p retentionPolicy) archiveDelete(ctx context.Context, tx *dml.Tx, ids []int64) (archived, deleted uint64, err error) {
+	if p.ArchiveTable != "" {
+		sqlStr, _, err := dml.NewInsert(p.ArchiveTable).FromSelect(
+			dml.NewSelect().Star().From(p.TableName).Where(dml.Column(p.pkColumn).In().Int64s(ids...)),
+		).ToSQL()
+		if err != nil {
+			return 0, 0, errors.WithStack(err)
+		}
+		res, err := tx.DB.ExecContext(ctx, sqlStr)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "[ddl] Retention failed to archive into table %q", p.ArchiveTable)
+		}
+		ra, err := res.RowsAffected()
+		if err != nil {
+			return 0, 0, errors.WithStack(err)
+		}
+		archived = uint64(ra)
+	}
+
+	sqlStr, _, err := dml.NewDelete(p.TableName).Where(dml.Column(p.pkColumn).In().Int64s(ids...)).ToSQL()
+	if err != nil {
+		return archived, 0, errors.WithStack(err)
+	}
+	res, err := tx.DB.ExecContext(ctx, sqlStr)
+	if err != nil {
+		return archived, 0, errors.WithStack(err)
+	}
+	ra, err := res.RowsAffected()
+	return archived, uint64(ra), errors.WithStack(err)
+}
@@ -0,0 +1,115 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func newRetentionTables(t *testing.T) (*ddl.Tables, sqlmock.Sqlmock, func()) {
+	dbc, dbMock := dmltest.MockDB(t)
+	tbls, err := ddl.NewTables(ddl.WithConnPool(dbc), ddl.WithTable("sales_order_grid",
+		&ddl.Column{Field: "entity_id", Pos: 1, DataType: "int", ColumnType: "int(10) unsigned", Key: "PRI", Extra: "auto_increment"},
+		&ddl.Column{Field: "created_at", Pos: 2, DataType: "timestamp", ColumnType: "timestamp"},
+	))
+	assert.NoError(t, err)
+	return tbls, dbMock, func() { dmltest.MockClose(t, dbc, dbMock) }
+}
+
+func TestRetention(t *testing.T) {
+	const claimSQL = "SELECT `entity_id` FROM `sales_order_grid` WHERE (`created_at` < NOW() - INTERVAL 7776000 SECOND) ORDER BY `entity_id` LIMIT 0,2 FOR UPDATE SKIP LOCKED"
+
+	t.Run("archive in batches", func(t *testing.T) {
+		tbls, dbMock, closeFn := newRetentionTables(t)
+		defer closeFn()
+
+		var prgs []ddl.RetentionProgress
+		r := tbls.NewRetention(ddl.RetentionOptions{
+			Progress: func(p ddl.RetentionProgress) { p.Duration = 0; prgs = append(prgs, p) },
+		})
+		assert.NoError(t, r.Register(ddl.RetentionPolicy{
+			TableName:    "sales_order_grid",
+			KeepFor:      90 * 24 * time.Hour,
+			ArchiveTable: "sales_order_grid_archive",
+			BatchSize:    2,
+		}))
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(claimSQL)).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(3).AddRow(4))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `sales_order_grid_archive` SELECT * FROM `sales_order_grid` WHERE (`entity_id` IN (3,4))")).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_order_grid` WHERE (`entity_id` IN (3,4))")).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectCommit()
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(claimSQL)).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(7))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `sales_order_grid_archive` SELECT * FROM `sales_order_grid` WHERE (`entity_id` IN (7))")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_order_grid` WHERE (`entity_id` IN (7))")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectCommit()
+
+		assert.NoError(t, r.RunOnce(context.TODO()))
+		assert.Exactly(t, []ddl.RetentionProgress{
+			{TableName: "sales_order_grid", Batch: 1, Archived: 2, Deleted: 2},
+			{TableName: "sales_order_grid", Batch: 2, Archived: 1, Deleted: 1, Done: true},
+		}, prgs)
+		st := r.Stats()["sales_order_grid"]
+		assert.Exactly(t, uint64(1), st.Runs)
+		assert.Exactly(t, uint64(2), st.Batches)
+		assert.Exactly(t, uint64(3), st.Deleted)
+		assert.NoError(t, st.LastErr)
+	})
+
+	t.Run("delete fails", func(t *testing.T) {
+		tbls, dbMock, closeFn := newRetentionTables(t)
+		defer closeFn()
+
+		r := tbls.NewRetention(ddl.RetentionOptions{})
+		assert.NoError(t, r.Register(ddl.RetentionPolicy{TableName: "sales_order_grid", KeepFor: 90 * 24 * time.Hour, BatchSize: 2}))
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(claimSQL)).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_order_grid` WHERE (`entity_id` IN (3))")).
+			WillReturnError(errors.Aborted.Newf("lock wait timeout"))
+		dbMock.ExpectRollback()
+
+		err := r.RunOnce(context.TODO())
+		assert.ErrorIsKind(t, errors.Aborted, err)
+		assert.ErrorIsKind(t, errors.Aborted, r.Stats()["sales_order_grid"].LastErr)
+	})
+
+	t.Run("register errors", func(t *testing.T) {
+		tbls, _, closeFn := newRetentionTables(t)
+		defer closeFn()
+
+		r := tbls.NewRetention(ddl.RetentionOptions{})
+		assert.ErrorIsKind(t, errors.NotValid, r.Register(ddl.RetentionPolicy{TableName: "sales_order_grid"}))
+		assert.ErrorIsKind(t, errors.NotFound, r.Register(ddl.RetentionPolicy{TableName: "sales_order_grid", TimeColumn: "updated_at", KeepFor: time.Hour}))
+		assert.NoError(t, r.Register(ddl.RetentionPolicy{TableName: "sales_order_grid", KeepFor: time.Hour}))
+		assert.ErrorIsKind(t, errors.AlreadyExists, r.Register(ddl.RetentionPolicy{TableName: "sales_order_grid", KeepFor: time.Hour}))
+	})
+}
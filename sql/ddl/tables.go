@@ -99,6 +99,41 @@ type Tables struct {
 	// queries contains the query key, e.g. if the query is select or update or
 	// etc, and the associated object with the final query
 	queries map[string]*dml.DBR
+	// autoIncThreshold and autoIncWarnFn see WithAutoIncrementWarning.
+	autoIncThreshold float64
+	autoIncWarnFn    func(AutoIncrement)
+	// changeHooks see WithTableChangeHook.
+	changeHooks []TableChangeFn
+}
+
+// TableEvent defines the kind of change of a table in the Tables registry.
+type TableEvent uint8
+
+// Those constants define the events passed to a TableChangeFn.
+const (
+	TableEventAdded TableEvent = iota + 1
+	TableEventRemoved
+	TableEventReloaded
+)
+
+// TableChangeFn gets called after a table has been added, removed or reloaded
+// via AddTable, RemoveTable or Reload. Argument oldTbl is nil for an added
+// table and newTbl is nil for a removed table. The function gets called
+// without holding the lock of the Tables type.
+type TableChangeFn func(event TableEvent, oldTbl, newTbl *Table)
+
+// WithTableChangeHook adds functions to notify dependent caches or generated
+// repositories about changes in the Tables registry.
+func WithTableChangeHook(fns ...TableChangeFn) TableOption {
+	return TableOption{
+		sortOrder: 1,
+		fn: func(tm *Tables) error {
+			tm.mu.Lock()
+			defer tm.mu.Unlock()
+			tm.changeHooks = append(tm.changeHooks, fns...)
+			return nil
+		},
+	}
 }
 
 // WithQueryDBR adds a pre-defined query with its key to the Tables object.
@@ -371,65 +406,109 @@ func WithLoadTables(ctx context.Context, db dml.Querier, tableNames ...string) T
 	return TableOption{
 		sortOrder: 70,
 		fn: func(tm *Tables) error {
-			for _, tn := range tableNames {
-				if err := dml.IsValidIdentifier(tn); err != nil {
-					return errors.WithStack(err)
-				}
-			}
-
-			// load all columns for all tables
-			tblColMap, err := LoadColumns(ctx, db, tableNames...)
+			tables, err := loadTables(ctx, db, tableNames)
 			if err != nil {
 				return errors.WithStack(err)
 			}
-
-			var rows *sql.Rows
-			if len(tableNames) == 0 {
-				var err error
-				rows, err = db.QueryContext(ctx, selAllTables)
-				if err != nil {
+			for _, nt := range tables {
+				if err := tm.Upsert(nt); err != nil {
 					return errors.WithStack(err)
 				}
-			} else {
-				sqlStr, _, err := dml.Interpolate(selTables).Strs(tableNames...).ToSQL()
-				if err != nil {
-					return errors.Wrapf(err, "[ddl] WithLoadTables dml.ExpandPlaceHolders for tables %v", tableNames)
-				}
-				rows, err = db.QueryContext(ctx, sqlStr)
-				if err != nil {
-					return errors.Wrapf(err, "[ddl] WithLoadTables QueryContext for tables %v with WHERE clause", tableNames)
-				}
 			}
+			return nil
+		},
+	}
+}
 
-			defer func() {
-				// Not testable with the sqlmock package :-(
-				if err2 := rows.Close(); err2 != nil && err == nil {
-					err = errors.WithStack(err2)
-				}
-			}()
+// loadTables queries the tables and their columns. An empty tableNames slice
+// loads all tables of the current database.
+func loadTables(ctx context.Context, db dml.Querier, tableNames []string) (_ []*Table, err error) {
+	for _, tn := range tableNames {
+		if err := dml.IsValidIdentifier(tn); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
 
-			rc := new(dml.ColumnMap)
-			for rows.Next() {
-				if err = rc.Scan(rows); err != nil {
-					return errors.Wrapf(err, "[ddl] Scan Query for tables: %v", tableNames)
-				}
+	// load all columns for all tables
+	tblColMap, err := LoadColumns(ctx, db, tableNames...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-				nt, err := newTable(rc)
-				if err != nil {
-					return errors.WithStack(err)
-				}
+	var rows *sql.Rows
+	if len(tableNames) == 0 {
+		rows, err = db.QueryContext(ctx, selAllTables)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	} else {
+		sqlStr, _, err := dml.Interpolate(selTables).Strs(tableNames...).ToSQL()
+		if err != nil {
+			return nil, errors.Wrapf(err, "[ddl] WithLoadTables dml.ExpandPlaceHolders for tables %v", tableNames)
+		}
+		rows, err = db.QueryContext(ctx, sqlStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[ddl] WithLoadTables QueryContext for tables %v with WHERE clause", tableNames)
+		}
+	}
 
-				nt.Columns = tblColMap[nt.Name]
+	defer func() {
+		// Not testable with the sqlmock package :-(
+		if err2 := rows.Close(); err2 != nil && err == nil {
+			err = errors.WithStack(err2)
+		}
+	}()
 
-				if err := tm.Upsert(nt); err != nil {
-					return errors.WithStack(err)
+	var tables []*Table
+	rc := new(dml.ColumnMap)
+	for rows.Next() {
+		if err = rc.Scan(rows); err != nil {
+			return nil, errors.Wrapf(err, "[ddl] Scan Query for tables: %v", tableNames)
+		}
+
+		nt, err := newTable(rc)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		nt.Columns = tblColMap[nt.Name]
+		tables = append(tables, nt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return tables, nil
+}
+
+// WithColumnCompression compresses transparently the values of the columns of
+// a table before writing and decompresses them while scanning. See
+// dml.WithColumnCompression for the details. The table must contain the
+// columns and each column must have a binary data type like BLOB or
+// VARBINARY. Equally named columns of other tables stay uncompressed.
+// Requires a connection pool, see WithDB or WithConnPool.
+func WithColumnCompression(enc dml.ValueEncoder, dec dml.ValueDecoder, tableName string, columns ...string) TableOption {
+	return TableOption{
+		sortOrder: 100, // must run after all table loading options
+		fn: func(tm *Tables) error {
+			tbl, err := tm.Table(tableName)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			for _, c := range columns {
+				col := tbl.Columns.ByField(c)
+				if col.Field == "" {
+					return errors.NotFound.Newf("[ddl] WithColumnCompression: Column %q not found in table %q", c, tableName)
+				}
+				if dt := strings.ToLower(col.DataType); !strings.Contains(dt, "blob") && !strings.Contains(dt, "binary") {
+					return errors.NotAcceptable.Newf("[ddl] WithColumnCompression: Column %q in table %q has data type %q but requires a binary data type", c, tableName, col.DataType)
 				}
 			}
-			if err = rows.Err(); err != nil {
-				return errors.WithStack(err)
+			tm.mu.RLock()
+			dcp := tm.dcp
+			tm.mu.RUnlock()
+			if dcp == nil {
+				return errors.NotValid.Newf("[ddl] WithColumnCompression requires a connection pool for table %q", tableName)
 			}
-
-			return err
+			return errors.WithStack(dcp.Options(dml.WithColumnCompression(enc, dec, tableName, columns...)))
 		},
 	}
 }
@@ -503,6 +582,18 @@ func (tm *Tables) CachedQueries() map[string]string {
 	return ret
 }
 
+// AllowList creates a dml.AllowList from all cached queries, which includes the
+// queries of the dmlgen generated code, and the additional queries. Run it
+// during CI and write the AllowList into a file which gets loaded in
+// production with dml.ReadAllowList.
+func (tm *Tables) AllowList(additionalQueries ...string) *dml.AllowList {
+	al := dml.NewAllowList(additionalQueries...)
+	for _, sqlStr := range tm.CachedQueries() {
+		al.Add(sqlStr)
+	}
+	return al
+}
+
 // errTableNotFound provides a custom error behaviour with not capturing the
 // stack trace and hence less allocs.
 type errTableNotFound string
@@ -608,6 +699,116 @@ func (tm *Tables) DeleteAllFromCache() {
 	tm.queries = make(map[string]*dml.DBR)
 }
 
+// AddTable adds a new table to the registry and notifies the change hooks.
+// Returns an AlreadyExists error if the table has already been added. Safe for
+// concurrent use.
+func (tm *Tables) AddTable(t *Table) error {
+	if err := dml.IsValidIdentifier(t.Name); err != nil {
+		return errors.WithStack(err)
+	}
+	tm.mu.Lock()
+	if _, ok := tm.tm[t.Name]; ok {
+		tm.mu.Unlock()
+		return errors.AlreadyExists.Newf("[ddl] Table %q has already been added", t.Name)
+	}
+	t.dcp = tm.dcp
+	tm.tm[t.Name] = t.update()
+	tm.mu.Unlock()
+
+	tm.notify(TableEventAdded, nil, t)
+	return nil
+}
+
+// RemoveTable removes a table from the registry and notifies the change hooks.
+// Returns a NotFound error if the table does not exist. Safe for concurrent
+// use.
+func (tm *Tables) RemoveTable(name string) error {
+	tm.mu.Lock()
+	t, ok := tm.tm[name]
+	if !ok {
+		tm.mu.Unlock()
+		return errTableNotFound(name)
+	}
+	delete(tm.tm, name)
+	tm.mu.Unlock()
+
+	tm.notify(TableEventRemoved, t, nil)
+	return nil
+}
+
+// Reload re-introspects the tables and their columns from the database, e.g.
+// after a migration, and notifies the change hooks. An empty tableNames
+// argument reloads all registered tables. A reloaded table replaces the
+// registered *Table pointer instead of modifying it, so in-flight queries
+// using the previous table keep working. Registered tables which do not exist
+// anymore in the database get removed and not yet registered tables get added.
+// Safe for concurrent use.
+func (tm *Tables) Reload(ctx context.Context, tableNames ...string) error {
+	if tm.dcp == nil {
+		return errors.NotValid.Newf("[ddl] Tables.Reload requires a database connection. Use option WithConnPool.")
+	}
+	if len(tableNames) == 0 {
+		tableNames = tm.Tables()
+	} else {
+		tableNames = append(make([]string, 0, len(tableNames)), tableNames...)
+	}
+	if len(tableNames) == 0 {
+		return nil
+	}
+	sort.Strings(tableNames)
+
+	loaded, err := loadTables(ctx, tm.dcp.DB, tableNames)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	loadedByName := make(map[string]*Table, len(loaded))
+	for _, t := range loaded {
+		loadedByName[t.Name] = t
+	}
+
+	type change struct {
+		event          TableEvent
+		oldTbl, newTbl *Table
+	}
+	changes := make([]change, 0, len(tableNames))
+
+	tm.mu.Lock()
+	for _, tn := range tableNames {
+		oldTbl := tm.tm[tn]
+		newTbl, ok := loadedByName[tn]
+		switch {
+		case !ok && oldTbl == nil:
+			continue
+		case !ok:
+			delete(tm.tm, tn)
+			changes = append(changes, change{event: TableEventRemoved, oldTbl: oldTbl})
+			continue
+		}
+		newTbl.dcp = tm.dcp
+		tm.tm[tn] = newTbl.update()
+		if oldTbl == nil {
+			changes = append(changes, change{event: TableEventAdded, newTbl: newTbl})
+		} else {
+			changes = append(changes, change{event: TableEventReloaded, oldTbl: oldTbl, newTbl: newTbl})
+		}
+	}
+	tm.mu.Unlock()
+
+	for _, c := range changes {
+		tm.notify(c.event, c.oldTbl, c.newTbl)
+	}
+	return nil
+}
+
+func (tm *Tables) notify(event TableEvent, oldTbl, newTbl *Table) {
+	tm.mu.RLock()
+	hooks := tm.changeHooks
+	tm.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(event, oldTbl, newTbl)
+	}
+}
+
 // Validate validates the table names and their column against the current
 // database schema. The context is used to maybe cancel the "Load Columns"
 // query.
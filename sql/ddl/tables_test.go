@@ -298,6 +298,31 @@ func TestWithCreateTable_Mock_DoesNotCreateTable(t *testing.T) {
 	// t.Log(table.Columns.GoString())
 }
 
+func TestLoadColumns_CharsetCollation(t *testing.T) {
+	t.Parallel()
+
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	rows := sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_NAME", "ORDINAL_POSITION", "COLUMN_DEFAULT", "IS_NULLABLE", "DATA_TYPE", "CHARACTER_MAXIMUM_LENGTH", "NUMERIC_PRECISION", "NUMERIC_SCALE", "COLUMN_TYPE", "COLUMN_KEY", "EXTRA", "COLUMN_COMMENT", "CHARACTER_SET_NAME", "COLLATION_NAME"}).
+		FromCSVString(
+			`"admin_user","user_id",1,NULL,"NO","int",NULL,10,0,"int(10) unsigned","PRI","auto_increment","User ID",NULL,NULL
+"admin_user","firstname",2,NULL,"YES","varchar",32,NULL,NULL,"varchar(32)","","","User First Name","utf8mb4","utf8mb4_unicode_ci"
+`)
+	dbMock.ExpectQuery("SELECT.+CHARACTER_SET_NAME, COLLATION_NAME.+FROM information_schema.COLUMNS WHERE TABLE_SCHEMA=DATABASE\\(\\) AND TABLE_NAME.+").
+		WillReturnRows(rows)
+
+	tc, err := ddl.LoadColumns(context.TODO(), dbc.DB, "admin_user")
+	assert.NoError(t, err)
+	cols := tc["admin_user"]
+	assert.False(t, cols.ByField("user_id").CharacterSet.Valid)
+	assert.Exactly(t, "User First Name", cols.ByField("firstname").Comment)
+	assert.Exactly(t, null.MakeString("utf8mb4"), cols.ByField("firstname").CharacterSet)
+	assert.Exactly(t, null.MakeString("utf8mb4_unicode_ci"), cols.ByField("firstname").Collation)
+	assert.Exactly(t, `&ddl.Column{Field: "firstname", Pos: 2, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(32), ColumnType: "varchar(32)", Comment: "User First Name", CharacterSet: null.MakeString("utf8mb4"), Collation: null.MakeString("utf8mb4_unicode_ci"), }`,
+		cols.ByField("firstname").GoString())
+}
+
 func TestWithCreateTable_Mock_DoesCreateTable(t *testing.T) {
 	t.Parallel()
 
@@ -678,3 +703,197 @@ func TestWithQueryDBR(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Exactly(t, "SELECT * FROM `a1`", sqlStr)
 }
+
+func TestTables_AllowList(t *testing.T) {
+	ts := ddl.MustNewTables()
+	assert.NoError(t, ts.Options(ddl.WithQueryDBR("key1", dml.NewSelect("*").From("a1").Where(dml.Column("id").In().PlaceHolder()).WithDBR())))
+
+	al := ts.AllowList("DELETE FROM `a1` WHERE `id` = ?")
+	assert.Exactly(t, 2, al.Len())
+	_, ok := al.Contains("SELECT * FROM `a1` WHERE (`id` IN (1,2,3))")
+	assert.True(t, ok)
+	_, ok = al.Contains("DELETE FROM `a1` WHERE `id` = 5")
+	assert.True(t, ok)
+	_, ok = al.Contains("DELETE FROM `a1`")
+	assert.False(t, ok)
+}
+
+func TestTables_AutoIncrementStatus(t *testing.T) {
+	t.Parallel()
+
+	db, mock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, db, mock)
+
+	mock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT TABLE_NAME, AUTO_INCREMENT FROM information_schema.TABLES WHERE TABLE_SCHEMA=DATABASE() AND TABLE_NAME IN ('customer_entity','sales_order') ORDER BY TABLE_NAME")).
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "AUTO_INCREMENT"}).
+			AddRow("customer_entity", 2000000000).
+			AddRow("sales_order", 4000))
+
+	var warnings []ddl.AutoIncrement
+	ts := ddl.MustNewTables(
+		ddl.WithConnPool(db),
+		ddl.WithTable("customer_entity",
+			&ddl.Column{Field: "entity_id", DataType: "int", ColumnType: "int(11)", Extra: "auto_increment"},
+		),
+		ddl.WithTable("sales_order",
+			&ddl.Column{Field: "entity_id", DataType: "int", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+		),
+		ddl.WithTable("core_config_data",
+			&ddl.Column{Field: "path", DataType: "varchar", ColumnType: "varchar(255)"},
+		),
+		ddl.WithAutoIncrementWarning(0.9, func(ai ddl.AutoIncrement) {
+			warnings = append(warnings, ai)
+		}),
+	)
+
+	ais, err := ts.AutoIncrementStatus(context.TODO())
+	assert.NoError(t, err)
+	assert.Exactly(t, []ddl.AutoIncrement{
+		{TableName: "customer_entity", ColumnName: "entity_id", ColumnType: "int(11)", Current: 2000000000, Max: 2147483647},
+		{TableName: "sales_order", ColumnName: "entity_id", ColumnType: "int(10) unsigned", Current: 4000, Max: 4294967295},
+	}, ais)
+	assert.Exactly(t, uint64(147483647), ais[0].Remaining())
+
+	assert.Len(t, warnings, 1)
+	assert.Exactly(t, "customer_entity", warnings[0].TableName)
+
+	t.Run("rows close error", func(t *testing.T) {
+		mock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT TABLE_NAME, AUTO_INCREMENT FROM information_schema.TABLES")).
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "AUTO_INCREMENT"}).
+				AddRow("sales_order", 4000).
+				CloseError(errors.ConnectionFailed.Newf("close failed")))
+		_, err := ts.AutoIncrementStatus(context.TODO())
+		assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		err := ts.Options(ddl.WithAutoIncrementWarning(1.5, nil))
+		assert.ErrorIsKind(t, errors.OutOfRange, err)
+	})
+}
+
+func TestTables_AddRemoveReload(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	var events []string
+	ts := ddl.MustNewTables(
+		ddl.WithConnPool(dbc),
+		ddl.WithTable("core_config_data", &ddl.Column{Field: "config_id", DataType: "int", Key: "PRI"}),
+		ddl.WithTable("old_table", &ddl.Column{Field: "id", DataType: "int", Key: "PRI"}),
+		ddl.WithTableChangeHook(func(event ddl.TableEvent, oldTbl, newTbl *ddl.Table) {
+			switch event {
+			case ddl.TableEventAdded:
+				events = append(events, "added:"+newTbl.Name)
+			case ddl.TableEventRemoved:
+				events = append(events, "removed:"+oldTbl.Name)
+			case ddl.TableEventReloaded:
+				events = append(events, "reloaded:"+oldTbl.Name+":"+newTbl.Name)
+			}
+		}),
+	)
+
+	t.Run("AddTable", func(t *testing.T) {
+		assert.NoError(t, ts.AddTable(&ddl.Table{Name: "new_table"}))
+		assert.ErrorIsKind(t, errors.AlreadyExists, ts.AddTable(&ddl.Table{Name: "new_table"}))
+		assert.ErrorIsKind(t, errors.NotValid, ts.AddTable(&ddl.Table{Name: "new table"}))
+		assert.Exactly(t, 3, ts.Len())
+	})
+	t.Run("RemoveTable", func(t *testing.T) {
+		assert.NoError(t, ts.RemoveTable("new_table"))
+		assert.ErrorIsKind(t, errors.NotFound, ts.RemoveTable("new_table"))
+		assert.Exactly(t, 2, ts.Len())
+	})
+	t.Run("Reload", func(t *testing.T) {
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.COLUMNS WHERE.+TABLE_NAME IN.+").
+			WillReturnRows(
+				dmltest.MustMockRows(dmltest.WithFile("testdata/core_config_data_columns.csv")))
+		dbMock.ExpectQuery("SELECT.+FROM information_schema.TABLES WHERE.+TABLE_NAME IN.+").
+			WillReturnRows(
+				dmltest.MustMockRows(dmltest.WithFile("testdata/core_config_data_tables.csv")))
+
+		inFlight := ts.MustTable("core_config_data")
+		assert.NoError(t, ts.Reload(context.TODO()))
+
+		assert.Exactly(t, 1, inFlight.Columns.Len(), "in-flight table must not be modified")
+		reloaded := ts.MustTable("core_config_data")
+		assert.Exactly(t, "Config Data", reloaded.TableComment)
+		assert.True(t, reloaded.Columns.Len() > 1)
+		_, err := ts.Table("old_table")
+		assert.ErrorIsKind(t, errors.NotFound, err)
+	})
+	t.Run("Reload without connection", func(t *testing.T) {
+		assert.ErrorIsKind(t, errors.NotValid, ddl.MustNewTables().Reload(context.TODO()))
+	})
+
+	assert.Exactly(t, []string{
+		"added:new_table",
+		"removed:new_table",
+		"reloaded:core_config_data:core_config_data",
+		"removed:old_table",
+	}, events)
+}
+
+// plainCodec implements dml.ValueEncoder and dml.ValueDecoder without any
+// compression.
+type plainCodec struct{}
+
+func (plainCodec) EncodeAll(src, dst []byte) []byte            { return append(dst, src...) }
+func (plainCodec) DecodeAll(input, dst []byte) ([]byte, error) { return append(dst, input...), nil }
+
+func TestWithColumnCompression(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	ts := ddl.MustNewTables(
+		ddl.WithConnPool(dbc),
+		ddl.WithTable("catalog_product_text",
+			&ddl.Column{Field: "value_id", DataType: "int", ColumnType: "int(11)"},
+			&ddl.Column{Field: "value", DataType: "mediumblob", ColumnType: "mediumblob"},
+			&ddl.Column{Field: "label", DataType: "text", ColumnType: "text"},
+		),
+	)
+
+	t.Run("column not found", func(t *testing.T) {
+		err := ts.Options(ddl.WithColumnCompression(plainCodec{}, plainCodec{}, "catalog_product_text", "value_xx"))
+		assert.ErrorIsKind(t, errors.NotFound, err)
+	})
+	t.Run("table not found", func(t *testing.T) {
+		err := ts.Options(ddl.WithColumnCompression(plainCodec{}, plainCodec{}, "catalog_product_xx", "value"))
+		assert.ErrorIsKind(t, errors.NotFound, err)
+	})
+	t.Run("text column not acceptable", func(t *testing.T) {
+		err := ts.Options(ddl.WithColumnCompression(plainCodec{}, plainCodec{}, "catalog_product_text", "value", "label"))
+		assert.ErrorIsKind(t, errors.NotAcceptable, err)
+	})
+	t.Run("compresses arguments", func(t *testing.T) {
+		assert.NoError(t, ts.Options(ddl.WithColumnCompression(plainCodec{}, plainCodec{}, "catalog_product_text", "value")))
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `catalog_product_text` (`value_id`,`value`) VALUES (?,?)")).
+			WithArgs(int64(1), []byte("\xfecz\x01Hello")).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err := dbc.InsertInto("catalog_product_text").AddColumns("value_id", "value").WithDBR().
+			ExecContext(context.TODO(), &productText{ID: 1, Value: "Hello"})
+		assert.NoError(t, err)
+	})
+}
+
+type productText struct {
+	ID    int64
+	Value string
+}
+
+func (p *productText) MapColumns(cm *dml.ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "value_id":
+			cm.Int64(&p.ID)
+		case "value":
+			cm.String(&p.Value)
+		default:
+			return errors.NotFound.Newf("[ddl_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
@@ -0,0 +1 @@
+DROP TABLE `sku_log`;
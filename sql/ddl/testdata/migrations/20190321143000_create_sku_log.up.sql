@@ -0,0 +1,6 @@
+-- Log of changed SKUs
+CREATE TABLE `sku_log` (
+	`id` INT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	`sku` VARCHAR(64) NOT NULL
+);
+CREATE INDEX `IDX_SKU_LOG_SKU` ON `sku_log` (`sku`);
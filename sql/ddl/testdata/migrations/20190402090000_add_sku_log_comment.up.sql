@@ -0,0 +1 @@
+ALTER TABLE `sku_log` ADD COLUMN `comment` TEXT NULL;
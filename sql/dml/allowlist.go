@@ -0,0 +1,358 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// AllowList contains the digests of all statements which are allowed to be
+// executed. Once applied via WithAllowList, each statement gets normalized and
+// its digest must be part of the AllowList, otherwise the statement gets
+// rejected with an error of kind NotAllowed. The AllowList should be generated
+// during CI from the cached queries of the builders and ddl.Tables, which
+// includes the queries of the dmlgen generated code, and then loaded in
+// production with ReadAllowList. Safe for concurrent use.
+type AllowList struct {
+	// LogOnly if true, unknown statements get logged as Info message and
+	// executed, instead of being rejected. Useful for the first roll out.
+	LogOnly bool
+	mu      sync.RWMutex
+	digests map[string]string // digest => normalized query, might be empty
+}
+
+// NewAllowList creates a new AllowList and adds the digests of the queries.
+func NewAllowList(queries ...string) *AllowList {
+	al := &AllowList{
+		digests: make(map[string]string, len(queries)),
+	}
+	return al.Add(queries...)
+}
+
+// Add adds the digests of the queries. The queries can contain placeholders or
+// interpolated arguments.
+func (al *AllowList) Add(queries ...string) *AllowList {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	for _, q := range queries {
+		nq := NormalizeQuery(q)
+		al.digests[digest(nq)] = nq
+	}
+	return al
+}
+
+// AddDigests adds already calculated digests, see QueryDigest.
+func (al *AllowList) AddDigests(digests ...string) *AllowList {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	for _, d := range digests {
+		if _, ok := al.digests[d]; !ok {
+			al.digests[d] = ""
+		}
+	}
+	return al
+}
+
+// Contains returns the digest of the query and reports whether the digest is
+// part of the AllowList.
+func (al *AllowList) Contains(query string) (digest string, ok bool) {
+	digest = QueryDigest(query)
+	al.mu.RLock()
+	_, ok = al.digests[digest]
+	al.mu.RUnlock()
+	return digest, ok
+}
+
+// Len returns the number of digests.
+func (al *AllowList) Len() int {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+	return len(al.digests)
+}
+
+// WriteTo writes one digest per line followed by its normalized query, sorted
+// by the normalized query. Digests without a query get written last. The
+// output can be read with ReadAllowList and is stable, so that it can be
+// checked into the repository and reviewed.
+func (al *AllowList) WriteTo(w io.Writer) (n int64, err error) {
+	al.mu.RLock()
+	defer al.mu.RUnlock()
+
+	lines := make([]string, 0, len(al.digests))
+	for d, q := range al.digests {
+		if q != "" {
+			d += " " + q
+		}
+		lines = append(lines, d)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i][sha256.Size*2:]+lines[i] < lines[j][sha256.Size*2:]+lines[j]
+	})
+
+	for _, l := range lines {
+		n2, err := io.WriteString(w, l+"\n")
+		n += int64(n2)
+		if err != nil {
+			return n, errors.WithStack(err)
+		}
+	}
+	return n, nil
+}
+
+// ReadAllowList reads the format as written by AllowList.WriteTo. Empty lines
+// and lines starting with # get ignored. Each line must start with a digest.
+func ReadAllowList(r io.Reader) (*AllowList, error) {
+	al := NewAllowList()
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), 1<<20) // long INSERT statements
+	var lineNo int
+	for s.Scan() {
+		lineNo++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		d := line
+		var q string
+		if i := strings.IndexByte(line, ' '); i > 0 {
+			d, q = line[:i], line[i+1:]
+		}
+		if _, err := hex.DecodeString(d); err != nil || len(d) != sha256.Size*2 {
+			return nil, errors.NotValid.Newf("[dml] ReadAllowList: Invalid digest %q in line %d", d, lineNo)
+		}
+		al.digests[d] = q
+	}
+	if err := s.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return al, nil
+}
+
+// WithAllowList checks the digest of each statement of the ConnPool and its
+// connections and transactions against the AllowList before the execution.
+// Unknown statements return an error of kind NotAllowed, or if
+// AllowList.LogOnly has been set, get logged as Info message.
+func WithAllowList(al *AllowList) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 10,
+		fn: func(c *ConnPool) error {
+			c.allowList = al
+			return nil
+		},
+	}
+}
+
+// checkAllowList returns an error of kind NotAllowed if the digest of the query
+// is not part of the AllowList. Prepared statements have an empty query and
+// get checked with their cached SQL.
+func (a *DBR) checkAllowList(query string) error {
+	if query == "" {
+		query = a.base.cachedSQL[a.base.cacheKey]
+	}
+	d, ok := a.base.allowList.Contains(query)
+	if ok {
+		return nil
+	}
+	if !a.base.allowList.LogOnly {
+		return errors.NotAllowed.Newf("[dml] Query with ID %q and digest %q is not part of the allow list: %q", a.base.id, d, query)
+	}
+	if a.base.Log != nil && a.base.Log.IsInfo() {
+		a.base.Log.Info("AllowList.NotAllowed", log.String("id", a.base.id), log.String("digest", d), log.String("sql", query))
+	}
+	return nil
+}
+
+// QueryDigest returns the hex encoded SHA256 hash of the normalized query. See
+// NormalizeQuery.
+func QueryDigest(query string) string {
+	return digest(NormalizeQuery(query))
+}
+
+func digest(normalizedQuery string) string {
+	h := sha256.Sum256([]byte(normalizedQuery))
+	return hex.EncodeToString(h[:])
+}
+
+// NormalizeQuery converts a query into its canonical form, so that the same
+// statement with different arguments results in the same digest:
+//	- comments get removed, also the ID comment of the builders, but the
+//	  contents of executable comments /*! */, /*M! */ and of optimizer hints
+//	  /*+ */ are kept,
+//	- string and number literals get replaced with a question mark,
+//	- a list of question marks gets collapsed into one question mark and the
+//	  placeholder of the IN operator gets enclosed in parentheses,
+//	- repeated equal tuples like in INSERT VALUES get collapsed into one tuple,
+//	- white spaces get collapsed into one space and removed after an opening
+//	  parenthesis and around commas.
+// Keywords and identifiers keep their case.
+//		SELECT * FROM `t` WHERE `id` IN (1, 2,3) AND name = 'x'
+// Gets converted to:
+//		SELECT * FROM `t` WHERE `id` IN (?) AND name = ?
+func NormalizeQuery(query string) string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	var pendingSpace bool
+	writeToken := func(token string) {
+		if pendingSpace && buf.Len() > 0 && token[0] != ')' && token[0] != ',' {
+			if last := buf.Bytes()[buf.Len()-1]; last != '(' && last != ',' {
+				buf.WriteByte(' ')
+			}
+		}
+		pendingSpace = false
+		buf.WriteString(token)
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pendingSpace = true
+
+		case c == '/' && isExecutableComment(query[i:]):
+			// The server executes the contents, so they must be part of the
+			// digest. The closing */ gets written by the default case.
+			j := i + 3
+			if query[i+2] == 'M' {
+				j++
+			}
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++ // version
+			}
+			writeToken(query[i:j])
+			i = j - 1
+
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				writeToken(query[i:]) // unterminated, keep it
+				i = len(query)
+				break
+			}
+			i += end + 3
+			pendingSpace = true
+
+		case c == '#' || (c == '-' && strings.HasPrefix(query[i:], "-- ")):
+			if end := strings.IndexByte(query[i:], '\n'); end < 0 {
+				i = len(query)
+			} else {
+				i += end
+			}
+			pendingSpace = true
+
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i, c)
+			writeToken(placeHolderStr)
+
+		case c == '`':
+			j := skipQuoted(query, i, c)
+			writeToken(query[i : j+1])
+			i = j
+
+		case isDigestWordByte(c):
+			j := i + 1
+			for j < len(query) && (isDigestWordByte(query[j]) || (c >= '0' && c <= '9' && query[j] == '.')) {
+				j++
+			}
+			if c >= '0' && c <= '9' {
+				writeToken(placeHolderStr) // number, also 0x1F, 1.5e3
+			} else {
+				writeToken(query[i:j])
+			}
+			i = j - 1
+
+		default:
+			writeToken(query[i : i+1])
+		}
+	}
+	return collapseDigestTuples(collapseDigestLists(buf.String()))
+}
+
+// isExecutableComment reports whether the query starts with a comment whose
+// contents get executed by MySQL or MariaDB.
+func isExecutableComment(query string) bool {
+	return strings.HasPrefix(query, "/*!") || strings.HasPrefix(query, "/*M!") || strings.HasPrefix(query, "/*+")
+}
+
+// skipQuoted returns the position of the closing quote. Escaped quotes with a
+// backslash or a doubled quote get skipped. Returns the last position if the
+// quote does not get closed.
+func skipQuoted(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(query) - 1
+}
+
+func isDigestWordByte(c byte) bool {
+	return c == '_' || c == '$' || c == '@' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// collapseDigestLists collapses ?,?,? into ? and adds the parentheses to the
+// placeholder of the IN operator, which gets expanded later into a list.
+func collapseDigestLists(s string) string {
+	for strings.Contains(s, "?,?") {
+		s = strings.Replace(s, "?,?", placeHolderStr, -1)
+	}
+	return strings.Replace(s, " IN ?", " IN (?)", -1)
+}
+
+// collapseDigestTuples collapses repeated equal tuples without nested
+// parentheses like (?,DEFAULT),(?,DEFAULT) into (?,DEFAULT).
+func collapseDigestTuples(s string) string {
+	if !strings.Contains(s, "),(") {
+		return s
+	}
+	var buf strings.Builder
+	buf.Grow(len(s))
+	for i := 0; i < len(s); {
+		if s[i] == '(' {
+			if j := strings.IndexAny(s[i+1:], "()"); j >= 0 && s[i+1+j] == ')' {
+				tuple := s[i : i+j+2]
+				buf.WriteString(tuple)
+				i += len(tuple)
+				for strings.HasPrefix(s[i:], ",") && strings.HasPrefix(s[i+1:], tuple) {
+					i += len(tuple) + 1
+				}
+				continue
+			}
+		}
+		buf.WriteByte(s[i])
+		i++
+	}
+	return buf.String()
+}
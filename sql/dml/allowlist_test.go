@@ -0,0 +1,134 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM `t` WHERE `id` IN (1, 2,3) AND name = 'x'", "SELECT * FROM `t` WHERE `id` IN (?) AND name = ?"},
+		{"SELECT * FROM `t` WHERE `id` IN (?,?,?) AND name = ?", "SELECT * FROM `t` WHERE `id` IN (?) AND name = ?"},
+		{"/*ID$abc*/ SELECT  `a`\n\tFROM `t` -- comment\nWHERE `b` > -1.5e3 # comment", "SELECT `a` FROM `t` WHERE `b` > -?"},
+		{"INSERT INTO `t` (`a`,`b`) VALUES (1,'it''s'),(2,\"x\\\"y\"),(3,DEFAULT)", "INSERT INTO `t` (`a`,`b`) VALUES (?),(?,DEFAULT)"},
+		{"INSERT INTO `t` (`a`,`b`) VALUES (?,?),(?,?)", "INSERT INTO `t` (`a`,`b`) VALUES (?)"},
+		{"SELECT `col1`, `t2`.`col3` FROM `t2` LIMIT 0x1F", "SELECT `col1`,`t2`.`col3` FROM `t2` LIMIT ?"},
+		{"SELECT '1 /* no comment */'", "SELECT ?"},
+		{"DELETE FROM `t` WHERE (`id` NOT IN ?)", "DELETE FROM `t` WHERE (`id` NOT IN (?))"},
+		{"SELECT /*+ MAX_EXECUTION_TIME(1000) */ `a` FROM `t` WHERE `id`=5 /*!50000 UNION SELECT 'x'*/", "SELECT /*+ MAX_EXECUTION_TIME(?) */ `a` FROM `t` WHERE `id`=? /*!50000 UNION SELECT ?*/"},
+		{"SELECT 1 /*M!100200 , 2 */", "SELECT ? /*M!100200,? */"},
+	}
+	for i, test := range tests {
+		assert.Exactly(t, test.want, dml.NormalizeQuery(test.query), "Index %d", i)
+	}
+	assert.Exactly(t, dml.QueryDigest("SELECT 1"), dml.QueryDigest("SELECT   2 /* other */"))
+	assert.Len(t, dml.QueryDigest("SELECT 1"), 64)
+}
+
+func TestAllowList_ReadWrite(t *testing.T) {
+	t.Parallel()
+
+	al := dml.NewAllowList("SELECT * FROM `b` WHERE `id` = ?", "DELETE FROM `a` WHERE `id` IN (?)")
+	al.AddDigests(dml.QueryDigest("UPDATE `c` SET `d` = 1"))
+	assert.Exactly(t, 3, al.Len())
+
+	var buf bytes.Buffer
+	_, err := al.WriteTo(&buf)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Exactly(t, dml.QueryDigest("DELETE FROM `a` WHERE `id` IN (7)")+" DELETE FROM `a` WHERE `id` IN (?)", lines[0])
+	assert.Exactly(t, dml.QueryDigest("UPDATE `c` SET `d` = 1"), lines[2])
+
+	al2, err := dml.ReadAllowList(strings.NewReader("# generated\n\n" + buf.String()))
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, al2.Len())
+	_, ok := al2.Contains("SELECT * FROM `b` WHERE `id` = 44")
+	assert.True(t, ok)
+	_, ok = al2.Contains("UPDATE `c` SET `d` = 2")
+	assert.True(t, ok)
+	_, ok = al2.Contains("SELECT * FROM `b` WHERE `id` = 44 /*!50000 UNION SELECT password FROM admin_user*/")
+	assert.False(t, ok, "Executable comments must change the digest")
+	_, ok = al2.Contains("SELECT * FROM `b` WHERE `id` = 44 /* harmless */")
+	assert.True(t, ok)
+
+	al2, err = dml.ReadAllowList(strings.NewReader("xyz SELECT 1\n"))
+	assert.Nil(t, al2)
+	assert.True(t, errors.NotValid.Match(err), "%+v", err)
+}
+
+func TestWithAllowList(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	al := dml.NewAllowList("SELECT `email` FROM `customer` WHERE (`id` IN ?)", "DELETE FROM `customer` WHERE (`id` = ?)")
+	assert.NoError(t, dbc.Options(dml.WithAllowList(al)))
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer` WHERE (`id` = 3)")).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer` WHERE (`id` IN (4,5))")).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("a@b.c"))
+
+	_, err := dbc.DeleteFrom("customer").Where(dml.Column("id").Int(3)).WithDBR().ExecContext(context.TODO())
+	assert.NoError(t, err)
+	emails, err := dbc.SelectFrom("customer").AddColumns("email").Where(dml.Column("id").In().PlaceHolder()).
+		WithDBR().Interpolate().LoadStrings(context.TODO(), nil, []int64{4, 5})
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"a@b.c"}, emails)
+
+	_, err = dbc.DeleteFrom("customer").WithDBR().ExecContext(context.TODO())
+	assert.True(t, errors.NotAllowed.Match(err), "%+v", err)
+
+	t.Run("log only", func(t *testing.T) {
+		al.LogOnly = true
+		defer func() { al.LogOnly = false }()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")).WillReturnResult(sqlmock.NewResult(0, 9))
+		_, err = dbc.DeleteFrom("customer").WithDBR().ExecContext(context.TODO())
+		assert.NoError(t, err)
+	})
+
+	t.Run("every execution path", func(t *testing.T) {
+		var email string
+		err := dbc.SelectFrom("customer").AddColumns("email").Limit(0, 1).WithDBR().
+			QueryRowContext(context.TODO()).Scan(&email)
+		assert.True(t, errors.NotAllowed.Match(err), "%+v", err)
+
+		_, err = dbc.Union(
+			dbc.SelectFrom("customer").AddColumns("email"),
+			dbc.SelectFrom("admin_user").AddColumns("email"),
+		).WithDBR().LoadStrings(context.TODO(), nil)
+		assert.True(t, errors.NotAllowed.Match(err), "%+v", err)
+
+		_, err = dbc.SelectFrom("customer").Star().ColumnStats(context.TODO(), dml.ColumnStatsOptions{})
+		assert.True(t, errors.NotAllowed.Match(err), "%+v", err)
+
+		_, err = dbc.SelectFrom("customer").Star().ExplainAnalyze(context.TODO())
+		assert.True(t, errors.NotAllowed.Match(err), "%+v", err)
+	})
+}
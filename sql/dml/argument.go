@@ -18,8 +18,9 @@ import (
 	"bytes"
 	"database/sql"
 	"database/sql/driver"
+	"encoding"
 	"math"
-	"strconv"
+	"reflect"
 	"time"
 	"unicode/utf8"
 
@@ -30,8 +31,9 @@ import (
 // https://www.adampalmer.me/iodigitalsec/2013/08/18/mysql_real_escape_string-wont-magically-solve-your-sql-injection-problems/
 
 const (
-	sqlStrNullUC = "NULL"
-	sqlStar      = "*"
+	sqlStrNullUC    = "NULL"
+	sqlStrDefaultUC = "DEFAULT"
+	sqlStar         = "*"
 )
 
 // QualifiedRecord is a ColumnMapper with a qualifier. A QualifiedRecord gets
@@ -59,9 +61,30 @@ func Qualify(q string, record ColumnMapper) QualifiedRecord {
 // writeInterfaceValue.
 type internalNULLNIL struct{}
 
+// internalDEFAULT represents the SQL keyword DEFAULT in an INSERT statement.
+// The place holder of such an argument gets replaced with DEFAULT and the
+// argument itself gets removed from the argument list.
+type internalDEFAULT struct{}
+
+// Default marks a column value in an INSERT ... VALUES statement to use the
+// column default as defined in the table schema instead of NULL or the Go zero
+// value. It can be passed as an argument or gets appended via
+// ColumnMap.Default within a ColumnMapper. Not supported in prepared
+// statements.
+var Default = internalDEFAULT{}
+
+func containsDefaultArg(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(internalDEFAULT); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func sliceLen(arg interface{}) (l int, isSlice bool) {
 	switch v := arg.(type) {
-	case nil, int, int64, uint64, float64, bool, string, []byte, time.Time, null.String, null.Int64, null.Float64, null.Bool, null.Time:
+	case nil, int, int64, uint64, float64, bool, string, []byte, time.Time, null.String, null.Int64, null.Float64, null.Bool, null.Time, internalDEFAULT:
 		l = 1
 	case []int:
 		l = len(v)
@@ -105,6 +128,8 @@ func sliceLen(arg interface{}) (l int, isSlice bool) {
 	case []null.Time:
 		l = len(v)
 		isSlice = true
+	case driver.Valuer, encoding.TextMarshaler:
+		l = 1
 	default:
 		panic(errors.NotSupported.Newf("[dml] Unsupported type: %T => %#v", v, v))
 	}
@@ -112,7 +137,7 @@ func sliceLen(arg interface{}) (l int, isSlice bool) {
 	return
 }
 
-func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error) {
+func writeInterfaceValue(arg interface{}, w *bytes.Buffer, d mysqlDialect, pos uint) (err error) {
 	var requestPos bool
 	if pos > 0 {
 		requestPos = true
@@ -156,17 +181,17 @@ func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error)
 			w.WriteByte(')')
 		}
 	case null.Int64:
-		err = v.WriteTo(dialect, w)
+		err = v.WriteTo(d, w)
 	case []null.Int64:
 		if requestPos {
-			err = v[pos].WriteTo(dialect, w)
+			err = v[pos].WriteTo(d, w)
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = v[i].WriteTo(dialect, w)
+				err = v[i].WriteTo(d, w)
 			}
 			w.WriteByte(')')
 		}
@@ -235,47 +260,47 @@ func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error)
 			w.WriteByte(')')
 		}
 	case null.Float64:
-		err = v.WriteTo(dialect, w)
+		err = v.WriteTo(d, w)
 	case []null.Float64:
 		if requestPos {
-			err = v[pos].WriteTo(dialect, w)
+			err = v[pos].WriteTo(d, w)
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = v[i].WriteTo(dialect, w)
+				err = v[i].WriteTo(d, w)
 			}
 			w.WriteByte(')')
 		}
 	case bool:
-		dialect.EscapeBool(w, v)
+		d.EscapeBool(w, v)
 	case []bool:
 		if requestPos {
-			dialect.EscapeBool(w, v[pos])
+			d.EscapeBool(w, v[pos])
 		} else {
 			w.WriteByte('(')
 			for i, val := range v {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				dialect.EscapeBool(w, val)
+				d.EscapeBool(w, val)
 			}
 			w.WriteByte(')')
 		}
 	case null.Bool:
-		v.WriteTo(dialect, w)
+		v.WriteTo(d, w)
 	case []null.Bool:
 		if requestPos {
-			v[pos].WriteTo(dialect, w)
+			v[pos].WriteTo(d, w)
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = v[i].WriteTo(dialect, w)
+				err = v[i].WriteTo(d, w)
 			}
 			w.WriteByte(')')
 		}
@@ -283,11 +308,11 @@ func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error)
 		if !utf8.ValidString(v) {
 			return errors.NotValid.Newf("[dml] Argument.WriteTo: String is not UTF-8: %q", v)
 		}
-		dialect.EscapeString(w, v)
+		d.EscapeString(w, v)
 	case []string:
 		if requestPos {
 			if nv := v[pos]; utf8.ValidString(nv) {
-				dialect.EscapeString(w, nv)
+				d.EscapeString(w, nv)
 			} else {
 				err = errors.NotValid.Newf("[dml] Argument.WriteTo: String is not UTF-8: %q", nv)
 			}
@@ -298,7 +323,7 @@ func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error)
 					w.WriteByte(',')
 				}
 				if nv := v[i]; utf8.ValidString(nv) {
-					dialect.EscapeString(w, nv)
+					d.EscapeString(w, nv)
 				} else {
 					err = errors.NotValid.Newf("[dml] Argument.WriteTo: String is not UTF-8: %q", nv)
 				}
@@ -306,79 +331,131 @@ func writeInterfaceValue(arg interface{}, w *bytes.Buffer, pos uint) (err error)
 			w.WriteByte(')')
 		}
 	case null.String:
-		err = v.WriteTo(dialect, w)
+		err = v.WriteTo(d, w)
 	case []null.String:
 		if requestPos {
-			err = v[pos].WriteTo(dialect, w)
+			err = v[pos].WriteTo(d, w)
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = v[i].WriteTo(dialect, w)
+				err = v[i].WriteTo(d, w)
 			}
 			w.WriteByte(')')
 		}
 	case []byte:
-		err = writeBytes(w, v)
+		err = writeBytes(w, d, v)
 
 	case [][]byte:
 		if requestPos {
-			err = writeBytes(w, v[pos])
+			err = writeBytes(w, d, v[pos])
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = writeBytes(w, v[i])
+				err = writeBytes(w, d, v[i])
 			}
 			w.WriteByte(')')
 		}
 	case time.Time:
-		dialect.EscapeTime(w, v)
+		d.EscapeTime(w, v)
 	case []time.Time:
 		if requestPos {
-			dialect.EscapeTime(w, v[pos])
+			d.EscapeTime(w, v[pos])
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					err = w.WriteByte(',')
 				}
-				dialect.EscapeTime(w, v[i])
+				d.EscapeTime(w, v[i])
 			}
 			w.WriteByte(')')
 		}
 	case null.Time:
-		err = v.WriteTo(dialect, w)
+		err = v.WriteTo(d, w)
 	case []null.Time:
 		if requestPos {
-			err = v[pos].WriteTo(dialect, w)
+			err = v[pos].WriteTo(d, w)
 		} else {
 			w.WriteByte('(')
 			for l, i := len(v), 0; i < l && err == nil; i++ {
 				if i > 0 {
 					w.WriteByte(',')
 				}
-				err = v[i].WriteTo(dialect, w)
+				err = v[i].WriteTo(d, w)
 			}
 			w.WriteByte(')')
 		}
 	case internalNULLNIL:
 		_, err = w.WriteString(sqlStrNullUC)
+	case internalDEFAULT:
+		_, err = w.WriteString(sqlStrDefaultUC)
 	case nil:
 		// do nothing
 		// _, err = w.WriteString("[PLEASE USE type internalNULLNIL]")
 	case sql.NamedArg:
-		return writeInterfaceValue(v.Value, w, pos)
+		return writeInterfaceValue(v.Value, w, d, pos)
+	case driver.Valuer:
+		err = writeDriverValuer(w, d, v)
+	case encoding.TextMarshaler:
+		err = writeTextMarshaler(w, d, v)
 	default:
 		return errors.NotSupported.Newf("[dml] Unsupported field type: %T => %#v", arg, arg)
 	}
 	return err
 }
 
+// writeDriverValuer interpolates the value returned by a custom driver.Valuer.
+// The returned value must be one of the types a driver must be able to
+// handle. A nil pointer writes NULL.
+func writeDriverValuer(w *bytes.Buffer, d mysqlDialect, dv driver.Valuer) error {
+	if isNilPointer(dv) {
+		_, err := w.WriteString(sqlStrNullUC)
+		return err
+	}
+	v, err := dv.Value()
+	if err != nil {
+		return errors.Fatal.New(err, "[dml] driver.Valuer error for %T", dv)
+	}
+	switch t := v.(type) {
+	case nil:
+		_, err = w.WriteString(sqlStrNullUC)
+	case int64, float64, bool, []byte, string, time.Time:
+		err = writeInterfaceValue(t, w, d, 0)
+	default:
+		err = errors.NotSupported.Newf("[dml] driver.Valuer %T returned an unsupported type: %T", dv, v)
+	}
+	return err
+}
+
+// writeTextMarshaler interpolates the text returned by an
+// encoding.TextMarshaler as an escaped string. A nil pointer writes NULL.
+func writeTextMarshaler(w *bytes.Buffer, d mysqlDialect, tm encoding.TextMarshaler) error {
+	if isNilPointer(tm) {
+		_, err := w.WriteString(sqlStrNullUC)
+		return err
+	}
+	txt, err := tm.MarshalText()
+	if err != nil {
+		return errors.BadEncoding.New(err, "[dml] encoding.TextMarshaler error for %T", tm)
+	}
+	if !utf8.Valid(txt) {
+		return errors.NotValid.Newf("[dml] Argument.WriteTo: Text of %T is not UTF-8: %q", tm, txt)
+	}
+	d.EscapeString(w, string(txt))
+	return nil
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
 // multiplyInterfaceValues is only applicable when using *Union as a template.
 // multiplyInterfaceValues repeats the `args` variable n-times to match the number of
 // generated SELECT queries in the final UNION statement. It should be called
@@ -416,7 +493,7 @@ func totalSliceLen(args []interface{}) (l int, containsAtLeastOneSlice bool) {
 }
 
 // Write writes all arguments into buf and separates by a comma.
-func writeInterfaces(buf *bytes.Buffer, args []interface{}) error {
+func writeInterfaces(buf *bytes.Buffer, d mysqlDialect, args []interface{}) error {
 	if len(args) > 1 {
 		buf.WriteByte('(')
 	}
@@ -424,7 +501,7 @@ func writeInterfaces(buf *bytes.Buffer, args []interface{}) error {
 		if j > 0 {
 			buf.WriteByte(',')
 		}
-		if err := writeInterfaceValue(arg, buf, 0); err != nil {
+		if err := writeInterfaceValue(arg, buf, d, 0); err != nil {
 			return errors.Wrapf(err, "[dml] args write failed at pos %d with argument %#v", j, arg)
 		}
 	}
@@ -509,14 +586,8 @@ func expandInterface(appendTo []interface{}, arg interface{}) []interface{} {
 			appendTo = v.Append(appendTo)
 		}
 
-		// Get send as text in a byte slice. The MySQL/MariaDB Server type
-		// casts it into a bigint. If you change this, a test will fail.
 	case uint64:
-		if vv > math.MaxInt64 {
-			appendTo = append(appendTo, strconv.AppendUint([]byte{}, vv, 10))
-		} else {
-			appendTo = append(appendTo, int64(vv))
-		}
+		appendTo = append(appendTo, uint64Arg(vv))
 	case null.Uint8:
 		appendTo = vv.Append(appendTo)
 	case []null.Uint8:
@@ -543,11 +614,7 @@ func expandInterface(appendTo []interface{}, arg interface{}) []interface{} {
 		}
 
 	case uint:
-		if vv > math.MaxInt64 {
-			appendTo = append(appendTo, strconv.AppendUint([]byte{}, uint64(vv), 10))
-		} else {
-			appendTo = append(appendTo, int64(vv))
-		}
+		appendTo = append(appendTo, uint64Arg(uint64(vv)))
 
 	case uint8:
 		appendTo = append(appendTo, int64(vv))
@@ -558,19 +625,11 @@ func expandInterface(appendTo []interface{}, arg interface{}) []interface{} {
 
 	case []uint64:
 		for _, v := range vv {
-			if v > math.MaxInt64 {
-				appendTo = append(appendTo, strconv.AppendUint([]byte{}, v, 10))
-			} else {
-				appendTo = append(appendTo, int64(v))
-			}
+			appendTo = append(appendTo, uint64Arg(v))
 		}
 	case []uint:
 		for _, v := range vv {
-			if v > math.MaxInt64 {
-				appendTo = append(appendTo, strconv.AppendUint([]byte{}, uint64(v), 10))
-			} else {
-				appendTo = append(appendTo, int64(v))
-			}
+			appendTo = append(appendTo, uint64Arg(uint64(v)))
 		}
 
 	case []float64:
@@ -630,6 +689,9 @@ func expandInterface(appendTo []interface{}, arg interface{}) []interface{} {
 	case driver.Valuer:
 		dvv, _ := vv.Value()
 		appendTo = expandInterface(appendTo, dvv)
+	case encoding.TextMarshaler:
+		txt, _ := vv.MarshalText()
+		appendTo = append(appendTo, string(txt))
 	case internalNULLNIL:
 		appendTo = expandInterface(appendTo, nil)
 
@@ -734,6 +796,18 @@ func driverValues(appendToArgs []interface{}, dvs ...driver.Valuer) ([]interface
 	return appendToArgs, nil
 }
 
+// uint64Arg returns values up to math.MaxInt64 as int64, the type of a
+// driver.Value. Larger values get returned unchanged as uint64 because
+// github.com/go-sql-driver/mysql, since v1.5, sends them as an unsigned BIGINT.
+// The former conversion into a decimal text required the server to cast the
+// text back into a number.
+func uint64Arg(v uint64) interface{} {
+	if v > math.MaxInt64 {
+		return v
+	}
+	return int64(v)
+}
+
 func iFaceToArgs(args []interface{}, values ...interface{}) ([]interface{}, error) {
 	for _, val := range values {
 		switch v := val.(type) {
@@ -752,7 +826,7 @@ func iFaceToArgs(args []interface{}, values ...interface{}) ([]interface{}, erro
 		case int8:
 			args = append(args, int64(v))
 		case uint64:
-			args = append(args, int64(v))
+			args = append(args, uint64Arg(v))
 		case uint32:
 			args = append(args, int64(v))
 		case uint16:
@@ -17,10 +17,12 @@ package dml
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/corestoreio/errors"
@@ -64,7 +66,7 @@ func (fn QuerySQLFn) ToSQL() (string, []interface{}, error) {
 
 // queryBuilder must support thread safety when writing and reading the cache.
 type queryBuilder interface {
-	toSQL(w *bytes.Buffer, placeHolders []string) ([]string, error)
+	toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error)
 }
 
 // builderCommon
@@ -107,11 +109,97 @@ type builderCommon struct {
 	// dedicated database session) or a *sql.Tx (an in-progress database
 	// transaction).
 	db QueryExecPreparer
+	// connGroups contains the named connection pools of the ConnPool. Used
+	// by Select.UseGroup.
+	connGroups map[string]*sql.DB
+	// replicas if set, receive the queries of a Select. See WithReplicas.
+	replicas *replicaSet
+	// txCTEs contains the common table expressions of a transaction prepended
+	// to the statement. See Tx.RegisterCTE.
+	txCTEs []WithCTE
 	// containsTuples indicates if a SQL query contains the tuples placeholder
 	// (see constant placeHolderTuples) and if true the function
 	// DBR.prepareQueryAndArgs will replace the tuples placeholder with the
 	// correct amount of MySQL/MariaDB placeholders.
 	containsTuples bool
+	// serverTimeZone converts time arguments and scanned time values. See
+	// WithServerTimeZone.
+	serverTimeZone *time.Location
+	// metrics receives the durations of all statements. See WithMetrics.
+	metrics Metrics
+	// hooks get called before and after each statement. See WithQueryHooks.
+	hooks queryHooks
+	// retry if set, retries idempotent statements failing with a transient
+	// error. See WithRetryPolicy.
+	retry *RetryPolicy
+	// allowList if set, checks the digest of each statement before its
+	// execution. See WithAllowList.
+	allowList *AllowList
+	// slowQuery if set, logs statements exceeding a threshold. See
+	// WithSlowQueryLog.
+	slowQuery *slowQueryLog
+	// compression maps a table name to the compressors of its columns. See
+	// WithColumnCompression.
+	compression compressionTables
+	// encryption maps a column name to its encrypter. See
+	// WithColumnEncryption.
+	encryption map[string]*columnEncrypter
+	// lockWait if greater zero, limits the innodb_lock_wait_timeout of a
+	// statement to the remaining time of the context deadline. See
+	// WithDeadlineLockWaitTimeout.
+	lockWait time.Duration
+	// lockWaitSyntax contains the syntax of the server to set the lock wait
+	// timeout. See WithDeadlineLockWaitTimeout.
+	lockWaitSyntax uint8
+	// lockWaitDetector caches the syntax of the server for
+	// Select.LockWaitTimeout, if WithDeadlineLockWaitTimeout has not been
+	// applied. Shared with all derived connections.
+	lockWaitDetector *lockWaitDetector
+	// lockWaitState tracks the lock wait timeout of the session of a Tx or a
+	// Conn. Nil in a ConnPool.
+	lockWaitState *lockWaitState
+	// stmtLockWait if greater zero, limits the innodb_lock_wait_timeout of the
+	// statement. See Select.LockWaitTimeout.
+	stmtLockWait time.Duration
+	// maxExecTime if set, limits the execution time of a SELECT to the
+	// remaining time of the context deadline. See
+	// WithDeadlineMaxExecutionTime.
+	maxExecTime uint8
+	// adaptive if set, interpolates one-off statements and prepares frequently
+	// executed statements. See WithAdaptivePrepare.
+	adaptive *adaptivePrepare
+	// stmtCache if set, caches prepared statements. See WithStmtCache.
+	stmtCache *stmtCache
+	// txStmts if set, keeps the cached statements bound to the transaction.
+	// Nil outside of a Tx.
+	txStmts *txStmtCache
+	// sqlCommenter if set, appends the tags of the context as a comment to
+	// each statement. See WithSQLCommenter.
+	sqlCommenter *SQLCommenter
+	// interpolate if enabled, interpolates the arguments of all statements.
+	// See ConnPool.WithDefaultInterpolate.
+	interpolate *interpolateToggle
+	// returning contains the RETURNING capabilities of the server. See
+	// WithDetectReturning.
+	returning uint8
+	// noBackslashEscapes if true, strings get escaped for the sql_mode
+	// NO_BACKSLASH_ESCAPES. See WithNoBackslashEscapes.
+	noBackslashEscapes bool
+	// jsonMembershipFallback contains the flags fallbackMemberOf and
+	// fallbackJSONOverlaps. See WithJSONMembershipFallback.
+	jsonMembershipFallback uint8
+	// resultCacheNamespace separates the keys of the result cache of different
+	// databases. See WithResultCacheNamespace.
+	resultCacheNamespace string
+}
+
+// sqlDialect returns the dialect to write the SQL string and to interpolate
+// the arguments with the settings of the connection.
+func (bc *builderCommon) sqlDialect() mysqlDialect {
+	d := dialect
+	d.noBackslashEscapes = bc.noBackslashEscapes
+	d.jsonMembershipFallback = bc.jsonMembershipFallback
+	return d
 }
 
 func (bc *builderCommon) withCacheKey(key string, args ...interface{}) {
@@ -178,7 +266,7 @@ func (bb *BuilderBase) buildToSQL(qb queryBuilder) (string, error) {
 	if !ok {
 		buf := bufferpool.Get()
 		defer bufferpool.Put(buf)
-		qualifiedColumns, err := qb.toSQL(buf, []string{})
+		qualifiedColumns, err := qb.toSQL(buf, bb.sqlDialect(), []string{})
 		if err != nil {
 			return "", errors.WithStack(err)
 		}
@@ -204,6 +292,9 @@ func (bb *BuilderBase) prepare(ctx context.Context, db Preparer, qb queryBuilder
 	if in, ok := qb.(*Insert); ok && in != nil && !in.IsBuildValues {
 		return nil, errors.NotAcceptable.Newf("[dml] did you forgot to call .BuildValues()?")
 	}
+	if sel, ok := qb.(*Select); ok && sel != nil && sel.LockWaitTimeoutSeconds > 0 {
+		return nil, errors.NotSupported.Newf("[dml] Select.LockWaitTimeout does not support prepared statements")
+	}
 
 	rawQuery, err := bb.buildToSQL(qb)
 	if bb.Log != nil && bb.Log.IsDebug() {
@@ -213,7 +304,9 @@ func (bb *BuilderBase) prepare(ctx context.Context, db Preparer, qb queryBuilder
 		return nil, errors.WithStack(err)
 	}
 
-	sqlStmt, err := db.PrepareContext(ctx, rawQuery)
+	ev := bb.builderCommon.hookEvent("Prepare", rawQuery, 0)
+	ev.Source = sourceName(source)
+	sqlStmt, err := bb.hooks.prepare(ctx, db, ev)
 	if err != nil {
 		return nil, errors.Wrapf(err, "[dml] Prepare.PrepareContext with query %q", rawQuery)
 	}
@@ -222,9 +315,10 @@ func (bb *BuilderBase) prepare(ctx context.Context, db Preparer, qb queryBuilder
 		base: bb.builderCommon,
 		Stmt: sqlStmt,
 	}
+	stmt.tables, stmt.tablesKnown = builderTables(qb)
 	stmt.base.cacheKey = bb.cacheKey
 	stmt.base.cachedSQLUpsert(bb.cacheKey, rawQuery)
-	stmt.base.db = stmtWrapper{stmt: sqlStmt}
+	stmt.base.db = stmtWrapper{stmt: sqlStmt, inTx: isTxBound(db)}
 	stmt.base.source = source
 	return stmt, nil
 }
@@ -237,6 +331,7 @@ func (bb *BuilderBase) newDBR(qb queryBuilder) *DBR {
 	a := DBR{
 		base: bb.builderCommon,
 	}
+	a.tables, a.tablesKnown = builderTables(qb)
 	if err != nil {
 		a.base.ärgErr = errors.WithStack(err)
 	}
@@ -342,7 +437,7 @@ func sqlWriteUnionAll(w *bytes.Buffer, isAll, isIntersect, isExcept bool) {
 	w.WriteByte('\n')
 }
 
-func sqlWriteOrderBy(w *bytes.Buffer, orderBys ids, br bool) {
+func sqlWriteOrderBy(w *bytes.Buffer, d mysqlDialect, orderBys ids, br bool) {
 	if len(orderBys) == 0 {
 		return
 	}
@@ -352,7 +447,7 @@ func sqlWriteOrderBy(w *bytes.Buffer, orderBys ids, br bool) {
 	}
 	w.WriteRune(brS)
 	w.WriteString("ORDER BY ")
-	orderBys.writeQuoted(w, nil)
+	orderBys.writeQuoted(w, d, nil)
 }
 
 // LIMIT 0,0 quickly returns an empty set. This can be useful for checking the
@@ -390,14 +485,14 @@ func writeUint64(w *bytes.Buffer, i uint64) (err error) {
 	return err
 }
 
-func writeBytes(w *bytes.Buffer, p []byte) (err error) {
+func writeBytes(w *bytes.Buffer, d mysqlDialect, p []byte) (err error) {
 	switch {
 	case p == nil:
 		_, err = w.WriteString(sqlStrNullUC)
 	case !utf8.Valid(p):
-		dialect.EscapeBinary(w, p)
+		d.EscapeBinary(w, p)
 	default:
-		dialect.EscapeString(w, string(p)) // maybe create an EscapeByteString version to avoid one alloc ;-)
+		d.EscapeString(w, string(p)) // maybe create an EscapeByteString version to avoid one alloc ;-)
 	}
 	return
 }
@@ -434,12 +529,17 @@ func calcInsertTemplatePlaceholderPos(columnCount uint) (start, end uint) {
 	return
 }
 
-func writeTuplePlaceholders(buf *bytes.Buffer, rowCount, columnCount uint) {
+// writeTuplePlaceholders writes rowCount tuples of place holders. If
+// rowConstructor is true each tuple gets prefixed with the keyword ROW.
+func writeTuplePlaceholders(buf *bytes.Buffer, rowCount, columnCount uint, rowConstructor bool) {
 	start, end := calcInsertTemplatePlaceholderPos(columnCount)
 	for r := uint(0); r < rowCount; r++ {
 		if r > 0 {
 			buf.WriteByte(',')
 		}
+		if rowConstructor {
+			buf.WriteString("ROW")
+		}
 		if columnCount <= tupleTemplateCount {
 			buf.WriteString(tupleTemplate[start:end])
 		} else {
@@ -0,0 +1,135 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/corestoreio/pkg/storage/null"
+)
+
+// BoundArgs binds arguments to a statement of a query builder and executes
+// it. It unifies the builder and the DBR runner into one fluent API:
+//		rowCount, err := dbc.SelectFrom("customer").Star().
+//			Where(dml.Column("entity_id").In().PlaceHolder()).
+//			Args([]int64{1, 2, 3}).Load(ctx, &customers)
+//		res, err := dbc.Update("customer").AddClauses(dml.Column("email").PlaceHolder()).
+//			Args("a@b.c").ExecContext(ctx)
+// The SQL string stays cached in the query builder, so calling Args again
+// does not rebuild it. For executing the same statement many times, WithDBR
+// should be used instead to reuse the argument allocations. BoundArgs is not
+// safe for concurrent use.
+type BoundArgs struct {
+	dbr  *DBR
+	args []interface{}
+}
+
+func newBoundArgs(a *DBR, args []interface{}) *BoundArgs {
+	return &BoundArgs{dbr: a, args: args}
+}
+
+// DBR returns the underlying runner, e.g. to set options like Interpolate.
+func (ba *BoundArgs) DBR() *DBR { return ba.dbr }
+
+// Interpolate enables interpolation of the arguments into the SQL string.
+func (ba *BoundArgs) Interpolate() *BoundArgs {
+	ba.dbr.Interpolate()
+	return ba
+}
+
+// ToSQL returns the SQL string and its arguments as sent to the server.
+func (ba *BoundArgs) ToSQL() (string, []interface{}, error) {
+	return ba.dbr.prepareQueryAndArgs(ba.args)
+}
+
+// ExecContext executes the statement with the bound arguments.
+func (ba *BoundArgs) ExecContext(ctx context.Context) (sql.Result, error) {
+	return ba.dbr.ExecContext(ctx, ba.args...)
+}
+
+// QueryContext runs the query with the bound arguments.
+func (ba *BoundArgs) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	return ba.dbr.QueryContext(ctx, ba.args...)
+}
+
+// Load loads the rows into the ColumnMapper. See DBR.Load.
+func (ba *BoundArgs) Load(ctx context.Context, s ColumnMapper) (rowCount uint64, err error) {
+	return ba.dbr.Load(ctx, s, ba.args...)
+}
+
+// LoadInt64s loads the first column of each row. See DBR.LoadInt64s.
+func (ba *BoundArgs) LoadInt64s(ctx context.Context, dest []int64) ([]int64, error) {
+	return ba.dbr.LoadInt64s(ctx, dest, ba.args...)
+}
+
+// LoadUint64s loads the first column of each row. See DBR.LoadUint64s.
+func (ba *BoundArgs) LoadUint64s(ctx context.Context, dest []uint64) ([]uint64, error) {
+	return ba.dbr.LoadUint64s(ctx, dest, ba.args...)
+}
+
+// LoadStrings loads the first column of each row. See DBR.LoadStrings.
+func (ba *BoundArgs) LoadStrings(ctx context.Context, dest []string) ([]string, error) {
+	return ba.dbr.LoadStrings(ctx, dest, ba.args...)
+}
+
+// LoadNullInt64s loads the first column of each row including NULLs. See
+// DBR.LoadNullInt64s.
+func (ba *BoundArgs) LoadNullInt64s(ctx context.Context, dest []null.Int64) ([]null.Int64, error) {
+	return ba.dbr.LoadNullInt64s(ctx, dest, ba.args...)
+}
+
+// LoadNullStrings loads the first column of each row including NULLs. See
+// DBR.LoadNullStrings.
+func (ba *BoundArgs) LoadNullStrings(ctx context.Context, dest []null.String) ([]null.String, error) {
+	return ba.dbr.LoadNullStrings(ctx, dest, ba.args...)
+}
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (b *Select) Args(args ...interface{}) *BoundArgs { return newBoundArgs(b.WithDBR(), args) }
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (b *Insert) Args(args ...interface{}) *BoundArgs { return newBoundArgs(b.WithDBR(), args) }
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (b *Update) Args(args ...interface{}) *BoundArgs { return newBoundArgs(b.WithDBR(), args) }
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (b *Delete) Args(args ...interface{}) *BoundArgs { return newBoundArgs(b.WithDBR(), args) }
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (u *Union) Args(args ...interface{}) *BoundArgs { return newBoundArgs(u.WithDBR(), args) }
+
+// Args binds the arguments and returns the runner. See type BoundArgs.
+func (b *With) Args(args ...interface{}) *BoundArgs { return newBoundArgs(b.WithDBR(), args) }
+
+// WithArgs is the former name of WithDBR and kept for compatibility with the
+// WithArgs flow. New code should use WithDBR or Args.
+func (b *Select) WithArgs() *DBR { return b.WithDBR() }
+
+// WithArgs is the former name of WithDBR. See Select.WithArgs.
+func (b *Insert) WithArgs() *DBR { return b.WithDBR() }
+
+// WithArgs is the former name of WithDBR. See Select.WithArgs.
+func (b *Update) WithArgs() *DBR { return b.WithDBR() }
+
+// WithArgs is the former name of WithDBR. See Select.WithArgs.
+func (b *Delete) WithArgs() *DBR { return b.WithDBR() }
+
+// WithArgs is the former name of WithDBR. See Select.WithArgs.
+func (u *Union) WithArgs() *DBR { return u.WithDBR() }
+
+// WithArgs is the former name of WithDBR. See Select.WithArgs.
+func (b *With) WithArgs() *DBR { return b.WithDBR() }
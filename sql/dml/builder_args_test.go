@@ -0,0 +1,78 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestBoundArgs(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	t.Run("Select Load", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` IN ?)")).
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "a@x.io", "A").AddRow(2, "b@x.io", "B"))
+
+		var got saverEntities
+		rowCount, err := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").In().PlaceHolder()).
+			Args([]int64{1, 2}).Load(context.TODO(), &got)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(2), rowCount)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("Update Exec", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=? WHERE (`id` = ?)")).
+			WithArgs("c@x.io", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		res, err := dbc.Update("customer").AddClauses(dml.Column("email").PlaceHolder()).
+			Where(dml.Column("id").PlaceHolder()).Args("c@x.io", 3).ExecContext(context.TODO())
+		assert.NoError(t, err)
+		ra, err := res.RowsAffected()
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(1), ra)
+	})
+
+	t.Run("ToSQL", func(t *testing.T) {
+		sel := dml.NewSelect("id").From("customer").Where(dml.Column("id").PlaceHolder())
+		sqlStr, args, err := sel.Args(5).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `customer` WHERE (`id` = ?)", sqlStr)
+		assert.Exactly(t, []interface{}{int64(5)}, args)
+
+		sqlStr, args, err = sel.Args(6).Interpolate().ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `customer` WHERE (`id` = 6)", sqlStr)
+		assert.Nil(t, args)
+	})
+
+	t.Run("WithArgs compatibility", func(t *testing.T) {
+		compareToSQL(t, dml.NewDelete("customer").Where(dml.Column("id").PlaceHolder()).WithArgs().TestWithArgs(7), errors.NoKind,
+			"DELETE FROM `customer` WHERE (`id` = ?)",
+			"DELETE FROM `customer` WHERE (`id` = 7)",
+			int64(7),
+		)
+	})
+}
@@ -0,0 +1,278 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/corestoreio/errors"
+)
+
+// DefaultCollectionSaverBatchSize defines the maximum amount of records per
+// INSERT or UPDATE statement of a CollectionSaver.
+const DefaultCollectionSaverBatchSize = 100
+
+// SaveOutcome reports the result of a single record passed to
+// CollectionSaver.Save.
+type SaveOutcome struct {
+	// Index of the record in the slice passed to Save.
+	Index int
+	// Inserted is true when the record has been written with an INSERT and
+	// false when it has been written with an UPDATE.
+	Inserted bool
+	// LastInsertID contains the auto increment value of an inserted record.
+	LastInsertID int64
+	// Err contains the error of the statement which has written the record. If
+	// another statement of the transaction failed, Err has kind Aborted
+	// because all changes have been rolled back.
+	Err error
+}
+
+// CollectionSaver writes a collection of records. Records with a zero primary
+// key get inserted and records with a non-zero primary key get updated. The
+// records are written in batches of INSERT and CASE based UPDATE statements
+// within one transaction.
+//		cs := dml.NewCollectionSaver("customer_entity", "entity_id", "email", "firstname")
+//		outcomes, err := cs.Save(ctx, dbc, customers...)
+type CollectionSaver struct {
+	// Table defines the name of the table.
+	Table string
+	// PrimaryKey defines the name of the primary key column. The column gets
+	// requested from the ColumnMapper to decide between INSERT and UPDATE.
+	PrimaryKey string
+	// Columns defines the columns to write, without the primary key.
+	Columns []string
+	// BatchSize defines the maximum amount of records per statement. Defaults
+	// to DefaultCollectionSaverBatchSize.
+	BatchSize int
+}
+
+// NewCollectionSaver creates a new CollectionSaver for the table. The columns
+// must not contain the primary key.
+func NewCollectionSaver(table, primaryKey string, columns ...string) *CollectionSaver {
+	return &CollectionSaver{
+		Table:      table,
+		PrimaryKey: primaryKey,
+		Columns:    columns,
+		BatchSize:  DefaultCollectionSaverBatchSize,
+	}
+}
+
+type saverRecord struct {
+	index int
+	rec   ColumnMapper
+	args  []interface{} // column values in the order of Columns, PK last
+}
+
+// Save partitions the records into records to insert and records to update
+// and executes the batched statements within one transaction. The returned
+// outcomes have the same order as the records. The error is the first error of
+// a statement; in this case the transaction has been rolled back.
+func (cs *CollectionSaver) Save(ctx context.Context, cp *ConnPool, records ...ColumnMapper) ([]SaveOutcome, error) {
+	if cs.Table == "" || cs.PrimaryKey == "" || len(cs.Columns) == 0 {
+		return nil, errors.NotValid.Newf("[dml] CollectionSaver requires a table, a primary key and columns. Have: %q %q %v", cs.Table, cs.PrimaryKey, cs.Columns)
+	}
+	for _, c := range cs.Columns {
+		if c == cs.PrimaryKey {
+			return nil, errors.NotAcceptable.Newf("[dml] CollectionSaver: Columns must not contain the primary key %q", cs.PrimaryKey)
+		}
+	}
+
+	outcomes := make([]SaveOutcome, len(records))
+	var inserts, updates []saverRecord
+	cols := append(append(make([]string, 0, len(cs.Columns)+1), cs.Columns...), cs.PrimaryKey)
+	compression := cp.compression.columns([]string{cs.Table}, true)
+	for i, rec := range records {
+		cm := NewColumnMap(len(cols), cols...)
+		cm.compression = compression
+		cm.encryption = cp.encryption
+		if err := rec.MapColumns(cm); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(cm.args) != len(cols) {
+			return nil, errors.Mismatch.Newf("[dml] CollectionSaver: record %d (%T) mapped %d values but requires %d for columns %v", i, rec, len(cm.args), len(cols), cols)
+		}
+		if err := sealEncrypted(cm.args, cols); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		sr := saverRecord{index: i, rec: rec, args: cm.args}
+		outcomes[i].Index = i
+		if isZeroPrimaryKey(cm.args[len(cs.Columns)]) {
+			outcomes[i].Inserted = true
+			inserts = append(inserts, sr)
+		} else {
+			updates = append(updates, sr)
+		}
+	}
+	if len(records) == 0 {
+		return outcomes, nil
+	}
+
+	tx, err := cp.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	batchSize := cs.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultCollectionSaverBatchSize
+	}
+
+	failed := func(batch []saverRecord, err error) ([]SaveOutcome, error) {
+		for i := range outcomes {
+			outcomes[i].Err = errors.Aborted.Newf("[dml] CollectionSaver: transaction has been rolled back")
+		}
+		for _, sr := range batch {
+			outcomes[sr.index].Err = err
+		}
+		// A failed Commit ends the transaction, so the rollback returns
+		// ErrTxDone which must not hide the original error.
+		if rErr := tx.Rollback(); rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			return outcomes, errors.Wrapf(err, "[dml] CollectionSaver: rollback failed: %s", rErr)
+		}
+		return outcomes, err
+	}
+
+	for len(inserts) > 0 {
+		batch := inserts
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		inserts = inserts[len(batch):]
+		if err := cs.insertBatch(ctx, tx, batch, outcomes); err != nil {
+			return failed(batch, err)
+		}
+	}
+	for len(updates) > 0 {
+		batch := updates
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+		updates = updates[len(batch):]
+		if err := cs.updateBatch(ctx, tx, batch); err != nil {
+			return failed(batch, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failed(nil, errors.WithStack(err))
+	}
+	return outcomes, nil
+}
+
+func (cs *CollectionSaver) insertBatch(ctx context.Context, tx *Tx, batch []saverRecord, outcomes []SaveOutcome) error {
+	recs := make([]interface{}, len(batch))
+	for i, sr := range batch {
+		recs[i] = sr.rec
+	}
+	res, err := tx.InsertInto(cs.Table).AddColumns(cs.Columns...).WithDBR().ExecContext(ctx, recs...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	lID, err := res.LastInsertId()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for i, sr := range batch {
+		outcomes[sr.index].LastInsertID = lID + int64(i)
+	}
+	return nil
+}
+
+// updateBatch writes a statement like:
+//		UPDATE `t` SET `a`=CASE `pk` WHEN ? THEN ? WHEN ? THEN ? END,
+//			`b`=CASE `pk` WHEN ? THEN ? WHEN ? THEN ? END WHERE `pk` IN (?,?)
+func (cs *CollectionSaver) updateBatch(ctx context.Context, tx *Tx, batch []saverRecord) error {
+	pkIdx := len(cs.Columns)
+	args := make([]interface{}, 0, len(batch)*(2*len(cs.Columns)+1))
+
+	var buf bytes.Buffer
+	buf.WriteString("UPDATE ")
+	Quoter.WriteIdentifier(&buf, cs.Table)
+	buf.WriteString(" SET ")
+	for ci, c := range cs.Columns {
+		if ci > 0 {
+			buf.WriteString(", ")
+		}
+		Quoter.WriteIdentifier(&buf, c)
+		buf.WriteString("=CASE ")
+		Quoter.WriteIdentifier(&buf, cs.PrimaryKey)
+		for _, sr := range batch {
+			buf.WriteString(" WHEN ? THEN ?")
+			args = expandInterface(args, sr.args[pkIdx])
+			args = expandInterface(args, sr.args[ci])
+		}
+		buf.WriteString(" END")
+	}
+	buf.WriteString(" WHERE ")
+	Quoter.WriteIdentifier(&buf, cs.PrimaryKey)
+	buf.WriteString(" IN (")
+	for i, sr := range batch {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('?')
+		args = expandInterface(args, sr.args[pkIdx])
+	}
+	buf.WriteByte(')')
+
+	_, err := tx.WithRawSQL(buf.String()).ExecContext(ctx, args...)
+	return errors.WithStack(err)
+}
+
+// isZeroPrimaryKey reports whether the primary key value has not yet been
+// assigned by the database.
+func isZeroPrimaryKey(v interface{}) bool {
+	if dv, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = dv.Value(); err != nil {
+			return false
+		}
+	}
+	switch pk := v.(type) {
+	case nil, internalNULLNIL:
+		return true
+	case int:
+		return pk == 0
+	case int8:
+		return pk == 0
+	case int16:
+		return pk == 0
+	case int32:
+		return pk == 0
+	case int64:
+		return pk == 0
+	case uint:
+		return pk == 0
+	case uint8:
+		return pk == 0
+	case uint16:
+		return pk == 0
+	case uint32:
+		return pk == 0
+	case uint64:
+		return pk == 0
+	case float64:
+		return pk == 0
+	case string:
+		return pk == ""
+	case []byte:
+		return len(pk) == 0
+	}
+	return false
+}
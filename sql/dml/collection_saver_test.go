@@ -0,0 +1,134 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+type saverEntity struct {
+	ID    uint64
+	Email string
+	Name  string
+}
+
+func (e *saverEntity) MapColumns(cm *dml.ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "id":
+			cm.Uint64(&e.ID)
+		case "email":
+			cm.String(&e.Email)
+		case "name":
+			cm.String(&e.Name)
+		default:
+			return errors.NotFound.Newf("[dml_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
+
+func TestCollectionSaver_Save(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	t.Run("insert and update", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `customer` (`email`,`name`) VALUES (?,?),(?,?)")).
+			WithArgs("a@x.io", "A", "c@x.io", "C").
+			WillReturnResult(sqlmock.NewResult(11, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? END, `name`=CASE `id` WHEN ? THEN ? WHEN ? THEN ? END WHERE `id` IN (?,?)")).
+			WithArgs(int64(5), "b@x.io", int64(7), "d@x.io", int64(5), "B", int64(7), "D", int64(5), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectCommit()
+
+		cs := dml.NewCollectionSaver("customer", "id", "email", "name")
+		outcomes, err := cs.Save(context.TODO(), dbc,
+			&saverEntity{Email: "a@x.io", Name: "A"},
+			&saverEntity{ID: 5, Email: "b@x.io", Name: "B"},
+			&saverEntity{Email: "c@x.io", Name: "C"},
+			&saverEntity{ID: 7, Email: "d@x.io", Name: "D"},
+		)
+		assert.NoError(t, err)
+		assert.Exactly(t, []dml.SaveOutcome{
+			{Index: 0, Inserted: true, LastInsertID: 11},
+			{Index: 1},
+			{Index: 2, Inserted: true, LastInsertID: 12},
+			{Index: 3},
+		}, outcomes)
+	})
+
+	t.Run("batches and rollback", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=CASE `id` WHEN ? THEN ? END WHERE `id` IN (?)")).
+			WithArgs(int64(5), "b@x.io", int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=CASE `id` WHEN ? THEN ? END WHERE `id` IN (?)")).
+			WithArgs(int64(7), "d@x.io", int64(7)).
+			WillReturnError(errors.AlreadyExists.Newf("Duplicate entry"))
+		dbMock.ExpectRollback()
+
+		cs := dml.NewCollectionSaver("customer", "id", "email")
+		cs.BatchSize = 1
+		outcomes, err := cs.Save(context.TODO(), dbc,
+			&saverEntity{ID: 5, Email: "b@x.io"},
+			&saverEntity{ID: 7, Email: "d@x.io"},
+		)
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+		assert.Len(t, outcomes, 2)
+		assert.ErrorIsKind(t, errors.Aborted, outcomes[0].Err)
+		assert.ErrorIsKind(t, errors.AlreadyExists, outcomes[1].Err)
+	})
+
+	t.Run("rollback error keeps the original error", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=CASE `id` WHEN ? THEN ? END WHERE `id` IN (?)")).
+			WithArgs(int64(7), "d@x.io", int64(7)).
+			WillReturnError(errors.AlreadyExists.Newf("Duplicate entry"))
+		dbMock.ExpectRollback().WillReturnError(errors.ConnectionFailed.Newf("connection lost"))
+
+		cs := dml.NewCollectionSaver("customer", "id", "email")
+		_, err := cs.Save(context.TODO(), dbc, &saverEntity{ID: 7, Email: "d@x.io"})
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+		assert.Contains(t, err.Error(), "connection lost")
+	})
+
+	t.Run("failed commit", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer` SET `email`=CASE `id` WHEN ? THEN ? END WHERE `id` IN (?)")).
+			WithArgs(int64(7), "d@x.io", int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectCommit().WillReturnError(errors.ConnectionFailed.Newf("commit failed"))
+
+		cs := dml.NewCollectionSaver("customer", "id", "email")
+		outcomes, err := cs.Save(context.TODO(), dbc, &saverEntity{ID: 7, Email: "d@x.io"})
+		assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+		assert.Contains(t, err.Error(), "commit failed")
+		assert.ErrorIsKind(t, errors.Aborted, outcomes[0].Err)
+	})
+
+	t.Run("primary key in columns", func(t *testing.T) {
+		cs := dml.NewCollectionSaver("customer", "id", "id", "email")
+		_, err := cs.Save(context.TODO(), dbc, &saverEntity{})
+		assert.ErrorIsKind(t, errors.NotAcceptable, err)
+	})
+}
@@ -0,0 +1,360 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// ColumnStatsOptions configures function Select.ColumnStats. The zero value
+// applies the defaults.
+type ColumnStatsOptions struct {
+	// SampleSize defines the number of rows kept in the reservoir. Defaults
+	// to 10000.
+	SampleSize int
+	// MaxEnumValues defines the maximum number of distinct values of a string
+	// column to suggest an ENUM. Defaults to 16. A negative value disables
+	// the ENUM suggestion.
+	MaxEnumValues int
+	// Seed initializes the random number generator to get a reproducible
+	// sample. Defaults to the current time.
+	Seed int64
+}
+
+// ColumnStats contains the statistics of a column in the sample. The fields
+// SuggestedDataType and SuggestedColumnType use the same format as the fields
+// DataType and ColumnType of ddl.Column, so a column can be passed to
+// dmlgen.WithTable to overwrite the Go type or to ddl linting.
+type ColumnStats struct {
+	Column string
+	// SampledRows contains the number of rows in the sample.
+	SampledRows int
+	// Nulls contains the number of NULL values in the sample.
+	Nulls int
+	// NullRatio is Nulls divided by SampledRows.
+	NullRatio float64
+	// Cardinality contains the number of distinct non-NULL values in the
+	// sample, which is a lower bound of the cardinality of the table.
+	Cardinality int
+	// MinLength and MaxLength contain the number of characters of the
+	// shortest and longest non-NULL value.
+	MinLength int
+	MaxLength int
+	// Min and Max contain the smallest and largest non-NULL value. Numbers
+	// get compared numerically, all other values lexically.
+	Min string
+	Max string
+	// SuggestedDataType contains the MySQL data type, e.g. smallint or
+	// varchar. Empty if the sample contains only NULL values.
+	SuggestedDataType string
+	// SuggestedColumnType contains the full MySQL column type, e.g.
+	// "smallint unsigned" or "varchar(32)".
+	SuggestedColumnType string
+	// Nullable reports whether the sample contains NULL values.
+	Nullable bool
+}
+
+// String implements fmt.Stringer and writes the statistics on one line.
+func (cs ColumnStats) String() string {
+	return fmt.Sprintf("%s: %s null=%.2f cardinality=%d length=%d..%d", cs.Column, cs.SuggestedColumnType,
+		cs.NullRatio, cs.Cardinality, cs.MinLength, cs.MaxLength)
+}
+
+// ColumnStats replaces the removed PROCEDURE ANALYSE() of MySQL 5.7. It reads
+// all rows of the query, keeps a random sample of them (reservoir sampling) and
+// returns for each selected column its statistics and the smallest fitting
+// data type. The sample gets collected on the client, so use a WHERE clause or
+// a LIMIT to reduce the scanned rows of large tables. Argument args gets
+// passed to the underlying DBR.
+//		stats, err := dbc.SelectFrom("sales_order").Star().ColumnStats(ctx, dml.ColumnStatsOptions{SampleSize: 5000})
+func (b *Select) ColumnStats(ctx context.Context, o ColumnStatsOptions, args ...interface{}) ([]ColumnStats, error) {
+	sqlStr, qArgs, err := b.WithDBR().prepareExecution(args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cs, err := columnStats(ctx, b.db, sqlStr, qArgs, o)
+	return cs, errors.WithStack(err)
+}
+
+func columnStats(ctx context.Context, db QueryExecPreparer, sqlStr string, args []interface{}, o ColumnStatsOptions) (cs []ColumnStats, err error) {
+	if o.SampleSize < 0 {
+		return nil, errors.NotValid.Newf("[dml] ColumnStats SampleSize must be positive, got %d", o.SampleSize)
+	}
+	if o.SampleSize == 0 {
+		o.SampleSize = 10000
+	}
+	if o.MaxEnumValues == 0 {
+		o.MaxEnumValues = 16
+	}
+	if o.Seed == 0 {
+		o.Seed = time.Now().UnixNano()
+	}
+
+	rows, err := db.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[dml] ColumnStats with query %q", sqlStr)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vals := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+
+	rnd := rand.New(rand.NewSource(o.Seed))
+	var sample [][]sql.NullString
+	for n := 0; rows.Next(); n++ {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		switch {
+		case n < o.SampleSize:
+			sample = append(sample, append([]sql.NullString(nil), vals...))
+		default: // Algorithm R
+			if j := rnd.Intn(n + 1); j < o.SampleSize {
+				copy(sample[j], vals)
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cs = make([]ColumnStats, len(cols))
+	for i, c := range cols {
+		cs[i] = analyseColumn(c, i, sample, o.MaxEnumValues)
+	}
+	return cs, nil
+}
+
+// columnAnalysis tracks which data types can store all values of a column.
+type columnAnalysis struct {
+	isInt, isDecimal, isFloat, isDate, isDateTime bool
+	// isBigUint gets set if a value exceeds math.MaxInt64.
+	isBigUint        bool
+	minInt, maxInt   int64
+	intDigits, scale int
+	fsp              int
+	maxBytes         int
+}
+
+func (ca *columnAnalysis) add(v string, first bool) {
+	if len(v) > ca.maxBytes {
+		ca.maxBytes = len(v)
+	}
+	if len(v) > 1 && v[0] == '0' && v[1] != '.' {
+		// leading zeros like in zip codes would get lost in a numeric type
+		ca.isInt, ca.isDecimal, ca.isFloat = false, false, false
+	}
+	if ca.isInt {
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			if first || i < ca.minInt {
+				ca.minInt = i
+			}
+			if first || i > ca.maxInt {
+				ca.maxInt = i
+			}
+		} else if _, err := strconv.ParseUint(v, 10, 64); err == nil {
+			ca.isBigUint = true
+		} else {
+			ca.isInt = false
+		}
+	}
+	if ca.isDecimal {
+		intPart, frac := strings.TrimPrefix(v, "-"), ""
+		if pos := strings.IndexByte(intPart, '.'); pos >= 0 {
+			intPart, frac = intPart[:pos], intPart[pos+1:]
+		}
+		if intPart == "" || !isDigits(intPart) || !isDigits(frac) {
+			ca.isDecimal = false
+		} else {
+			if d := len(strings.TrimLeft(intPart, "0")); d > ca.intDigits {
+				ca.intDigits = d
+			}
+			if len(frac) > ca.scale {
+				ca.scale = len(frac)
+			}
+		}
+	}
+	if ca.isFloat {
+		_, err := strconv.ParseFloat(v, 64)
+		ca.isFloat = err == nil && strings.ContainsAny(v, "0123456789") // excludes NaN and Inf
+	}
+	if ca.isDate {
+		_, err := time.Parse("2006-01-02", v)
+		ca.isDate = err == nil
+	}
+	if ca.isDateTime {
+		if _, err := time.Parse("2006-01-02 15:04:05.999999", v); err != nil {
+			ca.isDateTime = false
+		} else if pos := strings.IndexByte(v, '.'); pos > 0 && len(v)-pos-1 > ca.fsp {
+			ca.fsp = len(v) - pos - 1
+		}
+	}
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// intType returns the smallest integer type which can store all values.
+func (ca *columnAnalysis) intType() (dataType, columnType string) {
+	if ca.isBigUint {
+		return "bigint", "bigint unsigned"
+	}
+	types := [...]struct {
+		name     string
+		min, max int64
+	}{
+		{"tinyint", math.MinInt8, math.MaxInt8},
+		{"smallint", math.MinInt16, math.MaxInt16},
+		{"mediumint", -1 << 23, 1<<23 - 1},
+		{"int", math.MinInt32, math.MaxInt32},
+		{"bigint", math.MinInt64, math.MaxInt64},
+	}
+	for _, t := range types {
+		if ca.minInt >= 0 && uint64(ca.maxInt) <= uint64(t.max)*2+1 {
+			return t.name, t.name + " unsigned"
+		}
+		if ca.minInt >= t.min && ca.maxInt <= t.max {
+			return t.name, t.name
+		}
+	}
+	return "bigint", "bigint"
+}
+
+func analyseColumn(name string, idx int, sample [][]sql.NullString, maxEnumValues int) ColumnStats {
+	cs := ColumnStats{
+		Column:      name,
+		SampledRows: len(sample),
+	}
+	ca := columnAnalysis{isInt: true, isDecimal: true, isFloat: true, isDate: true, isDateTime: true}
+	distinct := make(map[string]struct{})
+	for _, row := range sample {
+		v := row[idx]
+		if !v.Valid {
+			cs.Nulls++
+			continue
+		}
+		first := len(distinct) == 0
+		distinct[v.String] = struct{}{}
+		l := utf8.RuneCountInString(v.String)
+		if first || l < cs.MinLength {
+			cs.MinLength = l
+		}
+		if l > cs.MaxLength {
+			cs.MaxLength = l
+		}
+		ca.add(v.String, first)
+	}
+	if cs.SampledRows > 0 {
+		cs.NullRatio = float64(cs.Nulls) / float64(cs.SampledRows)
+	}
+	cs.Nullable = cs.Nulls > 0
+	cs.Cardinality = len(distinct)
+	if cs.Cardinality == 0 {
+		return cs
+	}
+
+	values := make([]string, 0, len(distinct))
+	for v := range distinct {
+		values = append(values, v)
+	}
+	if ca.isFloat {
+		sort.Slice(values, func(i, j int) bool {
+			fi, _ := strconv.ParseFloat(values[i], 64)
+			fj, _ := strconv.ParseFloat(values[j], 64)
+			return fi < fj
+		})
+	} else {
+		sort.Strings(values)
+	}
+	cs.Min, cs.Max = values[0], values[len(values)-1]
+
+	nonNulls := cs.SampledRows - cs.Nulls
+	switch {
+	case ca.isInt && !(ca.isBigUint && ca.minInt < 0):
+		cs.SuggestedDataType, cs.SuggestedColumnType = ca.intType()
+	case ca.isDecimal && ca.intDigits+ca.scale <= 65:
+		p := ca.intDigits + ca.scale
+		if p == 0 {
+			p = 1
+		}
+		cs.SuggestedDataType = "decimal"
+		cs.SuggestedColumnType = "decimal(" + strconv.Itoa(p) + "," + strconv.Itoa(ca.scale) + ")"
+	case ca.isFloat:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "double", "double"
+	case ca.isDate:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "date", "date"
+	case ca.isDateTime:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "datetime", "datetime"
+		if ca.fsp > 0 {
+			cs.SuggestedColumnType = "datetime(" + strconv.Itoa(ca.fsp) + ")"
+		}
+	case maxEnumValues > 0 && cs.Cardinality <= maxEnumValues && cs.Cardinality*2 <= nonNulls:
+		buf := bufferpool.Get()
+		buf.WriteString("enum(")
+		for i, v := range values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\'')
+			buf.WriteString(strings.Replace(v, "'", "''", -1))
+			buf.WriteByte('\'')
+		}
+		buf.WriteByte(')')
+		cs.SuggestedDataType, cs.SuggestedColumnType = "enum", buf.String()
+		bufferpool.Put(buf)
+	case cs.MinLength == cs.MaxLength && cs.MaxLength <= 255:
+		cs.SuggestedDataType = "char"
+		cs.SuggestedColumnType = "char(" + strconv.Itoa(cs.MaxLength) + ")"
+	case cs.MaxLength <= 16383: // maximum length of an utf8mb4 VARCHAR
+		cs.SuggestedDataType = "varchar"
+		cs.SuggestedColumnType = "varchar(" + strconv.Itoa(cs.MaxLength) + ")"
+	case ca.maxBytes <= 65535:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "text", "text"
+	case ca.maxBytes <= 16777215:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "mediumtext", "mediumtext"
+	default:
+		cs.SuggestedDataType, cs.SuggestedColumnType = "longtext", "longtext"
+	}
+	return cs
+}
@@ -0,0 +1,96 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestSelect_ColumnStats(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	newRows := func() *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"entity_id", "sku", "status", "price", "zip", "created_at", "note", "empty"})
+		statuses := []string{"pending", "complete", "canceled"}
+		for i := 1; i <= 30; i++ {
+			var note interface{}
+			if i%3 == 0 {
+				note = fmt.Sprintf("Note ü %d", i)
+			}
+			rows.AddRow(i*100, fmt.Sprintf("SKU-%03d", i), statuses[i%3], fmt.Sprintf("%d.%02d", i, i%7),
+				fmt.Sprintf("%05d", i*7), fmt.Sprintf("2020-01-%02d 10:11:12.5", i), note, nil)
+		}
+		return rows
+	}
+
+	t.Run("all rows", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT * FROM `sales_order`")).WillReturnRows(newRows())
+
+		cs, err := dbc.SelectFrom("sales_order").Star().ColumnStats(context.TODO(), dml.ColumnStatsOptions{})
+		assert.NoError(t, err)
+		assert.Len(t, cs, 8)
+
+		assert.Exactly(t, dml.ColumnStats{
+			Column: "entity_id", SampledRows: 30, Cardinality: 30, MinLength: 3, MaxLength: 4, Min: "100", Max: "3000",
+			SuggestedDataType: "smallint", SuggestedColumnType: "smallint unsigned",
+		}, cs[0])
+		assert.Exactly(t, "char(7)", cs[1].SuggestedColumnType)
+		assert.Exactly(t, "enum('canceled','complete','pending')", cs[2].SuggestedColumnType)
+		assert.Exactly(t, "decimal(4,2)", cs[3].SuggestedColumnType)
+		assert.Exactly(t, "1.01", cs[3].Min)
+		assert.Exactly(t, "30.02", cs[3].Max)
+		assert.Exactly(t, "char(5)", cs[4].SuggestedColumnType, "leading zeros")
+		assert.Exactly(t, "datetime(1)", cs[5].SuggestedColumnType)
+
+		note := cs[6]
+		assert.Exactly(t, "varchar(9)", note.SuggestedColumnType)
+		assert.Exactly(t, 20, note.Nulls)
+		assert.True(t, note.Nullable)
+		assert.Exactly(t, 8, note.MinLength, "counts characters")
+		assert.Exactly(t, "note: varchar(9) null=0.67 cardinality=10 length=8..9", note.String())
+
+		assert.Exactly(t, dml.ColumnStats{Column: "empty", SampledRows: 30, Nulls: 30, NullRatio: 1, Nullable: true}, cs[7])
+	})
+
+	t.Run("reservoir", func(t *testing.T) {
+		var have [2][]dml.ColumnStats
+		for i := range have {
+			dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id`, `sku` FROM `sales_order` WHERE (`entity_id` > 0)")).
+				WillReturnRows(newRows())
+			cs, err := dbc.SelectFrom("sales_order").AddColumns("entity_id", "sku").Where(dml.Column("entity_id").Greater().Int(0)).
+				ColumnStats(context.TODO(), dml.ColumnStatsOptions{SampleSize: 10, Seed: 42})
+			assert.NoError(t, err)
+			assert.Exactly(t, 10, cs[0].SampledRows)
+			assert.Exactly(t, 10, cs[0].Cardinality)
+			have[i] = cs
+		}
+		assert.Exactly(t, have[0], have[1], "same seed, same sample")
+	})
+
+	t.Run("invalid sample size", func(t *testing.T) {
+		cs, err := dbc.SelectFrom("sales_order").Star().ColumnStats(context.TODO(), dml.ColumnStatsOptions{SampleSize: -1})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		assert.Nil(t, cs)
+	})
+}
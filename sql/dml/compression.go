@@ -0,0 +1,200 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/corestoreio/errors"
+)
+
+// compressionHeader gets prepended to each compressed value. The first byte
+// is not valid UTF-8, so uncompressed legacy text values never start with the
+// header and get passed through unchanged.
+var compressionHeader = []byte{0xfe, 'c', 'z', 0x01}
+
+// ValueEncoder compresses src and appends the result to dst. The signature
+// matches the EncodeAll method of the zstd Encoder in package
+// github.com/klauspost/compress/zstd, so an Encoder can be used directly.
+type ValueEncoder interface {
+	EncodeAll(src, dst []byte) []byte
+}
+
+// ValueDecoder decompresses input and appends the result to dst. The signature
+// matches the DecodeAll method of the zstd Decoder in package
+// github.com/klauspost/compress/zstd, so a Decoder can be used directly.
+type ValueDecoder interface {
+	DecodeAll(input, dst []byte) ([]byte, error)
+}
+
+type columnCompressor struct {
+	enc ValueEncoder
+	dec ValueDecoder
+	// decodeOnly has a nil encoder and gets used for statements whose tables
+	// are unknown.
+	decodeOnly *columnCompressor
+}
+
+// compress returns the compressed data including the header.
+func (cc *columnCompressor) compress(data []byte) []byte {
+	dst := make([]byte, len(compressionHeader), len(compressionHeader)+len(data)/2)
+	copy(dst, compressionHeader)
+	return cc.enc.EncodeAll(data, dst)
+}
+
+// compressionTables maps a table name to the compressors of its columns.
+type compressionTables map[string]map[string]*columnCompressor
+
+// columns returns the compressors of the columns of the tables. If the tables
+// are unknown, like for raw SQL, the columns of all tables only get
+// decompressed, because the header identifies a compressed value. Columns of
+// earlier tables take precedence.
+func (ct compressionTables) columns(tables []string, known bool) map[string]*columnCompressor {
+	if len(ct) == 0 {
+		return nil
+	}
+	if !known {
+		tables = make([]string, 0, len(ct))
+		for t := range ct {
+			tables = append(tables, t)
+		}
+		sort.Strings(tables)
+	}
+	var m map[string]*columnCompressor
+	for _, t := range tables {
+		for col, cc := range ct[t] {
+			if m == nil {
+				m = make(map[string]*columnCompressor)
+			}
+			if _, ok := m[col]; ok {
+				continue
+			}
+			if !known {
+				cc = cc.decodeOnly
+			}
+			m[col] = cc
+		}
+	}
+	return m
+}
+
+// WithColumnCompression compresses transparently the values of the columns of
+// a table before writing and decompresses them while scanning. Compression
+// applies to the ColumnMap functions Byte, String and NullString, hence to all
+// ColumnMapper types, when the column name is known. Values without the
+// compression header, for example rows written before enabling the
+// compression, get passed through. The columns must have a binary data type
+// like BLOB or VARBINARY. The column names should not contain a qualifier.
+// Equally named columns of other tables stay uncompressed. Statements whose
+// tables are unknown, like raw SQL, only decompress. Only builders created
+// after applying this option use the compression.
+//		enc, _ := zstd.NewWriter(nil)
+//		dec, _ := zstd.NewReader(nil)
+//		dml.WithColumnCompression(enc, dec, "catalog_product_entity_text", "value")
+func WithColumnCompression(enc ValueEncoder, dec ValueDecoder, table string, columns ...string) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3,
+		fn: func(c *ConnPool) error {
+			if enc == nil || dec == nil {
+				return errors.NotValid.Newf("[dml] WithColumnCompression requires an encoder and a decoder for table %q and columns %v", table, columns)
+			}
+			if table == "" {
+				return errors.Empty.Newf("[dml] WithColumnCompression requires a table name for columns %v", columns)
+			}
+			cc := &columnCompressor{enc: enc, dec: dec, decodeOnly: &columnCompressor{dec: dec}}
+			// copy on write because existing builders might still use the maps.
+			m := make(compressionTables, len(c.compression)+1)
+			for k, v := range c.compression {
+				m[k] = v
+			}
+			cols := make(map[string]*columnCompressor, len(m[table])+len(columns))
+			for k, v := range m[table] {
+				cols[k] = v
+			}
+			for _, col := range columns {
+				cols[col] = cc
+			}
+			m[table] = cols
+			c.compression = m
+			return nil
+		},
+	}
+}
+
+// columnCompression returns the compressors of the columns of the tables used
+// in the statement.
+func (a *DBR) columnCompression() map[string]*columnCompressor {
+	return a.base.compression.columns(a.tables, a.tablesKnown)
+}
+
+// compressor returns the compressor of the current column or nil.
+func (b *ColumnMap) compressor() *columnCompressor {
+	if len(b.compression) == 0 {
+		return nil
+	}
+	return b.compression[b.unqualifiedColumn()]
+}
+
+// unqualifiedColumn returns the name of the current column without its
+// qualifier or an empty string if the column is unknown.
+func (b *ColumnMap) unqualifiedColumn() string {
+	var col string
+	switch {
+	case b.index >= 0 && b.index < b.columnsLen:
+		col = b.columns[b.index]
+	case b.columnsLen == 1:
+		col = b.columns[0]
+	default:
+		return ""
+	}
+	if i := strings.LastIndexByte(col, '.'); i >= 0 {
+		col = col[i+1:]
+	}
+	return col
+}
+
+// decompressCurrent replaces the scanned value of the current column with its
+// decompressed value, if the value contains the compression header.
+func (b *ColumnMap) decompressCurrent() {
+	cc := b.compressor()
+	if cc == nil || b.scanErr != nil {
+		return
+	}
+	v := &b.scanCol[b.index]
+	var data []byte
+	switch v.field {
+	case 'y':
+		data = v.byte
+	case 's':
+		if !strings.HasPrefix(v.string, string(compressionHeader)) {
+			return
+		}
+		data = []byte(v.string)
+	default:
+		return
+	}
+	if !bytes.HasPrefix(data, compressionHeader) {
+		return // uncompressed legacy value
+	}
+	dec, err := cc.dec.DecodeAll(data[len(compressionHeader):], nil)
+	if err != nil {
+		b.scanErr = errors.BadEncoding.New(err, "[dml] Column %q failed to decompress", b.Column())
+		return
+	}
+	v.field = 'y'
+	v.byte = dec
+}
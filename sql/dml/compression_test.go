@@ -0,0 +1,152 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+// flateCodec implements dml.ValueEncoder and dml.ValueDecoder to avoid the
+// dependency to a zstd package.
+type flateCodec struct{}
+
+func (flateCodec) EncodeAll(src, dst []byte) []byte {
+	buf := bytes.NewBuffer(dst)
+	w, _ := flate.NewWriter(buf, flate.BestSpeed)
+	_, _ = w.Write(src)
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func (flateCodec) DecodeAll(input, dst []byte) ([]byte, error) {
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(input)))
+	return append(dst, data...), err
+}
+
+func compressValue(data string) []byte {
+	return flateCodec{}.EncodeAll([]byte(data), []byte{0xfe, 'c', 'z', 0x01})
+}
+
+type compressedEntity struct {
+	ID          int64
+	Description string
+	Payload     []byte
+	Note        null.String
+}
+
+func (e *compressedEntity) MapColumns(cm *dml.ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "id":
+			cm.Int64(&e.ID)
+		case "description":
+			cm.String(&e.Description)
+		case "payload":
+			cm.Byte(&e.Payload)
+		case "note":
+			cm.NullString(&e.Note)
+		default:
+			return errors.NotFound.Newf("[dml_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
+
+func TestWithColumnCompression(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	assert.NoError(t, dbc.Options(dml.WithColumnCompression(flateCodec{}, flateCodec{}, "product", "description", "payload")))
+
+	description := strings.Repeat("A long product description. ", 20)
+
+	t.Run("compress arguments", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `product` (`id`,`description`,`payload`,`note`) VALUES (?,?,?,?)")).
+			WithArgs(int64(3), compressValue(description), compressValue("{}"), "not compressed").
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		_, err := dbc.InsertInto("product").AddColumns("id", "description", "payload", "note").WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{
+				ID:          3,
+				Description: description,
+				Payload:     []byte("{}"),
+				Note:        null.MakeString("not compressed"),
+			})
+		assert.NoError(t, err)
+	})
+
+	t.Run("equally named column of another table", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `category` (`id`,`description`) VALUES (?,?)")).
+			WithArgs(int64(4), description).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+
+		_, err := dbc.InsertInto("category").AddColumns("id", "description").WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{
+				ID:          4,
+				Description: description,
+			})
+		assert.NoError(t, err)
+	})
+
+	t.Run("raw SQL decompresses", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).
+				AddRow(3, compressValue(description)))
+
+		e := new(compressedEntity)
+		_, err := dbc.WithQueryBuilder(dml.QuerySQLFn(func() (string, []interface{}, error) {
+			return "SELECT `id`, `description` FROM `product`", nil, nil
+		})).Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, description, e.Description)
+	})
+
+	t.Run("decompress values with legacy passthrough", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description`, `payload`, `note` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description", "payload", "note"}).
+				AddRow(3, compressValue(description), []byte("legacy"), "not compressed"))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("id", "description", "payload", "note").WithDBR().
+			Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, description, e.Description)
+		assert.Exactly(t, []byte("legacy"), e.Payload)
+		assert.Exactly(t, null.MakeString("not compressed"), e.Note)
+	})
+
+	t.Run("corrupt value", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).
+				AddRow(3, []byte{0xfe, 'c', 'z', 0x01, 0xff, 0xff}))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("id", "description").WithDBR().
+			Load(context.TODO(), e)
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+}
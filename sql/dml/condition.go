@@ -23,6 +23,7 @@ import (
 
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/bufferpool"
 )
 
 const (
@@ -56,7 +57,13 @@ const (
 	NotRegexp      Op = 'R'          // NOT REGEXP ?
 	Xor            Op = '⊻'          // XOR ?
 	SpaceShip      Op = '\U0001f680' // a <=> b is equivalent to a = b OR (a IS NULL AND b IS NULL) NULL-safe equal to operator
+	DistinctFrom   Op = '≢'          // NOT (a <=> b) emulates a IS DISTINCT FROM b
 	Coalesce       Op = 'c'          // Returns the first non-NULL value in the list, or NULL if there are no non-NULL arguments.
+	MemberOf       Op = '∋'          // ? MEMBER OF(json_array)
+	JSONOverlaps   Op = '∩'          // JSON_OVERLAPS(json_doc, ?)
+	JSONContains   Op = '⊇'          // JSON_CONTAINS(json_doc, ?)
+	STWithin       Op = '⊂'          // ST_Within(geometry, ?)
+	STContains     Op = '⊃'          // ST_Contains(geometry, ?)
 )
 
 // Op the Operator, defines comparison and operator functions used in any
@@ -73,7 +80,7 @@ func (o Op) String() string {
 	return string(o)
 }
 
-func (o Op) write(w *bytes.Buffer, args ...interface{}) (err error) {
+func (o Op) write(w *bytes.Buffer, d mysqlDialect, args ...interface{}) (err error) {
 	var arg interface{}
 	if len(args) == 1 {
 		arg = args[0]
@@ -91,63 +98,63 @@ func (o Op) write(w *bytes.Buffer, args ...interface{}) (err error) {
 		_, err = w.WriteString(" IS NOT NULL")
 	case In, NotIn:
 		w.WriteString(" IN ")
-		err = writeInterfaces(w, args)
+		err = writeInterfaces(w, d, args)
 	case Like, NotLike:
 		w.WriteString(" LIKE ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case Regexp, NotRegexp:
 		w.WriteString(" REGEXP ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case Between, NotBetween:
 		w.WriteString(" BETWEEN ")
 		if arg == nil {
 			w.WriteByte(placeHolderRune)
 			w.WriteString(" AND ") // don't write the last place holder as it gets written somewhere else
 		} else {
-			if err = writeInterfaceValue(arg, w, 1); err != nil {
+			if err = writeInterfaceValue(arg, w, d, 1); err != nil {
 				return errors.WithStack(err)
 			}
 			w.WriteString(" AND ")
-			if err = writeInterfaceValue(arg, w, 2); err != nil {
+			if err = writeInterfaceValue(arg, w, d, 2); err != nil {
 				return errors.WithStack(err)
 			}
 		}
 	case Greatest:
 		w.WriteString(" GREATEST ")
-		err = writeInterfaces(w, args)
+		err = writeInterfaces(w, d, args)
 	case Least:
 		w.WriteString(" LEAST ")
-		err = writeInterfaces(w, args)
+		err = writeInterfaces(w, d, args)
 	case Coalesce:
 		w.WriteString(" COALESCE ")
-		err = writeInterfaces(w, args)
+		err = writeInterfaces(w, d, args)
 	case Xor:
 		w.WriteString(" XOR ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case Exists, NotExists:
 		w.WriteString(" EXISTS ")
-		err = writeInterfaces(w, args)
+		err = writeInterfaces(w, d, args)
 	case Less:
 		w.WriteString(" < ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case Greater:
 		w.WriteString(" > ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case LessOrEqual:
 		w.WriteString(" <= ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case GreaterOrEqual:
 		w.WriteString(" >= ")
-		err = writeInterfaceValue(arg, w, 0)
-	case SpaceShip:
+		err = writeInterfaceValue(arg, w, d, 0)
+	case SpaceShip, DistinctFrom:
 		w.WriteString(" <=> ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	case NotEqual:
 		w.WriteString(" != ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	default: // and case Equal
 		w.WriteString(" = ")
-		err = writeInterfaceValue(arg, w, 0)
+		err = writeInterfaceValue(arg, w, d, 0)
 	}
 	return
 }
@@ -193,6 +200,22 @@ func (js Joins) Clone() Joins {
 	return c
 }
 
+// write writes all JOIN clauses with their ON or USING conditions.
+func (js Joins) write(w *bytes.Buffer, d mysqlDialect, placeHolders []string, isWithDBR bool) (_ []string, err error) {
+	for _, f := range js {
+		w.WriteByte(' ')
+		w.WriteString(f.JoinType)
+		w.WriteString(" JOIN ")
+		if placeHolders, err = f.Table.writeQuoted(w, d, placeHolders); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if placeHolders, err = f.On.write(w, d, 'j', placeHolders, isWithDBR); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return placeHolders, nil
+}
+
 type join struct {
 	// JoinType can be LEFT, RIGHT, INNER, OUTER, CROSS or another word.
 	JoinType string
@@ -241,6 +264,8 @@ type Condition struct {
 		arg interface{} // Only set in case of no expression
 		// args same as arg but only used in case of an expression.
 		args []interface{}
+		// likeEscape if set, writes the ESCAPE clause for LIKE and NOT LIKE.
+		likeEscape rune
 		// Select adds a sub-select to the where statement. Column must be
 		// either a column name or anything else which can handle the result of
 		// a sub-select.
@@ -264,6 +289,13 @@ type Condition struct {
 	// creation in the JOIN part for the USING syntax. Additionally used in ON
 	// DUPLICATE KEY.
 	Columns []string
+	// jsonPath if set, wraps the left column with JSON_EXTRACT. See
+	// JSONExtract.
+	jsonPath    string
+	jsonUnquote bool
+	// stDistanceTo if set, wraps the left column with ST_Distance_Sphere. See
+	// STDistanceSphere.
+	stDistanceTo *Point
 }
 
 // Clone creates a new clone of the current object. It resets the internal error
@@ -403,6 +435,52 @@ func (c *Condition) NotLike() *Condition {
 	return c
 }
 
+// EscapeLike compares the left hand side with LIKE, or NOT LIKE if NotLike has
+// been called before, against the literal value. The wildcards % and _ and the
+// escape character itself get escaped in the value and the ESCAPE clause gets
+// appended, so user supplied search terms cannot act as wildcards. Escaping
+// works per UTF-8 character. An empty value only appends the ESCAPE clause,
+// which is useful for place holders or for values escaped with
+// EscapeLikeValue and combined with own wildcards.
+//		Column("sku").EscapeLike("100%_off", '!')
+//		// `sku` LIKE '100!%!_off' ESCAPE '!'
+//		Column("name").Like().Str("%"+EscapeLikeValue("50%", '!')+"%").EscapeLike("", '!')
+//		// `name` LIKE '%50!%%' ESCAPE '!'
+func (c *Condition) EscapeLike(value string, escapeChar rune) *Condition {
+	if c.Operator != NotLike {
+		c.Operator = Like
+	}
+	if escapeChar == 0 {
+		escapeChar = '\\'
+	}
+	c.Right.likeEscape = escapeChar
+	if value != "" {
+		c.Str(EscapeLikeValue(value, escapeChar))
+	}
+	return c
+}
+
+// EscapeLikeValue prefixes the LIKE wildcards % and _ and the escape character
+// with the escape character. A zero escape character defaults to the
+// backslash.
+func EscapeLikeValue(value string, escapeChar rune) string {
+	if escapeChar == 0 {
+		escapeChar = '\\'
+	}
+	if !strings.ContainsAny(value, "%_") && !strings.ContainsRune(value, escapeChar) {
+		return value
+	}
+	var buf strings.Builder
+	buf.Grow(len(value) + 4)
+	for _, r := range value {
+		if r == '%' || r == '_' || r == escapeChar {
+			buf.WriteRune(escapeChar)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
 func (c *Condition) Greatest() *Condition {
 	c.Operator = Greatest
 	return c
@@ -473,6 +551,25 @@ func (c *Condition) SpaceShip() *Condition {
 	return c
 }
 
+// NullSafeEqual compares NULL-safe with the `<=>` operator. Same as SpaceShip.
+// It returns 1 rather than NULL if both operands are NULL, and 0 rather than
+// NULL if one operand is NULL.
+//		Column("a").NullSafeEqual().Str("x") // (`a` <=> 'x')
+//		Column("a").NullSafeEqual().NullString(null.String{}) // (`a` <=> NULL)
+func (c *Condition) NullSafeEqual() *Condition {
+	c.Operator = SpaceShip
+	return c
+}
+
+// IsDistinctFrom emulates the SQL standard `IS DISTINCT FROM` operator with a
+// negated NULL-safe comparison. It returns true if one operand is NULL and the
+// other is not, or if both are not NULL and differ.
+//		Column("a").IsDistinctFrom().Str("x") // (NOT (`a` <=> 'x'))
+func (c *Condition) IsDistinctFrom() *Condition {
+	c.Operator = DistinctFrom
+	return c
+}
+
 func (c *Condition) Coalesce() *Condition {
 	c.Operator = Coalesce
 	return c
@@ -829,6 +926,25 @@ func (c *Condition) Values() *Condition {
 	return c
 }
 
+// ValuesOf only usable in case for ON DUPLICATE KEY to assign the value of
+// another column, which would have been inserted:
+//		column=VALUES(otherColumn)
+func (c *Condition) ValuesOf(otherColumn string) *Condition {
+	return c.Expr(SQLValues(otherColumn))
+}
+
+// PlusValues only usable in case for ON DUPLICATE KEY to add the value, which
+// would have been inserted, to the current value, e.g. for counters:
+//		column=`column`+VALUES(column)
+func (c *Condition) PlusValues() *Condition {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	Quoter.quote(buf, c.Left)
+	buf.WriteByte('+')
+	writeSQLValues(buf, "", c.Left)
+	return c.Expr(buf.String())
+}
+
 // DriverValue adds multiple of the same underlying values to the argument
 // slice. When using different values, the last applied value wins and gets
 // added to the argument slice. For example driver.Values of type `int` will
@@ -863,6 +979,13 @@ func (c *Condition) SQLCase(value, defaultValue string, compareResult ...string)
 	return c
 }
 
+// SQLIf sets the right hand side to an IF() expression, see function SQLIf.
+// Mostly used in ON DUPLICATE KEY UPDATE, in combination with SQLValues:
+//		`price`=IF((`version` < VALUES(`version`)), VALUES(`price`), `price`)
+func (c *Condition) SQLIf(expression, true, false string) *Condition {
+	return c.Expr("IF((" + expression + "), " + true + ", " + false + ")")
+}
+
 // SQLIfNull see description at function SQLIfNull.
 func (c *Condition) SQLIfNull(expression ...string) *Condition {
 	c.Right.Column = sqlIfNull(expression)
@@ -876,7 +999,7 @@ func (c *Condition) SQLIfNull(expression ...string) *Condition {
 
 // write writes the conditions for usage as restrictions in WHERE, HAVING or
 // JOIN clauses. conditionType enum of j=join, w=where, h=having
-func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []string, isWithDBR bool) (_placeHolders []string, err error) {
+func (cs Conditions) write(w *bytes.Buffer, d mysqlDialect, conditionType byte, placeHolders []string, isWithDBR bool) (_placeHolders []string, err error) {
 	if len(cs) == 0 {
 		return placeHolders, nil
 	}
@@ -938,12 +1061,35 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 		}
 
 		w.WriteByte('(')
+		if cnd.Operator == DistinctFrom {
+			w.WriteString("NOT (")
+		}
 		// Code is a bit duplicated but can be refactored later. The order of
 		// the `case`s has been carefully implemented.
 		switch lenArgs := len(cnd.Right.args); {
+		case cnd.Operator == MemberOf || cnd.Operator == JSONOverlaps:
+			if placeHolders, err = cnd.writeJSONMembership(w, d, placeHolders); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+		case cnd.Operator == JSONContains:
+			if placeHolders, err = cnd.writeFunction(w, d, "JSON_CONTAINS", placeHolders); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+		case cnd.Operator == STWithin:
+			if placeHolders, err = cnd.writeFunction(w, d, "ST_Within", placeHolders); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+		case cnd.Operator == STContains:
+			if placeHolders, err = cnd.writeFunction(w, d, "ST_Contains", placeHolders); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
 		case cnd.IsLeftExpression:
 			var phCount int
-			phCount, err = writeExpression(w, cnd.Left, cnd.Right.args)
+			phCount, err = writeExpression(w, d, cnd.Left, cnd.Right.args)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
@@ -956,14 +1102,14 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 				if eArg == nil {
 					eArg = cnd.Right.args[0]
 				}
-				cnd.Operator.write(w, eArg)
+				cnd.Operator.write(w, d, eArg)
 
 			case cnd.Right.Sub != nil:
-				if err = cnd.Operator.write(w); err != nil {
+				if err = cnd.Operator.write(w, d); err != nil {
 					return nil, errors.WithStack(err)
 				}
 				w.WriteByte('(')
-				placeHolders, err = cnd.Right.Sub.toSQL(w, placeHolders)
+				placeHolders, err = cnd.Right.Sub.toSQL(w, d, placeHolders)
 				if err != nil {
 					return nil, errors.Wrapf(err, "[dml] write failed SubSelect for table: %q", cnd.Right.Sub.Table.String())
 				}
@@ -971,46 +1117,46 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 			}
 
 		case cnd.Right.IsExpression:
-			Quoter.WriteIdentifier(w, cnd.Left)
-			if err = cnd.Operator.write(w); err != nil {
+			cnd.writeLeft(w, d)
+			if err = cnd.Operator.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
-			if _, err = writeExpression(w, cnd.Right.Column, cnd.Right.args); err != nil {
+			if _, err = writeExpression(w, d, cnd.Right.Column, cnd.Right.args); err != nil {
 				return nil, errors.WithStack(err)
 			}
 		case cnd.Right.Sub != nil:
-			Quoter.WriteIdentifier(w, cnd.Left)
-			if err = cnd.Operator.write(w); err != nil {
+			cnd.writeLeft(w, d)
+			if err = cnd.Operator.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			w.WriteByte('(')
-			placeHolders, err = cnd.Right.Sub.toSQL(w, placeHolders)
+			placeHolders, err = cnd.Right.Sub.toSQL(w, d, placeHolders)
 			if err != nil {
 				return nil, errors.Wrapf(err, "[dml] write failed SubSelect for table: %q", cnd.Right.Sub.Table.String())
 			}
 			w.WriteByte(')')
 
 		case cnd.Right.arg != nil && lenArgs == 0: // One Argument and no expression
-			Quoter.WriteIdentifier(w, cnd.Left)
+			cnd.writeLeft(w, d)
 			if al, _ := sliceLen(cnd.Right.arg); al > 1 && cnd.Operator == 0 { // no operator but slice applied, so creating an IN query.
 				cnd.Operator = In
 			}
-			if err = cnd.Operator.write(w, cnd.Right.arg); err != nil {
+			if err = cnd.Operator.write(w, d, cnd.Right.arg); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
 		case cnd.Right.arg == nil && lenArgs > 0:
-			Quoter.WriteIdentifier(w, cnd.Left)
+			cnd.writeLeft(w, d)
 			if totalSliceLenSimple(cnd.Right.args) > 1 && cnd.Operator == 0 { // no operator but slice applied, so creating an IN query.
 				cnd.Operator = In
 			}
-			if err = cnd.Operator.write(w, cnd.Right.args...); err != nil {
+			if err = cnd.Operator.write(w, d, cnd.Right.args...); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
 		case cnd.Right.Column != "": // compares the left column with the right column
-			Quoter.WriteIdentifier(w, cnd.Left)
-			if err = cnd.Operator.write(w); err != nil {
+			cnd.writeLeft(w, d)
+			if err = cnd.Operator.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			Quoter.WriteIdentifier(w, cnd.Right.Column)
@@ -1024,7 +1170,7 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 				Quoter.quote(w, col)
 			}
 			w.WriteByte(')')
-			if err = cnd.Operator.write(w); err != nil {
+			if err = cnd.Operator.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			if isWithDBR {
@@ -1034,13 +1180,13 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 				placeHolders = append(placeHolders, placeHolderTuples)
 			} else {
 				w.WriteByte('(')
-				writeTuplePlaceholders(w, 1, uint(len(cnd.Columns)))
+				writeTuplePlaceholders(w, 1, uint(len(cnd.Columns)), false)
 				w.WriteByte(')')
 			}
 
 		case cnd.Right.PlaceHolder != "":
-			Quoter.WriteIdentifier(w, cnd.Left)
-			if err = cnd.Operator.write(w); err != nil {
+			cnd.writeLeft(w, d)
+			if err = cnd.Operator.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
@@ -1061,12 +1207,12 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 			}
 
 		case cnd.Right.arg == nil && lenArgs == 0: // No Argument at all, which kinda is the default case
-			Quoter.WriteIdentifier(w, cnd.Left)
+			cnd.writeLeft(w, d)
 			cOp := cnd.Operator
 			if cOp == 0 {
 				cOp = Null
 			}
-			if err = cOp.write(w); err != nil {
+			if err = cOp.write(w, d); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
@@ -1074,37 +1220,49 @@ func (cs Conditions) write(w *bytes.Buffer, conditionType byte, placeHolders []s
 			panic(errors.NotSupported.Newf("[dml] Multiple arguments for a column are not supported\nWhereFragment: %#v\n", cnd))
 		}
 
+		if cnd.Right.likeEscape != 0 {
+			w.WriteString(" ESCAPE ")
+			d.EscapeString(w, string(cnd.Right.likeEscape))
+		}
+		if cnd.Operator == DistinctFrom {
+			w.WriteByte(')')
+		}
 		w.WriteByte(')')
 		i++
 	}
 	return placeHolders, errors.WithStack(err)
 }
 
-func (cs Conditions) writeSetClauses(w *bytes.Buffer, placeHolders []string) ([]string, error) {
+func (cs Conditions) writeSetClauses(w *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
 	for i, cnd := range cs {
+		if cnd.previousErr != nil {
+			return nil, errors.WithStack(cnd.previousErr)
+		}
 		if i > 0 {
 			w.WriteString(", ")
 		}
-		Quoter.quote(w, cnd.Left)
+		Quoter.WriteIdentifier(w, cnd.Left)
 		w.WriteByte('=')
 
 		switch {
 		case cnd.Right.arg != nil && len(cnd.Right.args) == 0: // One Argument and no expression
-			if err := writeInterfaceValue(cnd.Right.arg, w, 0); err != nil {
+			if err := writeInterfaceValue(cnd.Right.arg, w, d, 0); err != nil {
 				return nil, errors.WithStack(err)
 			}
 		case cnd.Right.IsExpression: // maybe that case is superfluous
-			if _, err := writeExpression(w, cnd.Right.Column, cnd.Right.args); err != nil {
+			if _, err := writeExpression(w, d, cnd.Right.Column, cnd.Right.args); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			placeHolders = append(placeHolders, cnd.Left)
 		case cnd.Right.Sub != nil:
 			w.WriteByte('(')
 			var err error
-			if placeHolders, err = cnd.Right.Sub.toSQL(w, placeHolders); err != nil {
+			if placeHolders, err = cnd.Right.Sub.toSQL(w, d, placeHolders); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			w.WriteByte(')')
+		case cnd.Right.Column != "": // assigns the value of another column, e.g. of a joined table
+			Quoter.WriteIdentifier(w, cnd.Right.Column)
 		default:
 			placeHolders = append(placeHolders, cnd.Left)
 			w.WriteByte(placeHolderRune)
@@ -1113,24 +1271,78 @@ func (cs Conditions) writeSetClauses(w *bytes.Buffer, placeHolders []string) ([]
 	return placeHolders, nil
 }
 
-func writeSQLValues(w *bytes.Buffer, column string) {
+// writeSQLValues writes VALUES(`column`) or, with a row alias of the MySQL 8
+// syntax, `alias`.`column`.
+func writeSQLValues(w *bytes.Buffer, rowAlias, column string) {
+	if rowAlias != "" {
+		Quoter.writeQualifierName(w, rowAlias, column)
+		return
+	}
 	w.WriteString("VALUES(")
 	Quoter.quote(w, column)
 	w.WriteByte(')')
 }
 
+// SQLValues returns the expression VALUES(`column`) to refer in an ON
+// DUPLICATE KEY UPDATE expression to the value which would have been
+// inserted. If the Insert uses a row alias, see Insert.WithRowAlias, the
+// expression gets rewritten to `alias`.`column`.
+//		dml.Column("updated_at").SQLIf("`version` < "+dml.SQLValues("version"), dml.SQLValues("updated_at"), "`updated_at`")
+func SQLValues(column string) string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	writeSQLValues(buf, "", column)
+	return buf.String()
+}
+
+// rewriteSQLValues replaces all VALUES(`column`) in an expression with
+// `rowAlias`.`column`.
+func rewriteSQLValues(w *bytes.Buffer, rowAlias, expression string) {
+	const valuesStart = "VALUES(`"
+	for {
+		pos := strings.Index(expression, valuesStart)
+		if pos < 0 {
+			break
+		}
+		end := strings.Index(expression[pos+len(valuesStart):], "`)")
+		if end < 0 {
+			break
+		}
+		w.WriteString(expression[:pos])
+		writeSQLValues(w, rowAlias, expression[pos+len(valuesStart):pos+len(valuesStart)+end])
+		expression = expression[pos+len(valuesStart)+end+2:]
+	}
+	w.WriteString(expression)
+}
+
 var onDuplicateKeyPart = []byte(` ON DUPLICATE KEY UPDATE `)
 
 const onDuplicateKeyPartS = ` ON DUPLICATE KEY UPDATE `
 
+// rowAliasPartS precedes the row alias of the MySQL 8 syntax, which gets
+// written directly before onDuplicateKeyPartS.
+const rowAliasPartS = ` AS `
+
 // writeOnDuplicateKey writes the columns to `w` and appends the arguments to
 // `args` and returns `args`.
 // https://dev.mysql.com/doc/refman/5.7/en/insert-on-duplicate.html
-func (cs Conditions) writeOnDuplicateKey(w *bytes.Buffer, placeHolders []string) ([]string, error) {
+func (cs Conditions) writeOnDuplicateKey(w *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
+	return cs.writeOnDuplicateKeyAlias(w, d, "", placeHolders)
+}
+
+// writeOnDuplicateKeyAlias same as writeOnDuplicateKey but a non-empty
+// rowAlias writes the MySQL 8 syntax `AS alias ON DUPLICATE KEY UPDATE
+// a=alias.a` instead of VALUES(a).
+// https://dev.mysql.com/doc/refman/8.0/en/insert-on-duplicate.html
+func (cs Conditions) writeOnDuplicateKeyAlias(w *bytes.Buffer, d mysqlDialect, rowAlias string, placeHolders []string) ([]string, error) {
 	if len(cs) == 0 {
 		return placeHolders, nil
 	}
 
+	if rowAlias != "" {
+		w.WriteString(rowAliasPartS)
+		Quoter.quote(w, rowAlias)
+	}
 	w.Write(onDuplicateKeyPart)
 	for i, cnd := range cs {
 		addColon := false
@@ -1140,7 +1352,7 @@ func (cs Conditions) writeOnDuplicateKey(w *bytes.Buffer, placeHolders []string)
 			}
 			Quoter.quote(w, col)
 			w.WriteByte('=')
-			writeSQLValues(w, col)
+			writeSQLValues(w, rowAlias, col)
 			addColon = true
 		}
 		if cnd.Left == "" {
@@ -1154,7 +1366,14 @@ func (cs Conditions) writeOnDuplicateKey(w *bytes.Buffer, placeHolders []string)
 
 		switch {
 		case cnd.Right.IsExpression: // maybe that case is superfluous
-			if _, err := writeExpression(w, cnd.Right.Column, cnd.Right.args); err != nil {
+			expr := cnd.Right.Column
+			if rowAlias != "" {
+				buf := bufferpool.Get()
+				rewriteSQLValues(buf, rowAlias, expr)
+				expr = buf.String()
+				bufferpool.Put(buf)
+			}
+			if _, err := writeExpression(w, d, expr, cnd.Right.args); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
@@ -1177,9 +1396,9 @@ func (cs Conditions) writeOnDuplicateKey(w *bytes.Buffer, placeHolders []string)
 			}
 
 		case cnd.Right.arg == nil:
-			writeSQLValues(w, cnd.Left)
+			writeSQLValues(w, rowAlias, cnd.Left)
 		case cnd.Right.arg != nil:
-			if err := writeInterfaceValue(cnd.Right.arg, w, 0); err != nil {
+			if err := writeInterfaceValue(cnd.Right.arg, w, d, 0); err != nil {
 				return nil, errors.WithStack(err)
 			}
 
@@ -0,0 +1,383 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// Flags of jsonMembershipFallback. If set, the conditions MemberOf and
+// JSONOverlaps get written with JSON_CONTAINS.
+const (
+	fallbackMemberOf uint8 = 1 << iota
+	fallbackJSONOverlaps
+)
+
+// MemberOf checks whether the value is an element of the JSON array stored in
+// the column. MySQL >= 8.0.17 uses a multi-valued index on the column instead
+// of a table scan.
+//		Column("tags").MemberOf().PlaceHolder()
+//		// (? MEMBER OF(`tags`))
+//		Column("tags").MemberOf().Str("red")
+//		// ('red' MEMBER OF(`tags`))
+// Servers without MEMBER OF, see WithDetectJSONMembership, get the condition
+// written as:
+//		// (JSON_CONTAINS(`tags`, JSON_ARRAY('red')))
+func (c *Condition) MemberOf() *Condition {
+	c.Operator = MemberOf
+	return c
+}
+
+// JSONOverlaps checks whether the JSON array stored in the column has at least
+// one element in common with the values. MySQL >= 8.0.17 uses a multi-valued
+// index on the column instead of a table scan. A place holder must be bound to
+// a JSON array.
+//		Column("tags").JSONOverlaps().Strs("red", "blue")
+//		// (JSON_OVERLAPS(`tags`, JSON_ARRAY('red','blue')))
+//		Column("tags").JSONOverlaps().PlaceHolder()
+//		// (JSON_OVERLAPS(`tags`, ?))
+// Servers without JSON_OVERLAPS, see WithDetectJSONMembership, get one
+// JSON_CONTAINS per value connected with OR. The fallback requires values and
+// does not support place holders.
+//		// (JSON_CONTAINS(`tags`, JSON_ARRAY('red')) OR JSON_CONTAINS(`tags`, JSON_ARRAY('blue')))
+func (c *Condition) JSONOverlaps() *Condition {
+	c.Operator = JSONOverlaps
+	return c
+}
+
+// writeJSONMembership writes the MemberOf and JSONOverlaps conditions, which
+// require the value before the column or as function argument.
+func (c *Condition) writeJSONMembership(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
+	arg := c.Right.arg
+	switch {
+	case arg == nil && len(c.Right.args) == 1:
+		arg = c.Right.args[0]
+	case len(c.Right.args) > 1:
+		return nil, errors.NotSupported.Newf("[dml] Condition %q: Multiple arguments are not supported, use a slice", c.Left)
+	}
+	isPlaceHolder := arg == nil && c.Right.PlaceHolder != ""
+	if arg == nil && !isPlaceHolder {
+		return nil, errors.NotAcceptable.Newf("[dml] Condition %q requires a value or a place holder", c.Left)
+	}
+	argLen, isSlice := sliceLen(arg)
+	fallback := d.jsonMembershipFallback
+
+	writeValue := func(pos uint) error {
+		if isPlaceHolder {
+			placeHolders = c.appendPlaceHolder(w, placeHolders)
+			return nil
+		}
+		return writeInterfaceValue(arg, w, d, pos)
+	}
+	writeContains := func(pos uint) error {
+		w.WriteString("JSON_CONTAINS(")
+		c.writeLeft(w, d)
+		w.WriteString(", JSON_ARRAY(")
+		if err := writeValue(pos); err != nil {
+			return errors.WithStack(err)
+		}
+		_, err := w.WriteString("))")
+		return err
+	}
+
+	switch {
+	case c.Operator == MemberOf && isSlice:
+		return nil, errors.NotAcceptable.Newf("[dml] Condition %q: MemberOf requires a single value, use JSONOverlaps for slices", c.Left)
+
+	case c.Operator == MemberOf && fallback&fallbackMemberOf != 0:
+		err = writeContains(0)
+
+	case c.Operator == MemberOf:
+		if err = writeValue(0); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		w.WriteString(" MEMBER OF(")
+		c.writeLeft(w, d)
+		w.WriteByte(')')
+
+	case fallback&fallbackJSONOverlaps != 0:
+		if isPlaceHolder {
+			return nil, errors.NotSupported.Newf("[dml] Condition %q: The JSON_CONTAINS fallback of JSONOverlaps does not support place holders", c.Left)
+		}
+		if !isSlice {
+			err = writeContains(0)
+			break
+		}
+		if argLen == 0 {
+			w.WriteString("0") // nothing overlaps with an empty array
+		}
+		for i := 0; i < argLen && err == nil; i++ {
+			if i > 0 {
+				w.WriteString(" OR ")
+			}
+			err = writeContains(uint(i + 1))
+		}
+
+	default:
+		w.WriteString("JSON_OVERLAPS(")
+		c.writeLeft(w, d)
+		w.WriteString(", ")
+		switch {
+		case isPlaceHolder:
+			err = writeValue(0)
+		case isSlice: // writes the values enclosed in parentheses
+			w.WriteString("JSON_ARRAY")
+			err = writeValue(0)
+		default:
+			w.WriteString("JSON_ARRAY(")
+			err = writeValue(0)
+			w.WriteByte(')')
+		}
+		w.WriteByte(')')
+	}
+	return placeHolders, errors.WithStack(err)
+}
+
+// appendPlaceHolder writes the place holder and appends its name, like
+// Conditions.write does.
+func (c *Condition) appendPlaceHolder(w *bytes.Buffer, placeHolders []string) []string {
+	switch ph := c.Right.PlaceHolder; {
+	case ph == placeHolderStr:
+		w.WriteByte(placeHolderRune)
+		return append(placeHolders, c.Left)
+	case isNamedArg(ph):
+		w.WriteByte(placeHolderRune)
+		if !strings.HasPrefix(ph, namedArgStartStr) {
+			ph = namedArgStartStr + ph
+		}
+		return append(placeHolders, ph)
+	default:
+		w.WriteString(ph)
+		return append(placeHolders, c.Left)
+	}
+}
+
+// JSONExtract compares the value at the JSON path of the column instead of the
+// column itself. The path gets validated and written as an escaped string
+// literal. All operators, place holders and values work as for a column.
+//		Column("attrs").JSONExtract("$.color").Equal().Str("red")
+//		// (JSON_EXTRACT(`attrs`, '$.color') = 'red')
+//		Column("attrs").JSONExtract("$.tags").MemberOf().PlaceHolder()
+//		// (? MEMBER OF(JSON_EXTRACT(`attrs`, '$.tags')))
+// Used in Select.AddColumnsConditions, the value gets returned as JSON:
+//		Column("attrs").JSONExtract("$.color").Alias("color")
+//		// JSON_EXTRACT(`attrs`, '$.color') AS `color`
+func (c *Condition) JSONExtract(path string) *Condition {
+	if err := validateJSONPath(path); err != nil && c.previousErr == nil {
+		c.previousErr = err
+	}
+	c.jsonPath = path
+	c.jsonUnquote = false
+	return c
+}
+
+// JSONUnquote same as JSONExtract but removes the quotes of a JSON string,
+// equivalent to the ->> operator of MySQL, which MariaDB does not support.
+//		Column("attrs").JSONUnquote("$.color").Like().Str("re%")
+//		// (JSON_UNQUOTE(JSON_EXTRACT(`attrs`, '$.color')) LIKE 're%')
+func (c *Condition) JSONUnquote(path string) *Condition {
+	c.JSONExtract(path)
+	c.jsonUnquote = true
+	return c
+}
+
+// JSONContains checks whether the JSON document of the value is contained in
+// the column, or at the path of the column when combined with JSONExtract.
+// The value must be a valid JSON document, hence a string must be enclosed in
+// double quotes.
+//		Column("attrs").JSONContains().Str(`{"color":"red"}`)
+//		// (JSON_CONTAINS(`attrs`, '{\"color\":\"red\"}'))
+//		Column("attrs").JSONExtract("$.tags").JSONContains().PlaceHolder()
+//		// (JSON_CONTAINS(JSON_EXTRACT(`attrs`, '$.tags'), ?))
+func (c *Condition) JSONContains() *Condition {
+	c.Operator = JSONContains
+	return c
+}
+
+// JSONSet assigns in an UPDATE SET clause the values to the paths of the JSON
+// document in the column. Paths which do not exist get created. Each path
+// requires one value, applied with the value functions or bound as place
+// holders. The paths get validated and written as escaped string literals.
+//		NewUpdate("catalog_product").AddClauses(
+//			Column("attrs").JSONSet("$.color", "$.size").Str("red").Int(42),
+//		)
+//		// `attrs`=JSON_SET(`attrs`, '$.color', 'red', '$.size', 42)
+func (c *Condition) JSONSet(paths ...string) *Condition {
+	return c.jsonModify("JSON_SET", true, paths)
+}
+
+// JSONRemove removes in an UPDATE SET clause the paths from the JSON document
+// in the column.
+//		Column("attrs").JSONRemove("$.color")
+//		// `attrs`=JSON_REMOVE(`attrs`, '$.color')
+func (c *Condition) JSONRemove(paths ...string) *Condition {
+	return c.jsonModify("JSON_REMOVE", false, paths)
+}
+
+func (c *Condition) jsonModify(function string, withValues bool, paths []string) *Condition {
+	if len(paths) == 0 && c.previousErr == nil {
+		c.previousErr = errors.Empty.Newf("[dml] %s on column %q requires at least one path", function, c.Left)
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString(function)
+	buf.WriteByte('(')
+	Quoter.WriteIdentifier(buf, c.Left)
+	for _, p := range paths {
+		if err := validateJSONPath(p); err != nil && c.previousErr == nil {
+			c.previousErr = err
+		}
+		if strings.IndexByte(p, '\\') >= 0 && c.previousErr == nil {
+			c.previousErr = errors.NotSupported.Newf("[dml] %s on column %q does not support a backslash in the path %q", function, c.Left, p)
+		}
+		buf.WriteString(", ")
+		// Without a backslash the path is valid with and without the sql_mode
+		// NO_BACKSLASH_ESCAPES. The condition does not know its connection.
+		escapeStringQuotes(buf, p)
+		if withValues {
+			buf.WriteString(", ?")
+		}
+	}
+	buf.WriteByte(')')
+	return c.Expr(buf.String())
+}
+
+// validateJSONPath checks the path for the leading $. A question mark would
+// be mistaken for a place holder during the interpolation.
+func validateJSONPath(path string) error {
+	if !strings.HasPrefix(path, "$") || strings.IndexByte(path, placeHolderRune) >= 0 {
+		return errors.NotValid.Newf("[dml] JSON path %q must start with $ and must not contain a question mark", path)
+	}
+	return nil
+}
+
+// writeLeft writes the quoted left column or its JSON_EXTRACT or
+// ST_Distance_Sphere expression.
+func (c *Condition) writeLeft(w *bytes.Buffer, d mysqlDialect) {
+	if c.stDistanceTo != nil {
+		w.WriteString("ST_Distance_Sphere(")
+		Quoter.WriteIdentifier(w, c.Left)
+		w.WriteString(", ")
+		c.stDistanceTo.writeTo(w)
+		w.WriteByte(')')
+		return
+	}
+	if c.jsonPath == "" {
+		Quoter.WriteIdentifier(w, c.Left)
+		return
+	}
+	if c.jsonUnquote {
+		w.WriteString("JSON_UNQUOTE(")
+	}
+	w.WriteString("JSON_EXTRACT(")
+	Quoter.WriteIdentifier(w, c.Left)
+	w.WriteString(", ")
+	d.EscapeString(w, c.jsonPath)
+	w.WriteByte(')')
+	if c.jsonUnquote {
+		w.WriteByte(')')
+	}
+}
+
+// writeFunction writes the conditions JSONContains, STWithin and STContains
+// as a function call with the left column and the value as arguments.
+func (c *Condition) writeFunction(w *bytes.Buffer, d mysqlDialect, function string, placeHolders []string) ([]string, error) {
+	arg := c.Right.arg
+	switch {
+	case arg == nil && len(c.Right.args) == 1:
+		arg = c.Right.args[0]
+	case len(c.Right.args) > 1:
+		return nil, errors.NotSupported.Newf("[dml] Condition %q: %s supports only one value", c.Left, function)
+	}
+	w.WriteString(function)
+	w.WriteByte('(')
+	c.writeLeft(w, d)
+	w.WriteString(", ")
+	switch {
+	case arg != nil:
+		if err := writeInterfaceValue(arg, w, d, 0); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	case c.Right.PlaceHolder != "":
+		placeHolders = c.appendPlaceHolder(w, placeHolders)
+	default:
+		return nil, errors.NotAcceptable.Newf("[dml] Condition %q requires a value or a place holder", c.Left)
+	}
+	w.WriteByte(')')
+	return placeHolders, nil
+}
+
+// WithJSONMembershipFallback writes the conditions MemberOf and JSONOverlaps
+// with JSON_CONTAINS, for servers without multi-valued index support. The
+// setting applies to all statements created afterwards by the connection pool
+// and its connections and transactions.
+func WithJSONMembershipFallback(memberOf, jsonOverlaps bool) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 2, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			c.jsonMembershipFallback = jsonMembershipFlags(memberOf, jsonOverlaps)
+			return nil
+		},
+	}
+}
+
+// WithDetectJSONMembership queries the server version and applies
+// WithJSONMembershipFallback for servers without MEMBER OF or JSON_OVERLAPS.
+func WithDetectJSONMembership(ctx context.Context) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			var version string
+			if err := c.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+				return errors.Wrapf(err, "[dml] WithDetectJSONMembership failed to query the version")
+			}
+			memberOf, jsonOverlaps := supportsJSONMembership(version)
+			c.jsonMembershipFallback = jsonMembershipFlags(!memberOf, !jsonOverlaps)
+			if c.Log != nil && c.Log.IsDebug() {
+				c.Log.Debug("WithDetectJSONMembership", log.String("version", version),
+					log.Bool("member_of", memberOf), log.Bool("json_overlaps", jsonOverlaps))
+			}
+			return nil
+		},
+	}
+}
+
+func jsonMembershipFlags(memberOf, jsonOverlaps bool) (v uint8) {
+	if memberOf {
+		v |= fallbackMemberOf
+	}
+	if jsonOverlaps {
+		v |= fallbackJSONOverlaps
+	}
+	return v
+}
+
+// supportsJSONMembership reports whether a server with the version string as
+// returned by VERSION() supports MEMBER OF and JSON_OVERLAPS. MySQL since
+// 8.0.17 supports both, MariaDB since 10.9 only JSON_OVERLAPS.
+func supportsJSONMembership(version string) (memberOf, jsonOverlaps bool) {
+	isMariaDB, v := parseServerVersion(version)
+	if isMariaDB {
+		return false, v[0] > 10 || (v[0] == 10 && v[1] >= 9)
+	}
+	ok := v[0] > 8 || (v[0] == 8 && (v[1] > 0 || v[2] >= 17))
+	return ok, ok
+}
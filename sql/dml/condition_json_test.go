@@ -0,0 +1,227 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestCondition_JSONMembership(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		cnd          *Condition
+		wantNative   string
+		wantFallback string
+		wantErrKind  errors.Kind
+	}{
+		{
+			Column("tags").MemberOf().PlaceHolder(),
+			"SELECT `id` FROM `products` WHERE (? MEMBER OF(`tags`))",
+			"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`tags`, JSON_ARRAY(?)))",
+			errors.NoKind,
+		},
+		{
+			Column("p.tags").MemberOf().Str("red"),
+			"SELECT `id` FROM `products` WHERE ('red' MEMBER OF(`p`.`tags`))",
+			"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`p`.`tags`, JSON_ARRAY('red')))",
+			errors.NoKind,
+		},
+		{
+			Column("sizes").MemberOf().Int64(42),
+			"SELECT `id` FROM `products` WHERE (42 MEMBER OF(`sizes`))",
+			"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`sizes`, JSON_ARRAY(42)))",
+			errors.NoKind,
+		},
+		{
+			Column("tags").JSONOverlaps().Strs("red", "blue"),
+			"SELECT `id` FROM `products` WHERE (JSON_OVERLAPS(`tags`, JSON_ARRAY('red','blue')))",
+			"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`tags`, JSON_ARRAY('red')) OR JSON_CONTAINS(`tags`, JSON_ARRAY('blue')))",
+			errors.NoKind,
+		},
+		{
+			Column("tags").JSONOverlaps().Str("red"),
+			"SELECT `id` FROM `products` WHERE (JSON_OVERLAPS(`tags`, JSON_ARRAY('red')))",
+			"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`tags`, JSON_ARRAY('red')))",
+			errors.NoKind,
+		},
+		{
+			Column("tags").JSONOverlaps().PlaceHolder(),
+			"SELECT `id` FROM `products` WHERE (JSON_OVERLAPS(`tags`, ?))",
+			"",
+			errors.NotSupported,
+		},
+		{
+			Column("tags").MemberOf().Strs("red", "blue"),
+			"",
+			"",
+			errors.NotAcceptable,
+		},
+		{
+			Column("tags").MemberOf(),
+			"",
+			"",
+			errors.NotAcceptable,
+		},
+	}
+	for _, test := range tests {
+		// A new Select for each call because the SQL string gets cached.
+		wantErrKind := errors.NoKind
+		if test.wantNative == "" {
+			wantErrKind = test.wantErrKind
+		}
+		compareToSQL2(t, NewSelect("id").From("products").Where(test.cnd), wantErrKind, test.wantNative)
+
+		sel := NewSelect("id").From("products").Where(test.cnd)
+		sel.jsonMembershipFallback = fallbackMemberOf | fallbackJSONOverlaps
+		compareToSQL2(t, sel, test.wantErrKind, test.wantFallback)
+	}
+}
+
+func TestCondition_JSONMembership_PlaceHolderNames(t *testing.T) {
+	t.Parallel()
+
+	sel := NewSelect("id").From("products").Where(
+		Column("tags").MemberOf().PlaceHolder(),
+		Column("sizes").JSONOverlaps().NamedArg("sizes"),
+	)
+	_, _, err := sel.WithDBR().ToSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"tags", ":sizes"}, sel.qualifiedColumns)
+}
+
+func TestWithDetectJSONMembership(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version string
+		want    uint8
+	}{
+		{"5.7.29-log", fallbackMemberOf | fallbackJSONOverlaps},
+		{"8.0.16", fallbackMemberOf | fallbackJSONOverlaps},
+		{"8.0.17", 0},
+		{"8.1.0-commercial", 0},
+		{"10.8.3-MariaDB", fallbackMemberOf | fallbackJSONOverlaps},
+		{"5.5.5-10.9.2-MariaDB", fallbackMemberOf},
+		{"11.4.2-MariaDB-ubu2404", fallbackMemberOf},
+	}
+	for _, test := range tests {
+		db, dbMock, err := sqlmock.New()
+		assert.NoError(t, err)
+
+		dbMock.ExpectQuery("SELECT VERSION()").
+			WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow(test.version))
+
+		dbc, err := NewConnPool(WithDB(db), WithDetectJSONMembership(context.TODO()))
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, dbc.jsonMembershipFallback, "Version %q", test.version)
+		assert.Exactly(t, test.want, dbc.SelectFrom("products").jsonMembershipFallback, "Version %q", test.version)
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		tx, err := dbc.BeginTx(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, test.want, tx.SelectFrom("products").jsonMembershipFallback, "Version %q", test.version)
+		assert.NoError(t, tx.Rollback())
+		dbMock.ExpectClose()
+		assert.NoError(t, db.Close())
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	}
+}
+
+func TestCondition_JSONFunctions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WHERE", func(t *testing.T) {
+		tests := []struct {
+			cnd         *Condition
+			want        string
+			wantErrKind errors.Kind
+		}{
+			{
+				Column("attrs").JSONExtract("$.color").Equal().Str("red"),
+				"SELECT `id` FROM `products` WHERE (JSON_EXTRACT(`attrs`, '$.color') = 'red')",
+				errors.NoKind,
+			},
+			{
+				Column("p.attrs").JSONExtract(`$."it's"`).Equal().PlaceHolder(),
+				"SELECT `id` FROM `products` WHERE (JSON_EXTRACT(`p`.`attrs`, '$.\\\"it\\'s\\\"') = ?)",
+				errors.NoKind,
+			},
+			{
+				Column("attrs").JSONUnquote("$.color").In().Strs("red", "blue"),
+				"SELECT `id` FROM `products` WHERE (JSON_UNQUOTE(JSON_EXTRACT(`attrs`, '$.color')) IN ('red','blue'))",
+				errors.NoKind,
+			},
+			{
+				Column("attrs").JSONExtract("$.tags").MemberOf().Str("red"),
+				"SELECT `id` FROM `products` WHERE ('red' MEMBER OF(JSON_EXTRACT(`attrs`, '$.tags')))",
+				errors.NoKind,
+			},
+			{
+				Column("attrs").JSONContains().Str(`{"color":"red"}`),
+				"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(`attrs`, '{\\\"color\\\":\\\"red\\\"}'))",
+				errors.NoKind,
+			},
+			{
+				Column("attrs").JSONExtract("$.tags").JSONContains().PlaceHolder(),
+				"SELECT `id` FROM `products` WHERE (JSON_CONTAINS(JSON_EXTRACT(`attrs`, '$.tags'), ?))",
+				errors.NoKind,
+			},
+			{
+				Column("attrs").JSONContains(),
+				"",
+				errors.NotAcceptable,
+			},
+			{
+				Column("attrs").JSONExtract("color').Equal(1) OR ('1").Equal().Int(1),
+				"",
+				errors.NotValid,
+			},
+			{
+				Column("attrs").JSONExtract("$.a?").Equal().Int(1),
+				"",
+				errors.NotValid,
+			},
+		}
+		for _, test := range tests {
+			compareToSQL2(t, NewSelect("id").From("products").Where(test.cnd), test.wantErrKind, test.want)
+		}
+	})
+
+	t.Run("SELECT column", func(t *testing.T) {
+		compareToSQL2(t, NewSelect("id").From("products").AddColumnsConditions(
+			Column("attrs").JSONUnquote("$.color").Alias("color"),
+		), errors.NoKind,
+			"SELECT `id`, JSON_UNQUOTE(JSON_EXTRACT(`attrs`, '$.color')) AS `color` FROM `products`",
+		)
+	})
+
+	t.Run("UPDATE SET", func(t *testing.T) {
+		compareToSQL2(t, NewUpdate("products").AddClauses(
+			Column("attrs").JSONSet("$.color", "$.size").Str("red").Int(42),
+			Column("meta").JSONRemove("$.legacy", "$.tmp"),
+		).Where(Column("id").Int(3)), errors.NoKind,
+			"UPDATE `products` SET `attrs`=JSON_SET(`attrs`, '$.color', 'red', '$.size', 42), `meta`=JSON_REMOVE(`meta`, '$.legacy', '$.tmp') WHERE (`id` = 3)",
+		)
+		compareToSQL2(t, NewUpdate("products").AddClauses(
+			Column("attrs").JSONSet(),
+		), errors.Empty, "")
+	})
+}
@@ -99,6 +99,37 @@ func TestConditionOpRune(t *testing.T) {
 	)
 }
 
+func TestCondition_NullSafeEqual(t *testing.T) {
+	t.Parallel()
+
+	t.Run("literals", func(t *testing.T) {
+		compareToSQL(t,
+			NewSelect("a").From("t1").Where(
+				Column("a").NullSafeEqual().Str("x"),
+				Column("b").NullSafeEqual().NullString(null.String{}),
+				Column("c").IsDistinctFrom().Int64(3),
+				Column("d").IsDistinctFrom().NullInt64(null.Int64{}),
+				Column("t1.e").IsDistinctFrom().Column("t2.e"),
+			),
+			errors.NoKind,
+			"SELECT `a` FROM `t1` WHERE (`a` <=> 'x') AND (`b` <=> NULL) AND (NOT (`c` <=> 3)) AND (NOT (`d` <=> NULL)) AND (NOT (`t1`.`e` <=> `t2`.`e`))",
+			"",
+		)
+	})
+	t.Run("place holders", func(t *testing.T) {
+		compareToSQL(t,
+			NewSelect("a").From("t1").Where(
+				Column("a").NullSafeEqual().PlaceHolder(),
+				Column("b").IsDistinctFrom().PlaceHolder(),
+			).WithDBR().TestWithArgs("x", nil),
+			errors.NoKind,
+			"SELECT `a` FROM `t1` WHERE (`a` <=> ?) AND (NOT (`b` <=> ?))",
+			"SELECT `a` FROM `t1` WHERE (`a` <=> 'x') AND (NOT (`b` <=> NULL))",
+			"x", nil,
+		)
+	})
+}
+
 func TestConditionOp_String(t *testing.T) {
 	t.Parallel()
 	var o Op
@@ -253,7 +284,7 @@ func TestConditions_writeOnDuplicateKey(t *testing.T) {
 		return func(t *testing.T) {
 			buf := new(bytes.Buffer)
 
-			ph, err := cnds.writeOnDuplicateKey(buf, nil)
+			ph, err := cnds.writeOnDuplicateKey(buf, dialect, nil)
 			assert.Nil(t, ph, "TODO check me")
 			assert.NoError(t, err)
 		}
@@ -413,6 +444,44 @@ func TestConditionExpr(t *testing.T) {
 	})
 }
 
+func TestCondition_EscapeLike(t *testing.T) {
+	t.Parallel()
+
+	t.Run("literal value", func(t *testing.T) {
+		s := NewSelect("sku").From("catalog_product_entity").Where(
+			Column("sku").EscapeLike("100%_off!", '!'),
+			Column("name").NotLike().EscapeLike("Grüß_", 0),
+		)
+		compareToSQL(t, s, errors.NoKind,
+			"SELECT `sku` FROM `catalog_product_entity` WHERE (`sku` LIKE '100!%!_off!!' ESCAPE '!') AND (`name` NOT LIKE 'Grüß\\\\_' ESCAPE '\\\\')",
+			"",
+		)
+	})
+	t.Run("own wildcards", func(t *testing.T) {
+		s := NewSelect("sku").From("catalog_product_entity").Where(
+			Column("name").Like().Str("%"+EscapeLikeValue("50%", '|')+"%").EscapeLike("", '|'),
+		)
+		compareToSQL(t, s, errors.NoKind,
+			"SELECT `sku` FROM `catalog_product_entity` WHERE (`name` LIKE '%50|%%' ESCAPE '|')",
+			"",
+		)
+	})
+	t.Run("place holder", func(t *testing.T) {
+		s := NewSelect("sku").From("catalog_product_entity").Where(
+			Column("name").PlaceHolder().EscapeLike("", '!'),
+		)
+		compareToSQL(t, s, errors.NoKind,
+			"SELECT `sku` FROM `catalog_product_entity` WHERE (`name` LIKE ? ESCAPE '!')",
+			"",
+		)
+	})
+	t.Run("EscapeLikeValue", func(t *testing.T) {
+		assert.Exactly(t, "abc", EscapeLikeValue("abc", '!'))
+		assert.Exactly(t, `a\%b\_c\\`, EscapeLikeValue(`a%b_c\`, 0))
+		assert.Exactly(t, "€€€%€_ä", EscapeLikeValue("€%_ä", '€'))
+	})
+}
+
 func TestCondition_Columns_Tuples(t *testing.T) {
 	t.Parallel()
 	/*
@@ -428,13 +497,13 @@ func TestCondition_Columns_Tuples(t *testing.T) {
 	}
 	t.Run("WHERE withDBR=false", func(t *testing.T) {
 		var buf bytes.Buffer
-		_, err := cond.write(&buf, 'w', nil, false)
+		_, err := cond.write(&buf, dialect, 'w', nil, false)
 		assert.NoError(t, err)
 		assert.Exactly(t, " WHERE ((`entity_id`, `attribute_id`, `store_id`, `source_id`) IN ((?,?,?,?)))", buf.String())
 	})
 	t.Run("WHERE withDBR=true", func(t *testing.T) {
 		var buf bytes.Buffer
-		_, err := cond.write(&buf, 'w', nil, true)
+		_, err := cond.write(&buf, dialect, 'w', nil, true)
 		assert.NoError(t, err)
 		assert.Exactly(t, " WHERE ((`entity_id`, `attribute_id`, `store_id`, `source_id`) IN /*TUPLES=004*/)", buf.String())
 	})
@@ -51,6 +51,133 @@ type connCommon struct {
 	makeUniqueID uniqueIDFn
 	mapTableName func(oldName string) (newName string)
 	runOnClose   []ConnPoolOption
+	// killQuery if set, sends a KILL QUERY when the context of a running
+	// query gets canceled. See WithKillQueryOnCancel.
+	killQuery *killQuery
+	// connGroups contains named connection pools. Only set in ConnPool. See
+	// WithConnGroup.
+	connGroups map[string]*sql.DB
+	// replicas if set, receive the queries of a Select. See WithReplicas.
+	replicas *replicaSet
+	// txCTEs contains the common table expressions prepended to each Select,
+	// Update and Delete. Only set in Tx. See Tx.RegisterCTE.
+	txCTEs []WithCTE
+	// poolSizer if set, samples the pool statistics. Only set in ConnPool.
+	// See WithPoolSizing.
+	poolSizer *poolSizer
+	// serverTimeZone if set, converts time values from and to UTC. See
+	// WithServerTimeZone.
+	serverTimeZone *time.Location
+	// emulateSetOperations lets Intersect and Except emulate the set
+	// operations. See WithDetectSetOperations.
+	emulateSetOperations bool
+	// metrics receives the durations of all statements. See WithMetrics.
+	metrics Metrics
+	// hooks get called before and after each statement. See WithQueryHooks.
+	hooks queryHooks
+	// retry if set, retries idempotent statements failing with a transient
+	// error. See WithRetryPolicy.
+	retry *RetryPolicy
+	// allowList if set, checks the digest of each statement before its
+	// execution. See WithAllowList.
+	allowList *AllowList
+	// slowQuery if set, logs statements exceeding a threshold. See
+	// WithSlowQueryLog.
+	slowQuery *slowQueryLog
+	// compression maps a table name to the compressors of its columns. See
+	// WithColumnCompression.
+	compression compressionTables
+	// encryption maps a column name to its encrypter. See
+	// WithColumnEncryption.
+	encryption map[string]*columnEncrypter
+	// lockWait if greater zero, limits the innodb_lock_wait_timeout of a
+	// statement to the remaining time of the context deadline. See
+	// WithDeadlineLockWaitTimeout.
+	lockWait time.Duration
+	// lockWaitSyntax contains the syntax of the server to set the lock wait
+	// timeout. See WithDeadlineLockWaitTimeout.
+	lockWaitSyntax uint8
+	// lockWaitDetector caches the syntax of the server for
+	// Select.LockWaitTimeout, if WithDeadlineLockWaitTimeout has not been
+	// applied. Shared with all derived connections.
+	lockWaitDetector *lockWaitDetector
+	// lockWaitState tracks the lock wait timeout of the session of a Tx or a
+	// Conn. Nil in a ConnPool.
+	lockWaitState *lockWaitState
+	// maxExecTime if set, limits the execution time of a SELECT to the
+	// remaining time of the context deadline. See
+	// WithDeadlineMaxExecutionTime.
+	maxExecTime uint8
+	// adaptive if set, interpolates one-off statements and prepares frequently
+	// executed statements. See WithAdaptivePrepare.
+	adaptive *adaptivePrepare
+	// stmtCache if set, caches prepared statements. See WithStmtCache.
+	stmtCache *stmtCache
+	// txStmts if set, keeps the cached statements bound to the transaction.
+	// Nil outside of a Tx.
+	txStmts *txStmtCache
+	// sqlCommenter if set, appends the tags of the context as a comment to
+	// each statement. See WithSQLCommenter.
+	sqlCommenter *SQLCommenter
+	// interpolate if enabled, interpolates the arguments of all statements.
+	// Shared with all derived connections. See ConnPool.WithDefaultInterpolate.
+	interpolate *interpolateToggle
+	// returning contains the RETURNING capabilities of the server. See
+	// WithDetectReturning.
+	returning uint8
+	// noBackslashEscapes if true, strings get escaped for the sql_mode
+	// NO_BACKSLASH_ESCAPES. See WithNoBackslashEscapes.
+	noBackslashEscapes bool
+	// jsonMembershipFallback contains the flags fallbackMemberOf and
+	// fallbackJSONOverlaps. See WithJSONMembershipFallback.
+	jsonMembershipFallback uint8
+	// resultCacheNamespace separates the keys of the result cache of different
+	// databases. See WithResultCacheNamespace.
+	resultCacheNamespace string
+}
+
+// newBuilderCommon creates the builderCommon of a statement with all settings
+// of the connection. All statement types must be created with it, otherwise
+// they miss connection features like metrics, hooks or the allow list.
+// Replicas are only set by Select.
+func (c *connCommon) newBuilderCommon(id string, l log.Logger, db QueryExecPreparer) builderCommon {
+	return builderCommon{
+		id:                     id,
+		Log:                    l,
+		db:                     db,
+		connGroups:             c.connGroups,
+		serverTimeZone:         c.serverTimeZone,
+		metrics:                c.metrics,
+		hooks:                  c.hooks,
+		retry:                  c.retry,
+		allowList:              c.allowList,
+		compression:            c.compression,
+		encryption:             c.encryption,
+		lockWait:               c.lockWait,
+		lockWaitSyntax:         c.lockWaitSyntax,
+		lockWaitDetector:       c.lockWaitDetector,
+		lockWaitState:          c.lockWaitState,
+		maxExecTime:            c.maxExecTime,
+		sqlCommenter:           c.sqlCommenter,
+		txCTEs:                 c.txCTEs,
+		adaptive:               c.adaptive,
+		stmtCache:              c.stmtCache,
+		txStmts:                c.txStmts,
+		slowQuery:              c.slowQuery,
+		interpolate:            c.interpolate,
+		returning:              c.returning,
+		noBackslashEscapes:     c.noBackslashEscapes,
+		jsonMembershipFallback: c.jsonMembershipFallback,
+		resultCacheNamespace:   c.resultCacheNamespace,
+	}
+}
+
+// sqlDialect returns the dialect of the statements created by the connection.
+func (c *connCommon) sqlDialect() mysqlDialect {
+	d := dialect
+	d.noBackslashEscapes = c.noBackslashEscapes
+	d.jsonMembershipFallback = c.jsonMembershipFallback
+	return d
 }
 
 // ConnPool at a connection to the database with an EventReceiver to send
@@ -74,6 +201,16 @@ type ConnPool struct {
 type Conn struct {
 	connCommon
 	DB *sql.Conn
+	// killConn wraps DB when the option WithKillQueryOnCancel has been applied.
+	killConn *killQueryConn
+}
+
+// qep returns the DB or in case of enabled KILL QUERY feature the wrapped DB.
+func (c *Conn) qep() QueryExecPreparer {
+	if c.killConn != nil {
+		return c.killConn
+	}
+	return c.DB
 }
 
 // Tx is an in-progress database transaction.
@@ -90,6 +227,12 @@ type Conn struct {
 type Tx struct {
 	connCommon
 	DB *sql.Tx
+	// nestedLevel counts the nested scopes of WrapNested.
+	nestedLevel int
+	// tempTables contains the names of the materialized common table
+	// expressions, dropped at the end of the transaction. See
+	// Tx.MaterializeCTE.
+	tempTables []string
 }
 
 // ConnPoolOption can be used at an argument in NewConnPool to configure a
@@ -246,6 +389,40 @@ func WithSetNamesUTF8MB4() ConnPoolOption {
 	}
 }
 
+// WithNoBackslashEscapes switches the string escaper of the interpolation to
+// the rules of the sql_mode NO_BACKSLASH_ESCAPES. Without it, interpolated
+// queries are broken or exploitable on servers running in that mode. The
+// setting applies to all statements created afterwards by the connection pool
+// and its connections and transactions.
+func WithNoBackslashEscapes(enable bool) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 2, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			c.noBackslashEscapes = enable
+			return nil
+		},
+	}
+}
+
+// WithDetectNoBackslashEscapes queries the sql_mode of the server and applies
+// WithNoBackslashEscapes if the sql_mode contains NO_BACKSLASH_ESCAPES.
+func WithDetectNoBackslashEscapes(ctx context.Context) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			var sqlMode string
+			if err := c.DB.QueryRowContext(ctx, "SELECT @@SESSION.sql_mode").Scan(&sqlMode); err != nil {
+				return errors.Wrapf(err, "[dml] WithDetectNoBackslashEscapes failed to query the sql_mode")
+			}
+			enable := strings.Contains(strings.ToUpper(sqlMode), "NO_BACKSLASH_ESCAPES")
+			if c.Log != nil && c.Log.IsDebug() {
+				c.Log.Debug("WithDetectNoBackslashEscapes", log.String("sql_mode", sqlMode), log.Bool("no_backslash_escapes", enable))
+			}
+			return WithNoBackslashEscapes(enable).fn(c)
+		},
+	}
+}
+
 // WithDB sets the DB value to an existing connection. Mainly used for testing.
 // Does not support DriverCallBack.
 func WithDB(db *sql.DB) ConnPoolOption {
@@ -344,7 +521,9 @@ func (t dsnConnector) Driver() driver.Driver {
 // When you want to set it for longer than an hour, discuss that with an
 // infrastructure/network engineer.
 func NewConnPool(opts ...ConnPoolOption) (*ConnPool, error) {
-	var c ConnPool
+	c := ConnPool{
+		connCommon: connCommon{interpolate: new(interpolateToggle), lockWaitDetector: new(lockWaitDetector)},
+	}
 	if err := c.Options(opts...); err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -355,6 +534,9 @@ func NewConnPool(opts ...ConnPoolOption) (*ConnPool, error) {
 	if c.mapTableName == nil {
 		c.mapTableName = mapTableNameNoOp
 	}
+	if c.resultCacheNamespace == "" {
+		c.resultCacheNamespace = defaultResultCacheNamespace(c.dsn)
+	}
 	// validate that DSN contains the utf8mb4 setting, if DSN is set
 
 	return &c, nil
@@ -433,15 +615,33 @@ func (c *ConnPool) Close() (err error) {
 	if c.Log != nil && c.Log.IsDebug() {
 		defer c.Log.Debug("Close", log.Err(err), log.Duration("duration", now().Sub(c.start)))
 	}
+	// All resources get closed, the first error gets returned.
 	for _, opt := range c.runOnClose {
-		if err = opt.fn(c); err != nil {
-			return errors.WithStack(err)
+		if errC := opt.fn(c); errC != nil && err == nil {
+			err = errors.WithStack(errC)
+		}
+	}
+	if errC := c.closeConnGroups(); errC != nil && err == nil {
+		err = errC
+	}
+	c.poolSizer.close()
+	if errC := c.replicas.close(); errC != nil && err == nil {
+		err = errC
+	}
+	if c.adaptive != nil {
+		if errC := c.adaptive.close(); errC != nil && err == nil {
+			err = errC
 		}
 	}
+	if errC := c.stmtCache.close(); errC != nil && err == nil {
+		err = errC
+	}
 	if c.DB != nil {
-		err = c.DB.Close() // no stack wrap otherwise error is hard to compare
+		if errC := c.DB.Close(); errC != nil && err == nil {
+			err = errC // no stack wrap otherwise error is hard to compare
+		}
 	}
-	return
+	return err
 }
 
 // BeginTx starts a transaction.
@@ -472,10 +672,33 @@ func (c *ConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error
 	}
 	return &Tx{
 		connCommon: connCommon{
-			start:        start,
-			Log:          l,
-			makeUniqueID: c.makeUniqueID,
-			mapTableName: c.mapTableName,
+			start:                  start,
+			Log:                    l,
+			makeUniqueID:           c.makeUniqueID,
+			mapTableName:           c.mapTableName,
+			serverTimeZone:         c.serverTimeZone,
+			metrics:                c.metrics,
+			hooks:                  c.hooks,
+			retry:                  c.retry,
+			allowList:              c.allowList,
+			compression:            c.compression,
+			encryption:             c.encryption,
+			lockWait:               c.lockWait,
+			lockWaitSyntax:         c.lockWaitSyntax,
+			lockWaitDetector:       c.lockWaitDetector,
+			lockWaitState:          newLockWaitState(c.lockWait),
+			maxExecTime:            c.maxExecTime,
+			sqlCommenter:           c.sqlCommenter,
+			adaptive:               c.adaptive,
+			stmtCache:              c.stmtCache,
+			txStmts:                c.stmtCache.newTxStmtCache(),
+			slowQuery:              c.slowQuery,
+			interpolate:            c.interpolate,
+			emulateSetOperations:   c.emulateSetOperations,
+			returning:              c.returning,
+			noBackslashEscapes:     c.noBackslashEscapes,
+			jsonMembershipFallback: c.jsonMembershipFallback,
+			resultCacheNamespace:   c.resultCacheNamespace,
 		},
 		DB: dbTx,
 	}, nil
@@ -520,15 +743,13 @@ func (c *ConnPool) Transaction(ctx context.Context, opts *sql.TxOptions, fns ...
 // errors of the QueryBuilder will be forwarded to the DBR type.
 func (c *ConnPool) WithQueryBuilder(qb QueryBuilder) *DBR {
 	sql, _, err := qb.ToSQL()
+	bc := c.newBuilderCommon(c.makeUniqueID(), c.Log, c.DB)
+	bc.cachedSQL = map[string]string{"": sql}
+	bc.ärgErr = errors.WithStack(err)
 	a := &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": sql},
-			Log:       c.Log,
-			id:        c.makeUniqueID(),
-			db:        c.DB,
-			ärgErr:    errors.WithStack(err),
-		},
+		base: bc,
 	}
+	a.tables, a.tablesKnown = builderTables(qb)
 	return a
 }
 
@@ -545,15 +766,50 @@ func (c *ConnPool) Conn(ctx context.Context) (*Conn, error) {
 	if l != nil {
 		l = c.Log.With(log.String("conn_id", c.makeUniqueID()))
 	}
-	return &Conn{
+	var kqc *killQueryConn
+	if err == nil && c.killQuery != nil {
+		if kqc, err = newKillQueryConn(ctx, c.killQuery, dbc); err != nil {
+			_ = dbc.Close()
+			return nil, errors.WithStack(err)
+		}
+	}
+	conn := &Conn{
 		connCommon: connCommon{
-			start:        now(),
-			Log:          l,
-			makeUniqueID: c.makeUniqueID,
-			mapTableName: c.mapTableName,
+			start:                  now(),
+			Log:                    l,
+			makeUniqueID:           c.makeUniqueID,
+			mapTableName:           c.mapTableName,
+			killQuery:              c.killQuery,
+			serverTimeZone:         c.serverTimeZone,
+			metrics:                c.metrics,
+			hooks:                  c.hooks,
+			retry:                  c.retry,
+			allowList:              c.allowList,
+			compression:            c.compression,
+			encryption:             c.encryption,
+			lockWait:               c.lockWait,
+			lockWaitSyntax:         c.lockWaitSyntax,
+			lockWaitDetector:       c.lockWaitDetector,
+			lockWaitState:          newLockWaitState(c.lockWait),
+			maxExecTime:            c.maxExecTime,
+			sqlCommenter:           c.sqlCommenter,
+			adaptive:               c.adaptive,
+			slowQuery:              c.slowQuery,
+			interpolate:            c.interpolate,
+			emulateSetOperations:   c.emulateSetOperations,
+			returning:              c.returning,
+			noBackslashEscapes:     c.noBackslashEscapes,
+			jsonMembershipFallback: c.jsonMembershipFallback,
+			resultCacheNamespace:   c.resultCacheNamespace,
 		},
-		DB: dbc,
-	}, errors.WithStack(err)
+		DB:       dbc,
+		killConn: kqc,
+	}
+	if err == nil {
+		// prepared statements belong to the connection
+		conn.stmtCache = c.stmtCache.derive(conn.qep())
+	}
+	return conn, errors.WithStack(err)
 }
 
 // WithRawSQL creates a new DBR for the given SQL string. It does not
@@ -565,13 +821,10 @@ func (c *ConnPool) WithRawSQL(query string) *DBR {
 	if l != nil {
 		l = l.With(log.String("conn_pool_raw_sql_id", id), log.String("query", query))
 	}
+	bc := c.newBuilderCommon(id, l, c.DB)
+	bc.cachedSQL = map[string]string{"": query}
 	return &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": query},
-			Log:       l,
-			id:        id,
-			db:        c.DB,
-		},
+		base: bc,
 	}
 }
 
@@ -588,14 +841,11 @@ func (c *ConnPool) WithPrepare(ctx context.Context, query string) *DBR {
 		l = l.With(log.String("conn_pool_prepare_sql_id", id), log.String("query", query))
 	}
 
-	stmt, err := c.DB.PrepareContext(ctx, query)
+	stmt, err := c.hooks.prepare(ctx, c.DB, &QueryHookEvent{ID: id, Kind: "Prepare", Source: "raw", Query: query})
+	bc := c.newBuilderCommon(id, l, stmtWrapper{stmt: stmt})
+	bc.ärgErr = err
 	a := &DBR{
-		base: builderCommon{
-			id:     id,
-			ärgErr: err,
-			Log:    l,
-			db:     stmtWrapper{stmt: stmt},
-		},
+		base:       bc,
 		isPrepared: true,
 	}
 	return a
@@ -652,10 +902,33 @@ func (c *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	}
 	return &Tx{
 		connCommon: connCommon{
-			start:        start,
-			Log:          l,
-			makeUniqueID: c.makeUniqueID,
-			mapTableName: c.mapTableName,
+			start:                  start,
+			Log:                    l,
+			makeUniqueID:           c.makeUniqueID,
+			mapTableName:           c.mapTableName,
+			serverTimeZone:         c.serverTimeZone,
+			metrics:                c.metrics,
+			hooks:                  c.hooks,
+			retry:                  c.retry,
+			allowList:              c.allowList,
+			compression:            c.compression,
+			encryption:             c.encryption,
+			lockWait:               c.lockWait,
+			lockWaitSyntax:         c.lockWaitSyntax,
+			lockWaitDetector:       c.lockWaitDetector,
+			lockWaitState:          c.lockWaitState,
+			maxExecTime:            c.maxExecTime,
+			sqlCommenter:           c.sqlCommenter,
+			adaptive:               c.adaptive,
+			stmtCache:              c.stmtCache,
+			txStmts:                c.stmtCache.newTxStmtCache(),
+			slowQuery:              c.slowQuery,
+			interpolate:            c.interpolate,
+			emulateSetOperations:   c.emulateSetOperations,
+			returning:              c.returning,
+			noBackslashEscapes:     c.noBackslashEscapes,
+			jsonMembershipFallback: c.jsonMembershipFallback,
+			resultCacheNamespace:   c.resultCacheNamespace,
 		},
 		DB: dbTx,
 	}, nil
@@ -704,7 +977,18 @@ func (c *Conn) Close() error {
 	if c.Log != nil && c.Log.IsDebug() {
 		defer c.Log.Debug("Close", log.Duration("duration", now().Sub(c.start)))
 	}
-	return c.DB.Close() // no stack wrap otherwise error is hard to compare
+	if c.killConn != nil {
+		c.killConn.stopWatch()
+	}
+	errSC := c.stmtCache.close()
+	if err := c.resetLockWait(c.qep()); err != nil && errSC == nil {
+		errSC = err
+	}
+	err := c.DB.Close() // no stack wrap otherwise error is hard to compare
+	if errSC != nil {
+		return errSC
+	}
+	return err
 }
 
 // WithQueryBuilder creates a new DBR for handling the arguments with the
@@ -717,14 +1001,11 @@ func (c *Conn) WithQueryBuilder(qb QueryBuilder) *DBR {
 	if l != nil {
 		l = l.With(log.String("query_builder_id", id), log.String("sql", sql))
 	}
+	bc := c.newBuilderCommon(id, l, c.qep())
+	bc.cachedSQL = map[string]string{"": sql}
+	bc.ärgErr = errors.WithStack(err)
 	a := &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": sql},
-			Log:       l,
-			id:        id,
-			db:        c.DB,
-			ärgErr:    errors.WithStack(err),
-		},
+		base: bc,
 	}
 	return a
 }
@@ -738,14 +1019,44 @@ func (c *Conn) WithRawSQL(query string) *DBR {
 	if l != nil {
 		l = l.With(log.String("conn_pool_raw_sql_id", id), log.String("sql", query))
 	}
+	bc := c.newBuilderCommon(id, l, c.qep())
+	bc.cachedSQL = map[string]string{"": query}
 	return &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": query},
-			Log:       l,
-			id:        id,
-			db:        c.DB,
-		},
+		base: bc,
+	}
+}
+
+// SessionVars creates a new DBR which selects the user defined session
+// variables, e.g. @total, of the current connection. Use one of the Load
+// functions to retrieve the typed values, e.g. LoadNullInt64 for a single
+// variable or Load with a ColumnMapper for several variables. See
+// Select.IntoVars.
+func (c *Conn) SessionVars(vars ...string) *DBR {
+	sqlStr, err := sessionVarsSQL(vars)
+	return c.WithQueryBuilder(QuerySQLFn(func() (string, []interface{}, error) {
+		return sqlStr, nil, err
+	}))
+}
+
+// SessionVars creates a new DBR which selects the user defined session
+// variables of the current transaction. See Conn.SessionVars.
+func (tx *Tx) SessionVars(vars ...string) *DBR {
+	sqlStr, err := sessionVarsSQL(vars)
+	return tx.WithQueryBuilder(QuerySQLFn(func() (string, []interface{}, error) {
+		return sqlStr, nil, err
+	}))
+}
+
+func sessionVarsSQL(vars []string) (string, error) {
+	if len(vars) == 0 {
+		return "", errors.Empty.Newf("[dml] SessionVars: no variables specified")
 	}
+	for _, v := range vars {
+		if err := isValidUserVariable(v); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return "SELECT " + strings.Join(vars, ", "), nil
 }
 
 // WithRawSQL creates a new DBR for the given SQL string in the current
@@ -757,13 +1068,10 @@ func (tx *Tx) WithRawSQL(query string) *DBR {
 	if l != nil {
 		l = l.With(log.String("tx_raw_sql_id", id), log.String("sql", query))
 	}
+	bc := tx.newBuilderCommon(id, l, tx.DB)
+	bc.cachedSQL = map[string]string{"": query}
 	return &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": query},
-			Log:       l,
-			id:        id,
-			db:        tx.DB,
-		},
+		base: bc,
 	}
 }
 
@@ -780,25 +1088,30 @@ func (tx *Tx) WithPrepare(ctx context.Context, query string) *DBR {
 		l = l.With(log.String("tx_prepare_sql_id", id), log.String("query", query))
 	}
 
-	stmt, err := tx.DB.PrepareContext(ctx, query)
+	stmt, err := tx.hooks.prepare(ctx, tx.DB, &QueryHookEvent{ID: id, Kind: "Prepare", Source: "raw", Query: query})
+	bc := tx.newBuilderCommon(id, l, stmtWrapper{stmt: stmt, inTx: true})
+	bc.ärgErr = err
 	a := &DBR{
-		base: builderCommon{
-			id:     id,
-			ärgErr: err,
-			Log:    l,
-			db:     stmtWrapper{stmt: stmt},
-		},
+		base:       bc,
 		isPrepared: true,
 	}
 	return a
 }
 
 // Commit finishes the transaction. It logs the time taken, if a logger has been
-// set with Info logging enabled.
+// set with Info logging enabled. If the tables of MaterializeCTE cannot be
+// dropped, the transaction does not get committed and must be rolled back.
 func (tx *Tx) Commit() error {
 	if tx.Log != nil && tx.Log.IsDebug() {
 		defer tx.Log.Debug("Commit", log.Duration("duration", now().Sub(tx.start)))
 	}
+	defer tx.txStmts.close()
+	if err := tx.dropTempTables(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := tx.resetLockWait(tx.DB); err != nil {
+		return errors.WithStack(err)
+	}
 	return tx.DB.Commit()
 }
 
@@ -808,7 +1121,15 @@ func (tx *Tx) Rollback() error {
 	if tx.Log != nil && tx.Log.IsDebug() {
 		defer tx.Log.Debug("Rollback", log.Duration("duration", now().Sub(tx.start)))
 	}
-	return tx.DB.Rollback()
+	defer tx.txStmts.close()
+	dropErr := tx.dropTempTables()
+	if err := tx.resetLockWait(tx.DB); err != nil && dropErr == nil {
+		dropErr = err
+	}
+	if err := tx.DB.Rollback(); err != nil {
+		return err
+	}
+	return errors.WithStack(dropErr)
 }
 
 // WithQueryBuilder creates a new DBR for handling the arguments with the
@@ -816,14 +1137,11 @@ func (tx *Tx) Rollback() error {
 // errors of the QueryBuilder will be forwarded to the DBR type.
 func (tx *Tx) WithQueryBuilder(qb QueryBuilder) *DBR {
 	sqlStr, _, err := qb.ToSQL()
+	bc := tx.newBuilderCommon(tx.makeUniqueID(), tx.Log, tx.DB)
+	bc.cachedSQL = map[string]string{"": sqlStr}
+	bc.ärgErr = errors.WithStack(err)
 	a := &DBR{
-		base: builderCommon{
-			cachedSQL: map[string]string{"": sqlStr},
-			Log:       tx.Log,
-			id:        tx.makeUniqueID(),
-			db:        tx.DB,
-			ärgErr:    errors.WithStack(err),
-		},
+		base: bc,
 	}
 	return a
 }
@@ -0,0 +1,300 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// adaptiveMaxQueries limits the number of tracked SQL strings. If reached, the
+// SQL strings without a prepared statement get removed.
+const adaptiveMaxQueries = 4096
+
+// AdaptivePrepare configures WithAdaptivePrepare.
+type AdaptivePrepare struct {
+	// Threshold defines the number of executions of the same statement digest
+	// within Window. If exceeded, the statement gets prepared on the server.
+	// Defaults to 10.
+	Threshold int
+	// Window defaults to one minute.
+	Window time.Duration
+	// MaxStatements limits the number of prepared statements to stay below
+	// the server variable max_prepared_stmt_count. If reached, further
+	// statements get interpolated. Defaults to 256.
+	MaxStatements int
+}
+
+// AdaptivePrepareStats reports the state of a statement digest.
+type AdaptivePrepareStats struct {
+	Digest string
+	// Executions counts the executions in the current window.
+	Executions int
+	// Prepared reports that the digest has exceeded the threshold. Its SQL
+	// strings get executed as prepared statements.
+	Prepared bool
+}
+
+type adaptiveDigest struct {
+	digest      string
+	windowStart time.Time
+	count       int
+	hot         bool
+}
+
+type adaptiveQuery struct {
+	digest *adaptiveDigest
+	stmt   *sql.Stmt
+	// noPrepare gets set if the server failed to prepare the statement.
+	noPrepare bool
+}
+
+type adaptivePrepare struct {
+	AdaptivePrepare
+	db *sql.DB
+
+	mu sync.Mutex
+	// digests key is the digest of the normalized query, see QueryDigest.
+	digests map[string]*adaptiveDigest
+	// queries key is the SQL string with place holders.
+	queries  map[string]*adaptiveQuery
+	prepared int
+}
+
+// WithAdaptivePrepare chooses per statement between interpolation and a server
+// side prepared statement. Statements get tracked by their digest, see
+// QueryDigest. One-off statements get executed interpolated, which requires
+// one round trip instead of the three of the driver (prepare, execute, close).
+// If the executions of a digest exceed the threshold within the window, its
+// statements get prepared once and reused by the ConnPool and its
+// transactions. Prepared statements stay open until the ConnPool gets closed.
+// Connections, connection groups and DBRs created by Prepare only interpolate.
+// Statements without arguments and DBR.QueryRowContext are not affected.
+//		dbc, err := dml.NewConnPool(dml.WithDSN(dsn), dml.WithAdaptivePrepare(dml.AdaptivePrepare{
+//			Threshold: 30, // executions per minute
+//		}))
+func WithAdaptivePrepare(ap AdaptivePrepare) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 2, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			if ap.Threshold < 0 || ap.Window < 0 || ap.MaxStatements < 0 {
+				return errors.NotValid.Newf("[dml] WithAdaptivePrepare requires positive values, got %#v", ap)
+			}
+			if ap.Threshold == 0 {
+				ap.Threshold = 10
+			}
+			if ap.Window == 0 {
+				ap.Window = time.Minute
+			}
+			if ap.MaxStatements == 0 {
+				ap.MaxStatements = 256
+			}
+			c.adaptive = &adaptivePrepare{
+				AdaptivePrepare: ap,
+				db:              c.DB,
+				digests:         make(map[string]*adaptiveDigest),
+				queries:         make(map[string]*adaptiveQuery),
+			}
+			return nil
+		},
+	}
+}
+
+// AdaptivePrepareStats returns the state of all tracked digests. Returns nil
+// if WithAdaptivePrepare has not been applied.
+func (c *ConnPool) AdaptivePrepareStats() []AdaptivePrepareStats {
+	ap := c.adaptive
+	if ap == nil {
+		return nil
+	}
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ret := make([]AdaptivePrepareStats, 0, len(ap.digests))
+	for _, d := range ap.digests {
+		ret = append(ret, AdaptivePrepareStats{Digest: d.digest, Executions: d.count, Prepared: d.hot})
+	}
+	return ret
+}
+
+// track counts the execution of the SQL string and returns its prepared
+// statement, if the digest is hot.
+func (ap *adaptivePrepare) track(ctx context.Context, sqlStr string) (*sql.Stmt, error) {
+	ap.mu.Lock()
+	q, ok := ap.queries[sqlStr]
+	if !ok {
+		if len(ap.queries) >= adaptiveMaxQueries {
+			ap.evict()
+		}
+		d := QueryDigest(sqlStr)
+		dg, ok := ap.digests[d]
+		if !ok {
+			dg = &adaptiveDigest{digest: d}
+			ap.digests[d] = dg
+		}
+		q = &adaptiveQuery{digest: dg}
+		ap.queries[sqlStr] = q
+	}
+
+	dg := q.digest
+	if now := time.Now(); now.Sub(dg.windowStart) >= ap.Window {
+		dg.windowStart = now
+		dg.count = 0
+	}
+	dg.count++
+	if dg.count > ap.Threshold {
+		dg.hot = true
+	}
+	if q.stmt != nil || q.noPrepare || !dg.hot || ap.prepared >= ap.MaxStatements {
+		stmt := q.stmt
+		ap.mu.Unlock()
+		return stmt, nil
+	}
+	ap.prepared++ // reserve the slot while preparing without the lock
+	ap.mu.Unlock()
+
+	stmt, err := ap.db.PrepareContext(ctx, sqlStr)
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		ap.prepared--
+		return nil, errors.Wrapf(err, "[dml] WithAdaptivePrepare failed to prepare query %q", sqlStr)
+	case err != nil: // not every statement can be prepared, fall back to interpolation
+		ap.prepared--
+		q.noPrepare = true
+		return nil, nil
+	case q.stmt != nil: // another goroutine has been faster
+		ap.prepared--
+		return q.stmt, errors.WithStack(stmt.Close())
+	}
+	q.stmt = stmt
+	return stmt, nil
+}
+
+// evict removes the SQL strings without prepared statement and the digests
+// not referenced anymore.
+func (ap *adaptivePrepare) evict() {
+	digests := make(map[string]*adaptiveDigest, len(ap.digests))
+	for k, q := range ap.queries {
+		if q.stmt == nil {
+			delete(ap.queries, k)
+			continue
+		}
+		digests[q.digest.digest] = q.digest
+	}
+	ap.digests = digests
+}
+
+func (ap *adaptivePrepare) close() (err error) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	for _, q := range ap.queries {
+		if q.stmt != nil {
+			if cErr := q.stmt.Close(); err == nil && cErr != nil {
+				err = errors.WithStack(cErr)
+			}
+		}
+	}
+	ap.queries = make(map[string]*adaptiveQuery)
+	ap.digests = make(map[string]*adaptiveDigest)
+	ap.prepared = 0
+	return err
+}
+
+// adaptiveStmt returns either a prepared statement or the interpolated SQL
+// string. If both are empty, the statement must be executed unmodified.
+// Statements with Select.LockWaitTimeout get interpolated.
+func (bc *builderCommon) adaptiveStmt(ctx context.Context, sqlStr string, args []interface{}) (stmt *sql.Stmt, interpolated string, err error) {
+	if bc.adaptive == nil || sqlStr == "" || len(args) == 0 {
+		return nil, "", nil
+	}
+	if bc.stmtLockWait == 0 {
+		switch db := bc.db.(type) {
+		case *sql.DB:
+			if db == bc.adaptive.db {
+				stmt, err = bc.adaptive.track(ctx, sqlStr)
+			}
+		case *sql.Tx:
+			if stmt, err = bc.adaptive.track(ctx, sqlStr); stmt != nil {
+				stmt = db.StmtContext(ctx, stmt)
+			}
+		}
+		if stmt != nil || err != nil {
+			return stmt, "", errors.WithStack(err)
+		}
+	}
+
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	if err := writeInterpolate(buf, bc.sqlDialect(), sqlStr, args); err != nil {
+		return nil, "", nil // e.g. unsupported argument types get sent to the driver
+	}
+	return nil, buf.String(), nil
+}
+
+// execContext executes the statement, prepared if WithStmtCache or adaptive if
+// WithAdaptivePrepare has been applied.
+func (bc *builderCommon) execContext(ctx context.Context, sqlStr string, args []interface{}) (res sql.Result, err error) {
+	if ok, err := bc.withCachedStmt(ctx, sqlStr, args, func(stmt *sql.Stmt) (err error) {
+		res, err = stmt.ExecContext(ctx, args...)
+		return err
+	}); ok {
+		return res, err
+	}
+	stmt, interpolated, err := bc.adaptiveStmt(ctx, sqlStr, args)
+	switch {
+	case err != nil:
+		return nil, errors.WithStack(err)
+	case stmt != nil:
+		return stmt.ExecContext(ctx, args...)
+	case interpolated != "":
+		sqlStr, args = interpolated, nil
+	}
+	sqlStr, db, err := bc.withDeadlineLockWait(ctx, bc.withSQLComment(ctx, sqlStr))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return db.ExecContext(ctx, sqlStr, args...)
+}
+
+// queryContext same as execContext but for queries.
+func (bc *builderCommon) queryContext(ctx context.Context, sqlStr string, args []interface{}) (rows *sql.Rows, err error) {
+	if ok, err := bc.withCachedStmt(ctx, sqlStr, args, func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.QueryContext(ctx, args...)
+		return err
+	}); ok {
+		return rows, err
+	}
+	stmt, interpolated, err := bc.adaptiveStmt(ctx, sqlStr, args)
+	switch {
+	case err != nil:
+		return nil, errors.WithStack(err)
+	case stmt != nil:
+		return stmt.QueryContext(ctx, args...)
+	case interpolated != "":
+		sqlStr, args = interpolated, nil
+	}
+	sqlStr, db, err := bc.withDeadlineLockWait(ctx, bc.withSQLComment(ctx, sqlStr))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return db.QueryContext(ctx, sqlStr, args...)
+}
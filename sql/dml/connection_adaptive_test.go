@@ -0,0 +1,85 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWithAdaptivePrepare(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithAdaptivePrepare(dml.AdaptivePrepare{Threshold: 2}))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	sel := dbc.SelectFrom("product").AddColumns("id").Where(dml.Column("id").PlaceHolder()).WithDBR()
+
+	t.Run("one-off statements get interpolated", func(t *testing.T) {
+		for _, id := range []string{"1", "2"} {
+			dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id` FROM `product` WHERE (`id` = " + id + ")")).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+		}
+		for _, id := range []int64{1, 2} {
+			ids, err := sel.LoadInt64s(context.TODO(), nil, id)
+			assert.NoError(t, err)
+			assert.Exactly(t, []int64{id}, ids)
+		}
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `sku`='a' WHERE (`id` = 1)")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dbc.Update("product").AddClauses(dml.Column("sku").PlaceHolder()).
+			Where(dml.Column("id").PlaceHolder()).WithDBR().ExecContext(context.TODO(), "a", 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("frequent statements get prepared once", func(t *testing.T) {
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("SELECT `id` FROM `product` WHERE (`id` = ?)"))
+		prep.WillBeClosed()
+		prep.ExpectQuery().WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		prep.ExpectQuery().WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+		for _, id := range []int64{3, 4} {
+			ids, err := sel.LoadInt64s(context.TODO(), nil, id)
+			assert.NoError(t, err)
+			assert.Exactly(t, []int64{id}, ids)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats := dbc.AdaptivePrepareStats()
+		assert.Len(t, stats, 2)
+		for _, s := range stats {
+			switch s.Executions {
+			case 4:
+				assert.True(t, s.Prepared, "SELECT should be prepared")
+				assert.Exactly(t, dml.QueryDigest("SELECT `id` FROM `product` WHERE (`id` = 42)"), s.Digest)
+			case 1:
+				assert.False(t, s.Prepared, "UPDATE should not be prepared")
+			default:
+				t.Errorf("unexpected stats %#v", s)
+			}
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		err := dbc.Options(dml.WithAdaptivePrepare(dml.AdaptivePrepare{Threshold: -1}))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
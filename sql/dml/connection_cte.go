@@ -0,0 +1,148 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+func validateCTE(cte WithCTE) error {
+	if cte.Name == "" {
+		return errors.Empty.Newf("[dml] Tx: Name of the common table expression cannot be empty")
+	}
+	if cte.Select == nil && cte.Union == nil {
+		return errors.Empty.Newf("[dml] Tx: Common table expression %q requires a Select or a Union", cte.Name)
+	}
+	return nil
+}
+
+// RegisterCTE registers common table expressions once for the transaction.
+// All Select, Update and Delete statements created afterwards via the
+// transaction get the WITH clause prepended, so complex reporting transactions
+// can refer to the expressions like to a table. Arguments of the expressions
+// must be passed before the arguments of the statement. Statements used as the
+// top level statement of a With must not be created after the registration.
+// Supported in: MySQL >=8.0.1 and MariaDb >=10.2. MariaDB does not accept a
+// WITH clause before UPDATE and DELETE, hence on MariaDB only Select
+// statements can refer to the registered expressions. Use MaterializeCTE
+// for UPDATE and DELETE statements on MariaDB.
+//		err := tx.RegisterCTE(dml.WithCTE{
+//			Name:   "paid_orders",
+//			Select: dml.NewSelect().Star().From("sales_order").Where(dml.Column("state").Str("complete")),
+//		})
+//		// WITH `paid_orders` AS (SELECT * FROM `sales_order` WHERE ...)
+//		// SELECT SUM(`grand_total`) FROM `paid_orders`
+//		sum, _, err := tx.SelectFrom("paid_orders").AddColumnsConditions(dml.Expr("SUM(`grand_total`)")).
+//			WithDBR().LoadNullFloat64(ctx)
+func (tx *Tx) RegisterCTE(expressions ...WithCTE) error {
+	for _, cte := range expressions {
+		if err := validateCTE(cte); err != nil {
+			return errors.WithStack(err)
+		}
+		if tx.hasCTE(cte.Name) {
+			return errors.AlreadyExists.Newf("[dml] Tx.RegisterCTE: Common table expression %q already exists", cte.Name)
+		}
+		tx.txCTEs = append(tx.txCTEs, cte)
+	}
+	return nil
+}
+
+// MaterializeCTE creates a temporary table with the result of the common table
+// expression. Useful if the expression gets referenced by several statements
+// of the transaction and should be calculated only once. Statements refer to
+// the name of the expression like to a table. The temporary tables get dropped
+// on Commit and Rollback because they would otherwise live as long as the
+// connection. Arguments args get passed to the underlying DBR.
+func (tx *Tx) MaterializeCTE(ctx context.Context, cte WithCTE, args ...interface{}) (err error) {
+	if err := validateCTE(cte); err != nil {
+		return errors.WithStack(err)
+	}
+	if tx.hasCTE(cte.Name) {
+		return errors.AlreadyExists.Newf("[dml] Tx.MaterializeCTE: Common table expression %q already exists", cte.Name)
+	}
+	if tx.Log != nil && tx.Log.IsDebug() {
+		defer log.WhenDone(tx.Log).Debug("MaterializeCTE", log.String("name", cte.Name), log.Err(err))
+	}
+	qb := QuerySQLFn(func() (string, []interface{}, error) {
+		buf := bufferpool.Get()
+		defer bufferpool.Put(buf)
+		buf.WriteString("CREATE TEMPORARY TABLE ")
+		Quoter.quote(buf, cte.Name)
+		// the column names of the expression are only supported by a CTE
+		buf.WriteString(" AS WITH ")
+		if _, err := writeCTEs(buf, tx.sqlDialect(), []WithCTE{cte}, "", nil); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+		buf.WriteString("SELECT * FROM ")
+		Quoter.quote(buf, cte.Name)
+		return buf.String(), nil, nil
+	})
+	if _, err = tx.WithQueryBuilder(qb).ExecContext(ctx, args...); err != nil {
+		return errors.Wrapf(err, "[dml] Tx.MaterializeCTE failed to create %q", cte.Name)
+	}
+	tx.tempTables = append(tx.tempTables, cte.Name)
+	return nil
+}
+
+func (tx *Tx) hasCTE(name string) bool {
+	for _, cte := range tx.txCTEs {
+		if cte.Name == name {
+			return true
+		}
+	}
+	for _, tt := range tx.tempTables {
+		if tt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dropTempTables drops the tables created by MaterializeCTE.
+func (tx *Tx) dropTempTables() error {
+	if len(tx.tempTables) == 0 {
+		return nil
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString("DROP TEMPORARY TABLE IF EXISTS ")
+	for i, tt := range tx.tempTables {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		Quoter.quote(buf, tt)
+	}
+	if _, err := tx.DB.Exec(buf.String()); err != nil {
+		return errors.Wrapf(err, "[dml] Tx failed to drop the materialized common table expressions %v", tx.tempTables)
+	}
+	tx.tempTables = nil
+	return nil
+}
+
+// writeTxCTEs prepends the common table expressions registered in the
+// transaction.
+func (bc *builderCommon) writeTxCTEs(w *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
+	if len(bc.txCTEs) == 0 {
+		return placeHolders, nil
+	}
+	w.WriteString("WITH ")
+	placeHolders, err := writeCTEs(w, d, bc.txCTEs, bc.cacheKey, placeHolders)
+	return placeHolders, errors.WithStack(err)
+}
@@ -0,0 +1,128 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestTx_RegisterCTE(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	paidOrders := dml.WithCTE{
+		Name:   "paid_orders",
+		Select: dml.NewSelect().Star().From("sales_order").Where(dml.Column("state").PlaceHolder()),
+	}
+	const withPaidOrders = "WITH `paid_orders` AS (SELECT * FROM `sales_order` WHERE (`state` = ?))\n"
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(withPaidOrders+"SELECT SUM(grand_total) FROM `paid_orders` WHERE (`store_id` = ?)")).
+		WithArgs("complete", int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(42.5))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(withPaidOrders + "UPDATE `customer_entity` SET `is_paying`=1 WHERE (`entity_id` IN (SELECT `customer_id` FROM `paid_orders`))")).
+		WithArgs("complete").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(withPaidOrders + "DELETE FROM `quote` WHERE (`entity_id` IN (SELECT `quote_id` FROM `paid_orders`))")).
+		WithArgs("complete").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	dbMock.ExpectCommit()
+
+	err := dbc.Transaction(context.TODO(), nil, func(tx *dml.Tx) error {
+		beforeRegister := tx.SelectFrom("sales_order").Star()
+		if err := tx.RegisterCTE(paidOrders); err != nil {
+			return err
+		}
+		assert.ErrorIsKind(t, errors.AlreadyExists, tx.RegisterCTE(paidOrders))
+		assert.ErrorIsKind(t, errors.Empty, tx.RegisterCTE(dml.WithCTE{Name: "empty"}))
+		compareToSQL(t, beforeRegister, errors.NoKind, "SELECT * FROM `sales_order`", "")
+
+		sum, _, err := tx.SelectFrom("paid_orders").AddColumnsConditions(dml.Expr("SUM(grand_total)")).
+			Where(dml.Column("store_id").PlaceHolder()).
+			WithDBR().LoadNullFloat64(context.TODO(), "complete", 2)
+		if err != nil {
+			return err
+		}
+		assert.Exactly(t, 42.5, sum.Float64)
+
+		if _, err := tx.Update("customer_entity").AddClauses(dml.Column("is_paying").Int(1)).
+			Where(dml.Column("entity_id").In().Sub(dml.NewSelect("customer_id").From("paid_orders"))).
+			WithDBR().ExecContext(context.TODO(), "complete"); err != nil {
+			return err
+		}
+		_, err = tx.DeleteFrom("quote").
+			Where(dml.Column("entity_id").In().Sub(dml.NewSelect("quote_id").From("paid_orders"))).
+			WithDBR().ExecContext(context.TODO(), "complete")
+		return err
+	})
+	assert.NoError(t, err)
+}
+
+func TestTx_MaterializeCTE(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	revenue := dml.WithCTE{
+		Name:    "revenue",
+		Columns: []string{"store_id", "total"},
+		Select: dml.NewSelect("store_id").AddColumnsConditions(dml.Expr("SUM(grand_total)")).
+			From("sales_order").Where(dml.Column("created_at").GreaterOrEqual().PlaceHolder()).GroupBy("store_id"),
+	}
+
+	t.Run("commit drops table", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE TEMPORARY TABLE `revenue` AS WITH `revenue` (`store_id`,`total`) AS (SELECT `store_id`, SUM(grand_total) FROM `sales_order` WHERE (`created_at` >= ?) GROUP BY `store_id`)\nSELECT * FROM `revenue`")).
+			WithArgs("2020-01-01").
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DROP TEMPORARY TABLE IF EXISTS `revenue`")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectCommit()
+
+		err := dbc.Transaction(context.TODO(), nil, func(tx *dml.Tx) error {
+			if err := tx.MaterializeCTE(context.TODO(), revenue, "2020-01-01"); err != nil {
+				return err
+			}
+			assert.ErrorIsKind(t, errors.AlreadyExists, tx.RegisterCTE(revenue))
+			assert.ErrorIsKind(t, errors.AlreadyExists, tx.MaterializeCTE(context.TODO(), revenue))
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("rollback drops table", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("CREATE TEMPORARY TABLE `revenue`")).
+			WithArgs("2020-01-01").
+			WillReturnResult(sqlmock.NewResult(0, 4))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DROP TEMPORARY TABLE IF EXISTS `revenue`")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectRollback()
+
+		err := dbc.Transaction(context.TODO(), nil, func(tx *dml.Tx) error {
+			if err := tx.MaterializeCTE(context.TODO(), revenue, "2020-01-01"); err != nil {
+				return err
+			}
+			return errors.Aborted.Newf("Report canceled")
+		})
+		assert.ErrorIsKind(t, errors.Aborted, err)
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/go-sql-driver/mysql"
+)
+
+// WithConnGroup adds a named connection group, e.g. "reporting" or
+// "search-indexer", which points to dedicated replicas. A Select statement
+// created from the ConnPool can be routed to the group via Select.UseGroup so
+// that heavy analytical queries do not hit the serving pool. The connection
+// pool of the group gets closed when the ConnPool gets closed.
+func WithConnGroup(name string, db *sql.DB) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			return c.addConnGroup(name, db)
+		},
+	}
+}
+
+// WithConnGroupDSN same as WithConnGroup but opens a new connection pool with
+// the data source name.
+func WithConnGroupDSN(name, dsn string) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			if !strings.Contains(dsn, "parseTime") {
+				return errors.NotImplemented.Newf("[dml] The DSN for go-sql-driver/mysql of connection group %q must contain the parameters `?parseTime=true[&loc=YourTimeZone]`", name)
+			}
+			if _, err := mysql.ParseDSN(dsn); err != nil {
+				return errors.WithStack(err)
+			}
+			return c.addConnGroup(name, sql.OpenDB(dsnConnector{dsn: dsn, driver: mysql.MySQLDriver{}}))
+		},
+	}
+}
+
+func (c *ConnPool) addConnGroup(name string, db *sql.DB) error {
+	if name == "" || db == nil {
+		return errors.Empty.Newf("[dml] Connection group name %q or its DB cannot be empty", name)
+	}
+	if _, ok := c.connGroups[name]; ok {
+		return errors.AlreadyExists.Newf("[dml] Connection group %q already exists", name)
+	}
+	if c.connGroups == nil {
+		c.connGroups = make(map[string]*sql.DB)
+	}
+	c.connGroups[name] = db
+	return nil
+}
+
+// ConnGroup returns the connection pool of a named connection group. See
+// WithConnGroup.
+func (c *ConnPool) ConnGroup(name string) (*sql.DB, bool) {
+	db, ok := c.connGroups[name]
+	return db, ok
+}
+
+func (c *ConnPool) closeConnGroups() (err error) {
+	for name, db := range c.connGroups {
+		if errC := db.Close(); errC != nil && err == nil {
+			err = errors.Wrapf(errC, "[dml] Failed to close connection group %q", name)
+		}
+	}
+	return err
+}
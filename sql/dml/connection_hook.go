@@ -0,0 +1,151 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/corestoreio/log"
+)
+
+// QueryHookEvent describes a statement passed to the functions of a QueryHook.
+type QueryHookEvent struct {
+	// ID of the statement as generated by the unique ID function of
+	// WithLogger. Might be empty for prepared statements of a connection.
+	ID string
+	// Kind is either "Query", "QueryRow", "Exec" or "Prepare".
+	Kind string
+	// Source of the statement like "select", "insert", "update", "delete",
+	// "with", "union", "show" or "raw" for raw SQL queries.
+	Source string
+	// Query contains the SQL string with place holders or the interpolated
+	// SQL string.
+	Query   string
+	ArgsLen int
+	// Duration and Err are only set in AfterQuery. A QueryRow defers its
+	// error until Scan, hence Err is always nil.
+	Duration time.Duration
+	Err      error
+}
+
+// QueryHook gets called before and after each statement executed via
+// QueryContext, QueryRowContext, ExecContext or PrepareContext of the ConnPool,
+// Conn, Tx and DBR types. BeforeQuery returns the context which gets passed to
+// the database driver and to AfterQuery. This allows to start an OpenTelemetry
+// span in BeforeQuery and to end it in AfterQuery. An implementation must be
+// safe for concurrent use.
+type QueryHook interface {
+	BeforeQuery(ctx context.Context, ev *QueryHookEvent) context.Context
+	AfterQuery(ctx context.Context, ev *QueryHookEvent)
+}
+
+// QueryHookFuncs implements QueryHook. Nil functions get skipped.
+type QueryHookFuncs struct {
+	Before func(ctx context.Context, ev *QueryHookEvent) context.Context
+	After  func(ctx context.Context, ev *QueryHookEvent)
+}
+
+// BeforeQuery implements QueryHook.
+func (qh QueryHookFuncs) BeforeQuery(ctx context.Context, ev *QueryHookEvent) context.Context {
+	if qh.Before == nil {
+		return ctx
+	}
+	return qh.Before(ctx, ev)
+}
+
+// AfterQuery implements QueryHook.
+func (qh QueryHookFuncs) AfterQuery(ctx context.Context, ev *QueryHookEvent) {
+	if qh.After != nil {
+		qh.After(ctx, ev)
+	}
+}
+
+// WithQueryHooks appends the hooks to the ConnPool. The hooks get inherited by
+// all connections, transactions and statements created by the ConnPool.
+// BeforeQuery gets called in the order of the hooks, AfterQuery in reverse
+// order.
+//		dbc, err := dml.NewConnPool(
+//			dml.WithDSN(dsn),
+//			dml.WithQueryHooks(dml.QueryHookFuncs{
+//				Before: func(ctx context.Context, ev *dml.QueryHookEvent) context.Context {
+//					ctx, _ = tracer.Start(ctx, ev.Kind+" "+ev.Source)
+//					return ctx
+//				},
+//				After: func(ctx context.Context, ev *dml.QueryHookEvent) {
+//					span := trace.SpanFromContext(ctx)
+//					if ev.Err != nil {
+//						span.RecordError(ev.Err)
+//					}
+//					span.End()
+//				},
+//			}),
+//		)
+func WithQueryHooks(hooks ...QueryHook) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 10,
+		fn: func(c *ConnPool) error {
+			c.hooks = append(c.hooks, hooks...)
+			return nil
+		},
+	}
+}
+
+// queryHooks a list of hooks called in before and after a statement.
+type queryHooks []QueryHook
+
+// before calls all BeforeQuery functions and returns the context for the
+// statement and the start time.
+func (qhs queryHooks) before(ctx context.Context, ev *QueryHookEvent) (context.Context, time.Time) {
+	for _, qh := range qhs {
+		ctx = qh.BeforeQuery(ctx, ev)
+	}
+	return ctx, log.Now()
+}
+
+// after calls all AfterQuery functions in reverse order.
+func (qhs queryHooks) after(ctx context.Context, ev *QueryHookEvent, start time.Time, err error) {
+	ev.Duration = log.Now().Sub(start)
+	ev.Err = err
+	for i := len(qhs) - 1; i >= 0; i-- {
+		qhs[i].AfterQuery(ctx, ev)
+	}
+}
+
+// prepare calls the hooks around PrepareContext.
+func (qhs queryHooks) prepare(ctx context.Context, db Preparer, ev *QueryHookEvent) (stmt *sql.Stmt, err error) {
+	if len(qhs) == 0 {
+		return db.PrepareContext(ctx, ev.Query)
+	}
+	ctx, start := qhs.before(ctx, ev)
+	defer func() { qhs.after(ctx, ev, start, err) }()
+	stmt, err = db.PrepareContext(ctx, ev.Query)
+	return
+}
+
+// hookEvent creates a new event for the hooks of the statement.
+func (bc *builderCommon) hookEvent(kind, query string, argsLen int) *QueryHookEvent {
+	if query == "" {
+		query = bc.cachedSQL[bc.cacheKey] // prepared statement
+	}
+	return &QueryHookEvent{
+		ID:      bc.id,
+		Kind:    kind,
+		Source:  sourceName(bc.source),
+		Query:   query,
+		ArgsLen: argsLen,
+	}
+}
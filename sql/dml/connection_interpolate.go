@@ -0,0 +1,122 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"sync/atomic"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/config"
+)
+
+// interpolateToggle gets shared by the ConnPool with all its connections,
+// transactions and statements, so a change applies immediately.
+type interpolateToggle struct {
+	enable uint32
+}
+
+func (it *interpolateToggle) enabled() bool {
+	return it != nil && atomic.LoadUint32(&it.enable) == 1
+}
+
+func (it *interpolateToggle) set(enable bool) {
+	var v uint32
+	if enable {
+		v = 1
+	}
+	atomic.StoreUint32(&it.enable, v)
+}
+
+// WithDefaultInterpolate enables or disables the interpolation of the
+// arguments for all statements of the ConnPool, same as calling
+// DBR.Interpolate on each DBR. The change applies to already created
+// connections, transactions and DBRs and can be made at any time, e.g. when a
+// proxy like ProxySQL cannot handle prepared statements. Prepared statements
+// created by Prepare, DBRs without arguments and DBR.Interpolate are not
+// affected. Safe for concurrent use.
+func (c *ConnPool) WithDefaultInterpolate(enable bool) *ConnPool {
+	if c.interpolate == nil {
+		c.interpolate = new(interpolateToggle)
+	}
+	c.interpolate.set(enable)
+	return c
+}
+
+// DefaultInterpolate reports whether WithDefaultInterpolate has been enabled.
+func (c *ConnPool) DefaultInterpolate() bool {
+	return c.interpolate.enabled()
+}
+
+// ConfigGetter reads a configuration value. Implemented by *config.Service.
+type ConfigGetter interface {
+	Get(p config.Path) *config.Value
+}
+
+// SubscribeDefaultInterpolate applies the boolean value of the configuration
+// path to WithDefaultInterpolate and subscribes to its changes, so fleets can
+// switch between prepared and interpolated execution without a deployment.
+// Set the Path.UseEnvSuffix to allow a different value per environment. A
+// missing value disables the interpolation. Errors while reading a changed
+// value get logged and remove the subscription.
+//		p := config.MustMakePath("sql/dml/interpolate").WithEnvSuffix()
+//		id, err := dbc.SubscribeDefaultInterpolate(cfgSrv, cfgSrv, p)
+func (c *ConnPool) SubscribeDefaultInterpolate(sub config.Subscriber, cg ConfigGetter, p config.Path) (subscriptionID int, err error) {
+	mr := interpolateReceiver{c: c, cg: cg, path: p}
+	if err := mr.apply(); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	// The pubSub of the config.Service publishes the first four levels and the
+	// fully qualified path including an environment suffix, so subscribe to
+	// scope/id/section/group and filter for the field.
+	lvl, err := p.Level(4)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	subscriptionID, err = sub.Subscribe(lvl, mr)
+	return subscriptionID, errors.WithStack(err)
+}
+
+type interpolateReceiver struct {
+	c    *ConnPool
+	cg   ConfigGetter
+	path config.Path
+}
+
+func (ir interpolateReceiver) apply() error {
+	enable, _, err := ir.cg.Get(ir.path).Bool()
+	if err != nil {
+		return errors.Wrapf(err, "[dml] SubscribeDefaultInterpolate failed to read path %q", ir.path.String())
+	}
+	ir.c.WithDefaultInterpolate(enable)
+	if ir.c.Log != nil && ir.c.Log.IsDebug() {
+		ir.c.Log.Debug("SubscribeDefaultInterpolate", log.Stringer("path", ir.path), log.Bool("interpolate", enable))
+	}
+	return nil
+}
+
+// MessageConfig implements config.MessageReceiver.
+func (ir interpolateReceiver) MessageConfig(p config.Path) error {
+	if p.ScopeID != ir.path.ScopeID || !p.EqualRoute(ir.path) {
+		return nil
+	}
+	if err := ir.apply(); err != nil {
+		if ir.c.Log != nil && ir.c.Log.IsInfo() {
+			ir.c.Log.Info("SubscribeDefaultInterpolate", log.Err(err))
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
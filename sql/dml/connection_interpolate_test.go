@@ -0,0 +1,100 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/config/storage"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestConnPool_WithDefaultInterpolate(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	upd := dbc.Update("product").AddClauses(dml.Column("sku").PlaceHolder()).
+		Where(dml.Column("id").PlaceHolder()).WithDBR()
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `sku`=? WHERE (`id` = ?)")).
+		WithArgs("a", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err := upd.ExecContext(context.TODO(), "a", 1)
+	assert.NoError(t, err)
+
+	dbc.WithDefaultInterpolate(true)
+	assert.True(t, dbc.DefaultInterpolate())
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `sku`='b' WHERE (`id` = 2)")).
+		WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = upd.ExecContext(context.TODO(), "b", 2)
+	assert.NoError(t, err)
+
+	sqlStr, _, err := upd.ToSQL()
+	assert.NoError(t, err)
+	assert.Exactly(t, "UPDATE `product` SET `sku`=? WHERE (`id` = ?)", sqlStr, "statement without arguments must not be interpolated")
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `sku`='c' WHERE (`id` = 3)")).
+		WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+	assert.NoError(t, dbc.Transaction(context.TODO(), nil, func(tx *dml.Tx) error {
+		_, err := tx.Update("product").AddClauses(dml.Column("sku").PlaceHolder()).
+			Where(dml.Column("id").PlaceHolder()).WithDBR().ExecContext(context.TODO(), "c", 3)
+		return err
+	}))
+
+	dbc.WithDefaultInterpolate(false)
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `sku`=? WHERE (`id` = ?)")).
+		WithArgs("d", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = upd.ExecContext(context.TODO(), "d", 4)
+	assert.NoError(t, err)
+}
+
+func TestConnPool_SubscribeDefaultInterpolate(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	p := config.MustMakePath("sql/dml/interpolate")
+	cfgStore := storage.NewMap("default/0/sql/dml/interpolate", "1")
+	cfgSrv := config.NewFakeService(cfgStore)
+
+	var receiver config.MessageReceiver
+	cfgSrv.SubscribeFn = func(path string, mr config.MessageReceiver) (int, error) {
+		assert.Exactly(t, "default/0/sql/dml", path)
+		receiver = mr
+		return 3, nil
+	}
+
+	id, err := dbc.SubscribeDefaultInterpolate(cfgSrv, cfgSrv, p)
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, id)
+	assert.True(t, dbc.DefaultInterpolate())
+
+	assert.NoError(t, cfgStore.Set(p, []byte("0")))
+	assert.NoError(t, receiver.MessageConfig(config.MustMakePath("sql/dml/other")))
+	assert.True(t, dbc.DefaultInterpolate(), "other paths must be ignored")
+
+	assert.NoError(t, receiver.MessageConfig(p))
+	assert.False(t, dbc.DefaultInterpolate())
+
+	assert.NoError(t, cfgStore.Set(p, []byte("yes please")))
+	assert.Error(t, receiver.MessageConfig(p))
+	assert.False(t, dbc.DefaultInterpolate())
+}
@@ -0,0 +1,207 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// KillQueryStats contains the counters of the KILL QUERY feature. See
+// WithKillQueryOnCancel.
+type KillQueryStats struct {
+	// Canceled counts the queries whose context has been canceled while the
+	// query was still running.
+	Canceled uint64
+	// Killed counts the successful KILL QUERY statements.
+	Killed uint64
+	// Failed counts the KILL QUERY statements which returned an error.
+	Failed uint64
+}
+
+// killQuery gets shared between the ConnPool and all its Conn types.
+type killQuery struct {
+	// stats must be the first field to guarantee the 64-bit alignment of the
+	// atomic counters on 32-bit platforms.
+	stats KillQueryStats
+	// db is the side connection pool used to send the KILL QUERY statement.
+	db      *sql.DB
+	timeout time.Duration
+	log     log.Logger
+}
+
+// WithKillQueryOnCancel enables sending a `KILL QUERY thread_id` statement via
+// a side connection of the pool when the context of a running query gets
+// canceled. Without it the MySQL server continues executing the query even if
+// the client is not interested anymore in the result. Only queries running on
+// a dedicated connection (type Conn created via ConnPool.Conn) can be tracked
+// because the thread ID of the connection must be known. Argument `timeout`
+// limits the duration of the KILL QUERY statement, defaults to two seconds. The
+// user of the DSN requires the PROCESS privilege or must own the connection.
+func WithKillQueryOnCancel(timeout time.Duration) ConnPoolOption {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return ConnPoolOption{
+		sortOrder: 200, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			if c.DB == nil {
+				return errors.NotValid.Newf("[dml] WithKillQueryOnCancel requires a database connection pool")
+			}
+			c.killQuery = &killQuery{
+				db:      c.DB,
+				timeout: timeout,
+				log:     c.Log,
+			}
+			return nil
+		},
+	}
+}
+
+// KillQueryStats returns a snapshot of the counters of the KILL QUERY feature.
+// Returns empty stats if the option WithKillQueryOnCancel has not been applied.
+func (c *ConnPool) KillQueryStats() KillQueryStats {
+	if c.killQuery == nil {
+		return KillQueryStats{}
+	}
+	return KillQueryStats{
+		Canceled: atomic.LoadUint64(&c.killQuery.stats.Canceled),
+		Killed:   atomic.LoadUint64(&c.killQuery.stats.Killed),
+		Failed:   atomic.LoadUint64(&c.killQuery.stats.Failed),
+	}
+}
+
+func (kq *killQuery) kill(threadID uint64) {
+	atomic.AddUint64(&kq.stats.Canceled, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), kq.timeout)
+	defer cancel()
+
+	_, err := kq.db.ExecContext(ctx, "KILL QUERY "+strconv.FormatUint(threadID, 10))
+	if err != nil {
+		atomic.AddUint64(&kq.stats.Failed, 1)
+	} else {
+		atomic.AddUint64(&kq.stats.Killed, 1)
+	}
+	if kq.log != nil && kq.log.IsDebug() {
+		kq.log.Debug("KillQuery", log.Uint64("thread_id", threadID), log.Err(err))
+	}
+}
+
+// killQueryConn wraps a dedicated connection and checks the context of each
+// query. The embedded PrepareContext does not get checked.
+type killQueryConn struct {
+	*sql.Conn
+	kq       *killQuery
+	threadID uint64
+
+	// watchStop and watchDone belong to the goroutine which watches the
+	// context of the last returned rows.
+	watchMu   sync.Mutex
+	watchStop chan struct{}
+	watchDone chan struct{}
+}
+
+func newKillQueryConn(ctx context.Context, kq *killQuery, dbc *sql.Conn) (*killQueryConn, error) {
+	var threadID uint64
+	if err := dbc.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&threadID); err != nil {
+		return nil, errors.Wrapf(err, "[dml] Failed to query CONNECTION_ID")
+	}
+	if threadID == 0 {
+		return nil, errors.NotValid.Newf("[dml] CONNECTION_ID returned an invalid thread ID")
+	}
+	return &killQueryConn{
+		Conn:     dbc,
+		kq:       kq,
+		threadID: threadID,
+	}, nil
+}
+
+// killIfCanceled sends the KILL QUERY if the context has been canceled. The
+// driver returns immediately after a cancellation while the server still
+// executes the query. Killing synchronously after the query has returned
+// guarantees that the next query on the same connection cannot be hit by an
+// outdated KILL QUERY. A KILL QUERY for an idle connection has no effect.
+func (k *killQueryConn) killIfCanceled(ctx context.Context, err error) {
+	if err != nil && ctx.Err() != nil {
+		k.kq.kill(k.threadID)
+	}
+}
+
+// watchRows sends the KILL QUERY if the context gets canceled while the rows
+// get iterated. The server might still send rows after the driver has given
+// up. The watcher runs until the next statement on the connection or until
+// the connection gets closed.
+func (k *killQueryConn) watchRows(ctx context.Context) {
+	if ctx.Done() == nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	k.watchMu.Lock()
+	k.watchStop, k.watchDone = stop, done
+	k.watchMu.Unlock()
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			k.kq.kill(k.threadID)
+		case <-stop:
+		}
+	}()
+}
+
+// stopWatch stops the watcher of the previous rows and waits until a running
+// KILL QUERY has finished, so that it cannot hit the next statement.
+func (k *killQueryConn) stopWatch() {
+	k.watchMu.Lock()
+	stop, done := k.watchStop, k.watchDone
+	k.watchStop, k.watchDone = nil, nil
+	k.watchMu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (k *killQueryConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	k.stopWatch()
+	rows, err := k.Conn.QueryContext(ctx, query, args...)
+	k.killIfCanceled(ctx, err)
+	if err == nil {
+		k.watchRows(ctx)
+	}
+	return rows, err
+}
+
+func (k *killQueryConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	k.stopWatch()
+	res, err := k.Conn.ExecContext(ctx, query, args...)
+	k.killIfCanceled(ctx, err)
+	return res, err
+}
+
+// QueryRowContext defers errors until Scan, so only the context gets checked.
+func (k *killQueryConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	k.stopWatch()
+	row := k.Conn.QueryRowContext(ctx, query, args...)
+	k.killIfCanceled(ctx, ctx.Err())
+	return row
+}
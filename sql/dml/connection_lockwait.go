@@ -0,0 +1,354 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/errors"
+)
+
+// DefaultLockWaitTimeout defines the default value of the server variable
+// innodb_lock_wait_timeout.
+const DefaultLockWaitTimeout = 50 * time.Second
+
+// WithDeadlineLockWaitTimeout propagates the deadline of a context into the
+// lock wait timeout of each statement. If the remaining time of the context is
+// shorter than the innodb_lock_wait_timeout of the server, a transaction
+// waiting for a row lock fails fast instead of outliving its request. The
+// remaining time gets rounded down to full seconds, with a minimum of one
+// second. The argument serverTimeout declares the innodb_lock_wait_timeout of
+// the server; zero applies DefaultLockWaitTimeout. Prepared statements,
+// statements already containing a SET clause and contexts without deadline are
+// not affected. The detected syntax gets also used by Select.LockWaitTimeout.
+//
+// The server version gets queried to choose the syntax. MariaDB >= 10.1.2
+// receives a SET STATEMENT clause, which changes the variable for the duration
+// of the statement only.
+//		SET STATEMENT innodb_lock_wait_timeout=3 FOR UPDATE `stock` SET ...
+// MySQL does not support a per statement lock wait timeout, so the session
+// variable gets changed before the statement and restored to serverTimeout by
+// the next statement without deadline, by Tx.Commit, Tx.Rollback and
+// Conn.Close. A session exists only within a Tx or a Conn, statements of the
+// ConnPool run with the timeout of the server.
+//		SET SESSION innodb_lock_wait_timeout=3
+func WithDeadlineLockWaitTimeout(ctx context.Context, serverTimeout time.Duration) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			if serverTimeout < 0 {
+				return errors.NotValid.Newf("[dml] WithDeadlineLockWaitTimeout requires a positive duration, got %s", serverTimeout)
+			}
+			if serverTimeout == 0 {
+				serverTimeout = DefaultLockWaitTimeout
+			}
+			var version string
+			if err := c.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+				return errors.Wrapf(err, "[dml] WithDeadlineLockWaitTimeout failed to query the version")
+			}
+			c.lockWaitSyntax = lockWaitSyntaxOf(version)
+			c.lockWait = serverTimeout
+			return nil
+		},
+	}
+}
+
+const (
+	lockWaitSetStatement uint8 = iota + 1
+	lockWaitSession
+)
+
+// lockWaitSyntaxOf returns the syntax to set the lock wait timeout of a
+// statement for the version string of the server. MariaDB >= 10.1.2 supports
+// SET STATEMENT.
+func lockWaitSyntaxOf(version string) uint8 {
+	if isMariaDB, v := parseServerVersion(version); isMariaDB && (v[0] > 10 || (v[0] == 10 && (v[1] > 1 || (v[1] == 1 && v[2] >= 2)))) {
+		return lockWaitSetStatement
+	}
+	return lockWaitSession
+}
+
+// lockWaitDetector detects the syntax of the server once, if
+// Select.LockWaitTimeout gets used without the option
+// WithDeadlineLockWaitTimeout.
+type lockWaitDetector struct {
+	mu     sync.Mutex
+	syntax uint8
+}
+
+// detect queries the version of the server, if the syntax has not yet been
+// detected. A nil detector queries the version on each call.
+func (d *lockWaitDetector) detect(ctx context.Context, db QueryExecPreparer) (uint8, error) {
+	if d != nil {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.syntax != 0 {
+			return d.syntax, nil
+		}
+	}
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return 0, errors.Wrapf(err, "[dml] Select.LockWaitTimeout failed to query the version")
+	}
+	syntax := lockWaitSyntaxOf(version)
+	if d != nil {
+		d.syntax = syntax
+	}
+	return syntax, nil
+}
+
+// lockWaitState tracks the innodb_lock_wait_timeout of the session of a Tx or a
+// Conn on servers without the SET STATEMENT syntax. A Tx started by a Conn
+// shares the state of the Conn.
+type lockWaitState struct {
+	// current contains the seconds of the session variable, zero if the
+	// session uses the global value of the server.
+	current int64
+	// server contains the seconds to restore, zero restores the global value.
+	server int64
+}
+
+// newLockWaitState returns the state of a new session. A zero serverTimeout
+// restores the global value of the server.
+func newLockWaitState(serverTimeout time.Duration) *lockWaitState {
+	secs := int64(serverTimeout / time.Second)
+	return &lockWaitState{current: secs, server: secs}
+}
+
+// lockWaitSessionSQL returns the statement to set the session variable to
+// secs, zero sets the global value of the server.
+func lockWaitSessionSQL(secs int64) string {
+	if secs == 0 {
+		return "SET SESSION innodb_lock_wait_timeout=DEFAULT"
+	}
+	return "SET SESSION innodb_lock_wait_timeout=" + strconv.FormatInt(secs, 10)
+}
+
+// set changes the session variable if it differs from secs.
+func (s *lockWaitState) set(ctx context.Context, db QueryExecPreparer, secs int64) error {
+	if s == nil || s.current == secs {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, lockWaitSessionSQL(secs)); err != nil {
+		return errors.Wrapf(err, "[dml] Failed to set the innodb_lock_wait_timeout to %d", secs)
+	}
+	s.current = secs
+	return nil
+}
+
+// lockWaitConn runs one statement of a ConnPool on a dedicated connection,
+// whose session variable innodb_lock_wait_timeout has been changed. See
+// Select.LockWaitTimeout.
+type lockWaitConn struct {
+	conn *sql.Conn
+}
+
+// newLockWaitConn acquires a connection from the pool of bc and sets the
+// session variable to secs.
+func (bc *builderCommon) newLockWaitConn(ctx context.Context, secs int64) (*lockWaitConn, error) {
+	type conner interface {
+		Conn(ctx context.Context) (*sql.Conn, error)
+	}
+	db, ok := bc.db.(conner)
+	if !ok {
+		return nil, errors.NotSupported.Newf("[dml] Select.LockWaitTimeout requires a ConnPool, a Conn or a Tx but got %T", bc.db)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lc := &lockWaitConn{conn: conn}
+	if _, err := conn.ExecContext(ctx, lockWaitSessionSQL(secs)); err != nil {
+		return nil, errors.Wrapf(lc.discard(err), "[dml] Failed to set the innodb_lock_wait_timeout to %d", secs)
+	}
+	return lc, nil
+}
+
+// discard closes the connection instead of returning it into the pool,
+// because the session variable might still be changed. It blocks until the
+// rows of the connection have been closed and returns err.
+func (lc *lockWaitConn) discard(err error) error {
+	_ = lc.conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	return err
+}
+
+// PrepareContext returns an error because a prepared statement outlives the
+// connection.
+func (lc *lockWaitConn) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.NotSupported.Newf("[dml] Select.LockWaitTimeout does not support prepared statements")
+}
+
+// ExecContext executes the statement, restores the session variable and
+// returns the connection into the pool.
+func (lc *lockWaitConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	res, err := lc.conn.ExecContext(ctx, query, args...)
+	if _, errR := lc.conn.ExecContext(context.Background(), lockWaitSessionSQL(0)); errR != nil {
+		if err == nil {
+			err = errors.Wrapf(errR, "[dml] Failed to restore the innodb_lock_wait_timeout")
+		}
+		return res, lc.discard(err)
+	}
+	if errC := lc.conn.Close(); errC != nil && err == nil {
+		err = errC
+	}
+	return res, err
+}
+
+// QueryContext executes the query. The session variable cannot be restored
+// while the rows get read, hence the connection gets discarded after the rows
+// have been closed.
+func (lc *lockWaitConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := lc.conn.QueryContext(ctx, query, args...)
+	go lc.discard(nil)
+	return rows, err
+}
+
+// QueryRowContext same as QueryContext.
+func (lc *lockWaitConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := lc.conn.QueryRowContext(ctx, query, args...)
+	go lc.discard(nil)
+	return row
+}
+
+const (
+	maxExecTimeMySQL uint8 = iota + 1
+	maxExecTimeMariaDB
+)
+
+// WithDeadlineMaxExecutionTime propagates the deadline of a context into the
+// maximum execution time of each SELECT statement, so the server aborts a
+// query once the client has given up waiting for it. The server version gets
+// queried to choose the syntax: MySQL >= 5.7.8 receives an optimizer hint with
+// the remaining milliseconds, MariaDB >= 10.1.2 a SET STATEMENT clause with the
+// remaining seconds.
+//		SELECT /*+ MAX_EXECUTION_TIME(2500) */ `sku` FROM ...
+//		SET STATEMENT max_statement_time=2.500 FOR SELECT `sku` FROM ...
+// Prepared statements and contexts without deadline are not affected.
+func WithDeadlineMaxExecutionTime(ctx context.Context) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			var version string
+			if err := c.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+				return errors.Wrapf(err, "[dml] WithDeadlineMaxExecutionTime failed to query the version")
+			}
+			isMariaDB, v := parseServerVersion(version)
+			switch {
+			case isMariaDB && (v[0] > 10 || (v[0] == 10 && (v[1] > 1 || (v[1] == 1 && v[2] >= 2)))):
+				c.maxExecTime = maxExecTimeMariaDB
+			case !isMariaDB && (v[0] > 5 || (v[0] == 5 && (v[1] > 7 || (v[1] == 7 && v[2] >= 8)))):
+				c.maxExecTime = maxExecTimeMySQL
+			default:
+				return errors.NotSupported.Newf("[dml] WithDeadlineMaxExecutionTime server version %q does not support a maximum execution time", version)
+			}
+			return nil
+		},
+	}
+}
+
+// withDeadlineLockWait prefixes sqlStr with the lock wait timeout and the
+// maximum execution time derived from the context deadline and from
+// Select.LockWaitTimeout. On servers without the SET STATEMENT syntax the lock
+// wait timeout gets set on the session. The statement must be executed with
+// the returned db, which is a dedicated connection if the session of a
+// ConnPool has been changed. See WithDeadlineLockWaitTimeout and
+// WithDeadlineMaxExecutionTime.
+func (bc *builderCommon) withDeadlineLockWait(ctx context.Context, sqlStr string) (_ string, db QueryExecPreparer, err error) {
+	db = bc.db
+	if bc.stmtLockWait > 0 {
+		if _, ok := db.(stmtWrapper); ok || sqlStr == "" {
+			return "", nil, errors.NotSupported.Newf("[dml] Select.LockWaitTimeout does not support prepared statements")
+		}
+	}
+	if (bc.lockWait <= 0 && bc.stmtLockWait <= 0 && bc.maxExecTime == 0) || sqlStr == "" || strings.HasPrefix(sqlStr, "SET ") {
+		return sqlStr, db, nil
+	}
+	deadline, ok := ctx.Deadline()
+	remaining := time.Until(deadline)
+
+	var setVars string
+	if bc.lockWait > 0 || bc.stmtLockWait > 0 {
+		syntax, limit, limited := bc.lockWaitSyntax, bc.lockWait, false
+		if bc.stmtLockWait > 0 {
+			if syntax == 0 {
+				if syntax, err = bc.lockWaitDetector.detect(ctx, db); err != nil {
+					return "", nil, errors.WithStack(err)
+				}
+			}
+			limit, limited = bc.stmtLockWait, true
+		}
+		secs := int64(limit / time.Second)
+		if ok && remaining < limit {
+			limited = true
+			secs = int64(remaining / time.Second)
+			if secs < 1 {
+				secs = 1 // minimum value of innodb_lock_wait_timeout
+			}
+		}
+		switch {
+		case syntax == lockWaitSession && bc.lockWaitState != nil:
+			if err := bc.lockWaitState.set(ctx, db, secs); err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+		case syntax == lockWaitSession && bc.stmtLockWait > 0:
+			// a ConnPool has no session, deadlines run with the timeout of
+			// the server
+			if db, err = bc.newLockWaitConn(ctx, secs); err != nil {
+				return "", nil, errors.WithStack(err)
+			}
+		case syntax == lockWaitSetStatement && limited:
+			setVars = "innodb_lock_wait_timeout=" + strconv.FormatInt(secs, 10)
+		}
+	}
+	if ok && bc.maxExecTime != 0 && strings.HasPrefix(sqlStr, "SELECT ") {
+		ms := int64(remaining / time.Millisecond)
+		if ms < 1 {
+			ms = 1 // zero disables the limit
+		}
+		switch bc.maxExecTime {
+		case maxExecTimeMySQL:
+			// only the first hint comment gets recognized, see Select.OptimizerHint
+			if strings.HasPrefix(sqlStr, "SELECT /*+ ") {
+				sqlStr = "SELECT /*+ MAX_EXECUTION_TIME(" + strconv.FormatInt(ms, 10) + ") " + sqlStr[len("SELECT /*+ "):]
+			} else {
+				sqlStr = "SELECT /*+ MAX_EXECUTION_TIME(" + strconv.FormatInt(ms, 10) + ") */ " + sqlStr[len("SELECT "):]
+			}
+		case maxExecTimeMariaDB:
+			if setVars != "" {
+				setVars += ", "
+			}
+			setVars += "max_statement_time=" + strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
+		}
+	}
+	if setVars == "" {
+		return sqlStr, db, nil
+	}
+	return "SET STATEMENT " + setVars + " FOR " + sqlStr, db, nil
+}
+
+// resetLockWait restores the innodb_lock_wait_timeout of the session to the
+// timeout of the server. See WithDeadlineLockWaitTimeout.
+func (c *connCommon) resetLockWait(db QueryExecPreparer) error {
+	if c.lockWaitState == nil {
+		return nil
+	}
+	return c.lockWaitState.set(context.Background(), db, c.lockWaitState.server)
+}
@@ -0,0 +1,356 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWithDeadlineLockWaitTimeout(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	assert.ErrorIsKind(t, errors.NotValid, dbc.Options(dml.WithDeadlineLockWaitTimeout(context.TODO(), -time.Second)))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("5.5.5-10.3.22-MariaDB-log"))
+	assert.NoError(t, dbc.Options(dml.WithDeadlineLockWaitTimeout(context.TODO(), 10*time.Second)))
+
+	runUpdate := func(ctx context.Context, wantSQL string) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(wantSQL)).
+			WithArgs(int64(5), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dbc.Update("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().ExecContext(ctx, int64(5), int64(3))
+		assert.NoError(t, err)
+	}
+
+	t.Run("short deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+		defer cancel()
+		runUpdate(ctx, "SET STATEMENT innodb_lock_wait_timeout=3 FOR UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")
+	})
+	t.Run("minimum one second", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		runUpdate(ctx, "SET STATEMENT innodb_lock_wait_timeout=1 FOR UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")
+	})
+	t.Run("deadline longer than server timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		runUpdate(ctx, "UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")
+	})
+	t.Run("no deadline", func(t *testing.T) {
+		runUpdate(context.Background(), "UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")
+	})
+	t.Run("query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SET STATEMENT innodb_lock_wait_timeout=2 FOR SELECT `qty` FROM `stock` WHERE (`product_id` = ?) FOR UPDATE")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		qty, found, err := dbc.SelectFrom("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).ForUpdate().
+			WithDBR().LoadNullInt64(ctx, int64(3))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, int64(5), qty.Int64)
+	})
+	t.Run("Select.LockWaitTimeout", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SET STATEMENT innodb_lock_wait_timeout=4 FOR SELECT `qty` FROM `stock` WHERE (`product_id` = ?) FOR UPDATE")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		_, _, err := dbc.SelectFrom("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).ForUpdate().
+			LockWaitTimeout(4*time.Second).WithDBR().LoadNullInt64(context.Background(), int64(3))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SET STATEMENT innodb_lock_wait_timeout=2 FOR SELECT `qty` FROM `stock` WHERE (`product_id` = ?) FOR UPDATE")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		_, _, err = dbc.SelectFrom("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).ForUpdate().
+			LockWaitTimeout(4*time.Second).WithDBR().LoadNullInt64(ctx, int64(3))
+		assert.NoError(t, err, "the shorter deadline wins")
+	})
+	t.Run("Select.LockWaitTimeout in a Union", func(t *testing.T) {
+		sqlStr, _, err := dml.NewUnion(
+			dml.NewSelect("qty").From("stock").ForUpdate().LockWaitTimeout(4*time.Second),
+			dml.NewSelect("qty").From("stock_archive"),
+		).ToSQL()
+		assert.NoError(t, err)
+		assert.NotContains(t, sqlStr, "SET STATEMENT")
+	})
+}
+
+func TestWithDeadlineLockWaitTimeout_MySQL(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.21"))
+	assert.NoError(t, dbc.Options(dml.WithDeadlineLockWaitTimeout(context.TODO(), 10*time.Second)))
+
+	runUpdate := func(db interface {
+		Update(string) *dml.Update
+	}, ctx context.Context) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")).
+			WithArgs(int64(5), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := db.Update("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().ExecContext(ctx, int64(5), int64(3))
+		assert.NoError(t, err)
+	}
+	expectSet := func(secs string) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SET SESSION innodb_lock_wait_timeout=" + secs)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("connection pool without session", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+		defer cancel()
+		runUpdate(dbc, ctx)
+	})
+	t.Run("transaction restores the session", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		tx, err := dbc.BeginTx(context.Background(), nil)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+		defer cancel()
+		expectSet("3")
+		runUpdate(tx, ctx)
+		runUpdate(tx, ctx) // session variable already set
+		expectSet("10")
+		runUpdate(tx, context.Background())
+		expectSet("3")
+		runUpdate(tx, ctx)
+
+		expectSet("10")
+		dbMock.ExpectCommit()
+		assert.NoError(t, tx.Commit())
+	})
+	t.Run("rollback restores the session", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		tx, err := dbc.BeginTx(context.Background(), nil)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		expectSet("2")
+		runUpdate(tx, ctx)
+
+		expectSet("10")
+		dbMock.ExpectRollback()
+		assert.NoError(t, tx.Rollback())
+	})
+	t.Run("Select.LockWaitTimeout", func(t *testing.T) {
+		// the connection pool uses a dedicated connection, which gets discarded
+		// after the rows have been closed. sqlmock cannot open a new connection
+		// after the last one has been closed, so another one gets held.
+		held, err := dbc.Conn(context.Background())
+		assert.NoError(t, err)
+		expectSet("4")
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `qty` FROM `stock` FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		dbMock.ExpectClose()
+		_, _, err = dbc.SelectFrom("stock").AddColumns("qty").ForUpdate().
+			LockWaitTimeout(4 * time.Second).WithDBR().LoadNullInt64(context.Background())
+		assert.NoError(t, err)
+		waitForExpectations(t, dbMock)
+		assert.NoError(t, held.Close())
+
+		dbMock.ExpectBegin()
+		tx, err := dbc.BeginTx(context.Background(), nil)
+		assert.NoError(t, err)
+		expectSet("4")
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `qty` FROM `stock` FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		_, _, err = tx.SelectFrom("stock").AddColumns("qty").ForUpdate().
+			LockWaitTimeout(4 * time.Second).WithDBR().LoadNullInt64(context.Background())
+		assert.NoError(t, err)
+
+		expectSet("10")
+		runUpdate(tx, context.Background())
+		dbMock.ExpectCommit()
+		assert.NoError(t, tx.Commit())
+	})
+	t.Run("connection restores the session", func(t *testing.T) {
+		conn, err := dbc.Conn(context.Background())
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		expectSet("2")
+		runUpdate(conn, ctx)
+
+		expectSet("10")
+		assert.NoError(t, conn.Close())
+	})
+}
+
+func TestSelect_LockWaitTimeout(t *testing.T) {
+	t.Run("MariaDB detects the syntax once", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("5.5.5-10.3.22-MariaDB-log"))
+		for i := 0; i < 2; i++ {
+			dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SET STATEMENT innodb_lock_wait_timeout=4 FOR SELECT `qty` FROM `stock` FOR UPDATE")).
+				WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+			_, _, err := dbc.SelectFrom("stock").AddColumns("qty").ForUpdate().
+				LockWaitTimeout(4 * time.Second).WithDBR().LoadNullInt64(context.Background())
+			assert.NoError(t, err)
+		}
+	})
+	t.Run("MySQL restores the dedicated connection after Exec", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.21"))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SET SESSION innodb_lock_wait_timeout=4")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SELECT `qty` FROM `stock` FOR UPDATE INTO @qty")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SET SESSION innodb_lock_wait_timeout=DEFAULT")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		_, err := dbc.SelectFrom("stock").AddColumns("qty").ForUpdate().IntoVars("@qty").
+			LockWaitTimeout(4 * time.Second).WithDBR().ExecContext(context.Background())
+		assert.NoError(t, err)
+	})
+	t.Run("MySQL transaction without option", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectBegin()
+		tx, err := dbc.BeginTx(context.Background(), nil)
+		assert.NoError(t, err)
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.21"))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SET SESSION innodb_lock_wait_timeout=4")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `qty` FROM `stock` FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		_, _, err = tx.SelectFrom("stock").AddColumns("qty").ForUpdate().
+			LockWaitTimeout(4 * time.Second).WithDBR().LoadNullInt64(context.Background())
+		assert.NoError(t, err)
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SET SESSION innodb_lock_wait_timeout=DEFAULT")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectCommit()
+		assert.NoError(t, tx.Commit())
+	})
+	t.Run("prepared statement", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		_, err := dbc.SelectFrom("stock").AddColumns("qty").ForUpdate().
+			LockWaitTimeout(4 * time.Second).Prepare(context.Background())
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
+
+// waitForExpectations waits until a connection discarded in the background
+// has been closed.
+func waitForExpectations(t *testing.T, dbMock sqlmock.Sqlmock) {
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = dbMock.ExpectationsWereMet(); err == nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal(err)
+}
+
+func TestWithDeadlineMaxExecutionTime(t *testing.T) {
+	newConnPool := func(t *testing.T, version string, opts ...dml.ConnPoolOption) (*dml.ConnPool, sqlmock.Sqlmock) {
+		dbc, dbMock := dmltest.MockDB(t)
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+		assert.NoError(t, dbc.Options(append(opts, dml.WithDeadlineMaxExecutionTime(context.TODO()))...))
+		return dbc, dbMock
+	}
+	loadQty := func(t *testing.T, dbc *dml.ConnPool, dbMock sqlmock.Sqlmock, ctx context.Context, wantSQLRegex string) {
+		dbMock.ExpectQuery(wantSQLRegex).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		qty, found, err := dbc.SelectFrom("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().LoadNullInt64(ctx, int64(3))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, int64(5), qty.Int64)
+	}
+
+	t.Run("MySQL optimizer hint", func(t *testing.T) {
+		dbc, dbMock := newConnPool(t, "8.0.21")
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		loadQty(t, dbc, dbMock, ctx, `^SELECT /\*\+ MAX_EXECUTION_TIME\(\d+\) \*/ .qty. FROM .stock. WHERE \(.product_id. = \?\)$`)
+		loadQty(t, dbc, dbMock, context.Background(), `^SELECT .qty. FROM .stock. WHERE \(.product_id. = \?\)$`)
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")).
+			WithArgs(int64(5), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dbc.Update("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().ExecContext(ctx, int64(5), int64(3))
+		assert.NoError(t, err, "UPDATE must not be affected")
+	})
+
+	t.Run("MySQL merges into optimizer hint", func(t *testing.T) {
+		dbc, dbMock := newConnPool(t, "8.0.21")
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		dbMock.ExpectQuery(`^SELECT /\*\+ MAX_EXECUTION_TIME\(\d+\) NO_ICP\(stock\) \*/ .qty. FROM .stock.$`).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		qty, found, err := dbc.SelectFrom("stock").AddColumns("qty").OptimizerHint("NO_ICP(stock)").
+			WithDBR().LoadNullInt64(ctx)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, int64(5), qty.Int64)
+	})
+
+	t.Run("MariaDB with lock wait timeout", func(t *testing.T) {
+		dbc, dbMock := newConnPool(t, "5.5.5-10.3.22-MariaDB-log")
+		defer dmltest.MockClose(t, dbc, dbMock)
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("5.5.5-10.3.22-MariaDB-log"))
+		assert.NoError(t, dbc.Options(dml.WithDeadlineLockWaitTimeout(context.TODO(), 10*time.Second)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		loadQty(t, dbc, dbMock, ctx, `^SET STATEMENT innodb_lock_wait_timeout=2, max_statement_time=2\.\d{3} FOR SELECT .qty. FROM .stock. WHERE \(.product_id. = \?\)$`)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("5.6.40"))
+		err := dbc.Options(dml.WithDeadlineMaxExecutionTime(context.TODO()))
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
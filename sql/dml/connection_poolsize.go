@@ -0,0 +1,220 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// PoolSizing configures WithPoolSizing.
+type PoolSizing struct {
+	// Interval defines how often sql.DBStats gets sampled in the background.
+	// Zero disables the background sampling, see ConnPool.SamplePoolStats.
+	Interval time.Duration
+	// Window defines the number of samples a recommendation gets calculated
+	// from. Defaults to 30.
+	Window int
+	// MinOpenConns and MaxOpenConns bound the recommended number of open
+	// connections. Zero MaxOpenConns applies no upper bound.
+	MinOpenConns int
+	MaxOpenConns int
+	// AutoApply applies each new recommendation to the connection pool. The
+	// window of samples starts anew after each applied recommendation, so the
+	// waits get only counted once. Requires MaxOpenConns as upper bound.
+	AutoApply bool
+}
+
+// PoolRecommendation suggests the settings of the connection pool derived from
+// the sampled sql.DBStats. See WithPoolSizing.
+type PoolRecommendation struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	// PeakInUse, WaitCount and WaitDuration describe the load within the
+	// window of samples.
+	PeakInUse    int
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// WithPoolSizing samples the statistics of the connection pool and calculates
+// a recommendation for its settings, for services which cannot be tuned per
+// deployment. If queries had to wait for a connection, the recommendation
+// raises MaxOpenConns by a quarter; if the peak of connections in use stays
+// below half of MaxOpenConns, it shrinks MaxOpenConns to twice the peak.
+// MaxIdleConns equals MaxOpenConns and ConnMaxLifetime equals MaxOpenConns
+// times one second, following the advice of NewConnPool. The background
+// sampling stops when the ConnPool gets closed.
+//		dbc, err := dml.NewConnPool(dml.WithDSN(dsn), dml.WithPoolSizing(dml.PoolSizing{
+//			Interval: 10 * time.Second, MinOpenConns: 4, MaxOpenConns: 64, AutoApply: true,
+//		}))
+func WithPoolSizing(ps PoolSizing) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			if ps.Interval < 0 || ps.Window < 0 || ps.MinOpenConns < 0 || ps.MaxOpenConns < 0 ||
+				(ps.MaxOpenConns > 0 && ps.MinOpenConns > ps.MaxOpenConns) || (ps.AutoApply && ps.MaxOpenConns == 0) {
+				return errors.NotValid.Newf("[dml] WithPoolSizing invalid settings: %#v", ps)
+			}
+			if c.poolSizer != nil {
+				return errors.AlreadyExists.Newf("[dml] WithPoolSizing has already been applied")
+			}
+			if ps.Window == 0 {
+				ps.Window = 30
+			}
+			c.poolSizer = &poolSizer{
+				ps:      ps,
+				db:      c.DB,
+				log:     c.Log,
+				samples: make([]sql.DBStats, 0, ps.Window+1),
+			}
+			if ps.Interval > 0 {
+				c.poolSizer.stop = make(chan struct{})
+				c.poolSizer.done = make(chan struct{})
+				go c.poolSizer.run()
+			}
+			return nil
+		},
+	}
+}
+
+// SamplePoolStats adds the current statistics of the connection pool to the
+// window of WithPoolSizing and applies the recommendation if AutoApply has
+// been set. Runs in the background if PoolSizing.Interval has been set.
+func (c *ConnPool) SamplePoolStats() {
+	if c.poolSizer != nil {
+		c.poolSizer.sample(c.DB.Stats())
+	}
+}
+
+// PoolRecommendation returns the recommended settings of the connection pool.
+// Returns false if WithPoolSizing has not been applied or less than two
+// samples have been taken since the start or the last applied recommendation.
+func (c *ConnPool) PoolRecommendation() (PoolRecommendation, bool) {
+	if c.poolSizer == nil {
+		return PoolRecommendation{}, false
+	}
+	c.poolSizer.mu.Lock()
+	defer c.poolSizer.mu.Unlock()
+	return c.poolSizer.recommend()
+}
+
+type poolSizer struct {
+	ps  PoolSizing
+	db  *sql.DB
+	log log.Logger
+
+	mu sync.Mutex
+	// samples contains Window+1 samples to calculate Window deltas.
+	samples []sql.DBStats
+	applied PoolRecommendation
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (p *poolSizer) sample(s sql.DBStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.samples) == cap(p.samples) {
+		copy(p.samples, p.samples[1:])
+		p.samples = p.samples[:len(p.samples)-1]
+	}
+	p.samples = append(p.samples, s)
+	if !p.ps.AutoApply {
+		return
+	}
+	r, ok := p.recommend()
+	if !ok || r.MaxOpenConns == p.applied.MaxOpenConns {
+		return
+	}
+	p.db.SetMaxOpenConns(r.MaxOpenConns)
+	p.db.SetMaxIdleConns(r.MaxIdleConns)
+	p.db.SetConnMaxLifetime(r.ConnMaxLifetime)
+	p.applied = r
+	// the waits of the current window led to this recommendation, so the
+	// next one gets calculated from the new sample onwards.
+	p.samples = append(p.samples[:0], s)
+	if p.log != nil && p.log.IsDebug() {
+		p.log.Debug("WithPoolSizing.AutoApply", log.Int("max_open_conns", r.MaxOpenConns), log.Int("peak_in_use", r.PeakInUse),
+			log.Int64("wait_count", r.WaitCount), log.Duration("wait_duration", r.WaitDuration))
+	}
+}
+
+// recommend must be called with the lock held.
+func (p *poolSizer) recommend() (r PoolRecommendation, ok bool) {
+	if len(p.samples) < 2 {
+		return r, false
+	}
+	first, last := p.samples[0], p.samples[len(p.samples)-1]
+	for _, s := range p.samples[1:] {
+		if s.InUse > r.PeakInUse {
+			r.PeakInUse = s.InUse
+		}
+	}
+	r.WaitCount = last.WaitCount - first.WaitCount
+	r.WaitDuration = last.WaitDuration - first.WaitDuration
+
+	current := last.MaxOpenConnections
+	r.MaxOpenConns = current
+	switch {
+	case r.WaitCount > 0:
+		base := current
+		if r.PeakInUse > base {
+			base = r.PeakInUse
+		}
+		r.MaxOpenConns = base + (base+3)/4 // rounds up, at least one more
+	case current == 0 || r.PeakInUse*2 < current:
+		r.MaxOpenConns = r.PeakInUse * 2
+	}
+	if r.MaxOpenConns < p.ps.MinOpenConns {
+		r.MaxOpenConns = p.ps.MinOpenConns
+	}
+	if p.ps.MaxOpenConns > 0 && r.MaxOpenConns > p.ps.MaxOpenConns {
+		r.MaxOpenConns = p.ps.MaxOpenConns
+	}
+	if r.MaxOpenConns < 1 {
+		r.MaxOpenConns = 1
+	}
+	r.MaxIdleConns = r.MaxOpenConns
+	r.ConnMaxLifetime = time.Duration(r.MaxOpenConns) * time.Second
+	return r, true
+}
+
+func (p *poolSizer) run() {
+	defer close(p.done)
+	t := time.NewTicker(p.ps.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+			p.sample(p.db.Stats())
+		}
+	}
+}
+
+func (p *poolSizer) close() {
+	if p != nil && p.stop != nil {
+		close(p.stop)
+		<-p.done
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWithPoolSizing(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	dbc, err := NewConnPool(WithDB(db), WithPoolSizing(PoolSizing{Window: 3, MinOpenConns: 2, MaxOpenConns: 20, AutoApply: true}))
+	assert.NoError(t, err)
+	defer func() {
+		dbMock.ExpectClose()
+		assert.NoError(t, dbc.Close())
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	}()
+
+	_, ok := dbc.PoolRecommendation()
+	assert.False(t, ok, "requires two samples")
+
+	ps := dbc.poolSizer
+	ps.sample(sql.DBStats{MaxOpenConnections: 10, InUse: 2})
+	ps.sample(sql.DBStats{MaxOpenConnections: 10, InUse: 10, WaitCount: 4, WaitDuration: time.Second})
+
+	t.Run("waits raise max open conns", func(t *testing.T) {
+		assert.Exactly(t, PoolRecommendation{
+			MaxOpenConns:    13,
+			MaxIdleConns:    13,
+			ConnMaxLifetime: 13 * time.Second,
+			PeakInUse:       10,
+			WaitCount:       4,
+			WaitDuration:    time.Second,
+		}, ps.applied)
+		assert.Exactly(t, 13, db.Stats().MaxOpenConnections, "AutoApply")
+		_, ok := dbc.PoolRecommendation()
+		assert.False(t, ok, "window restarts after AutoApply")
+	})
+
+	t.Run("applied waits do not compound", func(t *testing.T) {
+		ps.sample(sql.DBStats{MaxOpenConnections: 13, InUse: 10, WaitCount: 4, WaitDuration: time.Second})
+		r, ok := dbc.PoolRecommendation()
+		assert.True(t, ok)
+		assert.Exactly(t, int64(0), r.WaitCount)
+		assert.Exactly(t, 13, r.MaxOpenConns)
+		assert.Exactly(t, 13, db.Stats().MaxOpenConnections)
+	})
+
+	t.Run("window drops old samples", func(t *testing.T) {
+		ps.sample(sql.DBStats{MaxOpenConnections: 13, InUse: 3, WaitCount: 4, WaitDuration: time.Second})
+		ps.sample(sql.DBStats{MaxOpenConnections: 13, InUse: 1, WaitCount: 4, WaitDuration: time.Second})
+		ps.sample(sql.DBStats{MaxOpenConnections: 13, InUse: 1, WaitCount: 4, WaitDuration: time.Second})
+		assert.Exactly(t, 6, ps.applied.MaxOpenConns, "shrinks to twice the peak")
+		assert.Exactly(t, 3, ps.applied.PeakInUse)
+		assert.Exactly(t, int64(0), ps.applied.WaitCount)
+	})
+
+	t.Run("bounds", func(t *testing.T) {
+		ps.sample(sql.DBStats{MaxOpenConnections: 6, InUse: 0, WaitCount: 4})
+		assert.Exactly(t, 2, ps.applied.MaxOpenConns, "MinOpenConns")
+
+		ps.sample(sql.DBStats{MaxOpenConnections: 18, InUse: 18, WaitCount: 100})
+		assert.Exactly(t, 20, ps.applied.MaxOpenConns, "MaxOpenConns")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		err := dbc.Options(WithPoolSizing(PoolSizing{MinOpenConns: 5, MaxOpenConns: 4}))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		err = dbc.Options(WithPoolSizing(PoolSizing{AutoApply: true}))
+		assert.ErrorIsKind(t, errors.NotValid, err, "AutoApply requires MaxOpenConns")
+		err = dbc.Options(WithPoolSizing(PoolSizing{}))
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+	})
+}
@@ -17,12 +17,16 @@ package dml_test
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/sql/dml"
 	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
 	"github.com/corestoreio/pkg/util/assert"
 )
 
@@ -170,6 +174,75 @@ func TestTx_Wrap(t *testing.T) {
 	})
 }
 
+func TestDBR_BindNamed(t *testing.T) {
+	t.Parallel()
+
+	dbc, mock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, mock)
+
+	type base struct {
+		StoreID int64 `db:"store_id"`
+		Name    string
+	}
+	type user struct {
+		base
+		ID      int64  `db:"id"`
+		Name    string `db:"name,omitempty"`
+		Ignored string `db:"-"`
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		dbr := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id AND name = :name AND store_id IN (:store_id, :id) AND x = ':no'")
+		args, err := dbr.BindNamed(&user{base: base{StoreID: 2, Name: "Embedded"}, ID: 5, Name: "Gopher"})
+		assert.NoError(t, err)
+		compareToSQL(t, dbr.TestWithArgs(args...), errors.NoKind,
+			"SELECT * FROM users WHERE id = ? AND name = ? AND store_id IN (?, ?) AND x = ':no'",
+			"SELECT * FROM users WHERE id = 5 AND name = 'Gopher' AND store_id IN (2, 5) AND x = ':no'",
+			int64(5), "Gopher", int64(2), int64(5),
+		)
+
+		// binding a second time works with the already replaced SQL
+		args, err = dbr.BindNamed(user{ID: 6})
+		assert.NoError(t, err)
+		assert.Exactly(t, []interface{}{int64(6), "", int64(0), int64(6)}, args)
+	})
+	t.Run("struct with quoted string", func(t *testing.T) {
+		dbr := dbc.WithRawSQL("SELECT * FROM users WHERE name = 'a:b' AND id = :id")
+		args, err := dbr.BindNamed(user{ID: 3})
+		assert.NoError(t, err)
+		assert.Exactly(t, []interface{}{int64(3)}, args)
+	})
+	t.Run("struct with unsupported field type", func(t *testing.T) {
+		_, err := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id").BindNamed(struct {
+			ID  int64 `db:"id"`
+			IDs []int64
+		}{ID: 3})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+	t.Run("map", func(t *testing.T) {
+		dbr := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id")
+		args, err := dbr.BindNamed(map[string]interface{}{"id": 7, "unused": 8})
+		assert.NoError(t, err)
+		assert.Exactly(t, []interface{}{7}, args)
+
+		args, err = dbr.BindNamed(map[string]string{"id": "8"})
+		assert.NoError(t, err)
+		assert.Exactly(t, []interface{}{"8"}, args)
+	})
+	t.Run("placeholder not satisfied", func(t *testing.T) {
+		args, err := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id AND email = :email").
+			BindNamed(map[string]interface{}{"id": 7})
+		assert.ErrorIsKind(t, errors.NotFound, err)
+		assert.Nil(t, args)
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id").BindNamed(3)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+		_, err = dbc.WithRawSQL("SELECT * FROM users WHERE id = :id").BindNamed(nil)
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
 func TestWithRawSQL(t *testing.T) {
 	t.Parallel()
 
@@ -317,6 +390,7 @@ func TestWithExecSQLOnConn(t *testing.T) {
 		)
 		assert.NoError(t, err)
 
+		mock.ExpectClose() // the DB gets closed despite the error
 		err = dbc.Close()
 		assert.ErrorIsKind(t, errors.NotAcceptable, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -420,3 +494,468 @@ func TestConnPool_WithDisabledForeignKeyChecks(t *testing.T) {
 		assert.ErrorIsKind(t, errors.Blocked, err)
 	})
 }
+
+func TestConnPool_TruncateAll(t *testing.T) {
+	const fkSQL = "SELECT `TABLE_NAME`, `REFERENCED_TABLE_NAME` FROM `information_schema`.`KEY_COLUMN_USAGE` WHERE `TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_NAME` IS NOT NULL"
+
+	t.Run("child first", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(fkSQL)).WillReturnRows(
+			sqlmock.NewRows([]string{"TABLE_NAME", "REFERENCED_TABLE_NAME"}).
+				AddRow("customer_address", "customer").
+				AddRow("customer", "store").
+				AddRow("customer", "customer"),
+		)
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer_address`")).WillReturnResult(sqlmock.NewResult(0, 3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("ALTER TABLE `customer_address` AUTO_INCREMENT = 1")).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")).WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("ALTER TABLE `customer` AUTO_INCREMENT = 1")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := dbc.TruncateAll(context.TODO(), []string{"customer", "customer_address"}, dml.TruncateOptions{ResetAutoIncrement: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("disabled foreign key checks", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectExec(`SET FOREIGN_KEY_CHECKS=0`).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("TRUNCATE TABLE `customer`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("TRUNCATE TABLE `customer_address`")).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(`SET FOREIGN_KEY_CHECKS=1`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := dbc.TruncateAll(context.TODO(), []string{"customer", "customer_address"}, dml.TruncateOptions{DisableForeignKeyChecks: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid table name", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		err := dbc.TruncateAll(context.TODO(), []string{"customer;"}, dml.TruncateOptions{})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+func TestConnPool_SeedFromJSON(t *testing.T) {
+	const fkSQL = "SELECT `TABLE_NAME`, `REFERENCED_TABLE_NAME` FROM `information_schema`.`KEY_COLUMN_USAGE` WHERE `TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_NAME` IS NOT NULL"
+	const seed = `{
+		"address": [{"id": 1, "customer_id": 2, "geo": {"lat": 1.5}}],
+		"customer": [{"id": 2, "email": "a@b.c"}, {"email": "d@e.f", "id": 5}, {"id": 3, "balance": 12.3456789012}, {"id": 4}]
+	}`
+
+	t.Run("parents first", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(fkSQL)).WillReturnRows(
+			sqlmock.NewRows([]string{"TABLE_NAME", "REFERENCED_TABLE_NAME"}).AddRow("address", "customer"),
+		)
+		// omitted columns get their DEFAULT value
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `customer` (`email`,`id`) VALUES (?,?),(?,?)")).
+			WithArgs("a@b.c", int64(2), "d@e.f", int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `customer` (`balance`,`id`) VALUES (?,?)")).
+			WithArgs("12.3456789012", int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `customer` (`id`) VALUES (?)")).
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `address` (`customer_id`,`geo`,`id`) VALUES (?,?,?)")).
+			WithArgs(int64(2), `{"lat":1.5}`, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := dbc.SeedFromJSON(context.TODO(), strings.NewReader(seed), dml.SeedOptions{BatchSize: 2})
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		err := dbc.SeedFromJSON(context.TODO(), strings.NewReader(`{"customer": {}}`), dml.SeedOptions{})
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+
+	t.Run("row without columns", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		err := dbc.SeedFromJSON(context.TODO(), strings.NewReader(`{"customer": [{}]}`), dml.SeedOptions{})
+		assert.ErrorIsKind(t, errors.Empty, err)
+	})
+}
+
+func TestWithKillQueryOnCancel(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithKillQueryOnCancel(time.Second))
+	defer dmltest.MockClose(t, dbc, dbMock)
+	dbMock.MatchExpectationsInOrder(false)
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT CONNECTION_ID()")).
+		WillReturnRows(sqlmock.NewRows([]string{"CONNECTION_ID()"}).AddRow(4711))
+
+	conn, err := dbc.Conn(context.TODO())
+	assert.NoError(t, err)
+
+	t.Run("query finishes", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `sales_order` SET `state`='new'")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := conn.Update("sales_order").AddClauses(dml.Column("state").Str("new")).WithDBR().ExecContext(context.TODO())
+		assert.NoError(t, err)
+		assert.Exactly(t, dml.KillQueryStats{}, dbc.KillQueryStats())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT SLEEP(10)")).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"SLEEP(10)"}).AddRow(0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("KILL QUERY 4711")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := conn.WithRawSQL("SELECT SLEEP(10)").QueryContext(ctx)
+		assert.Error(t, err)
+		assert.Exactly(t, dml.KillQueryStats{Canceled: 1, Killed: 1}, dbc.KillQueryStats())
+	})
+
+	t.Run("context canceled while iterating rows", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(1).AddRow(2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("KILL QUERY 4711")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, err := conn.WithRawSQL("SELECT `entity_id` FROM `sales_order`").QueryContext(ctx)
+		assert.NoError(t, err)
+		assert.True(t, rows.Next())
+		cancel()
+		for i := 0; i < 100 && dbc.KillQueryStats().Killed < 2; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.NoError(t, rows.Close())
+		assert.Exactly(t, dml.KillQueryStats{Canceled: 2, Killed: 2}, dbc.KillQueryStats())
+	})
+
+	dbMock.ExpectClose()
+	assert.NoError(t, conn.Close())
+}
+
+func TestWithConnGroup(t *testing.T) {
+	dbReporting, mockReporting, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	dbc, dbMock := dmltest.MockDB(t, dml.WithConnGroup("reporting", dbReporting))
+	defer func() {
+		mockReporting.ExpectClose()
+		dmltest.MockClose(t, dbc, dbMock)
+		assert.NoError(t, mockReporting.ExpectationsWereMet())
+	}()
+
+	t.Run("query routed to group", func(t *testing.T) {
+		mockReporting.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(3).AddRow(4))
+
+		ids, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").UseGroup("reporting").WithDBR().LoadInt64s(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{3, 4}, ids)
+
+		db, ok := dbc.ConnGroup("reporting")
+		assert.True(t, ok)
+		assert.Exactly(t, dbReporting, db)
+	})
+
+	t.Run("group not found", func(t *testing.T) {
+		ids, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").UseGroup("search-indexer").WithDBR().LoadInt64s(context.TODO(), nil)
+		assert.ErrorIsKind(t, errors.NotFound, err)
+		assert.Nil(t, ids)
+	})
+
+	t.Run("duplicate group", func(t *testing.T) {
+		err := dbc.Options(dml.WithConnGroup("reporting", dbReporting))
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+	})
+}
+
+func TestConn_SessionVars(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	conn, err := dbc.Conn(context.TODO())
+	assert.NoError(t, err)
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SELECT COUNT(*), MAX(`entity_id`) FROM `sales_order` INTO @total, @max_id")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT @total")).
+		WillReturnRows(sqlmock.NewRows([]string{"@total"}).AddRow(42))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT @total, @max_id")).
+		WillReturnRows(sqlmock.NewRows([]string{"@total", "@max_id"}).AddRow(42, 4711))
+
+	_, err = conn.SelectFrom("sales_order").
+		AddColumnsConditions(dml.Expr("COUNT(*)"), dml.Expr("MAX(`entity_id`)")).
+		IntoVars("@total", "@max_id").WithDBR().ExecContext(context.TODO())
+	assert.NoError(t, err)
+
+	total, found, err := conn.SessionVars("@total").LoadNullInt64(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Exactly(t, null.MakeInt64(42), total)
+
+	var total2, maxID null.Int64
+	err = conn.SessionVars("@total", "@max_id").IterateSerial(context.TODO(), func(cm *dml.ColumnMap) error {
+		for cm.Next() {
+			switch c := cm.Column(); c {
+			case "@total":
+				cm.NullInt64(&total2)
+			case "@max_id":
+				cm.NullInt64(&maxID)
+			}
+		}
+		return cm.Err()
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, null.MakeInt64(42), total2)
+	assert.Exactly(t, null.MakeInt64(4711), maxID)
+
+	_, _, err = conn.SessionVars().LoadNullInt64(context.TODO())
+	assert.ErrorIsKind(t, errors.Empty, err)
+
+	assert.NoError(t, conn.Close())
+}
+
+func TestWithServerTimeZone(t *testing.T) {
+	server := time.FixedZone("CEST", 2*3600)
+	dbc, dbMock := dmltest.MockDB(t, dml.WithServerTimeZone(server))
+	defer dmltest.MockClose(t, dbc, dbMock)
+	assert.Exactly(t, server, dbc.ServerTimeZone())
+
+	// 10:00 UTC is 12:00 in the server time zone. The driver formats and
+	// parses in UTC, hence the server wall clock gets transported as UTC.
+	createdAt := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	serverWallClock := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("time arguments", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order` WHERE (`created_at` > ?) AND (`updated_at` < ?)")).
+			WithArgs(serverWallClock, serverWallClock).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(1))
+
+		ids, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").
+			Where(dml.Column("created_at").Greater().PlaceHolder(), dml.Column("updated_at").Less().PlaceHolder()).
+			WithDBR().LoadInt64s(context.TODO(), nil, createdAt.In(time.FixedZone("EST", -5*3600)), null.MakeTime(createdAt))
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{1}, ids)
+	})
+	t.Run("interpolated time arguments", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order` WHERE (`created_at` > '2019-06-01 12:00:00')")).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(1))
+
+		ids, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").
+			Where(dml.Column("created_at").Greater().PlaceHolder()).
+			WithDBR().Interpolate().LoadInt64s(context.TODO(), nil, createdAt)
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{1}, ids)
+	})
+	t.Run("scanned values", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `created_at`, `updated_at` FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).
+				AddRow(serverWallClock, nil).
+				AddRow([]byte("2019-06-01 12:00:00"), serverWallClock))
+
+		var got []time.Time
+		var gotNull []null.Time
+		err := dbc.SelectFrom("sales_order").AddColumns("created_at", "updated_at").WithDBR().
+			IterateSerial(context.TODO(), func(cm *dml.ColumnMap) error {
+				var ca time.Time
+				var ua null.Time
+				for cm.Next() {
+					switch c := cm.Column(); c {
+					case "created_at":
+						cm.Time(&ca)
+					case "updated_at":
+						cm.NullTime(&ua)
+					}
+				}
+				got = append(got, ca)
+				gotNull = append(gotNull, ua)
+				return cm.Err()
+			})
+		assert.NoError(t, err)
+		assert.Exactly(t, []time.Time{createdAt, createdAt}, got)
+		assert.Exactly(t, []null.Time{{}, null.MakeTime(createdAt)}, gotNull)
+	})
+	t.Run("LoadNullTime", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT MAX(created_at) FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(serverWallClock))
+
+		nt, found, err := dbc.SelectFrom("sales_order").AddColumnsConditions(dml.Expr("MAX(created_at)")).
+			WithDBR().LoadNullTime(context.TODO())
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, null.MakeTime(createdAt), nt)
+	})
+	t.Run("nil location", func(t *testing.T) {
+		_, err := dml.NewConnPool(dml.WithServerTimeZone(nil))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+type observedQueries struct {
+	mu   sync.Mutex
+	data []dml.QueryObservation
+}
+
+func (oq *observedQueries) ObserveQuery(o dml.QueryObservation) {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+	oq.data = append(oq.data, o)
+}
+
+func TestWithMetrics(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	oq := new(observedQueries)
+	assert.NoError(t, dbc.Options(dml.WithMetrics(oq)))
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")).WillReturnResult(sqlmock.NewResult(0, 2))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`")).WillReturnError(errors.ConnectionFailed.Newf("Upps"))
+
+	_, err := dbc.DeleteFrom("customer").WithDBR().ExecContext(context.TODO())
+	assert.NoError(t, err)
+	_, err = dbc.SelectFrom("customer").AddColumns("email").WithDBR().LoadStrings(context.TODO(), nil)
+	assert.True(t, errors.ConnectionFailed.Match(err), "%+v", err)
+
+	t.Run("union and show", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("(SELECT `email` FROM `customer`)\nUNION\n(SELECT `email` FROM `admin_user`)")).
+			WillReturnRows(sqlmock.NewRows([]string{"email"}))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SHOW VARIABLES")).
+			WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}))
+
+		_, err := dbc.Union(dml.NewSelect("email").From("customer"), dml.NewSelect("email").From("admin_user")).
+			WithDBR().LoadStrings(context.TODO(), nil)
+		assert.NoError(t, err)
+		_, err = dbc.Show().Variable().WithDBR().LoadStrings(context.TODO(), nil)
+		assert.NoError(t, err)
+	})
+
+	assert.Len(t, oq.data, 4)
+	assert.Exactly(t, "union", oq.data[2].Source)
+	assert.Exactly(t, "show", oq.data[3].Source)
+	assert.Exactly(t, "Exec", oq.data[0].Kind)
+	assert.Exactly(t, "delete", oq.data[0].Source)
+	assert.NoError(t, oq.data[0].Err)
+	assert.Exactly(t, dml.DurationBucket(oq.data[0].Duration), oq.data[0].Bucket)
+	assert.Exactly(t, "Query", oq.data[1].Kind)
+	assert.Exactly(t, "select", oq.data[1].Source)
+	assert.True(t, errors.ConnectionFailed.Match(oq.data[1].Err), "%+v", oq.data[1].Err)
+}
+
+type ctxHookKey struct{}
+
+func TestWithQueryHooks(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	var events []dml.QueryHookEvent
+	newHook := func(name string) dml.QueryHook {
+		return dml.QueryHookFuncs{
+			Before: func(ctx context.Context, ev *dml.QueryHookEvent) context.Context {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, "before_"+name)
+				return context.WithValue(ctx, ctxHookKey{}, name)
+			},
+			After: func(ctx context.Context, ev *dml.QueryHookEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, "after_"+name+"_"+ctx.Value(ctxHookKey{}).(string))
+				if name == "a" {
+					events = append(events, *ev)
+				}
+			},
+		}
+	}
+
+	dbc, dbMock := dmltest.MockDB(t, dml.WithQueryHooks(newHook("a"), newHook("b")))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer` WHERE (`id` = ?)")).
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`")).
+		WillReturnError(errors.ConnectionFailed.Newf("Upps"))
+	dbMock.ExpectBegin()
+	dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer` WHERE (`id` = ?)")).WillBeClosed()
+	dbMock.ExpectRollback()
+
+	_, err := dbc.DeleteFrom("customer").Where(dml.Column("id").PlaceHolder()).WithDBR().ExecContext(context.TODO(), 3)
+	assert.NoError(t, err)
+	_, err = dbc.SelectFrom("customer").AddColumns("email").WithDBR().LoadStrings(context.TODO(), nil)
+	assert.True(t, errors.ConnectionFailed.Match(err), "%+v", err)
+
+	tx, err := dbc.BeginTx(context.TODO(), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, tx.WithPrepare(context.TODO(), "SELECT `email` FROM `customer` WHERE (`id` = ?)").Close())
+	assert.NoError(t, tx.Rollback())
+
+	assert.Exactly(t, []string{
+		"before_a", "before_b", "after_b_b", "after_a_b",
+		"before_a", "before_b", "after_b_b", "after_a_b",
+		"before_a", "before_b", "after_b_b", "after_a_b",
+	}, calls)
+	assert.Len(t, events, 3)
+	assert.Exactly(t, "Exec", events[0].Kind)
+	assert.Exactly(t, "delete", events[0].Source)
+	assert.Exactly(t, "DELETE FROM `customer` WHERE (`id` = ?)", events[0].Query)
+	assert.Exactly(t, 1, events[0].ArgsLen)
+	assert.NoError(t, events[0].Err)
+	assert.Exactly(t, "Query", events[1].Kind)
+	assert.Exactly(t, "select", events[1].Source)
+	assert.True(t, errors.ConnectionFailed.Match(events[1].Err), "%+v", events[1].Err)
+	assert.Exactly(t, "Prepare", events[2].Kind)
+	assert.Exactly(t, "raw", events[2].Source)
+}
+
+func TestTx_WrapNested(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SAVEPOINT `dml_nested_1`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")).WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SAVEPOINT `dml_nested_2`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("ROLLBACK TO SAVEPOINT `dml_nested_2`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("RELEASE SAVEPOINT `dml_nested_1`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("SAVEPOINT `dml_nested_1`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("RELEASE SAVEPOINT `dml_nested_1`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectCommit()
+
+	ctx := context.TODO()
+	err := dbc.Transaction(ctx, nil, func(tx *dml.Tx) error {
+		err := tx.WrapNested(ctx, func(tx *dml.Tx) error {
+			if _, err := tx.DeleteFrom("customer").WithDBR().ExecContext(ctx); err != nil {
+				return err
+			}
+			errNested := tx.WrapNested(ctx, func(tx *dml.Tx) error {
+				return errors.NotValid.Newf("invalid loyalty points")
+			})
+			assert.ErrorIsKind(t, errors.NotValid, errNested)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tx.WrapNested(ctx, func(tx *dml.Tx) error { return nil })
+	})
+	assert.NoError(t, err)
+
+	t.Run("empty name", func(t *testing.T) {
+		dbMock.ExpectBegin()
+		dbMock.ExpectRollback()
+		tx, err := dbc.BeginTx(ctx, nil)
+		assert.NoError(t, err)
+		assert.ErrorIsKind(t, errors.Empty, tx.Savepoint(ctx, ""))
+		assert.NoError(t, tx.Rollback())
+	})
+}
@@ -0,0 +1,243 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/go-sql-driver/mysql"
+)
+
+// DefaultReplicaHealthCheckInterval defines the interval of the health check
+// used by NewConnPoolPrimaryReplica.
+const DefaultReplicaHealthCheckInterval = 5 * time.Second
+
+// ReplicaRouting configures WithReplicas and WithReplicaDSN.
+type ReplicaRouting struct {
+	// LeastLoaded routes a query to the healthy replica with the fewest
+	// connections in use. Default is round-robin.
+	LeastLoaded bool
+	// HealthCheckInterval defines how often the replicas get pinged in the
+	// background. A replica failing the ping receives no queries until a later
+	// ping succeeds. Zero disables the background check, see
+	// ConnPool.CheckReplicas.
+	HealthCheckInterval time.Duration
+}
+
+// NewConnPoolPrimaryReplica creates a new ConnPool which sends writes to the
+// primary and routes the queries of a Select to the replicas, round-robin and
+// with a background health check. Further options can be applied with
+// ConnPool.Options. See WithReplicas.
+func NewConnPoolPrimaryReplica(primaryDSN string, replicaDSNs ...string) (*ConnPool, error) {
+	return NewConnPool(
+		WithDSN(primaryDSN),
+		WithReplicaDSN(ReplicaRouting{HealthCheckInterval: DefaultReplicaHealthCheckInterval}, replicaDSNs...),
+	)
+}
+
+// WithReplicas routes the queries of a Select created via ConnPool.SelectFrom
+// to the replicas. All other statements, a Select with a locking clause,
+// statements within a Conn or Tx and a DBR marked with ForcePrimary run on the
+// primary ConnPool. If all replicas are unhealthy, the queries fall back to the
+// primary. The replicas get closed when the ConnPool gets closed.
+func WithReplicas(rr ReplicaRouting, dbs ...*sql.DB) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			return c.setReplicas(rr, dbs)
+		},
+	}
+}
+
+// WithReplicaDSN same as WithReplicas but opens a new connection pool for each
+// data source name.
+func WithReplicaDSN(rr ReplicaRouting, dsns ...string) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			dbs := make([]*sql.DB, 0, len(dsns))
+			for _, dsn := range dsns {
+				if !strings.Contains(dsn, "parseTime") {
+					return errors.NotImplemented.Newf("[dml] The DSN for go-sql-driver/mysql of a replica must contain the parameters `?parseTime=true[&loc=YourTimeZone]`")
+				}
+				if _, err := mysql.ParseDSN(dsn); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			for _, dsn := range dsns {
+				dbs = append(dbs, sql.OpenDB(dsnConnector{dsn: dsn, driver: mysql.MySQLDriver{}}))
+			}
+			if err := c.setReplicas(rr, dbs); err != nil {
+				for _, db := range dbs {
+					_ = db.Close()
+				}
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+func (c *ConnPool) setReplicas(rr ReplicaRouting, dbs []*sql.DB) error {
+	if c.DB == nil || len(dbs) == 0 {
+		return errors.Empty.Newf("[dml] WithReplicas requires a primary DB and at least one replica, got %d replicas", len(dbs))
+	}
+	if c.replicas != nil {
+		return errors.AlreadyExists.Newf("[dml] WithReplicas has already been applied")
+	}
+	for _, db := range dbs {
+		if db == nil {
+			return errors.Empty.Newf("[dml] WithReplicas replica DB cannot be nil")
+		}
+	}
+	if rr.HealthCheckInterval < 0 {
+		return errors.NotValid.Newf("[dml] WithReplicas requires a positive health check interval, got %s", rr.HealthCheckInterval)
+	}
+	c.replicas = &replicaSet{
+		rr:      rr,
+		primary: c.DB,
+		dbs:     dbs,
+		dead:    make([]uint32, len(dbs)),
+	}
+	if rr.HealthCheckInterval > 0 {
+		c.replicas.stop = make(chan struct{})
+		c.replicas.done = make(chan struct{})
+		go c.replicas.run()
+	}
+	return nil
+}
+
+// CheckReplicas pings all replicas and marks the failing replicas as unhealthy.
+// Returns the number of healthy replicas. Runs in the background if
+// ReplicaRouting.HealthCheckInterval has been set.
+func (c *ConnPool) CheckReplicas(ctx context.Context) int {
+	if c.replicas == nil {
+		return 0
+	}
+	return c.replicas.check(ctx)
+}
+
+type replicaSet struct {
+	rr ReplicaRouting
+	// primary gets compared with the DB of a DBR to detect whether the
+	// statement still runs on the ConnPool.
+	primary *sql.DB
+	dbs     []*sql.DB
+	// dead contains for each replica a one if the health check failed.
+	dead    []uint32
+	counter uint32
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// pick returns a healthy replica or nil.
+func (rs *replicaSet) pick() *sql.DB {
+	if rs.rr.LeastLoaded {
+		var best *sql.DB
+		bestInUse := 0
+		for i, db := range rs.dbs {
+			if atomic.LoadUint32(&rs.dead[i]) == 1 {
+				continue
+			}
+			if inUse := db.Stats().InUse; best == nil || inUse < bestInUse {
+				best, bestInUse = db, inUse
+			}
+		}
+		return best
+	}
+	n := uint32(len(rs.dbs))
+	start := atomic.AddUint32(&rs.counter, 1) - 1
+	for i := uint32(0); i < n; i++ {
+		idx := (start + i) % n
+		if atomic.LoadUint32(&rs.dead[idx]) == 0 {
+			return rs.dbs[idx]
+		}
+	}
+	return nil
+}
+
+func (rs *replicaSet) check(ctx context.Context) (healthy int) {
+	for i, db := range rs.dbs {
+		var dead uint32
+		if err := db.PingContext(ctx); err != nil {
+			dead = 1
+		} else {
+			healthy++
+		}
+		atomic.StoreUint32(&rs.dead[i], dead)
+	}
+	return healthy
+}
+
+func (rs *replicaSet) run() {
+	defer close(rs.done)
+	t := time.NewTicker(rs.rr.HealthCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-t.C:
+			ctx, cancel := context.WithTimeout(context.Background(), rs.rr.HealthCheckInterval)
+			rs.check(ctx)
+			cancel()
+		}
+	}
+}
+
+func (rs *replicaSet) close() (err error) {
+	if rs == nil {
+		return nil
+	}
+	if rs.stop != nil {
+		close(rs.stop)
+		<-rs.done
+	}
+	for i, db := range rs.dbs {
+		if errC := db.Close(); errC != nil && err == nil {
+			err = errors.Wrapf(errC, "[dml] Failed to close replica %d", i)
+		}
+	}
+	return err
+}
+
+// ForcePrimary runs the query on the primary even if replicas have been
+// configured, e.g. to read data written in the same request. See
+// WithReplicas.
+func (a *DBR) ForcePrimary() *DBR {
+	a.forcePrimary = true
+	return a
+}
+
+// readBase returns the builderCommon to run a query with. If the query can run
+// on a replica, a copy with the DB of the replica gets returned.
+func (a *DBR) readBase() *builderCommon {
+	rs := a.base.replicas
+	if rs == nil || a.forcePrimary || a.base.db != QueryExecPreparer(rs.primary) {
+		return &a.base
+	}
+	db := rs.pick()
+	if db == nil {
+		return &a.base
+	}
+	bc := a.base
+	bc.db = db
+	return &bc
+}
@@ -0,0 +1,107 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWithReplicas(t *testing.T) {
+	replica1, mock1, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	replica2, mock2, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+
+	dbc, dbMock := dmltest.MockDB(t, dml.WithReplicas(dml.ReplicaRouting{}, replica1, replica2))
+	defer func() {
+		mock1.ExpectClose()
+		mock2.ExpectClose()
+		dmltest.MockClose(t, dbc, dbMock)
+		assert.NoError(t, mock1.ExpectationsWereMet())
+		assert.NoError(t, mock2.ExpectationsWereMet())
+	}()
+
+	const sqlSelect = "SELECT `sku` FROM `catalog_product_entity` WHERE (`entity_id` = ?)"
+	sel := dbc.SelectFrom("catalog_product_entity").AddColumns("sku").
+		Where(dml.Column("entity_id").PlaceHolder()).WithDBR()
+	loadSKU := func(t *testing.T, dbr *dml.DBR, mock sqlmock.Sqlmock, wantSQL string) {
+		mock.ExpectQuery(dmltest.SQLMockQuoteMeta(wantSQL)).WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"sku"}).AddRow("SKU-1"))
+		sku, found, err := dbr.LoadNullString(context.TODO(), int64(1))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, "SKU-1", sku.Data)
+	}
+
+	t.Run("queries round-robin", func(t *testing.T) {
+		loadSKU(t, sel, mock1, sqlSelect)
+		loadSKU(t, sel, mock2, sqlSelect)
+		loadSKU(t, sel, mock1, sqlSelect)
+	})
+
+	t.Run("primary", func(t *testing.T) {
+		loadSKU(t, sel.Clone().ForcePrimary(), dbMock, sqlSelect)
+		loadSKU(t, dbc.SelectFrom("catalog_product_entity").AddColumns("sku").
+			Where(dml.Column("entity_id").PlaceHolder()).ForUpdate().WithDBR(), dbMock, sqlSelect+" FOR UPDATE")
+
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `catalog_product_entity` SET `sku`=? WHERE (`entity_id` = ?)")).
+			WithArgs("SKU-2", int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dbc.Update("catalog_product_entity").AddColumns("sku").
+			Where(dml.Column("entity_id").PlaceHolder()).WithDBR().ExecContext(context.TODO(), "SKU-2", int64(1))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unhealthy replicas get skipped", func(t *testing.T) {
+		mock1.ExpectPing().WillReturnError(errors.ConnectionFailed.Newf("replica1 gone"))
+		mock2.ExpectPing()
+		assert.Exactly(t, 1, dbc.CheckReplicas(context.TODO()))
+		loadSKU(t, sel, mock2, sqlSelect)
+		loadSKU(t, sel, mock2, sqlSelect)
+
+		mock1.ExpectPing().WillReturnError(errors.ConnectionFailed.Newf("replica1 gone"))
+		mock2.ExpectPing().WillReturnError(errors.ConnectionFailed.Newf("replica2 gone"))
+		assert.Exactly(t, 0, dbc.CheckReplicas(context.TODO()))
+		loadSKU(t, sel, dbMock, sqlSelect)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		err := dbc.Options(dml.WithReplicas(dml.ReplicaRouting{}))
+		assert.ErrorIsKind(t, errors.Empty, err)
+		err = dbc.Options(dml.WithReplicas(dml.ReplicaRouting{}, replica1))
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+		err = dbc.Options(dml.WithReplicaDSN(dml.ReplicaRouting{}, "root@tcp(replica:3306)/magento"))
+		assert.ErrorIsKind(t, errors.NotImplemented, err)
+	})
+}
+
+func TestWithReplicas_CloseError(t *testing.T) {
+	replica, mockR, err := sqlmock.New()
+	assert.NoError(t, err)
+	dbc, dbMock := dmltest.MockDB(t, dml.WithReplicas(dml.ReplicaRouting{}, replica))
+
+	mockR.ExpectClose().WillReturnError(errors.ConnectionFailed.Newf("replica gone"))
+	dbMock.ExpectClose()
+	err = dbc.Close()
+	assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+	assert.NoError(t, mockR.ExpectationsWereMet())
+	assert.NoError(t, dbMock.ExpectationsWereMet(), "the primary must be closed despite the replica error")
+}
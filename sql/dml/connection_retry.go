@@ -0,0 +1,205 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+)
+
+// MySQL error numbers of transient errors.
+const (
+	MySQLErrLockWaitTimeout uint16 = 1205
+	MySQLErrDeadlock        uint16 = 1213
+)
+
+// RetryEvent describes a failed attempt which gets retried.
+type RetryEvent struct {
+	// ID of the statement.
+	ID string
+	// Kind is either "Query" or "Exec".
+	Kind string
+	// Attempt is the number of the failed attempt, starting at one.
+	Attempt int
+	// Delay until the next attempt.
+	Delay time.Duration
+	Err   error
+}
+
+// RetryPolicy retries statements which failed with a transient error. Only
+// idempotent statements get retried: SELECT, UNION and SHOW statements and all
+// statements marked via DBR.Idempotent. Other queries, like an INSERT with a
+// RETURNING clause, do not get retried. Statements running in a transaction,
+// including statements prepared on a transaction, never get retried because a
+// deadlock rolls back the whole transaction.
+type RetryPolicy struct {
+	// MaxAttempts defines the maximum number of attempts including the first
+	// one. A value lower than two disables retrying.
+	MaxAttempts int
+	// InitialBackoff defines the delay after the first failed attempt, doubled
+	// after each further attempt. Defaults to 10ms.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the delay. Defaults to one second.
+	MaxBackoff time.Duration
+	// IsRetryable classifies an error as transient. Defaults to
+	// IsRetryableError.
+	IsRetryable func(error) bool
+	// OnRetry gets called before waiting for the next attempt. Optional.
+	OnRetry func(context.Context, RetryEvent)
+}
+
+// IsRetryableError returns true if the error is a deadlock (1213), a lock wait
+// timeout (1205) or driver.ErrBadConn.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Cause(err) == driver.ErrBadConn {
+		return true
+	}
+	switch MySQLNumberFromError(err) {
+	case MySQLErrDeadlock, MySQLErrLockWaitTimeout:
+		return true
+	}
+	return false
+}
+
+// WithRetryPolicy sets the retry policy for all statements created by the
+// ConnPool and its connections. DBR.WithRetryPolicy can overwrite it per
+// statement.
+//		dml.WithRetryPolicy(dml.RetryPolicy{
+//			MaxAttempts: 3,
+//			OnRetry: func(ctx context.Context, re dml.RetryEvent) {
+//				retryCounter.Inc()
+//			},
+//		})
+func WithRetryPolicy(rp RetryPolicy) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 10,
+		fn: func(c *ConnPool) error {
+			if rp.MaxAttempts < 0 || rp.InitialBackoff < 0 || rp.MaxBackoff < 0 {
+				return errors.NotValid.Newf("[dml] WithRetryPolicy requires positive values: %#v", rp)
+			}
+			c.retry = &rp
+			return nil
+		},
+	}
+}
+
+// backoff returns the delay after the failed attempt.
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	d, max := rp.InitialBackoff, rp.MaxBackoff
+	if d == 0 {
+		d = 10 * time.Millisecond
+	}
+	if max == 0 {
+		max = time.Second
+	}
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+func (rp *RetryPolicy) isRetryable(err error) bool {
+	if rp.IsRetryable != nil {
+		return rp.IsRetryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// withRetry calls fn until it succeeds, returns a non-retryable error, the
+// attempts are exhausted or the context gets canceled.
+func (bc *builderCommon) withRetry(ctx context.Context, kind string, idempotent bool, fn func() error) error {
+	rp := bc.retry
+	if rp == nil || rp.MaxAttempts < 2 || !idempotent {
+		return fn()
+	}
+	if isTxBound(bc.db) {
+		return fn()
+	}
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= rp.MaxAttempts || !rp.isRetryable(err) {
+			return err
+		}
+		d := rp.backoff(attempt)
+		if rp.OnRetry != nil {
+			rp.OnRetry(ctx, RetryEvent{ID: bc.id, Kind: kind, Attempt: attempt, Delay: d, Err: err})
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+// isTxBound returns true if the statements get executed within a transaction.
+func isTxBound(db Preparer) bool {
+	switch db := db.(type) {
+	case *sql.Tx:
+		return true
+	case stmtWrapper:
+		return db.inTx
+	}
+	return false
+}
+
+// isIdempotent returns true if the statement can be retried. Raw SQL
+// qualifies only if it starts with SELECT or SHOW. Queries of With statements
+// might be an UPDATE or DELETE and must be marked via Idempotent.
+func (a *DBR) isIdempotent(sqlStr string) bool {
+	if a.idempotent {
+		return true
+	}
+	switch a.base.source {
+	case dmlSourceSelect, dmlSourceUnion, dmlSourceShow:
+		return true
+	case 0:
+		sqlStr = strings.TrimLeft(sqlStr, " \t\r\n(")
+		for _, kw := range [...]string{"SELECT", "SHOW"} {
+			if len(sqlStr) > len(kw) && strings.EqualFold(sqlStr[:len(kw)], kw) && strings.IndexByte(" \t\r\n(", sqlStr[len(kw)]) >= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WithRetryPolicy overwrites the retry policy of the ConnPool for this
+// statement. A MaxAttempts lower than two disables retrying.
+func (a *DBR) WithRetryPolicy(rp RetryPolicy) *DBR {
+	a.base.retry = &rp
+	return a
+}
+
+// Idempotent marks the statement as safe to be executed multiple times, so
+// it gets retried by the RetryPolicy. SELECT statements are always considered
+// idempotent.
+func (a *DBR) Idempotent() *DBR {
+	a.idempotent = true
+	return a
+}
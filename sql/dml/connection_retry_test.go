@@ -0,0 +1,159 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	assert.True(t, dml.IsRetryableError(&mysql.MySQLError{Number: 1213}))
+	assert.True(t, dml.IsRetryableError(errors.WithStack(&mysql.MySQLError{Number: 1205})))
+	assert.True(t, dml.IsRetryableError(driver.ErrBadConn))
+	assert.False(t, dml.IsRetryableError(&mysql.MySQLError{Number: 1062}))
+	assert.False(t, dml.IsRetryableError(nil))
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	var events []dml.RetryEvent
+	dbc, dbMock := dmltest.MockDB(t, dml.WithRetryPolicy(dml.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Microsecond,
+		MaxBackoff:     3 * time.Microsecond,
+		OnRetry: func(_ context.Context, re dml.RetryEvent) {
+			events = append(events, re)
+		},
+	}))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+
+	t.Run("query succeeds after deadlocks", func(t *testing.T) {
+		events = nil
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`")
+		dbMock.ExpectQuery(selectSQL).WillReturnError(deadlock)
+		dbMock.ExpectQuery(selectSQL).WillReturnError(deadlock)
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("a@x.io"))
+
+		emails, err := dbc.SelectFrom("customer").AddColumns("email").WithDBR().LoadStrings(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{"a@x.io"}, emails)
+		assert.Len(t, events, 2)
+		assert.Exactly(t, "Query", events[0].Kind)
+		assert.Exactly(t, 1, events[0].Attempt)
+		assert.Exactly(t, time.Microsecond, events[0].Delay)
+		assert.Exactly(t, 2*time.Microsecond, events[1].Delay)
+	})
+
+	t.Run("query attempts exhausted", func(t *testing.T) {
+		events = nil
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`")
+		for i := 0; i < 3; i++ {
+			dbMock.ExpectQuery(selectSQL).WillReturnError(deadlock)
+		}
+		_, err := dbc.SelectFrom("customer").AddColumns("email").WithDBR().LoadStrings(context.TODO(), nil)
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("exec not idempotent", func(t *testing.T) {
+		events = nil
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")).WillReturnError(deadlock)
+		_, err := dbc.DeleteFrom("customer").WithDBR().ExecContext(context.TODO())
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.Len(t, events, 0)
+	})
+
+	t.Run("exec idempotent", func(t *testing.T) {
+		events = nil
+		deleteSQL := dmltest.SQLMockQuoteMeta("DELETE FROM `customer`")
+		dbMock.ExpectExec(deleteSQL).WillReturnError(&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"})
+		dbMock.ExpectExec(deleteSQL).WillReturnResult(sqlmock.NewResult(0, 2))
+		_, err := dbc.DeleteFrom("customer").WithDBR().Idempotent().ExecContext(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+		assert.Exactly(t, "Exec", events[0].Kind)
+	})
+
+	t.Run("raw query not idempotent", func(t *testing.T) {
+		events = nil
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("INSERT INTO `customer` (`email`) VALUES ('a@x.io') RETURNING `entity_id`")).WillReturnError(deadlock)
+		_, err := dbc.WithRawSQL("INSERT INTO `customer` (`email`) VALUES ('a@x.io') RETURNING `entity_id`").LoadUint64s(context.TODO(), nil)
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.Len(t, events, 0)
+	})
+
+	t.Run("raw query idempotent", func(t *testing.T) {
+		events = nil
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `customer`")
+		dbMock.ExpectQuery(selectSQL).WillReturnError(deadlock)
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(3))
+		ids, err := dbc.WithRawSQL("SELECT `entity_id` FROM `customer`").LoadUint64s(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, []uint64{3}, ids)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("prepared in transaction", func(t *testing.T) {
+		events = nil
+		dbMock.ExpectBegin()
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`"))
+		prep.ExpectQuery().WillReturnError(deadlock)
+		dbMock.ExpectRollback()
+
+		tx, err := dbc.BeginTx(context.TODO(), nil)
+		assert.NoError(t, err)
+		_, err = tx.WithPrepare(context.TODO(), "SELECT `email` FROM `customer`").LoadStrings(context.TODO(), nil)
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.NoError(t, tx.Rollback())
+		assert.Len(t, events, 0)
+	})
+
+	t.Run("builder prepared in transaction", func(t *testing.T) {
+		events = nil
+		dbMock.ExpectBegin()
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`"))
+		prep.ExpectQuery().WillReturnError(deadlock)
+		dbMock.ExpectRollback()
+
+		tx, err := dbc.BeginTx(context.TODO(), nil)
+		assert.NoError(t, err)
+		stmt, err := tx.SelectFrom("customer").AddColumns("email").Prepare(context.TODO())
+		assert.NoError(t, err)
+		_, err = stmt.WithDBR().LoadStrings(context.TODO(), nil)
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.NoError(t, tx.Rollback())
+		assert.Len(t, events, 0)
+	})
+
+	t.Run("disabled per statement", func(t *testing.T) {
+		events = nil
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `customer`")).WillReturnError(deadlock)
+		_, err := dbc.SelectFrom("customer").AddColumns("email").WithDBR().
+			WithRetryPolicy(dml.RetryPolicy{}).LoadStrings(context.TODO(), nil)
+		assert.Exactly(t, dml.MySQLErrDeadlock, dml.MySQLNumberFromError(err))
+		assert.Len(t, events, 0)
+	})
+}
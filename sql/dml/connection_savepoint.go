@@ -0,0 +1,88 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// Savepoint sets a named transaction savepoint. An existing savepoint with the
+// same name gets replaced.
+// https://dev.mysql.com/doc/refman/5.7/en/savepoint.html
+func (tx *Tx) Savepoint(ctx context.Context, name string) error {
+	return tx.execSavepoint(ctx, "SAVEPOINT ", name)
+}
+
+// RollbackToSavepoint rolls back the transaction to the named savepoint
+// without terminating the transaction. Savepoints set after the named
+// savepoint get deleted.
+func (tx *Tx) RollbackToSavepoint(ctx context.Context, name string) error {
+	return tx.execSavepoint(ctx, "ROLLBACK TO SAVEPOINT ", name)
+}
+
+// ReleaseSavepoint removes the named savepoint without a commit or rollback.
+func (tx *Tx) ReleaseSavepoint(ctx context.Context, name string) error {
+	return tx.execSavepoint(ctx, "RELEASE SAVEPOINT ", name)
+}
+
+func (tx *Tx) execSavepoint(ctx context.Context, stmt, name string) (err error) {
+	if name == "" {
+		return errors.Empty.Newf("[dml] Tx.Savepoint name cannot be empty")
+	}
+	if tx.Log != nil && tx.Log.IsDebug() {
+		defer log.WhenDone(tx.Log).Debug("Savepoint", log.String("sql", stmt), log.String("name", name), log.Err(err))
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString(stmt)
+	Quoter.quote(buf, name)
+	if _, err = tx.DB.ExecContext(ctx, buf.String()); err != nil {
+		return errors.Wrapf(err, "[dml] Tx.%s%q", stmt, name)
+	}
+	return nil
+}
+
+// WrapNested runs fn in a nested transaction scope. It sets a savepoint before
+// calling fn, rolls back to the savepoint if fn returns an error and releases
+// the savepoint otherwise. The outer transaction stays usable in both cases.
+// Nested calls of WrapNested within fn create further savepoints, named by
+// their nesting level.
+//		err := dbc.Transaction(ctx, nil, func(tx *dml.Tx) error {
+//			// insert order
+//			return tx.WrapNested(ctx, func(tx *dml.Tx) error {
+//				// optional loyalty points, rolled back on error
+//			})
+//		})
+func (tx *Tx) WrapNested(ctx context.Context, fn func(*Tx) error) error {
+	tx.nestedLevel++
+	defer func() { tx.nestedLevel-- }()
+	name := "dml_nested_" + strconv.Itoa(tx.nestedLevel)
+
+	if err := tx.Savepoint(ctx, name); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := fn(tx); err != nil {
+		if rErr := tx.RollbackToSavepoint(ctx, name); rErr != nil {
+			return errors.Wrapf(rErr, "[dml] Tx.WrapNested rollback failed after error: %s", err)
+		}
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(tx.ReleaseSavepoint(ctx, name))
+}
@@ -0,0 +1,290 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/corestoreio/errors"
+)
+
+// TruncateOptions configures ConnPool.TruncateAll.
+type TruncateOptions struct {
+	// DisableForeignKeyChecks truncates all tables with TRUNCATE TABLE in a
+	// session with disabled foreign key checks. If false, all rows get removed
+	// with DELETE FROM in child-first order of the foreign keys.
+	DisableForeignKeyChecks bool
+	// ResetAutoIncrement sets the auto increment value of each table to one
+	// after the rows have been deleted. Only used without
+	// DisableForeignKeyChecks because TRUNCATE TABLE always resets it.
+	ResetAutoIncrement bool
+}
+
+// SeedOptions configures ConnPool.SeedFromJSON.
+type SeedOptions struct {
+	// DisableForeignKeyChecks inserts all rows in a session with disabled
+	// foreign key checks. If false, parent tables get seeded before their child
+	// tables.
+	DisableForeignKeyChecks bool
+	// BatchSize defines the maximum number of rows per INSERT statement.
+	// Defaults to 100.
+	BatchSize int
+}
+
+// TruncateAll removes all rows from the tables. Either with disabled foreign
+// key checks or in an order where child tables get emptied before their parent
+// tables. The foreign keys get queried from the information_schema of the
+// current database. Useful to bootstrap demo environments or integration tests.
+// Table names get mapped with the TableNameMapper.
+func (c *ConnPool) TruncateAll(ctx context.Context, tables []string, o TruncateOptions) error {
+	for _, t := range tables {
+		if err := IsValidIdentifier(t); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if o.DisableForeignKeyChecks {
+		return c.WithDisabledForeignKeyChecks(ctx, func(conn *Conn) error {
+			for _, t := range tables {
+				if _, err := conn.qep().ExecContext(ctx, "TRUNCATE TABLE "+Quoter.Name(c.mapTableName(t))); err != nil {
+					return errors.Wrapf(err, "[dml] TruncateAll failed for table %q", t)
+				}
+			}
+			return nil
+		})
+	}
+
+	ordered, err := c.foreignKeyOrder(ctx, tables)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, t := range ordered {
+		if _, err := c.DB.ExecContext(ctx, "DELETE FROM "+Quoter.Name(c.mapTableName(t))); err != nil {
+			return errors.Wrapf(err, "[dml] TruncateAll failed for table %q", t)
+		}
+		if o.ResetAutoIncrement {
+			if _, err := c.DB.ExecContext(ctx, "ALTER TABLE "+Quoter.Name(c.mapTableName(t))+" AUTO_INCREMENT = 1"); err != nil {
+				return errors.Wrapf(err, "[dml] TruncateAll failed to reset the auto increment for table %q", t)
+			}
+		}
+	}
+	return nil
+}
+
+// SeedFromJSON inserts rows read from a JSON object whose keys are the table
+// names and whose values are arrays of rows. Each row is an object with the
+// column names as keys:
+//
+//	{
+//		"customer_entity": [{"entity_id": 1, "email": "a@b.c"}],
+//		"customer_address_entity": [{"entity_id": 1, "parent_id": 1}]
+//	}
+//
+// Rows of a table can have different columns, missing columns get their
+// DEFAULT value. Nested objects and arrays get inserted as JSON strings.
+// Numbers retain their precision. Without SeedOptions.DisableForeignKeyChecks
+// the parent tables get seeded first, as defined by the foreign keys in the
+// information_schema of the current database.
+func (c *ConnPool) SeedFromJSON(ctx context.Context, r io.Reader, o SeedOptions) error {
+	var data map[string][]map[string]interface{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return errors.BadEncoding.New(err, "[dml] SeedFromJSON failed to decode the JSON")
+	}
+	if o.BatchSize < 1 {
+		o.BatchSize = 100
+	}
+
+	tables := make([]string, 0, len(data))
+	for t := range data {
+		if err := IsValidIdentifier(t); err != nil {
+			return errors.WithStack(err)
+		}
+		for i, row := range data[t] {
+			if len(row) == 0 {
+				return errors.Empty.Newf("[dml] SeedFromJSON: Row %d of table %q has no columns", i, t)
+			}
+		}
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+
+	if o.DisableForeignKeyChecks {
+		return c.WithDisabledForeignKeyChecks(ctx, func(conn *Conn) error {
+			for _, t := range tables {
+				if err := seedTable(ctx, conn.InsertInto, t, data[t], o.BatchSize); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			return nil
+		})
+	}
+
+	ordered, err := c.foreignKeyOrder(ctx, tables)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for i := len(ordered) - 1; i >= 0; i-- { // parents first
+		t := ordered[i]
+		if err := seedTable(ctx, c.InsertInto, t, data[t], o.BatchSize); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// seedTable inserts the rows in batches. Consecutive rows with the same
+// columns share an INSERT statement, so the omitted columns of a row get their
+// DEFAULT value.
+func seedTable(ctx context.Context, insertInto func(string) *Insert, table string, rows []map[string]interface{}, batchSize int) error {
+	for i := 0; i < len(rows); {
+		columns := make([]string, 0, len(rows[i]))
+		for col := range rows[i] {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+
+		n := 1
+		for n < batchSize && i+n < len(rows) && hasSeedColumns(rows[i+n], columns) {
+			n++
+		}
+		args := make([]interface{}, 0, n*len(columns))
+		for _, row := range rows[i : i+n] {
+			for _, col := range columns {
+				v, err := seedValue(row[col])
+				if err != nil {
+					return errors.Wrapf(err, "[dml] SeedFromJSON failed for table %q and column %q", table, col)
+				}
+				args = append(args, v)
+			}
+		}
+		if _, err := insertInto(table).AddColumns(columns...).SetRowCount(n).WithDBR().ExecContext(ctx, args...); err != nil {
+			return errors.Wrapf(err, "[dml] SeedFromJSON failed for table %q", table)
+		}
+		i += n
+	}
+	return nil
+}
+
+// hasSeedColumns reports whether the row has exactly the columns.
+func hasSeedColumns(row map[string]interface{}, columns []string) bool {
+	if len(row) != len(columns) {
+		return false
+	}
+	for _, col := range columns {
+		if _, ok := row[col]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// seedValue converts a decoded JSON value into a driver compatible argument.
+func seedValue(v interface{}) (interface{}, error) {
+	switch vt := v.(type) {
+	case json.Number:
+		if i, err := vt.Int64(); err == nil {
+			return i, nil
+		}
+		return vt.String(), nil // keeps the precision of decimals
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(vt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return string(b), nil
+	}
+	return v, nil
+}
+
+// foreignKeyOrder returns the tables sorted child-first: a table appears before
+// all tables it references via a foreign key. Self referencing foreign keys get
+// ignored. Cyclic foreign keys return an error of kind NotAcceptable.
+func (c *ConnPool) foreignKeyOrder(ctx context.Context, tables []string) ([]string, error) {
+	parents := make(map[string]map[string]bool, len(tables)) // child => parents
+	mapped := make(map[string]string, len(tables))           // mapped name => name
+	for _, t := range tables {
+		parents[t] = map[string]bool{}
+		mapped[c.mapTableName(t)] = t
+	}
+
+	var child, parent string
+	err := c.WithRawSQL("SELECT `TABLE_NAME`, `REFERENCED_TABLE_NAME` FROM `information_schema`.`KEY_COLUMN_USAGE` WHERE `TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_SCHEMA` = DATABASE() AND `REFERENCED_TABLE_NAME` IS NOT NULL").
+		IterateSerial(ctx, func(cm *ColumnMap) error {
+			for cm.Next() {
+				switch c := cm.Column(); c {
+				case "TABLE_NAME":
+					cm.String(&child)
+				case "REFERENCED_TABLE_NAME":
+					cm.String(&parent)
+				}
+			}
+			if err := cm.Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			ct, okc := mapped[child]
+			pt, okp := mapped[parent]
+			if okc && okp && ct != pt {
+				parents[ct][pt] = true
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, errors.Wrapf(err, "[dml] Failed to query the foreign keys")
+	}
+	return sortChildFirst(tables, parents)
+}
+
+// sortChildFirst sorts topologically with Kahn's algorithm. Tables on the same
+// level keep their order from argument `tables`.
+func sortChildFirst(tables []string, parents map[string]map[string]bool) ([]string, error) {
+	childCount := make(map[string]int, len(tables))
+	for _, ps := range parents {
+		for p := range ps {
+			childCount[p]++
+		}
+	}
+	ordered := make([]string, 0, len(tables))
+	done := make(map[string]bool, len(tables))
+	for len(ordered) < len(tables) {
+		var next []string
+		for _, t := range tables {
+			if !done[t] && childCount[t] == 0 {
+				next = append(next, t)
+			}
+		}
+		if len(next) == 0 {
+			var cyclic []string
+			for _, t := range tables {
+				if !done[t] {
+					cyclic = append(cyclic, t)
+				}
+			}
+			return nil, errors.NotAcceptable.Newf("[dml] Tables %v have cyclic foreign keys. Disable the foreign key checks.", cyclic)
+		}
+		for _, t := range next {
+			done[t] = true
+			for p := range parents[t] {
+				childCount[p]--
+			}
+		}
+		ordered = append(ordered, next...)
+	}
+	return ordered, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/corestoreio/errors"
+)
+
+// Common tag keys of the sqlcommenter specification.
+const (
+	SQLCommentTagRoute       = "route"
+	SQLCommentTagController  = "controller"
+	SQLCommentTagAction      = "action"
+	SQLCommentTagJob         = "job"
+	SQLCommentTagTraceparent = "traceparent"
+)
+
+type ctxSQLCommentTags struct{}
+
+// WithSQLCommentTags modifies a context to carry the tags for WithSQLCommenter.
+// The arguments are key-value pairs and get merged with already existing tags
+// of the context.
+//		ctx = dml.WithSQLCommentTags(ctx, dml.SQLCommentTagRoute, "/checkout/cart", dml.SQLCommentTagController, "cart")
+func WithSQLCommentTags(ctx context.Context, keyValues ...string) context.Context {
+	prev := SQLCommentTags(ctx)
+	tags := make(map[string]string, len(prev)+len(keyValues)/2)
+	for k, v := range prev {
+		tags[k] = v
+	}
+	for i := 1; i < len(keyValues); i += 2 {
+		tags[keyValues[i-1]] = keyValues[i]
+	}
+	return context.WithValue(ctx, ctxSQLCommentTags{}, tags)
+}
+
+// SQLCommentTags returns the tags of the context set by WithSQLCommentTags.
+// The returned map must not be modified.
+func SQLCommentTags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(ctxSQLCommentTags{}).(map[string]string)
+	return tags
+}
+
+// SQLCommenter configures WithSQLCommenter.
+type SQLCommenter struct {
+	// Tags selects the tags of the context to serialize. Empty serializes
+	// all tags.
+	Tags []string
+	// Extract optional function adds further tags of the context, for
+	// example the traceparent of the current span.
+	Extract func(ctx context.Context, tags map[string]string)
+}
+
+// WithSQLCommenter appends the tags of the context as a trailing comment to
+// each statement, so the load in performance_schema or in the slow query log
+// can be attributed to a route, controller or job without joins in the
+// application. The format follows the sqlcommenter specification: keys get
+// sorted, keys and values get URL encoded and values enclosed in single
+// quotes.
+//		SELECT * FROM `sales_order` /*controller='cart',route='%2Fcheckout%2Fcart'*/
+// Prepared statements and contexts without tags are not affected, because
+// varying comments would defeat the statement caches.
+// https://google.github.io/sqlcommenter/spec/
+func WithSQLCommenter(sc SQLCommenter) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 4,
+		fn: func(c *ConnPool) error {
+			for _, t := range sc.Tags {
+				if t == "" {
+					return errors.NotValid.Newf("[dml] WithSQLCommenter tag key cannot be empty: %q", sc.Tags)
+				}
+			}
+			c.sqlCommenter = &sc
+			return nil
+		},
+	}
+}
+
+// withSQLComment appends the tags of the context to sqlStr. See
+// WithSQLCommenter.
+func (bc *builderCommon) withSQLComment(ctx context.Context, sqlStr string) string {
+	sc := bc.sqlCommenter
+	if sc == nil || sqlStr == "" || strings.HasSuffix(sqlStr, "*/") {
+		return sqlStr
+	}
+	ctxTags := SQLCommentTags(ctx)
+	tags := make(map[string]string, len(ctxTags)+1)
+	if len(sc.Tags) == 0 {
+		for k, v := range ctxTags {
+			tags[k] = v
+		}
+	}
+	for _, k := range sc.Tags {
+		if v, ok := ctxTags[k]; ok {
+			tags[k] = v
+		}
+	}
+	if sc.Extract != nil {
+		sc.Extract(ctx, tags)
+	}
+	if len(tags) == 0 {
+		return sqlStr
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	buf.Grow(len(sqlStr) + len(keys)*24)
+	buf.WriteString(sqlStr)
+	buf.WriteString(" /*")
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(url.PathEscape(k)) // escapes ' and * and prevents closing the comment
+		buf.WriteString("='")
+		buf.WriteString(url.PathEscape(tags[k]))
+		buf.WriteByte('\'')
+	}
+	buf.WriteString("*/")
+	return buf.String()
+}
@@ -0,0 +1,73 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWithSQLCommenter(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	assert.ErrorIsKind(t, errors.NotValid, dbc.Options(dml.WithSQLCommenter(dml.SQLCommenter{Tags: []string{""}})))
+	assert.NoError(t, dbc.Options(dml.WithSQLCommenter(dml.SQLCommenter{
+		Tags: []string{dml.SQLCommentTagRoute, dml.SQLCommentTagController},
+		Extract: func(ctx context.Context, tags map[string]string) {
+			if tid, ok := ctx.Value(ctxTraceID{}).(string); ok {
+				tags[dml.SQLCommentTagTraceparent] = tid
+			}
+		},
+	})))
+
+	runUpdate := func(ctx context.Context, wantSQL string) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(wantSQL)).
+			WithArgs(int64(5), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := dbc.Update("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().ExecContext(ctx, int64(5), int64(3))
+		assert.NoError(t, err)
+	}
+
+	t.Run("no tags", func(t *testing.T) {
+		runUpdate(context.Background(), "UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?)")
+	})
+	t.Run("selected tags get escaped", func(t *testing.T) {
+		ctx := dml.WithSQLCommentTags(context.Background(), dml.SQLCommentTagRoute, "/checkout/cart*/", "unselected", "x")
+		ctx = dml.WithSQLCommentTags(ctx, dml.SQLCommentTagController, "it's")
+		assert.Exactly(t, map[string]string{"route": "/checkout/cart*/", "controller": "it's", "unselected": "x"}, dml.SQLCommentTags(ctx))
+		runUpdate(ctx, "UPDATE `stock` SET `qty`=? WHERE (`product_id` = ?) /*controller='it%27s',route='%2Fcheckout%2Fcart%2A%2F'*/")
+	})
+	t.Run("extracted tags", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxTraceID{}, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `qty` FROM `stock` WHERE (`product_id` = ?) /*traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"qty"}).AddRow(5))
+		qty, found, err := dbc.SelectFrom("stock").AddColumns("qty").Where(dml.Column("product_id").PlaceHolder()).
+			WithDBR().LoadNullInt64(ctx, int64(3))
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, int64(5), qty.Int64)
+	})
+}
+
+type ctxTraceID struct{}
@@ -84,3 +84,34 @@ func TestWithDSNfromEnv(t *testing.T) {
 		assert.ErrorIsKind(t, errors.NotExists, err)
 	})
 }
+
+func TestSortChildFirst(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chain and independent", func(t *testing.T) {
+		got, err := sortChildFirst(
+			[]string{"address", "customer", "log", "order_item", "order"},
+			map[string]map[string]bool{
+				"address":    {"customer": true},
+				"customer":   {},
+				"log":        {},
+				"order_item": {"order": true},
+				"order":      {"customer": true},
+			},
+		)
+		assert.NoError(t, err)
+		assert.Exactly(t, []string{"address", "log", "order_item", "order", "customer"}, got)
+	})
+	t.Run("cyclic", func(t *testing.T) {
+		got, err := sortChildFirst(
+			[]string{"a", "b", "c"},
+			map[string]map[string]bool{
+				"a": {"b": true},
+				"b": {"a": true},
+				"c": {},
+			},
+		)
+		assert.ErrorIsKind(t, errors.NotAcceptable, err)
+		assert.Nil(t, got)
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/storage/null"
+)
+
+// WithServerTimeZone declares the time zone in which the database server
+// stores DATETIME values, e.g. the zone of the session variable time_zone.
+// time.Time and null.Time arguments in any location get converted to the wall
+// clock of the server time zone. Scanned time values of a ColumnMap get
+// converted from the server time zone to UTC. This avoids off-by-timezone bugs
+// when comparing e.g. a created_at column with time.Now(). The driver must
+// parse and format time values in UTC, which is the default of the DSN
+// parameter `loc` of the go-sql-driver/mysql. Other locations return a
+// NotAcceptable error.
+func WithServerTimeZone(loc *time.Location) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 4, // must run after WithDSN and WithDB
+		fn: func(c *ConnPool) error {
+			if loc == nil {
+				return errors.NotValid.Newf("[dml] WithServerTimeZone requires a time.Location")
+			}
+			if c.dsn != nil && c.dsn.Loc != nil && c.dsn.Loc != time.UTC {
+				return errors.NotAcceptable.Newf("[dml] WithServerTimeZone requires the DSN parameter loc=UTC but got %q", c.dsn.Loc)
+			}
+			c.serverTimeZone = loc
+			return nil
+		},
+	}
+}
+
+// ServerTimeZone returns the time zone as set by WithServerTimeZone or nil.
+func (c *ConnPool) ServerTimeZone() *time.Location {
+	return c.serverTimeZone
+}
+
+// timeToServer converts t into the wall clock of the server time zone. The
+// returned time is located in UTC because the driver formats in UTC.
+func timeToServer(t time.Time, server *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	t = t.In(server)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// timeFromServer interprets the wall clock of t, as parsed by the driver, in
+// the server time zone and returns it in UTC.
+func timeFromServer(t time.Time, server *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), server).UTC()
+}
+
+// convertTimeArguments converts all time arguments to the server time zone.
+// The slice args of the caller does not get modified, a copy gets returned
+// if any argument must be converted.
+func (a *DBR) convertTimeArguments(args []interface{}) []interface{} {
+	loc := a.base.serverTimeZone
+	if loc == nil {
+		return args
+	}
+	copied := false
+	set := func(i int, v interface{}) {
+		if !copied {
+			args = append(make([]interface{}, 0, len(args)), args...)
+			copied = true
+		}
+		args[i] = v
+	}
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			set(i, timeToServer(v, loc))
+		case null.Time:
+			if v.Valid {
+				v.Time = timeToServer(v.Time, loc)
+				set(i, v)
+			}
+		case []time.Time:
+			ts := make([]time.Time, len(v))
+			for j, t := range v {
+				ts[j] = timeToServer(t, loc)
+			}
+			set(i, ts)
+		case []null.Time:
+			ts := make([]null.Time, len(v))
+			for j, t := range v {
+				if t.Valid {
+					t.Time = timeToServer(t.Time, loc)
+				}
+				ts[j] = t
+			}
+			set(i, ts)
+		}
+	}
+	return args
+}
@@ -20,12 +20,13 @@ import (
 	"database/sql"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/log"
 	"github.com/corestoreio/pkg/storage/null"
 	"github.com/corestoreio/pkg/util/bufferpool"
-	"github.com/corestoreio/pkg/util/byteconv"
+	"github.com/corestoreio/pkg/util/conv"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -56,8 +57,44 @@ type DBR struct {
 	insertColumnCount   uint
 	tupleRowCount       uint
 	insertIsBuildValues bool
+	// insertRowAlias if true, the VALUES tuples must be written before the
+	// row alias of the ON DUPLICATE KEY clause.
+	insertRowAlias bool
+	// insertRowConstructor if true, writes the VALUES tuples as ROW(?,?).
+	insertRowConstructor bool
+	// insertChunkSize if greater zero, splits the execution of an INSERT
+	// statement. See Insert.WithChunkSize.
+	insertChunkSize int
 	// isPrepared if true the cachedSQL field in base gets ignored
 	isPrepared bool
+	// reuseGuard, executed and lastArgs detect stale arguments and double
+	// executions. See ReuseGuard.
+	reuseGuard bool
+	executed   bool
+	lastArgs   []interface{}
+	// resultSizeLimit and resultSize account the scanned bytes of a query. See
+	// WithResultSizeLimit.
+	resultSizeLimit uint64
+	resultSize      uint64
+	// resultCache and resultCacheTTL cache the result sets of Load. See
+	// WithResultCache.
+	resultCache    ResultCacher
+	resultCacheTTL time.Duration
+	// resultCacheTimeout limits the query filling the result cache. See
+	// WithResultCacheTimeout.
+	resultCacheTimeout time.Duration
+	// tables contains the names of the tables used in the statement. Used for
+	// the read-your-writes bypass of the result cache and the column
+	// compression.
+	tables []string
+	// tablesKnown if false, the tables of the statement can't be determined,
+	// like for raw SQL.
+	tablesKnown bool
+	// idempotent allows the RetryPolicy to retry the statement. See
+	// Idempotent.
+	idempotent bool
+	// forcePrimary disables the routing to a replica. See ForcePrimary.
+	forcePrimary bool
 	// Options like enable interpolation or expanding placeholders.
 	Options uint
 }
@@ -203,6 +240,48 @@ func (a *DBR) ExpandPlaceHolders() *DBR {
 	return a
 }
 
+// options returns the Options including the interpolation set via
+// ConnPool.WithDefaultInterpolate. The default applies only to statements with
+// arguments which are not prepared.
+func (a *DBR) options(lenExtArgs int) uint {
+	if lenExtArgs > 0 && !a.isPrepared && a.base.interpolate.enabled() {
+		return a.Options | argOptionInterpolate
+	}
+	return a.Options
+}
+
+// ErrResultTooLarge gets returned by Load, the Load*s functions,
+// IterateSerial and IterateParallel when the scanned bytes of a result set
+// exceed the limit set with WithResultSizeLimit.
+var ErrResultTooLarge = errors.OutOfRange.Newf("[dml] The result set is too large")
+
+// WithResultSizeLimit sets the maximum approximate amount of bytes which Load,
+// the Load*s functions, IterateSerial and IterateParallel are allowed to scan.
+// A row exceeding the limit does not get appended to the result. Exceeding the limit
+// aborts the query with ErrResultTooLarge. Protects e.g. API servers from
+// loading large BLOB columns by accident. Zero disables the limit.
+func (a *DBR) WithResultSizeLimit(maxBytes uint64) *DBR {
+	a.resultSizeLimit = maxBytes
+	return a
+}
+
+// ResultSize returns the approximate amount of bytes scanned by the last call
+// to Load, a Load*s function, IterateSerial or IterateParallel. See
+// ColumnMap.ScannedBytes.
+func (a *DBR) ResultSize() uint64 {
+	return a.resultSize
+}
+
+// addResultSize adds the bytes of a scanned row to the result size and checks
+// the limit before the row gets appended.
+func (a *DBR) addResultSize(n uint64) error {
+	a.resultSize += n
+	if a.resultSizeLimit > 0 && a.resultSize > a.resultSizeLimit {
+		return errors.Wrapf(ErrResultTooLarge, "[dml] DBR: %d scanned bytes exceed the limit of %d bytes", a.resultSize, a.resultSizeLimit)
+	}
+	return nil
+}
+
 // prepareQueryAndArgs transforms mainly the DBR into []interface{}. It appends
 // its arguments to the `extArgs` arguments from the Exec+ or Query+ function.
 // This allows for a developer to reuse the interface slice and save
@@ -214,13 +293,14 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 		return "", nil, errors.WithStack(a.base.ärgErr)
 	}
 	lenExtArgs := len(extArgs)
+	opts := a.options(lenExtArgs)
 	var hasNamedArgs uint8
 	var containsQualifiedRecords int
 	var primitiveCounts int
 	var args []interface{}
 	if lenExtArgs > 0 {
 		args = pooledInterfacesGet()
-		defer pooledInterfacesPut(args)
+		defer pooledInterfacesPut(args, a.reuseGuard)
 
 		for _, ea := range extArgs {
 			switch eaTypeValue := ea.(type) {
@@ -240,13 +320,18 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 					primitiveCounts++
 				}
 			default:
+				if sm, ok := asStructMapper(ea); ok {
+					containsQualifiedRecords++
+					args = append(args, sm)
+					continue
+				}
 				args = append(args, ea)
 				primitiveCounts++
 			}
 		}
 	}
 	if a.base.source == dmlSourceInsert {
-		return a.prepareQueryAndArgsInsert(args, primitiveCounts)
+		return a.prepareQueryAndArgsInsert(args, primitiveCounts, opts)
 	}
 
 	cachedSQL, ok := a.base.cachedSQL[a.base.cacheKey]
@@ -255,19 +340,20 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 	}
 
 	if a.base.templateStmtCount < 2 && hasNamedArgs == 0 && containsQualifiedRecords == 0 &&
-		a.Options == 0 && !a.base.containsTuples { // no options and qualified records provided
+		opts == 0 && !a.base.containsTuples { // no options and qualified records provided
+		args = a.convertTimeArguments(args)
 
 		if a.isPrepared {
 			return "", expandInterfaces(args), nil
 		}
 
-		if a.Options == 0 && len(a.OrderBys) == 0 && !a.LimitValid {
+		if opts == 0 && len(a.OrderBys) == 0 && !a.LimitValid {
 			return cachedSQL, expandInterfaces(args), nil
 		}
 		buf := bufferpool.Get()
 		defer bufferpool.Put(buf)
 		buf.WriteString(cachedSQL)
-		sqlWriteOrderBy(buf, a.OrderBys, false)
+		sqlWriteOrderBy(buf, a.base.sqlDialect(), a.OrderBys, false)
 		sqlWriteLimitOffset(buf, a.LimitValid, a.OffsetValid, a.OffsetCount, a.LimitCount)
 		return buf.String(), expandInterfaces(args), nil
 	}
@@ -288,6 +374,7 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 	if args, err = a.appendConvertedRecordsToArguments(hasNamedArgs, args, containsQualifiedRecords); err != nil {
 		return "", nil, errors.WithStack(err)
 	}
+	args = a.convertTimeArguments(args)
 
 	if a.isPrepared {
 		return "", expandInterfaces(args), nil
@@ -301,11 +388,11 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 		return "", nil, errors.WithStack(err)
 	}
 
-	sqlWriteOrderBy(sqlBuf.First, a.OrderBys, false)
+	sqlWriteOrderBy(sqlBuf.First, a.base.sqlDialect(), a.OrderBys, false)
 	sqlWriteLimitOffset(sqlBuf.First, a.LimitValid, a.OffsetValid, a.OffsetCount, a.LimitCount)
 
 	// `switch` statement no suitable.
-	if a.Options > 0 && lenExtArgs > 0 && containsQualifiedRecords == 0 && len(args) == 0 {
+	if opts > 0 && lenExtArgs > 0 && containsQualifiedRecords == 0 && len(args) == 0 {
 		return "", nil, errors.NotAllowed.Newf("[dml] Interpolation/ExpandPlaceholders supports only Records and Arguments and not yet an interface slice.")
 	}
 	if a.base.containsTuples {
@@ -319,7 +406,7 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 	}
 
 	// TODO more advanced caching of the final non-expanded SQL string
-	if a.Options&argOptionExpandPlaceholder != 0 {
+	if opts&argOptionExpandPlaceholder != 0 {
 		phCount := bytes.Count(sqlBuf.First.Bytes(), placeHolderByte)
 		if aLen, hasSlice := totalSliceLen(args); phCount < aLen || hasSlice {
 			// if a.base.containsTuples {
@@ -338,8 +425,8 @@ func (a *DBR) prepareQueryAndArgs(extArgs []interface{}) (_ string, _ []interfac
 			}
 		}
 	}
-	if a.Options&argOptionInterpolate != 0 {
-		if err := writeInterpolateBytes(sqlBuf.Second, sqlBuf.First.Bytes(), args); err != nil {
+	if opts&argOptionInterpolate != 0 {
+		if err := writeInterpolateBytes(sqlBuf.Second, a.base.sqlDialect(), sqlBuf.First.Bytes(), args); err != nil {
 			return "", nil, errors.Wrapf(err, "[dml] Interpolation failed: %q", sqlBuf.String())
 		}
 		return sqlBuf.Second.String(), nil, nil
@@ -379,7 +466,9 @@ func (a *DBR) appendConvertedRecordsToArguments(hasNamedArgs uint8, collectedArg
 	var nextUnnamedArgPos int
 	// TODO refactor prototype and make it performant and beautiful code
 	cm := NewColumnMap(len(collectedArgs)+containsQualifiedRecords, "") // can use an arg pool DBR sync.Pool, nope.
-	for tsc := 0; tsc < templateStmtCount; tsc++ {                      // only in case of UNION statements in combination with a template SELECT, can be optimized later
+	cm.compression = a.columnCompression()
+	cm.encryption = a.base.encryption
+	for tsc := 0; tsc < templateStmtCount; tsc++ { // only in case of UNION statements in combination with a template SELECT, can be optimized later
 
 		// `qualifiedColumns` contains the correct order as the place holders
 		// appear in the SQL string.
@@ -435,6 +524,9 @@ func (a *DBR) appendConvertedRecordsToArguments(hasNamedArgs uint8, collectedArg
 		}
 		nextUnnamedArgPos = 0
 	}
+	if err := sealEncrypted(cm.args, qualifiedColumns); err != nil {
+		return collectedArgs, errors.WithStack(err)
+	}
 	if len(cm.args) > 0 {
 		collectedArgs = cm.args
 	}
@@ -445,11 +537,13 @@ func (a *DBR) appendConvertedRecordsToArguments(hasNamedArgs uint8, collectedArg
 // prepareQueryAndArgsInsert prepares the special arguments for an INSERT statement. The
 // returned interface slice is the same as the `extArgs` slice. extArgs =
 // external arguments.
-func (a *DBR) prepareQueryAndArgsInsert(extArgs []interface{}, primitiveCounts int) (string, []interface{}, error) {
+func (a *DBR) prepareQueryAndArgsInsert(extArgs []interface{}, primitiveCounts int, opts uint) (string, []interface{}, error) {
 	sqlBuf := bufferpool.GetTwin()
 	defer bufferpool.PutTwin(sqlBuf)
 	cm := NewColumnMap(2*primitiveCounts, a.base.qualifiedColumns...)
-	cm.args = extArgs
+	cm.compression = a.columnCompression()
+	cm.encryption = a.base.encryption
+	cm.args = append(cm.args, extArgs...) // the records get appended, so copy the arguments of the caller
 	lenExtArgsBefore := len(extArgs)
 	lenInsertCachedSQL := len(a.insertCachedSQL)
 	cachedSQL, _ := a.base.cachedSQL[a.base.cacheKey]
@@ -480,15 +574,25 @@ func (a *DBR) prepareQueryAndArgsInsert(extArgs []interface{}, primitiveCounts i
 			}
 		}
 		primitiveCounts += len(cm.args) - lenExtArgsBefore
+		if err := sealEncrypted(cm.args[lenExtArgsBefore:], a.base.qualifiedColumns); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
 	}
+	cm.args = a.convertTimeArguments(cm.args)
 
 	if a.isPrepared {
+		if containsDefaultArg(cm.args) {
+			return "", nil, errors.NotSupported.Newf("[dml] The DEFAULT keyword is not supported in prepared INSERT statements.")
+		}
 		// TODO above construct can be more optimized when using prepared statements
 		return "", expandInterfaces(cm.args), nil
 	}
 
 	if !a.insertIsBuildValues && lenInsertCachedSQL == 0 { // Write placeholder list e.g. "VALUES (?,?),(?,?)"
 		odkPos := strings.Index(cachedSQL, onDuplicateKeyPartS)
+		if odkPos > 0 && a.insertRowAlias {
+			odkPos = strings.LastIndex(cachedSQL[:odkPos], rowAliasPartS)
+		}
 		if odkPos > 0 {
 			sqlBuf.First.Reset()
 			sqlBuf.First.WriteString(cachedSQL[:odkPos])
@@ -496,13 +600,13 @@ func (a *DBR) prepareQueryAndArgsInsert(extArgs []interface{}, primitiveCounts i
 
 		if a.tupleRowCount > 0 {
 			columnCount := uint(primitiveCounts) / a.tupleRowCount
-			writeTuplePlaceholders(sqlBuf.First, a.tupleRowCount, columnCount)
+			writeTuplePlaceholders(sqlBuf.First, a.tupleRowCount, columnCount, a.insertRowConstructor)
 		} else if a.insertColumnCount > 0 {
 			rowCount := uint(primitiveCounts) / a.insertColumnCount
 			if rowCount == 0 {
 				rowCount = 1
 			}
-			writeTuplePlaceholders(sqlBuf.First, rowCount, a.insertColumnCount)
+			writeTuplePlaceholders(sqlBuf.First, rowCount, a.insertColumnCount, a.insertRowConstructor)
 		}
 		if odkPos > 0 {
 			sqlBuf.First.WriteString(cachedSQL[odkPos:])
@@ -512,19 +616,27 @@ func (a *DBR) prepareQueryAndArgsInsert(extArgs []interface{}, primitiveCounts i
 		a.insertCachedSQL = sqlBuf.First.String()
 	}
 
-	if a.Options > 0 {
+	if opts > 0 {
 		if primitiveCounts > 0 && !containsRecords && len(cm.args) == 0 {
 			return "", nil, errors.NotAllowed.Newf("[dml] Interpolation/ExpandPlaceholders supports only Records and Arguments and not yet an interface slice.")
 		}
 
-		if a.Options&argOptionInterpolate != 0 {
-			if err := writeInterpolateBytes(sqlBuf.Second, sqlBuf.First.Bytes(), cm.args); err != nil {
+		if opts&argOptionInterpolate != 0 {
+			if err := writeInterpolateBytes(sqlBuf.Second, a.base.sqlDialect(), sqlBuf.First.Bytes(), cm.args); err != nil {
 				return "", nil, errors.Wrapf(err, "[dml] Interpolation failed: %q", sqlBuf.First.String())
 			}
 			return sqlBuf.Second.String(), nil, nil
 		}
 	}
 
+	if containsDefaultArg(cm.args) {
+		// The position of DEFAULT can change with each execution, so the
+		// insertCachedSQL must be kept with all its place holders.
+		sqlBuf.Second.Reset()
+		args := writeDefaultPlaceHolders(sqlBuf.Second, a.insertCachedSQL, cm.args)
+		return sqlBuf.Second.String(), expandInterfaces(args), nil
+	}
+
 	return a.insertCachedSQL, expandInterfaces(cm.args), nil
 }
 
@@ -558,6 +670,8 @@ func (a *DBR) mapColumns(containsQualifiedRecords int, args []interface{}, cm *C
 	var cm2 *ColumnMap
 	if containsQualifiedRecords > 0 {
 		cm2 = NewColumnMap(1)
+		cm2.compression = cm.compression
+		cm2.encryption = cm.encryption
 	}
 	for cm.Next() {
 		// now a bit slow ...
@@ -579,8 +693,17 @@ func (a *DBR) mapColumns(containsQualifiedRecords int, args []interface{}, cm *C
 				// in the upper switch case we compare Name==c and so we know which
 				// column but with the mapper we don't know.
 				_ = at.Record.MapColumns(cm2)
+				if err := cm2.Err(); err != nil {
+					return errors.WithStack(err)
+				}
 				cm.args = append(cm.args, cm2.args...)
 				// do not break the loop like in the upper switch case.
+			case ColumnMapper:
+				_ = at.MapColumns(cm2)
+				if err := cm2.Err(); err != nil {
+					return errors.WithStack(err)
+				}
+				cm.args = append(cm.args, cm2.args...)
 			}
 		}
 	}
@@ -590,10 +713,12 @@ func (a *DBR) mapColumns(containsQualifiedRecords int, args []interface{}, cm *C
 // Reset resets the internal slices for new usage retaining the already
 // allocated memory. Reset gets called automatically in many Load* functions. In
 // case of an INSERT statement, Reset triggers a new build of the VALUES part.
-// This function must be called when the number of argument changes.
+// This function must be called when the number of argument changes. Reset
+// also clears the state of the ReuseGuard.
 func (a *DBR) Reset() *DBR {
 	a.insertIsBuildValues = false
 	a.insertCachedSQL = a.insertCachedSQL[:0]
+	a.resetReuse()
 	return a
 }
 
@@ -605,7 +730,7 @@ func (a *DBR) WithDB(db QueryExecPreparer) *DBR {
 
 // WithPreparedStmt uses a SQL statement as DB connection.
 func (a *DBR) WithPreparedStmt(stmt *sql.Stmt) *DBR {
-	a.base.db = stmtWrapper{stmt: stmt}
+	a.base.db = stmtWrapper{stmt: stmt, inTx: isTxBound(a.base.db)}
 	return a
 }
 
@@ -629,6 +754,8 @@ func (a *DBR) Clone() *DBR {
 		c.QualifiedColumnsAliases = make([]string, len(a.QualifiedColumnsAliases))
 		copy(c.QualifiedColumnsAliases, a.QualifiedColumnsAliases)
 	}
+	c.executed = false
+	c.lastArgs = nil
 	return &c
 }
 
@@ -685,8 +812,11 @@ func pooledInterfacesGet() []interface{} {
 	return pooledInterfaces.Get().([]interface{})
 }
 
-func pooledInterfacesPut(args []interface{}) {
+func pooledInterfacesPut(args []interface{}, poison bool) {
 	if cap(args) <= argumentPoolMaxSize {
+		if poison {
+			poisonArguments(args)
+		}
 		args = args[:0]
 		pooledInterfaces.Put(args)
 	}
@@ -703,6 +833,9 @@ func pooledInterfacesPut(args []interface{}) {
 // function ExecValidateOneAffectedRow to check if the underlying SQL statement
 // has affected only one row.
 func (a *DBR) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	if a.insertChunkSize > 0 && a.base.source == dmlSourceInsert && !a.isPrepared {
+		return a.execInsertChunks(ctx, args)
+	}
 	return a.exec(ctx, args)
 }
 
@@ -713,7 +846,7 @@ func (a *DBR) QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows,
 
 // QueryRowContext traditional way of the databasel/sql package.
 func (a *DBR) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row {
-	sqlStr, args, err := a.prepareQueryAndArgs(args)
+	sqlStr, args, err := a.prepareExecution(args)
 	if a.base.Log != nil && a.base.Log.IsDebug() {
 		defer log.WhenDone(a.base.Log).Debug(
 			"QueryRowContext",
@@ -721,9 +854,41 @@ func (a *DBR) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row
 			log.String("source", string(a.base.source)),
 			log.Err(err))
 	}
-	return a.base.db.QueryRowContext(ctx, sqlStr, args...)
+	if len(a.base.hooks) > 0 {
+		ev := a.base.hookEvent("QueryRow", sqlStr, len(args))
+		var start time.Time
+		ctx, start = a.base.hooks.before(ctx, ev)
+		defer a.base.hooks.after(ctx, ev, start, nil)
+	}
+	if err != nil {
+		// sql.Row cannot be created outside of database/sql, so the error gets
+		// returned by Row.Scan via the canceled context.
+		return a.base.db.QueryRowContext(errContext{Context: ctx, err: err}, sqlStr, args...)
+	}
+	bc := a.readBase()
+	sqlStr, db, err := bc.withDeadlineLockWait(ctx, bc.withSQLComment(ctx, sqlStr))
+	if err != nil {
+		return a.base.db.QueryRowContext(errContext{Context: ctx, err: err}, sqlStr, args...)
+	}
+	return db.QueryRowContext(ctx, sqlStr, args...)
+}
+
+// errContext is a canceled context which returns err. database/sql checks the
+// context before it acquires a connection, so no query gets sent.
+type errContext struct {
+	context.Context
+	err error
 }
 
+func (errContext) Done() <-chan struct{} { return closedChan }
+func (c errContext) Err() error          { return c.err }
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
 // IterateSerial iterates in serial order over the result set by loading one row each
 // iteration and then discarding it. Handles records one by one. The context
 // gets only used in the Query function.
@@ -741,7 +906,12 @@ func (a *DBR) IterateSerial(ctx context.Context, callBack func(*ColumnMap) error
 		return
 	}
 	cmr := pooledColumnMapGet() // this sync.Pool might not work correctly, write a complex test.
+	cmr.serverTimeZone = a.base.serverTimeZone
+	cmr.compression = a.columnCompression()
+	cmr.encryption = a.base.encryption
+	cmr.maxScannedBytes = a.resultSizeLimit
 	defer pooledBufferColumnMapPut(cmr, nil, func() {
+		a.resultSize = cmr.ScannedBytes
 		// Not testable with the sqlmock package :-(
 		if err2 := r.Close(); err2 != nil && err == nil {
 			err = errors.Wrap(err2, "[dml] IterateSerial.QueryContext.Rows.Close")
@@ -765,7 +935,7 @@ func (a *DBR) IterateSerial(ctx context.Context, callBack func(*ColumnMap) error
 // iterateParallelForNextLoop has been extracted from IterateParallel to not
 // mess around with closing channels in different locations of the source code
 // when an error occurs.
-func iterateParallelForNextLoop(ctx context.Context, r *sql.Rows, rowChan chan<- *ColumnMap) (err error) {
+func (a *DBR) iterateParallelForNextLoop(ctx context.Context, r *sql.Rows, rowChan chan<- *ColumnMap) (err error) {
 	defer func() {
 		if err2 := r.Err(); err2 != nil && err == nil {
 			err = errors.WithStack(err)
@@ -776,9 +946,20 @@ func iterateParallelForNextLoop(ctx context.Context, r *sql.Rows, rowChan chan<-
 	}()
 
 	var idx uint64
+	a.resultSize = 0
+	compression := a.columnCompression()
 	for r.Next() {
-		var cm ColumnMap // must be empty because we're not collecting data
-		if errS := cm.Scan(r); errS != nil {
+		// must be empty because we're not collecting data
+		cm := ColumnMap{
+			serverTimeZone:  a.base.serverTimeZone,
+			compression:     compression,
+			encryption:      a.base.encryption,
+			ScannedBytes:    a.resultSize,
+			maxScannedBytes: a.resultSizeLimit,
+		}
+		errS := cm.Scan(r)
+		a.resultSize = cm.ScannedBytes
+		if errS != nil {
 			err = errors.WithStack(errS)
 			return
 		}
@@ -831,12 +1012,15 @@ func (a *DBR) IterateParallel(ctx context.Context, concurrencyLevel int, callBac
 		})
 	}
 
-	if err2 := iterateParallelForNextLoop(ctx, r, rowChan); err2 != nil {
+	if err2 := a.iterateParallelForNextLoop(ctx, r, rowChan); err2 != nil {
 		err = err2
 	}
 	close(rowChan)
 
-	return errors.WithStack(g.Wait())
+	if err2 := g.Wait(); err2 != nil {
+		return errors.WithStack(err2)
+	}
+	return errors.WithStack(err)
 }
 
 // Load loads data from a query into an object. Load can load a single row or
@@ -846,6 +1030,9 @@ func (a *DBR) Load(ctx context.Context, s ColumnMapper, args ...interface{}) (ro
 	if a.base.Log != nil && a.base.Log.IsDebug() {
 		defer log.WhenDone(a.base.Log).Debug("Load", log.String("id", a.base.id), log.Err(err), log.ObjectTypeOf("ColumnMapper", s), log.Uint64("row_count", rowCount))
 	}
+	if a.resultCache != nil && !isTxBound(a.base.db) && !resultCacheBypassed(ctx, a.tables, a.tablesKnown) {
+		return a.loadResultCache(ctx, s, args)
+	}
 
 	r, err := a.query(ctx, args)
 	if err != nil {
@@ -853,7 +1040,12 @@ func (a *DBR) Load(ctx context.Context, s ColumnMapper, args ...interface{}) (ro
 		return
 	}
 	cm := pooledColumnMapGet()
+	cm.serverTimeZone = a.base.serverTimeZone
+	cm.compression = a.columnCompression()
+	cm.encryption = a.base.encryption
+	cm.maxScannedBytes = a.resultSizeLimit
 	defer pooledBufferColumnMapPut(cm, nil, func() {
+		a.resultSize = cm.ScannedBytes
 		// Not testable with the sqlmock package :-(
 		if err2 := r.Close(); err2 != nil && err == nil {
 			err = errors.Wrap(err2, "[dml] DBR.Load.Rows.Close")
@@ -949,6 +1141,16 @@ func (a *DBR) loadPrimitive(ctx context.Context, ptr interface{}, args ...interf
 		}
 		found = true
 	}
+	if found && a.base.serverTimeZone != nil {
+		switch t := ptr.(type) {
+		case *null.Time:
+			if t.Valid {
+				t.Time = timeFromServer(t.Time, a.base.serverTimeZone)
+			}
+		case *time.Time:
+			*t = timeFromServer(*t, a.base.serverTimeZone)
+		}
+	}
 	if err = rows.Err(); err != nil {
 		err = errors.WithStack(err)
 	}
@@ -968,6 +1170,7 @@ func (a *DBR) LoadInt64s(ctx context.Context, dest []int64, args ...interface{})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	a.resultSize = 0
 	defer func() {
 		if cErr := r.Close(); err == nil && cErr != nil {
 			err = errors.WithStack(cErr)
@@ -978,7 +1181,10 @@ func (a *DBR) LoadInt64s(ctx context.Context, dest []int64, args ...interface{})
 		if err = r.Scan(&nv); err != nil {
 			return nil, errors.WithStack(err)
 		}
-		if i64, ok, err := byteconv.ParseInt(nv); ok && err == nil {
+		if err = a.addResultSize(uint64(len(nv))); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if i64, ok, err := conv.ParseInt64(nv); ok && err == nil {
 			dest = append(dest, i64)
 		} else if err != nil {
 			return nil, errors.WithStack(err)
@@ -1005,6 +1211,7 @@ func (a *DBR) LoadUint64s(ctx context.Context, dest []uint64, args ...interface{
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	a.resultSize = 0
 	defer func() {
 		if errC := rows.Close(); errC != nil && err == nil {
 			err = errors.WithStack(errC)
@@ -1016,7 +1223,10 @@ func (a *DBR) LoadUint64s(ctx context.Context, dest []uint64, args ...interface{
 		if err = rows.Scan(&nv); err != nil {
 			return nil, errors.WithStack(err)
 		}
-		if u64, ok, err := byteconv.ParseUint(nv, 10, 64); ok && err == nil {
+		if err = a.addResultSize(uint64(len(nv))); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if u64, ok, err := conv.ParseUint64(nv); ok && err == nil {
 			dest = append(dest, u64)
 		} else if err != nil {
 			return nil, errors.WithStack(err)
@@ -1041,6 +1251,7 @@ func (a *DBR) LoadFloat64s(ctx context.Context, dest []float64, args ...interfac
 	if rows, err = a.query(ctx, args); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	a.resultSize = 0
 	defer func() {
 		if errC := rows.Close(); errC != nil && err == nil {
 			err = errors.WithStack(errC)
@@ -1052,7 +1263,10 @@ func (a *DBR) LoadFloat64s(ctx context.Context, dest []float64, args ...interfac
 		if err = rows.Scan(&nv); err != nil {
 			return nil, errors.WithStack(err)
 		}
-		if f64, ok, err := byteconv.ParseFloat(nv); ok && err == nil {
+		if err = a.addResultSize(uint64(len(nv))); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if f64, ok, err := conv.ParseFloat64(nv); ok && err == nil {
 			dest = append(dest, f64)
 		} else if err != nil {
 			return nil, errors.WithStack(err)
@@ -1077,6 +1291,7 @@ func (a *DBR) LoadStrings(ctx context.Context, dest []string, args ...interface{
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	a.resultSize = 0
 	defer func() {
 		if errC := rows.Close(); errC != nil && err == nil {
 			err = errors.WithStack(errC)
@@ -1088,6 +1303,9 @@ func (a *DBR) LoadStrings(ctx context.Context, dest []string, args ...interface{
 		if err = rows.Scan(&value); err != nil {
 			return nil, errors.WithStack(err)
 		}
+		if err = a.addResultSize(uint64(len(value))); err != nil {
+			return nil, errors.WithStack(err)
+		}
 		if value != nil {
 			dest = append(dest, string(value))
 		}
@@ -1099,16 +1317,170 @@ func (a *DBR) LoadStrings(ctx context.Context, dest []string, args ...interface{
 	return dest, err
 }
 
-func (a *DBR) query(ctx context.Context, args []interface{}) (rows *sql.Rows, err error) {
-	sqlStr, args, err := a.prepareQueryAndArgs(args)
+// LoadNullInt64s executes the query and returns the values appended to slice
+// dest. Contrary to LoadInt64s, NULL values are included as invalid
+// null.Int64 so that the position of each value matches its row.
+func (a *DBR) LoadNullInt64s(ctx context.Context, dest []null.Int64, args ...interface{}) (_ []null.Int64, err error) {
+	err = a.loadNullSlice(ctx, "LoadNullInt64s", args, func(rows *sql.Rows) error {
+		var nv null.Int64
+		if err := rows.Scan(&nv); err != nil {
+			return err
+		}
+		if err := a.addResultSize(8); err != nil {
+			return err
+		}
+		dest = append(dest, nv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// LoadNullUint64s executes the query and returns the values appended to slice
+// dest. Contrary to LoadUint64s, NULL values are included as invalid
+// null.Uint64 so that the position of each value matches its row.
+func (a *DBR) LoadNullUint64s(ctx context.Context, dest []null.Uint64, args ...interface{}) (_ []null.Uint64, err error) {
+	err = a.loadNullSlice(ctx, "LoadNullUint64s", args, func(rows *sql.Rows) error {
+		var nv null.Uint64
+		if err := rows.Scan(&nv); err != nil {
+			return err
+		}
+		if err := a.addResultSize(8); err != nil {
+			return err
+		}
+		dest = append(dest, nv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// LoadNullFloat64s executes the query and returns the values appended to slice
+// dest. Contrary to LoadFloat64s, NULL values are included as invalid
+// null.Float64 so that the position of each value matches its row.
+func (a *DBR) LoadNullFloat64s(ctx context.Context, dest []null.Float64, args ...interface{}) (_ []null.Float64, err error) {
+	err = a.loadNullSlice(ctx, "LoadNullFloat64s", args, func(rows *sql.Rows) error {
+		var nv null.Float64
+		if err := rows.Scan(&nv); err != nil {
+			return err
+		}
+		if err := a.addResultSize(8); err != nil {
+			return err
+		}
+		dest = append(dest, nv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// LoadNullStrings executes the query and returns the values appended to slice
+// dest. Contrary to LoadStrings, NULL values are included as invalid
+// null.String so that the position of each value matches its row.
+func (a *DBR) LoadNullStrings(ctx context.Context, dest []null.String, args ...interface{}) (_ []null.String, err error) {
+	err = a.loadNullSlice(ctx, "LoadNullStrings", args, func(rows *sql.Rows) error {
+		var nv null.String
+		if err := rows.Scan(&nv); err != nil {
+			return err
+		}
+		if err := a.addResultSize(uint64(len(nv.Data))); err != nil {
+			return err
+		}
+		dest = append(dest, nv)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// loadNullSlice runs the query and calls scan for each row.
+func (a *DBR) loadNullSlice(ctx context.Context, logName string, args []interface{}, scan func(*sql.Rows) error) (err error) {
+	var rowCount int
 	if a.base.Log != nil && a.base.Log.IsDebug() {
-		defer log.WhenDone(a.base.Log).Debug(
-			"Query", log.String("sql", sqlStr), log.Int("length_args", len(args)), log.String("source", string(a.base.source)), log.Err(err))
+		// do not use fullSQL because we might log sensitive data
+		ld := log.WhenDone(a.base.Log)
+		defer func() {
+			ld.Debug(logName, log.Int("row_count", rowCount), log.String("id", a.base.id), log.Err(err))
+		}()
+	}
+
+	rows, err := a.query(ctx, args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	a.resultSize = 0
+	defer func() {
+		if errC := rows.Close(); errC != nil && err == nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return errors.WithStack(err)
+		}
+		rowCount++
+	}
+	return errors.WithStack(rows.Err())
+}
+
+func (a *DBR) query(ctx context.Context, args []interface{}) (rows *sql.Rows, err error) {
+	sqlStr, args, err := a.prepareExecution(args)
+	return a.queryPrepared(ctx, sqlStr, args, err)
+}
+
+// prepareExecution calls prepareQueryAndArgs and runs the checks which apply
+// before a statement gets sent to the server. Every function which executes
+// the statement must use it instead of prepareQueryAndArgs.
+func (a *DBR) prepareExecution(extArgs []interface{}) (string, []interface{}, error) {
+	if a.reuseGuard {
+		if err := a.checkReuse(extArgs); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+	}
+	sqlStr, args, err := a.prepareQueryAndArgs(extArgs)
+	if err != nil {
+		return sqlStr, args, errors.WithStack(err)
+	}
+	if a.base.allowList != nil {
+		if err := a.checkAllowList(sqlStr); err != nil {
+			return sqlStr, args, errors.WithStack(err)
+		}
+	}
+	return sqlStr, args, nil
+}
+
+// queryPrepared executes the query with the SQL string and the arguments
+// returned from prepareExecution.
+func (a *DBR) queryPrepared(ctx context.Context, sqlStr string, args []interface{}, err error) (rows *sql.Rows, _ error) {
+	if a.base.isObserved() {
+		defer func(start time.Time, fields ...log.Field) {
+			d := a.base.observeDuration("Query", start, err, fields...)
+			a.base.logSlowQuery(ctx, "Query", d, sqlStr, args, err, fields...)
+		}(log.Now(), log.String("sql", sqlStr), log.Int("length_args", len(args)), log.String("source", string(a.base.source)))
 	}
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	rows, err = a.base.db.QueryContext(ctx, sqlStr, args...)
+	if len(a.base.hooks) > 0 {
+		ev := a.base.hookEvent("Query", sqlStr, len(args))
+		var start time.Time
+		ctx, start = a.base.hooks.before(ctx, ev)
+		defer func() { a.base.hooks.after(ctx, ev, start, err) }()
+	}
+	bc := a.readBase()
+	err = a.base.withRetry(ctx, "Query", a.isIdempotent(sqlStr), func() (err error) {
+		rows, err = bc.queryContext(ctx, sqlStr, args)
+		return err
+	})
 	if err != nil {
 		if sqlStr == "" {
 			cachedSQL, _ := a.base.cachedSQL[a.base.cacheKey]
@@ -1120,20 +1492,32 @@ func (a *DBR) query(ctx context.Context, args []interface{}) (rows *sql.Rows, er
 }
 
 func (a *DBR) exec(ctx context.Context, rawArgs []interface{}) (result sql.Result, err error) {
-	sqlStr, args, err := a.prepareQueryAndArgs(rawArgs)
-	if a.base.Log != nil && a.base.Log.IsDebug() {
-		defer log.WhenDone(a.base.Log).Debug("Exec", log.String("sql", sqlStr),
-			log.Int("length_args", len(args)), log.Int("length_raw_args", len(rawArgs)), log.String("source", string(a.base.source)),
-			log.Err(err))
+	sqlStr, args, err := a.prepareExecution(rawArgs)
+	if a.base.isObserved() {
+		defer func(start time.Time, fields ...log.Field) {
+			d := a.base.observeDuration("Exec", start, err, fields...)
+			a.base.logSlowQuery(ctx, "Exec", d, sqlStr, args, err, fields...)
+		}(log.Now(), log.String("sql", sqlStr),
+			log.Int("length_args", len(args)), log.Int("length_raw_args", len(rawArgs)), log.String("source", string(a.base.source)))
 	}
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	result, err = a.base.db.ExecContext(ctx, sqlStr, args...)
+	if len(a.base.hooks) > 0 {
+		ev := a.base.hookEvent("Exec", sqlStr, len(args))
+		var start time.Time
+		ctx, start = a.base.hooks.before(ctx, ev)
+		defer func() { a.base.hooks.after(ctx, ev, start, err) }()
+	}
+	err = a.base.withRetry(ctx, "Exec", a.isIdempotent(sqlStr), func() (err error) {
+		result, err = a.base.execContext(ctx, sqlStr, args)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "[dml] ExecContext with query %q", sqlStr) // err gets catched by the defer
 	}
+	recordWrittenTables(ctx, a.tables, a.tablesKnown)
 	lID, err := result.LastInsertId()
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1174,3 +1558,21 @@ func ExecValidateOneAffectedRow(res sql.Result, err error) error {
 	}
 	return nil
 }
+
+// ExecValidateOptimisticLock checks the sql.Result.RowsAffected of an UPDATE
+// statement with an optimistic lock, see Update.OptimisticLock. If fewer rows
+// than `wantRows` have been affected, another process has modified or deleted
+// the rows in the meantime and an error of kind Mismatch gets returned.
+func ExecValidateOptimisticLock(res sql.Result, err error, wantRows int64) error {
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rowCount, err := res.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if rowCount < wantRows {
+		return errors.Mismatch.Newf("[dml] ExecValidateOptimisticLock: Rows have been modified concurrently. Affected rows %d Want %d", rowCount, wantRows)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// Cursor pulls the rows of a result set lazily. The caller controls the pace
+// of the iteration and can stop early without any channel plumbing. The
+// current row gets scanned into a pooled ColumnMap. A Cursor can't be used in
+// concurrent context and must be closed.
+//		cur, err := dbr.Rows(ctx, arg1)
+//		if err != nil {
+//			return err
+//		}
+//		defer cur.Close()
+//		for cur.Next() {
+//			var p Product
+//			if err := cur.Scan(&p); err != nil {
+//				return err
+//			}
+//		}
+//		return cur.Err()
+type Cursor struct {
+	dbr    *DBR
+	ctx    context.Context
+	rows   *sql.Rows
+	cm     *ColumnMap
+	err    error
+	closed bool
+}
+
+// Rows executes the query and returns a Cursor to iterate over the result set.
+// The Cursor respects the cancellation of the context.
+func (a *DBR) Rows(ctx context.Context, args ...interface{}) (*Cursor, error) {
+	r, err := a.query(ctx, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[dml] DBR.Rows.Query with query ID %q", a.base.id)
+	}
+	cm := pooledColumnMapGet()
+	cm.serverTimeZone = a.base.serverTimeZone
+	cm.compression = a.columnCompression()
+	cm.encryption = a.base.encryption
+	cm.maxScannedBytes = a.resultSizeLimit
+	return &Cursor{
+		dbr:  a,
+		ctx:  ctx,
+		rows: r,
+		cm:   cm,
+	}, nil
+}
+
+// Next prepares the next row for reading with Scan or ColumnMap. It returns
+// false when there are no more rows, the context has been canceled or an error
+// occurred. Call Err to distinguish between these cases.
+func (c *Cursor) Next() bool {
+	if c.closed || c.err != nil {
+		return false
+	}
+	if err := c.ctx.Err(); err != nil {
+		c.err = errors.WithStack(err)
+		return false
+	}
+	if !c.rows.Next() {
+		c.err = errors.WithStack(c.rows.Err())
+		return false
+	}
+	if err := c.cm.Scan(c.rows); err != nil {
+		c.err = errors.WithStack(err)
+		return false
+	}
+	return true
+}
+
+// Scan maps the current row into the ColumnMapper.
+func (c *Cursor) Scan(s ColumnMapper) error {
+	if c.closed {
+		return errors.AlreadyClosed.Newf("[dml] Cursor with query ID %q already closed", c.dbr.base.id)
+	}
+	if err := s.MapColumns(c.cm); err != nil {
+		return errors.Wrapf(err, "[dml] Cursor.Scan failed with queryID %q and ColumnMapper %T", c.dbr.base.id, s)
+	}
+	return nil
+}
+
+// ColumnMap returns the ColumnMap of the current row, like in the callback of
+// IterateSerial. The ColumnMap is only valid until the next call to Next or
+// Close.
+func (c *Cursor) ColumnMap() *ColumnMap {
+	return c.cm
+}
+
+// Err returns the error, if any, that was encountered during iteration. A
+// canceled context returns its error.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Close closes the underlying rows and puts the ColumnMap back into the pool.
+// Close is idempotent and must always be called.
+func (c *Cursor) Close() (err error) {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.dbr.resultSize = c.cm.ScannedBytes
+	if c.dbr.base.Log != nil && c.dbr.base.Log.IsDebug() {
+		defer log.WhenDone(c.dbr.base.Log).Debug("Cursor", log.String("id", c.dbr.base.id), log.Err(err), log.Uint64("result_size", c.dbr.resultSize))
+	}
+	if err = c.rows.Close(); err != nil {
+		err = errors.Wrap(err, "[dml] Cursor.Rows.Close")
+	}
+	pooledBufferColumnMapPut(c.cm, nil, nil)
+	c.cm = nil
+	return err
+}
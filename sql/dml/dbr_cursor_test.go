@@ -0,0 +1,99 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestDBR_Rows(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "email", "name"}).
+			AddRow(1, "a@x.io", "A").
+			AddRow(2, "b@x.io", "B").
+			AddRow(3, "c@x.io", "C")
+	}
+
+	t.Run("iterate all", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` > ?)")).
+			WithArgs(0).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").Greater().PlaceHolder()).WithDBR()
+		cur, err := dbr.Rows(context.TODO(), 0)
+		assert.NoError(t, err)
+
+		var got []saverEntity
+		for cur.Next() {
+			var e saverEntity
+			assert.NoError(t, cur.Scan(&e))
+			got = append(got, e)
+		}
+		assert.NoError(t, cur.Err())
+		assert.NoError(t, cur.Close())
+		assert.NoError(t, cur.Close())
+		assert.False(t, cur.Next())
+		assert.ErrorIsKind(t, errors.AlreadyClosed, cur.Scan(&saverEntity{}))
+		assert.Exactly(t, []saverEntity{
+			{ID: 1, Email: "a@x.io", Name: "A"},
+			{ID: 2, Email: "b@x.io", Name: "B"},
+			{ID: 3, Email: "c@x.io", Name: "C"},
+		}, got)
+	})
+
+	t.Run("break early", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer`")).
+			WillReturnRows(newRows().CloseError(errors.AlreadyClosed.Newf("rows closed")))
+
+		cur, err := dbc.SelectFrom("customer").AddColumns("id", "email", "name").WithDBR().Rows(context.TODO())
+		assert.NoError(t, err)
+		assert.True(t, cur.Next())
+		assert.Exactly(t, uint64(0), cur.ColumnMap().Count)
+		assert.ErrorIsKind(t, errors.AlreadyClosed, cur.Close())
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer`")).
+			WillReturnRows(newRows())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cur, err := dbc.SelectFrom("customer").AddColumns("id", "email", "name").WithDBR().Rows(ctx)
+		assert.NoError(t, err)
+		defer func() { assert.NoError(t, cur.Close()) }()
+		assert.True(t, cur.Next())
+		cancel()
+		assert.False(t, cur.Next())
+		assert.True(t, errors.Is(cur.Err(), context.Canceled), "%+v", cur.Err())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id` FROM `customer`")).
+			WillReturnError(errors.ConnectionFailed.Newf("ups"))
+
+		cur, err := dbc.SelectFrom("customer").AddColumns("id").WithDBR().Rows(context.TODO())
+		assert.Nil(t, cur)
+		assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/corestoreio/errors"
+)
+
+// WithChunkSize splits the INSERT statement into multiple statements with at
+// most `rows` rows each, when executed via DBR.ExecContext. A large batch of
+// records might otherwise exceed the server variable max_allowed_packet. The
+// chunks run one after another on the same connection type, so for an atomic
+// insert the DBR must be created from a Tx. The returned sql.Result
+// aggregates the affected rows of all chunks and returns the LastInsertId of
+// the first chunk. Records implementing LastInsertIDAssigner receive their IDs
+// across all chunks. Zero disables chunking. Not supported with prepared
+// statements, INSERT ... SELECT, the SET syntax and the DEFAULT keyword.
+//		res, err := dbc.InsertInto("customer_entity").AddColumns("email", "firstname").
+//			WithChunkSize(500).WithDBR().ExecContext(ctx, dml.Qualify("", customers))
+func (b *Insert) WithChunkSize(rows int) *Insert {
+	b.ChunkSize = rows
+	return b
+}
+
+// chunkedResult aggregates the results of the chunks.
+type chunkedResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (cr chunkedResult) LastInsertId() (int64, error) { return cr.lastInsertID, nil }
+func (cr chunkedResult) RowsAffected() (int64, error) { return cr.rowsAffected, nil }
+
+// execInsertChunks flattens all arguments and records into the column values
+// and executes them in chunks of insertChunkSize rows.
+func (a *DBR) execInsertChunks(ctx context.Context, rawArgs []interface{}) (sql.Result, error) {
+	colCount := int(a.insertColumnCount)
+	if a.insertIsBuildValues {
+		return nil, errors.NotSupported.Newf("[dml] Insert.WithChunkSize can't be combined with BuildValues or SetPairs")
+	}
+	if colCount == 0 {
+		return nil, errors.NotSupported.Newf("[dml] Insert.WithChunkSize requires the columns of the INSERT statement")
+	}
+
+	if a.reuseGuard {
+		if err := a.checkReuse(rawArgs); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	flat := *a // collects the plain arguments without touching the cache of `a`
+	flat.Options = 0
+	flat.base.interpolate = nil
+	flat.insertCachedSQL = ""
+	_, args, err := flat.prepareQueryAndArgs(rawArgs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(args)%colCount != 0 {
+		return nil, errors.NotSupported.Newf("[dml] Insert.WithChunkSize: %d arguments do not fit into %d columns. The DEFAULT keyword is not supported.", len(args), colCount)
+	}
+
+	var assigners []LastInsertIDAssigner
+	for _, arg := range rawArgs {
+		switch at := arg.(type) {
+		case LastInsertIDAssigner:
+			assigners = append(assigners, at)
+		case QualifiedRecord:
+			if lia, ok := at.Record.(LastInsertIDAssigner); ok {
+				assigners = append(assigners, lia)
+			}
+		}
+	}
+
+	chunkArgs := a.insertChunkSize * colCount
+	var res chunkedResult
+	var rowIdx int
+	var lastChunkLen int
+	chunk := *a
+	chunk.reuseGuard = false
+	chunk.tupleRowCount = 0
+	for start := 0; start < len(args); start += chunkArgs {
+		end := start + chunkArgs
+		if end > len(args) {
+			end = len(args)
+		}
+		if end-start != lastChunkLen {
+			chunk.insertCachedSQL = "" // rebuild the place holders for a different row count
+			lastChunkLen = end - start
+		}
+		r, err := chunk.exec(ctx, args[start:end])
+		if err != nil {
+			return nil, errors.Wrapf(err, "[dml] Insert.WithChunkSize failed at row %d", rowIdx)
+		}
+		ra, err := r.RowsAffected()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res.rowsAffected += ra
+		lID, err := r.LastInsertId()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if start == 0 {
+			res.lastInsertID = lID
+		}
+		for rows := (end - start) / colCount; rows > 0; rows-- {
+			if rowIdx < len(assigners) && lID > 0 {
+				assigners[rowIdx].AssignLastInsertID(lID)
+			}
+			rowIdx++
+			lID++
+		}
+	}
+	return res, nil
+}
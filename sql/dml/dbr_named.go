@@ -0,0 +1,118 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"reflect"
+
+	"github.com/corestoreio/errors"
+)
+
+// BindNamed binds the named placeholders (`:name`) of a raw SQL query to the
+// values of `arg` and returns the arguments in the order of the placeholders.
+// The returned arguments can be passed to any of the Load*, Query* or Exec*
+// functions. Argument `arg` can be a map with string keys or a struct, or a
+// pointer to a struct. The fields of a struct get resolved like in
+// StructMapper: the name is taken from the `db` struct tag, or if missing, from
+// the field name. A tag value of "-" skips the field. Embedded structs are
+// supported. All named placeholders must be
+// satisfied, otherwise an error of kind NotFound gets returned. Values which
+// are not used by the query get ignored. BindNamed replaces the named
+// placeholders in the SQL with question marks, hence it can be called several
+// times, e.g. for each new argument.
+//		dbr := dbc.WithRawSQL("SELECT * FROM users WHERE id = :id AND name = :name")
+//		args, err := dbr.BindNamed(struct {
+//			ID   int64 `db:"id"`
+//			Name string
+//		}{ID: 1, Name: "Gopher"})
+//		_, err = dbr.Load(ctx, &user, args...)
+func (a *DBR) BindNamed(arg interface{}) ([]interface{}, error) {
+	if a.base.ärgErr != nil {
+		return nil, errors.WithStack(a.base.ärgErr)
+	}
+	if a.isPrepared {
+		return nil, errors.NotSupported.Newf("[dml] DBR.BindNamed does not support prepared statements with ID %q", a.base.id)
+	}
+	if cachedSQL, ok := a.base.cachedSQL[a.base.cacheKey]; ok {
+		var found bool
+		if cachedSQL, a.base.qualifiedColumns, found = extractReplaceNamedArgs(cachedSQL, a.base.qualifiedColumns); found {
+			a.base.cachedSQLUpsert(a.base.cacheKey, cachedSQL)
+		}
+	}
+
+	lookup, err := namedArgLookup(arg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := make([]interface{}, 0, len(a.base.qualifiedColumns))
+	for _, qc := range a.base.qualifiedColumns {
+		name, isNamed := cutNamedArgStartStr(qc)
+		if !isNamed {
+			return nil, errors.NotSupported.Newf("[dml] DBR.BindNamed: Query with ID %q mixes named placeholders with the placeholder for column %q", a.base.id, qc)
+		}
+		v, ok := lookup(name)
+		if !ok {
+			return nil, errors.NotFound.Newf("[dml] DBR.BindNamed: Named placeholder %q of query with ID %q has not been satisfied by %T", qc, a.base.id, arg)
+		}
+		args = append(args, v)
+	}
+	return args, nil
+}
+
+// namedArgLookup creates a function which returns the value for a named
+// placeholder.
+func namedArgLookup(arg interface{}) (func(name string) (interface{}, bool), error) {
+	switch at := arg.(type) {
+	case map[string]interface{}:
+		return func(name string) (interface{}, bool) {
+			v, ok := at[name]
+			return v, ok
+		}, nil
+	case nil:
+		return nil, errors.NotValid.Newf("[dml] DBR.BindNamed requires a map or a struct but got nil")
+	}
+
+	rv := reflect.ValueOf(arg)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.NotValid.Newf("[dml] DBR.BindNamed requires a map or a struct but got a nil %T", arg)
+		}
+		rv = rv.Elem()
+	}
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		return func(name string) (interface{}, bool) {
+			v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, nil
+	case rv.Kind() == reflect.Struct:
+		sm := loadStructMapping(rv.Type())
+		if sm.err != nil {
+			return nil, errors.WithStack(sm.err)
+		}
+		return func(name string) (interface{}, bool) {
+			f, ok := sm.field(name)
+			if !ok {
+				return nil, false
+			}
+			return rv.FieldByIndex(f.index).Interface(), true
+		}, nil
+	}
+	return nil, errors.NotSupported.Newf("[dml] DBR.BindNamed requires a map or a struct but got %T", arg)
+}
@@ -0,0 +1,639 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/sync/singleflight"
+	"github.com/corestoreio/pkg/util/bufferpool"
+	"github.com/go-sql-driver/mysql"
+)
+
+// ResultCacher defines a cache for the result sets of DBR.Load. Type
+// objcache.Service implements it. Get must call the Unmarshal([]byte) error
+// function of dst and Set the Marshal() ([]byte, error) function of src. A
+// cache miss can be reported either via an error or by passing empty data to
+// Unmarshal.
+type ResultCacher interface {
+	Set(ctx context.Context, key string, src interface{}, expires time.Duration) error
+	Get(ctx context.Context, key string, dst interface{}) error
+}
+
+// resultCacheFlight suppresses concurrent queries for the same cache key.
+var resultCacheFlight singleflight.Group
+
+// defaultResultCacheTimeout limits the query filling the result cache if the
+// context of the caller has no deadline.
+const defaultResultCacheTimeout = 30 * time.Second
+
+// resultCachePools counts the connection pools without a DSN to create a
+// unique namespace for each of them.
+var resultCachePools uint64
+
+// defaultResultCacheNamespace returns the address and the database name of the
+// DSN. Without a DSN the database is unknown and each pool gets its own
+// namespace.
+func defaultResultCacheNamespace(dsn *mysql.Config) string {
+	if dsn != nil {
+		return dsn.Net + "(" + dsn.Addr + ")/" + dsn.DBName
+	}
+	return "pool" + strconv.FormatUint(atomic.AddUint64(&resultCachePools, 1), 10)
+}
+
+// WithResultCacheNamespace sets the namespace of the result cache keys of all
+// statements created by the connection pool. Pools connected to different
+// databases must not share a namespace. Defaults to the address and the
+// database name of the DSN. A pool created via WithDB without a DSN gets a
+// namespace unique to the process, so a cache shared by several processes
+// requires this option to share the entries.
+func WithResultCacheNamespace(ns string) ConnPoolOption {
+	return ConnPoolOption{
+		fn: func(c *ConnPool) error {
+			if ns == "" {
+				return errors.Empty.Newf("[dml] WithResultCacheNamespace requires a namespace")
+			}
+			c.resultCacheNamespace = ns
+			return nil
+		},
+	}
+}
+
+// WithResultCache enables the opt-in result cache for function Load. The
+// cache key is the SHA256 hash of the namespace of the connection pool, see
+// WithResultCacheNamespace, the final SQL string and its arguments. On a cache
+// miss the query runs only once for all concurrent callers with the same key
+// (stampede protection) and the rows get stored for the duration ttl. The
+// query does not get canceled if the context of the caller, which started it,
+// gets canceled, because other callers might still wait for it. It runs until
+// the deadline of that context or, without a deadline, until the timeout of
+// WithResultCacheTimeout. An empty result set gets cached too. Errors of the
+// cache fall back to the database. Prepared statements and statements of a
+// transaction do not get cached. Useful for read-heavy configuration or
+// catalog queries. See WithReadYourWrites to bypass the cache after a write.
+//		srv, err := objcache.NewService(nil, objcache.NewCacheSimpleInmemory, nil)
+//		dbr := dbc.SelectFrom("core_configuration").Star().WithDBR().WithResultCache(srv, time.Minute)
+func (a *DBR) WithResultCache(rc ResultCacher, ttl time.Duration) *DBR {
+	a.resultCache = rc
+	a.resultCacheTTL = ttl
+	return a
+}
+
+// WithResultCacheTimeout limits the query filling the result cache, if the
+// context of the caller has no deadline. Defaults to 30 seconds.
+func (a *DBR) WithResultCacheTimeout(d time.Duration) *DBR {
+	a.resultCacheTimeout = d
+	return a
+}
+
+type ctxReadYourWrites struct{}
+
+// writtenTables collects the tables modified within a request.
+type writtenTables struct {
+	mu     sync.Mutex
+	tables map[string]struct{}
+}
+
+// WithReadYourWrites modifies a context to track the tables written by
+// DBR.ExecContext. Once a table has been written, Load bypasses the result
+// cache for all queries using that table and the same context. This ensures
+// read-your-writes semantics for the current request without disabling the
+// result cache globally.
+//		ctx = dml.WithReadYourWrites(ctx)
+//		_, err = dbc.Update("customer").AddClauses(dml.Column("email").Str(email)).
+//			Where(dml.Column("id").Int64(id)).WithDBR().ExecContext(ctx)
+//		_, err = dbrCachedCustomer.Load(ctx, &customers) // bypasses the cache
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxReadYourWrites{}, &writtenTables{})
+}
+
+// TablesWritten returns true if the context tracks written tables and at
+// least one of the tables has been written. A written statement with unknown
+// tables, like raw SQL, counts as a write to all tables.
+func TablesWritten(ctx context.Context, tables ...string) bool {
+	wt, ok := ctx.Value(ctxReadYourWrites{}).(*writtenTables)
+	if !ok {
+		return false
+	}
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if _, ok := wt.tables[anyTable]; ok && len(tables) > 0 {
+		return true
+	}
+	for _, t := range tables {
+		if _, ok := wt.tables[t]; ok || (t == anyTable && len(wt.tables) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func recordWrittenTables(ctx context.Context, tables []string, tablesKnown bool) {
+	if !tablesKnown {
+		tables = []string{anyTable}
+	}
+	if len(tables) == 0 {
+		return
+	}
+	wt, ok := ctx.Value(ctxReadYourWrites{}).(*writtenTables)
+	if !ok {
+		return
+	}
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if wt.tables == nil {
+		wt.tables = make(map[string]struct{}, len(tables))
+	}
+	for _, t := range tables {
+		wt.tables[t] = struct{}{}
+	}
+}
+
+// resultCacheBypassed reports whether Load must query the database because
+// the current request has written to one of the tables. If the tables of the
+// statement are unknown, any write of the current request bypasses the cache.
+func resultCacheBypassed(ctx context.Context, tables []string, tablesKnown bool) bool {
+	if !tablesKnown {
+		return TablesWritten(ctx, anyTable)
+	}
+	return len(tables) > 0 && TablesWritten(ctx, tables...)
+}
+
+// anyTable gets recorded for statements with unknown tables and matches every
+// table in TablesWritten.
+const anyTable = "*"
+
+// builderTables returns the names of the tables used by a query builder,
+// including joined tables, derived tables, sub-selects and common table
+// expressions. It returns false if the tables can't be determined, for
+// unknown query builders or expressions which might contain a sub-select.
+func builderTables(qb interface{}) ([]string, bool) {
+	tc := tablesCollector{known: true}
+	tc.builder(qb)
+	if !tc.known {
+		return nil, false
+	}
+	return tc.tables, true
+}
+
+type tablesCollector struct {
+	tables []string
+	known  bool
+}
+
+// expression marks the tables as unknown if the SQL expression might contain
+// a sub-select.
+func (tc *tablesCollector) expression(expr string) {
+	if strings.Contains(strings.ToUpper(expr), "SELECT") {
+		tc.known = false
+	}
+}
+
+func (tc *tablesCollector) ident(ident id) {
+	switch {
+	case ident.DerivedTable != nil:
+		tc.builder(ident.DerivedTable)
+	case ident.DerivedUnion != nil:
+		tc.builder(ident.DerivedUnion)
+	case ident.Expression != "":
+		tc.expression(ident.Expression)
+	case ident.Name != "":
+		tc.tables = append(tc.tables, ident.Name)
+	}
+}
+
+func (tc *tablesCollector) columns(cols ids) {
+	for _, c := range cols {
+		switch {
+		case c.DerivedTable != nil:
+			tc.builder(c.DerivedTable)
+		case c.DerivedUnion != nil:
+			tc.builder(c.DerivedUnion)
+		case c.Expression != "":
+			tc.expression(c.Expression)
+		}
+	}
+}
+
+func (tc *tablesCollector) conditions(cs Conditions) {
+	for _, c := range cs {
+		if c.IsLeftExpression {
+			tc.expression(c.Left)
+		}
+		if c.Right.IsExpression {
+			tc.expression(c.Right.Column)
+		}
+		if c.Right.Sub != nil {
+			tc.builder(c.Right.Sub)
+		}
+	}
+}
+
+func (tc *tablesCollector) joins(js Joins) {
+	for _, j := range js {
+		tc.ident(j.Table)
+		tc.conditions(j.On)
+	}
+}
+
+func (tc *tablesCollector) builder(qb interface{}) {
+	switch b := qb.(type) {
+	case *Select:
+		if b == nil {
+			return
+		}
+		tc.ident(b.Table)
+		tc.columns(b.Columns)
+		tc.joins(b.Joins)
+		tc.conditions(b.Wheres)
+		tc.conditions(b.Havings)
+	case *Union:
+		if b == nil {
+			return
+		}
+		for _, s := range b.Selects {
+			tc.builder(s)
+		}
+	case *With:
+		for _, cte := range b.Subclauses {
+			tc.builder(cte.Select)
+			tc.builder(cte.Union)
+		}
+		tc.builder(b.TopLevel.Select)
+		tc.builder(b.TopLevel.Union)
+		tc.builder(b.TopLevel.Update)
+		tc.builder(b.TopLevel.Delete)
+	case *Insert:
+		if b.Into != "" {
+			tc.tables = append(tc.tables, b.Into)
+		}
+		tc.ident(b.Table)
+		tc.builder(b.Select)
+		tc.conditions(b.Pairs)
+		tc.conditions(b.SetClauses)
+		tc.conditions(b.OnDuplicateKeys)
+	case *Update:
+		if b == nil {
+			return
+		}
+		tc.ident(b.Table)
+		tc.joins(b.Joins)
+		tc.conditions(b.SetClauses)
+		tc.conditions(b.Wheres)
+	case *Delete:
+		if b == nil {
+			return
+		}
+		tc.ident(b.Table)
+		tc.joins(b.Joins)
+		tc.conditions(b.Wheres)
+		for _, t := range b.MultiTables {
+			tc.ident(t)
+		}
+	case *Show:
+		// SHOW statements read the server state, not tables.
+	default:
+		tc.known = false
+	}
+}
+
+// resultCacheKey returns the hex encoded hash of the namespace, the SQL string
+// and the interpolated arguments. It returns false if the query can't be
+// cached.
+func resultCacheKey(namespace, sqlStr string, args []interface{}) (string, bool) {
+	if sqlStr == "" {
+		return "", false // prepared statement
+	}
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString(namespace)
+	buf.WriteByte(0)
+	if err := writeInterpolate(buf, dialect, sqlStr, args); err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return "dml_rc_" + hex.EncodeToString(sum[:]), true
+}
+
+func (a *DBR) loadResultCache(ctx context.Context, s ColumnMapper, args []interface{}) (rowCount uint64, err error) {
+	sqlStr, args, err := a.prepareExecution(args)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	key, ok := resultCacheKey(a.base.resultCacheNamespace, sqlStr, args)
+	if !ok {
+		r, err := a.queryPrepared(ctx, sqlStr, args, nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "[dml] DBR.Load.QueryContext failed with queryID %q and ColumnMapper %T", a.base.id, s)
+		}
+		e, err := a.scanResultCacheEntry(r)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		return a.loadResultCacheEntry(e, s)
+	}
+
+	e := new(resultCacheEntry)
+	if err := a.resultCache.Get(ctx, key, e); err == nil && e.found {
+		return a.loadResultCacheEntry(e, s)
+	}
+
+	// The flight runs in its own goroutine and outlives the caller, if its
+	// context gets canceled, hence it uses a copy of the DBR and a context
+	// without cancellation but with the deadline of the caller or a timeout.
+	ac := a.Clone()
+	deadline, hasDeadline := ctx.Deadline()
+	ch := resultCacheFlight.DoChan(key, func() (interface{}, error) {
+		var fctx context.Context = detachedContext{Context: ctx}
+		var cancel context.CancelFunc
+		switch {
+		case hasDeadline:
+			fctx, cancel = context.WithDeadline(fctx, deadline)
+		case ac.resultCacheTimeout > 0:
+			fctx, cancel = context.WithTimeout(fctx, ac.resultCacheTimeout)
+		default:
+			fctx, cancel = context.WithTimeout(fctx, defaultResultCacheTimeout)
+		}
+		defer cancel()
+
+		r, err := ac.queryPrepared(fctx, sqlStr, args, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[dml] DBR.Load.QueryContext failed with queryID %q and ColumnMapper %T", ac.base.id, s)
+		}
+		e, err := ac.scanResultCacheEntry(r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := ac.resultCache.Set(fctx, key, e, ac.resultCacheTTL); err != nil && ac.base.Log != nil {
+			ac.base.Log.Info("dml.DBR.Load.ResultCache.Set", log.String("id", ac.base.id), log.String("key", key), log.Err(err))
+		}
+		return e, nil
+	})
+	select {
+	case <-ctx.Done():
+		return 0, errors.WithStack(ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return 0, errors.WithStack(res.Err)
+		}
+		return a.loadResultCacheEntry(res.Val.(*resultCacheEntry), s)
+	}
+}
+
+// detachedContext keeps the values of the parent context but never gets
+// canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// scanResultCacheEntry scans all rows into a new entry and closes the rows.
+func (a *DBR) scanResultCacheEntry(r *sql.Rows) (_ *resultCacheEntry, err error) {
+	cm := pooledColumnMapGet()
+	cm.maxScannedBytes = a.resultSizeLimit
+	defer pooledBufferColumnMapPut(cm, nil, func() {
+		if err2 := r.Close(); err2 != nil && err == nil {
+			err = errors.Wrap(err2, "[dml] DBR.Load.Rows.Close")
+		}
+	})
+
+	e := &resultCacheEntry{found: true}
+	for r.Next() {
+		if err = cm.Scan(r); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if e.columns == nil {
+			e.columns = append([]string(nil), cm.columns...)
+		}
+		for _, sc := range cm.scanCol {
+			if sc.field == 'y' {
+				sc.byte = append([]byte(nil), sc.byte...) // driver reuses the buffer
+			}
+			e.values = append(e.values, sc)
+		}
+	}
+	if err = r.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return e, nil
+}
+
+// loadResultCacheEntry maps the rows of the entry to the ColumnMapper like the
+// function Load does with the rows from the database.
+func (a *DBR) loadResultCacheEntry(e *resultCacheEntry, s ColumnMapper) (rowCount uint64, err error) {
+	cm := pooledColumnMapGet()
+	cm.serverTimeZone = a.base.serverTimeZone
+	cm.compression = a.columnCompression()
+	cm.encryption = a.base.encryption
+	cm.maxScannedBytes = a.resultSizeLimit
+	defer pooledBufferColumnMapPut(cm, nil, func() {
+		a.resultSize = cm.ScannedBytes
+		for i := range cm.scanCol {
+			cm.scanCol[i].byte = nil // shared with the entry
+		}
+		if rc, ok := s.(ioCloser); ok {
+			if err2 := rc.Close(); err2 != nil && err == nil {
+				err = errors.Wrap(err2, "[dml] DBR.Load.ColumnMapper.Close")
+			}
+		}
+	})
+
+	if lc := len(e.columns); lc > 0 {
+		for i := 0; i+lc <= len(e.values); i += lc {
+			if err = cm.scanCachedRow(e.columns, e.values[i:i+lc]); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if err = s.MapColumns(cm); err != nil {
+				return 0, errors.Wrapf(err, "[dml] DBR.Load failed with queryID %q and ColumnMapper %T", a.base.id, s)
+			}
+		}
+	}
+	if cm.HasRows {
+		cm.Count++ // because first row is zero but we want the actual row number
+	}
+	return cm.Count, nil
+}
+
+// resultCacheEntry contains the scanned rows of a result set. It implements
+// its own binary encoding to be independent of the codec of the cache.
+type resultCacheEntry struct {
+	columns []string
+	// values contains len(columns) values per row.
+	values []scannedColumn
+	// found gets set to false if the cache passes empty data to Unmarshal.
+	found bool
+}
+
+const resultCacheEntryVersion = 1
+
+// Marshal encodes the entry.
+func (e *resultCacheEntry) Marshal() ([]byte, error) {
+	var vb [binary.MaxVarintLen64]byte
+	data := make([]byte, 0, 64+len(e.values)*8)
+	putUvarint := func(u uint64) {
+		data = append(data, vb[:binary.PutUvarint(vb[:], u)]...)
+	}
+	data = append(data, resultCacheEntryVersion)
+	putUvarint(uint64(len(e.columns)))
+	for _, c := range e.columns {
+		putUvarint(uint64(len(c)))
+		data = append(data, c...)
+	}
+	putUvarint(uint64(len(e.values)))
+	for _, v := range e.values {
+		data = append(data, v.field)
+		switch v.field {
+		case 'i':
+			data = append(data, vb[:binary.PutVarint(vb[:], v.int64)]...)
+		case 'f':
+			binary.BigEndian.PutUint64(vb[:8], math.Float64bits(v.float64))
+			data = append(data, vb[:8]...)
+		case 'b':
+			if v.bool {
+				data = append(data, 1)
+			} else {
+				data = append(data, 0)
+			}
+		case 's':
+			putUvarint(uint64(len(v.string)))
+			data = append(data, v.string...)
+		case 'y':
+			putUvarint(uint64(len(v.byte)))
+			data = append(data, v.byte...)
+		case 't':
+			tb, err := v.time.MarshalBinary()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			putUvarint(uint64(len(tb)))
+			data = append(data, tb...)
+		}
+	}
+	return data, nil
+}
+
+// Unmarshal decodes the entry. Empty data marks a cache miss.
+func (e *resultCacheEntry) Unmarshal(data []byte) error {
+	e.found = false
+	if len(data) == 0 {
+		return nil
+	}
+	if data[0] != resultCacheEntryVersion {
+		return errors.NotSupported.Newf("[dml] ResultCache entry version %d not supported", data[0])
+	}
+	pos := 1
+	errCorrupt := func() error {
+		return errors.CorruptData.Newf("[dml] ResultCache entry corrupt at position %d", pos)
+	}
+	uvarint := func() (int, bool) {
+		u, n := binary.Uvarint(data[pos:])
+		if n <= 0 || u > uint64(len(data)) {
+			return 0, false
+		}
+		pos += n
+		return int(u), true
+	}
+	nextBytes := func() ([]byte, bool) {
+		l, ok := uvarint()
+		if !ok || pos+l > len(data) {
+			return nil, false
+		}
+		b := data[pos : pos+l : pos+l]
+		pos += l
+		return b, true
+	}
+
+	lc, ok := uvarint()
+	if !ok {
+		return errCorrupt()
+	}
+	e.columns = make([]string, lc)
+	for i := range e.columns {
+		c, ok := nextBytes()
+		if !ok {
+			return errCorrupt()
+		}
+		e.columns[i] = string(c)
+	}
+	lv, ok := uvarint()
+	if !ok {
+		return errCorrupt()
+	}
+	e.values = make([]scannedColumn, lv)
+	for i := range e.values {
+		if pos >= len(data) {
+			return errCorrupt()
+		}
+		v := &e.values[i]
+		v.field = data[pos]
+		pos++
+		switch v.field {
+		case 'i':
+			i64, n := binary.Varint(data[pos:])
+			if n <= 0 {
+				return errCorrupt()
+			}
+			v.int64 = i64
+			pos += n
+		case 'f':
+			if pos+8 > len(data) {
+				return errCorrupt()
+			}
+			v.float64 = math.Float64frombits(binary.BigEndian.Uint64(data[pos:]))
+			pos += 8
+		case 'b':
+			if pos >= len(data) {
+				return errCorrupt()
+			}
+			v.bool = data[pos] == 1
+			pos++
+		case 's':
+			b, ok := nextBytes()
+			if !ok {
+				return errCorrupt()
+			}
+			v.string = string(b)
+		case 'y':
+			if v.byte, ok = nextBytes(); !ok {
+				return errCorrupt()
+			}
+		case 't':
+			b, ok := nextBytes()
+			if !ok {
+				return errCorrupt()
+			}
+			if err := v.time.UnmarshalBinary(b); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	e.found = true
+	return nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+// resultCacheMock stores the encoded values like objcache.Service does.
+type resultCacheMock struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	sets int
+}
+
+func (rc *resultCacheMock) Set(_ context.Context, key string, src interface{}, _ time.Duration) error {
+	data, err := src.(interface{ Marshal() ([]byte, error) }).Marshal()
+	if err != nil {
+		return err
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.data == nil {
+		rc.data = map[string][]byte{}
+	}
+	rc.data[key] = data
+	rc.sets++
+	return nil
+}
+
+func (rc *resultCacheMock) Get(_ context.Context, key string, dst interface{}) error {
+	rc.mu.Lock()
+	data, ok := rc.data[key]
+	rc.mu.Unlock()
+	if !ok {
+		return errors.NotFound.Newf("key %q not found", key)
+	}
+	return dst.(interface{ Unmarshal([]byte) error }).Unmarshal(data)
+}
+
+type saverEntities []saverEntity
+
+func (es *saverEntities) MapColumns(cm *dml.ColumnMap) error {
+	var e saverEntity
+	if err := e.MapColumns(cm); err != nil {
+		return errors.WithStack(err)
+	}
+	*es = append(*es, e)
+	return nil
+}
+
+func TestDBR_WithResultCache(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	t.Run("second Load hits the cache", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` > 0)")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).
+				AddRow(1, "a@x.io", "A").
+				AddRow(2, "b@x.io", []byte("B")))
+
+		dbr := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").Greater().PlaceHolder()).WithDBR().Interpolate().WithResultCache(rc, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			var got saverEntities
+			rowCount, err := dbr.Load(context.TODO(), &got, 0)
+			assert.NoError(t, err)
+			assert.Exactly(t, uint64(2), rowCount)
+			assert.Exactly(t, []saverEntity{
+				{ID: 1, Email: "a@x.io", Name: "A"},
+				{ID: 2, Email: "b@x.io", Name: "B"},
+			}, []saverEntity(got))
+		}
+		assert.Exactly(t, 1, rc.sets)
+	})
+
+	t.Run("empty result gets cached", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` > 100)")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}))
+
+		dbr := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").Greater().PlaceHolder()).WithDBR().Interpolate().WithResultCache(rc, time.Minute)
+		for i := 0; i < 2; i++ {
+			var got saverEntities
+			rowCount, err := dbr.Load(context.TODO(), &got, 100)
+			assert.NoError(t, err)
+			assert.Exactly(t, uint64(0), rowCount)
+			assert.Len(t, got, 0)
+		}
+		assert.Exactly(t, 1, rc.sets)
+	})
+
+	t.Run("read your writes", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` AS `c` INNER JOIN `customer_address` AS `ca` ON (`ca`.`parent_id` = `c`.`id`)")
+		newRows := func() *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "a@x.io", "A")
+		}
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(newRows())
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer_address` SET `city`='Sydney'")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("customer", "c").AddColumns("id", "email", "name").
+			Join(dml.MakeIdentifier("customer_address").Alias("ca"), dml.Column("ca.parent_id").Equal().Column("c.id")).
+			WithDBR().WithResultCache(rc, time.Minute)
+
+		ctx := dml.WithReadYourWrites(context.TODO())
+		var got saverEntities
+		_, err := dbr.Load(ctx, &got)
+		assert.NoError(t, err)
+		_, err = dbr.Load(ctx, &got) // served from cache
+		assert.NoError(t, err)
+		assert.False(t, dml.TablesWritten(ctx, "customer", "customer_address"))
+
+		_, err = dbc.Update("customer_address").AddClauses(dml.Column("city").Str("Sydney")).WithDBR().ExecContext(ctx)
+		assert.NoError(t, err)
+		assert.True(t, dml.TablesWritten(ctx, "customer_address"))
+
+		_, err = dbr.Load(ctx, &got) // bypasses the cache
+		assert.NoError(t, err)
+		_, err = dbr.Load(context.TODO(), &got) // other request uses the cache
+		assert.NoError(t, err)
+		assert.Len(t, got, 4)
+		assert.Exactly(t, 1, rc.sets)
+	})
+
+	t.Run("read your writes with sub-selects and unknown tables", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` IN (SELECT `parent_id` FROM `customer_address`))")
+		unionSQL := dmltest.SQLMockQuoteMeta("(SELECT `id`, `email`, `name` FROM `customer`)\nUNION\n(SELECT `id`, `email`, `name` FROM `customer_archive`)")
+		newRows := func() *sqlmock.Rows {
+			return sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "a@x.io", "A")
+		}
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(newRows())
+		dbMock.ExpectQuery(unionSQL).WillReturnRows(newRows())
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer_address` SET `city`='Sydney'")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(newRows())
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer_archive`")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(unionSQL).WillReturnRows(newRows())
+
+		dbrSub := dbc.SelectFrom("customer").AddColumns("id", "email", "name").Where(
+			dml.Column("id").In().Sub(dml.NewSelect("parent_id").From("customer_address")),
+		).WithDBR().WithResultCache(rc, time.Minute)
+		dbrUnion := dbc.Union(
+			dml.NewSelect("id", "email", "name").From("customer"),
+			dml.NewSelect("id", "email", "name").From("customer_archive"),
+		).WithDBR().WithResultCache(rc, time.Minute)
+
+		ctx := dml.WithReadYourWrites(context.TODO())
+		var got saverEntities
+		for _, dbr := range []*dml.DBR{dbrSub, dbrUnion, dbrSub, dbrUnion} {
+			_, err := dbr.Load(ctx, &got)
+			assert.NoError(t, err)
+		}
+
+		_, err := dbc.Update("customer_address").AddClauses(dml.Column("city").Str("Sydney")).WithDBR().ExecContext(ctx)
+		assert.NoError(t, err)
+		_, err = dbrSub.Load(ctx, &got) // bypasses the cache
+		assert.NoError(t, err)
+		_, err = dbrUnion.Load(ctx, &got) // served from cache
+		assert.NoError(t, err)
+
+		_, err = dbc.WithRawSQL("DELETE FROM `customer_archive`").ExecContext(ctx)
+		assert.NoError(t, err)
+		assert.True(t, dml.TablesWritten(ctx, "customer"))
+		_, err = dbrUnion.Load(ctx, &got) // bypasses the cache
+		assert.NoError(t, err)
+		assert.Exactly(t, 2, rc.sets)
+	})
+
+	t.Run("namespace per connection pool", func(t *testing.T) {
+		dbc2, dbMock2 := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc2, dbMock2)
+
+		rc := &resultCacheMock{}
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer`")
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "a@x.io", "A"))
+		dbMock2.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(2, "b@x.io", "B"))
+
+		for _, db := range []*dml.ConnPool{dbc, dbc2, dbc, dbc2} {
+			var got saverEntities
+			_, err := db.SelectFrom("customer").AddColumns("id", "email", "name").WithDBR().
+				WithResultCache(rc, time.Minute).Load(context.TODO(), &got)
+			assert.NoError(t, err)
+			if db == dbc {
+				assert.Exactly(t, []saverEntity{{ID: 1, Email: "a@x.io", Name: "A"}}, []saverEntity(got))
+			} else {
+				assert.Exactly(t, []saverEntity{{ID: 2, Email: "b@x.io", Name: "B"}}, []saverEntity(got))
+			}
+		}
+		assert.Exactly(t, 2, rc.sets)
+	})
+
+	t.Run("transaction bypasses the cache", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		selectSQL := dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer`")
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "a@x.io", "A"))
+		dbMock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(1, "c@x.io", "A"))
+		dbMock.ExpectCommit()
+
+		tx, err := dbc.BeginTx(context.TODO(), nil)
+		assert.NoError(t, err)
+		dbr := tx.SelectFrom("customer").AddColumns("id", "email", "name").WithDBR().WithResultCache(rc, time.Minute)
+		var got saverEntities
+		_, err = dbr.Load(context.TODO(), &got)
+		assert.NoError(t, err)
+		_, err = dbr.Load(context.TODO(), &got)
+		assert.NoError(t, err)
+		assert.NoError(t, tx.Commit())
+		assert.Exactly(t, "c@x.io", got[1].Email)
+		assert.Exactly(t, 0, rc.sets)
+	})
+
+	t.Run("canceled caller does not cancel the flight", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` = 7)")).
+			WillDelayFor(50 * time.Millisecond).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(7, "g@x.io", "G"))
+
+		newDBR := func() *dml.DBR {
+			return dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+				Where(dml.Column("id").Int64(7)).WithDBR().WithResultCache(rc, time.Minute)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var got saverEntities
+		_, err := newDBR().Load(ctx, &got)
+		assert.True(t, errors.Is(err, context.Canceled), "%+v", err)
+
+		_, err = newDBR().Load(context.TODO(), &got)
+		assert.NoError(t, err)
+		assert.Exactly(t, []saverEntity{{ID: 7, Email: "g@x.io", Name: "G"}}, []saverEntity(got))
+		assert.Exactly(t, 1, rc.sets)
+	})
+
+	t.Run("flight times out", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` = 8)")).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(8, "h@x.io", "H"))
+
+		var got saverEntities
+		_, err := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").Int64(8)).WithDBR().WithResultCache(rc, time.Minute).
+			WithResultCacheTimeout(10*time.Millisecond).Load(context.TODO(), &got)
+		assert.Error(t, err)
+		waitForIdleConns(t, dbc)
+		assert.Exactly(t, 0, rc.sets)
+	})
+
+	t.Run("flight uses the deadline of the caller", func(t *testing.T) {
+		rc := &resultCacheMock{}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email`, `name` FROM `customer` WHERE (`id` = 9)")).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(9, "i@x.io", "I"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var got saverEntities
+		_, err := dbc.SelectFrom("customer").AddColumns("id", "email", "name").
+			Where(dml.Column("id").Int64(9)).WithDBR().WithResultCache(rc, time.Minute).Load(ctx, &got)
+		assert.Error(t, err)
+		waitForIdleConns(t, dbc)
+		assert.Exactly(t, 0, rc.sets)
+	})
+}
+
+// waitForIdleConns waits until the flight of the result cache has returned its
+// connection. The flights of the tests must stop long before their delayed
+// query would return.
+func waitForIdleConns(t *testing.T, dbc *dml.ConnPool) {
+	for i := 0; i < 500; i++ {
+		if dbc.DB.Stats().InUse == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("the flight still uses its connection")
+}
@@ -0,0 +1,121 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"reflect"
+
+	"github.com/corestoreio/errors"
+)
+
+// poisonedArgument replaces the consumed arguments in the pooled argument
+// slices when the reuse guard has been enabled. A poisoned argument which
+// reaches a query proves that a reference to an already consumed argument
+// slice has been retained.
+type poisonedArgument struct{}
+
+// ReuseGuard enables a debug mode to detect the subtle bugs of a reused DBR.
+// Internally pooled argument slices get poisoned after each execution, so a
+// stale reference to consumed arguments results in an error of kind
+// AlreadyClosed. Executing the DBR a second time without calling Reset and
+// without providing new arguments or records returns an error of kind
+// Duplicated. Arguments are new if they differ by value, or for records by
+// pointer, from the arguments of the previous execution. The guard costs
+// allocations and should not be enabled in production.
+func (a *DBR) ReuseGuard() *DBR {
+	a.reuseGuard = true
+	return a
+}
+
+// checkReuse gets called before each execution when the reuse guard has been
+// enabled. Functions which only build the SQL string, like ToSQL or Explain,
+// do not count as an execution.
+func (a *DBR) checkReuse(extArgs []interface{}) error {
+	for i, arg := range extArgs {
+		if _, ok := arg.(poisonedArgument); ok {
+			return errors.AlreadyClosed.Newf("[dml] DBR with ID %q: Argument at position %d has already been consumed by a previous execution", a.base.id, i)
+		}
+	}
+	if a.executed && argumentsIdentical(a.lastArgs, extArgs) {
+		return errors.Duplicated.Newf("[dml] DBR with ID %q has already been executed with the same arguments. Call Reset or provide new arguments.", a.base.id)
+	}
+	a.executed = true
+	a.lastArgs = append(a.lastArgs[:0], extArgs...)
+	return nil
+}
+
+// resetReuse clears the state of the reuse guard and releases the references
+// to the arguments of the previous execution.
+func (a *DBR) resetReuse() {
+	for i := range a.lastArgs {
+		a.lastArgs[i] = nil
+	}
+	a.lastArgs = a.lastArgs[:0]
+	a.executed = false
+}
+
+// argumentsIdentical reports whether both argument slices are equal. Arguments
+// of non-comparable types, like slices, are never considered equal because
+// their content might have been changed.
+func argumentsIdentical(prev, next []interface{}) bool {
+	if len(prev) != len(next) {
+		return false
+	}
+	for i, p := range prev {
+		n := next[i]
+		if qp, ok := p.(QualifiedRecord); ok {
+			qn, ok := n.(QualifiedRecord)
+			if !ok || qp.Qualifier != qn.Qualifier {
+				return false
+			}
+			p, n = qp.Record, qn.Record
+		}
+		if p == nil || n == nil {
+			if p != n {
+				return false
+			}
+			continue
+		}
+		if !argumentEqual(p, n) {
+			return false
+		}
+	}
+	return true
+}
+
+// argumentEqual compares two non-nil arguments. A comparable struct type can
+// still contain an interface field with a non-comparable value, e.g. a
+// sql.NamedArg with a slice, which lets the comparison panic.
+func argumentEqual(p, n interface{}) (equal bool) {
+	tp := reflect.TypeOf(p)
+	if tp != reflect.TypeOf(n) || !tp.Comparable() {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			equal = false
+		}
+	}()
+	return p == n
+}
+
+// poisonArguments overwrites the whole capacity of an argument slice before
+// it gets returned to the pool.
+func poisonArguments(args []interface{}) {
+	args = args[:cap(args)]
+	for i := range args {
+		args[i] = poisonedArgument{}
+	}
+}
@@ -16,10 +16,12 @@ package dml
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/log"
 	"github.com/corestoreio/pkg/storage/null"
@@ -147,7 +149,7 @@ func TestArguments_WriteTo(t *testing.T) {
 			null.MakeBool(true), null.MakeTime(now()))
 
 		var buf bytes.Buffer
-		assert.NoError(t, writeInterfaces(&buf, args))
+		assert.NoError(t, writeInterfaces(&buf, dialect, args))
 		assert.Exactly(t,
 			"(NULL,-1,1,2,3.1,1,'eCom1','eCom2','2006-01-02 15:04:05','eCom3',4,2.7,1,'2006-01-02 15:04:05')",
 			buf.String())
@@ -159,7 +161,7 @@ func TestArguments_WriteTo(t *testing.T) {
 			null.Bool{}, null.Time{})
 
 		var buf bytes.Buffer
-		assert.NoError(t, writeInterfaces(&buf, args))
+		assert.NoError(t, writeInterfaces(&buf, dialect, args))
 		assert.Exactly(t,
 			"(NULL,-1,1,2,3.1,1,'eCom1','eCom2','2006-01-02 15:04:05',NULL,NULL,NULL,NULL,NULL)",
 			buf.String())
@@ -173,7 +175,7 @@ func TestArguments_WriteTo(t *testing.T) {
 			[]null.Bool{null.MakeBool(true)}, []null.Time{null.MakeTime(now()), null.MakeTime(now())})
 
 		var buf bytes.Buffer
-		assert.NoError(t, writeInterfaces(&buf, args))
+		assert.NoError(t, writeInterfaces(&buf, dialect, args))
 		assert.Exactly(t,
 			"(NULL,(-1,-2),(1,2),(568,766),(2),(1.2,3.1),(0,1),('eCom1','eCom11'),('eCom2'),('2006-01-02 15:04:05','2006-01-02 15:04:05'),('eCom3','eCom3'),(4,4),(2.7,2.7),(1),('2006-01-02 15:04:05','2006-01-02 15:04:05'))",
 			buf.String(), "%q", buf.String())
@@ -181,46 +183,46 @@ func TestArguments_WriteTo(t *testing.T) {
 	t.Run("non-utf8 string", func(t *testing.T) {
 		args := toIFaceSlice("\xc0\x80")
 		var buf bytes.Buffer
-		err := writeInterfaces(&buf, args)
+		err := writeInterfaces(&buf, dialect, args)
 		assert.Empty(t, buf.String(), "Buffer should be empty")
 		assert.ErrorIsKind(t, errors.NotValid, err)
 	})
 	t.Run("non-utf8 strings", func(t *testing.T) {
 		args := toIFaceSlice([]string{"Go", "\xc0\x80"})
 		var buf bytes.Buffer
-		err := writeInterfaces(&buf, args)
+		err := writeInterfaces(&buf, dialect, args)
 		assert.Exactly(t, `('Go',)`, buf.String())
 		assert.ErrorIsKind(t, errors.NotValid, err)
 	})
 	t.Run("non-utf8 NullStrings", func(t *testing.T) {
 		args := toIFaceSlice([]null.String{null.MakeString("Go2"), null.MakeString("Hello\xc0\x80World")})
 		var buf bytes.Buffer
-		err := writeInterfaces(&buf, args)
+		err := writeInterfaces(&buf, dialect, args)
 		assert.Exactly(t, "('Go2',)", buf.String())
 		assert.ErrorIsKind(t, errors.NotValid, err)
 	})
 	t.Run("non-utf8 NullString", func(t *testing.T) {
 		args := toIFaceSlice(null.MakeString("Hello\xc0\x80World"))
 		var buf bytes.Buffer
-		err := writeInterfaces(&buf, args)
+		err := writeInterfaces(&buf, dialect, args)
 		assert.Empty(t, buf.String())
 		assert.ErrorIsKind(t, errors.NotValid, err)
 	})
 	t.Run("bytes as binary", func(t *testing.T) {
 		args := toIFaceSlice([][]byte{[]byte("\xc0\x80")})
 		var buf bytes.Buffer
-		assert.NoError(t, writeInterfaces(&buf, args))
+		assert.NoError(t, writeInterfaces(&buf, dialect, args))
 		assert.Exactly(t, `(0xc080)`, buf.String())
 	})
 	t.Run("bytesSlice as binary", func(t *testing.T) {
 		args := toIFaceSlice([][]byte{[]byte(`Rusty`), []byte("Go\xc0\x80")})
 		var buf bytes.Buffer
-		assert.NoError(t, writeInterfaces(&buf, args))
+		assert.NoError(t, writeInterfaces(&buf, dialect, args))
 		assert.Exactly(t, "('Rusty',0x476fc080)", buf.String())
 	})
 	t.Run("should panic because unknown field type", func(t *testing.T) {
 		var buf bytes.Buffer
-		assert.ErrorIsKind(t, errors.NotSupported, writeInterfaceValue(complex64(1), &buf, 0))
+		assert.ErrorIsKind(t, errors.NotSupported, writeInterfaceValue(complex64(1), &buf, dialect, 0))
 		assert.Empty(t, buf.String(), "buffer should be empty")
 	})
 }
@@ -440,3 +442,149 @@ func (mockSQLRes) LastInsertId() (int64, error) {
 func (m mockSQLRes) RowsAffected() (int64, error) {
 	return m.int64, m.error
 }
+
+func TestDBR_ReuseGuard(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("double execution without new arguments", func(t *testing.T) {
+		a := NewSelect("a").From("b").Where(Column("c").PlaceHolder()).WithDB(dbMock{}).WithDBR().ReuseGuard()
+		_, err := a.QueryContext(ctx, 1)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, 1)
+		assert.ErrorIsKind(t, errors.Duplicated, err)
+	})
+	t.Run("new arguments", func(t *testing.T) {
+		a := NewSelect("a").From("b").Where(Column("c").PlaceHolder()).WithDB(dbMock{}).WithDBR().ReuseGuard()
+		_, err := a.QueryContext(ctx, 1)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, 2)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, []int64{2})
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, []int64{2})
+		assert.NoError(t, err, "slices are never equal")
+	})
+	t.Run("new records", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		for i := 0; i < 4; i++ {
+			mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+		}
+
+		a := NewInsert("a").AddColumns("name").WithDB(db).WithDBR().ReuseGuard().Interpolate()
+		p1 := &dmlPerson{Name: "One"}
+		_, err = a.ExecContext(ctx, Qualify("", p1))
+		assert.NoError(t, err)
+		_, err = a.ExecContext(ctx, Qualify("", &dmlPerson{Name: "Two"}))
+		assert.NoError(t, err)
+		_, err = a.ExecContext(ctx, Qualify("", &dmlPerson{Name: "Two"}))
+		assert.NoError(t, err)
+		_, err = a.ExecContext(ctx, Qualify("", p1))
+		assert.NoError(t, err)
+		_, err = a.ExecContext(ctx, Qualify("", p1))
+		assert.ErrorIsKind(t, errors.Duplicated, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("building the SQL is not an execution", func(t *testing.T) {
+		a := NewSelect("a").From("b").Where(Column("c").PlaceHolder()).WithDB(dbMock{}).WithDBR().ReuseGuard()
+		_, _, err := a.testWithArgs(1).ToSQL()
+		assert.NoError(t, err)
+		_, _, err = a.testWithArgs(1).ToSQL()
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, 1)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx, 1)
+		assert.ErrorIsKind(t, errors.Duplicated, err)
+	})
+	t.Run("chunked insert", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(2, 1))
+
+		a := NewInsert("a").AddColumns("name").WithChunkSize(1).WithDB(db).WithDBR().ReuseGuard()
+		_, err = a.ExecContext(ctx, "x", "y")
+		assert.NoError(t, err)
+		_, err = a.ExecContext(ctx, "x", "y")
+		assert.ErrorIsKind(t, errors.Duplicated, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Reset allows re-execution", func(t *testing.T) {
+		a := NewSelect("a").From("b").WithDB(dbMock{}).WithDBR().ReuseGuard()
+		_, err := a.QueryContext(ctx)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx)
+		assert.ErrorIsKind(t, errors.Duplicated, err)
+		_, err = a.Reset().QueryContext(ctx)
+		assert.NoError(t, err)
+		_, err = a.Clone().QueryContext(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("without guard", func(t *testing.T) {
+		a := NewSelect("a").From("b").WithDB(dbMock{}).WithDBR()
+		_, err := a.QueryContext(ctx)
+		assert.NoError(t, err)
+		_, err = a.QueryContext(ctx)
+		assert.NoError(t, err)
+	})
+	t.Run("poisoned arguments", func(t *testing.T) {
+		retained := append(pooledInterfacesGet(), int64(1), "x")
+		pooledInterfacesPut(retained, true)
+		assert.Exactly(t, poisonedArgument{}, retained[1])
+
+		a := NewSelect("a").From("b").Where(Column("c").In().PlaceHolder()).WithDB(dbMock{}).WithDBR().ReuseGuard()
+		_, err := a.QueryContext(ctx, retained...)
+		assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+	})
+	t.Run("argumentsIdentical", func(t *testing.T) {
+		assert.True(t, argumentsIdentical(nil, nil))
+		assert.True(t, argumentsIdentical([]interface{}{nil, 1, "a"}, []interface{}{nil, 1, "a"}))
+		assert.False(t, argumentsIdentical([]interface{}{1}, []interface{}{int64(1)}))
+		assert.False(t, argumentsIdentical([]interface{}{1}, []interface{}{1, 2}))
+		na := sql.Named("x", []int{1})
+		assert.False(t, argumentsIdentical([]interface{}{na}, []interface{}{na}))
+		assert.True(t, argumentsIdentical([]interface{}{Qualify("q", nil)}, []interface{}{Qualify("q", nil)}))
+	})
+}
+
+func TestDBR_ServerTimeZone(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	dbc, err := NewConnPool(WithDB(db), WithServerTimeZone(time.FixedZone("CEST", 2*3600)))
+	assert.NoError(t, err)
+	defer func() {
+		dbMock.ExpectClose()
+		assert.NoError(t, dbc.Close())
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	}()
+
+	createdAt := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	serverWallClock := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("arguments of the caller do not change", func(t *testing.T) {
+		a := dbc.SelectFrom("sales_order").AddColumns("entity_id").Where(Column("created_at").PlaceHolder()).WithDBR()
+		args := []interface{}{createdAt}
+		converted := a.convertTimeArguments(args)
+		assert.Exactly(t, []interface{}{createdAt}, args)
+		assert.Exactly(t, []interface{}{serverWallClock}, converted)
+
+		ins := dbc.InsertInto("sales_order").AddColumns("created_at").WithDBR()
+		_, gotArgs, err := ins.prepareQueryAndArgs(args)
+		assert.NoError(t, err)
+		assert.Exactly(t, []interface{}{serverWallClock}, gotArgs)
+		assert.Exactly(t, []interface{}{createdAt}, args)
+	})
+
+	t.Run("loadPrimitive time.Time", func(t *testing.T) {
+		dbMock.ExpectQuery("SELECT MAX").WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(serverWallClock))
+		var got time.Time
+		found, err := dbc.SelectFrom("sales_order").AddColumnsConditions(Expr("MAX(created_at)")).WithDBR().
+			loadPrimitive(context.TODO(), &got)
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Exactly(t, createdAt, got)
+	})
+}
@@ -45,14 +45,19 @@ type Delete struct {
 	// MultiTables specifies the additional tables to delete from. Use function
 	// `FromTables` to conveniently set it.
 	MultiTables ids
-	// Returning allows from MariaDB 10.0.5, it is possible to return a
+	// MultiTablesUsing writes the multi-table DELETE with the USING syntax. The
+	// tables to delete from follow the FROM keyword and the table references
+	// follow the USING keyword. Use function `FromTablesUsing` to conveniently
+	// set it.
+	MultiTablesUsing bool
+	// ReturningColumns allows from MariaDB 10.0.5, it is possible to return a
 	// resultset of the deleted rows for a single table to the client by using
 	// the syntax DELETE ... RETURNING select_expr [, select_expr2 ...]] Any of
 	// SQL expression that can be calculated from a single row fields is
-	// allowed. Subqueries are allowed. The AS keyword is allowed, so it is
-	// possible to use aliases. The use of aggregate functions is not allowed.
-	// RETURNING cannot be used in multi-table DELETEs.
-	Returning *Select
+	// allowed. The use of aggregate functions is not allowed. RETURNING cannot
+	// be used in multi-table DELETEs. Use function `Returning` to conveniently
+	// set it and LoadReturning to execute it.
+	ReturningColumns ids
 }
 
 // NewDelete creates a new Delete object.
@@ -76,12 +81,8 @@ func newDeleteFrom(db QueryExecPreparer, cCom *connCommon, from string) *Delete
 	}
 	return &Delete{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  db,
-			},
-			Table: MakeIdentifier(from),
+			builderCommon: cCom.newBuilderCommon(id, l, db),
+			Table:         MakeIdentifier(from),
 		},
 		BuilderConditional: BuilderConditional{
 			Wheres: make(Conditions, 0, 2),
@@ -98,7 +99,7 @@ func (c *ConnPool) DeleteFrom(from string) *Delete {
 // DeleteFrom creates a new Delete for the given table in the context for a
 // single database connection. Mapping the table name is supported.
 func (c *Conn) DeleteFrom(from string) *Delete {
-	return newDeleteFrom(c.DB, &c.connCommon, from)
+	return newDeleteFrom(c.qep(), &c.connCommon, from)
 }
 
 // DeleteFrom creates a new Delete for the given table in the context for a
@@ -119,6 +120,20 @@ func (b *Delete) FromTables(tables ...string) *Delete {
 	return b
 }
 
+// FromTablesUsing specifies additional tables to delete from besides the
+// default table and switches to the USING syntax. The default table and the
+// joined tables form the table references after USING:
+//		DELETE FROM `ce`,`customer_address` USING `customer_entity` AS `ce`
+//		INNER JOIN `customer_address` AS `ca` ON ... WHERE ...
+func (b *Delete) FromTablesUsing(tables ...string) *Delete {
+	// DELETE [LOW_PRIORITY] [QUICK] [IGNORE]
+	// FROM tbl_name[.*] [, tbl_name[.*]] ...	<-- MultiTables/FromTablesUsing
+	// USING table_references
+	//[WHERE where_condition]
+	b.MultiTablesUsing = true
+	return b.FromTables(tables...)
+}
+
 // Join creates an INNER join construct. By default, the onConditions are glued
 // together with AND. Same Source and Target Table: Until MariaDB 10.3.1,
 // deleting from a table with the same source and target was not possible. From
@@ -253,7 +268,7 @@ func (b *Delete) WithCacheKey(key string, args ...interface{}) *Delete {
 
 // ToSQL serialized the Delete to a SQL string
 // It returns the string with placeholders and a slice of query arguments
-func (b *Delete) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (b *Delete) toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	b.source = dmlSourceDelete
 	b.defaultQualifier = b.Table.qualifier()
 
@@ -261,9 +276,16 @@ func (b *Delete) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err
 		return nil, errors.Empty.Newf("[dml] Delete: Table is missing")
 	}
 
+	if placeHolders, err = b.writeTxCTEs(w, d, placeHolders); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	w.WriteString("DELETE ")
 	writeStmtID(w, b.id)
 
+	isUsing := b.MultiTablesUsing && len(b.MultiTables) > 0
+	if isUsing {
+		w.WriteString("FROM ")
+	}
 	for i, mt := range b.MultiTables {
 		if i == 0 {
 			if b.Table.Aliased != "" {
@@ -276,20 +298,24 @@ func (b *Delete) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err
 		if i > 0 {
 			w.WriteByte(',')
 		}
-		placeHolders, err = mt.writeQuoted(w, placeHolders)
+		placeHolders, err = mt.writeQuoted(w, d, placeHolders)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 	if len(b.MultiTables) > 0 {
 		w.WriteByte(' ')
-		if b.Returning != nil {
+		if len(b.ReturningColumns) > 0 {
 			return nil, errors.NotAllowed.Newf("[dml] MariaDB does not support RETURNING in multi-table DELETEs")
 		}
 	}
 
-	w.WriteString("FROM ")
-	placeHolders, err = b.Table.writeQuoted(w, placeHolders)
+	if isUsing {
+		w.WriteString("USING ")
+	} else {
+		w.WriteString("FROM ")
+	}
+	placeHolders, err = b.Table.writeQuoted(w, d, placeHolders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -298,26 +324,25 @@ func (b *Delete) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err
 		w.WriteByte(' ')
 		w.WriteString(f.JoinType)
 		w.WriteString(" JOIN ")
-		if placeHolders, err = f.Table.writeQuoted(w, placeHolders); err != nil {
+		if placeHolders, err = f.Table.writeQuoted(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
-		if placeHolders, err = f.On.write(w, 'j', placeHolders, b.isWithDBR); err != nil {
+		if placeHolders, err = f.On.write(w, d, 'j', placeHolders, b.isWithDBR); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 
-	placeHolders, err = b.Wheres.write(w, 'w', placeHolders, b.isWithDBR)
+	placeHolders, err = b.Wheres.write(w, d, 'w', placeHolders, b.isWithDBR)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	sqlWriteOrderBy(w, b.OrderBys, false)
+	sqlWriteOrderBy(w, d, b.OrderBys, false)
 	sqlWriteLimitOffset(w, b.LimitValid, false, 0, b.LimitCount)
 
-	if b.Returning != nil {
+	if len(b.ReturningColumns) > 0 {
 		w.WriteString(" RETURNING ")
-		placeHolders, err = b.Returning.toSQL(w, placeHolders)
-		if err != nil {
+		if placeHolders, err = b.ReturningColumns.writeQuoted(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
@@ -362,6 +387,6 @@ func (b *Delete) Clone() *Delete {
 	c.BuilderBase = b.BuilderBase.Clone()
 	c.BuilderConditional = b.BuilderConditional.Clone()
 	c.MultiTables = b.MultiTables.Clone()
-	c.Returning = b.Returning.Clone()
+	c.ReturningColumns = b.ReturningColumns.Clone()
 	return &c
 }
@@ -238,6 +238,24 @@ func TestDelete_Join(t *testing.T) {
 			"",
 		)
 	})
+
+	t.Run("FromTablesUsing", func(t *testing.T) {
+		del := dml.NewDelete("customer_entity").Alias("ce").
+			FromTablesUsing("ca").
+			Join(
+				dml.MakeIdentifier("customer_address").Alias("ca"),
+				dml.Column("ce.entity_id").Equal().Column("ca.parent_id"),
+			).
+			Where(
+				dml.Column("ce.created_at").Less().PlaceHolder(),
+			)
+
+		compareToSQL(t, del.WithDBR().TestWithArgs(now()), errors.NoKind,
+			"DELETE FROM `ce`,`ca` USING `customer_entity` AS `ce` INNER JOIN `customer_address` AS `ca` ON (`ce`.`entity_id` = `ca`.`parent_id`) WHERE (`ce`.`created_at` < ?)",
+			"DELETE FROM `ce`,`ca` USING `customer_entity` AS `ce` INNER JOIN `customer_address` AS `ca` ON (`ce`.`entity_id` = `ca`.`parent_id`) WHERE (`ce`.`created_at` < '2006-01-02 15:04:05')",
+			now(),
+		)
+	})
 }
 
 func TestDelete_Returning(t *testing.T) {
@@ -250,7 +268,7 @@ func TestDelete_Returning(t *testing.T) {
 				dml.MakeIdentifier("customer_address").Alias("ca"),
 				dml.Columns("ce.entity_id", "ca.parent_id"),
 			)
-		del.Returning = dml.NewSelect()
+		del.Returning("entity_id")
 		compareToSQL(t, del, errors.NotAllowed,
 			"",
 			"",
@@ -262,9 +280,9 @@ func TestDelete_Returning(t *testing.T) {
 			Where(
 				dml.Column("ce.entity_id").GreaterOrEqual().PlaceHolder(),
 			)
-		del.Returning = dml.NewSelect("entity_id", "created_at").From("customer_entity")
+		del.Returning("entity_id", "created_at")
 		compareToSQL(t, del, errors.NoKind,
-			"DELETE FROM `customer_entity` WHERE (`ce`.`entity_id` >= ?) RETURNING SELECT `entity_id`, `created_at` FROM `customer_entity`",
+			"DELETE FROM `customer_entity` WHERE (`ce`.`entity_id` >= ?) RETURNING `entity_id`, `created_at`",
 			"",
 		)
 	})
@@ -27,7 +27,9 @@ const (
 	namedArgStartByte   = ':'
 )
 
-var dialect dialecter = mysqlDialect{
+// dialect contains the default settings. Statements of a connection use the
+// settings of the connection, see builderCommon.sqlDialect.
+var dialect = mysqlDialect{
 	identR: strings.NewReplacer("`", "``", ".", "`.`"),
 }
 
@@ -46,6 +48,12 @@ const mysqlTimeFormat = "2006-01-02 15:04:05"
 
 type mysqlDialect struct {
 	identR *strings.Replacer
+	// noBackslashEscapes if true, the string escaper follows the sql_mode
+	// NO_BACKSLASH_ESCAPES. See WithNoBackslashEscapes.
+	noBackslashEscapes bool
+	// jsonMembershipFallback contains the flags fallbackMemberOf and
+	// fallbackJSONOverlaps. See WithJSONMembershipFallback.
+	jsonMembershipFallback uint8
 }
 
 func (d mysqlDialect) EscapeIdent(w *bytes.Buffer, ident string) {
@@ -74,7 +82,12 @@ func (d mysqlDialect) EscapeBinary(w *bytes.Buffer, b []byte) {
 
 // EscapeString. Need to turn \x00, \n, \r, \, ', " and \x1a.
 // Returns an escaped, quoted string. eg, "hello 'world'" -> "'hello \'world\''".
+// With enabled NO_BACKSLASH_ESCAPES only the single quote gets doubled.
 func (d mysqlDialect) EscapeString(w *bytes.Buffer, s string) {
+	if d.noBackslashEscapes {
+		escapeStringQuotes(w, s)
+		return
+	}
 	w.WriteByte('\'')
 	for _, char := range s {
 		// for each case, don't use write rune 8-)
@@ -100,6 +113,21 @@ func (d mysqlDialect) EscapeString(w *bytes.Buffer, s string) {
 	w.WriteByte('\'')
 }
 
+// escapeStringQuotes escapes a string for the sql_mode NO_BACKSLASH_ESCAPES.
+// In this mode the backslash is an ordinary character and the only way to
+// escape a single quote is to double it. eg, "hello 'world'" -> "'hello
+// ''world'''".
+func escapeStringQuotes(w *bytes.Buffer, s string) {
+	w.WriteByte('\'')
+	for i := strings.IndexByte(s, '\''); i >= 0; i = strings.IndexByte(s, '\'') {
+		w.WriteString(s[:i+1])
+		w.WriteByte('\'')
+		s = s[i+1:]
+	}
+	w.WriteString(s)
+	w.WriteByte('\'')
+}
+
 func (d mysqlDialect) EscapeTime(w *bytes.Buffer, t time.Time) {
 	if t.IsZero() {
 		w.WriteString("'0000-00-00'") //  00:00:00
@@ -15,10 +15,13 @@
 package dml
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
 	"github.com/corestoreio/pkg/util/naughtystrings"
 )
 
@@ -48,3 +51,90 @@ func TestEscapeWith_NaughtyStrings(t *testing.T) {
 		sel.Wheres = sel.Wheres[:0]
 	}
 }
+
+func TestEscapeString_NoBackslashEscapes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		have            string
+		wantDefault     string
+		wantNoBackslash string
+	}{
+		{"hello 'world'", `'hello \'world\''`, `'hello ''world'''`},
+		{`C:\path\`, `'C:\\path\\'`, `'C:\path\'`},
+		{`\'; DROP TABLE x; --`, `'\\\'; DROP TABLE x; --'`, `'\''; DROP TABLE x; --'`},
+		{"''", `'\'\''`, `''''''`},
+		{"a\nb\"c", `'a\nb\"c'`, "'a\nb\"c'"},
+	}
+	d := dialect
+	d.noBackslashEscapes = true
+	buf := new(bytes.Buffer)
+	for _, test := range tests {
+		dialect.EscapeString(buf, test.have)
+		assert.Exactly(t, test.wantDefault, buf.String(), "%q", test.have)
+		buf.Reset()
+
+		d.EscapeString(buf, test.have)
+		assert.Exactly(t, test.wantNoBackslash, buf.String(), "%q", test.have)
+		buf.Reset()
+	}
+}
+
+func TestWithDetectNoBackslashEscapes(t *testing.T) {
+	t.Parallel()
+
+	newConnPool := func(sqlMode string) (*ConnPool, sqlmock.Sqlmock) {
+		db, dbMock, err := sqlmock.New()
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+			_ = db.Close()
+		})
+		dbMock.ExpectQuery("SELECT @@SESSION.sql_mode").
+			WillReturnRows(sqlmock.NewRows([]string{"sql_mode"}).AddRow(sqlMode))
+		dbc, err := NewConnPool(WithDB(db), WithDetectNoBackslashEscapes(context.TODO()))
+		assert.NoError(t, err)
+		return dbc, dbMock
+	}
+	// the pools must not influence each other
+	dbcNBE, dbMockNBE := newConnPool("STRICT_TRANS_TABLES,NO_BACKSLASH_ESCAPES")
+	dbcDefault, _ := newConnPool("STRICT_TRANS_TABLES")
+
+	t.Run("build", func(t *testing.T) {
+		sqlStr, _, err := dbcNBE.SelectFrom("dml_people").AddColumns("id").Where(Column("name").Str(`O\'Reilly`)).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\''Reilly')", sqlStr)
+
+		sqlStr, _, err = dbcDefault.SelectFrom("dml_people").AddColumns("id").Where(Column("name").Str(`O\'Reilly`)).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\\\\\'Reilly')", sqlStr)
+	})
+	t.Run("sub select of a standalone builder", func(t *testing.T) {
+		sqlStr, _, err := dbcNBE.SelectFrom("dml_people").AddColumns("id").Where(
+			Column("id").In().Sub(NewSelect("id").From("dml_people").Where(Column("name").Str(`O\'Reilly`))),
+		).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`id` IN (SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\''Reilly')))", sqlStr)
+	})
+	t.Run("interpolate", func(t *testing.T) {
+		sqlStr, _, err := dbcNBE.SelectFrom("dml_people").AddColumns("id").Where(Column("name").PlaceHolder()).
+			WithDBR().Interpolate().testWithArgs(`O\'Reilly`).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\''Reilly')", sqlStr)
+
+		sqlStr, _, err = dbcDefault.SelectFrom("dml_people").AddColumns("id").Where(Column("name").PlaceHolder()).
+			WithDBR().Interpolate().testWithArgs(`O\'Reilly`).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\\\\\'Reilly')", sqlStr)
+	})
+	t.Run("transaction", func(t *testing.T) {
+		dbMockNBE.ExpectBegin()
+		dbMockNBE.ExpectRollback()
+		tx, err := dbcNBE.BeginTx(context.TODO(), nil)
+		assert.NoError(t, err)
+		sqlStr, _, err := tx.SelectFrom("dml_people").AddColumns("id").Where(Column("name").Str(`O\'Reilly`)).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `id` FROM `dml_people` WHERE (`name` = 'O\\''Reilly')", sqlStr)
+		assert.NoError(t, tx.Rollback())
+	})
+}
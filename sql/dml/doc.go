@@ -47,9 +47,4 @@
 // optimistic concurrency and use serializable isolation.
 //
 // TODO(CyS) refactor some parts of the code once Go implements generics ;-)
-//
-// TODO(CyS) implement usage of window functions:
-//    - https://mariadb.com/kb/en/library/window-functions/
-//    - https://dev.mysql.com/doc/refman/8.0/en/window-functions-usage.html
-//    - https://blog.statsbot.co/sql-window-functions-tutorial-b5075b87d129
 package dml
@@ -0,0 +1,236 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eav builds the query fragments for the Magento style
+// entity-attribute-value tables.
+//
+// An EAV entity consists of the entity table, e.g. catalog_product_entity,
+// and one value table per backend type, e.g. catalog_product_entity_varchar.
+// A value row belongs to a store; the store ID zero contains the default
+// value which applies when a store has no own value. The attributes get
+// resolved by their code via the attribute table eav_attribute, so no IDs
+// need to be hard coded.
+//
+//		product := eav.Entity{Table: "catalog_product_entity", TypeID: 4}
+//		sel, err := product.Select(1,
+//			eav.Attribute{Code: "name", BackendType: eav.TypeVarchar},
+//			eav.Attribute{Code: "status", BackendType: eav.TypeInt},
+//		)
+//		// SELECT `e`.*, IFNULL(`name_s`.`value`,`name_d`.`value`) AS `name`, ...
+//		// FROM `catalog_product_entity` AS `e`
+//		// LEFT JOIN `catalog_product_entity_varchar` AS `name_d` ON ... `store_id` = 0
+//		// LEFT JOIN `catalog_product_entity_varchar` AS `name_s` ON ... `store_id` = 1
+package eav
+
+import (
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// Backend types of an attribute. Each type except TypeStatic has its own
+// value table. Static attributes are columns of the entity table.
+const (
+	TypeDatetime = "datetime"
+	TypeDecimal  = "decimal"
+	TypeInt      = "int"
+	TypeStatic   = "static"
+	TypeText     = "text"
+	TypeVarchar  = "varchar"
+)
+
+// DefaultStoreID defines the store which contains the default values.
+const DefaultStoreID = 0
+
+// valueTypes contains all backend types with a value table, in the order of
+// Magento.
+var valueTypes = [...]string{TypeVarchar, TypeInt, TypeDecimal, TypeDatetime, TypeText}
+
+// Attribute defines an attribute by its code and its backend type.
+type Attribute struct {
+	Code string
+	// BackendType selects the value table, see the Type* constants.
+	BackendType string
+}
+
+func (a Attribute) validate() error {
+	if err := dml.IsValidIdentifier(a.Code); err != nil {
+		return errors.NotValid.New(err, "[eav] Attribute code %q is not valid", a.Code)
+	}
+	switch a.BackendType {
+	case TypeStatic, TypeVarchar, TypeInt, TypeDecimal, TypeDatetime, TypeText:
+		return nil
+	}
+	return errors.NotSupported.Newf("[eav] Attribute %q backend type %q not supported", a.Code, a.BackendType)
+}
+
+// Entity defines the tables of an EAV entity. Only field Table is required.
+type Entity struct {
+	// Table defines the entity table, e.g. catalog_product_entity. The value
+	// tables get derived from it, e.g. catalog_product_entity_int.
+	Table string
+	// Alias of the entity table. Defaults to "e".
+	Alias string
+	// TypeID restricts the attribute lookup to the entity type, e.g. 4 for
+	// products. Zero disables the restriction, which can match the
+	// attribute of another entity type with the same code.
+	TypeID int64
+	// LinkField defines the column which links the value tables to the entity
+	// table. Defaults to entity_id. Magento Commerce uses row_id.
+	LinkField string
+	// AttributeTable defines the table to look up the attribute IDs.
+	// Defaults to eav_attribute.
+	AttributeTable string
+}
+
+func (e Entity) alias() string {
+	if e.Alias == "" {
+		return "e"
+	}
+	return e.Alias
+}
+
+func (e Entity) linkField() string {
+	if e.LinkField == "" {
+		return "entity_id"
+	}
+	return e.LinkField
+}
+
+func (e Entity) attributeTable() string {
+	if e.AttributeTable == "" {
+		return "eav_attribute"
+	}
+	return e.AttributeTable
+}
+
+// ValueTable returns the name of the value table of the backend type.
+func (e Entity) ValueTable(backendType string) string {
+	return e.Table + "_" + backendType
+}
+
+// AttributeID returns the sub select which resolves the attribute ID by its
+// code.
+func (e Entity) AttributeID(code string) *dml.Select {
+	sel := dml.NewSelect("attribute_id").From(e.attributeTable()).Where(
+		dml.Column("attribute_code").Str(code),
+	)
+	if e.TypeID > 0 {
+		sel.Where(dml.Column("entity_type_id").Int64(e.TypeID))
+	}
+	return sel
+}
+
+// JoinAttributes adds to sel for each attribute the value column with the
+// store fallback. The value of the store gets used and if the store has no
+// value, the default value of store zero. sel must select from the entity
+// table with the alias of the Entity. Each attribute gets joined twice with
+// the aliases <code>_d for the default and <code>_s for the store scope. The
+// column alias is the attribute code. For the default scope only, set storeID
+// to DefaultStoreID.
+func (e Entity) JoinAttributes(sel *dml.Select, storeID int64, attrs ...Attribute) (*dml.Select, error) {
+	if e.Table == "" {
+		return nil, errors.Empty.Newf("[eav] Entity table cannot be empty")
+	}
+	ea := e.alias()
+	for _, a := range attrs {
+		if err := a.validate(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if a.BackendType == TypeStatic {
+			sel.AddColumns(ea + "." + a.Code)
+			continue
+		}
+		aliasDefault := a.Code + "_d"
+		sel.LeftJoin(dml.MakeIdentifier(e.ValueTable(a.BackendType)).Alias(aliasDefault), e.joinConditions(aliasDefault, a.Code, DefaultStoreID)...)
+		if storeID == DefaultStoreID {
+			sel.AddColumnsAliases(aliasDefault+".value", a.Code)
+			continue
+		}
+		aliasStore := a.Code + "_s"
+		sel.LeftJoin(dml.MakeIdentifier(e.ValueTable(a.BackendType)).Alias(aliasStore), e.joinConditions(aliasStore, a.Code, storeID)...)
+		sel.AddColumnsConditions(dml.SQLIfNull(aliasStore, "value", aliasDefault, "value").Alias(a.Code))
+	}
+	return sel, nil
+}
+
+// ScopeColumn returns the column alias of JoinAttributeScopes for the
+// attribute code.
+func ScopeColumn(code string) string { return code + "_scope" }
+
+// JoinAttributeScopes adds to sel, which must have been passed to
+// JoinAttributes, for each attribute a boolean column which reports whether
+// the store defines the value itself. A false value means the value has been
+// inherited from the default store or is NULL. The column alias gets created
+// with ScopeColumn. Static attributes get skipped.
+func (e Entity) JoinAttributeScopes(sel *dml.Select, storeID int64, attrs ...Attribute) *dml.Select {
+	for _, a := range attrs {
+		if a.BackendType == TypeStatic {
+			continue
+		}
+		valueAlias := a.Code + "_s"
+		if storeID == DefaultStoreID {
+			valueAlias = a.Code + "_d"
+		}
+		sel.AddColumnsConditions(dml.Expr("(" + dml.Quoter.QualifierName(valueAlias, "store_id") + " IS NOT NULL)").Alias(ScopeColumn(a.Code)))
+	}
+	return sel
+}
+
+func (e Entity) joinConditions(valueAlias, code string, storeID int64) []*dml.Condition {
+	return []*dml.Condition{
+		dml.Column(valueAlias + "." + e.linkField()).Equal().Column(e.alias() + "." + e.linkField()),
+		dml.Column(valueAlias + ".attribute_id").Equal().Sub(e.AttributeID(code)),
+		dml.Column(valueAlias + ".store_id").Int64(storeID),
+	}
+}
+
+// Select creates a new SELECT statement with all columns of the entity table
+// and the attribute values of the store, see JoinAttributes. Add further
+// conditions to the returned Select, e.g. the entity IDs.
+func (e Entity) Select(storeID int64, attrs ...Attribute) (*dml.Select, error) {
+	sel := dml.NewSelect(e.alias()+".*").FromAlias(e.Table, e.alias())
+	return e.JoinAttributes(sel, storeID, attrs...)
+}
+
+// ValuesUnion creates a UNION ALL over the value tables which loads all
+// attribute values of one entity for a store and the default store. The
+// columns are value, attribute_id and store_id. The rows of a store come
+// before the default values of the same attribute, so the first row per
+// attribute_id wins. Without backend types all value tables get queried.
+// The arguments are the entity ID and the store IDs including the default
+// store, e.g. WithDBR().Load(ctx, values, 1561, []int64{1, eav.DefaultStoreID}).
+func (e Entity) ValuesUnion(backendTypes ...string) (*dml.Union, error) {
+	if e.Table == "" {
+		return nil, errors.Empty.Newf("[eav] Entity table cannot be empty")
+	}
+	if len(backendTypes) == 0 {
+		backendTypes = valueTypes[:]
+	}
+	for _, bt := range backendTypes {
+		if bt == TypeStatic {
+			return nil, errors.NotSupported.Newf("[eav] Backend type %q has no value table", bt)
+		}
+		if err := (Attribute{Code: "value", BackendType: bt}).validate(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	sel := dml.NewSelect("t.value", "t.attribute_id", "t.store_id").
+		FromAlias(e.ValueTable("$type$"), "t").
+		Where(
+			dml.Column("t."+e.linkField()).PlaceHolder(),
+			dml.Column("t.store_id").In().PlaceHolder(),
+		)
+	return dml.NewUnion(sel).StringReplace("$type$", backendTypes...).All().
+		OrderBy("attribute_id").OrderByDesc("store_id"), nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eav_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dml/eav"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+var product = eav.Entity{Table: "catalog_product_entity", TypeID: 4}
+
+func TestEntity_Select(t *testing.T) {
+	t.Parallel()
+
+	t.Run("store fallback", func(t *testing.T) {
+		sel, err := product.Select(1,
+			eav.Attribute{Code: "sku", BackendType: eav.TypeStatic},
+			eav.Attribute{Code: "name", BackendType: eav.TypeVarchar},
+		)
+		assert.NoError(t, err)
+		sel.Where(dml.Column("e.entity_id").In().Int64s(1561, 1562))
+
+		sqlStr, args, err := sel.ToSQL()
+		assert.NoError(t, err)
+		assert.Nil(t, args)
+		assert.Exactly(t, "SELECT `e`.*, `e`.`sku`, IFNULL(`name_s`.`value`,`name_d`.`value`) AS `name` FROM `catalog_product_entity` AS `e`"+
+			" LEFT JOIN `catalog_product_entity_varchar` AS `name_d` ON (`name_d`.`entity_id` = `e`.`entity_id`) AND (`name_d`.`attribute_id` = (SELECT `attribute_id` FROM `eav_attribute` WHERE (`attribute_code` = 'name') AND (`entity_type_id` = 4))) AND (`name_d`.`store_id` = 0)"+
+			" LEFT JOIN `catalog_product_entity_varchar` AS `name_s` ON (`name_s`.`entity_id` = `e`.`entity_id`) AND (`name_s`.`attribute_id` = (SELECT `attribute_id` FROM `eav_attribute` WHERE (`attribute_code` = 'name') AND (`entity_type_id` = 4))) AND (`name_s`.`store_id` = 1)"+
+			" WHERE (`e`.`entity_id` IN (1561,1562))", sqlStr)
+	})
+
+	t.Run("scopes", func(t *testing.T) {
+		attrs := []eav.Attribute{
+			{Code: "sku", BackendType: eav.TypeStatic},
+			{Code: "name", BackendType: eav.TypeVarchar},
+		}
+		sel, err := product.Select(1, attrs...)
+		assert.NoError(t, err)
+		product.JoinAttributeScopes(sel, 1, attrs...)
+
+		sqlStr, _, err := sel.ToSQL()
+		assert.NoError(t, err)
+		assert.Contains(t, sqlStr, "IFNULL(`name_s`.`value`,`name_d`.`value`) AS `name`, (`name_s`.`store_id` IS NOT NULL) AS `name_scope` FROM")
+
+		sel, err = product.Select(eav.DefaultStoreID, attrs...)
+		assert.NoError(t, err)
+		product.JoinAttributeScopes(sel, eav.DefaultStoreID, attrs...)
+		sqlStr, _, err = sel.ToSQL()
+		assert.NoError(t, err)
+		assert.Contains(t, sqlStr, "`name_d`.`value` AS `name`, (`name_d`.`store_id` IS NOT NULL) AS `name_scope` FROM")
+	})
+
+	t.Run("default store with row_id", func(t *testing.T) {
+		e := eav.Entity{Table: "catalog_category_entity", Alias: "cat", LinkField: "row_id"}
+		sel, err := e.Select(eav.DefaultStoreID, eav.Attribute{Code: "is_active", BackendType: eav.TypeInt})
+		assert.NoError(t, err)
+
+		sqlStr, _, err := sel.ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT `cat`.*, `is_active_d`.`value` AS `is_active` FROM `catalog_category_entity` AS `cat`"+
+			" LEFT JOIN `catalog_category_entity_int` AS `is_active_d` ON (`is_active_d`.`row_id` = `cat`.`row_id`) AND (`is_active_d`.`attribute_id` = (SELECT `attribute_id` FROM `eav_attribute` WHERE (`attribute_code` = 'is_active'))) AND (`is_active_d`.`store_id` = 0)", sqlStr)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := eav.Entity{}.Select(1, eav.Attribute{Code: "name", BackendType: eav.TypeVarchar})
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = product.Select(1, eav.Attribute{Code: "name", BackendType: "blob"})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+		_, err = product.Select(1, eav.Attribute{Code: "na`me", BackendType: eav.TypeVarchar})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+func TestEntity_ValuesUnion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("selected types", func(t *testing.T) {
+		u, err := product.ValuesUnion(eav.TypeVarchar, eav.TypeInt)
+		assert.NoError(t, err)
+
+		sqlStr, args, err := u.WithDBR().TestWithArgs(1561, []int64{1, eav.DefaultStoreID}).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "(SELECT `t`.`value`, `t`.`attribute_id`, `t`.`store_id` FROM `catalog_product_entity_varchar` AS `t` WHERE (`t`.`entity_id` = ?) AND (`t`.`store_id` IN ?))\n"+
+			"UNION ALL\n"+
+			"(SELECT `t`.`value`, `t`.`attribute_id`, `t`.`store_id` FROM `catalog_product_entity_int` AS `t` WHERE (`t`.`entity_id` = ?) AND (`t`.`store_id` IN ?))\n"+
+			"ORDER BY `attribute_id`, `store_id` DESC", sqlStr)
+		assert.Exactly(t, []interface{}{int64(1561), int64(1), int64(0), int64(1561), int64(1), int64(0)}, args)
+	})
+
+	t.Run("all types", func(t *testing.T) {
+		u, err := product.ValuesUnion()
+		assert.NoError(t, err)
+		sqlStr, _, err := u.ToSQL()
+		assert.NoError(t, err)
+		for _, bt := range []string{"varchar", "int", "decimal", "datetime", "text"} {
+			assert.Contains(t, sqlStr, "`catalog_product_entity_"+bt+"`")
+		}
+	})
+
+	t.Run("static not supported", func(t *testing.T) {
+		_, err := product.ValuesUnion(eav.TypeStatic)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
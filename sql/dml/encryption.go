@@ -0,0 +1,487 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/corestoreio/errors"
+)
+
+// encryptionHeader gets prepended to each encrypted value, followed by the key
+// version as uint32 big endian, the nonce and the sealed data. The first byte
+// is not valid UTF-8, so plain text legacy values never start with the header
+// and get passed through unchanged. The last byte of the header defines the
+// additional data: encryptionBoundTable values are bound to table and column,
+// encryptionBoundRow values additionally to the primary key.
+var encryptionHeader = []byte{0xfe, 'e', 'n', encryptionBoundTable}
+
+const (
+	encryptionBoundTable byte = 0x01
+	encryptionBoundRow   byte = 0x02
+	encryptionPrefixLen       = 8 // header plus key version
+)
+
+// EncryptionKeyProvider provides the AES keys for WithColumnEncryption. A key
+// must have a length of 16, 24 or 32 bytes to select AES-128, AES-192 or
+// AES-256. The key of a version must never change, otherwise existing values
+// can't be decrypted anymore. Implementations can load the keys from a KMS or
+// a vault and must be safe for concurrent use.
+type EncryptionKeyProvider interface {
+	// CurrentKey returns the key and its version to encrypt new values.
+	CurrentKey() (version uint32, key []byte, err error)
+	// Key returns the key of a version to decrypt existing values.
+	Key(version uint32) ([]byte, error)
+}
+
+// EncryptionKeys implements a static EncryptionKeyProvider. To rotate a key
+// add the new key and set Current to its version. Old keys must be kept until
+// all values have been re-encrypted with ConnPool.ReEncryptColumn.
+type EncryptionKeys struct {
+	Current uint32
+	Keys    map[uint32][]byte
+}
+
+// CurrentKey implements EncryptionKeyProvider.
+func (ek EncryptionKeys) CurrentKey() (uint32, []byte, error) {
+	k, err := ek.Key(ek.Current)
+	return ek.Current, k, err
+}
+
+// Key implements EncryptionKeyProvider.
+func (ek EncryptionKeys) Key(version uint32) ([]byte, error) {
+	k, ok := ek.Keys[version]
+	if !ok {
+		return nil, errors.NotFound.Newf("[dml] EncryptionKeys: key version %d not found", version)
+	}
+	return k, nil
+}
+
+type columnEncrypter struct {
+	kp       EncryptionKeyProvider
+	table    string
+	pkColumn string
+	mu       sync.RWMutex
+	// aeads caches the ciphers per key version.
+	aeads map[uint32]cipher.AEAD
+}
+
+func (ce *columnEncrypter) aead(version uint32, key []byte) (cipher.AEAD, error) {
+	ce.mu.RLock()
+	a, ok := ce.aeads[version]
+	ce.mu.RUnlock()
+	if ok {
+		return a, nil
+	}
+	if key == nil {
+		var err error
+		if key, err = ce.kp.Key(version); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.NotValid.New(err, "[dml] Invalid AES key with version %d", version)
+	}
+	if a, err = cipher.NewGCM(block); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ce.mu.Lock()
+	ce.aeads[version] = a
+	ce.mu.Unlock()
+	return a, nil
+}
+
+// additionalData binds an encrypted value to the table, the column and, if
+// known, to the primary key of the row. So an encrypted value can't be copied
+// into another column or another row.
+func (ce *columnEncrypter) additionalData(column, pk string, boundRow bool) []byte {
+	ad := make([]byte, 0, len(ce.table)+len(column)+len(pk)+2)
+	ad = append(append(append(ad, ce.table...), 0), column...)
+	if boundRow {
+		ad = append(append(ad, 0), pk...)
+	}
+	return ad
+}
+
+// encrypt encrypts data with the current key. If the primary key pk of the row
+// is unknown, like for an INSERT with an auto increment column, the value gets
+// only bound to the table and the column.
+func (ce *columnEncrypter) encrypt(column, pk string, boundRow bool, data []byte) ([]byte, error) {
+	version, key, err := ce.kp.CurrentKey()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	a, err := ce.aead(version, key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dst := make([]byte, encryptionPrefixLen+a.NonceSize(), encryptionPrefixLen+a.NonceSize()+len(data)+a.Overhead())
+	copy(dst, encryptionHeader)
+	if boundRow {
+		dst[len(encryptionHeader)-1] = encryptionBoundRow
+	}
+	binary.BigEndian.PutUint32(dst[len(encryptionHeader):], version)
+	nonce := dst[encryptionPrefixLen:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return a.Seal(dst, nonce, data, ce.additionalData(column, pk, boundRow)), nil
+}
+
+// decrypt decrypts data, which must start with the encryption header. The
+// primary key pk gets only used if the value has been bound to the row. A
+// getPK returning false reports that the primary key is not available.
+func (ce *columnEncrypter) decrypt(column string, getPK func() (string, bool), data []byte) ([]byte, error) {
+	version, ok := EncryptedKeyVersion(data)
+	if !ok {
+		return nil, errors.BadEncoding.Newf("[dml] Encrypted value of column %q is too short", column)
+	}
+	var pk string
+	boundRow := data[len(encryptionHeader)-1] == encryptionBoundRow
+	if boundRow {
+		if pk, ok = getPK(); !ok {
+			return nil, errors.NotFound.Newf("[dml] Encrypted value of column %q requires the primary key column %q in the result set", column, ce.pkColumn)
+		}
+	}
+	a, err := ce.aead(version, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data = data[encryptionPrefixLen:]
+	if len(data) < a.NonceSize() {
+		return nil, errors.BadEncoding.Newf("[dml] Encrypted value of column %q is too short", column)
+	}
+	plain, err := a.Open(nil, data[:a.NonceSize()], data[a.NonceSize():], ce.additionalData(column, pk, boundRow))
+	if err != nil {
+		return nil, errors.BadEncoding.New(err, "[dml] Column %q failed to decrypt with key version %d", column, version)
+	}
+	return plain, nil
+}
+
+// EncryptedKeyVersion returns the key version of a value encrypted by
+// WithColumnEncryption. Returns false if the value is not encrypted.
+func EncryptedKeyVersion(value []byte) (version uint32, ok bool) {
+	if !hasEncryptionHeader(value) || len(value) < encryptionPrefixLen {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(value[len(encryptionHeader):]), true
+}
+
+func hasEncryptionHeader(value []byte) bool {
+	l := len(encryptionHeader) - 1
+	return len(value) > l && bytes.Equal(value[:l], encryptionHeader[:l]) &&
+		(value[l] == encryptionBoundTable || value[l] == encryptionBoundRow)
+}
+
+// WithColumnEncryption encrypts transparently the values of the columns of a
+// table with AES-GCM before writing and decrypts them while scanning. Each
+// value gets tagged with the version of the key, so keys can be rotated; see
+// ConnPool.ReEncryptColumn. Each value gets bound to the table, the column and
+// the primary key of the row, so it can't be copied into another row. Values
+// written without the primary key in the statement, like with an auto
+// increment column, get only bound to the table and the column until
+// ReEncryptColumn binds them to their row. Reading a value bound to the row
+// requires the primary key column in the result set. Like
+// WithColumnCompression, the encryption applies to the ColumnMap functions
+// Byte, String and NullString when the column name is known. Compressed columns
+// get first compressed and then encrypted. Values without the encryption
+// header, for example rows written before enabling the encryption, get passed
+// through. The columns must have a binary data type like BLOB or VARBINARY and
+// can't be searched anymore. The column names should not contain a qualifier
+// and must be unique across all tables with encrypted columns. Only builders
+// created after applying this option use the encryption.
+//		dml.WithColumnEncryption(dml.EncryptionKeys{
+//			Current: 2,
+//			Keys:    map[uint32][]byte{1: key1, 2: key2},
+//		}, "customer_entity", "entity_id", "iban", "tax_vat")
+func WithColumnEncryption(kp EncryptionKeyProvider, table, pkColumn string, columns ...string) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 3,
+		fn: func(c *ConnPool) error {
+			if kp == nil {
+				return errors.NotValid.Newf("[dml] WithColumnEncryption requires a key provider for columns %v", columns)
+			}
+			if table == "" || pkColumn == "" {
+				return errors.Empty.Newf("[dml] WithColumnEncryption requires the table and the primary key column for columns %v", columns)
+			}
+			ce := &columnEncrypter{kp: kp, table: table, pkColumn: pkColumn, aeads: make(map[uint32]cipher.AEAD)}
+			// Fail early with an invalid current key.
+			version, key, err := kp.CurrentKey()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err := ce.aead(version, key); err != nil {
+				return errors.WithStack(err)
+			}
+			// copy on write because existing builders might still use the map.
+			m := make(map[string]*columnEncrypter, len(c.encryption)+len(columns))
+			for k, v := range c.encryption {
+				m[k] = v
+			}
+			for _, col := range columns {
+				if prev, ok := m[col]; ok && prev.table != table {
+					return errors.AlreadyExists.Newf("[dml] WithColumnEncryption: Column %q of table %q has already been registered for table %q", col, table, prev.table)
+				}
+				m[col] = ce
+			}
+			c.encryption = m
+			return nil
+		},
+	}
+}
+
+// encrypter returns the encrypter of the current column or nil.
+func (b *ColumnMap) encrypter() *columnEncrypter {
+	if len(b.encryption) == 0 {
+		return nil
+	}
+	return b.encryption[b.unqualifiedColumn()]
+}
+
+// pendingEncryption gets appended as an argument and replaced by the encrypted
+// value in sealEncrypted, because the primary key of the row might get mapped
+// after the encrypted column.
+type pendingEncryption struct {
+	ce     *columnEncrypter
+	column string
+	data   []byte
+}
+
+// encodeValue compresses the data of the current column and prepares the
+// encryption.
+func (b *ColumnMap) encodeValue(cc *columnCompressor, ce *columnEncrypter, data []byte) interface{} {
+	if cc != nil && cc.enc != nil {
+		data = cc.compress(data)
+	}
+	if ce != nil {
+		return &pendingEncryption{ce: ce, column: b.unqualifiedColumn(), data: data}
+	}
+	return data
+}
+
+// sealEncrypted encrypts the pending values in args. The arguments belong to
+// the columns, repeated for each row. The primary key value of a row gets used
+// as additional data, if the row contains the primary key column. An error
+// aborts the statement.
+func sealEncrypted(args []interface{}, columns []string) error {
+	for i, arg := range args {
+		pe, ok := arg.(*pendingEncryption)
+		if !ok {
+			continue
+		}
+		var pk string
+		var boundRow bool
+		if lc := len(columns); lc > 0 && len(args)%lc == 0 {
+			row := i - i%lc
+			for j, c := range columns {
+				_, c = splitColumn(c)
+				if c, _ = cutNamedArgStartStr(c); c == pe.ce.pkColumn {
+					pk, boundRow = encryptionPrimaryKey(args[row+j])
+					break
+				}
+			}
+		}
+		enc, err := pe.ce.encrypt(pe.column, pk, boundRow, pe.data)
+		if err != nil {
+			return errors.Wrapf(err, "[dml] Column %q failed to encrypt", pe.column)
+		}
+		args[i] = enc
+	}
+	return nil
+}
+
+// encryptionPrimaryKey returns the textual representation of a primary key
+// value. Returns false for NULL, zero values and unsupported types. A zero
+// value triggers the auto increment, so the final primary key is unknown.
+func encryptionPrimaryKey(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), v != 0
+	case int:
+		return strconv.Itoa(v), v != 0
+	case int32:
+		return strconv.FormatInt(int64(v), 10), v != 0
+	case uint64:
+		return strconv.FormatUint(v, 10), v != 0
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), v != 0
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), v != 0
+	case string:
+		return v, v != ""
+	case []byte:
+		return string(v), len(v) > 0
+	case driver.Valuer:
+		dv, err := v.Value()
+		if err != nil || dv == nil {
+			return "", false
+		}
+		return encryptionPrimaryKey(dv)
+	}
+	return "", false
+}
+
+// decryptCurrent replaces the scanned value of the current column with its
+// decrypted value, if the value contains the encryption header.
+func (b *ColumnMap) decryptCurrent() {
+	ce := b.encrypter()
+	if ce == nil || b.scanErr != nil {
+		return
+	}
+	v := &b.scanCol[b.index]
+	var data []byte
+	switch v.field {
+	case 'y':
+		data = v.byte
+	case 's':
+		if !hasEncryptionHeader([]byte(v.string)) {
+			return
+		}
+		data = []byte(v.string)
+	default:
+		return
+	}
+	if !hasEncryptionHeader(data) {
+		return // plain text legacy value
+	}
+	plain, err := ce.decrypt(b.unqualifiedColumn(), func() (string, bool) {
+		for i, c := range b.columns {
+			if _, c = splitColumn(c); c == ce.pkColumn {
+				switch pkv := b.scanCol[i]; pkv.field {
+				case 'i', 'y', 's':
+					return pkv.String(), true
+				}
+			}
+		}
+		return "", false
+	}, data)
+	if err != nil {
+		b.scanErr = errors.WithStack(err)
+		return
+	}
+	v.field = 'y'
+	v.byte = plain
+}
+
+// ReEncryptColumn encrypts all values of a column, which have been encrypted
+// with an older key version or which are not yet encrypted, with the current
+// key. Values only bound to the table and the column get bound to their row.
+// The column must have been registered with WithColumnEncryption for the table
+// and the primary key column. The table requires an integer primary key. The
+// rows get processed in batches of batchSize rows, ordered by the primary key.
+// A row gets only updated if its value has not been changed concurrently.
+// Returns the number of updated rows.
+func (c *ConnPool) ReEncryptColumn(ctx context.Context, table, pkColumn, column string, batchSize uint64) (updated int64, err error) {
+	ce := c.encryption[column]
+	if ce == nil {
+		return 0, errors.NotFound.Newf("[dml] ReEncryptColumn: column %q has not been registered with WithColumnEncryption", column)
+	}
+	if ce.table != table || ce.pkColumn != pkColumn {
+		return 0, errors.Mismatch.Newf("[dml] ReEncryptColumn: column %q has been registered for table %q with primary key %q", column, ce.table, ce.pkColumn)
+	}
+	for _, id := range []string{table, pkColumn, column} {
+		if err := IsValidIdentifier(id); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+	version, _, err := ce.kp.CurrentKey()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	currentPrefix := make([]byte, encryptionPrefixLen)
+	copy(currentPrefix, encryptionHeader)
+	currentPrefix[len(encryptionHeader)-1] = encryptionBoundRow
+	binary.BigEndian.PutUint32(currentPrefix[len(encryptionHeader):], version)
+
+	qPK, qCol := Quoter.Name(pkColumn), Quoter.Name(column)
+	selSQL, _, err := NewSelect(pkColumn, column).From(table).Where(
+		Column(pkColumn).Greater().PlaceHolder(),
+		Column(column).NotNull(),
+		Expr("LEFT("+qCol+", "+strconv.Itoa(encryptionPrefixLen)+") <> ?"),
+	).OrderBy(pkColumn).Limit(0, batchSize).ToSQL()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	updSQL := "UPDATE " + Quoter.Name(table) + " SET " + qCol + "=? WHERE " + qPK + "=? AND " + qCol + "=?"
+
+	type row struct {
+		pk    int64
+		value []byte
+	}
+	var lastPK int64
+	for {
+		rows, err := c.DB.QueryContext(ctx, selSQL, lastPK, currentPrefix)
+		if err != nil {
+			return updated, errors.Wrapf(err, "[dml] ReEncryptColumn failed to query table %q", table)
+		}
+		var batch []row
+		for rows.Next() {
+			var r row
+			if err = rows.Scan(&r.pk, &r.value); err != nil {
+				break
+			}
+			batch = append(batch, r)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		if cErr := rows.Close(); err == nil {
+			err = cErr
+		}
+		if err != nil {
+			return updated, errors.WithStack(err)
+		}
+
+		for _, r := range batch {
+			plain := r.value
+			pk := strconv.FormatInt(r.pk, 10)
+			if hasEncryptionHeader(plain) {
+				if plain, err = ce.decrypt(column, func() (string, bool) { return pk, true }, r.value); err != nil {
+					return updated, errors.Wrapf(err, "[dml] ReEncryptColumn failed for primary key %d", r.pk)
+				}
+			}
+			enc, err := ce.encrypt(column, pk, true, plain)
+			if err != nil {
+				return updated, errors.WithStack(err)
+			}
+			res, err := c.DB.ExecContext(ctx, updSQL, enc, r.pk, r.value)
+			if err != nil {
+				return updated, errors.Wrapf(err, "[dml] ReEncryptColumn failed to update primary key %d", r.pk)
+			}
+			ra, err := res.RowsAffected()
+			if err != nil {
+				return updated, errors.WithStack(err)
+			}
+			updated += ra
+			lastPK = r.pk
+		}
+		if uint64(len(batch)) < batchSize {
+			return updated, nil
+		}
+	}
+}
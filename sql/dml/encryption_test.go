@@ -0,0 +1,235 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+// encryptedArg matches an argument encrypted with the key version and stores
+// the ciphertext. unboundRow defines that the value has been encrypted without
+// the primary key.
+type encryptedArg struct {
+	version    uint32
+	unboundRow bool
+	value      []byte
+}
+
+func (ea *encryptedArg) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	version, ok := dml.EncryptedKeyVersion(b)
+	ea.value = b
+	return ok && version == ea.version && (b[3] == 0x01) == ea.unboundRow
+}
+
+// failingKeyProvider returns an error from CurrentKey if fail is set.
+type failingKeyProvider struct {
+	dml.EncryptionKeys
+	fail bool
+}
+
+func (fkp *failingKeyProvider) CurrentKey() (uint32, []byte, error) {
+	if fkp.fail {
+		return 0, nil, errors.Unavailable.Newf("KMS not available")
+	}
+	return fkp.EncryptionKeys.CurrentKey()
+}
+
+var testEncryptionKeys = dml.EncryptionKeys{
+	Current: 1,
+	Keys: map[uint32][]byte{
+		1: bytes.Repeat([]byte{'1'}, 32),
+		2: bytes.Repeat([]byte{'2'}, 16),
+	},
+}
+
+func TestWithColumnEncryption(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	assert.NoError(t, dbc.Options(dml.WithColumnEncryption(testEncryptionKeys, "product", "id", "description", "payload", "note")))
+
+	description, payload, note := &encryptedArg{version: 1}, &encryptedArg{version: 1}, &encryptedArg{version: 1}
+
+	t.Run("encrypt arguments", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `product` (`id`,`description`,`payload`,`note`) VALUES (?,?,?,?)")).
+			WithArgs(int64(3), description, payload, note).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		_, err := dbc.InsertInto("product").AddColumns("id", "description", "payload", "note").WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{
+				ID:          3,
+				Description: "secret description",
+				Payload:     []byte("{}"),
+				Note:        null.MakeString("secret note"),
+			})
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(description.value, []byte("secret")), "ciphertext contains the plain text")
+	})
+
+	t.Run("decrypt values with legacy passthrough", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description`, `payload`, `note` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description", "payload", "note"}).
+				AddRow(3, description.value, []byte("legacy"), note.value))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("id", "description", "payload", "note").WithDBR().
+			Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, "secret description", e.Description)
+		assert.Exactly(t, []byte("legacy"), e.Payload)
+		assert.Exactly(t, null.MakeString("secret note"), e.Note)
+	})
+
+	t.Run("value of another column", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(3, note.value))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("id", "description").WithDBR().
+			Load(context.TODO(), e)
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+
+	t.Run("value of another row", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(4, description.value))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("id", "description").WithDBR().
+			Load(context.TODO(), e)
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+
+	t.Run("value bound to row requires primary key", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"description"}).AddRow(description.value))
+
+		e := new(compressedEntity)
+		_, err := dbc.SelectFrom("product").AddColumns("description").WithDBR().
+			Load(context.TODO(), e)
+		assert.ErrorIsKind(t, errors.NotFound, err)
+	})
+
+	t.Run("update binds the primary key of the WHERE clause", func(t *testing.T) {
+		updDescription := &encryptedArg{version: 1}
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `description`=? WHERE (`id` = ?)")).
+			WithArgs(updDescription, int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := dbc.Update("product").AddColumns("description").Where(dml.Column("id").PlaceHolder()).WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{ID: 3, Description: "new secret"})
+		assert.NoError(t, err)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(3, updDescription.value))
+		e := new(compressedEntity)
+		_, err = dbc.SelectFrom("product").AddColumns("id", "description").WithDBR().Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, "new secret", e.Description)
+	})
+
+	t.Run("auto increment binds only the table", func(t *testing.T) {
+		autoDescription := &encryptedArg{version: 1, unboundRow: true}
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `product` (`description`) VALUES (?)")).
+			WithArgs(autoDescription).
+			WillReturnResult(sqlmock.NewResult(5, 1))
+
+		_, err := dbc.InsertInto("product").AddColumns("description").WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{Description: "auto secret"})
+		assert.NoError(t, err)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"description"}).AddRow(autoDescription.value))
+		e := new(compressedEntity)
+		_, err = dbc.SelectFrom("product").AddColumns("description").WithDBR().Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, "auto secret", e.Description)
+	})
+
+	t.Run("re-encrypt with new key version", func(t *testing.T) {
+		keys := testEncryptionKeys
+		keys.Current = 2
+		assert.NoError(t, dbc.Options(dml.WithColumnEncryption(keys, "product", "id", "description")))
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product` WHERE (`id` > ?) AND (`description` IS NOT NULL) AND (LEFT(`description`, 8) <> ?) ORDER BY `id` LIMIT 0,2")).
+			WithArgs(int64(0), []byte{0xfe, 'e', 'n', 0x02, 0, 0, 0, 2}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).
+				AddRow(3, description.value).AddRow(4, []byte("plain text")))
+		newDescription := &encryptedArg{version: 2}
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `description`=? WHERE `id`=? AND `description`=?")).
+			WithArgs(newDescription, int64(3), description.value).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `product` SET `description`=? WHERE `id`=? AND `description`=?")).
+			WithArgs(&encryptedArg{version: 2}, int64(4), []byte("plain text")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product` WHERE")).
+			WithArgs(int64(4), []byte{0xfe, 'e', 'n', 0x02, 0, 0, 0, 2}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}))
+
+		updated, err := dbc.ReEncryptColumn(context.TODO(), "product", "id", "description", 2)
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(2), updated)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `description` FROM `product`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "description"}).AddRow(3, newDescription.value))
+		e := new(compressedEntity)
+		_, err = dbc.SelectFrom("product").AddColumns("id", "description").WithDBR().Load(context.TODO(), e)
+		assert.NoError(t, err)
+		assert.Exactly(t, "secret description", e.Description)
+
+		_, err = dbc.ReEncryptColumn(context.TODO(), "product", "id", "sku", 2)
+		assert.ErrorIsKind(t, errors.NotFound, err)
+		_, err = dbc.ReEncryptColumn(context.TODO(), "product", "sku", "description", 2)
+		assert.ErrorIsKind(t, errors.Mismatch, err)
+	})
+
+	t.Run("encryption error aborts the statement", func(t *testing.T) {
+		fkp := &failingKeyProvider{EncryptionKeys: testEncryptionKeys}
+		assert.NoError(t, dbc.Options(dml.WithColumnEncryption(fkp, "product", "id", "description")))
+		fkp.fail = true
+
+		_, err := dbc.InsertInto("product").AddColumns("id", "description").WithDBR().
+			ExecContext(context.TODO(), &compressedEntity{ID: 3, Description: "secret"})
+		assert.ErrorIsKind(t, errors.Unavailable, err)
+
+		_, err = dbc.Update("product").AddColumns("description").Where(dml.Column("id").PlaceHolder()).WithDBR().
+			ExecContext(context.TODO(), dml.Qualify("", &compressedEntity{ID: 3, Description: "secret"}))
+		assert.ErrorIsKind(t, errors.Unavailable, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		err := dbc.Options(dml.WithColumnEncryption(dml.EncryptionKeys{Current: 1, Keys: map[uint32][]byte{1: []byte("short")}}, "product", "id", "description"))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+
+	t.Run("column of another table", func(t *testing.T) {
+		err := dbc.Options(dml.WithColumnEncryption(testEncryptionKeys, "category", "id", "description"))
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+	})
+}
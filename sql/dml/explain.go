@@ -0,0 +1,321 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// ExplainPlan contains the parsed output of EXPLAIN ANALYZE of MySQL or ANALYZE
+// FORMAT=JSON of MariaDB.
+type ExplainPlan struct {
+	// Nodes contains the root nodes of the tree. The tree format of MySQL 8
+	// has one root node. The JSON format of MariaDB has one node per accessed
+	// table without children.
+	Nodes []*ExplainNode
+}
+
+// ExplainNode represents one iterator of the tree-format output of MySQL 8 or
+// one table of the JSON output of MariaDB.
+//	-> Filter: (t1.a > 1)  (cost=0.55 rows=2) (actual time=0.029..0.033 rows=2 loops=1)
+type ExplainNode struct {
+	// Type of the node, e.g. "Filter", "Table scan", "Index lookup" or
+	// "Nested loop inner join". For MariaDB the join type like "ALL" or "ref".
+	Type string
+	// Operation contains the whole description of the node, e.g. "Filter:
+	// (t1.a > 1)". For MariaDB the table name and the used key.
+	Operation     string
+	EstimatedCost float64
+	EstimatedRows float64
+	// ActualRows is the average number of rows per loop.
+	ActualRows float64
+	// ActualTimeFirst is the average time per loop to read the first row.
+	ActualTimeFirst time.Duration
+	// ActualTimeLast is the average time per loop to read all rows.
+	ActualTimeLast time.Duration
+	Loops          uint64
+	NeverExecuted  bool
+	Children       []*ExplainNode
+}
+
+// String writes the plan in a condensed tree format, useful for logging.
+func (ep *ExplainPlan) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	var write func(depth int, nodes []*ExplainNode)
+	write = func(depth int, nodes []*ExplainNode) {
+		for _, n := range nodes {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(strings.Repeat("    ", depth))
+			buf.WriteString("-> ")
+			buf.WriteString(n.Operation)
+			if n.NeverExecuted {
+				buf.WriteString(" (never executed)")
+			} else {
+				fmt.Fprintf(buf, " (actual time=%s..%s rows=%s loops=%d)", n.ActualTimeFirst, n.ActualTimeLast,
+					strconv.FormatFloat(n.ActualRows, 'f', -1, 64), n.Loops)
+			}
+			write(depth+1, n.Children)
+		}
+	}
+	write(0, ep.Nodes)
+	return buf.String()
+}
+
+// ExplainAnalyze executes the query with EXPLAIN ANALYZE and returns the
+// actual execution plan, including the actual rows and timings. The query gets
+// executed, so use it with care. The server version gets queried to choose the
+// syntax: MySQL >= 8.0.18 receives EXPLAIN ANALYZE, MariaDB >= 10.1 ANALYZE
+// FORMAT=JSON. Argument args gets passed to the underlying DBR.
+func (b *Select) ExplainAnalyze(ctx context.Context, args ...interface{}) (*ExplainPlan, error) {
+	sqlStr, qArgs, err := b.WithDBR().prepareExecution(args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ep, err := explainAnalyze(ctx, b.db, sqlStr, qArgs)
+	return ep, errors.WithStack(err)
+}
+
+func explainAnalyze(ctx context.Context, db QueryExecPreparer, sqlStr string, args []interface{}) (ep *ExplainPlan, err error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+		return nil, errors.Wrapf(err, "[dml] ExplainAnalyze failed to query the version")
+	}
+	if isMariaDB, _ := parseServerVersion(version); isMariaDB {
+		var raw []byte
+		if err := db.QueryRowContext(ctx, "ANALYZE FORMAT=JSON "+sqlStr, args...).Scan(&raw); err != nil {
+			return nil, errors.Wrapf(err, "[dml] ExplainAnalyze with query %q", sqlStr)
+		}
+		ep, err = parseAnalyzeJSON(raw)
+		return ep, errors.WithStack(err)
+	}
+
+	rows, err := db.QueryContext(ctx, "EXPLAIN ANALYZE "+sqlStr, args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[dml] ExplainAnalyze with query %q", sqlStr)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+
+	ep = new(ExplainPlan)
+	for rows.Next() {
+		var tree string
+		if err = rows.Scan(&tree); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if ep.Nodes, err = parseExplainTree(tree); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ep, nil
+}
+
+// parseExplainTree parses the tree-format output of MySQL 8. Each node starts
+// with an arrow, indented by four spaces per level.
+func parseExplainTree(tree string) ([]*ExplainNode, error) {
+	var roots []*ExplainNode
+	var stack []*ExplainNode // index equals the depth
+	var last *ExplainNode
+	for _, line := range strings.Split(tree, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "->") {
+			if last == nil {
+				return nil, errors.NotValid.Newf("[dml] ExplainAnalyze: Unexpected line %q", line)
+			}
+			last.Operation += " " + trimmed // continued description
+			continue
+		}
+		n, err := parseExplainNode(strings.TrimSpace(trimmed[2:]))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		depth := (len(line) - len(trimmed)) / 4
+		if depth > len(stack) {
+			depth = len(stack)
+		}
+		stack = append(stack[:depth], n)
+		if depth == 0 {
+			roots = append(roots, n)
+		} else {
+			stack[depth-1].Children = append(stack[depth-1].Children, n)
+		}
+		last = n
+	}
+	return roots, nil
+}
+
+func parseExplainNode(s string) (*ExplainNode, error) {
+	n := new(ExplainNode)
+	if i := strings.LastIndex(s, " (never executed)"); i >= 0 {
+		n.NeverExecuted = true
+		s = s[:i]
+	} else if i := strings.LastIndex(s, " (actual time="); i >= 0 && strings.HasSuffix(s, ")") {
+		for _, kv := range strings.Fields(s[i+len(" (actual ") : len(s)-1]) {
+			k, v := splitExplainKV(kv)
+			var err error
+			switch k {
+			case "time":
+				first, last := v, v
+				if j := strings.Index(v, ".."); j >= 0 {
+					first, last = v[:j], v[j+2:]
+				}
+				if n.ActualTimeFirst, err = parseExplainMillis(first); err == nil {
+					n.ActualTimeLast, err = parseExplainMillis(last)
+				}
+			case "rows":
+				n.ActualRows, err = strconv.ParseFloat(v, 64)
+			case "loops":
+				n.Loops, err = strconv.ParseUint(v, 10, 64)
+			}
+			if err != nil {
+				return nil, errors.BadEncoding.New(err, "[dml] ExplainAnalyze failed to parse %q", kv)
+			}
+		}
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, " (cost="); i >= 0 && strings.HasSuffix(s, ")") {
+		for _, kv := range strings.Fields(s[i+len(" (") : len(s)-1]) {
+			k, v := splitExplainKV(kv)
+			var err error
+			switch k {
+			case "cost":
+				n.EstimatedCost, err = strconv.ParseFloat(v, 64)
+			case "rows":
+				n.EstimatedRows, err = strconv.ParseFloat(v, 64)
+			}
+			if err != nil {
+				return nil, errors.BadEncoding.New(err, "[dml] ExplainAnalyze failed to parse %q", kv)
+			}
+		}
+		s = s[:i]
+	}
+	n.Operation = strings.TrimSpace(s)
+	n.Type = n.Operation
+	if i := strings.Index(n.Type, ": "); i > 0 {
+		n.Type = n.Type[:i]
+	} else if i := strings.Index(n.Type, " on "); i > 0 {
+		n.Type = n.Type[:i]
+	}
+	return n, nil
+}
+
+func splitExplainKV(kv string) (key, value string) {
+	if i := strings.IndexByte(kv, '='); i > 0 {
+		return kv[:i], kv[i+1:]
+	}
+	return kv, ""
+}
+
+func parseExplainMillis(v string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Millisecond)), nil
+}
+
+// parseAnalyzeJSON converts the output of ANALYZE FORMAT=JSON of MariaDB. Each
+// accessed table becomes one node.
+func parseAnalyzeJSON(raw []byte) (*ExplainPlan, error) {
+	doc, err := decodeExplainJSON(raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ep := new(ExplainPlan)
+	walkExplainTables(doc, 0, func(t map[string]interface{}, _ uint64) {
+		n := &ExplainNode{
+			Type:           explainString(t["access_type"]),
+			EstimatedRows:  explainFloat(t["rows"]),
+			ActualRows:     explainFloat(t["r_rows"]),
+			ActualTimeLast: time.Duration(explainFloat(t["r_total_time_ms"]) * float64(time.Millisecond)),
+			Loops:          uint64(explainFloat(t["r_loops"])),
+		}
+		n.NeverExecuted = n.Loops == 0
+		n.Operation = n.Type + " on " + explainString(t["table_name"])
+		if key := explainString(t["key"]); key != "" {
+			n.Operation += " using " + key
+		}
+		ep.Nodes = append(ep.Nodes, n)
+	})
+	return ep, nil
+}
+
+// slowQueryLog see WithSlowQueryLog.
+type slowQueryLog struct {
+	threshold      time.Duration
+	explainAnalyze bool
+}
+
+// WithSlowQueryLog logs all statements of the ConnPool and its connections and
+// transactions, which take longer than the threshold, as Info message
+// "SlowQuery". If explainAnalyze is true, slow SELECT statements executed via
+// the connection pool get executed a second time with ExplainAnalyze and the
+// actual plan gets attached to the log entry as field "explain_analyze". Due to
+// the double execution, the threshold should be chosen carefully. Requires a
+// logger, see WithLogger.
+func WithSlowQueryLog(threshold time.Duration, explainAnalyze bool) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 10,
+		fn: func(c *ConnPool) error {
+			c.slowQuery = &slowQueryLog{
+				threshold:      threshold,
+				explainAnalyze: explainAnalyze,
+			}
+			return nil
+		},
+	}
+}
+
+// logSlowQuery writes an Info log entry if duration `d` exceeds the threshold
+// of WithSlowQueryLog.
+func (bc *builderCommon) logSlowQuery(ctx context.Context, kind string, d time.Duration, sqlStr string, args []interface{}, err error, fields ...log.Field) {
+	if bc.slowQuery == nil || d < bc.slowQuery.threshold || bc.Log == nil || !bc.Log.IsInfo() {
+		return
+	}
+	fields = append(fields, log.String("kind", kind), log.Duration("duration", d),
+		log.String("duration_bucket", DurationBucketLabel(DurationBucket(d))), log.Err(err))
+
+	if _, isPool := bc.db.(*sql.DB); isPool && err == nil && bc.slowQuery.explainAnalyze && kind == "Query" && sqlStr != "" {
+		switch bc.source {
+		case dmlSourceSelect, dmlSourceUnion, dmlSourceWith:
+			if ep, errEA := explainAnalyze(ctx, bc.db, sqlStr, args); errEA != nil {
+				fields = append(fields, log.ErrWithKey("explain_analyze_error", errEA))
+			} else {
+				fields = append(fields, log.String("explain_analyze", ep.String()))
+			}
+		}
+	}
+	bc.Log.Info("SlowQuery", fields...)
+}
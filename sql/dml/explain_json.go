@@ -0,0 +1,230 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// ExplainResult contains the parsed output of EXPLAIN FORMAT=JSON.
+type ExplainResult struct {
+	// QueryCost contains the estimated cost of the outermost query block.
+	// MariaDB does not report costs.
+	QueryCost float64
+	// Tables contains all accessed tables in the order of the plan, including
+	// the tables of subqueries and unions.
+	Tables []ExplainTable
+	// JSON contains the unparsed output of the server.
+	JSON []byte
+}
+
+// ExplainTable describes the access to one table of an execution plan.
+type ExplainTable struct {
+	SelectID  uint64
+	TableName string
+	// AccessType contains the join type, e.g. "ALL" for a full table scan,
+	// "index", "range", "ref", "eq_ref" or "const".
+	AccessType   string
+	PossibleKeys []string
+	// Key contains the used index or is empty.
+	Key          string
+	UsedKeyParts []string
+	// Rows contains the estimated number of examined rows per scan.
+	Rows float64
+	// Filtered contains the estimated percentage of rows filtered by the
+	// condition.
+	Filtered          float64
+	AttachedCondition string
+}
+
+// IsFullTableScan returns true if all rows of the table get read.
+func (et ExplainTable) IsFullTableScan() bool {
+	return et.AccessType == "ALL"
+}
+
+// FullTableScans returns all tables which get read completely.
+func (er *ExplainResult) FullTableScans() []ExplainTable {
+	var ets []ExplainTable
+	for _, et := range er.Tables {
+		if et.IsFullTableScan() {
+			ets = append(ets, et)
+		}
+	}
+	return ets
+}
+
+// Explain runs the statement with EXPLAIN FORMAT=JSON and returns the parsed
+// execution plan. The statement does not get executed, hence it can be used
+// for SELECT, UPDATE and DELETE statements. If a logger has been set and the
+// Info level is enabled, each full table scan gets logged as
+// "DBR.Explain.FullTableScan". Requires MySQL >= 5.6.5 or MariaDB >= 10.1.
+// Argument args gets passed to the underlying DBR.
+//		er, err := dbc.SelectFrom("sales_order").Star().Where(dml.Column("status").PlaceHolder()).
+//			WithDBR().Explain(ctx, "pending")
+func (a *DBR) Explain(ctx context.Context, args ...interface{}) (*ExplainResult, error) {
+	if a.isPrepared {
+		return nil, errors.NotSupported.Newf("[dml] DBR.Explain does not support prepared statements")
+	}
+	sqlStr, qArgs, err := a.prepareQueryAndArgs(args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var raw []byte
+	if err := a.base.db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+sqlStr, qArgs...).Scan(&raw); err != nil {
+		return nil, errors.Wrapf(err, "[dml] DBR.Explain with query %q", sqlStr)
+	}
+	er, err := parseExplainJSON(raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if a.base.Log != nil && a.base.Log.IsInfo() {
+		for _, et := range er.FullTableScans() {
+			a.base.Log.Info("DBR.Explain.FullTableScan", log.String("id", a.base.id), log.String("table", et.TableName),
+				log.Float64("rows", et.Rows), log.String("sql", sqlStr))
+		}
+	}
+	return er, nil
+}
+
+// Explain runs the query with EXPLAIN FORMAT=JSON and returns the parsed
+// execution plan. See DBR.Explain.
+func (b *Select) Explain(ctx context.Context, args ...interface{}) (*ExplainResult, error) {
+	return b.WithDBR().Explain(ctx, args...)
+}
+
+// Explain runs the statement with EXPLAIN FORMAT=JSON and returns the parsed
+// execution plan. The statement does not get executed. See DBR.Explain.
+func (b *Update) Explain(ctx context.Context, args ...interface{}) (*ExplainResult, error) {
+	return b.WithDBR().Explain(ctx, args...)
+}
+
+// Explain runs the statement with EXPLAIN FORMAT=JSON and returns the parsed
+// execution plan. The statement does not get executed. See DBR.Explain.
+func (b *Delete) Explain(ctx context.Context, args ...interface{}) (*ExplainResult, error) {
+	return b.WithDBR().Explain(ctx, args...)
+}
+
+func parseExplainJSON(raw []byte) (*ExplainResult, error) {
+	doc, err := decodeExplainJSON(raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	er := &ExplainResult{JSON: raw}
+	if qb, ok := doc["query_block"].(map[string]interface{}); ok {
+		if ci, ok := qb["cost_info"].(map[string]interface{}); ok {
+			er.QueryCost = explainFloat(ci["query_cost"])
+		}
+	}
+	walkExplainTables(doc, 0, func(t map[string]interface{}, selectID uint64) {
+		er.Tables = append(er.Tables, makeExplainTable(t, selectID))
+	})
+	return er, nil
+}
+
+func decodeExplainJSON(raw []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.BadEncoding.New(err, "[dml] Failed to decode the explain output %q", raw)
+	}
+	return doc, nil
+}
+
+// walkExplainTables calls fn for all tables in depth-first order. The key
+// "table" gets visited first and the other keys sorted to get a deterministic
+// order because JSON objects are not ordered.
+func walkExplainTables(v interface{}, selectID uint64, fn func(t map[string]interface{}, selectID uint64)) {
+	switch vt := v.(type) {
+	case []interface{}:
+		for _, e := range vt {
+			walkExplainTables(e, selectID, fn)
+		}
+	case map[string]interface{}:
+		if id, ok := vt["select_id"]; ok {
+			selectID = uint64(explainFloat(id))
+		}
+		if t, ok := vt["table"].(map[string]interface{}); ok {
+			fn(t, selectID)
+			walkExplainTables(t, selectID, fn) // subqueries can be materialized within a table
+		}
+		keys := make([]string, 0, len(vt))
+		for k := range vt {
+			if k != "table" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkExplainTables(vt[k], selectID, fn)
+		}
+	}
+}
+
+func makeExplainTable(t map[string]interface{}, selectID uint64) ExplainTable {
+	et := ExplainTable{
+		SelectID:          selectID,
+		TableName:         explainString(t["table_name"]),
+		AccessType:        explainString(t["access_type"]),
+		PossibleKeys:      explainStrings(t["possible_keys"]),
+		Key:               explainString(t["key"]),
+		UsedKeyParts:      explainStrings(t["used_key_parts"]),
+		Filtered:          explainFloat(t["filtered"]),
+		AttachedCondition: explainString(t["attached_condition"]),
+	}
+	if r, ok := t["rows_examined_per_scan"]; ok { // MySQL
+		et.Rows = explainFloat(r)
+	} else { // MariaDB
+		et.Rows = explainFloat(t["rows"])
+	}
+	return et
+}
+
+func explainString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func explainStrings(v interface{}) []string {
+	vs, _ := v.([]interface{})
+	if len(vs) == 0 {
+		return nil
+	}
+	ss := make([]string, 0, len(vs))
+	for _, v := range vs {
+		ss = append(ss, explainString(v))
+	}
+	return ss
+}
+
+// explainFloat converts a number or a string like "100.00" of MySQL.
+// Unparsable numbers get ignored.
+func explainFloat(v interface{}) float64 {
+	var f float64
+	switch vt := v.(type) {
+	case json.Number:
+		f, _ = vt.Float64()
+	case string:
+		f, _ = strconv.ParseFloat(vt, 64)
+	}
+	return f
+}
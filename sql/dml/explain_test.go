@@ -0,0 +1,238 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/corestoreio/pkg/util/cstesting"
+)
+
+const explainAnalyzeTree = `-> Nested loop inner join  (cost=1.60 rows=3) (actual time=0.051..0.065 rows=3 loops=1)
+    -> Filter: (t1.a > 1)  (cost=0.55 rows=3) (actual time=0.029..0.033 rows=3 loops=1)
+        -> Table scan on t1  (cost=0.55 rows=3) (actual time=0.026..0.030 rows=3 loops=1)
+    -> Index lookup on t2 using idx_a (a=t1.a)  (cost=0.28 rows=1) (never executed)
+`
+
+func TestSelect_ExplainAnalyze(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	expectVersion := func(version string) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+	}
+
+	t.Run("MySQL tree format", func(t *testing.T) {
+		expectVersion("8.0.21")
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN ANALYZE SELECT `a` FROM `t1` WHERE (`a` > 1)")).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(explainAnalyzeTree))
+
+		ep, err := dbc.SelectFrom("t1").AddColumns("a").Where(dml.Column("a").Greater().Int(1)).ExplainAnalyze(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, ep.Nodes, 1)
+
+		root := ep.Nodes[0]
+		assert.Exactly(t, "Nested loop inner join", root.Type)
+		assert.Exactly(t, 1.6, root.EstimatedCost)
+		assert.Exactly(t, 3.0, root.ActualRows)
+		assert.Exactly(t, 51*time.Microsecond, root.ActualTimeFirst)
+		assert.Exactly(t, 65*time.Microsecond, root.ActualTimeLast)
+		assert.Exactly(t, uint64(1), root.Loops)
+		assert.Len(t, root.Children, 2)
+
+		filter := root.Children[0]
+		assert.Exactly(t, "Filter", filter.Type)
+		assert.Exactly(t, "Filter: (t1.a > 1)", filter.Operation)
+		assert.Len(t, filter.Children, 1)
+		assert.Exactly(t, "Table scan", filter.Children[0].Type)
+
+		lookup := root.Children[1]
+		assert.Exactly(t, "Index lookup", lookup.Type)
+		assert.Exactly(t, "Index lookup on t2 using idx_a (a=t1.a)", lookup.Operation)
+		assert.True(t, lookup.NeverExecuted)
+		assert.Exactly(t, 1.0, lookup.EstimatedRows)
+
+		assert.Exactly(t, `-> Nested loop inner join (actual time=51µs..65µs rows=3 loops=1)
+    -> Filter: (t1.a > 1) (actual time=29µs..33µs rows=3 loops=1)
+        -> Table scan on t1 (actual time=26µs..30µs rows=3 loops=1)
+    -> Index lookup on t2 using idx_a (a=t1.a) (never executed)`, ep.String())
+	})
+
+	t.Run("MariaDB JSON format", func(t *testing.T) {
+		expectVersion("5.5.5-10.3.22-MariaDB-log")
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("ANALYZE FORMAT=JSON SELECT `a` FROM `t1` INNER JOIN `t2` ON (`t1`.`a` = `t2`.`a`)")).
+			WillReturnRows(sqlmock.NewRows([]string{"ANALYZE"}).AddRow(`{"query_block": {"select_id": 1, "r_loops": 1, "r_total_time_ms": 0.065,
+  "table": {"table_name": "t1", "access_type": "ALL", "r_loops": 1, "rows": 10, "r_rows": 8, "r_total_time_ms": 0.03, "filtered": 100, "r_filtered": 100},
+  "block-nl-join": {"table": {"table_name": "t2", "access_type": "ref", "key": "idx_a", "r_loops": 0, "rows": 1, "r_rows": null}}}}`))
+
+		ep, err := dbc.SelectFrom("t1").AddColumns("a").
+			Join(dml.MakeIdentifier("t2"), dml.Column("t1.a").Equal().Column("t2.a")).ExplainAnalyze(context.TODO())
+		assert.NoError(t, err)
+		assert.Len(t, ep.Nodes, 2)
+		assert.Exactly(t, "ALL", ep.Nodes[0].Type)
+		assert.Exactly(t, "ALL on t1", ep.Nodes[0].Operation)
+		assert.Exactly(t, 10.0, ep.Nodes[0].EstimatedRows)
+		assert.Exactly(t, 8.0, ep.Nodes[0].ActualRows)
+		assert.Exactly(t, 30*time.Microsecond, ep.Nodes[0].ActualTimeLast)
+		assert.Exactly(t, "ref on t2 using idx_a", ep.Nodes[1].Operation)
+		assert.True(t, ep.Nodes[1].NeverExecuted)
+	})
+}
+
+func TestWithSlowQueryLog(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	rec := cstesting.NewLogRecorder()
+	rec.EnableDebug = false
+	assert.NoError(t, dbc.Options(
+		dml.WithLogger(rec, func() string { return "UNIQ01" }),
+		dml.WithSlowQueryLog(0, true),
+	))
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT /*ID$UNIQ01*/ `a` FROM `t1`")).
+		WillReturnRows(sqlmock.NewRows([]string{"a"}).AddRow(1))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT VERSION()")).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.0.21"))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN ANALYZE SELECT /*ID$UNIQ01*/ `a` FROM `t1`")).
+		WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(explainAnalyzeTree))
+
+	vals, err := dbc.SelectFrom("t1").AddColumns("a").WithDBR().LoadInt64s(context.TODO(), nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, []int64{1}, vals)
+
+	slow := rec.FindEntries(cstesting.LogLevelInfo, "SlowQuery", log.String("kind", "Query"))
+	assert.Len(t, slow, 1, rec.String())
+	assert.Contains(t, slow[0].Fields["explain_analyze"], "Table scan on t1")
+
+	// EXPLAIN ANALYZE runs while the rows of the query are still open, hence
+	// the pool closes two connections.
+	dbMock.ExpectClose()
+}
+
+const explainJSONMySQL = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "12.45"},
+    "nested_loop": [
+      {
+        "table": {
+          "table_name": "so",
+          "access_type": "ALL",
+          "possible_keys": ["PRIMARY"],
+          "rows_examined_per_scan": 40,
+          "rows_produced_per_join": 4,
+          "filtered": "10.00",
+          "attached_condition": "(so.status = 'pending')"
+        }
+      },
+      {
+        "table": {
+          "table_name": "soi",
+          "access_type": "ref",
+          "possible_keys": ["IDX_ORDER_ID"],
+          "key": "IDX_ORDER_ID",
+          "used_key_parts": ["order_id"],
+          "rows_examined_per_scan": 2,
+          "filtered": "100.00"
+        }
+      }
+    ],
+    "select_list_subqueries": [
+      {
+        "query_block": {
+          "select_id": 2,
+          "table": {"table_name": "customer_entity", "access_type": "eq_ref", "key": "PRIMARY", "rows_examined_per_scan": 1, "filtered": "100.00"}
+        }
+      }
+    ]
+  }
+}`
+
+func TestDBR_Explain(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	rec := cstesting.NewLogRecorder()
+	rec.EnableDebug = false
+	assert.NoError(t, dbc.Options(dml.WithLogger(rec, func() string { return "UNIQ01" })))
+
+	t.Run("MySQL select", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN FORMAT=JSON SELECT /*ID$UNIQ01*/ `so`.`entity_id` FROM `sales_order` AS `so` INNER JOIN `sales_order_item` AS `soi` ON (`so`.`entity_id` = `soi`.`order_id`) WHERE (`so`.`status` = ?)")).
+			WithArgs("pending").
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(explainJSONMySQL))
+
+		er, err := dbc.SelectFrom("sales_order", "so").AddColumns("so.entity_id").
+			Join(dml.MakeIdentifier("sales_order_item").Alias("soi"), dml.Column("so.entity_id").Equal().Column("soi.order_id")).
+			Where(dml.Column("so.status").PlaceHolder()).
+			Explain(context.TODO(), "pending")
+		assert.NoError(t, err)
+		assert.Exactly(t, 12.45, er.QueryCost)
+		assert.Exactly(t, explainJSONMySQL, string(er.JSON))
+		assert.Len(t, er.Tables, 3)
+		assert.Exactly(t, dml.ExplainTable{
+			SelectID:          1,
+			TableName:         "so",
+			AccessType:        "ALL",
+			PossibleKeys:      []string{"PRIMARY"},
+			Rows:              40,
+			Filtered:          10,
+			AttachedCondition: "(so.status = 'pending')",
+		}, er.Tables[0])
+		assert.Exactly(t, []string{"order_id"}, er.Tables[1].UsedKeyParts)
+		assert.Exactly(t, "IDX_ORDER_ID", er.Tables[1].Key)
+		assert.Exactly(t, uint64(2), er.Tables[2].SelectID)
+		assert.Exactly(t, "customer_entity", er.Tables[2].TableName)
+
+		fts := er.FullTableScans()
+		assert.Len(t, fts, 1)
+		assert.Exactly(t, "so", fts[0].TableName)
+		entries := rec.FindEntries(cstesting.LogLevelInfo, "DBR.Explain.FullTableScan", log.String("table", "so"))
+		assert.Len(t, entries, 1, rec.String())
+	})
+
+	t.Run("MariaDB update", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN FORMAT=JSON UPDATE /*ID$UNIQ01*/ `sales_order` SET `status`='complete' WHERE (`entity_id` = 3)")).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(
+				`{"query_block": {"select_id": 1, "table": {"update": 1, "table_name": "sales_order", "access_type": "range", "possible_keys": ["PRIMARY"], "key": "PRIMARY", "rows": 1, "filtered": 100}}}`))
+
+		er, err := dbc.Update("sales_order").AddClauses(dml.Column("status").Str("complete")).
+			Where(dml.Column("entity_id").Int(3)).Explain(context.TODO())
+		assert.NoError(t, err)
+		assert.Exactly(t, 0.0, er.QueryCost)
+		assert.Len(t, er.Tables, 1)
+		assert.Exactly(t, 1.0, er.Tables[0].Rows)
+		assert.Exactly(t, 100.0, er.Tables[0].Filtered)
+		assert.False(t, er.Tables[0].IsFullTableScan())
+	})
+
+	t.Run("delete invalid JSON", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN FORMAT=JSON DELETE /*ID$UNIQ01*/ FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block":`))
+
+		er, err := dbc.DeleteFrom("sales_order").Explain(context.TODO())
+		assert.Nil(t, er)
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+}
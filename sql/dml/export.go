@@ -0,0 +1,346 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// ExportOptions applies to Select.Export.
+type ExportOptions struct {
+	// KeyColumns names the columns of a unique key in the order of its index,
+	// usually the primary key. The rows get sorted ascending by these columns
+	// and the token contains their values of the last exported row. The
+	// columns must be NOT NULL and part of the selected columns.
+	KeyColumns []string
+	// BatchSize defines the maximum amount of rows per batch. Defaults to
+	// 1000.
+	BatchSize uint64
+	// After continues the export after the row of this token, e.g. the token
+	// of a previous run. Empty starts with the first row.
+	After string
+	// Until stops the export at the row of this token, inclusive. Empty
+	// exports until the last row. Together with After the key range can be
+	// split between several workers, see function ExportToken.
+	Until string
+}
+
+// Exporter exports a result set in batches with keyset pagination. Contrary
+// to LIMIT/OFFSET pagination each batch uses the index of the key columns, so
+// the speed does not degrade with the amount of exported rows. After each
+// batch Token returns an opaque resume token which allows to continue the
+// export after a restart of the process. An Exporter can't be used in
+// concurrent context; for parallel exports create one Exporter per key range.
+//		ex, err := dbc.SelectFrom("sales_order").Star().
+//			Export(dml.ExportOptions{KeyColumns: []string{"entity_id"}, After: lastToken})
+//		if err != nil {
+//			return err
+//		}
+//		for {
+//			orders := new(SalesOrders)
+//			rowCount, err := ex.Next(ctx, orders)
+//			if err != nil || rowCount == 0 {
+//				return err
+//			}
+//			// write orders somewhere and persist ex.Token()
+//		}
+type Exporter struct {
+	sel   *Select
+	opt   ExportOptions
+	args  []interface{}
+	after []scannedColumn // nil starts with the first row
+	until []scannedColumn
+	done  bool
+}
+
+// Export creates a new Exporter for the current query. The ORDER BY and LIMIT
+// clauses get replaced with the key columns and the batch size. Argument args
+// gets passed to each query, see DBR.Load.
+func (b *Select) Export(o ExportOptions, args ...interface{}) (*Exporter, error) {
+	if len(o.KeyColumns) == 0 {
+		return nil, errors.Empty.Newf("[dml] Select.Export requires at least one key column")
+	}
+	if o.BatchSize == 0 {
+		o.BatchSize = 1000
+	}
+	ex := &Exporter{
+		sel:  b,
+		opt:  o,
+		args: args,
+	}
+	var err error
+	if ex.after, err = decodeExportToken(o.After, len(o.KeyColumns)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ex.until, err = decodeExportToken(o.Until, len(o.KeyColumns)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ex, nil
+}
+
+// Next loads the next batch into ColumnMapper s and returns the amount of
+// loaded rows. A rowCount of zero signals the end of the export.
+func (ex *Exporter) Next(ctx context.Context, s ColumnMapper) (rowCount uint64, err error) {
+	if ex.sel.Log != nil && ex.sel.Log.IsDebug() {
+		defer log.WhenDone(ex.sel.Log).Debug("Exporter.Next", log.String("id", ex.sel.id), log.Err(err), log.Uint64("row_count", rowCount))
+	}
+	if ex.done {
+		return 0, nil
+	}
+
+	sel := ex.sel.Clone()
+	sel.cachedSQL = nil
+	sel.OrderBys = nil
+	sel.OrderBy(ex.opt.KeyColumns...)
+	sel.Limit(0, ex.opt.BatchSize)
+	if ex.after != nil {
+		sel.Where(exportKeyCondition(ex.opt.KeyColumns, ex.after, ">"))
+	}
+	if ex.until != nil {
+		sel.Where(exportKeyCondition(ex.opt.KeyColumns, ex.until, "<="))
+	}
+
+	em := &exportMapper{ColumnMapper: s, keyColumns: ex.opt.KeyColumns}
+	if rowCount, err = sel.WithDBR().Load(ctx, em, ex.args...); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if rowCount > 0 {
+		ex.after = em.last
+	}
+	ex.done = rowCount < ex.opt.BatchSize
+	return rowCount, nil
+}
+
+// Token returns the resume token of the last exported row. Store the token
+// and set it to ExportOptions.After to continue the export. Returns the
+// initial After token if no rows have been exported.
+func (ex *Exporter) Token() string {
+	if ex.after == nil {
+		return ""
+	}
+	return encodeExportToken(ex.after)
+}
+
+// ExportToken creates a token from the values of the key columns. It allows to
+// split an export into key ranges for several workers, e.g. worker one
+// exports until the token of ID 1000000 and worker two starts after that
+// token. Supported types are the integers, float64, string, []byte and
+// time.Time.
+func ExportToken(keyValues ...interface{}) (string, error) {
+	sc := make([]scannedColumn, len(keyValues))
+	for i, v := range keyValues {
+		switch vt := v.(type) {
+		case int:
+			sc[i] = scannedColumn{field: 'i', int64: int64(vt)}
+		case int64:
+			sc[i] = scannedColumn{field: 'i', int64: vt}
+		case int32:
+			sc[i] = scannedColumn{field: 'i', int64: int64(vt)}
+		case uint32:
+			sc[i] = scannedColumn{field: 'i', int64: int64(vt)}
+		case uint64:
+			if vt > math.MaxInt64 {
+				return "", errors.OutOfRange.Newf("[dml] ExportToken value %d at index %d overflows int64", vt, i)
+			}
+			sc[i] = scannedColumn{field: 'i', int64: int64(vt)}
+		case float64:
+			sc[i] = scannedColumn{field: 'f', float64: vt}
+		case string:
+			sc[i] = scannedColumn{field: 's', string: vt}
+		case []byte:
+			sc[i] = scannedColumn{field: 's', string: string(vt)}
+		case time.Time:
+			sc[i] = scannedColumn{field: 't', time: vt}
+		default:
+			return "", errors.NotSupported.Newf("[dml] ExportToken type %T at index %d not supported", v, i)
+		}
+	}
+	return encodeExportToken(sc), nil
+}
+
+// exportKeyCondition creates the keyset condition for ascending sorted keys,
+// e.g. for op > and keys a,b: (`a` > ? OR (`a` = ? AND `b` > ?)). Written
+// with OR instead of a row constructor to let older MySQL versions use the
+// index.
+func exportKeyCondition(keyColumns []string, values []scannedColumn, op string) *Condition {
+	var buf bytes.Buffer
+	var args []interface{}
+	buf.WriteByte('(')
+	for i := range keyColumns {
+		if i > 0 {
+			buf.WriteString(" OR (")
+		}
+		for j := 0; j < i; j++ {
+			Quoter.WriteIdentifier(&buf, keyColumns[j])
+			buf.WriteString(" = ? AND ")
+			args = append(args, values[j].exportArg())
+		}
+		Quoter.WriteIdentifier(&buf, keyColumns[i])
+		if i == len(keyColumns)-1 {
+			buf.WriteString(" " + op + " ?")
+		} else {
+			buf.WriteString(" " + strings.TrimSuffix(op, "=") + " ?")
+		}
+		args = append(args, values[i].exportArg())
+		if i > 0 {
+			buf.WriteByte(')')
+		}
+	}
+	buf.WriteByte(')')
+	c := Expr(buf.String())
+	c.Right.args = args
+	return c
+}
+
+func (s scannedColumn) exportArg() interface{} {
+	switch s.field {
+	case 'i':
+		return s.int64
+	case 'f':
+		return s.float64
+	case 't':
+		return s.time
+	}
+	return s.string
+}
+
+// encodeExportToken encodes the key values as base64 URL encoded JSON array.
+// Each value has a prefix of its type.
+func encodeExportToken(values []scannedColumn) string {
+	strs := make([]string, len(values))
+	for i, v := range values {
+		switch v.field {
+		case 'i':
+			strs[i] = "i" + strconv.FormatInt(v.int64, 10)
+		case 'f':
+			strs[i] = "f" + strconv.FormatFloat(v.float64, 'g', -1, 64)
+		case 't':
+			strs[i] = "t" + v.time.Format(time.RFC3339Nano)
+		default:
+			strs[i] = "s" + v.string
+		}
+	}
+	data, _ := json.Marshal(strs) // can't fail
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeExportToken(token string, keyCount int) ([]scannedColumn, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.NotValid.New(err, "[dml] Export token %q is not valid", token)
+	}
+	var strs []string
+	if err := json.Unmarshal(data, &strs); err != nil {
+		return nil, errors.NotValid.New(err, "[dml] Export token %q is not valid", token)
+	}
+	if len(strs) != keyCount {
+		return nil, errors.Mismatch.Newf("[dml] Export token %q contains %d values but %d key columns are defined", token, len(strs), keyCount)
+	}
+	values := make([]scannedColumn, len(strs))
+	for i, s := range strs {
+		if s == "" {
+			return nil, errors.NotValid.Newf("[dml] Export token %q contains an empty value at index %d", token, i)
+		}
+		v := &values[i]
+		v.field = s[0]
+		switch s = s[1:]; v.field {
+		case 'i':
+			v.int64, err = strconv.ParseInt(s, 10, 64)
+		case 'f':
+			v.float64, err = strconv.ParseFloat(s, 64)
+		case 't':
+			v.time, err = time.Parse(time.RFC3339Nano, s)
+		case 's':
+			v.string = s
+		default:
+			err = errors.NotSupported.Newf("[dml] Export token type %q not supported", v.field)
+		}
+		if err != nil {
+			return nil, errors.NotValid.New(err, "[dml] Export token %q contains an invalid value at index %d", token, i)
+		}
+	}
+	return values, nil
+}
+
+// exportMapper remembers the key column values of the current row before
+// passing the row to the wrapped ColumnMapper.
+type exportMapper struct {
+	ColumnMapper
+	keyColumns []string
+	keyIdx     []int
+	last       []scannedColumn
+}
+
+func (em *exportMapper) MapColumns(cm *ColumnMap) error {
+	if cm.Mode() != ColumnMapScan {
+		return em.ColumnMapper.MapColumns(cm)
+	}
+	if em.keyIdx == nil {
+		em.keyIdx = make([]int, len(em.keyColumns))
+		for i, kc := range em.keyColumns {
+			if dot := strings.LastIndexByte(kc, '.'); dot >= 0 {
+				kc = kc[dot+1:] // result set contains only the column name
+			}
+			em.keyIdx[i] = -1
+			for j, c := range cm.columns {
+				if c == kc {
+					em.keyIdx[i] = j
+				}
+			}
+			if em.keyIdx[i] < 0 {
+				return errors.NotFound.Newf("[dml] Exporter key column %q not found in the selected columns %v", em.keyColumns[i], cm.columns)
+			}
+		}
+		em.last = make([]scannedColumn, len(em.keyColumns))
+	}
+	for i, idx := range em.keyIdx {
+		sc := cm.scanCol[idx]
+		switch sc.field {
+		case 'n':
+			return errors.NotValid.Newf("[dml] Exporter key column %q must not contain NULL", em.keyColumns[i])
+		case 'y':
+			sc = scannedColumn{field: 's', string: string(sc.byte)}
+		case 'b':
+			b := sc.bool
+			sc = scannedColumn{field: 'i'}
+			if b {
+				sc.int64 = 1
+			}
+		}
+		em.last[i] = sc
+	}
+	return em.ColumnMapper.MapColumns(cm)
+}
+
+// Close calls Close of the wrapped ColumnMapper, if implemented.
+func (em *exportMapper) Close() error {
+	if c, ok := em.ColumnMapper.(ioCloser); ok {
+		return c.Close()
+	}
+	return nil
+}
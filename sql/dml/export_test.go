@@ -0,0 +1,111 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestSelect_Export(t *testing.T) {
+	t.Run("single key resumes", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		cols := []string{"config_id", "scope", "scope_id", "path", "value"}
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT * FROM `core_config_data` WHERE (`scope` = ?) ORDER BY `config_id` LIMIT 0,2")).
+			WithArgs("default").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(2, "default", 0, "a", nil).AddRow(4, "default", 0, "b", "x"))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT * FROM `core_config_data` WHERE (`scope` = ?) AND ((`config_id` > 4)) ORDER BY `config_id` LIMIT 0,2")).
+			WithArgs("default").
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(7, "default", 0, "c", nil))
+
+		sel := dbc.SelectFrom("core_config_data").Star().
+			Where(dml.Column("scope").PlaceHolder()).OrderBy("path")
+		ex, err := sel.Export(dml.ExportOptions{KeyColumns: []string{"config_id"}, BatchSize: 2}, "default")
+		assert.NoError(t, err)
+
+		ccd := new(TableCoreConfigDataSlice)
+		rowCount, err := ex.Next(context.TODO(), ccd)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(2), rowCount)
+		assert.Len(t, ccd.Data, 2)
+		token := ex.Token()
+		want, err := dml.ExportToken(4)
+		assert.NoError(t, err)
+		assert.Exactly(t, want, token)
+
+		// simulates a restart of the process
+		ex, err = sel.Export(dml.ExportOptions{KeyColumns: []string{"config_id"}, BatchSize: 2, After: token}, "default")
+		assert.NoError(t, err)
+		rowCount, err = ex.Next(context.TODO(), ccd)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(1), rowCount)
+		assert.Exactly(t, int64(7), ccd.Data[0].ConfigID)
+
+		// batch was smaller than BatchSize, no further query
+		rowCount, err = ex.Next(context.TODO(), ccd)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(0), rowCount)
+	})
+
+	t.Run("composite key range", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		after, err := dml.ExportToken("default", 3)
+		assert.NoError(t, err)
+		until, err := dml.ExportToken("website", 1)
+		assert.NoError(t, err)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `scope`, `config_id` FROM `core_config_data` WHERE ((`scope` > 'default' OR (`scope` = 'default' AND `config_id` > 3))) AND ((`scope` < 'website' OR (`scope` = 'website' AND `config_id` <= 1))) ORDER BY `scope`, `config_id` LIMIT 0,1000")).
+			WillReturnRows(sqlmock.NewRows([]string{"scope", "config_id"}).AddRow("stores", 5))
+
+		ex, err := dbc.SelectFrom("core_config_data").AddColumns("scope", "config_id").
+			Export(dml.ExportOptions{KeyColumns: []string{"scope", "config_id"}, After: after, Until: until})
+		assert.NoError(t, err)
+
+		ccd := new(TableCoreConfigDataSlice)
+		rowCount, err := ex.Next(context.TODO(), ccd)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(1), rowCount)
+		want, err := dml.ExportToken("stores", 5)
+		assert.NoError(t, err)
+		assert.Exactly(t, want, ex.Token())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sel := dml.NewSelect("config_id").From("core_config_data")
+		_, err := sel.Export(dml.ExportOptions{})
+		assert.ErrorIsKind(t, errors.Empty, err)
+
+		_, err = sel.Export(dml.ExportOptions{KeyColumns: []string{"config_id"}, After: "%%"})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+
+		token, err := dml.ExportToken(1, 2)
+		assert.NoError(t, err)
+		_, err = sel.Export(dml.ExportOptions{KeyColumns: []string{"config_id"}, After: token})
+		assert.ErrorIsKind(t, errors.Mismatch, err)
+
+		_, err = dml.ExportToken(struct{}{})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
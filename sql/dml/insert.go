@@ -68,6 +68,12 @@ type Insert struct {
 	// Select used to create an "INSERT INTO `table` SELECT ..." statement.
 	Select *Select
 	Pairs  Conditions
+	// SetClauses creates the "INSERT INTO `table` SET `a`=?, `b`=?" syntax for
+	// a single row. See function SetPairs.
+	SetClauses Conditions
+	// ChunkSize splits the execution into multiple statements with at most
+	// ChunkSize rows. See function WithChunkSize.
+	ChunkSize int
 	// OnDuplicateKeys updates the referenced columns. See documentation for
 	// type `Conditions`. For more details
 	// https://dev.mysql.com/doc/refman/5.7/en/insert-on-duplicate.html
@@ -103,10 +109,16 @@ type Insert struct {
 	// IsOnDuplicateKey if enabled adds all columns to the ON DUPLICATE KEY
 	// claus. Takes the OnDuplicateKeyExclude field into consideration.
 	IsOnDuplicateKey bool
+	// RowAlias uses the MySQL 8.0.19 syntax to refer to the new row in the ON
+	// DUPLICATE KEY UPDATE clause. See function WithRowAlias.
+	RowAlias string
 	// IsReplace uses the REPLACE syntax. See function Replace().
 	IsReplace bool
 	// IsIgnore ignores error. See function Ignore().
 	IsIgnore bool
+	// IsRowConstructor writes each row of the VALUES part with the row
+	// constructor ROW(?,?) of MySQL >= 8.0.19. See function RowConstructor.
+	IsRowConstructor bool
 	// IsBuildValues if true the VALUES part gets build when calling ToSQL.
 	// VALUES do not need to get build by default because mostly WithDBR gets
 	// called to build the VALUES part dynamically.
@@ -131,11 +143,7 @@ func newInsertInto(db QueryExecPreparer, cCom *connCommon, into string) *Insert
 
 	return &Insert{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  db,
-			},
+			builderCommon: cCom.newBuilderCommon(id, l, db),
 		},
 		Into: into,
 	}
@@ -150,7 +158,7 @@ func (c *ConnPool) InsertInto(into string) *Insert {
 // InsertInto instantiates a Insert for the given table. Mapping the table name
 // is supported.
 func (c *Conn) InsertInto(into string) *Insert {
-	return newInsertInto(c.DB, &c.connCommon, into)
+	return newInsertInto(c.qep(), &c.connCommon, into)
 }
 
 // InsertInto instantiates a Insert for the given table bound to a transaction.
@@ -186,6 +194,14 @@ func (b *Insert) Replace() *Insert {
 	return b
 }
 
+// RowConstructor writes each row of the VALUES part as ROW(?,?), e.g. for
+// statements which get shared with the VALUES statement of MySQL >= 8.0.19.
+// See IsRowConstructor.
+func (b *Insert) RowConstructor() *Insert {
+	b.IsRowConstructor = true
+	return b
+}
+
 // BuildValues see IsBuildValues.
 func (b *Insert) BuildValues() *Insert {
 	b.IsBuildValues = true
@@ -247,6 +263,18 @@ func (b *Insert) OnDuplicateKey() *Insert {
 	return b
 }
 
+// WithRowAlias sets an alias for the new row, which replaces the deprecated
+// VALUES() function in the ON DUPLICATE KEY UPDATE clause. Requires MySQL >=
+// 8.0.19 and is not supported by MariaDB. All VALUES(`column`) expressions,
+// also those created with SQLValues, ValuesOf or PlusValues, get rewritten:
+//		INSERT INTO `t` (`a`,`b`) VALUES (?,?) AS `new` ON DUPLICATE KEY UPDATE `a`=`new`.`a`, `b`=`b`+`new`.`b`
+// The row alias can't be combined with INSERT ... SELECT.
+// https://dev.mysql.com/doc/refman/8.0/en/insert-on-duplicate.html
+func (b *Insert) WithRowAlias(alias string) *Insert {
+	b.RowAlias = alias
+	return b
+}
+
 // WithPairs appends a column/value pair to the statement. Calling this function
 // multiple times with the same column name will trigger an error.
 // Slice values and right/left side expressions are not supported and ignored.
@@ -256,6 +284,21 @@ func (b *Insert) WithPairs(cvs ...*Condition) *Insert {
 	return b
 }
 
+// SetPairs appends column/value pairs to the SET clause and creates the
+// alternative single row syntax, which is easier to read and to review than
+// the VALUES list:
+//		dml.NewInsert("catalog_product_entity").SetPairs(
+//			dml.Column("sku").Str("SKU-1"),
+//			dml.Column("type_id").PlaceHolder(),
+//		)
+//		INSERT INTO `catalog_product_entity` SET `sku`='SKU-1', `type_id`=?
+// Columns without a value get a place holder. SetPairs can't be combined with
+// Pairs or FromSelect.
+func (b *Insert) SetPairs(cvs ...*Condition) *Insert {
+	b.SetClauses = append(b.SetClauses, cvs...)
+	return b
+}
+
 // FromSelect creates an "INSERT INTO `table` SELECT ..." statement from a
 // previously created SELECT statement.
 func (b *Insert) FromSelect(s *Select) *Insert {
@@ -289,12 +332,20 @@ func (b *Insert) WithDBR() *DBR {
 		return a
 	}
 
+	if len(b.SetClauses) > 0 {
+		a.insertIsBuildValues = true // the SET clause contains all place holders
+		return a
+	}
+
 	a.insertColumnCount = uint(len(b.Columns))
 	if b.RecordPlaceHolderCount > 0 {
 		a.insertColumnCount = uint(b.RecordPlaceHolderCount)
 	}
 	a.tupleRowCount = uint(b.RowCount)
 	a.insertIsBuildValues = b.IsBuildValues
+	a.insertChunkSize = b.ChunkSize
+	a.insertRowAlias = b.RowAlias != ""
+	a.insertRowConstructor = b.IsRowConstructor
 	return a
 }
 
@@ -320,7 +371,7 @@ func (b *Insert) WithCacheKey(key string, args ...interface{}) *Insert {
 	return b
 }
 
-func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, error) {
+func (b *Insert) toSQL(buf *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
 	for _, cv := range b.Pairs {
 		if !strInSlice(cv.Left, b.Columns) {
 			b.Columns = append(b.Columns, cv.Left)
@@ -345,7 +396,27 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 	Quoter.quote(buf, b.Into)
 	buf.WriteByte(' ')
 
+	if len(b.SetClauses) > 0 {
+		if b.Select != nil || len(b.Pairs) > 0 {
+			return nil, errors.NotAllowed.Newf("[dml] Insert.SetPairs can't be combined with Pairs or FromSelect")
+		}
+		for _, cnd := range b.SetClauses {
+			if !strInSlice(cnd.Left, b.Columns) {
+				b.Columns = append(b.Columns, cnd.Left) // for the ON DUPLICATE KEY clause
+			}
+		}
+		buf.WriteString("SET ")
+		ph, err := b.SetClauses.writeSetClauses(buf, d, placeHolders)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return b.writeOnDuplicateKey(buf, d, ph)
+	}
+
 	if b.Select != nil {
+		if b.RowAlias != "" {
+			return nil, errors.NotAllowed.Newf("[dml] Insert.WithRowAlias can't be combined with FromSelect")
+		}
 		if len(b.Columns) > 0 {
 			buf.WriteByte('(')
 			for i, c := range b.Columns {
@@ -356,11 +427,11 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 			}
 			buf.WriteString(") ")
 		}
-		ph, err := b.Select.toSQL(buf, placeHolders)
+		ph, err := b.Select.toSQL(buf, d, placeHolders)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		return b.writeOnDuplicateKey(buf, ph)
+		return b.writeOnDuplicateKey(buf, d, ph)
 	}
 
 	if len(b.Columns) > 0 {
@@ -389,6 +460,9 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 			if argCount0 > 0 {
 				rowCount = argCount0
 			}
+			if b.IsRowConstructor {
+				buf.WriteString("ROW")
+			}
 			buf.WriteByte('(')
 			for i := 0; i < argCount0 && lPairs <= rowCount; i++ {
 				buf.WriteString("?,")
@@ -396,13 +470,17 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 
 			for i, cv := range b.Pairs {
 				if i > 0 && i%rowCount == 0 {
-					buf.WriteString("),(")
+					buf.WriteString("),")
+					if b.IsRowConstructor {
+						buf.WriteString("ROW")
+					}
+					buf.WriteByte('(')
 				} else if i > 0 {
 					buf.WriteByte(',')
 				}
 				switch {
 				case cv.Right.arg != nil:
-					if err := writeInterfaceValue(cv.Right.arg, buf, 0); err != nil {
+					if err := writeInterfaceValue(cv.Right.arg, buf, d, 0); err != nil {
 						return nil, errors.WithStack(err)
 					}
 				case cv.Right.IsExpression:
@@ -410,7 +488,7 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 				case cv.Right.Sub != nil:
 					var err error
 					buf.WriteByte('(')
-					placeHolders, err = cv.Right.Sub.toSQL(buf, placeHolders)
+					placeHolders, err = cv.Right.Sub.toSQL(buf, d, placeHolders)
 					if err != nil {
 						return nil, errors.WithStack(err)
 					}
@@ -421,14 +499,14 @@ func (b *Insert) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 			}
 			buf.WriteByte(')')
 		} else {
-			writeTuplePlaceholders(buf, uint(rowCount), uint(argCount0))
+			writeTuplePlaceholders(buf, uint(rowCount), uint(argCount0), b.IsRowConstructor)
 		}
 	}
 
-	return b.writeOnDuplicateKey(buf, placeHolders)
+	return b.writeOnDuplicateKey(buf, d, placeHolders)
 }
 
-func (b *Insert) writeOnDuplicateKey(buf *bytes.Buffer, placeHolders []string) ([]string, error) {
+func (b *Insert) writeOnDuplicateKey(buf *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
 	if len(b.OnDuplicateKeyExclude) > 0 || b.IsOnDuplicateKey {
 		if len(b.OnDuplicateKeys) == 0 {
 			b.OnDuplicateKeys = append(b.OnDuplicateKeys, &Condition{})
@@ -450,7 +528,7 @@ func (b *Insert) writeOnDuplicateKey(buf *bytes.Buffer, placeHolders []string) (
 		}
 	}
 
-	return b.OnDuplicateKeys.writeOnDuplicateKey(buf, placeHolders)
+	return b.OnDuplicateKeys.writeOnDuplicateKeyAlias(buf, d, b.RowAlias, placeHolders)
 }
 
 func strInSlice(search string, sl []string) bool {
@@ -501,5 +579,6 @@ func (b *Insert) Clone() *Insert {
 	c.OnDuplicateKeys = b.OnDuplicateKeys.Clone()
 	c.Select = b.Select.Clone()
 	c.Pairs = b.Pairs.Clone()
+	c.SetClauses = b.SetClauses.Clone()
 	return &c
 }
@@ -348,6 +348,105 @@ func TestInsert_BuildValues(t *testing.T) {
 	})
 }
 
+func TestInsert_SetPairs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values and place holders", func(t *testing.T) {
+		ins := dml.NewInsert("catalog_product_entity").SetPairs(
+			dml.Column("sku").Str("SKU-1"),
+			dml.Column("type_id").PlaceHolder(),
+			dml.Column("updated_at").Expr("NOW()"),
+		)
+		compareToSQL(t, ins.WithDBR().TestWithArgs("simple"), errors.NoKind,
+			"INSERT INTO `catalog_product_entity` SET `sku`='SKU-1', `type_id`=?, `updated_at`=NOW()",
+			"INSERT INTO `catalog_product_entity` SET `sku`='SKU-1', `type_id`='simple', `updated_at`=NOW()",
+			"simple",
+		)
+	})
+
+	t.Run("record", func(t *testing.T) {
+		p := &dmlPerson{
+			Name:  "Pike",
+			Email: null.MakeString("pikes@peak.co"),
+		}
+		ins := dml.NewInsert("dml_person").SetPairs(dml.Column("name"), dml.Column("email"))
+		compareToSQL(t, ins.WithDBR().TestWithArgs(dml.Qualify("", p)), errors.NoKind,
+			"INSERT INTO `dml_person` SET `name`=?, `email`=?",
+			"INSERT INTO `dml_person` SET `name`='Pike', `email`='pikes@peak.co'",
+			"Pike", "pikes@peak.co",
+		)
+	})
+
+	t.Run("on duplicate key", func(t *testing.T) {
+		ins := dml.NewInsert("dml_person").
+			SetPairs(dml.Column("id").Int(1), dml.Column("name").Str("Pike")).
+			AddOnDuplicateKeyExclude("id")
+		compareToSQL(t, ins, errors.NoKind,
+			"INSERT INTO `dml_person` SET `id`=1, `name`='Pike' ON DUPLICATE KEY UPDATE `name`=VALUES(`name`)",
+			"",
+		)
+	})
+
+	t.Run("combined with pairs", func(t *testing.T) {
+		ins := dml.NewInsert("dml_person").
+			SetPairs(dml.Column("name").Str("Pike")).
+			WithPairs(dml.Column("email").Str("pikes@peak.co"))
+		compareToSQL(t, ins, errors.NotAllowed, "", "")
+	})
+}
+
+func TestInsert_WithChunkSize(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	t.Run("records", func(t *testing.T) {
+		persons := []*dmlPerson{{Name: "A"}, {Name: "B"}, {Name: "C"}, {Name: "D"}, {Name: "E"}}
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `dml_person` (`name`,`store_id`) VALUES (?,?),(?,?)")).
+			WithArgs("A", 0, "B", 0).WillReturnResult(sqlmock.NewResult(11, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `dml_person` (`name`,`store_id`) VALUES (?,?),(?,?)")).
+			WithArgs("C", 0, "D", 0).WillReturnResult(sqlmock.NewResult(13, 2))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `dml_person` (`name`,`store_id`) VALUES (?,?)")).
+			WithArgs("E", 0).WillReturnResult(sqlmock.NewResult(20, 1))
+
+		args := make([]interface{}, len(persons))
+		for i, p := range persons {
+			args[i] = dml.Qualify("", p)
+		}
+		res, err := dbc.InsertInto("dml_person").AddColumns("name", "store_id").WithChunkSize(2).
+			WithDBR().ExecContext(context.TODO(), args...)
+		assert.NoError(t, err)
+		ra, err := res.RowsAffected()
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(5), ra)
+		lid, err := res.LastInsertId()
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(11), lid)
+		for i, want := range []int64{11, 12, 13, 14, 20} {
+			assert.Exactly(t, want, persons[i].ID, "Index %d", i)
+		}
+	})
+
+	t.Run("plain arguments with interpolation", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `dml_person` (`name`) VALUES ('A'),('B'),('C')")).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `dml_person` (`name`) VALUES ('D')")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		res, err := dbc.InsertInto("dml_person").AddColumns("name").WithChunkSize(3).
+			WithDBR().Interpolate().ExecContext(context.TODO(), "A", "B", "C", "D")
+		assert.NoError(t, err)
+		ra, err := res.RowsAffected()
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(4), ra)
+	})
+
+	t.Run("arguments do not fit", func(t *testing.T) {
+		_, err := dbc.InsertInto("dml_person").AddColumns("name", "store_id").WithChunkSize(3).
+			WithDBR().ExecContext(context.TODO(), "A", 1, "B")
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
+
 func TestInsert_Clone(t *testing.T) {
 	t.Parallel()
 
@@ -15,6 +15,7 @@
 package dml
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"testing"
@@ -112,6 +113,77 @@ func TestInsert_SetValuesCount(t *testing.T) {
 	})
 }
 
+type defaultRecord struct {
+	ID   int64
+	Name string // if empty DEFAULT gets used
+}
+
+func (r defaultRecord) MapColumns(cm *ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "id":
+			cm.Int64(&r.ID)
+		case "name":
+			if r.Name == "" {
+				cm.Default()
+			} else {
+				cm.String(&r.Name)
+			}
+		default:
+			return errors.NotFound.Newf("[dml_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
+
+func TestInsert_Default(t *testing.T) {
+	t.Parallel()
+
+	t.Run("arguments", func(t *testing.T) {
+		ins := NewInsert("a").AddColumns("b", "c").WithDBR()
+		compareToSQL(t, ins.TestWithArgs(1, Default, Default, "x"), errors.NoKind,
+			"INSERT INTO `a` (`b`,`c`) VALUES (?,DEFAULT),(DEFAULT,?)",
+			"INSERT INTO `a` (`b`,`c`) VALUES (1,DEFAULT),(DEFAULT,'x')",
+			int64(1), "x",
+		)
+	})
+	t.Run("records", func(t *testing.T) {
+		ins := NewInsert("a").AddColumns("id", "name").WithDBR()
+		compareToSQL(t, ins.TestWithArgs(Qualify("", defaultRecord{ID: 1}), Qualify("", defaultRecord{ID: 2, Name: "n2"})), errors.NoKind,
+			"INSERT INTO `a` (`id`,`name`) VALUES (?,DEFAULT),(?,?)",
+			"INSERT INTO `a` (`id`,`name`) VALUES (1,DEFAULT),(2,'n2')",
+			int64(1), int64(2), "n2",
+		)
+	})
+	t.Run("BuildValues", func(t *testing.T) {
+		ins := NewInsert("a").AddColumns("b", "c").BuildValues().WithDBR()
+		compareToSQL(t, ins.TestWithArgs(Default, 3), errors.NoKind,
+			"INSERT INTO `a` (`b`,`c`) VALUES (DEFAULT,?)",
+			"INSERT INTO `a` (`b`,`c`) VALUES (DEFAULT,3)",
+			int64(3),
+		)
+	})
+	t.Run("row constructor", func(t *testing.T) {
+		ins := NewInsert("a").AddColumns("b", "c").RowConstructor().WithDBR()
+		compareToSQL(t, ins.TestWithArgs(1, Default, Default, "x"), errors.NoKind,
+			"INSERT INTO `a` (`b`,`c`) VALUES ROW(?,DEFAULT),ROW(DEFAULT,?)",
+			"INSERT INTO `a` (`b`,`c`) VALUES ROW(1,DEFAULT),ROW(DEFAULT,'x')",
+			int64(1), "x",
+		)
+		compareToSQL2(t, NewInsert("a").AddColumns("b", "c").SetRowCount(2).RowConstructor().BuildValues(), errors.NoKind,
+			"INSERT INTO `a` (`b`,`c`) VALUES ROW(?,?),ROW(?,?)",
+		)
+	})
+	t.Run("quoted place holder", func(t *testing.T) {
+		var buf bytes.Buffer
+		args := []interface{}{Default, "x"}
+		args2 := writeDefaultPlaceHolders(&buf, "INSERT INTO `a?` (`b`,`c`) VALUES (?,CONCAT('?',?))", args)
+		assert.Exactly(t, "INSERT INTO `a?` (`b`,`c`) VALUES (DEFAULT,CONCAT('?',?))", buf.String())
+		assert.Exactly(t, []interface{}{"x"}, args2)
+		assert.Exactly(t, []interface{}{Default, "x"}, args, "caller's slice must not change")
+	})
+}
+
 func TestInsertKeywordColumnName(t *testing.T) {
 	// Insert a column whose name is reserved
 	s := createRealSessionWithFixtures(t, nil)
@@ -574,6 +646,47 @@ func TestInsert_OnDuplicateKey(t *testing.T) {
 	})
 }
 
+func TestInsert_OnDuplicateKeyExpressions(t *testing.T) {
+	t.Parallel()
+
+	newIns := func() *Insert {
+		return NewInsert("cataloginventory_stock_item").
+			AddColumns("product_id", "qty", "version", "updated_at").
+			AddOnDuplicateKey(
+				Column("qty").PlusValues(),
+				Column("version").ValuesOf("version"),
+				Column("updated_at").SQLIf("`version` < "+SQLValues("version"), SQLValues("updated_at"), "`updated_at`"),
+			)
+	}
+
+	t.Run("VALUES", func(t *testing.T) {
+		compareToSQL(t, newIns().WithDBR().TestWithArgs(1, 2, 3, "2019-01-01"), errors.NoKind,
+			"INSERT INTO `cataloginventory_stock_item` (`product_id`,`qty`,`version`,`updated_at`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `qty`=`qty`+VALUES(`qty`), `version`=VALUES(`version`), `updated_at`=IF((`version` < VALUES(`version`)), VALUES(`updated_at`), `updated_at`)",
+			"INSERT INTO `cataloginventory_stock_item` (`product_id`,`qty`,`version`,`updated_at`) VALUES (1,2,3,'2019-01-01') ON DUPLICATE KEY UPDATE `qty`=`qty`+VALUES(`qty`), `version`=VALUES(`version`), `updated_at`=IF((`version` < VALUES(`version`)), VALUES(`updated_at`), `updated_at`)",
+			int64(1), int64(2), int64(3), "2019-01-01",
+		)
+	})
+
+	t.Run("row alias", func(t *testing.T) {
+		compareToSQL(t, newIns().WithRowAlias("new").WithDBR().TestWithArgs(1, 2, 3, "2019-01-01", 4, 5, 6, "2019-01-02"), errors.NoKind,
+			"INSERT INTO `cataloginventory_stock_item` (`product_id`,`qty`,`version`,`updated_at`) VALUES (?,?,?,?),(?,?,?,?) AS `new` ON DUPLICATE KEY UPDATE `qty`=`qty`+`new`.`qty`, `version`=`new`.`version`, `updated_at`=IF((`version` < `new`.`version`), `new`.`updated_at`, `updated_at`)",
+			"INSERT INTO `cataloginventory_stock_item` (`product_id`,`qty`,`version`,`updated_at`) VALUES (1,2,3,'2019-01-01'),(4,5,6,'2019-01-02') AS `new` ON DUPLICATE KEY UPDATE `qty`=`qty`+`new`.`qty`, `version`=`new`.`version`, `updated_at`=IF((`version` < `new`.`version`), `new`.`updated_at`, `updated_at`)",
+			int64(1), int64(2), int64(3), "2019-01-01", int64(4), int64(5), int64(6), "2019-01-02",
+		)
+	})
+
+	t.Run("row alias all columns", func(t *testing.T) {
+		compareToSQL2(t, NewInsert("a").AddColumns("b", "c").OnDuplicateKey().WithRowAlias("n").BuildValues(), errors.NoKind,
+			"INSERT INTO `a` (`b`,`c`) VALUES (?,?) AS `n` ON DUPLICATE KEY UPDATE `b`=`n`.`b`, `c`=`n`.`c`",
+		)
+	})
+
+	t.Run("row alias with SELECT", func(t *testing.T) {
+		compareToSQL2(t, NewInsert("a").AddColumns("b").OnDuplicateKey().WithRowAlias("n").
+			FromSelect(NewSelect("b").From("c")), errors.NotAllowed, "")
+	})
+}
+
 // TestInsert_Parallel_Bind_Slice is a tough test because first a complex SQL
 // statement from a collection and second it runs in parallel.
 func TestInsert_Parallel_Bind_Slice(t *testing.T) {
@@ -70,7 +70,7 @@ func expandPlaceHolderTuples(buf *bytes.Buffer, sql []byte, argCount int) error
 			return errors.NotValid.Newf("[dml] expandPlaceHolderTuples rowCount can be zero. argCount must be at least %d but got %d", tupleCount, argCount)
 		}
 		buf.WriteByte('(')
-		writeTuplePlaceholders(buf, uint(argCount)/tupleCount, tupleCount)
+		writeTuplePlaceholders(buf, uint(argCount)/tupleCount, tupleCount, false)
 		buf.WriteByte(')')
 		buf.Write(sql[idxPlaceHolderTuples+14:])
 	}
@@ -126,6 +126,45 @@ func expandPlaceHolders(buf writer, sql []byte, args []interface{}) error {
 	return nil
 }
 
+// writeDefaultPlaceHolders copies `sql` into `buf` and replaces each place
+// holder whose argument is of type internalDEFAULT with the keyword DEFAULT.
+// Place holders within quotes get ignored. Those arguments and all records get
+// removed from the returned newly allocated slice.
+func writeDefaultPlaceHolders(buf *bytes.Buffer, sql string, args []interface{}) []interface{} {
+	args2 := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch arg.(type) {
+		case QualifiedRecord, ColumnMapper:
+		// remove
+		default:
+			args2 = append(args2, arg)
+		}
+	}
+	args = args2
+	args2 = make([]interface{}, 0, len(args))
+
+	var phCounter int
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			j := skipQuoted(sql, i, c)
+			buf.WriteString(sql[i : j+1])
+			i = j
+		case c == placeHolderRune && phCounter < len(args):
+			if _, ok := args[phCounter].(internalDEFAULT); ok {
+				buf.WriteString(sqlStrDefaultUC)
+			} else {
+				buf.WriteByte(placeHolderRune)
+				args2 = append(args2, args[phCounter])
+			}
+			phCounter++
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return args2
+}
+
 // ip handles the interpolation of the SQL string and uses an internal argument
 // pool for optimal slice usage.
 type ip struct {
@@ -173,7 +212,7 @@ func (in *ip) ToSQL() (_ string, alwaysNil []interface{}, _ error) {
 	}
 	buf := bufferpool.Get()
 	defer bufferpool.Put(buf)
-	if err := writeInterpolate(buf, in.queryCache, in.args); err != nil {
+	if err := writeInterpolate(buf, dialect, in.queryCache, in.args); err != nil {
 		return "", nil, errors.WithStack(err)
 	}
 	return buf.String(), nil, nil
@@ -271,7 +310,7 @@ func (in *ip) Named(nArgs ...sql.NamedArg) *ip {
 
 // writeInterpolate merges `args` into `sql` and writes the result into `buf`. `sql`
 // stays unchanged.
-func writeInterpolate(buf *bytes.Buffer, sql string, args []interface{}) error {
+func writeInterpolate(buf *bytes.Buffer, d mysqlDialect, sql string, args []interface{}) error {
 	// TODO support :name identifier and the name field in argument
 
 	phCount, argCount := strings.Count(sql, placeHolderStr), len(args)
@@ -288,7 +327,7 @@ func writeInterpolate(buf *bytes.Buffer, sql string, args []interface{}) error {
 		switch {
 		case r == placeHolderRune && argCount > 0:
 			if phCounter < argCount { // protect for index out of bounds
-				if err := writeInterfaceValue(args[phCounter], buf, 0); err != nil {
+				if err := writeInterfaceValue(args[phCounter], buf, d, 0); err != nil {
 					return errors.WithStack(err)
 				}
 			}
@@ -307,7 +346,7 @@ func writeInterpolate(buf *bytes.Buffer, sql string, args []interface{}) error {
 		case r == '[':
 			w = strings.IndexRune(sql[pos:], ']')
 			col := sql[pos : pos+w]
-			dialect.EscapeIdent(buf, col)
+			d.EscapeIdent(buf, col)
 			pos += w + 1 // size of ']'
 		default:
 			buf.WriteString(sql[pos-w : pos])
@@ -320,24 +359,22 @@ func writeInterpolate(buf *bytes.Buffer, sql string, args []interface{}) error {
 // writeInterpolateByte same as writeInterpolate. Maybe package unsafe can do
 // here some magic to avoid duplicate code, but for now we stick with a copy of
 // the above original function writeInterpolateByte.
-func writeInterpolateBytes(buf *bytes.Buffer, sql []byte, args []interface{}) error {
-	args2 := args[:0] // filter without memory allocation
+func writeInterpolateBytes(buf *bytes.Buffer, d mysqlDialect, sql []byte, args []interface{}) error {
+	// records get skipped while writing, so the caller's slice does not get
+	// modified and no filtered copy gets allocated.
+	argCount := 0
 	for _, arg := range args {
-		switch arg.(type) {
-		case QualifiedRecord, ColumnMapper:
-		// remove
-		default:
-			args2 = append(args2, arg)
+		if !isRecordArg(arg) {
+			argCount++
 		}
 	}
-	args = args2
 
-	phCount, argCount := bytes.Count(sql, placeHolderByte), len(args)
+	phCount := bytes.Count(sql, placeHolderByte)
 	if argCount > 0 && phCount != argCount {
 		return errors.Mismatch.Newf("[dml] Number of place holders (%d) vs number of arguments (%d) do not match.", phCount, argCount)
 	}
 
-	var phCounter int
+	var phCounter, argPos int
 	pos := 0
 	for pos < len(sql) {
 		r, w := utf8.DecodeRune(sql[pos:])
@@ -346,9 +383,13 @@ func writeInterpolateBytes(buf *bytes.Buffer, sql []byte, args []interface{}) er
 		switch {
 		case r == placeHolderRune && argCount > 0:
 			if phCounter < argCount { // protect for index out of bounds
-				if err := writeInterfaceValue(args[phCounter], buf, 0); err != nil {
+				for isRecordArg(args[argPos]) {
+					argPos++
+				}
+				if err := writeInterfaceValue(args[argPos], buf, d, 0); err != nil {
 					return errors.WithStack(err)
 				}
+				argPos++
 			}
 			phCounter++
 		case r == '`', r == '\'', r == '"':
@@ -365,7 +406,7 @@ func writeInterpolateBytes(buf *bytes.Buffer, sql []byte, args []interface{}) er
 		case r == '[':
 			w = bytes.IndexByte(sql[pos:], ']')
 			col := sql[pos : pos+w]
-			dialect.EscapeIdent(buf, string(col))
+			d.EscapeIdent(buf, string(col))
 			pos += w + 1 // size of ']'
 		default:
 			buf.Write(sql[pos-w : pos])
@@ -375,6 +416,16 @@ func writeInterpolateBytes(buf *bytes.Buffer, sql []byte, args []interface{}) er
 	return nil
 }
 
+// isRecordArg reports whether the argument is a record, which provides the
+// arguments but does not get interpolated itself.
+func isRecordArg(arg interface{}) bool {
+	switch arg.(type) {
+	case QualifiedRecord, ColumnMapper:
+		return true
+	}
+	return false
+}
+
 // extractReplaceNamedArgs extracts all occurrences of a pattern `:[^\s]+` and
 // replaces them with a ? placeholder. It does not remove duplicates because
 // those are needed for the amount of arguments to get. The extracted strings
@@ -397,12 +448,18 @@ func extractReplaceNamedArgs(sql string, qualifiedColumns []string) (_ string, _
 		case r == '\'' && !quoteStart:
 			quoteStart = true
 			newSQL.WriteRune(r)
-		case quoteStart:
-			// do nothing
+		case r == '\\' && quoteStart && pos < lSQL:
+			// an escaped character does not end the quoted string
+			newSQL.WriteRune(r)
+			r, w = utf8.DecodeRuneInString(sql[pos:])
+			pos += w
 			newSQL.WriteRune(r)
 		case r == '\'' && quoteStart:
 			quoteStart = false
 			newSQL.WriteRune(r)
+		case quoteStart:
+			// do nothing
+			newSQL.WriteRune(r)
 		case r == namedArgStartByte:
 			foundColon = true
 			buf.WriteByte(namedArgStartByte)
@@ -421,6 +478,10 @@ func extractReplaceNamedArgs(sql string, qualifiedColumns []string) (_ string, _
 			newSQL.WriteRune(r)
 		}
 	}
+	if foundColon && buf.Len() > 1 { // named argument at the end of the SQL
+		qualifiedColumns = append(qualifiedColumns, buf.String())
+		found = true
+	}
 	bufferpool.Put(buf)
 	return newSQL.String(), qualifiedColumns, found
 }
@@ -440,7 +501,7 @@ func isNamedArg(placeHolder string) (ret bool) {
 }
 
 func isNotNamedArgSeperator(r rune) bool {
-	return !unicode.IsLetter(r) && !isEmoji(r) && !unicode.IsDigit(r) && r != '.'
+	return !unicode.IsLetter(r) && !isEmoji(r) && !unicode.IsDigit(r) && r != '.' && r != '_'
 }
 
 // isEmoji represents one of the most important functions in this project.
@@ -219,6 +219,60 @@ func TestInterpolate_ArgValue(t *testing.T) {
 	})
 }
 
+type argValSKU string
+
+func (s *argValSKU) Value() (driver.Value, error) {
+	return "SKU-" + string(*s), nil
+}
+
+type argTextIP [4]byte
+
+func (ip argTextIP) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3])), nil
+}
+
+type argTextBroken struct{}
+
+func (argTextBroken) MarshalText() ([]byte, error) {
+	return []byte{0xff, 0xfe}, nil
+}
+
+func TestInterpolate_ValuerTextMarshaler(t *testing.T) {
+	t.Parallel()
+
+	sku := argValSKU("O'Reilly")
+	var skuNil *argValSKU
+
+	t.Run("interpolate", func(t *testing.T) {
+		compareToSQL2(t,
+			Interpolate("SELECT * FROM x WHERE a = ? AND b = ? AND c = ?").
+				Unsafe(&sku, argTextIP{127, 0, 0, 1}, skuNil),
+			errors.NoKind,
+			"SELECT * FROM x WHERE a = 'SKU-O\\'Reilly' AND b = '127.0.0.1' AND c = NULL",
+		)
+	})
+	t.Run("DBR", func(t *testing.T) {
+		compareToSQL(t,
+			NewSelect("a").From("x").Where(
+				Column("sku").PlaceHolder(),
+				Column("ip").PlaceHolder(),
+			).WithDBR().TestWithArgs(&sku, argTextIP{10, 0, 0, 2}),
+			errors.NoKind,
+			"SELECT `a` FROM `x` WHERE (`sku` = ?) AND (`ip` = ?)",
+			"SELECT `a` FROM `x` WHERE (`sku` = 'SKU-O\\'Reilly') AND (`ip` = '10.0.0.2')",
+			"SKU-O'Reilly", "10.0.0.2",
+		)
+	})
+	t.Run("text not UTF-8", func(t *testing.T) {
+		_, _, err := Interpolate("SELECT * FROM x WHERE a = ?").Unsafe(argTextBroken{}).ToSQL()
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+	t.Run("valuer type not supported", func(t *testing.T) {
+		_, _, err := Interpolate("SELECT * FROM x WHERE a = ?").Unsafe(argValUint16(0)).ToSQL()
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
+
 func TestInterpolate_Reset(t *testing.T) {
 	t.Parallel()
 
@@ -671,6 +725,16 @@ func TestExtractNamedArgs(t *testing.T) {
 		"date_start = 'It\\'s xmas' ORDER BY X",
 		"date_start = 'It\\'s xmas' ORDER BY X",
 	))
+	t.Run("with underscore", runner(
+		"SELECT * FROM t WHERE store_id = :store_id",
+		"SELECT * FROM t WHERE store_id = ?",
+		namedArgStartStr+"store_id",
+	))
+	t.Run("named arguments after quoted strings", runner(
+		"SELECT * FROM t WHERE a = ':no' AND b = 'It\\'s :no' AND c = :c AND d = '' AND e = :e",
+		"SELECT * FROM t WHERE a = ':no' AND b = 'It\\'s :no' AND c = ? AND d = '' AND e = ?",
+		namedArgStartStr+"c", namedArgStartStr+"e",
+	))
 }
 
 func Test_expandPlaceHolderTuples(t *testing.T) {
@@ -712,3 +776,24 @@ func Test_expandPlaceHolderTuples(t *testing.T) {
 		" WHERE ((`entity_id`) IN ((?)))",
 	))
 }
+
+type interpolateRecord struct{}
+
+func (interpolateRecord) MapColumns(cm *ColumnMap) error { return nil }
+
+func Test_writeInterpolateBytes(t *testing.T) {
+	args := []interface{}{int64(1), interpolateRecord{}, "a", QualifiedRecord{}, int64(3)}
+	sql := []byte("SELECT * FROM `t` WHERE `a`=? AND `c`=? AND `d`=?")
+	buf := new(bytes.Buffer)
+	assert.NoError(t, writeInterpolateBytes(buf, dialect, sql, args))
+	assert.Exactly(t, "SELECT * FROM `t` WHERE `a`=1 AND `c`='a' AND `d`=3", buf.String())
+	assert.Exactly(t, []interface{}{int64(1), interpolateRecord{}, "a", QualifiedRecord{}, int64(3)}, args, "arguments must not be modified")
+
+	allocs := testing.AllocsPerRun(10, func() {
+		buf.Reset()
+		if err := writeInterpolateBytes(buf, dialect, sql, args); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Exactly(t, float64(0), allocs)
+}
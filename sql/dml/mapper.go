@@ -25,7 +25,7 @@ import (
 
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/storage/null"
-	"github.com/corestoreio/pkg/util/byteconv"
+	"github.com/corestoreio/pkg/util/conv"
 )
 
 // ColumnMapper allows a type to load data from database query into its fields
@@ -58,9 +58,16 @@ type ColumnMap struct {
 	// HasRows set to true if at least one row has been found.
 	HasRows bool
 	// Count increments on call to Scan.
-	Count    uint64
-	scanArgs []interface{} // could be a sync.Pool but check it in benchmarks.
-	scanCol  []scannedColumn
+	Count uint64
+	// ScannedBytes contains the approximate amount of bytes of the result set
+	// scanned so far. Variable sized columns count with their length, all
+	// other columns with a fixed size.
+	ScannedBytes uint64
+	// maxScannedBytes if greater zero, lets Scan return ErrResultTooLarge
+	// when ScannedBytes exceeds it. See DBR.WithResultSizeLimit.
+	maxScannedBytes uint64
+	scanArgs        []interface{} // could be a sync.Pool but check it in benchmarks.
+	scanCol         []scannedColumn
 	// Columns contains the names of the column returned from the query or
 	// needed to build a query aka reading the arguments in the ColumnMapper
 	// interface. One should only read from the slice. Never modify it.
@@ -71,6 +78,15 @@ type ColumnMap struct {
 	// between chainable API and too verbose error checking.
 	scanErr error
 	index   int // current column index
+	// serverTimeZone if set, converts scanned time values from the server
+	// time zone to UTC. See WithServerTimeZone.
+	serverTimeZone *time.Location
+	// compression if set, compresses the arguments and decompresses the
+	// scanned values of the registered columns. See WithColumnCompression.
+	compression map[string]*columnCompressor
+	// encryption if set, encrypts the arguments and decrypts the scanned
+	// values of the registered columns. See WithColumnEncryption.
+	encryption map[string]*columnEncrypter
 }
 
 // NewColumnMap exported for testing reasons.
@@ -103,6 +119,11 @@ func (b *ColumnMap) reset() {
 	b.columnsLen = 0
 	b.scanErr = nil
 	b.index = 0
+	b.serverTimeZone = nil
+	b.compression = nil
+	b.encryption = nil
+	b.ScannedBytes = 0
+	b.maxScannedBytes = 0
 }
 
 func (b *ColumnMap) setColumns(cols []string) {
@@ -204,6 +225,21 @@ func (s scannedColumn) String() string {
 	return fmt.Sprintf("Field Type %q not supported", s.field)
 }
 
+// size returns the approximate amount of bytes of the scanned value.
+func (s *scannedColumn) size() uint64 {
+	switch s.field {
+	case 'y':
+		return uint64(len(s.byte))
+	case 's':
+		return uint64(len(s.string))
+	case 'b':
+		return 1
+	case 'i', 'f', 't':
+		return 8
+	}
+	return 0
+}
+
 func (s *scannedColumn) reset() {
 	s.field = 0
 	s.bool = false
@@ -225,6 +261,14 @@ func (s *scannedColumn) Scan(src interface{}) (err error) {
 	case int: // sqlmock package requires this
 		s.field = 'i'
 		s.int64 = int64(val)
+	case uint64: // same behaviour as the binary protocol of the MySQL driver
+		if val > math.MaxInt64 {
+			s.field = 'y'
+			s.byte = strconv.AppendUint(s.byte[:0], val, 10)
+		} else {
+			s.field = 'i'
+			s.int64 = int64(val)
+		}
 	case float32:
 		s.field = 'f'
 		s.float64 = float64(val)
@@ -287,6 +331,40 @@ func (b *ColumnMap) Scan(r *sql.Rows) error {
 	if err := r.Scan(b.scanArgs...); err != nil {
 		return errors.WithStack(err)
 	}
+	for i := range b.scanCol {
+		b.ScannedBytes += b.scanCol[i].size()
+	}
+	if b.maxScannedBytes > 0 && b.ScannedBytes > b.maxScannedBytes {
+		return errors.Wrapf(ErrResultTooLarge, "[dml] ColumnMap.Scan: %d scanned bytes exceed the limit of %d bytes", b.ScannedBytes, b.maxScannedBytes)
+	}
+	return nil
+}
+
+// scanCachedRow assigns a row of a cached result set instead of scanning a row
+// of sql.Rows. The byte slices of the row get shared and must not be modified.
+func (b *ColumnMap) scanCachedRow(cols []string, row []scannedColumn) error {
+	if !b.initialized {
+		b.setColumns(cols)
+		// never reuse the pooled slices because their byte slices might still
+		// reference a driver buffer.
+		b.scanCol = make([]scannedColumn, b.columnsLen)
+		b.scanArgs = make([]interface{}, b.columnsLen)
+		for i := 0; i < b.columnsLen; i++ {
+			b.scanArgs[i] = &b.scanCol[i]
+		}
+		b.initialized = true
+		b.Count = 0
+		b.HasRows = true
+	} else {
+		b.Count++
+	}
+	copy(b.scanCol, row)
+	for i := range b.scanCol {
+		b.ScannedBytes += b.scanCol[i].size()
+	}
+	if b.maxScannedBytes > 0 && b.ScannedBytes > b.maxScannedBytes {
+		return errors.Wrapf(ErrResultTooLarge, "[dml] ColumnMap.Scan: %d scanned bytes exceed the limit of %d bytes", b.ScannedBytes, b.maxScannedBytes)
+	}
 	return nil
 }
 
@@ -336,7 +414,11 @@ func (b *ColumnMap) Bool(ptr *bool) *ColumnMap {
 		case 'i':
 			*ptr = v.int64 == 1
 		case 'y':
-			*ptr, _, b.scanErr = byteconv.ParseBool(v.byte)
+			if len(v.byte) == 1 && v.byte[0] <= 1 { // BIT(1) column
+				*ptr = v.byte[0] == 1
+				break
+			}
+			*ptr, _, b.scanErr = conv.ParseBool(v.byte)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
 			}
@@ -376,6 +458,10 @@ func (b *ColumnMap) NullBool(ptr *null.Bool) *ColumnMap {
 			ptr.Bool = v.int64 == 1
 			ptr.Valid = true
 		case 'y':
+			if len(v.byte) == 1 && v.byte[0] <= 1 { // BIT(1) column
+				*ptr = null.MakeBool(v.byte[0] == 1)
+				break
+			}
 			*ptr, b.scanErr = null.MakeBoolFromByte(v.byte)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -416,7 +502,7 @@ func (b *ColumnMap) Int(ptr *int) *ColumnMap {
 			*ptr = int(v.int64)
 		case 'y':
 			var i64 int64
-			i64, _, b.scanErr = byteconv.ParseInt(v.byte)
+			i64, _, b.scanErr = conv.ParseInt64(v.byte)
 			*ptr = int(i64)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -447,7 +533,7 @@ func (b *ColumnMap) Int64(ptr *int64) *ColumnMap {
 	case 'i':
 		*ptr = v.int64
 	case 'y':
-		*ptr, _, b.scanErr = byteconv.ParseInt(v.byte)
+		*ptr, _, b.scanErr = conv.ParseInt64(v.byte)
 		if b.scanErr != nil {
 			b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
 		}
@@ -477,7 +563,7 @@ func (b *ColumnMap) Int32(ptr *int32) *ColumnMap {
 		*ptr = int32(v.int64)
 	case 'y':
 		var i64 int64
-		i64, _, b.scanErr = byteconv.ParseInt(v.byte)
+		i64, _, b.scanErr = conv.ParseInt64(v.byte)
 		switch {
 		case b.scanErr != nil:
 			b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -512,7 +598,7 @@ func (b *ColumnMap) Int16(ptr *int16) *ColumnMap {
 		*ptr = int16(v.int64)
 	case 'y':
 		var i64 int64
-		i64, _, b.scanErr = byteconv.ParseInt(v.byte)
+		i64, _, b.scanErr = conv.ParseInt64(v.byte)
 		switch {
 		case b.scanErr != nil:
 			b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -547,7 +633,7 @@ func (b *ColumnMap) Int8(ptr *int8) *ColumnMap {
 		*ptr = int8(v.int64)
 	case 'y':
 		var i64 int64
-		i64, _, b.scanErr = byteconv.ParseInt(v.byte)
+		i64, _, b.scanErr = conv.ParseInt64(v.byte)
 		switch {
 		case b.scanErr != nil:
 			b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -835,7 +921,7 @@ func (b *ColumnMap) Float64(ptr *float64) *ColumnMap {
 		case 'f':
 			*ptr = v.float64
 		case 'y':
-			*ptr, _, b.scanErr = byteconv.ParseFloat(v.byte)
+			*ptr, _, b.scanErr = conv.ParseFloat64(v.byte)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
 			}
@@ -924,7 +1010,7 @@ func (b *ColumnMap) Uint(ptr *uint) *ColumnMap {
 			*ptr = uint(v.int64)
 		case 'y':
 			var u64 uint64
-			u64, _, b.scanErr = byteconv.ParseUint(v.byte, 10, strconv.IntSize)
+			u64, _, b.scanErr = conv.ParseUint(v.byte, strconv.IntSize)
 			*ptr = uint(u64)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -954,7 +1040,7 @@ func (b *ColumnMap) Uint8(ptr *uint8) *ColumnMap {
 			*ptr = uint8(v.int64)
 		case 'y':
 			var u64 uint64
-			u64, _, b.scanErr = byteconv.ParseUint(v.byte, 10, 8)
+			u64, _, b.scanErr = conv.ParseUint(v.byte, 8)
 			*ptr = uint8(u64)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -984,7 +1070,7 @@ func (b *ColumnMap) Uint16(ptr *uint16) *ColumnMap {
 			*ptr = uint16(v.int64)
 		case 'y':
 			var u64 uint64
-			u64, _, b.scanErr = byteconv.ParseUint(v.byte, 10, 16)
+			u64, _, b.scanErr = conv.ParseUint(v.byte, 16)
 			*ptr = uint16(u64)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -1014,7 +1100,7 @@ func (b *ColumnMap) Uint32(ptr *uint32) *ColumnMap {
 			*ptr = uint32(v.int64)
 		case 'y':
 			var u64 uint64
-			u64, _, b.scanErr = byteconv.ParseUint(v.byte, 10, 32)
+			u64, _, b.scanErr = conv.ParseUint(v.byte, 32)
 			*ptr = uint32(u64)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
@@ -1043,7 +1129,7 @@ func (b *ColumnMap) Uint64(ptr *uint64) *ColumnMap {
 		case 'i':
 			*ptr = uint64(v.int64)
 		case 'y':
-			*ptr, _, b.scanErr = byteconv.ParseUint(v.byte, 10, strconv.IntSize)
+			*ptr, _, b.scanErr = conv.ParseUint64(v.byte)
 			if b.scanErr != nil {
 				b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
 			}
@@ -1054,6 +1140,54 @@ func (b *ColumnMap) Uint64(ptr *uint64) *ColumnMap {
 	return b
 }
 
+// Bit reads the value of a BIT(n) column and appends it to the arguments slice
+// or assigns the value to the pointer. The server returns a BIT value as a big
+// endian byte slice with a length of (n+7)/8 bytes. While collecting the
+// arguments the value gets appended as an unsigned integer, which the server
+// converts into the bits. Use function Bool for a BIT(1) column.
+func (b *ColumnMap) Bit(ptr *uint64) *ColumnMap {
+	if b.shouldCollectArgs() {
+		if ptr == nil {
+			b.args = append(b.args, internalNULLNIL{})
+		} else {
+			b.args = append(b.args, *ptr)
+		}
+		return b
+	}
+	if b.scanErr == nil {
+		switch v := b.scanCol[b.index]; v.field {
+		case 'i':
+			*ptr = uint64(v.int64)
+		case 'y':
+			if len(v.byte) > 8 {
+				b.scanErr = errors.BadEncoding.Newf("[dml] Column %q BIT value exceeds 64 bits: %d bytes", b.Column(), len(v.byte))
+				return b
+			}
+			var u64 uint64
+			for _, c := range v.byte {
+				u64 = u64<<8 | uint64(c)
+			}
+			*ptr = u64
+		case 'n':
+			*ptr = 0
+		default:
+			b.scanErr = errors.NotSupported.Newf("[dml] Column %q does not support field type: %q", b.Column(), v.field)
+		}
+	}
+	return b
+}
+
+// Default appends the DEFAULT keyword marker to the arguments slice while
+// collecting arguments for an INSERT statement. The place holder of the current
+// column gets replaced with DEFAULT so the server uses the column default
+// value. In scan mode it does nothing.
+func (b *ColumnMap) Default() *ColumnMap {
+	if b.shouldCollectArgs() {
+		b.args = append(b.args, Default)
+	}
+	return b
+}
+
 type ioWriter interface {
 	Write(p []byte) (n int, err error)
 }
@@ -1083,13 +1217,18 @@ func (b *ColumnMap) Debug(w ioWriter) (err error) {
 // for function Scan.
 func (b *ColumnMap) Byte(ptr *[]byte) *ColumnMap {
 	if b.shouldCollectArgs() {
-		if ptr == nil {
+		switch cc, ce := b.compressor(), b.encrypter(); {
+		case ptr == nil:
 			b.args = append(b.args, internalNULLNIL{})
-		} else {
+		case (cc != nil || ce != nil) && *ptr != nil:
+			b.args = append(b.args, b.encodeValue(cc, ce, *ptr))
+		default:
 			b.args = append(b.args, *ptr)
 		}
 		return b
 	}
+	b.decryptCurrent()
+	b.decompressCurrent()
 	if b.scanErr == nil {
 		switch v := b.scanCol[b.index]; v.field {
 		case 's':
@@ -1174,14 +1313,19 @@ func (b *ColumnMap) Binary(enc interface {
 // for function Scan.
 func (b *ColumnMap) String(ptr *string) *ColumnMap {
 	if b.shouldCollectArgs() {
-		if ptr == nil {
+		switch cc, ce := b.compressor(), b.encrypter(); {
+		case ptr == nil:
 			b.args = append(b.args, internalNULLNIL{})
-		} else {
+		case cc != nil || ce != nil:
+			b.args = append(b.args, b.encodeValue(cc, ce, []byte(*ptr)))
+		default:
 			b.args = append(b.args, *ptr)
 		}
 		return b
 	}
 
+	b.decryptCurrent()
+	b.decompressCurrent()
 	if b.scanErr == nil {
 		switch v := b.scanCol[b.index]; v.field {
 		case 's':
@@ -1208,14 +1352,19 @@ func (b *ColumnMap) String(ptr *string) *ColumnMap {
 // documentation for function Scan.
 func (b *ColumnMap) NullString(ptr *null.String) *ColumnMap {
 	if b.shouldCollectArgs() {
-		if ptr == nil {
+		switch cc, ce := b.compressor(), b.encrypter(); {
+		case ptr == nil:
 			b.args = append(b.args, internalNULLNIL{})
-		} else {
+		case (cc != nil || ce != nil) && ptr.Valid:
+			b.args = append(b.args, b.encodeValue(cc, ce, []byte(ptr.Data)))
+		default:
 			b.args = append(b.args, *ptr)
 		}
 		return b
 	}
 
+	b.decryptCurrent()
+	b.decompressCurrent()
 	if b.scanErr == nil {
 		switch v := b.scanCol[b.index]; v.field {
 		case 's':
@@ -1263,9 +1412,7 @@ func (b *ColumnMap) Time(ptr *time.Time) *ColumnMap {
 			if len(v.byte) == 0 {
 				b.scanErr = errors.Empty.Newf("[dml] Column %q Time cannot be empty.", b.Column())
 			} else {
-				var nt null.Time
-				nt, b.scanErr = null.ParseDateTime(string(v.byte), time.UTC) // time.Location can be merged into ColumnMap but then change NullTime method receiver.
-				*ptr = nt.Time
+				*ptr, _, b.scanErr = conv.ParseDateTime(string(v.byte), time.UTC) // time.Location can be merged into ColumnMap but then change NullTime method receiver.
 				if b.scanErr != nil {
 					b.scanErr = errors.BadEncoding.New(b.scanErr, "[dml] Column %q", b.Column())
 				}
@@ -1273,6 +1420,9 @@ func (b *ColumnMap) Time(ptr *time.Time) *ColumnMap {
 		default:
 			b.scanErr = errors.NotSupported.Newf("[dml] Column %q does not support field type: %q", b.Column(), v.field)
 		}
+		if b.serverTimeZone != nil && b.scanErr == nil {
+			*ptr = timeFromServer(*ptr, b.serverTimeZone)
+		}
 	}
 	return b
 }
@@ -1308,6 +1458,9 @@ func (b *ColumnMap) NullTime(ptr *null.Time) *ColumnMap {
 		default:
 			b.scanErr = errors.NotSupported.Newf("[dml] Column %q does not support field type: %q", b.Column(), v.field)
 		}
+		if b.serverTimeZone != nil && b.scanErr == nil && ptr.Valid {
+			ptr.Time = timeFromServer(ptr.Time, b.serverTimeZone)
+		}
 	}
 	return b
 }
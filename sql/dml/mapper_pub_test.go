@@ -896,3 +896,148 @@ func TestColumnMap_Prepared(t *testing.T) {
 		nil, []byte("error"), // "text", "binary",
 	))
 }
+
+func TestColumnMap_ScannedBytes(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	newRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "email"}).
+			AddRow(1, []byte("0123456789")).
+			AddRow(2, nil).
+			AddRow(3, []byte("0123456789"))
+	}
+	ctx := context.Background()
+
+	t.Run("IterateSerial accounts", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email` FROM `blobs`")).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("id", "email").WithDBR()
+		var perRow []uint64
+		err := dbr.IterateSerial(ctx, func(cm *dml.ColumnMap) error {
+			perRow = append(perRow, cm.ScannedBytes)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Exactly(t, []uint64{18, 26, 44}, perRow)
+		assert.Exactly(t, uint64(44), dbr.ResultSize())
+	})
+	t.Run("IterateParallel accounts", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email` FROM `blobs`")).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("id", "email").WithDBR()
+		err := dbr.IterateParallel(ctx, 2, func(cm *dml.ColumnMap) error { return nil })
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(44), dbr.ResultSize())
+	})
+	t.Run("Load exceeds limit", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email` FROM `blobs`")).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("id", "email").WithDBR().WithResultSizeLimit(30)
+		_, err := dbr.Load(ctx, &dmlPerson{})
+		assert.ErrorIsKind(t, errors.OutOfRange, err)
+		assert.Exactly(t, dml.ErrResultTooLarge, errors.Cause(err))
+		assert.Exactly(t, uint64(44), dbr.ResultSize())
+	})
+	t.Run("IterateParallel exceeds limit", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `email` FROM `blobs`")).WillReturnRows(newRows())
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("id", "email").WithDBR().WithResultSizeLimit(20)
+		err := dbr.IterateParallel(ctx, 2, func(cm *dml.ColumnMap) error { return nil })
+		assert.ErrorIsKind(t, errors.OutOfRange, err)
+	})
+	t.Run("LoadStrings exceeds limit before appending", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `email` FROM `blobs`")).
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("a@b.c").AddRow("a-much-longer@example.com"))
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("email").WithDBR().WithResultSizeLimit(10)
+		emails, err := dbr.LoadStrings(ctx, nil)
+		assert.Exactly(t, dml.ErrResultTooLarge, errors.Cause(err))
+		assert.Nil(t, emails)
+		assert.Exactly(t, uint64(30), dbr.ResultSize())
+	})
+	t.Run("LoadNullInt64s within limit", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id` FROM `blobs`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(nil))
+
+		dbr := dbc.SelectFrom("blobs").AddColumns("id").WithDBR().WithResultSizeLimit(16)
+		ids, err := dbr.LoadNullInt64s(ctx, nil)
+		assert.NoError(t, err)
+		assert.Len(t, ids, 2)
+		assert.Exactly(t, uint64(16), dbr.ResultSize())
+	})
+}
+
+type bitEntity struct {
+	ID     uint64
+	Flags  uint64
+	Active bool
+}
+
+func (e *bitEntity) MapColumns(cm *dml.ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "id":
+			cm.Uint64(&e.ID)
+		case "flags":
+			cm.Bit(&e.Flags)
+		case "active":
+			cm.Bool(&e.Active)
+		default:
+			return errors.NotFound.Newf("[dml_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
+
+func TestColumnMap_BitAndMaxUint64(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	const bigID uint64 = 18446744073700551613
+	ctx := context.Background()
+
+	t.Run("insert arguments", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `bits` (`id`,`flags`,`active`) VALUES (?,?,?)")).
+			WithArgs(bigID, int64(5), true).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := dbc.InsertInto("bits").AddColumns("id", "flags", "active").WithDBR().
+			ExecContext(ctx, &bitEntity{ID: bigID, Flags: 5, Active: true})
+		assert.NoError(t, err)
+	})
+	t.Run("interpolated", func(t *testing.T) {
+		sqlStr, args, err := dbc.SelectFrom("bits").AddColumns("id").Where(dml.Column("id").Uint64(bigID)).
+			WithDBR().Interpolate().ToSQL()
+		assert.NoError(t, err)
+		assert.Nil(t, args)
+		assert.Exactly(t, "SELECT `id` FROM `bits` WHERE (`id` = 18446744073700551613)", sqlStr)
+	})
+	t.Run("prepared argument", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id` FROM `bits` WHERE (`id` = ?)")).
+			WithArgs(bigID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow([]byte("18446744073700551613")))
+
+		ids, err := dbc.SelectFrom("bits").AddColumns("id").Where(dml.Column("id").PlaceHolder()).
+			WithDBR().LoadUint64s(ctx, nil, bigID)
+		assert.NoError(t, err)
+		assert.Exactly(t, []uint64{bigID}, ids)
+	})
+	t.Run("scan BIT columns", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `flags`, `active` FROM `bits`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "flags", "active"}).
+				AddRow([]byte("18446744073700551613"), []byte{0x01, 0x02}, []byte{0x01}))
+
+		e := new(bitEntity)
+		_, err := dbc.SelectFrom("bits").AddColumns("id", "flags", "active").WithDBR().Load(ctx, e)
+		assert.NoError(t, err)
+		assert.Exactly(t, bitEntity{ID: bigID, Flags: 258, Active: true}, *e)
+	})
+	t.Run("BIT exceeds 64 bits", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `flags` FROM `bits`")).
+			WillReturnRows(sqlmock.NewRows([]string{"flags"}).AddRow(make([]byte, 9)))
+
+		_, err := dbc.SelectFrom("bits").AddColumns("flags").WithDBR().Load(ctx, new(bitEntity))
+		assert.ErrorIsKind(t, errors.BadEncoding, err)
+	})
+}
@@ -0,0 +1,353 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/storage/null"
+)
+
+// structMappings caches the *structMapping per reflect.Type of a struct.
+var structMappings sync.Map
+
+// structMappedTypes contains the supported field types of a struct. For each
+// type exists a function in ColumnMap.
+var structMappedTypes = map[reflect.Type]bool{}
+
+func init() {
+	for _, v := range []interface{}{
+		false, "", []byte(nil), time.Time{}, float64(0),
+		int(0), int64(0), int32(0), int16(0), int8(0),
+		uint(0), uint64(0), uint32(0), uint16(0), uint8(0),
+		null.Bool{}, null.String{}, null.Time{}, null.Float64{}, null.Decimal{},
+		null.Int64{}, null.Int32{}, null.Int16{}, null.Int8{},
+		null.Uint64{}, null.Uint32{}, null.Uint16{}, null.Uint8{},
+	} {
+		structMappedTypes[reflect.TypeOf(v)] = true
+	}
+}
+
+type structField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+type structMapping struct {
+	fields []structField
+	byName map[string]int
+	// hasTags is true if at least one field has a db tag. Only such structs
+	// get detected as arguments of a DBR.
+	hasTags bool
+	err     error
+}
+
+func (sm *structMapping) field(name string) (structField, bool) {
+	i, ok := sm.byName[name]
+	if !ok {
+		return structField{}, false
+	}
+	return sm.fields[i], true
+}
+
+// loadStructMapping returns the cached mapping of the struct type.
+func loadStructMapping(rt reflect.Type) *structMapping {
+	if sm, ok := structMappings.Load(rt); ok {
+		return sm.(*structMapping)
+	}
+	sm := &structMapping{byName: make(map[string]int, rt.NumField())}
+	sm.collect(rt, nil)
+	if sm.err == nil && len(sm.fields) == 0 {
+		sm.err = errors.Empty.Newf("[dml] StructMapper: Type %s has no exported fields", rt)
+	}
+	actual, _ := structMappings.LoadOrStore(rt, sm)
+	return actual.(*structMapping)
+}
+
+// collect adds the fields in the order of their declaration. Fields of the
+// outer struct take precedence over the fields of embedded structs, like in
+// BindNamed.
+func (sm *structMapping) collect(rt reflect.Type, index []int) {
+	var embedded []reflect.StructField
+	for i := 0; i < rt.NumField() && sm.err == nil; i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			tag = tag[:i]
+		}
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct && !structMappedTypes[sf.Type] {
+			embedded = append(embedded, sf)
+			continue
+		}
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Ptr {
+			continue // a nil pointer cannot receive the scanned values
+		}
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = sf.Name
+		} else {
+			sm.hasTags = true
+		}
+		if _, ok := sm.byName[name]; ok {
+			continue
+		}
+		if !structMappedTypes[sf.Type] {
+			sm.err = errors.NotSupported.Newf("[dml] StructMapper: Type %s of field %q in %s is not supported", sf.Type, sf.Name, rt)
+			return
+		}
+		sm.byName[name] = len(sm.fields)
+		sm.fields = append(sm.fields, structField{
+			name:  name,
+			index: append(append([]int(nil), index...), sf.Index...),
+			typ:   sf.Type,
+		})
+	}
+	for _, sf := range embedded {
+		sm.collect(sf.Type, append(append([]int(nil), index...), sf.Index...))
+	}
+}
+
+// StructMapper creates a ColumnMapper for a pointer to a plain struct or a
+// pointer to a slice of structs or of pointers to structs. The column name of
+// a field is taken from the `db` struct tag, or if missing, from the field
+// name. A tag value of "-" skips the field. Embedded structs are supported.
+// The mapping of a type gets created via reflection once and then cached.
+// Fields must have a type for which ColumnMap provides a function, e.g. int64,
+// string, time.Time or null.String. A struct with at least one `db` tag can be
+// passed directly as an argument to a DBR without calling StructMapper.
+//		type customer struct {
+//			ID    uint64      `db:"entity_id"`
+//			Email null.String `db:"email"`
+//		}
+//		var cs []customer
+//		_, err := dbc.SelectFrom("customer_entity").AddColumns("entity_id", "email").
+//			WithDBR().Load(ctx, dml.StructMapper(&cs))
+func StructMapper(ptr interface{}) ColumnMapper {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return structMapper{err: errors.NotValid.Newf("[dml] StructMapper requires a non-nil pointer but got %T", ptr)}
+	}
+	rv = rv.Elem()
+	switch rv.Kind() {
+	case reflect.Struct:
+		sm := loadStructMapping(rv.Type())
+		return structMapper{sm: sm, rv: rv, err: sm.err}
+	case reflect.Slice:
+		et := rv.Type().Elem()
+		isPtr := et.Kind() == reflect.Ptr
+		if isPtr {
+			et = et.Elem()
+		}
+		if et.Kind() == reflect.Struct {
+			sm := loadStructMapping(et)
+			return structSliceMapper{sm: sm, rv: rv, isPtr: isPtr, err: sm.err}
+		}
+	}
+	return structMapper{err: errors.NotSupported.Newf("[dml] StructMapper requires a pointer to a struct or to a slice of structs but got %T", ptr)}
+}
+
+// asStructMapper returns a ColumnMapper if the argument of a DBR is a struct,
+// or a pointer to a struct, with at least one db tag.
+func asStructMapper(arg interface{}) (ColumnMapper, bool) {
+	rt := reflect.TypeOf(arg)
+	isPtr := rt.Kind() == reflect.Ptr
+	if isPtr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || structMappedTypes[rt] {
+		return nil, false
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return nil, false
+	}
+	sm := loadStructMapping(rt)
+	if !sm.hasTags {
+		return nil, false
+	}
+	rv := reflect.ValueOf(arg)
+	if isPtr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	} else { // fields must be addressable
+		prv := reflect.New(rt)
+		prv.Elem().Set(rv)
+		rv = prv.Elem()
+	}
+	return structMapper{sm: sm, rv: rv, err: sm.err}, true
+}
+
+type structMapper struct {
+	sm  *structMapping
+	rv  reflect.Value
+	err error
+}
+
+// MapColumns implements interface ColumnMapper.
+func (s structMapper) MapColumns(cm *ColumnMap) error {
+	if s.err != nil {
+		return s.err
+	}
+	if cm.Mode() == ColumnMapEntityReadAll {
+		for _, f := range s.sm.fields {
+			mapStructField(cm, s.rv.FieldByIndex(f.index))
+		}
+		return cm.Err()
+	}
+	for cm.Next() {
+		c := cm.Column()
+		f, ok := s.sm.field(c)
+		if !ok {
+			return errors.NotFound.Newf("[dml] StructMapper: Column %q not found in %s", c, s.rv.Type())
+		}
+		mapStructField(cm, s.rv.FieldByIndex(f.index))
+	}
+	return cm.Err()
+}
+
+type structSliceMapper struct {
+	sm    *structMapping
+	rv    reflect.Value // the slice
+	isPtr bool
+	err   error
+}
+
+func (s structSliceMapper) elem(i int) structMapper {
+	ev := s.rv.Index(i)
+	if s.isPtr {
+		ev = ev.Elem()
+	}
+	return structMapper{sm: s.sm, rv: ev}
+}
+
+// MapColumns implements interface ColumnMapper.
+func (s structSliceMapper) MapColumns(cm *ColumnMap) error {
+	if s.err != nil {
+		return s.err
+	}
+	switch m := cm.Mode(); m {
+	case ColumnMapEntityReadAll, ColumnMapEntityReadSet:
+		for i := 0; i < s.rv.Len(); i++ {
+			if err := s.elem(i).MapColumns(cm); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case ColumnMapScan:
+		if cm.Count == 0 {
+			s.rv.SetLen(0)
+		}
+		et := s.rv.Type().Elem()
+		if s.isPtr {
+			s.rv.Set(reflect.Append(s.rv, reflect.New(et.Elem())))
+		} else {
+			s.rv.Set(reflect.Append(s.rv, reflect.Zero(et)))
+		}
+		if err := s.elem(s.rv.Len() - 1).MapColumns(cm); err != nil {
+			return errors.WithStack(err)
+		}
+	case ColumnMapCollectionReadSet:
+		for cm.Next() {
+			c := cm.Column()
+			f, ok := s.sm.field(c)
+			if !ok {
+				return errors.NotFound.Newf("[dml] StructMapper: Column %q not found in %s", c, s.rv.Type())
+			}
+			vals := reflect.MakeSlice(reflect.SliceOf(f.typ), 0, s.rv.Len())
+			for i := 0; i < s.rv.Len(); i++ {
+				vals = reflect.Append(vals, s.elem(i).rv.FieldByIndex(f.index))
+			}
+			cm.addSlice("StructMapper", vals.Interface())
+		}
+	default:
+		return errors.NotSupported.Newf("[dml] StructMapper: Unknown Mode: %q", string(m))
+	}
+	return cm.Err()
+}
+
+// mapStructField calls the function of ColumnMap for the type of the field.
+// The types are the same as in structMappedTypes.
+func mapStructField(cm *ColumnMap, fv reflect.Value) {
+	switch ptr := fv.Addr().Interface().(type) {
+	case *bool:
+		cm.Bool(ptr)
+	case *string:
+		cm.String(ptr)
+	case *[]byte:
+		cm.Byte(ptr)
+	case *time.Time:
+		cm.Time(ptr)
+	case *float64:
+		cm.Float64(ptr)
+	case *int:
+		cm.Int(ptr)
+	case *int64:
+		cm.Int64(ptr)
+	case *int32:
+		cm.Int32(ptr)
+	case *int16:
+		cm.Int16(ptr)
+	case *int8:
+		cm.Int8(ptr)
+	case *uint:
+		cm.Uint(ptr)
+	case *uint64:
+		cm.Uint64(ptr)
+	case *uint32:
+		cm.Uint32(ptr)
+	case *uint16:
+		cm.Uint16(ptr)
+	case *uint8:
+		cm.Uint8(ptr)
+	case *null.Bool:
+		cm.NullBool(ptr)
+	case *null.String:
+		cm.NullString(ptr)
+	case *null.Time:
+		cm.NullTime(ptr)
+	case *null.Float64:
+		cm.NullFloat64(ptr)
+	case *null.Decimal:
+		cm.Decimal(ptr)
+	case *null.Int64:
+		cm.NullInt64(ptr)
+	case *null.Int32:
+		cm.NullInt32(ptr)
+	case *null.Int16:
+		cm.NullInt16(ptr)
+	case *null.Int8:
+		cm.NullInt8(ptr)
+	case *null.Uint64:
+		cm.NullUint64(ptr)
+	case *null.Uint32:
+		cm.NullUint32(ptr)
+	case *null.Uint16:
+		cm.NullUint16(ptr)
+	case *null.Uint8:
+		cm.NullUint8(ptr)
+	}
+}
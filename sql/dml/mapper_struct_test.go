@@ -0,0 +1,126 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+type structAudit struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt null.Time `db:"updated_at"`
+}
+
+type structCustomer struct {
+	ID    uint64      `db:"entity_id"`
+	Email null.String `db:"email"`
+	Group int16       `db:"group_id"`
+	Notes string      `db:"-"`
+	structAudit
+}
+
+func TestStructMapper(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	created := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+	cols := []string{"entity_id", "email", "group_id", "created_at", "updated_at"}
+
+	t.Run("load entity", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id`, `email`, `group_id`, `created_at`, `updated_at` FROM `customer_entity` WHERE (`entity_id` = ?)")).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(3, "a@b.c", 2, created, nil))
+
+		var c structCustomer
+		rc, err := dbc.SelectFrom("customer_entity").AddColumns(cols...).Where(dml.Column("entity_id").PlaceHolder()).
+			WithDBR().Load(context.TODO(), dml.StructMapper(&c), 3)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(1), rc)
+		assert.Exactly(t, structCustomer{
+			ID:          3,
+			Email:       null.MakeString("a@b.c"),
+			Group:       2,
+			structAudit: structAudit{CreatedAt: created},
+		}, c)
+	})
+
+	t.Run("load slice of pointers", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id`, `email` FROM `customer_entity`")).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id", "email"}).AddRow(3, "a@b.c").AddRow(4, nil))
+
+		cs := []*structCustomer{{ID: 99}}
+		rc, err := dbc.SelectFrom("customer_entity").AddColumns("entity_id", "email").
+			WithDBR().Load(context.TODO(), dml.StructMapper(&cs))
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(2), rc)
+		assert.Len(t, cs, 2)
+		assert.Exactly(t, uint64(3), cs[0].ID)
+		assert.Exactly(t, uint64(4), cs[1].ID)
+		assert.False(t, cs[1].Email.Valid)
+	})
+
+	t.Run("struct as argument", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `customer_entity` SET `email`=?, `group_id`=? WHERE (`entity_id` = ?)")).
+			WithArgs("a@b.c", int64(2), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		c := structCustomer{ID: 3, Email: null.MakeString("a@b.c"), Group: 2}
+		_, err := dbc.Update("customer_entity").AddColumns("email", "group_id").Where(dml.Column("entity_id").PlaceHolder()).
+			WithDBR().ExecContext(context.TODO(), c)
+		assert.NoError(t, err)
+	})
+
+	t.Run("slice as argument", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `customer_entity` WHERE (`entity_id` IN (?,?))")).
+			WithArgs(int64(3), int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		cs := []structCustomer{{ID: 3}, {ID: 4}}
+		_, err := dbc.DeleteFrom("customer_entity").Where(dml.Column("entity_id").In().PlaceHolder()).
+			WithDBR().ExpandPlaceHolders().ExecContext(context.TODO(), dml.StructMapper(&cs))
+		assert.NoError(t, err)
+	})
+
+	t.Run("named placeholders", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `customer_entity` WHERE email = ? AND group_id = ?")).
+			WithArgs("a@b.c", int64(2)).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(3))
+
+		ids, err := dbc.WithRawSQL("SELECT `entity_id` FROM `customer_entity` WHERE email = :email AND group_id = :group_id").
+			LoadUint64s(context.TODO(), nil, &structCustomer{Email: null.MakeString("a@b.c"), Group: 2})
+		assert.NoError(t, err)
+		assert.Exactly(t, []uint64{3}, ids)
+	})
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		var v struct {
+			ID    int64             `db:"id"`
+			Attrs map[string]string `db:"attrs"`
+		}
+		cm := dml.NewColumnMap(1)
+		assert.ErrorIsKind(t, errors.NotSupported, dml.StructMapper(&v).MapColumns(cm))
+		assert.ErrorIsKind(t, errors.NotValid, dml.StructMapper(v).MapColumns(cm))
+		assert.ErrorIsKind(t, errors.NotSupported, dml.StructMapper(&[]int{}).MapColumns(cm))
+	})
+}
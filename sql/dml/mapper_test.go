@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"encoding"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -172,6 +173,11 @@ func TestScannedColumn_Scan(t *testing.T) {
 	assert.NoError(t, sc.Scan(nil))
 	assert.Exactly(t, "<nil>", sc.String())
 
+	assert.NoError(t, sc.Scan(uint64(4711)))
+	assert.Exactly(t, "4711", sc.String())
+	assert.NoError(t, sc.Scan(uint64(math.MaxUint64)))
+	assert.Exactly(t, "18446744073709551615", sc.String())
+
 	err := sc.Scan(uint8(1))
 	assert.ErrorIsKind(t, errors.NotSupported, err)
 }
@@ -0,0 +1,160 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"math"
+	"time"
+
+	"github.com/corestoreio/log"
+)
+
+// DurationBucketInf defines the upper bound of the last bucket which contains
+// all durations greater than the largest bucket of DurationBuckets.
+const DurationBucketInf time.Duration = math.MaxInt64
+
+// durationBuckets contains the exponential upper bounds from 1ms to 16.384s.
+var durationBuckets = [...]time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	16 * time.Millisecond,
+	32 * time.Millisecond,
+	64 * time.Millisecond,
+	128 * time.Millisecond,
+	256 * time.Millisecond,
+	512 * time.Millisecond,
+	1024 * time.Millisecond,
+	2048 * time.Millisecond,
+	4096 * time.Millisecond,
+	8192 * time.Millisecond,
+	16384 * time.Millisecond,
+}
+
+// DurationBuckets returns the upper bounds of the exponential duration buckets,
+// doubling from 1ms to 16.384s. The same bounds should be used to configure
+// the histograms of a Metrics implementation, e.g. with Prometheus.
+func DurationBuckets() []time.Duration {
+	ret := make([]time.Duration, len(durationBuckets))
+	copy(ret, durationBuckets[:])
+	return ret
+}
+
+// DurationBucket returns the upper bound of the bucket in which duration `d`
+// falls. Durations greater than the largest bucket return DurationBucketInf.
+func DurationBucket(d time.Duration) time.Duration {
+	for _, b := range durationBuckets {
+		if d <= b {
+			return b
+		}
+	}
+	return DurationBucketInf
+}
+
+// DurationBucketLabel returns the textual representation of a bucket as
+// returned by DurationBucket, e.g. "4ms", "1.024s" or "+Inf".
+func DurationBucketLabel(bucket time.Duration) string {
+	if bucket == DurationBucketInf {
+		return "+Inf"
+	}
+	return bucket.String()
+}
+
+// QueryObservation describes one executed statement.
+type QueryObservation struct {
+	// ID of the statement as generated by the unique ID function of
+	// WithLogger.
+	ID string
+	// Kind is either "Query" or "Exec".
+	Kind string
+	// Source of the statement like "select", "insert", "update", "delete",
+	// "with", "union", "show" or "raw" for raw SQL queries.
+	Source   string
+	Duration time.Duration
+	// Bucket contains the upper bound of the duration bucket. See
+	// DurationBucket.
+	Bucket time.Duration
+	Err    error
+}
+
+// Metrics receives the durations of all executed statements. The durations get
+// standardized into the exponential DurationBuckets so that dashboards across
+// services can aggregate them without post processing the raw durations. An
+// implementation must be safe for concurrent use.
+type Metrics interface {
+	ObserveQuery(QueryObservation)
+}
+
+// WithMetrics sets the Metrics which receives the durations of all statements
+// created by the ConnPool and its connections and transactions.
+func WithMetrics(m Metrics) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 10,
+		fn: func(c *ConnPool) error {
+			c.metrics = m
+			return nil
+		},
+	}
+}
+
+// sourceName maps the dmlSource constants to a readable name.
+func sourceName(source rune) string {
+	switch source {
+	case dmlSourceSelect:
+		return "select"
+	case dmlSourceInsert, dmlSourceInsertSelect:
+		return "insert"
+	case dmlSourceUpdate:
+		return "update"
+	case dmlSourceDelete:
+		return "delete"
+	case dmlSourceWith:
+		return "with"
+	case dmlSourceUnion:
+		return "union"
+	case dmlSourceShow:
+		return "show"
+	}
+	return "raw"
+}
+
+// isObserved returns true if the duration of a statement must be measured.
+func (bc *builderCommon) isObserved() bool {
+	return bc.metrics != nil || bc.slowQuery != nil || (bc.Log != nil && bc.Log.IsDebug())
+}
+
+// observeDuration writes the duration since `start` together with its bucket
+// into the debug log, reports it to the Metrics and returns it.
+func (bc *builderCommon) observeDuration(kind string, start time.Time, err error, fields ...log.Field) time.Duration {
+	d := log.Now().Sub(start)
+	bucket := DurationBucket(d)
+	if bc.Log != nil && bc.Log.IsDebug() {
+		bc.Log.Debug(kind, append([]log.Field{
+			log.Duration("duration", d), log.String("duration_bucket", DurationBucketLabel(bucket)),
+		}, append(fields, log.Err(err))...)...)
+	}
+	if bc.metrics != nil {
+		bc.metrics.ObserveQuery(QueryObservation{
+			ID:       bc.id,
+			Kind:     kind,
+			Source:   sourceName(bc.source),
+			Duration: d,
+			Bucket:   bucket,
+			Err:      err,
+		})
+	}
+	return d
+}
@@ -0,0 +1,50 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestDurationBucket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d     time.Duration
+		want  time.Duration
+		label string
+	}{
+		{0, time.Millisecond, "1ms"},
+		{time.Millisecond, time.Millisecond, "1ms"},
+		{time.Millisecond + 1, 2 * time.Millisecond, "2ms"},
+		{5 * time.Millisecond, 8 * time.Millisecond, "8ms"},
+		{time.Second, 1024 * time.Millisecond, "1.024s"},
+		{16384 * time.Millisecond, 16384 * time.Millisecond, "16.384s"},
+		{time.Minute, DurationBucketInf, "+Inf"},
+	}
+	for _, test := range tests {
+		b := DurationBucket(test.d)
+		assert.Exactly(t, test.want, b, "Duration %s", test.d)
+		assert.Exactly(t, test.label, DurationBucketLabel(b), "Duration %s", test.d)
+	}
+
+	bs := DurationBuckets()
+	assert.Len(t, bs, 15)
+	bs[0] = 0
+	assert.Exactly(t, time.Millisecond, DurationBuckets()[0], "must return a copy")
+}
@@ -41,6 +41,9 @@ type id struct {
 	// subquery in a SELECT statement FROM clause. Derived tables can return a
 	// scalar, column, row, or table. Ignored in any other case.
 	DerivedTable *Select
+	// DerivedUnion same as DerivedTable but uses a UNION, INTERSECT or EXCEPT
+	// statement as subquery in the FROM clause.
+	DerivedUnion *Union
 	// Name can be any kind of SQL expression or a valid identifier. It gets
 	// quoted when `IsLeftExpression` is false.
 	Name string
@@ -53,6 +56,9 @@ type id struct {
 	// Sort applies only to GROUP BY and ORDER BY clauses. 'd'=descending,
 	// 0=default or nothing; 'a'=ascending.
 	Sort byte
+	// indexHints contains the rendered index hints of a table reference. See
+	// UseIndex, ForceIndex and IgnoreIndex.
+	indexHints string
 }
 
 const (
@@ -67,11 +73,46 @@ func MakeIdentifier(name string) id { return id{Name: name} }
 // Alias sets the aliased name for the `Name` field.
 func (a id) Alias(alias string) id { a.Aliased = alias; return a }
 
-// Clone creates a new object and takes care of a cloned DerivedTable field.
+// UseIndex adds the USE INDEX hint to a table reference, so the server uses
+// only one of the named indexes. Can be called multiple times.
+//		dml.MakeIdentifier("sales_order").Alias("so").UseIndex("IDX_SALES_ORDER_STATUS")
+func (a id) UseIndex(indexes ...string) id { return a.addIndexHint("USE INDEX", indexes) }
+
+// ForceIndex adds the FORCE INDEX hint to a table reference, so the server
+// uses a table scan only if none of the named indexes can be used.
+func (a id) ForceIndex(indexes ...string) id { return a.addIndexHint("FORCE INDEX", indexes) }
+
+// IgnoreIndex adds the IGNORE INDEX hint to a table reference, so the server
+// does not use the named indexes.
+func (a id) IgnoreIndex(indexes ...string) id { return a.addIndexHint("IGNORE INDEX", indexes) }
+
+func (a id) addIndexHint(hint string, indexes []string) id {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	buf.WriteString(a.indexHints)
+	buf.WriteByte(' ')
+	buf.WriteString(hint)
+	buf.WriteString(" (")
+	for i, idx := range indexes {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		Quoter.quote(buf, idx)
+	}
+	buf.WriteByte(')')
+	a.indexHints = buf.String()
+	return a
+}
+
+// Clone creates a new object and takes care of a cloned DerivedTable and
+// DerivedUnion field.
 func (a id) Clone() id {
 	if nil != a.DerivedTable {
 		a.DerivedTable = a.DerivedTable.Clone()
 	}
+	if nil != a.DerivedUnion {
+		a.DerivedUnion = a.DerivedUnion.Clone()
+	}
 	return a
 }
 
@@ -85,7 +126,9 @@ func (a id) Clone() id {
 //	}
 //}
 
-func (a id) isEmpty() bool { return a.Name == "" && a.DerivedTable == nil && a.Expression == "" }
+func (a id) isEmpty() bool {
+	return a.Name == "" && a.DerivedTable == nil && a.DerivedUnion == nil && a.Expression == ""
+}
 
 // qualifier returns the correct qualifier for an identifier
 func (a id) qualifier() string {
@@ -112,10 +155,20 @@ func (a id) String() string {
 func (a id) QuoteAs() string { return Quoter.NameAlias(a.Name, a.Aliased) }
 
 // writeQuoted writes the quoted table and its maybe alias into w.
-func (a id) writeQuoted(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (a id) writeQuoted(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	if a.DerivedTable != nil {
 		w.WriteByte('(')
-		if placeHolders, err = a.DerivedTable.toSQL(w, placeHolders); err != nil {
+		if placeHolders, err = a.DerivedTable.toSQL(w, d, placeHolders); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		w.WriteByte(')')
+		w.WriteString(" AS ")
+		Quoter.quote(w, a.Aliased)
+		return placeHolders, nil
+	}
+	if a.DerivedUnion != nil {
+		w.WriteByte('(')
+		if placeHolders, err = a.DerivedUnion.toSQL(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		w.WriteByte(')')
@@ -125,7 +178,7 @@ func (a id) writeQuoted(w *bytes.Buffer, placeHolders []string) (_ []string, err
 	}
 
 	if a.Expression != "" {
-		writeExpression(w, a.Expression, nil)
+		writeExpression(w, d, a.Expression, nil)
 	} else {
 		Quoter.WriteIdentifier(w, a.Name)
 	}
@@ -133,6 +186,7 @@ func (a id) writeQuoted(w *bytes.Buffer, placeHolders []string) (_ []string, err
 		w.WriteString(" AS ")
 		Quoter.quote(w, a.Aliased)
 	}
+	w.WriteString(a.indexHints)
 
 	if a.Sort == sortAscending {
 		w.WriteString(" ASC")
@@ -158,12 +212,12 @@ func (idc ids) Clone() ids {
 }
 
 // writeQuoted writes all identifiers comma separated and quoted into w.
-func (idc ids) writeQuoted(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (idc ids) writeQuoted(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	for i, a := range idc {
 		if i > 0 {
 			w.WriteString(", ")
 		}
-		if placeHolders, err = a.writeQuoted(w, placeHolders); err != nil {
+		if placeHolders, err = a.writeQuoted(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
@@ -213,6 +267,36 @@ func (idc ids) AppendColumns(isUnsafe bool, columns ...string) ids {
 	return idc
 }
 
+// appendAllowed appends the columns mapped by the untrusted keys. See
+// Select.OrderByAllowed.
+func (idc ids) appendAllowed(funcName string, allowed map[string]string, keys []string) (ids, error) {
+	for _, k := range keys {
+		for _, key := range strings.Split(k, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			var sorting byte
+			switch lk := strings.ToLower(key); {
+			case key[0] == '-':
+				key, sorting = key[1:], sortDescending
+			case key[0] == '+':
+				key, sorting = key[1:], sortAscending
+			case strings.HasSuffix(lk, " desc"), strings.HasSuffix(lk, ":desc"):
+				key, sorting = strings.TrimSpace(key[:len(key)-5]), sortDescending
+			case strings.HasSuffix(lk, " asc"), strings.HasSuffix(lk, ":asc"):
+				key, sorting = strings.TrimSpace(key[:len(key)-4]), sortAscending
+			}
+			column, ok := allowed[key]
+			if !ok || column == "" {
+				return idc, errors.NotAllowed.Newf("[dml] Select.%s: Key %q is not allowed", funcName, key)
+			}
+			idc = append(idc, id{Name: column, Sort: sorting})
+		}
+	}
+	return idc, nil
+}
+
 // AppendColumnsAliases expects a balanced slice where i=column name and
 // i+1=alias name. An imbalanced slice will cause a panic. If a column name is
 // not valid identifier that column gets switched into an expression. The alias
@@ -241,16 +325,26 @@ func (idc ids) AppendColumnsAliases(isUnsafe bool, columns ...string) ids {
 // appendConditions adds an expression with arguments. SubSelects are not yet
 // supported. You should use this function when arguments should be attached to
 // the expression, otherwise use the function AppendColumns*.
-func (idc ids) appendConditions(expressions Conditions) (ids, error) {
+func (idc ids) appendConditions(d mysqlDialect, expressions Conditions) (ids, error) {
 	buf := bufferpool.Get()
 	for _, e := range expressions {
 		idf := id{Name: e.Left, Aliased: e.Aliased}
+		if e.jsonPath != "" || e.stDistanceTo != nil {
+			if e.previousErr != nil {
+				bufferpool.Put(buf)
+				return nil, errors.WithStack(e.previousErr)
+			}
+			e.writeLeft(buf, d)
+			idf.Expression = buf.String()
+			idf.Name = ""
+			buf.Reset()
+		}
 		if e.IsLeftExpression {
 			idf.Expression = idf.Name
 			idf.Name = ""
 
 			if len(e.Right.args) > 0 {
-				if err := writeInterpolate(buf, idf.Expression, e.Right.args); err != nil {
+				if err := writeInterpolate(buf, d, idf.Expression, e.Right.args); err != nil {
 					bufferpool.Put(buf)
 					return nil, errors.Wrapf(err, "[dml] ids.appendConditions with expression: %q", idf.Expression)
 				}
@@ -480,3 +574,19 @@ func cloneStringSlice(sl []string) []string {
 	copy(c, sl)
 	return c
 }
+
+// isValidUserVariable checks if the name is a valid user defined variable like
+// @total. Quoted user variable names are not supported.
+func isValidUserVariable(name string) error {
+	if len(name) < 2 || name[0] != '@' {
+		return errors.NotValid.Newf("[dml] Invalid user variable %q: must start with @", name)
+	}
+	for _, r := range name[1:] {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '$':
+		default:
+			return errors.NotValid.Newf("[dml] Invalid user variable %q: character %q not allowed", name, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// RETURNING capabilities of a server, see WithDetectReturning.
+const (
+	returningDetected uint8 = 1 << iota
+	returningDelete
+	returningUpdate
+)
+
+// WithDetectReturning queries the server version and lets Delete.LoadReturning
+// and Update.LoadReturning choose between the native RETURNING clause and the
+// fallback with two statements in a transaction. MariaDB supports DELETE ...
+// RETURNING since 10.0.5. No released version of MariaDB or MySQL supports
+// UPDATE ... RETURNING, so the UPDATE always uses the fallback. Without this
+// option the native DELETE ... RETURNING gets assumed.
+func WithDetectReturning(ctx context.Context) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			var version string
+			if err := c.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+				return errors.Wrapf(err, "[dml] WithDetectReturning failed to query the version")
+			}
+			c.returning = returningDetected
+			if supportsDeleteReturning(version) {
+				c.returning |= returningDelete
+			}
+			if c.Log != nil && c.Log.IsDebug() {
+				c.Log.Debug("WithDetectReturning", log.String("version", version), log.Bool("delete_returning", c.returning&returningDelete != 0))
+			}
+			return nil
+		},
+	}
+}
+
+// supportsDeleteReturning reports whether a server with the version string as
+// returned by VERSION() supports DELETE ... RETURNING. MariaDB since 10.0.5.
+func supportsDeleteReturning(version string) bool {
+	isMariaDB, v := parseServerVersion(version)
+	return isMariaDB && (v[0] > 10 || (v[0] == 10 && (v[1] > 0 || v[2] >= 5)))
+}
+
+// nativeReturning reports whether the statement kind, returningDelete or
+// returningUpdate, supports RETURNING on the server.
+func (bc *builderCommon) nativeReturning(kind uint8) bool {
+	if bc.returning&returningDetected == 0 {
+		return kind == returningDelete
+	}
+	return bc.returning&kind != 0
+}
+
+// deriveBuilderCommon copies the connection settings of bc with an empty SQL
+// cache and the database connection db.
+func (bc *builderCommon) deriveBuilderCommon(db QueryExecPreparer) builderCommon {
+	return builderCommon{
+		id:                     bc.id,
+		Log:                    bc.Log,
+		db:                     db,
+		connGroups:             bc.connGroups,
+		serverTimeZone:         bc.serverTimeZone,
+		metrics:                bc.metrics,
+		hooks:                  bc.hooks,
+		retry:                  bc.retry,
+		allowList:              bc.allowList,
+		compression:            bc.compression,
+		encryption:             bc.encryption,
+		lockWait:               bc.lockWait,
+		lockWaitSyntax:         bc.lockWaitSyntax,
+		lockWaitDetector:       bc.lockWaitDetector,
+		lockWaitState:          bc.lockWaitState,
+		maxExecTime:            bc.maxExecTime,
+		sqlCommenter:           bc.sqlCommenter,
+		adaptive:               bc.adaptive,
+		stmtCache:              bc.stmtCache,
+		txStmts:                bc.txStmts,
+		slowQuery:              bc.slowQuery,
+		interpolate:            bc.interpolate,
+		returning:              bc.returning,
+		noBackslashEscapes:     bc.noBackslashEscapes,
+		jsonMembershipFallback: bc.jsonMembershipFallback,
+		resultCacheNamespace:   bc.resultCacheNamespace,
+	}
+}
+
+// inTx runs fn in a new transaction. If the statement already runs in a
+// transaction, fn uses it.
+func (bc *builderCommon) inTx(ctx context.Context, fn func(QueryExecPreparer) error) (err error) {
+	type txBeginner interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+	switch db := bc.db.(type) {
+	case *sql.Tx:
+		return errors.WithStack(fn(db))
+	case txBeginner:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := fn(tx); err != nil {
+			if rErr := tx.Rollback(); rErr != nil {
+				return errors.Wrapf(err, "[dml] Rollback failed: %s", rErr)
+			}
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(tx.Commit())
+	}
+	return errors.NotSupported.Newf("[dml] The RETURNING fallback requires a *sql.DB, *sql.Conn or *sql.Tx but got %T", bc.db)
+}
+
+// Returning adds the columns of the deleted rows to return. A column gets
+// quoted if it is a valid identifier, otherwise it gets treated as an
+// expression if IsUnsafe has been set. Use LoadReturning to execute the
+// statement.
+func (b *Delete) Returning(columns ...string) *Delete {
+	b.ReturningColumns = b.ReturningColumns.AppendColumns(b.IsUnsafe, columns...)
+	return b
+}
+
+// LoadReturning deletes the rows and loads the RETURNING columns of the
+// deleted rows into the ColumnMapper. If the server does not support DELETE
+// ... RETURNING, see WithDetectReturning, a transaction runs two statements:
+//		SELECT <returning columns> FROM <table> WHERE <conditions> FOR UPDATE
+//		DELETE FROM <table> WHERE <conditions>
+// If the Delete has been created from a transaction, the transaction gets
+// used. The fallback does not support JOINs.
+func (b *Delete) LoadReturning(ctx context.Context, cm ColumnMapper, args ...interface{}) (rowCount uint64, err error) {
+	if len(b.ReturningColumns) == 0 {
+		return 0, errors.Empty.Newf("[dml] Delete.LoadReturning requires RETURNING columns for table %q", b.Table.Name)
+	}
+	if b.nativeReturning(returningDelete) {
+		rowCount, err = b.WithDBR().Load(ctx, cm, args...)
+		return rowCount, errors.WithStack(err)
+	}
+	if len(b.MultiTables) > 0 || len(b.Joins) > 0 {
+		return 0, errors.NotSupported.Newf("[dml] Delete.LoadReturning fallback does not support JOINs for table %q", b.Table.Name)
+	}
+
+	err = b.inTx(ctx, func(db QueryExecPreparer) error {
+		sel := &Select{
+			BuilderBase: BuilderBase{
+				builderCommon: b.deriveBuilderCommon(db),
+				Table:         b.Table.Clone(),
+				IsUnsafe:      b.IsUnsafe,
+			},
+			BuilderConditional: b.BuilderConditional.Clone(),
+			Columns:            b.ReturningColumns.Clone(),
+			IsForUpdate:        true,
+		}
+		var err error
+		if rowCount, err = sel.WithDBR().Load(ctx, cm, args...); err != nil {
+			return errors.WithStack(err)
+		}
+		del := &Delete{
+			BuilderBase: BuilderBase{
+				builderCommon: b.deriveBuilderCommon(db),
+				Table:         b.Table.Clone(),
+				IsUnsafe:      b.IsUnsafe,
+			},
+			BuilderConditional: b.BuilderConditional.Clone(),
+		}
+		_, err = del.WithDBR().ExecContext(ctx, args...)
+		return errors.WithStack(err)
+	})
+	return rowCount, errors.WithStack(err)
+}
+
+// Returning adds the columns of the updated rows to return. A column gets
+// quoted if it is a valid identifier, otherwise it gets treated as an
+// expression if IsUnsafe has been set. Use LoadReturning to execute the
+// statement.
+func (b *Update) Returning(columns ...string) *Update {
+	b.ReturningColumns = b.ReturningColumns.AppendColumns(b.IsUnsafe, columns...)
+	return b
+}
+
+// LoadReturning updates the rows and loads the RETURNING columns of the
+// updated rows into the ColumnMapper. If the server does not support UPDATE
+// ... RETURNING, see WithDetectReturning, a transaction runs two statements:
+//		UPDATE <table> SET <clauses> WHERE <conditions>
+//		SELECT <returning columns> FROM <table> WHERE <conditions>
+// The SELECT reads the new values, so the updated rows must still match the
+// WHERE conditions: do not update the columns of the WHERE clause. The
+// arguments of the SET clauses get removed from the SELECT. If the Update has
+// been created from a transaction, the transaction gets used. The fallback
+// does not support JOINs and OptimisticLock.
+func (b *Update) LoadReturning(ctx context.Context, cm ColumnMapper, args ...interface{}) (rowCount uint64, err error) {
+	if len(b.ReturningColumns) == 0 {
+		return 0, errors.Empty.Newf("[dml] Update.LoadReturning requires RETURNING columns for table %q", b.Table.Name)
+	}
+	if b.nativeReturning(returningUpdate) {
+		rowCount, err = b.WithDBR().Load(ctx, cm, args...)
+		return rowCount, errors.WithStack(err)
+	}
+	if len(b.Joins) > 0 || b.OptimisticLockColumn != "" {
+		return 0, errors.NotSupported.Newf("[dml] Update.LoadReturning fallback does not support JOINs and OptimisticLock for table %q", b.Table.Name)
+	}
+	whereArgs, err := b.whereArgs(args)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	err = b.inTx(ctx, func(db QueryExecPreparer) error {
+		upd := &Update{
+			BuilderBase: BuilderBase{
+				builderCommon: b.deriveBuilderCommon(db),
+				Table:         b.Table.Clone(),
+				IsUnsafe:      b.IsUnsafe,
+			},
+			BuilderConditional: b.BuilderConditional.Clone(),
+			SetClauses:         b.SetClauses.Clone(),
+		}
+		if _, err := upd.WithDBR().ExecContext(ctx, args...); err != nil {
+			return errors.WithStack(err)
+		}
+		sel := &Select{
+			BuilderBase: BuilderBase{
+				builderCommon: b.deriveBuilderCommon(db),
+				Table:         b.Table.Clone(),
+				IsUnsafe:      b.IsUnsafe,
+			},
+			BuilderConditional: b.BuilderConditional.Clone(),
+			Columns:            b.ReturningColumns.Clone(),
+		}
+		var err error
+		rowCount, err = sel.WithDBR().Load(ctx, cm, whereArgs...)
+		return errors.WithStack(err)
+	})
+	return rowCount, errors.WithStack(err)
+}
+
+// whereArgs removes the primitive arguments of the SET clauses. Records get
+// kept because they provide the arguments by the column names.
+func (b *Update) whereArgs(args []interface{}) ([]interface{}, error) {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+	setPlaceHolders, err := b.setClauses().writeSetClauses(buf, b.sqlDialect(), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	skip := len(setPlaceHolders)
+	ret := make([]interface{}, 0, len(args))
+	for _, a := range args {
+		switch a.(type) {
+		case QualifiedRecord, ColumnMapper:
+		default:
+			if skip > 0 {
+				skip--
+				continue
+			}
+		}
+		ret = append(ret, a)
+	}
+	return ret, nil
+}
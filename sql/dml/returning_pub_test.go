@@ -0,0 +1,167 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestUpdate_Returning(t *testing.T) {
+	t.Parallel()
+
+	upd := dml.NewUpdate("core_config_data").AddClauses(dml.Column("path").PlaceHolder()).
+		Where(dml.Column("config_id").PlaceHolder()).Limit(1).
+		Returning("config_id", "path")
+	compareToSQL(t, upd, errors.NoKind,
+		"UPDATE `core_config_data` SET `path`=? WHERE (`config_id` = ?) LIMIT 1 RETURNING `config_id`, `path`",
+		"",
+	)
+}
+
+func mockDetectReturning(t *testing.T, version string) (*dml.ConnPool, sqlmock.Sqlmock) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	dbMock.ExpectQuery("SELECT VERSION()").
+		WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow(version))
+	dbc, err := dml.NewConnPool(dml.WithDB(db), dml.WithDetectReturning(context.TODO()))
+	assert.NoError(t, err)
+	return dbc, dbMock
+}
+
+func TestDelete_LoadReturning(t *testing.T) {
+	columns := []string{"config_id", "scope_id", "path"}
+
+	t.Run("native", func(t *testing.T) {
+		dbc, dbMock := mockDetectReturning(t, "5.5.5-10.4.12-MariaDB")
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("DELETE FROM `core_config_data` WHERE (`scope_id` = ?) RETURNING `config_id`, `scope_id`, `path`")).
+			WithArgs(4).WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 4, "a/b/c").AddRow(5, 4, "a/b/d"))
+
+		ccd := &TableCoreConfigDataSlice{}
+		rc, err := dbc.DeleteFrom("core_config_data").Where(dml.Column("scope_id").PlaceHolder()).
+			Returning(columns...).LoadReturning(context.TODO(), ccd, 4)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(2), rc)
+		assert.Exactly(t, "&{5  4 a/b/d null}", fmt.Sprintf("%v", ccd.Data[1]))
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		dbc, dbMock := mockDetectReturning(t, "8.0.31")
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `config_id`, `scope_id`, `path` FROM `core_config_data` WHERE (`scope_id` = ?) ORDER BY `config_id` LIMIT 0,2 FOR UPDATE")).
+			WithArgs(4).WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 4, "a/b/c").AddRow(5, 4, "a/b/d"))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `core_config_data` WHERE (`scope_id` = ?) ORDER BY `config_id` LIMIT 2")).
+			WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 2))
+		dbMock.ExpectCommit()
+
+		ccd := &TableCoreConfigDataSlice{}
+		rc, err := dbc.DeleteFrom("core_config_data").Where(dml.Column("scope_id").PlaceHolder()).
+			OrderBy("config_id").Limit(2).
+			Returning(columns...).LoadReturning(context.TODO(), ccd, 4)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(2), rc)
+		assert.Exactly(t, "&{3  4 a/b/c null}", fmt.Sprintf("%v", ccd.Data[0]))
+	})
+
+	t.Run("fallback rollback", func(t *testing.T) {
+		dbc, dbMock := mockDetectReturning(t, "10.0.4-MariaDB")
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `config_id` FROM `core_config_data` WHERE (`scope_id` = ?) FOR UPDATE")).
+			WithArgs(4).WillReturnRows(sqlmock.NewRows(columns[:1]).AddRow(3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `core_config_data` WHERE (`scope_id` = ?)")).
+			WithArgs(4).WillReturnError(errors.AlreadyClosed.Newf("Connection lost"))
+		dbMock.ExpectRollback()
+
+		ccd := &TableCoreConfigDataSlice{}
+		_, err := dbc.DeleteFrom("core_config_data").Where(dml.Column("scope_id").PlaceHolder()).
+			Returning("config_id").LoadReturning(context.TODO(), ccd, 4)
+		assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+	})
+
+	t.Run("no columns", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		_, err := dbc.DeleteFrom("core_config_data").LoadReturning(context.TODO(), &TableCoreConfigDataSlice{})
+		assert.ErrorIsKind(t, errors.Empty, err)
+	})
+}
+
+func TestUpdate_LoadReturning(t *testing.T) {
+	columns := []string{"config_id", "scope_id", "path"}
+
+	t.Run("fallback", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `core_config_data` SET `path`=?, `value`=? WHERE (`scope_id` = ?) AND (`path` LIKE ?)")).
+			WithArgs("a/b/x", "1", 4, "a/b/%").WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `config_id`, `scope_id`, `path` FROM `core_config_data` WHERE (`scope_id` = ?) AND (`path` LIKE ?)")).
+			WithArgs(4, "a/b/%").WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 4, "a/b/x"))
+		dbMock.ExpectCommit()
+
+		ccd := &TableCoreConfigDataSlice{}
+		rc, err := dbc.Update("core_config_data").
+			AddClauses(dml.Column("path").PlaceHolder(), dml.Column("value").PlaceHolder()).
+			Where(dml.Column("scope_id").PlaceHolder(), dml.Column("path").Like().PlaceHolder()).
+			Returning(columns...).LoadReturning(context.TODO(), ccd, "a/b/x", "1", 4, "a/b/%")
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(1), rc)
+		assert.Exactly(t, "&{3  4 a/b/x null}", fmt.Sprintf("%v", ccd.Data[0]))
+	})
+
+	t.Run("fallback in transaction", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectBegin()
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("UPDATE `core_config_data` SET `path`=? WHERE (`config_id` = ?)")).
+			WithArgs("a/b/x", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `config_id` FROM `core_config_data` WHERE (`config_id` = ?)")).
+			WithArgs(3).WillReturnRows(sqlmock.NewRows(columns[:1]).AddRow(3))
+		dbMock.ExpectCommit()
+
+		assert.NoError(t, dbc.Transaction(context.TODO(), nil, func(tx *dml.Tx) error {
+			_, err := tx.Update("core_config_data").AddClauses(dml.Column("path").PlaceHolder()).
+				Where(dml.Column("config_id").PlaceHolder()).
+				Returning("config_id").LoadReturning(context.TODO(), &TableCoreConfigDataSlice{}, "a/b/x", 3)
+			return err
+		}))
+	})
+
+	t.Run("optimistic lock not supported", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		_, err := dbc.Update("core_config_data").AddClauses(dml.Column("path").PlaceHolder()).
+			Where(dml.Column("config_id").PlaceHolder()).OptimisticLock("version").
+			Returning("config_id").LoadReturning(context.TODO(), &TableCoreConfigDataSlice{}, "a/b/x", 3, 1)
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
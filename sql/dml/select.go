@@ -17,10 +17,15 @@ package dml
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/storage/null"
 )
 
 // Select contains the clauses for a SELECT statement. Wildcard `SELECT *`
@@ -48,9 +53,22 @@ type Select struct {
 	IsSQLNoCache         bool // See SQLNoCache()
 	IsForUpdate          bool // See ForUpdate()
 	IsLockInShareMode    bool // See LockInShareMode()
+	IsForShare           bool // See ForShare()
 	IsOrderByDeactivated bool // See OrderByDeactivated()
 	IsOrderByRand        bool // enables the original slow ORDER BY RAND() clause
 	OffsetCount          uint64
+	// LockWaitTimeoutSeconds if greater zero, limits the time a locking read
+	// waits for a row lock. See LockWaitTimeout().
+	LockWaitTimeoutSeconds uint64
+	// CountEstimateExactBelow if greater zero, CountEstimate executes an exact
+	// COUNT(*) query when the estimated row count is lower than this value.
+	CountEstimateExactBelow uint64
+	// IntoVariables contains the names of the user defined variables, including
+	// the @ sign, to which the result gets assigned. See IntoVars().
+	IntoVariables []string
+	// OptimizerHints contains the hints written into the /*+ ... */ comment
+	// after the SELECT keyword. See OptimizerHint().
+	OptimizerHints []string
 }
 
 // NewSelect creates a new Select object.
@@ -81,6 +99,24 @@ func NewSelectWithDerivedTable(subSelect *Select, aliasName string) *Select {
 	}
 }
 
+// NewSelectWithDerivedUnion creates a new derived table using the provided
+// UNION, INTERSECT or EXCEPT statement in the FROM part together with an alias
+// name. Placeholders and arguments of the Union are getting handled like the
+// ones of a sub-select. SQL result may look like:
+//		SELECT `t`.`sku` FROM ((SELECT `sku` FROM `cart_item`)
+//		INTERSECT
+//		(SELECT `sku` FROM `wishlist_item`)) AS `t`
+func NewSelectWithDerivedUnion(u *Union, aliasName string) *Select {
+	return &Select{
+		BuilderBase: BuilderBase{
+			Table: id{
+				DerivedUnion: u,
+				Aliased:      aliasName,
+			},
+		},
+	}
+}
+
 func newSelect(db QueryExecPreparer, cCom *connCommon, from []string) *Select {
 	id := cCom.makeUniqueID()
 	from[0] = cCom.mapTableName(from[0])
@@ -90,14 +126,11 @@ func newSelect(db QueryExecPreparer, cCom *connCommon, from []string) *Select {
 	}
 	s := &Select{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  db,
-			},
-			Table: MakeIdentifier(from[0]),
+			builderCommon: cCom.newBuilderCommon(id, l, db),
+			Table:         MakeIdentifier(from[0]),
 		},
 	}
+	s.replicas = cCom.replicas
 	if len(from) > 1 {
 		s.Table = s.Table.Alias(from[1])
 	}
@@ -113,7 +146,7 @@ func (c *ConnPool) SelectFrom(fromAlias ...string) *Select {
 // SelectFrom creates a new Select in a dedicated connection. Mapping of the
 // table name is supported.
 func (c *Conn) SelectFrom(fromAlias ...string) *Select {
-	return newSelect(c.DB, &c.connCommon, fromAlias)
+	return newSelect(c.qep(), &c.connCommon, fromAlias)
 }
 
 // SelectFrom creates a new Select that select that given columns bound to the
@@ -128,6 +161,20 @@ func (b *Select) WithDB(db QueryExecPreparer) *Select {
 	return b
 }
 
+// UseGroup routes the query to the named connection group, e.g. "reporting",
+// instead of the serving pool. Only supported for a Select created via
+// ConnPool.SelectFrom. An unknown group name returns a NotFound error when
+// building the query.
+func (b *Select) UseGroup(name string) *Select {
+	db, ok := b.connGroups[name]
+	if !ok {
+		b.ärgErr = errors.NotFound.Newf("[dml] Select.UseGroup connection group %q not found", name)
+		return b
+	}
+	b.db = db
+	return b
+}
+
 // Distinct marks the statement at a DISTINCT SELECT. It specifies removal of
 // duplicate rows from the result set.
 func (b *Select) Distinct() *Select {
@@ -158,6 +205,40 @@ func (b *Select) SQLNoCache() *Select {
 	return b
 }
 
+// UseIndex adds the USE INDEX hint to the table in the FROM clause. Tables of
+// a JOIN clause support the hints via their identifier, e.g.
+// MakeIdentifier("sales_order_item").UseIndex("IDX_ORDER_ID").
+//		SELECT `entity_id` FROM `sales_order` USE INDEX (`IDX_STATUS`) WHERE ...
+func (b *Select) UseIndex(indexes ...string) *Select {
+	b.Table = b.Table.UseIndex(indexes...)
+	return b
+}
+
+// ForceIndex adds the FORCE INDEX hint to the table in the FROM clause. See
+// UseIndex.
+func (b *Select) ForceIndex(indexes ...string) *Select {
+	b.Table = b.Table.ForceIndex(indexes...)
+	return b
+}
+
+// IgnoreIndex adds the IGNORE INDEX hint to the table in the FROM clause. See
+// UseIndex.
+func (b *Select) IgnoreIndex(indexes ...string) *Select {
+	b.Table = b.Table.IgnoreIndex(indexes...)
+	return b
+}
+
+// OptimizerHint adds optimizer hints, e.g. "BKA(t1)" or "NO_RANGE_OPTIMIZATION(t3
+// PRIMARY)", which get written into the hint comment after the SELECT keyword.
+// The hints are not validated, an invalid hint gets ignored by the server with
+// a warning.
+//		SELECT /*+ BKA(t1) NO_BKA(t2) */ ...
+// https://dev.mysql.com/doc/refman/8.0/en/optimizer-hints.html
+func (b *Select) OptimizerHint(hints ...string) *Select {
+	b.OptimizerHints = append(b.OptimizerHints, hints...)
+	return b
+}
+
 // ForUpdate sets for index records the search encounters, locks the rows and
 // any associated index entries, the same as if you issued an UPDATE statement
 // for those rows. Other transactions are blocked from updating those rows, from
@@ -187,6 +268,54 @@ func (b *Select) LockInShareMode() *Select {
 	return b
 }
 
+// ForShare sets a shared mode lock on any rows that are read, like
+// LockInShareMode, but uses the syntax of MySQL 8. LOCK IN SHARE MODE is still
+// supported by MySQL 8 for backward compatibility.
+// https://dev.mysql.com/doc/refman/8.0/en/innodb-locking-reads.html
+func (b *Select) ForShare() *Select {
+	b.IsForShare = true
+	return b
+}
+
+// LockWaitTimeout limits the time the statement waits for a row lock, e.g. in
+// combination with ForUpdate or ForShare. The duration gets rounded up to full
+// seconds. The timeout gets applied when the statement gets executed. The
+// syntax of the server gets detected once, or taken from the option
+// WithDeadlineLockWaitTimeout. MariaDB sets the variable for the duration of
+// the statement:
+//		SET STATEMENT innodb_lock_wait_timeout=5 FOR SELECT ... FOR UPDATE
+// MySQL sets the session variable. A Tx or a Conn restores it with the next
+// statement, on Commit, Rollback and Close. A ConnPool runs the statement on a
+// dedicated connection, which gets restored after an Exec and discarded after
+// the rows of a query have been closed.
+//		SET SESSION innodb_lock_wait_timeout=5
+// Sub-selects and the parts of a Union do not apply their own timeout.
+// Prepared statements return an error of kind NotSupported, the statement
+// cache and the adaptive preparation get bypassed.
+func (b *Select) LockWaitTimeout(d time.Duration) *Select {
+	b.LockWaitTimeoutSeconds = 0
+	if d > 0 {
+		b.LockWaitTimeoutSeconds = uint64((d + time.Second - 1) / time.Second)
+	}
+	return b
+}
+
+// IntoVars assigns the columns of the result row to user defined session
+// variables. Each variable name must start with an @ sign. The query must
+// return at most one row. The variables can be read afterwards with
+// Conn.SessionVars or Tx.SessionVars but only on the same connection.
+//		SELECT COUNT(*), MAX(`entity_id`) FROM `sales_order` INTO @total, @max_id
+func (b *Select) IntoVars(vars ...string) *Select {
+	for _, v := range vars {
+		if err := isValidUserVariable(v); err != nil {
+			b.ärgErr = errors.WithStack(err)
+			return b
+		}
+	}
+	b.IntoVariables = append(b.IntoVariables, vars...)
+	return b
+}
+
 // Count executes a COUNT(*) as `counted` query without touching or changing the
 // currently set columns.
 func (b *Select) Count() *Select {
@@ -194,6 +323,132 @@ func (b *Select) Count() *Select {
 	return b
 }
 
+// sqlStrCountEstimateTable retrieves the table statistics. TABLE_ROWS is only
+// exact for the MyISAM, MEMORY and ARCHIVE storage engines.
+const sqlStrCountEstimateTable = "SELECT `TABLE_ROWS`, `ENGINE` FROM `information_schema`.`TABLES` WHERE `TABLE_SCHEMA` = COALESCE(?, DATABASE()) AND `TABLE_NAME` = ?"
+
+// CountEstimate returns a fast approximate row count of the current query
+// without executing an expensive COUNT(*). Useful for pagination on very large
+// tables. A query without any WHERE, JOIN, GROUP BY or HAVING clause reads the
+// statistics from information_schema.TABLES, all other queries use the
+// estimated rows of the EXPLAIN output. ORDER BY and LIMIT clauses get ignored.
+// The return value isExact reports whether the row count is exact, which
+// happens for some storage engines or if the estimate is lower than field
+// CountEstimateExactBelow. Argument args gets passed to the underlying DBR.
+func (b *Select) CountEstimate(ctx context.Context, args ...interface{}) (rowCount uint64, isExact bool, err error) {
+	if b.Log != nil && b.Log.IsDebug() {
+		defer log.WhenDone(b.Log).Debug("CountEstimate", log.String("id", b.id), log.Uint64("row_count", rowCount), log.Bool("is_exact", isExact), log.Err(err))
+	}
+
+	c := b.Clone()
+	c.OrderBys = nil
+	c.LimitValid = false
+	c.LimitCount = 0
+	c.OffsetCount = 0
+	c.IsOrderByRand = false
+	c.cachedSQL = nil
+
+	if c.Table.DerivedTable == nil && c.Table.DerivedUnion == nil && c.Table.Expression == "" && len(c.Wheres) == 0 && len(c.Joins) == 0 && len(c.GroupBys) == 0 && len(c.Havings) == 0 && !c.IsDistinct {
+		rowCount, isExact, err = c.countEstimateTable(ctx)
+	} else {
+		rowCount, err = c.countEstimateExplain(ctx, args)
+	}
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	if isExact || rowCount >= b.CountEstimateExactBelow {
+		return rowCount, isExact, nil
+	}
+
+	cc := c.Clone()
+	cc.cachedSQL = nil // c has cached the EXPLAIN query
+	nv, _, err := cc.Count().WithDBR().LoadNullUint64(ctx, args...)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	return nv.Uint64, true, nil
+}
+
+func (b *Select) countEstimateTable(ctx context.Context) (rowCount uint64, isExact bool, err error) {
+	var schema null.String
+	tableName := b.Table.Name
+	if pos := strings.IndexByte(tableName, '.'); pos > 0 {
+		schema = null.MakeString(tableName[:pos])
+		tableName = tableName[pos+1:]
+	}
+
+	var rows null.Uint64
+	var engine null.String
+	if err = b.db.QueryRowContext(ctx, sqlStrCountEstimateTable, schema, tableName).Scan(&rows, &engine); err != nil {
+		return 0, false, errors.Wrapf(err, "[dml] Select.CountEstimate for table %q", b.Table.Name)
+	}
+	switch strings.ToUpper(engine.Data) {
+	case "MYISAM", "MEMORY", "ARCHIVE":
+		isExact = true
+	}
+	return rows.Uint64, isExact, nil
+}
+
+// countEstimateExplain uses the `rows` and `filtered` columns of the first row
+// of the EXPLAIN output, which represents the driving table of the query.
+func (b *Select) countEstimateExplain(ctx context.Context, args []interface{}) (rowCount uint64, err error) {
+	sqlStr, qArgs, err := b.WithDBR().prepareQueryAndArgs(args)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	rows, err := b.db.QueryContext(ctx, "EXPLAIN "+sqlStr, qArgs...)
+	if err != nil {
+		return 0, errors.Wrapf(err, "[dml] Select.CountEstimate with query %q", sqlStr)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	rowsIdx, filteredIdx := -1, -1
+	for i, c := range cols {
+		switch strings.ToLower(c) {
+		case "rows":
+			rowsIdx = i
+		case "filtered":
+			filteredIdx = i
+		}
+	}
+	if rowsIdx < 0 {
+		return 0, errors.NotFound.Newf("[dml] Select.CountEstimate EXPLAIN output does not contain a rows column: %v", cols)
+	}
+
+	if rows.Next() {
+		vals := make([]sql.RawBytes, len(cols))
+		dest := make([]interface{}, len(cols))
+		for i := range vals {
+			dest[i] = &vals[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if len(vals[rowsIdx]) > 0 {
+			if rowCount, err = strconv.ParseUint(string(vals[rowsIdx]), 10, 64); err != nil {
+				return 0, errors.BadEncoding.New(err, "[dml] Select.CountEstimate failed to parse rows column")
+			}
+		}
+		if filteredIdx >= 0 && len(vals[filteredIdx]) > 0 {
+			if f, errF := strconv.ParseFloat(string(vals[filteredIdx]), 64); errF == nil && f > 0 && f < 100 {
+				rowCount = uint64(float64(rowCount) * f / 100)
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return rowCount, nil
+}
+
 // Star creates a SELECT * FROM query. Such queries are discouraged from using.
 func (b *Select) Star() *Select {
 	b.IsStar = true
@@ -239,7 +494,7 @@ func (b *Select) AddColumnsAliases(columnAliases ...string) *Select {
 // RawArguments field to maintain the correct order of arguments.
 // 		AddColumnsConditions(Expr("(e.price*x.tax*t.weee)").Alias("final_price")) // (e.price*x.tax*t.weee) AS `final_price`
 func (b *Select) AddColumnsConditions(expressions ...*Condition) *Select {
-	b.Columns, b.ärgErr = b.Columns.appendConditions(expressions)
+	b.Columns, b.ärgErr = b.Columns.appendConditions(b.sqlDialect(), expressions)
 	return b
 }
 
@@ -338,6 +593,35 @@ func (b *Select) OrderByDesc(columns ...string) *Select {
 	return b
 }
 
+// OrderByAllowed appends columns to the ORDER BY statement from untrusted
+// sort keys, e.g. the query parameters of an HTTP request. Argument `allowed`
+// maps the public sort keys to the real column names. A sort key can contain
+// several comma separated keys. Each key can be prefixed with "-" for
+// descending or "+" for ascending sorting, or can have the suffix " DESC",
+// " ASC", ":desc" or ":asc" in any case. A key not found in the map sets an
+// error of kind NotAllowed which gets returned when building the SQL string.
+// User input never gets written into the SQL string.
+//		allowed := map[string]string{"name": "e.name", "created": "e.created_at"}
+//		s.OrderByAllowed(allowed, r.URL.Query().Get("sort")) // sort=-created,name
+//		// ORDER BY `e`.`created_at` DESC, `e`.`name`
+func (b *Select) OrderByAllowed(allowed map[string]string, sortKeys ...string) *Select {
+	if b.ärgErr != nil {
+		return b
+	}
+	b.OrderBys, b.ärgErr = b.OrderBys.appendAllowed("OrderByAllowed", allowed, sortKeys)
+	return b
+}
+
+// GroupByAllowed appends columns to the GROUP BY statement from untrusted keys.
+// See OrderByAllowed for the syntax of the keys.
+func (b *Select) GroupByAllowed(allowed map[string]string, groupKeys ...string) *Select {
+	if b.ärgErr != nil {
+		return b
+	}
+	b.GroupBys, b.ärgErr = b.GroupBys.appendAllowed("GroupByAllowed", allowed, groupKeys)
+	return b
+}
+
 // OrderByRandom sorts the table randomly by not using ORDER BY RAND() rather
 // using a JOIN with the single primary key column. This function overwrites
 // previously set ORDER BY statements and the field LimitCount. The generated
@@ -415,6 +699,10 @@ func (b *Select) CrossJoin(table id, onConditions ...*Condition) *Select {
 func (b *Select) WithDBR() *DBR {
 	b.isWithDBR = true
 	dbr := b.newDBR(b)
+	if b.IsForUpdate || b.IsLockInShareMode || b.IsForShare {
+		dbr.base.replicas = nil // locking reads must run on the primary
+	}
+	dbr.base.stmtLockWait = time.Duration(b.LockWaitTimeoutSeconds) * time.Second
 	return dbr
 }
 
@@ -438,7 +726,7 @@ func (b *Select) WithCacheKey(key string, args ...interface{}) *Select {
 
 // ToSQL serialized the Select to a SQL string
 // It returns the string with placeholders and a slice of query arguments
-func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []string, err error) {
+func (b *Select) toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_placeHolders []string, err error) {
 	b.source = dmlSourceSelect
 	b.defaultQualifier = b.Table.qualifier()
 
@@ -446,7 +734,21 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 		return nil, errors.Empty.Newf("[dml] Select: no columns specified")
 	}
 
+	if placeHolders, err = b.writeTxCTEs(w, d, placeHolders); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	w.WriteString("SELECT ")
+	if len(b.OptimizerHints) > 0 {
+		w.WriteString("/*+ ")
+		for _, h := range b.OptimizerHints {
+			if strings.Contains(h, "*/") {
+				return nil, errors.NotValid.Newf("[dml] Select: optimizer hint %q cannot contain the comment end", h)
+			}
+			w.WriteString(h)
+			w.WriteByte(' ')
+		}
+		w.WriteString("*/ ")
+	}
 	writeStmtID(w, b.id)
 	if b.IsDistinct {
 		w.WriteString("DISTINCT ")
@@ -465,14 +767,14 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 		w.WriteString("COUNT(*) AS ")
 		Quoter.quote(w, "counted")
 	default:
-		if placeHolders, err = b.Columns.writeQuoted(w, placeHolders); err != nil {
+		if placeHolders, err = b.Columns.writeQuoted(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 
 	if !b.Table.isEmpty() {
 		w.WriteString(" FROM ")
-		if placeHolders, err = b.Table.writeQuoted(w, placeHolders); err != nil {
+		if placeHolders, err = b.Table.writeQuoted(w, d, placeHolders); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
@@ -499,19 +801,11 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 		})
 	}
 
-	for _, f := range joins {
-		w.WriteByte(' ')
-		w.WriteString(f.JoinType)
-		w.WriteString(" JOIN ")
-		if placeHolders, err = f.Table.writeQuoted(w, placeHolders); err != nil {
-			return nil, errors.WithStack(err)
-		}
-		if placeHolders, err = f.On.write(w, 'j', placeHolders, b.isWithDBR); err != nil {
-			return nil, errors.WithStack(err)
-		}
+	if placeHolders, err = joins.write(w, d, placeHolders, b.isWithDBR); err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	if placeHolders, err = b.Wheres.write(w, 'w', placeHolders, b.isWithDBR); err != nil {
+	if placeHolders, err = b.Wheres.write(w, d, 'w', placeHolders, b.isWithDBR); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -521,13 +815,13 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 			if i > 0 {
 				w.WriteString(", ")
 			}
-			if placeHolders, err = c.writeQuoted(w, placeHolders); err != nil {
+			if placeHolders, err = c.writeQuoted(w, d, placeHolders); err != nil {
 				return nil, errors.WithStack(err)
 			}
 		}
 	}
 
-	if placeHolders, err = b.Havings.write(w, 'h', placeHolders, b.isWithDBR); err != nil {
+	if placeHolders, err = b.Havings.write(w, d, 'h', placeHolders, b.isWithDBR); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -537,7 +831,7 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 	case b.IsOrderByRand:
 		w.WriteString(" ORDER BY RAND()")
 	default:
-		sqlWriteOrderBy(w, b.OrderBys, false)
+		sqlWriteOrderBy(w, d, b.OrderBys, false)
 	}
 
 	sqlWriteLimitOffset(w, b.LimitValid, true, b.OffsetCount, b.LimitCount)
@@ -545,9 +839,17 @@ func (b *Select) toSQL(w *bytes.Buffer, placeHolders []string) (_placeHolders []
 	switch {
 	case b.IsLockInShareMode:
 		w.WriteString(" LOCK IN SHARE MODE")
+	case b.IsForShare:
+		w.WriteString(" FOR SHARE")
 	case b.IsForUpdate:
 		w.WriteString(" FOR UPDATE")
 	}
+
+	// MySQL 8.0.20 deprecates INTO before the locking clause.
+	if len(b.IntoVariables) > 0 {
+		w.WriteString(" INTO ")
+		w.WriteString(strings.Join(b.IntoVariables, ", "))
+	}
 	return placeHolders, err
 }
 
@@ -589,5 +891,7 @@ func (b *Select) Clone() *Select {
 	c.Columns = b.Columns.Clone()
 	c.GroupBys = b.GroupBys.Clone()
 	c.Havings = b.Havings.Clone()
+	c.IntoVariables = cloneStringSlice(b.IntoVariables)
+	c.OptimizerHints = cloneStringSlice(b.OptimizerHints)
 	return &c
 }
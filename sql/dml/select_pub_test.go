@@ -283,6 +283,24 @@ func TestSelect_Load(t *testing.T) {
 		assert.Exactly(t, []int64{2, 3, 4, 16, 17}, dst)
 	})
 
+	t.Run("LoadNull* keeps NULL rows", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `scope_id` FROM `core_config_data`")).
+			WillReturnRows(sqlmock.NewRows([]string{"scope_id"}).AddRow(11).AddRow(nil).AddRow(33))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `value` FROM `core_config_data`")).
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(nil).AddRow("en_US").AddRow(nil))
+
+		ids, err := dbc.SelectFrom("core_config_data").AddColumns("scope_id").WithDBR().LoadNullInt64s(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, []null.Int64{null.MakeInt64(11), {}, null.MakeInt64(33)}, ids)
+
+		vals, err := dbc.SelectFrom("core_config_data").AddColumns("value").WithDBR().LoadNullStrings(context.TODO(), nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, []null.String{{}, null.MakeString("en_US"), {}}, vals)
+	})
+
 	t.Run("row error", func(t *testing.T) {
 		dbc, dbMock := dmltest.MockDB(t)
 		defer dmltest.MockClose(t, dbc, dbMock)
@@ -888,3 +906,85 @@ func TestDBR_ExpandTuples(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestSelect_CountEstimate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.TODO()
+
+	t.Run("table statistics InnoDB", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `TABLE_ROWS`, `ENGINE` FROM `information_schema`.`TABLES` WHERE `TABLE_SCHEMA` = COALESCE(?, DATABASE()) AND `TABLE_NAME` = ?")).
+			WithArgs(nil, "sales_order").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS", "ENGINE"}).AddRow(123456, "InnoDB"))
+
+		rowCount, isExact, err := dbc.SelectFrom("sales_order").Star().OrderBy("entity_id").Limit(0, 20).CountEstimate(ctx)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(123456), rowCount)
+		assert.False(t, isExact)
+	})
+
+	t.Run("table statistics MyISAM with schema", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `TABLE_ROWS`, `ENGINE` FROM `information_schema`.`TABLES`")).
+			WithArgs("archive", "sales_order").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_ROWS", "ENGINE"}).AddRow(42, "MyISAM"))
+
+		rowCount, isExact, err := dbc.SelectFrom("archive.sales_order").Star().CountEstimate(ctx)
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(42), rowCount)
+		assert.True(t, isExact)
+	})
+
+	t.Run("EXPLAIN with filtered", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN SELECT `entity_id` FROM `sales_order` WHERE (`state` = ?)")).
+			WithArgs("new").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "select_type", "table", "rows", "filtered", "Extra"}).
+				AddRow(1, "SIMPLE", "sales_order", 80000, "25.00", "Using where"))
+
+		rowCount, isExact, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").
+			Where(dml.Column("state").PlaceHolder()).Limit(0, 10).CountEstimate(ctx, "new")
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(20000), rowCount)
+		assert.False(t, isExact)
+	})
+
+	t.Run("EXPLAIN below threshold runs exact COUNT", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("EXPLAIN SELECT `entity_id` FROM `sales_order` WHERE (`state` = ?)")).
+			WithArgs("new").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "rows"}).AddRow(1, 800))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT COUNT(*) AS `counted` FROM `sales_order` WHERE (`state` = ?)")).
+			WithArgs("new").
+			WillReturnRows(sqlmock.NewRows([]string{"counted"}).AddRow(777))
+
+		sel := dbc.SelectFrom("sales_order").AddColumns("entity_id").Where(dml.Column("state").PlaceHolder())
+		sel.CountEstimateExactBelow = 1000
+		rowCount, isExact, err := sel.CountEstimate(ctx, "new")
+		assert.NoError(t, err)
+		assert.Exactly(t, uint64(777), rowCount)
+		assert.True(t, isExact)
+	})
+
+	t.Run("EXPLAIN query error", func(t *testing.T) {
+		dbc, dbMock := dmltest.MockDB(t)
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery("EXPLAIN SELECT").WillReturnError(errors.ConnectionFailed.Newf("Con failed"))
+
+		rowCount, isExact, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").
+			Where(dml.Column("state").Str("new")).CountEstimate(ctx)
+		assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+		assert.Exactly(t, uint64(0), rowCount)
+		assert.False(t, isExact)
+	})
+}
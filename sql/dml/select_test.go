@@ -19,6 +19,7 @@ import (
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/storage/null"
@@ -347,6 +348,44 @@ func TestSelect_OrderByDeactivated(t *testing.T) {
 	)
 }
 
+func TestSelect_OrderByAllowed(t *testing.T) {
+	t.Parallel()
+	allowed := map[string]string{
+		"name":    "e.name",
+		"created": "e.created_at",
+		"price":   "price",
+	}
+
+	t.Run("directions", func(t *testing.T) {
+		compareToSQL2(t,
+			NewSelect("a").From("c").OrderByAllowed(allowed, "-created,name", " price:DESC ", "name asc", "+price", ""),
+			errors.NoKind,
+			"SELECT `a` FROM `c` ORDER BY `e`.`created_at` DESC, `e`.`name`, `price` DESC, `e`.`name` ASC, `price` ASC",
+		)
+	})
+	t.Run("group by", func(t *testing.T) {
+		compareToSQL2(t,
+			NewSelect("a").From("c").GroupByAllowed(allowed, "name", "price"),
+			errors.NoKind,
+			"SELECT `a` FROM `c` GROUP BY `e`.`name`, `price`",
+		)
+	})
+	t.Run("not allowed", func(t *testing.T) {
+		compareToSQL2(t,
+			NewSelect("a").From("c").OrderByAllowed(allowed, "name", "(SELECT 1) desc"),
+			errors.NotAllowed,
+			"",
+		)
+	})
+	t.Run("first error wins", func(t *testing.T) {
+		compareToSQL2(t,
+			NewSelect("a").From("c").OrderByAllowed(allowed, "id").OrderByAllowed(allowed, "name"),
+			errors.NotAllowed,
+			"",
+		)
+	})
+}
+
 func TestSelect_ConditionColumn(t *testing.T) {
 	t.Parallel()
 	// TODO rewrite test to use every type which implements interface Argument and every operator
@@ -721,9 +760,7 @@ func TestSelect_WithArgs_LoadUint64(t *testing.T) {
 	})
 	defer testCloser(t, s)
 
-	// Despite it seems that Go can support large uint64 values ... the down side is that
-	// the byte encoded uint64 gets transferred as a string and MySQL/MariaDB must convert that
-	// string into a bigint.
+	// Large uint64 values get transferred as unsigned BIGINT, see uint64Arg.
 	const bigID uint64 = 18446744073700551613 // see also file dml_test.go MaxUint64
 
 	sel := s.SelectFrom("dml_people").AddColumns("id").Where(Column("id").Uint64(bigID))
@@ -902,6 +939,31 @@ func TestSelect_Locks(t *testing.T) {
 			"SELECT `p1`.*, `p2`.`name` AS `p2Name`, `p2`.`email` AS `p2Email` FROM `dml_people` AS `p1` FOR UPDATE",
 		)
 	})
+	t.Run("FOR SHARE", func(t *testing.T) {
+		s := NewSelect("p1.*").
+			FromAlias("dml_people", "p1").ForShare()
+		compareToSQL2(t, s, errors.NoKind,
+			"SELECT `p1`.* FROM `dml_people` AS `p1` FOR SHARE",
+		)
+	})
+	t.Run("LockWaitTimeout FOR UPDATE", func(t *testing.T) {
+		s := NewSelect("p1.*").
+			FromAlias("dml_people", "p1").Where(Column("id").Int(3)).
+			ForUpdate().LockWaitTimeout(1500 * time.Millisecond)
+		compareToSQL2(t, s, errors.NoKind,
+			"SELECT `p1`.* FROM `dml_people` AS `p1` WHERE (`id` = 3) FOR UPDATE",
+		)
+		assert.Exactly(t, uint64(2), s.LockWaitTimeoutSeconds)
+	})
+	t.Run("LockWaitTimeout reset", func(t *testing.T) {
+		s := NewSelect("p1.*").
+			FromAlias("dml_people", "p1").ForShare().
+			LockWaitTimeout(time.Second).LockWaitTimeout(0)
+		compareToSQL2(t, s, errors.NoKind,
+			"SELECT `p1`.* FROM `dml_people` AS `p1` FOR SHARE",
+		)
+		assert.Exactly(t, uint64(0), s.LockWaitTimeoutSeconds)
+	})
 }
 
 func TestSelect_Columns(t *testing.T) {
@@ -1515,3 +1577,54 @@ func TestSelect_DBR_Load_Functions(t *testing.T) {
 		assert.Exactly(t, []string(nil), vals)
 	})
 }
+
+func TestSelect_IntoVars(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		sel := NewSelect().AddColumnsConditions(Expr("COUNT(*)"), Expr("MAX(`entity_id`)")).
+			From("sales_order").Where(Column("state").Str("new")).
+			IntoVars("@total", "@max_id").ForUpdate()
+		compareToSQL2(t, sel, errors.NoKind,
+			"SELECT COUNT(*), MAX(`entity_id`) FROM `sales_order` WHERE (`state` = 'new') FOR UPDATE INTO @total, @max_id",
+		)
+	})
+	t.Run("invalid variable", func(t *testing.T) {
+		sel := NewSelect("entity_id").From("sales_order").IntoVars("total")
+		compareToSQL2(t, sel, errors.NotValid, "")
+	})
+	t.Run("invalid character", func(t *testing.T) {
+		sel := NewSelect("entity_id").From("sales_order").IntoVars("@a;DROP")
+		compareToSQL2(t, sel, errors.NotValid, "")
+	})
+}
+
+func TestSelect_Hints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("index hints", func(t *testing.T) {
+		sel := NewSelect("so.entity_id").FromAlias("sales_order", "so").
+			UseIndex("IDX_STATUS", "IDX_STATE").IgnoreIndex("IDX_CREATED_AT").
+			Join(MakeIdentifier("sales_order_item").Alias("soi").ForceIndex("IDX_ORDER_ID"),
+				Column("so.entity_id").Equal().Column("soi.order_id"),
+			).
+			Where(Column("so.status").Str("pending"))
+		compareToSQL2(t, sel, errors.NoKind,
+			"SELECT `so`.`entity_id` FROM `sales_order` AS `so` USE INDEX (`IDX_STATUS`, `IDX_STATE`) IGNORE INDEX (`IDX_CREATED_AT`) INNER JOIN `sales_order_item` AS `soi` FORCE INDEX (`IDX_ORDER_ID`) ON (`so`.`entity_id` = `soi`.`order_id`) WHERE (`so`.`status` = 'pending')",
+		)
+	})
+	t.Run("optimizer hints", func(t *testing.T) {
+		sel := NewSelect("entity_id").From("sales_order").
+			OptimizerHint("NO_RANGE_OPTIMIZATION(sales_order PRIMARY)").OptimizerHint("MAX_EXECUTION_TIME(1000)").Distinct()
+		compareToSQL2(t, sel, errors.NoKind,
+			"SELECT /*+ NO_RANGE_OPTIMIZATION(sales_order PRIMARY) MAX_EXECUTION_TIME(1000) */ DISTINCT `entity_id` FROM `sales_order`",
+		)
+		sel2 := sel.Clone().OptimizerHint("BKA(sales_order)")
+		assert.Exactly(t, []string{"NO_RANGE_OPTIMIZATION(sales_order PRIMARY)", "MAX_EXECUTION_TIME(1000)"}, sel.OptimizerHints)
+		assert.Len(t, sel2.OptimizerHints, 3)
+	})
+	t.Run("invalid optimizer hint", func(t *testing.T) {
+		sel := NewSelect("entity_id").From("sales_order").OptimizerHint("BKA(t1) */ DROP")
+		compareToSQL2(t, sel, errors.NotValid, "")
+	})
+}
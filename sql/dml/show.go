@@ -55,11 +55,7 @@ func (c *ConnPool) Show() *Show {
 	}
 	return &Show{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, l, c.DB),
 		},
 	}
 }
@@ -73,11 +69,7 @@ func (c *Conn) Show() *Show {
 	}
 	return &Show{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, l, c.qep()),
 		},
 	}
 }
@@ -91,11 +83,7 @@ func (tx *Tx) Show() *Show {
 	}
 	return &Show{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  tx.DB,
-			},
+			builderCommon: tx.newBuilderCommon(id, l, tx.DB),
 		},
 	}
 }
@@ -211,7 +199,7 @@ func (b *Show) WithCacheKey(key string, args ...interface{}) *Show {
 
 // ToSQL serialized the Show to a SQL string
 // It returns the string with placeholders and a slice of query arguments
-func (b *Show) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (b *Show) toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	b.source = dmlSourceShow
 	w.WriteString("SHOW ")
 
@@ -236,10 +224,10 @@ func (b *Show) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err er
 	}
 
 	if b.LikeCondition {
-		Like.write(w)
+		Like.write(w, d)
 		w.WriteByte(placeHolderRune)
 	} else {
-		placeHolders, err = b.WhereFragments.write(w, 'w', placeHolders, false)
+		placeHolders, err = b.WhereFragments.write(w, d, 'w', placeHolders, false)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -0,0 +1,266 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/binary"
+	"math"
+
+	"github.com/corestoreio/errors"
+)
+
+// SRIDWGS84 defines the spatial reference system of GPS coordinates.
+const SRIDWGS84 = 4326
+
+// Those constants define the WKB geometry types supported by the Point and
+// Polygon functions.
+const (
+	wkbPoint   = 1
+	wkbPolygon = 3
+)
+
+// Point defines a coordinate in a spatial reference system. For geographic
+// systems, like SRIDWGS84, X contains the longitude and Y the latitude. MySQL
+// stores geographic coordinates internally always in this order, regardless of
+// the axis order of the system.
+type Point struct {
+	SRID uint32
+	X, Y float64
+}
+
+// Geometry transforms the point into a geometry value.
+func (p Point) Geometry() Geometry {
+	wkb := make([]byte, 0, 21)
+	wkb = appendWKBHeader(wkb, wkbPoint)
+	wkb = appendWKBPoint(wkb, p)
+	return Geometry{SRID: p.SRID, WKB: wkb, Valid: true}
+}
+
+// Value implements the driver.Valuer interface and returns the MySQL internal
+// geometry format.
+func (p Point) Value() (driver.Value, error) {
+	return p.Geometry().Value()
+}
+
+// writeTo writes the point as a SQL expression. The expression does not use
+// ST_GeomFromText because MySQL 8 expects for geographic systems the WKT in
+// latitude, longitude order. ST_SRID with two arguments requires MySQL 8.
+func (p Point) writeTo(w *bytes.Buffer) {
+	if p.SRID > 0 {
+		w.WriteString("ST_SRID(")
+	}
+	w.WriteString("POINT(")
+	writeFloat64(w, p.X)
+	w.WriteByte(',')
+	writeFloat64(w, p.Y)
+	w.WriteByte(')')
+	if p.SRID > 0 {
+		w.WriteByte(',')
+		writeUint64(w, uint64(p.SRID))
+		w.WriteByte(')')
+	}
+}
+
+// Polygon creates a polygon geometry with one ring. The ring gets closed if the
+// last point differs from the first point. The SRID of the first point applies.
+func Polygon(ring ...Point) (Geometry, error) {
+	if len(ring) < 3 {
+		return Geometry{}, errors.NotValid.Newf("[dml] Polygon requires at least three points, got %d", len(ring))
+	}
+	closed := ring[0].X == ring[len(ring)-1].X && ring[0].Y == ring[len(ring)-1].Y
+	n := len(ring)
+	if !closed {
+		n++
+	}
+	wkb := make([]byte, 0, 13+n*16)
+	wkb = appendWKBHeader(wkb, wkbPolygon)
+	wkb = appendUint32LE(wkb, 1) // number of rings
+	wkb = appendUint32LE(wkb, uint32(n))
+	for _, p := range ring {
+		wkb = appendWKBPoint(wkb, p)
+	}
+	if !closed {
+		wkb = appendWKBPoint(wkb, ring[0])
+	}
+	return Geometry{SRID: ring[0].SRID, WKB: wkb, Valid: true}, nil
+}
+
+func appendWKBHeader(wkb []byte, geomType uint32) []byte {
+	wkb = append(wkb, 1) // little endian
+	return appendUint32LE(wkb, geomType)
+}
+
+func appendWKBPoint(wkb []byte, p Point) []byte {
+	var buf [16]byte
+	binary.LittleEndian.PutUint64(buf[:8], math.Float64bits(p.X))
+	binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(p.Y))
+	return append(wkb, buf[:]...)
+}
+
+func appendUint32LE(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// Geometry contains a spatial value as well-known binary (WKB) and its spatial
+// reference system identifier. It can be used as an argument and as a scan
+// target for columns of type GEOMETRY, POINT, POLYGON, etc. Valid is false
+// for NULL.
+type Geometry struct {
+	SRID  uint32
+	WKB   []byte
+	Valid bool
+}
+
+// Value implements the driver.Valuer interface and returns the MySQL internal
+// geometry format: four bytes little endian SRID followed by the WKB.
+func (g Geometry) Value() (driver.Value, error) {
+	if !g.Valid {
+		return nil, nil
+	}
+	return g.MarshalBinary()
+}
+
+// MarshalBinary returns the MySQL internal geometry format.
+func (g Geometry) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 4, 4+len(g.WKB))
+	binary.LittleEndian.PutUint32(data, g.SRID)
+	return append(data, g.WKB...), nil
+}
+
+// UnmarshalBinary parses the MySQL internal geometry format as returned when
+// selecting a spatial column without ST_AsBinary.
+func (g *Geometry) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 { // SRID, byte order and geometry type
+		return errors.BadEncoding.Newf("[dml] Geometry: internal format too short, got %d bytes", len(data))
+	}
+	g.SRID = binary.LittleEndian.Uint32(data)
+	g.WKB = append(g.WKB[:0], data[4:]...)
+	g.Valid = true
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (g *Geometry) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*g = Geometry{}
+		return nil
+	case []byte:
+		return g.UnmarshalBinary(v)
+	case string:
+		return g.UnmarshalBinary([]byte(v))
+	}
+	return errors.NotSupported.Newf("[dml] Geometry.Scan: type %T not supported", src)
+}
+
+// Point decodes the WKB as a point. Error behaviour: NotSupported for other
+// geometry types and BadEncoding for malformed data.
+func (g Geometry) Point() (Point, error) {
+	if len(g.WKB) < 21 || g.WKB[0] > 1 {
+		return Point{}, errors.BadEncoding.Newf("[dml] Geometry.Point: invalid WKB with length %d", len(g.WKB))
+	}
+	var bo binary.ByteOrder = binary.LittleEndian
+	if g.WKB[0] == 0 {
+		bo = binary.BigEndian
+	}
+	if gt := bo.Uint32(g.WKB[1:]); gt != wkbPoint {
+		return Point{}, errors.NotSupported.Newf("[dml] Geometry.Point: WKB geometry type %d is not a point", gt)
+	}
+	return Point{
+		SRID: g.SRID,
+		X:    math.Float64frombits(bo.Uint64(g.WKB[5:])),
+		Y:    math.Float64frombits(bo.Uint64(g.WKB[13:])),
+	}, nil
+}
+
+// Geometry reads a spatial value and appends it to the arguments slice or
+// assigns the MySQL internal geometry format stored in sql.RawBytes to the
+// pointer. See the documentation for function Scan.
+func (b *ColumnMap) Geometry(ptr *Geometry) *ColumnMap {
+	if b.shouldCollectArgs() {
+		if ptr == nil || !ptr.Valid {
+			b.args = append(b.args, internalNULLNIL{})
+		} else {
+			data, _ := ptr.MarshalBinary()
+			b.args = append(b.args, data)
+		}
+		return b
+	}
+	if b.scanErr == nil {
+		switch v := b.scanCol[b.index]; v.field {
+		case 'y', 's':
+			data := v.byte
+			if v.field == 's' {
+				data = []byte(v.string) // mostly used for testing
+			}
+			if b.scanErr = ptr.UnmarshalBinary(data); b.scanErr != nil {
+				b.scanErr = errors.Wrapf(b.scanErr, "[dml] Column %q", b.Column())
+			}
+		case 'n':
+			*ptr = Geometry{}
+		default:
+			b.scanErr = errors.NotSupported.Newf("[dml] Column %q does not support field type: %q", b.Column(), v.field)
+		}
+	}
+	return b
+}
+
+// Geometry adds a spatial value to the condition. A Point can be added with
+// DriverValue or with p.Geometry().
+//		poly, err := Polygon(Point{X: 13, Y: 52}, Point{X: 14, Y: 52}, Point{X: 14, Y: 53})
+//		Column("location").STWithin().Geometry(poly)
+func (c *Condition) Geometry(g Geometry) *Condition {
+	if c.isExpression() {
+		c.Right.args = append(c.Right.args, g)
+		return c
+	}
+	c.Right.arg = g
+	return c
+}
+
+// STDistanceSphere compares the spherical distance in meters between the
+// point stored in the column and the point p. Both points must use SRID 0 or
+// SRIDWGS84. All operators, place holders and values work as for a column.
+//		Column("location").STDistanceSphere(Point{X: 13.4, Y: 52.5}).LessOrEqual().Float64(5000)
+//		// (ST_Distance_Sphere(`location`, POINT(13.4,52.5)) <= 5000)
+// Used in Select.AddColumnsConditions, the distance gets returned:
+//		Column("location").STDistanceSphere(Point{X: 13.4, Y: 52.5}).Alias("distance")
+//		// ST_Distance_Sphere(`location`, POINT(13.4,52.5)) AS `distance`
+func (c *Condition) STDistanceSphere(p Point) *Condition {
+	c.stDistanceTo = &p
+	return c
+}
+
+// STWithin checks whether the geometry stored in the column lies within the
+// geometry of the value, for example a point within a polygon.
+//		Column("location").STWithin().PlaceHolder()
+//		// (ST_Within(`location`, ?))
+func (c *Condition) STWithin() *Condition {
+	c.Operator = STWithin
+	return c
+}
+
+// STContains checks whether the geometry stored in the column contains the
+// geometry of the value, for example a delivery area containing a point.
+//		Column("area").STContains().Geometry(Point{X: 13.4, Y: 52.5}.Geometry())
+//		// (ST_Contains(`area`, 0x...))
+func (c *Condition) STContains() *Condition {
+	c.Operator = STContains
+	return c
+}
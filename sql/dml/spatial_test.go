@@ -0,0 +1,173 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+type storeLocation struct {
+	ID       int64
+	Location dml.Geometry
+}
+
+func (sl *storeLocation) MapColumns(cm *dml.ColumnMap) error {
+	for cm.Next() {
+		switch c := cm.Column(); c {
+		case "id":
+			cm.Int64(&sl.ID)
+		case "location":
+			cm.Geometry(&sl.Location)
+		default:
+			return errors.NotFound.Newf("[dml_test] Column %q not found", c)
+		}
+	}
+	return cm.Err()
+}
+
+// berlinInternal contains POINT(13.4 52.5) with SRID 0 in the MySQL internal
+// format.
+const berlinInternal = "0x000000000101000000cdcccccccccc2a400000000000404a40"
+
+func TestGeometry(t *testing.T) {
+	t.Parallel()
+
+	berlin := dml.Point{X: 13.4, Y: 52.5}
+
+	t.Run("Point round trip", func(t *testing.T) {
+		data, err := berlin.Geometry().MarshalBinary()
+		assert.NoError(t, err)
+		var g dml.Geometry
+		assert.NoError(t, g.Scan(data))
+		assert.True(t, g.Valid)
+		p, err := g.Point()
+		assert.NoError(t, err)
+		assert.Exactly(t, berlin, p)
+
+		wgs := dml.Point{SRID: dml.SRIDWGS84, X: -0.1, Y: 51.5}
+		v, err := wgs.Value()
+		assert.NoError(t, err)
+		assert.NoError(t, g.Scan(v))
+		assert.Exactly(t, uint32(dml.SRIDWGS84), g.SRID)
+		p, err = g.Point()
+		assert.NoError(t, err)
+		assert.Exactly(t, wgs, p)
+	})
+	t.Run("Scan NULL and errors", func(t *testing.T) {
+		g := berlin.Geometry()
+		assert.NoError(t, g.Scan(nil))
+		assert.False(t, g.Valid)
+		v, err := g.Value()
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+
+		assert.ErrorIsKind(t, errors.BadEncoding, g.Scan([]byte{1, 2, 3}))
+		assert.ErrorIsKind(t, errors.NotSupported, g.Scan(42))
+	})
+	t.Run("Polygon", func(t *testing.T) {
+		poly, err := dml.Polygon(dml.Point{X: 13, Y: 52}, dml.Point{X: 14, Y: 52}, dml.Point{X: 14, Y: 53})
+		assert.NoError(t, err)
+		assert.Len(t, poly.WKB, 1+4+4+4+4*16) // ring gets closed
+		_, err = poly.Point()
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+
+		_, err = dml.Polygon(dml.Point{X: 13, Y: 52}, dml.Point{X: 14, Y: 52})
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+func TestCondition_Spatial(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ST_Distance_Sphere", func(t *testing.T) {
+		compareToSQL(t,
+			dml.NewSelect("id").From("store_location").Where(
+				dml.Column("location").STDistanceSphere(dml.Point{X: 13.4, Y: 52.5}).LessOrEqual().Float64(5000),
+			),
+			errors.NoKind,
+			"SELECT `id` FROM `store_location` WHERE (ST_Distance_Sphere(`location`, POINT(13.4,52.5)) <= 5000)",
+			"",
+		)
+		compareToSQL(t,
+			dml.NewSelect("id").From("store_location").AddColumnsConditions(
+				dml.Column("location").STDistanceSphere(dml.Point{SRID: dml.SRIDWGS84, X: 13.4, Y: 52.5}).Alias("distance"),
+			).Where(
+				dml.Column("sl.location").STDistanceSphere(dml.Point{SRID: dml.SRIDWGS84, X: 13.4, Y: 52.5}).Less().PlaceHolder(),
+			).OrderBy("distance"),
+			errors.NoKind,
+			"SELECT `id`, ST_Distance_Sphere(`location`, ST_SRID(POINT(13.4,52.5),4326)) AS `distance` FROM `store_location` WHERE (ST_Distance_Sphere(`sl`.`location`, ST_SRID(POINT(13.4,52.5),4326)) < ?) ORDER BY `distance`",
+			"",
+		)
+	})
+	t.Run("ST_Within and ST_Contains", func(t *testing.T) {
+		compareToSQL(t,
+			dml.NewSelect("id").From("store_location").Where(dml.Column("location").STWithin().PlaceHolder()),
+			errors.NoKind,
+			"SELECT `id` FROM `store_location` WHERE (ST_Within(`location`, ?))",
+			"",
+		)
+		compareToSQL(t,
+			dml.NewSelect("id").From("delivery_area").Where(
+				dml.Column("area").STContains().Geometry(dml.Point{X: 13.4, Y: 52.5}.Geometry()),
+			),
+			errors.NoKind,
+			"SELECT `id` FROM `delivery_area` WHERE (ST_Contains(`area`, "+berlinInternal+"))",
+			"",
+		)
+		compareToSQL(t,
+			dml.NewSelect("id").From("store_location").Where(dml.Column("location").STWithin()),
+			errors.NotAcceptable,
+			"",
+			"",
+		)
+	})
+}
+
+func TestColumnMap_Geometry(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	berlin := dml.Point{SRID: dml.SRIDWGS84, X: 13.4, Y: 52.5}
+	internal, err := berlin.Geometry().MarshalBinary()
+	assert.NoError(t, err)
+
+	t.Run("arguments", func(t *testing.T) {
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("INSERT INTO `store_location` (`id`,`location`) VALUES (?,?),(?,?)")).
+			WithArgs(int64(1), internal, int64(2), nil).
+			WillReturnResult(sqlmock.NewResult(2, 2))
+
+		_, err := dbc.InsertInto("store_location").AddColumns("id", "location").SetRowCount(2).WithDBR().
+			ExecContext(context.TODO(), &storeLocation{ID: 1, Location: berlin.Geometry()}, &storeLocation{ID: 2})
+		assert.NoError(t, err)
+	})
+	t.Run("scan", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `id`, `location` FROM `store_location`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "location"}).AddRow(1, internal))
+
+		sl := new(storeLocation)
+		_, err := dbc.SelectFrom("store_location").AddColumns("id", "location").WithDBR().Load(context.TODO(), sl)
+		assert.NoError(t, err)
+		p, err := sl.Location.Point()
+		assert.NoError(t, err)
+		assert.Exactly(t, berlin, p)
+	})
+}
@@ -28,6 +28,8 @@ type stmtWrapper struct {
 		QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row
 		ioCloser
 	}
+	// inTx if true, the statement has been prepared on a transaction.
+	inTx bool
 }
 
 func (sw stmtWrapper) PrepareContext(_ context.Context, sql string) (*sql.Stmt, error) {
@@ -69,15 +71,20 @@ func (sw stmtWrapper) Close() error {
 type Stmt struct {
 	base builderCommon
 	Stmt *sql.Stmt
+	// tables and tablesKnown get passed to the DBR. See DBR.tables.
+	tables      []string
+	tablesKnown bool
 }
 
 // WithDBR creates a new argument handler. Not safe for concurrent use.
 func (st *Stmt) WithDBR() *DBR {
 	a := &DBR{
-		base:       st.base,
-		isPrepared: true,
+		base:        st.base,
+		isPrepared:  true,
+		tables:      st.tables,
+		tablesKnown: st.tablesKnown,
 	}
-	a.base.db = stmtWrapper{stmt: st.Stmt}
+	a.base.db = stmtWrapper{stmt: st.Stmt, inTx: isTxBound(st.base.db)}
 	return a
 }
 
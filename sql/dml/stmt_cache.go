@@ -0,0 +1,387 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// MySQL error numbers of prepared statements which are gone on the server or
+// which can't be prepared.
+const (
+	MySQLErrUnknownStmtHandler uint16 = 1243
+	MySQLErrUnsupportedPS      uint16 = 1295
+	MySQLErrNeedReprepare      uint16 = 1615
+)
+
+// StmtCache configures WithStmtCache.
+type StmtCache struct {
+	// Size limits the number of prepared statements of the ConnPool and of
+	// each Conn. If reached, the least recently used statement gets closed.
+	// Defaults to 128.
+	Size int
+}
+
+// StmtCacheStats reports the usage of the prepared statement cache.
+type StmtCacheStats struct {
+	// Len contains the number of cached statements.
+	Len       int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Reprepares counts the statements prepared again because the server
+	// has lost them.
+	Reprepares uint64
+}
+
+type stmtCacheEntry struct {
+	query string
+	stmt  *sql.Stmt
+	// noPrepare gets set if the server does not support to prepare the
+	// statement.
+	noPrepare bool
+	// refs counts the callers using the statement. An evicted statement gets
+	// closed by the last caller.
+	refs    int
+	evicted bool
+}
+
+type stmtCache struct {
+	size int
+	// db prepares the statements and is either a *sql.DB or the connection
+	// of a Conn.
+	db  Preparer
+	log log.Logger
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	stats StmtCacheStats
+}
+
+// WithStmtCache prepares all statements of the ConnPool, its connections and
+// transactions on the server and caches them by their SQL string. The cache is
+// bounded and closes the least recently used statement, so many distinct SQL
+// strings, e.g. from different cache keys, do not leak prepared statements.
+// Statements lost by the server, e.g. after a reconnect, get prepared again.
+// Each Conn has its own cache because prepared statements belong to a
+// connection. Statements without arguments and DBRs created by Prepare are not
+// affected. WithStmtCache takes precedence over WithAdaptivePrepare.
+//		dbc, err := dml.NewConnPool(dml.WithDSN(dsn), dml.WithStmtCache(dml.StmtCache{Size: 512}))
+func WithStmtCache(sc StmtCache) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			if sc.Size < 0 {
+				return errors.NotValid.Newf("[dml] WithStmtCache requires a positive size, got %d", sc.Size)
+			}
+			if sc.Size == 0 {
+				sc.Size = 128
+			}
+			c.stmtCache = newStmtCache(sc.Size, c.DB, c.Log)
+			return nil
+		},
+	}
+}
+
+func newStmtCache(size int, db Preparer, l log.Logger) *stmtCache {
+	return &stmtCache{
+		size:  size,
+		db:    db,
+		log:   l,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// derive creates a new empty cache with the same size for another connection.
+func (sc *stmtCache) derive(db Preparer) *stmtCache {
+	if sc == nil {
+		return nil
+	}
+	return newStmtCache(sc.size, db, sc.log)
+}
+
+// StmtCacheStats returns the usage of the prepared statement cache. Returns
+// the zero value if WithStmtCache has not been applied.
+func (c *ConnPool) StmtCacheStats() StmtCacheStats {
+	return c.stmtCache.statistics()
+}
+
+// StmtCacheStats returns the usage of the prepared statement cache of the
+// connection. Returns the zero value if WithStmtCache has not been applied.
+func (c *Conn) StmtCacheStats() StmtCacheStats {
+	return c.stmtCache.statistics()
+}
+
+func (sc *stmtCache) statistics() StmtCacheStats {
+	if sc == nil {
+		return StmtCacheStats{}
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	s := sc.stats
+	s.Len = sc.ll.Len()
+	return s
+}
+
+// acquire returns the cached entry of the prepared statement or prepares it.
+// The entry field stmt is nil if the statement cannot be prepared. Each entry
+// must be passed to release after the statement has been used.
+func (sc *stmtCache) acquire(ctx context.Context, query string) (*stmtCacheEntry, error) {
+	sc.mu.Lock()
+	if el, ok := sc.items[query]; ok {
+		sc.ll.MoveToFront(el)
+		sc.stats.Hits++
+		e := el.Value.(*stmtCacheEntry)
+		e.refs++
+		sc.mu.Unlock()
+		return e, nil
+	}
+	sc.stats.Misses++
+	sc.mu.Unlock()
+
+	stmt, err := sc.db.PrepareContext(ctx, query)
+	if err != nil && ctx.Err() != nil {
+		return nil, errors.Wrapf(err, "[dml] WithStmtCache failed to prepare query %q", query)
+	}
+	if err != nil && MySQLNumberFromError(err) != MySQLErrUnsupportedPS {
+		// might be a temporary failure, so the next call tries again.
+		if sc.log != nil && sc.log.IsInfo() {
+			sc.log.Info("WithStmtCache.Prepare", log.String("sql", query), log.Err(err))
+		}
+		return &stmtCacheEntry{query: query, noPrepare: true, refs: 1}, nil
+	}
+
+	sc.mu.Lock()
+	if el, ok := sc.items[query]; ok { // another goroutine has been faster
+		sc.ll.MoveToFront(el)
+		e := el.Value.(*stmtCacheEntry)
+		e.refs++
+		sc.mu.Unlock()
+		if stmt != nil {
+			sc.closeStmt(query, stmt)
+		}
+		return e, nil
+	}
+	// not every statement can be prepared, fall back to the unprepared
+	// execution and remember it.
+	entry := &stmtCacheEntry{query: query, stmt: stmt, noPrepare: err != nil, refs: 1}
+	sc.items[query] = sc.ll.PushFront(entry)
+	var evicted []*stmtCacheEntry
+	for sc.ll.Len() > sc.size {
+		e := sc.ll.Remove(sc.ll.Back()).(*stmtCacheEntry)
+		delete(sc.items, e.query)
+		sc.stats.Evictions++
+		e.evicted = true
+		if e.stmt != nil && e.refs == 0 {
+			evicted = append(evicted, e)
+		}
+	}
+	sc.mu.Unlock()
+
+	for _, e := range evicted {
+		sc.closeStmt(e.query, e.stmt)
+	}
+	return entry, nil
+}
+
+// txStmtCache keeps the cached statements bound to a transaction for its
+// lifetime, so each statement gets bound only once. The statements hold a
+// reference of their cache entry until the end of the transaction, hence an
+// eviction does not close them while the transaction uses them.
+type txStmtCache struct {
+	sc    *stmtCache
+	mu    sync.Mutex
+	stmts map[string]txStmt
+}
+
+type txStmt struct {
+	entry *stmtCacheEntry
+	stmt  *sql.Stmt
+}
+
+// newTxStmtCache creates the cache of a new transaction. Returns nil if
+// WithStmtCache has not been applied.
+func (sc *stmtCache) newTxStmtCache() *txStmtCache {
+	if sc == nil {
+		return nil
+	}
+	return &txStmtCache{sc: sc, stmts: make(map[string]txStmt)}
+}
+
+func (tc *txStmtCache) get(query string) (txStmt, bool) {
+	if tc == nil {
+		return txStmt{}, false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	ts, ok := tc.stmts[query]
+	return ts, ok
+}
+
+// put stores the statement and reports whether it has been stored.
+func (tc *txStmtCache) put(query string, ts txStmt) bool {
+	if tc == nil {
+		return false
+	}
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if _, ok := tc.stmts[query]; ok {
+		return false
+	}
+	tc.stmts[query] = ts
+	return true
+}
+
+func (tc *txStmtCache) delete(query string) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	delete(tc.stmts, query)
+}
+
+// close releases the cache entries at the end of the transaction. database/sql
+// closes the statements bound to the transaction.
+func (tc *txStmtCache) close() {
+	if tc == nil {
+		return
+	}
+	tc.mu.Lock()
+	stmts := tc.stmts
+	tc.stmts = make(map[string]txStmt)
+	tc.mu.Unlock()
+	for _, ts := range stmts {
+		tc.sc.release(ts.entry)
+	}
+}
+
+// release returns the entry of acquire and closes the statement if it has
+// been evicted in the meantime and no other caller uses it.
+func (sc *stmtCache) release(e *stmtCacheEntry) {
+	sc.mu.Lock()
+	e.refs--
+	doClose := e.evicted && e.refs == 0 && e.stmt != nil
+	sc.mu.Unlock()
+	if doClose {
+		sc.closeStmt(e.query, e.stmt)
+	}
+}
+
+// closeStmt closes an evicted statement. Open rows keep the statement alive
+// until they get closed. The error only gets logged because the statement has
+// already been used successfully.
+func (sc *stmtCache) closeStmt(query string, stmt *sql.Stmt) {
+	if err := stmt.Close(); err != nil && sc.log != nil && sc.log.IsInfo() {
+		sc.log.Info("WithStmtCache.Close", log.String("sql", query), log.Err(err))
+	}
+}
+
+// remove deletes the entry from the cache, if it is still cached, and marks it
+// as evicted, so release closes the statement.
+func (sc *stmtCache) remove(e *stmtCacheEntry) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	el, ok := sc.items[e.query]
+	if !ok || el.Value.(*stmtCacheEntry) != e {
+		return
+	}
+	sc.ll.Remove(el)
+	delete(sc.items, e.query)
+	sc.stats.Reprepares++
+	e.evicted = true
+}
+
+func (sc *stmtCache) close() (err error) {
+	if sc == nil {
+		return nil
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for el := sc.ll.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*stmtCacheEntry)
+		e.evicted = true
+		if e.stmt != nil && e.refs == 0 { // otherwise release closes it
+			if cErr := e.stmt.Close(); err == nil && cErr != nil {
+				err = errors.WithStack(cErr)
+			}
+		}
+	}
+	sc.ll.Init()
+	sc.items = make(map[string]*list.Element, sc.size)
+	return err
+}
+
+// isStmtGone reports whether the server has lost the prepared statement. A
+// lost connection, driver.ErrBadConn, gets already handled by database/sql.
+func isStmtGone(err error) bool {
+	switch MySQLNumberFromError(err) {
+	case MySQLErrUnknownStmtHandler, MySQLErrNeedReprepare:
+		return true
+	}
+	return false
+}
+
+// withCachedStmt runs fn with the cached prepared statement of the SQL string.
+// If the statement is gone on the server, it gets prepared again and fn runs a
+// second time. Returns false if the cache does not apply. Statements with
+// Select.LockWaitTimeout do not get prepared. Within a transaction the
+// statement gets bound once and reused until the end of the transaction.
+func (bc *builderCommon) withCachedStmt(ctx context.Context, sqlStr string, args []interface{}, fn func(*sql.Stmt) error) (ok bool, err error) {
+	sc := bc.stmtCache
+	if sc == nil || sqlStr == "" || len(args) == 0 || bc.stmtLockWait > 0 {
+		return false, nil
+	}
+	tx, isTx := bc.db.(*sql.Tx)
+	if !isTx && bc.db != sc.db {
+		return false, nil
+	}
+	for attempt := 0; ; attempt++ {
+		ts, cached := bc.txStmts.get(sqlStr)
+		if !cached {
+			e, err := sc.acquire(ctx, sqlStr)
+			if err != nil {
+				return true, errors.WithStack(err)
+			}
+			if e.stmt == nil {
+				sc.release(e)
+				return false, nil
+			}
+			ts = txStmt{entry: e, stmt: e.stmt}
+			if isTx {
+				ts.stmt = tx.StmtContext(ctx, e.stmt)
+				cached = bc.txStmts.put(sqlStr, ts)
+			}
+		}
+		err = fn(ts.stmt)
+		if attempt == 0 && isStmtGone(err) {
+			sc.remove(ts.entry) // already gone on the server
+			if cached {
+				bc.txStmts.delete(sqlStr)
+			}
+			sc.release(ts.entry)
+			continue
+		}
+		if !cached {
+			sc.release(ts.entry)
+		}
+		return true, err
+	}
+}
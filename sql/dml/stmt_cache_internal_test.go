@@ -0,0 +1,57 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestStmtCache_EvictionWhileInUse(t *testing.T) {
+	db, dbMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() {
+		dbMock.ExpectClose()
+		assert.NoError(t, db.Close())
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	}()
+
+	sc := newStmtCache(1, db, nil)
+	prepA := dbMock.ExpectPrepare("SELECT a")
+	dbMock.ExpectPrepare("SELECT b").WillBeClosed()
+
+	a, err := sc.acquire(context.TODO(), "SELECT a")
+	assert.NoError(t, err)
+	b, err := sc.acquire(context.TODO(), "SELECT b")
+	assert.NoError(t, err)
+	assert.True(t, a.evicted, "SELECT a must be evicted")
+
+	// the evicted statement stays usable until it gets released
+	prepA.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = a.stmt.ExecContext(context.TODO())
+	assert.NoError(t, err)
+
+	prepA.WillBeClosed()
+	sc.release(a)
+	sc.release(b)
+	_, err = a.stmt.ExecContext(context.TODO())
+	assert.EqualError(t, err, "sql: statement is closed")
+
+	assert.NoError(t, sc.close())
+	assert.Exactly(t, StmtCacheStats{Misses: 2, Evictions: 1}, sc.statistics())
+}
@@ -0,0 +1,172 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestWithStmtCache(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithStmtCache(dml.StmtCache{Size: 1}))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	const (
+		sqlSelect = "SELECT `id` FROM `product` WHERE (`id` = ?)"
+		sqlUpdate = "UPDATE `product` SET `sku`=? WHERE (`id` = ?)"
+	)
+	sel := dbc.SelectFrom("product").AddColumns("id").Where(dml.Column("id").PlaceHolder()).WithDBR()
+	upd := dbc.Update("product").AddClauses(dml.Column("sku").PlaceHolder()).
+		Where(dml.Column("id").PlaceHolder()).WithDBR()
+
+	t.Run("statement gets prepared once", func(t *testing.T) {
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+		prep.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		prep.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+		for _, id := range []int64{1, 2} {
+			ids, err := sel.LoadInt64s(context.TODO(), nil, id)
+			assert.NoError(t, err)
+			assert.Exactly(t, []int64{id}, ids)
+		}
+	})
+
+	t.Run("least recently used statement gets closed", func(t *testing.T) {
+		prepUpd := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlUpdate))
+		prepUpd.ExpectExec().WithArgs("a", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		_, err := upd.ExecContext(context.TODO(), "a", 1)
+		assert.NoError(t, err)
+
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+		prep.ExpectQuery().WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		ids, err := sel.LoadInt64s(context.TODO(), nil, 3)
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{3}, ids)
+	})
+
+	t.Run("lost statement gets prepared again", func(t *testing.T) {
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(sqlSelect)).WithArgs(4).
+			WillReturnError(&mysql.MySQLError{Number: dml.MySQLErrUnknownStmtHandler, Message: "Unknown prepared statement handler"})
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+		prep.ExpectQuery().WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+		ids, err := sel.LoadInt64s(context.TODO(), nil, 4)
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{4}, ids)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		assert.Exactly(t, dml.StmtCacheStats{
+			Len:        1,
+			Hits:       2,
+			Misses:     4,
+			Evictions:  2,
+			Reprepares: 1,
+		}, dbc.StmtCacheStats())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		err := dbc.Options(dml.WithStmtCache(dml.StmtCache{Size: -1}))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
+
+func TestWithStmtCache_PrepareError(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithStmtCache(dml.StmtCache{Size: 2}))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	const sqlSelect = "SELECT `id` FROM `product` WHERE (`id` = ?)"
+	sel := dbc.SelectFrom("product").AddColumns("id").Where(dml.Column("id").PlaceHolder()).WithDBR()
+
+	t.Run("temporary error gets not cached", func(t *testing.T) {
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect)).WillReturnError(errors.ConnectionFailed.Newf("server busy"))
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta(sqlSelect)).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+		prep.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+		for _, id := range []int64{1, 2} {
+			ids, err := sel.LoadInt64s(context.TODO(), nil, id)
+			assert.NoError(t, err)
+			assert.Exactly(t, []int64{id}, ids)
+		}
+		assert.Exactly(t, 1, dbc.StmtCacheStats().Len)
+	})
+
+	t.Run("unsupported statement gets cached", func(t *testing.T) {
+		const sqlCall = "CALL `product_sync`(?)"
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlCall)).
+			WillReturnError(&mysql.MySQLError{Number: dml.MySQLErrUnsupportedPS, Message: "This command is not supported in the prepared statement protocol yet"})
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(sqlCall)).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta(sqlCall)).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		call := dbc.WithQueryBuilder(dml.QuerySQLFn(func() (string, []interface{}, error) {
+			return sqlCall, nil, nil
+		}))
+		for _, id := range []int64{1, 2} {
+			_, err := call.ExecContext(context.TODO(), id)
+			assert.NoError(t, err)
+		}
+		assert.Exactly(t, 2, dbc.StmtCacheStats().Len)
+	})
+}
+
+func TestWithStmtCache_Tx(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithStmtCache(dml.StmtCache{Size: 2}))
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	const sqlSelect = "SELECT `id` FROM `product` WHERE (`id` = ?)"
+	dbMock.ExpectBegin()
+	dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect)) // on another connection of the pool
+	prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+	prep.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	prep.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	dbMock.ExpectCommit()
+
+	tx, err := dbc.BeginTx(context.TODO(), nil)
+	assert.NoError(t, err)
+	sel := tx.SelectFrom("product").AddColumns("id").Where(dml.Column("id").PlaceHolder()).WithDBR()
+	for _, id := range []int64{1, 2} {
+		ids, err := sel.LoadInt64s(context.TODO(), nil, id)
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{id}, ids)
+	}
+	assert.NoError(t, tx.Commit())
+	// the second execution reuses the statement bound to the transaction.
+	assert.Exactly(t, dml.StmtCacheStats{Len: 1, Misses: 1}, dbc.StmtCacheStats())
+	dbMock.ExpectClose() // the pool has opened a second connection
+}
+
+func TestWithStmtCache_CloseError(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t, dml.WithStmtCache(dml.StmtCache{Size: 1}))
+
+	const sqlSelect = "SELECT `id` FROM `product` WHERE (`id` = ?)"
+	prep := dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta(sqlSelect))
+	prep.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	_, err := dbc.SelectFrom("product").AddColumns("id").Where(dml.Column("id").PlaceHolder()).
+		WithDBR().LoadInt64s(context.TODO(), nil, 1)
+	assert.NoError(t, err)
+
+	dbMock.ExpectClose().WillReturnError(errors.ConnectionFailed.Newf("DB gone"))
+	err = dbc.Close()
+	assert.ErrorIsKind(t, errors.ConnectionFailed, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
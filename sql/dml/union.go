@@ -36,6 +36,7 @@ type Union struct {
 	IsAll       bool // IsAll enables UNION ALL
 	IsIntersect bool // See Intersect()
 	IsExcept    bool // See Except()
+	IsEmulated  bool // See Emulate()
 
 	// When using Union as a template, only one *Select is required.
 	oldNew [][]string // use for string replacement with `repls` field
@@ -66,11 +67,7 @@ func (c *ConnPool) Union(selects ...*Select) *Union {
 	id := c.makeUniqueID()
 	return &Union{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: unionInitLog(c.Log, selects, id),
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, unionInitLog(c.Log, selects, id), c.DB),
 		},
 		Selects: selects,
 	}
@@ -81,11 +78,7 @@ func (c *Conn) Union(selects ...*Select) *Union {
 	id := c.makeUniqueID()
 	return &Union{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: unionInitLog(c.Log, selects, id),
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, unionInitLog(c.Log, selects, id), c.qep()),
 		},
 		Selects: selects,
 	}
@@ -97,11 +90,7 @@ func (tx *Tx) Union(selects ...*Select) *Union {
 	id := tx.makeUniqueID()
 	return &Union{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: unionInitLog(tx.Log, selects, id),
-				db:  tx.DB,
-			},
+			builderCommon: tx.newBuilderCommon(id, unionInitLog(tx.Log, selects, id), tx.DB),
 		},
 		Selects: selects,
 	}
@@ -188,6 +177,7 @@ func (u *Union) OrderByDesc(columns ...string) *Union {
 // subquery in the FROM clause. Only supported in MariaDB >=10.3
 func (u *Union) Intersect() *Union {
 	u.IsIntersect = true
+	u.IsExcept = false
 	return u
 }
 
@@ -197,6 +187,7 @@ func (u *Union) Intersect() *Union {
 // the same operation precedence. Only supported in MariaDB >=10.3
 func (u *Union) Except() *Union {
 	u.IsExcept = true
+	u.IsIntersect = false
 	return u
 }
 
@@ -262,10 +253,14 @@ func (u *Union) WithCacheKey(key string, args ...interface{}) *Union {
 
 // ToSQL generates the SQL string and its arguments. Calls to this function are
 // idempotent.
-func (u *Union) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (u *Union) toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	u.source = dmlSourceUnion
 	u.Selects[0].id = u.id
 
+	if u.IsEmulated && (u.IsIntersect || u.IsExcept) {
+		return u.toSQLEmulated(w, d, placeHolders)
+	}
+
 	if len(u.Selects) > 1 {
 		for i, s := range u.Selects {
 			if i > 0 {
@@ -273,18 +268,18 @@ func (u *Union) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err e
 			}
 			w.WriteByte('(')
 
-			placeHolders, err = s.toSQL(w, placeHolders)
+			placeHolders, err = s.toSQL(w, d, placeHolders)
 			if err != nil {
 				return nil, errors.Wrapf(err, "[dml] Union.ToSQL at Select index %d", i)
 			}
 			w.WriteByte(')')
 		}
-		sqlWriteOrderBy(w, u.OrderBys, true)
+		sqlWriteOrderBy(w, d, u.OrderBys, true)
 		return placeHolders, nil
 	}
 
 	bufSel0 := bufferpool.Get()
-	placeHolders, err = u.Selects[0].toSQL(bufSel0, placeHolders)
+	placeHolders, err = u.Selects[0].toSQL(bufSel0, d, placeHolders)
 	selStr := bufSel0.String()
 	bufferpool.Put(bufSel0)
 	if err != nil {
@@ -309,7 +304,7 @@ func (u *Union) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err e
 		return nil, errors.Empty.Newf("[dml] No SQL string generated. Number of select stmts: %d", len(u.Selects))
 	}
 
-	sqlWriteOrderBy(w, u.OrderBys, true)
+	sqlWriteOrderBy(w, d, u.OrderBys, true)
 	return placeHolders, nil
 }
 
@@ -0,0 +1,238 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/log"
+)
+
+// NewIntersect creates a new Union which combines the SELECT statements with
+// INTERSECT. Servers without native INTERSECT support require a call to
+// Union.Emulate.
+func NewIntersect(selects ...*Select) *Union {
+	return &Union{
+		Selects:     selects,
+		IsIntersect: true,
+	}
+}
+
+// NewExcept creates a new Union which combines the SELECT statements with
+// EXCEPT. Servers without native EXCEPT support require a call to
+// Union.Emulate.
+func NewExcept(selects ...*Select) *Union {
+	return &Union{
+		Selects:  selects,
+		IsExcept: true,
+	}
+}
+
+// Intersect creates a new INTERSECT with a random connection from the pool.
+// The statement gets emulated if WithDetectSetOperations detected a server
+// without native support.
+func (c *ConnPool) Intersect(selects ...*Select) *Union {
+	u := c.Union(selects...).Intersect()
+	u.IsEmulated = c.emulateSetOperations
+	return u
+}
+
+// Except creates a new EXCEPT with a random connection from the pool. The
+// statement gets emulated if WithDetectSetOperations detected a server without
+// native support.
+func (c *ConnPool) Except(selects ...*Select) *Union {
+	u := c.Union(selects...).Except()
+	u.IsEmulated = c.emulateSetOperations
+	return u
+}
+
+// Intersect creates a new INTERSECT with a dedicated connection from the pool.
+func (c *Conn) Intersect(selects ...*Select) *Union {
+	u := c.Union(selects...).Intersect()
+	u.IsEmulated = c.emulateSetOperations
+	return u
+}
+
+// Except creates a new EXCEPT with a dedicated connection from the pool.
+func (c *Conn) Except(selects ...*Select) *Union {
+	u := c.Union(selects...).Except()
+	u.IsEmulated = c.emulateSetOperations
+	return u
+}
+
+// Intersect creates a new INTERSECT bound to the transaction.
+func (tx *Tx) Intersect(selects ...*Select) *Union {
+	u := tx.Union(selects...).Intersect()
+	u.IsEmulated = tx.emulateSetOperations
+	return u
+}
+
+// Except creates a new EXCEPT bound to the transaction.
+func (tx *Tx) Except(selects ...*Select) *Union {
+	u := tx.Union(selects...).Except()
+	u.IsEmulated = tx.emulateSetOperations
+	return u
+}
+
+// Emulate rewrites INTERSECT and EXCEPT into EXISTS and NOT EXISTS subqueries
+// for servers without native support, like MySQL < 8.0.31 and MariaDB < 10.3.
+// The columns of each SELECT get compared by their position with the NULL-safe
+// operator <=>, and duplicates get removed, as the native set operations do.
+// All columns must be identifiers or must have an alias. A SELECT cannot have
+// its own ORDER BY or LIMIT, because the derived tables would not apply them
+// like the native set operations. Using the Union as a template is not
+// supported.
+//
+//	(SELECT a, b FROM t1) INTERSECT (SELECT c, d FROM t2)
+//
+// Gets converted to:
+//
+//	SELECT DISTINCT * FROM (SELECT a, b FROM t1) AS `t0` WHERE EXISTS (SELECT
+//	1 FROM (SELECT c, d FROM t2) AS `t1` WHERE `t0`.`a` <=> `t1`.`c` AND
+//	`t0`.`b` <=> `t1`.`d`)
+func (u *Union) Emulate() *Union {
+	u.IsEmulated = true
+	return u
+}
+
+// WithDetectSetOperations queries the server version and lets the Intersect and
+// Except functions of ConnPool, Conn and Tx emulate the set operations if the
+// server does not support them natively.
+func WithDetectSetOperations(ctx context.Context) ConnPoolOption {
+	return ConnPoolOption{
+		sortOrder: 11, // must run after WithDSN, WithDB and WithLogger
+		fn: func(c *ConnPool) error {
+			var version string
+			if err := c.DB.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version); err != nil {
+				return errors.Wrapf(err, "[dml] WithDetectSetOperations failed to query the version")
+			}
+			c.emulateSetOperations = !supportsSetOperations(version)
+			if c.Log != nil && c.Log.IsDebug() {
+				c.Log.Debug("WithDetectSetOperations", log.String("version", version), log.Bool("emulate", c.emulateSetOperations))
+			}
+			return nil
+		},
+	}
+}
+
+// supportsSetOperations reports whether a server with the version string as
+// returned by VERSION() supports INTERSECT and EXCEPT natively. MariaDB since
+// 10.3 and MySQL since 8.0.31.
+func supportsSetOperations(version string) bool {
+	isMariaDB, v := parseServerVersion(version)
+	if isMariaDB {
+		return v[0] > 10 || (v[0] == 10 && v[1] >= 3)
+	}
+	return v[0] > 8 || (v[0] == 8 && (v[1] > 0 || v[2] >= 31))
+}
+
+// parseServerVersion splits the version string as returned by VERSION() into
+// major, minor and patch version.
+func parseServerVersion(version string) (isMariaDB bool, v [3]int) {
+	isMariaDB = strings.Contains(strings.ToLower(version), "mariadb")
+	if isMariaDB {
+		version = strings.TrimPrefix(version, "5.5.5-") // replication compatibility prefix
+	}
+	if i := strings.IndexAny(version, "-+ "); i > 0 {
+		version = version[:i]
+	}
+	for i, p := range strings.SplitN(version, ".", 3) {
+		v[i], _ = strconv.Atoi(p)
+	}
+	return isMariaDB, v
+}
+
+// setOperationColumns returns the names of the result set columns of a SELECT.
+func setOperationColumns(s *Select) ([]string, error) {
+	if s.LimitValid || len(s.OrderBys) > 0 || s.IsOrderByRand {
+		return nil, errors.NotSupported.Newf("[dml] Union.Emulate does not support ORDER BY or LIMIT in the SELECT of table %q", s.Table.Name)
+	}
+	if s.IsStar || len(s.Columns) == 0 {
+		return nil, errors.NotAcceptable.Newf("[dml] Union.Emulate requires explicit columns in the SELECT of table %q", s.Table.Name)
+	}
+	cols := make([]string, 0, len(s.Columns))
+	for _, c := range s.Columns {
+		switch {
+		case c.Aliased != "":
+			cols = append(cols, c.Aliased)
+		case c.Expression == "" && c.DerivedTable == nil && c.Name != "" && c.Name != sqlStar:
+			n := c.Name
+			if i := strings.LastIndexByte(n, '.'); i >= 0 {
+				n = n[i+1:]
+			}
+			cols = append(cols, Quoter.unQuote(n))
+		default:
+			return nil, errors.NotAcceptable.Newf("[dml] Union.Emulate requires an alias for column %q in the SELECT of table %q", c.Name+c.Expression, s.Table.Name)
+		}
+	}
+	return cols, nil
+}
+
+// toSQLEmulated writes the INTERSECT or EXCEPT as EXISTS or NOT EXISTS
+// subqueries.
+func (u *Union) toSQLEmulated(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
+	if len(u.Selects) < 2 {
+		return nil, errors.NotAcceptable.Newf("[dml] Union.Emulate requires at least two SELECT statements")
+	}
+	cols0, err := setOperationColumns(u.Selects[0])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	w.WriteString("SELECT DISTINCT * FROM (")
+	if placeHolders, err = u.Selects[0].toSQL(w, d, placeHolders); err != nil {
+		return nil, errors.Wrapf(err, "[dml] Union.ToSQL at Select index %d", 0)
+	}
+	w.WriteString(") AS `t0` WHERE ")
+
+	for i, s := range u.Selects[1:] {
+		cols, err := setOperationColumns(s)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(cols) != len(cols0) {
+			return nil, errors.Mismatch.Newf("[dml] Union.Emulate: Select index %d has %d columns but the first Select has %d columns", i+1, len(cols), len(cols0))
+		}
+		if i > 0 {
+			w.WriteString(" AND ")
+		}
+		if u.IsExcept {
+			w.WriteString("NOT ")
+		}
+		w.WriteString("EXISTS (SELECT 1 FROM (")
+		if placeHolders, err = s.toSQL(w, d, placeHolders); err != nil {
+			return nil, errors.Wrapf(err, "[dml] Union.ToSQL at Select index %d", i+1)
+		}
+		alias := "t" + strconv.Itoa(i+1)
+		w.WriteString(") AS ")
+		Quoter.quote(w, alias)
+		w.WriteString(" WHERE ")
+		for j, c := range cols {
+			if j > 0 {
+				w.WriteString(" AND ")
+			}
+			Quoter.WriteQualifierName(w, "t0", cols0[j])
+			w.WriteString(" <=> ")
+			Quoter.WriteQualifierName(w, alias, c)
+		}
+		w.WriteByte(')')
+	}
+	sqlWriteOrderBy(w, d, u.OrderBys, false)
+	return placeHolders, nil
+}
@@ -438,3 +438,141 @@ func TestUnionTemplate_ReuseArgs(t *testing.T) {
 		assert.Exactly(t, []string{":entityID", ":storeID"}, u.qualifiedColumns)
 	})
 }
+
+func TestUnion_Emulate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("intersect", func(t *testing.T) {
+		u := NewIntersect(
+			NewSelect("a", "t.b").From("tableAB").Where(Column("a").Int64(3)),
+			NewSelect("c").AddColumnsAliases("d", "b").From("tableCD"),
+		).Emulate().OrderBy("a")
+		compareToSQL(t, u, errors.NoKind,
+			"SELECT DISTINCT * FROM (SELECT `a`, `t`.`b` FROM `tableAB` WHERE (`a` = 3)) AS `t0` WHERE EXISTS (SELECT 1 FROM (SELECT `c`, `d` AS `b` FROM `tableCD`) AS `t1` WHERE `t0`.`a` <=> `t1`.`c` AND `t0`.`b` <=> `t1`.`b`) ORDER BY `a`",
+			"",
+		)
+	})
+	t.Run("except three selects with placeholder", func(t *testing.T) {
+		u := NewExcept(
+			NewSelect("a").From("tableA").Where(Column("a").Greater().PlaceHolder()),
+			NewSelect("b").From("tableB"),
+			NewSelect("c").From("tableC"),
+		).Emulate().WithDBR()
+		compareToSQL(t, u.TestWithArgs(5), errors.NoKind,
+			"SELECT DISTINCT * FROM (SELECT `a` FROM `tableA` WHERE (`a` > ?)) AS `t0` WHERE NOT EXISTS (SELECT 1 FROM (SELECT `b` FROM `tableB`) AS `t1` WHERE `t0`.`a` <=> `t1`.`b`) AND NOT EXISTS (SELECT 1 FROM (SELECT `c` FROM `tableC`) AS `t2` WHERE `t0`.`a` <=> `t2`.`c`)",
+			"SELECT DISTINCT * FROM (SELECT `a` FROM `tableA` WHERE (`a` > 5)) AS `t0` WHERE NOT EXISTS (SELECT 1 FROM (SELECT `b` FROM `tableB`) AS `t1` WHERE `t0`.`a` <=> `t1`.`b`) AND NOT EXISTS (SELECT 1 FROM (SELECT `c` FROM `tableC`) AS `t2` WHERE `t0`.`a` <=> `t2`.`c`)",
+			int64(5),
+		)
+	})
+	t.Run("native", func(t *testing.T) {
+		u := NewExcept(
+			NewSelect("a").From("tableA"),
+			NewSelect("b").From("tableB"),
+		)
+		compareToSQL(t, u, errors.NoKind,
+			"(SELECT `a` FROM `tableA`)\nEXCEPT\n(SELECT `b` FROM `tableB`)",
+			"",
+		)
+	})
+	t.Run("column count mismatch", func(t *testing.T) {
+		u := NewIntersect(
+			NewSelect("a", "b").From("tableAB"),
+			NewSelect("c").From("tableC"),
+		).Emulate()
+		compareToSQL(t, u, errors.Mismatch, "", "")
+	})
+	t.Run("expression without alias", func(t *testing.T) {
+		u := NewIntersect(
+			NewSelect().AddColumnsConditions(Expr("COUNT(*)")).From("tableA"),
+			NewSelect("c").From("tableC"),
+		).Emulate()
+		compareToSQL(t, u, errors.NotAcceptable, "", "")
+	})
+	t.Run("star", func(t *testing.T) {
+		u := NewIntersect(
+			NewSelect().Star().From("tableA"),
+			NewSelect("c").From("tableC"),
+		).Emulate()
+		compareToSQL(t, u, errors.NotAcceptable, "", "")
+	})
+	t.Run("limit in a select", func(t *testing.T) {
+		u := NewExcept(
+			NewSelect("a").From("tableA"),
+			NewSelect("b").From("tableB").Limit(0, 1),
+		).Emulate()
+		compareToSQL(t, u, errors.NotSupported, "", "")
+	})
+	t.Run("order by in a select", func(t *testing.T) {
+		u := NewIntersect(
+			NewSelect("a").From("tableA").OrderBy("a"),
+			NewSelect("b").From("tableB"),
+		).Emulate()
+		compareToSQL(t, u, errors.NotSupported, "", "")
+	})
+}
+
+func TestUnion_SetOperationMode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("last mode wins", func(t *testing.T) {
+		u := NewUnion(
+			NewSelect("a").From("tableA"),
+			NewSelect("b").From("tableB"),
+		).Intersect().Except()
+		compareToSQL(t, u, errors.NoKind,
+			"(SELECT `a` FROM `tableA`)\nEXCEPT\n(SELECT `b` FROM `tableB`)",
+			"",
+		)
+		assert.False(t, u.IsIntersect)
+	})
+	t.Run("derived table with placeholders", func(t *testing.T) {
+		u := NewUnion(
+			NewSelect("sku").From("cart_item").Where(Column("customer_id").PlaceHolder()),
+			NewSelect("sku").From("wishlist_item").Where(Column("customer_id").PlaceHolder()),
+		).Intersect()
+		sel := NewSelectWithDerivedUnion(u, "t").AddColumns("t.sku").
+			Where(Column("t.sku").NotLike().PlaceHolder()).WithDBR()
+		compareToSQL(t, sel.TestWithArgs(7, 8, "gift%"), errors.NoKind,
+			"SELECT `t`.`sku` FROM ((SELECT `sku` FROM `cart_item` WHERE (`customer_id` = ?))\nINTERSECT\n(SELECT `sku` FROM `wishlist_item` WHERE (`customer_id` = ?))) AS `t` WHERE (`t`.`sku` NOT LIKE ?)",
+			"SELECT `t`.`sku` FROM ((SELECT `sku` FROM `cart_item` WHERE (`customer_id` = 7))\nINTERSECT\n(SELECT `sku` FROM `wishlist_item` WHERE (`customer_id` = 8))) AS `t` WHERE (`t`.`sku` NOT LIKE 'gift%')",
+			int64(7), int64(8), "gift%",
+		)
+	})
+	t.Run("derived emulated table", func(t *testing.T) {
+		u := NewExcept(
+			NewSelect("sku").From("cart_item"),
+			NewSelect("sku").From("wishlist_item"),
+		).Emulate()
+		sel := NewSelectWithDerivedUnion(u, "t").Count()
+		compareToSQL(t, sel, errors.NoKind,
+			"SELECT COUNT(*) AS `counted` FROM (SELECT DISTINCT * FROM (SELECT `sku` FROM `cart_item`) AS `t0` WHERE NOT EXISTS (SELECT 1 FROM (SELECT `sku` FROM `wishlist_item`) AS `t1` WHERE `t0`.`sku` <=> `t1`.`sku`)) AS `t`",
+			"",
+		)
+	})
+	t.Run("clone", func(t *testing.T) {
+		sel := NewSelectWithDerivedUnion(NewIntersect(NewSelect("a").From("tableA")), "t")
+		c := sel.Clone()
+		assert.NotSame(t, sel.Table.DerivedUnion, c.Table.DerivedUnion)
+	})
+}
+
+func TestSupportsSetOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"5.7.29-log", false},
+		{"8.0.30", false},
+		{"8.0.31", true},
+		{"8.1.0-commercial", true},
+		{"10.2.31-MariaDB-1:10.2.31+maria~bionic", false},
+		{"10.3.22-MariaDB", true},
+		{"5.5.5-10.4.12-MariaDB", true},
+		{"", false},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, supportsSetOperations(test.version), "Version %q", test.version)
+	}
+}
@@ -22,14 +22,22 @@ import (
 	"github.com/corestoreio/log"
 )
 
-// Update contains the logic for an UPDATE statement.
-// TODO: add UPDATE JOINS
+// Update contains the logic for an UPDATE statement. A multiple-table UPDATE
+// gets created with the JOIN functions.
 type Update struct {
 	BuilderBase
 	BuilderConditional
 	// SetClauses contains the column/argument association. For each column
 	// there must be one argument.
 	SetClauses Conditions
+	// OptimisticLockColumn if set, gets incremented by one in the SET clause
+	// and compared in the WHERE clause with its current value. See function
+	// OptimisticLock.
+	OptimisticLockColumn string
+	// ReturningColumns defines the columns of the updated rows to return. Use
+	// function `Returning` to conveniently set it and LoadReturning to execute
+	// it.
+	ReturningColumns ids
 }
 
 // NewUpdate creates a new Update object.
@@ -50,12 +58,8 @@ func newUpdate(db QueryExecPreparer, cComm *connCommon, table string) *Update {
 	}
 	return &Update{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: l,
-				db:  db,
-			},
-			Table: MakeIdentifier(table),
+			builderCommon: cComm.newBuilderCommon(id, l, db),
+			Table:         MakeIdentifier(table),
 		},
 	}
 }
@@ -68,7 +72,7 @@ func (c *ConnPool) Update(table string) *Update {
 
 // Update creates a new Update for the given table bound to a single connection.
 func (c *Conn) Update(table string) *Update {
-	return newUpdate(c.DB, &c.connCommon, table)
+	return newUpdate(c.qep(), &c.connCommon, table)
 }
 
 // Update creates a new Update for the given table bound to a transaction.
@@ -126,6 +130,54 @@ func (b *Update) Where(wf ...*Condition) *Update {
 	return b
 }
 
+// Join creates an INNER join construct for a multiple-table UPDATE. By default,
+// the onConditions are glued together with AND. Columns in the SET and WHERE
+// clauses should contain the qualifier of their table.
+//		dml.NewUpdate("sales_order").Alias("so").
+//			Join(dml.MakeIdentifier("customer_entity").Alias("ce"), dml.Column("ce.entity_id").Column("so.customer_id")).
+//			AddClauses(dml.Column("so.customer_email").Column("ce.email")).
+//			Where(dml.Column("ce.website_id").PlaceHolder())
+//		// UPDATE `sales_order` AS `so` INNER JOIN `customer_entity` AS `ce` ON (`ce`.`entity_id` = `so`.`customer_id`)
+//		// SET `so`.`customer_email`=`ce`.`email` WHERE (`ce`.`website_id` = ?)
+func (b *Update) Join(table id, onConditions ...*Condition) *Update {
+	b.join("INNER", table, onConditions...)
+	return b
+}
+
+// LeftJoin creates a LEFT join construct for a multiple-table UPDATE. By
+// default, the onConditions are glued together with AND.
+func (b *Update) LeftJoin(table id, onConditions ...*Condition) *Update {
+	b.join("LEFT", table, onConditions...)
+	return b
+}
+
+// RightJoin creates a RIGHT join construct for a multiple-table UPDATE. By
+// default, the onConditions are glued together with AND.
+func (b *Update) RightJoin(table id, onConditions ...*Condition) *Update {
+	b.join("RIGHT", table, onConditions...)
+	return b
+}
+
+// CrossJoin creates a CROSS join construct for a multiple-table UPDATE. By
+// default, the onConditions are glued together with AND.
+func (b *Update) CrossJoin(table id, onConditions ...*Condition) *Update {
+	b.join("CROSS", table, onConditions...)
+	return b
+}
+
+// OptimisticLock enables optimistic locking with an integer version column.
+// The column gets removed from the SetClauses, incremented by one and its
+// current value gets compared in the WHERE clause with a place holder, which
+// can be provided by a ColumnMapper:
+//		UPDATE `user` SET `name`=?, `version`=`version`+1 WHERE (`id` = ?) AND (`version` = ?)
+// If the row has been modified in the meantime, no row gets affected. Use
+// ExecValidateOptimisticLock to check the result.
+func (b *Update) OptimisticLock(versionColumn string) *Update {
+	b.OptimisticLockColumn = versionColumn
+	b.Wheres = append(b.Wheres, Column(versionColumn).Equal().PlaceHolder())
+	return b
+}
+
 // OrderBy appends columns to the ORDER BY statement for ascending sorting. A
 // column gets always quoted if it is a valid identifier otherwise it will be
 // treated as an expression. When you use ORDER BY or GROUP BY to sort a column
@@ -193,7 +245,7 @@ func (b *Update) WithCacheKey(key string, args ...interface{}) *Update {
 
 // ToSQL serialized the Update to a SQL string
 // It returns the string with placeholders and a slice of query arguments
-func (b *Update) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, error) {
+func (b *Update) toSQL(buf *bytes.Buffer, d mysqlDialect, placeHolders []string) ([]string, error) {
 	b.defaultQualifier = b.Table.qualifier()
 	b.source = dmlSourceUpdate
 
@@ -203,28 +255,70 @@ func (b *Update) toSQL(buf *bytes.Buffer, placeHolders []string) ([]string, erro
 	if len(b.SetClauses) == 0 {
 		return nil, errors.Empty.Newf("[dml] Update: No columns specified")
 	}
+	if len(b.Joins) > 0 && (len(b.OrderBys) > 0 || b.LimitValid) {
+		return nil, errors.NotAllowed.Newf("[dml] Update: ORDER BY and LIMIT are not allowed in a multiple-table UPDATE of table %q", b.Table.Name)
+	}
 
+	placeHolders, err := b.writeTxCTEs(buf, d, placeHolders)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	buf.WriteString("UPDATE ")
 	writeStmtID(buf, b.id)
-	_, _ = b.Table.writeQuoted(buf, nil)
+	_, _ = b.Table.writeQuoted(buf, d, nil)
+	placeHolders, err = b.Joins.write(buf, d, placeHolders, b.isWithDBR)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	buf.WriteString(" SET ")
 
-	placeHolders, err := b.SetClauses.writeSetClauses(buf, placeHolders)
+	setClauses := b.setClauses()
+	placeHolders, err = setClauses.writeSetClauses(buf, d, placeHolders)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	if b.OptimisticLockColumn != "" {
+		if len(setClauses) > 0 {
+			buf.WriteString(", ")
+		}
+		Quoter.quote(buf, b.OptimisticLockColumn)
+		buf.WriteByte('=')
+		Quoter.quote(buf, b.OptimisticLockColumn)
+		buf.WriteString("+1")
+	}
 
 	// Write WHERE clause if we have any fragments
-	placeHolders, err = b.Wheres.write(buf, 'w', placeHolders, b.isWithDBR)
+	placeHolders, err = b.Wheres.write(buf, d, 'w', placeHolders, b.isWithDBR)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	sqlWriteOrderBy(buf, b.OrderBys, false)
+	sqlWriteOrderBy(buf, d, b.OrderBys, false)
 	sqlWriteLimitOffset(buf, b.LimitValid, false, 0, b.LimitCount)
+
+	if len(b.ReturningColumns) > 0 {
+		buf.WriteString(" RETURNING ")
+		if placeHolders, err = b.ReturningColumns.writeQuoted(buf, d, placeHolders); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
 	return placeHolders, nil
 }
 
+// setClauses returns the SetClauses without the optimistic lock column.
+func (b *Update) setClauses() Conditions {
+	if b.OptimisticLockColumn == "" {
+		return b.SetClauses
+	}
+	setClauses := make(Conditions, 0, len(b.SetClauses))
+	for _, c := range b.SetClauses {
+		if c.Left != b.OptimisticLockColumn {
+			setClauses = append(setClauses, c)
+		}
+	}
+	return setClauses
+}
+
 // Prepare executes the statement represented by the Update to create a prepared
 // statement. It returns a custom statement type or an error if there was one.
 // Provided arguments or records in the Update are getting ignored. The provided
@@ -261,5 +355,6 @@ func (b *Update) Clone() *Update {
 	c.BuilderBase = b.BuilderBase.Clone()
 	c.BuilderConditional = b.BuilderConditional.Clone()
 	c.SetClauses = b.SetClauses.Clone()
+	c.ReturningColumns = b.ReturningColumns.Clone()
 	return &c
 }
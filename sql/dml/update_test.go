@@ -18,6 +18,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/storage/null"
 	"github.com/corestoreio/pkg/util/assert"
@@ -269,6 +270,63 @@ func TestUpdate_SetColumns(t *testing.T) {
 	)
 }
 
+func TestUpdate_OptimisticLock(t *testing.T) {
+	t.Parallel()
+
+	pRec := &dmlPerson{
+		ID:   12345,
+		Name: "Gopher",
+		Dob:  7,
+	}
+	u := NewUpdate("dml_person").AddColumns("name", "dob").
+		Where(Column("id").PlaceHolder()).OptimisticLock("dob").WithDBR()
+	compareToSQL(t, u.TestWithArgs(Qualify("", pRec)), errors.NoKind,
+		"UPDATE `dml_person` SET `name`=?, `dob`=`dob`+1 WHERE (`id` = ?) AND (`dob` = ?)",
+		"UPDATE `dml_person` SET `name`='Gopher', `dob`=`dob`+1 WHERE (`id` = 12345) AND (`dob` = 7)",
+		"Gopher", int64(12345), int64(7),
+	)
+
+	err := ExecValidateOptimisticLock(sqlmock.NewResult(0, 0), nil, 1)
+	assert.ErrorIsKind(t, errors.Mismatch, err)
+	assert.NoError(t, ExecValidateOptimisticLock(sqlmock.NewResult(0, 2), nil, 2))
+}
+
+func TestUpdate_Join(t *testing.T) {
+	t.Parallel()
+
+	t.Run("qualified SET and WHERE", func(t *testing.T) {
+		u := NewUpdate("sales_order").Alias("so").
+			Join(MakeIdentifier("customer_entity").Alias("ce"), Column("ce.entity_id").Column("so.customer_id")).
+			AddClauses(Column("so.customer_email").Column("ce.email"), Column("so.customer_group_id").Int(3)).
+			Where(Column("ce.website_id").Int(1))
+		compareToSQL(t, u, errors.NoKind,
+			"UPDATE `sales_order` AS `so` INNER JOIN `customer_entity` AS `ce` ON (`ce`.`entity_id` = `so`.`customer_id`) SET `so`.`customer_email`=`ce`.`email`, `so`.`customer_group_id`=3 WHERE (`ce`.`website_id` = 1)",
+			"",
+		)
+	})
+
+	t.Run("argument order", func(t *testing.T) {
+		u := NewUpdate("sales_order").Alias("so").
+			LeftJoin(MakeIdentifier("store").Alias("s"), Column("s.store_id").Column("so.store_id"), Column("s.code").PlaceHolder()).
+			AddColumns("so.status").
+			Where(Column("so.entity_id").PlaceHolder()).WithDBR()
+		compareToSQL(t, u.TestWithArgs("default", "complete", 42), errors.NoKind,
+			"UPDATE `sales_order` AS `so` LEFT JOIN `store` AS `s` ON (`s`.`store_id` = `so`.`store_id`) AND (`s`.`code` = ?) SET `so`.`status`=? WHERE (`so`.`entity_id` = ?)",
+			"UPDATE `sales_order` AS `so` LEFT JOIN `store` AS `s` ON (`s`.`store_id` = `so`.`store_id`) AND (`s`.`code` = 'default') SET `so`.`status`='complete' WHERE (`so`.`entity_id` = 42)",
+			"default", "complete", int64(42),
+		)
+		assert.Exactly(t, []string{"s.code", "so.status", "so.entity_id"}, u.base.qualifiedColumns)
+	})
+
+	t.Run("ORDER BY and LIMIT not allowed", func(t *testing.T) {
+		u := NewUpdate("sales_order").
+			CrossJoin(MakeIdentifier("store")).
+			AddClauses(Column("sales_order.status").Str("closed")).
+			Limit(10)
+		compareToSQL(t, u, errors.NotAllowed, "", "")
+	})
+}
+
 func TestUpdate_DisableBuildCache(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,195 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"strconv"
+
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+// WindowFrameBound defines the start or the end of a window frame.
+type WindowFrameBound struct {
+	expr string
+}
+
+// Predefined frame bounds.
+var (
+	FrameUnboundedPreceding = WindowFrameBound{expr: "UNBOUNDED PRECEDING"}
+	FrameCurrentRow         = WindowFrameBound{expr: "CURRENT ROW"}
+	FrameUnboundedFollowing = WindowFrameBound{expr: "UNBOUNDED FOLLOWING"}
+)
+
+// FramePreceding creates the frame bound "n PRECEDING".
+func FramePreceding(n uint64) WindowFrameBound {
+	return WindowFrameBound{expr: strconv.FormatUint(n, 10) + " PRECEDING"}
+}
+
+// FrameFollowing creates the frame bound "n FOLLOWING".
+func FrameFollowing(n uint64) WindowFrameBound {
+	return WindowFrameBound{expr: strconv.FormatUint(n, 10) + " FOLLOWING"}
+}
+
+// FramePrecedingExpr creates the frame bound "expr PRECEDING". The expression
+// gets written unchanged, for example a place holder `?` or an interval for a
+// RANGE frame:
+//		dml.FramePrecedingExpr("INTERVAL 7 DAY")
+func FramePrecedingExpr(expr string) WindowFrameBound {
+	return WindowFrameBound{expr: expr + " PRECEDING"}
+}
+
+// FrameFollowingExpr creates the frame bound "expr FOLLOWING". See
+// FramePrecedingExpr.
+func FrameFollowingExpr(expr string) WindowFrameBound {
+	return WindowFrameBound{expr: expr + " FOLLOWING"}
+}
+
+// WindowFunction builds a window function with its OVER clause. Use the
+// functions Alias or Condition to add the window function to a SELECT with
+// AddColumnsConditions. Arguments of the function get attached to the
+// condition and interpolated like in any other expression.
+//		dml.NewSelect("id").From("sales_order").AddColumnsConditions(
+//			dml.WindowFunc("ROW_NUMBER()").PartitionBy("customer_id").
+//				OrderByDesc("created_at").Alias("rn"),
+//			dml.WindowFunc("SUM(`grand_total`)").PartitionBy("customer_id").
+//				OrderBy("created_at").Rows(dml.FrameUnboundedPreceding, dml.FrameCurrentRow).Alias("running_total"),
+//		)
+// https://dev.mysql.com/doc/refman/8.0/en/window-functions-usage.html
+// https://mariadb.com/kb/en/library/window-functions/
+type WindowFunction struct {
+	function    string
+	args        []interface{}
+	windowName  string
+	partitionBy ids
+	orderBy     ids
+	frameUnit   string
+	frameStart  WindowFrameBound
+	frameEnd    WindowFrameBound
+}
+
+// WindowFunc creates a new window function for an aggregate or a
+// non-aggregate function. The function gets written unchanged. Place holders
+// in the function get replaced by the arguments.
+//		dml.WindowFunc("NTH_VALUE(`price`, ?)", 2)
+func WindowFunc(function string, args ...interface{}) *WindowFunction {
+	return &WindowFunction{
+		function: function,
+		args:     args,
+	}
+}
+
+// WindowName sets the name of a window defined in a WINDOW clause to inherit
+// its definition. Further PARTITION BY, ORDER BY or frame definitions get
+// appended.
+func (wf *WindowFunction) WindowName(name string) *WindowFunction {
+	wf.windowName = name
+	return wf
+}
+
+// PartitionBy appends columns to the PARTITION BY clause. A column gets
+// quoted if it is a valid identifier otherwise it will be treated as an
+// expression.
+func (wf *WindowFunction) PartitionBy(columns ...string) *WindowFunction {
+	wf.partitionBy = wf.partitionBy.AppendColumns(false, columns...)
+	return wf
+}
+
+// OrderBy appends columns to the ORDER BY clause for ascending sorting. A
+// column name can also contain the suffix words " ASC" or " DESC".
+func (wf *WindowFunction) OrderBy(columns ...string) *WindowFunction {
+	wf.orderBy = wf.orderBy.AppendColumns(false, columns...)
+	return wf
+}
+
+// OrderByDesc appends columns to the ORDER BY clause for descending sorting.
+func (wf *WindowFunction) OrderByDesc(columns ...string) *WindowFunction {
+	wf.orderBy = wf.orderBy.AppendColumns(false, columns...).applySort(len(columns), sortDescending)
+	return wf
+}
+
+// Rows sets a ROWS frame. If end is empty, only the start gets written:
+//		ROWS BETWEEN start AND end
+//		ROWS start
+func (wf *WindowFunction) Rows(start, end WindowFrameBound) *WindowFunction {
+	wf.frameUnit = "ROWS"
+	wf.frameStart = start
+	wf.frameEnd = end
+	return wf
+}
+
+// Range sets a RANGE frame. If end is empty, only the start gets written:
+//		RANGE BETWEEN start AND end
+//		RANGE start
+func (wf *WindowFunction) Range(start, end WindowFrameBound) *WindowFunction {
+	wf.frameUnit = "RANGE"
+	wf.frameStart = start
+	wf.frameEnd = end
+	return wf
+}
+
+// Alias creates the condition with an alias name for the column.
+func (wf *WindowFunction) Alias(a string) *Condition {
+	return wf.Condition().Alias(a)
+}
+
+// Condition creates the expression condition for the use in
+// AddColumnsConditions.
+func (wf *WindowFunction) Condition() *Condition {
+	c := Expr(wf.String())
+	c.Right.args = wf.args
+	return c
+}
+
+// String returns the window function with its OVER clause. Place holders do
+// not get replaced.
+func (wf *WindowFunction) String() string {
+	buf := bufferpool.Get()
+	defer bufferpool.Put(buf)
+
+	buf.WriteString(wf.function)
+	buf.WriteString(" OVER (")
+	sep := ""
+	if wf.windowName != "" {
+		Quoter.quote(buf, wf.windowName)
+		sep = " "
+	}
+	if len(wf.partitionBy) > 0 {
+		buf.WriteString(sep)
+		buf.WriteString("PARTITION BY ")
+		_, _ = wf.partitionBy.writeQuoted(buf, dialect, nil)
+		sep = " "
+	}
+	if len(wf.orderBy) > 0 {
+		buf.WriteString(sep)
+		buf.WriteString("ORDER BY ")
+		_, _ = wf.orderBy.writeQuoted(buf, dialect, nil)
+		sep = " "
+	}
+	if wf.frameUnit != "" && wf.frameStart.expr != "" {
+		buf.WriteString(sep)
+		buf.WriteString(wf.frameUnit)
+		if wf.frameEnd.expr != "" {
+			buf.WriteString(" BETWEEN ")
+			buf.WriteString(wf.frameStart.expr)
+			buf.WriteString(" AND ")
+			buf.WriteString(wf.frameEnd.expr)
+		} else {
+			buf.WriteByte(' ')
+			buf.WriteString(wf.frameStart.expr)
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
@@ -0,0 +1,69 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dml
+
+import (
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestWindowFunction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("String", func(t *testing.T) {
+		tests := []struct {
+			wf   *WindowFunction
+			want string
+		}{
+			{WindowFunc("ROW_NUMBER()"), "ROW_NUMBER() OVER ()"},
+			{
+				WindowFunc("RANK()").PartitionBy("o.customer_id", "store_id").OrderByDesc("created_at").OrderBy("entity_id"),
+				"RANK() OVER (PARTITION BY `o`.`customer_id`, `store_id` ORDER BY `created_at` DESC, `entity_id`)",
+			},
+			{
+				WindowFunc("SUM(`grand_total`)").OrderBy("created_at").Rows(FrameUnboundedPreceding, FrameCurrentRow),
+				"SUM(`grand_total`) OVER (ORDER BY `created_at` ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+			},
+			{
+				WindowFunc("AVG(`qty`)").Rows(FramePreceding(2), FrameFollowing(1)),
+				"AVG(`qty`) OVER (ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING)",
+			},
+			{
+				WindowFunc("COUNT(*)").OrderBy("created_at").Range(FramePrecedingExpr("INTERVAL 7 DAY"), WindowFrameBound{}),
+				"COUNT(*) OVER (ORDER BY `created_at` RANGE INTERVAL 7 DAY PRECEDING)",
+			},
+			{
+				WindowFunc("LAG(`price`)").WindowName("w").OrderBy("created_at DESC"),
+				"LAG(`price`) OVER (`w` ORDER BY `created_at` DESC)",
+			},
+		}
+		for _, test := range tests {
+			assert.Exactly(t, test.want, test.wf.String())
+		}
+	})
+
+	t.Run("AddColumnsConditions", func(t *testing.T) {
+		sel := NewSelect("entity_id").From("sales_order").AddColumnsConditions(
+			WindowFunc("ROW_NUMBER()").PartitionBy("customer_id").OrderByDesc("created_at").Alias("rn"),
+			WindowFunc("NTH_VALUE(`grand_total`, ?)", 2).PartitionBy("customer_id").
+				Rows(FrameUnboundedPreceding, FrameUnboundedFollowing).Alias("second_total"),
+		)
+		compareToSQL2(t, sel, errors.NoKind,
+			"SELECT `entity_id`, ROW_NUMBER() OVER (PARTITION BY `customer_id` ORDER BY `created_at` DESC) AS `rn`, NTH_VALUE(`grand_total`, 2) OVER (PARTITION BY `customer_id` ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS `second_total` FROM `sales_order`",
+		)
+	})
+}
@@ -99,11 +99,7 @@ func (c *ConnPool) With(expressions ...WithCTE) *With {
 	id := c.makeUniqueID()
 	return &With{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: withInitLog(c.Log, expressions, id),
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, withInitLog(c.Log, expressions, id), c.DB),
 		},
 		Subclauses: expressions,
 	}
@@ -114,11 +110,7 @@ func (c *Conn) With(expressions ...WithCTE) *With {
 	id := c.makeUniqueID()
 	return &With{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: withInitLog(c.Log, expressions, id),
-				db:  c.DB,
-			},
+			builderCommon: c.newBuilderCommon(id, withInitLog(c.Log, expressions, id), c.qep()),
 		},
 		Subclauses: expressions,
 	}
@@ -129,11 +121,7 @@ func (tx *Tx) With(expressions ...WithCTE) *With {
 	id := tx.makeUniqueID()
 	return &With{
 		BuilderBase: BuilderBase{
-			builderCommon: builderCommon{
-				id:  id,
-				Log: withInitLog(tx.Log, expressions, id),
-				db:  tx.DB,
-			},
+			builderCommon: tx.newBuilderCommon(id, withInitLog(tx.Log, expressions, id), tx.DB),
 		},
 		Subclauses: expressions,
 	}
@@ -216,7 +204,7 @@ func (b *With) WithCacheKey(key string, args ...interface{}) *With {
 	return b
 }
 
-func (b *With) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err error) {
+func (b *With) toSQL(w *bytes.Buffer, d mysqlDialect, placeHolders []string) (_ []string, err error) {
 	b.source = dmlSourceWith
 	w.WriteString("WITH ")
 	writeStmtID(w, b.id)
@@ -224,7 +212,38 @@ func (b *With) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err er
 		w.WriteString("RECURSIVE ")
 	}
 
-	for i, sc := range b.Subclauses {
+	if placeHolders, err = writeCTEs(w, d, b.Subclauses, b.cacheKey, placeHolders); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch {
+	case b.TopLevel.Select != nil:
+		b.TopLevel.Select.cacheKey = b.cacheKey
+		placeHolders, err = b.TopLevel.Select.toSQL(w, d, placeHolders)
+		return placeHolders, errors.WithStack(err)
+
+	case b.TopLevel.Union != nil:
+		b.TopLevel.Union.cacheKey = b.cacheKey
+		placeHolders, err = b.TopLevel.Union.toSQL(w, d, placeHolders)
+		return placeHolders, errors.WithStack(err)
+
+	case b.TopLevel.Update != nil:
+		b.TopLevel.Update.cacheKey = b.cacheKey
+		placeHolders, err = b.TopLevel.Update.toSQL(w, d, placeHolders)
+		return placeHolders, errors.WithStack(err)
+
+	case b.TopLevel.Delete != nil:
+		b.TopLevel.Delete.cacheKey = b.cacheKey
+		placeHolders, err = b.TopLevel.Delete.toSQL(w, d, placeHolders)
+		return placeHolders, errors.WithStack(err)
+	}
+	return nil, errors.Empty.Newf("[dml] Type With misses a top level statement")
+}
+
+// writeCTEs writes the comma separated common table expressions, each
+// terminated by a new line.
+func writeCTEs(w *bytes.Buffer, d mysqlDialect, ctes []WithCTE, cacheKey string, placeHolders []string) (_ []string, err error) {
+	for i, sc := range ctes {
 		Quoter.quote(w, sc.Name)
 		if len(sc.Columns) > 0 {
 			w.WriteRune(' ')
@@ -240,47 +259,26 @@ func (b *With) toSQL(w *bytes.Buffer, placeHolders []string) (_ []string, err er
 		w.WriteString(" AS (")
 		switch {
 		case sc.Select != nil:
-			sc.Select.cacheKey = b.cacheKey
-			placeHolders, err = sc.Select.toSQL(w, placeHolders)
+			sc.Select.cacheKey = cacheKey
+			placeHolders, err = sc.Select.toSQL(w, d, placeHolders)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
 		case sc.Union != nil:
-			sc.Union.cacheKey = b.cacheKey
-			placeHolders, err = sc.Union.toSQL(w, placeHolders)
+			sc.Union.cacheKey = cacheKey
+			placeHolders, err = sc.Union.toSQL(w, d, placeHolders)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
 		}
 		w.WriteRune(')')
-		if i < len(b.Subclauses)-1 {
+		if i < len(ctes)-1 {
 			w.WriteRune(',')
 		}
 		w.WriteRune('\n')
 	}
 
-	switch {
-	case b.TopLevel.Select != nil:
-		b.TopLevel.Select.cacheKey = b.cacheKey
-		placeHolders, err = b.TopLevel.Select.toSQL(w, placeHolders)
-		return placeHolders, errors.WithStack(err)
-
-	case b.TopLevel.Union != nil:
-		b.TopLevel.Union.cacheKey = b.cacheKey
-		placeHolders, err = b.TopLevel.Union.toSQL(w, placeHolders)
-		return placeHolders, errors.WithStack(err)
-
-	case b.TopLevel.Update != nil:
-		b.TopLevel.Update.cacheKey = b.cacheKey
-		placeHolders, err = b.TopLevel.Update.toSQL(w, placeHolders)
-		return placeHolders, errors.WithStack(err)
-
-	case b.TopLevel.Delete != nil:
-		b.TopLevel.Delete.cacheKey = b.cacheKey
-		placeHolders, err = b.TopLevel.Delete.toSQL(w, placeHolders)
-		return placeHolders, errors.WithStack(err)
-	}
-	return nil, errors.Empty.Newf("[dml] Type With misses a top level statement")
+	return placeHolders, nil
 }
 
 // Prepare executes the statement represented by the `With` to create a prepared
@@ -129,13 +129,13 @@ func TestNewWith(t *testing.T) {
 				dml.NewSelect().Unsafe().AddColumns("1"),
 				dml.NewSelect().Unsafe().AddColumns("1+n").From("my_cte").Where(dml.Column("n").Less().Int(6)),
 			).All()},
-			// UPDATE statement is wrong because we're missing a JOIN which is not yet implemented.
-		).Update(dml.NewUpdate("numbers").AddClauses(dml.Column("n").Int(0)).Where(dml.Expr("n=my_cte.n*my_cte.n"))).
+		).Update(dml.NewUpdate("numbers").CrossJoin(dml.MakeIdentifier("my_cte")).
+			AddClauses(dml.Column("numbers.n").Int(0)).Where(dml.Expr("numbers.n=my_cte.n*my_cte.n"))).
 			Recursive()
 
 		compareToSQL(t, cte, errors.NoKind,
-			"WITH RECURSIVE `my_cte` (`n`) AS ((SELECT 1)\nUNION ALL\n(SELECT 1+n FROM `my_cte` WHERE (`n` < 6)))\nUPDATE `numbers` SET `n`=0 WHERE (n=my_cte.n*my_cte.n)",
-			"WITH RECURSIVE `my_cte` (`n`) AS ((SELECT 1)\nUNION ALL\n(SELECT 1+n FROM `my_cte` WHERE (`n` < 6)))\nUPDATE `numbers` SET `n`=0 WHERE (n=my_cte.n*my_cte.n)",
+			"WITH RECURSIVE `my_cte` (`n`) AS ((SELECT 1)\nUNION ALL\n(SELECT 1+n FROM `my_cte` WHERE (`n` < 6)))\nUPDATE `numbers` CROSS JOIN `my_cte` SET `numbers`.`n`=0 WHERE (numbers.n=my_cte.n*my_cte.n)",
+			"WITH RECURSIVE `my_cte` (`n`) AS ((SELECT 1)\nUNION ALL\n(SELECT 1+n FROM `my_cte` WHERE (`n` < 6)))\nUPDATE `numbers` CROSS JOIN `my_cte` SET `numbers`.`n`=0 WHERE (numbers.n=my_cte.n*my_cte.n)",
 		)
 		// WITH RECURSIVE my_cte(n) AS
 		//(
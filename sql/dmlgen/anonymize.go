@@ -0,0 +1,147 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmlgen
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/util/codegen"
+	"github.com/corestoreio/pkg/util/strs"
+)
+
+// anonymizeStmt contains the generated Go code of one UPDATE statement of an
+// Anonymize* function.
+type anonymizeStmt struct {
+	table *Table
+	// where contains the Go code of the dml.Condition selecting the rows.
+	where string
+}
+
+// fnDBMAnonymize generates for a table with PII columns the function
+// Anonymize<EntityName> which updates all PII columns of the table and of the
+// tables referencing it, found via the foreign key graph. The referencing
+// tables get updated first.
+func (t *Table) fnDBMAnonymize(mainGen *codegen.Go, g *Generator) {
+	if len(t.piiColumns) == 0 || t.Table.IsView() || !t.hasFeature(g, FeatureDB|FeatureDBUpdate) {
+		return
+	}
+	pks := t.Table.Columns.PrimaryKeys()
+	if pks.Len() != 1 {
+		return
+	}
+	pk := pks[0]
+
+	rootWhere := "dml.Column(`" + pk.Field + "`).Equal().PlaceHolder()"
+	var stmts []anonymizeStmt
+	g.anonymizeChildren(t.Table.Name, pk.Field, rootWhere, map[string]bool{t.Table.Name: true}, &stmts)
+	stmts = append(stmts, anonymizeStmt{table: t, where: rootWhere})
+
+	mainGen.C(`Anonymize`+t.EntityName(), `anonymizes the PII columns of table`, t.Table.Name, `and of the tables referencing it for the primary key. Referencing tables get updated first. Auto generated.`)
+	mainGen.Pln(`func (dbm DBM) Anonymize`+t.EntityName(), `(ctx context.Context, id `, g.goType(pk), `) (err error) {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`var fv interface{}`)
+		mainGen.Pln(`_ = fv`)
+		for _, stmt := range stmts {
+			stmt.table.anonymizeStmt(mainGen, stmt.where)
+		}
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+}
+
+// anonymizeChildren walks depth-first through the tables referencing the
+// parent table and appends the statements of the children before the
+// statement of the child itself. Tables not known to the Generator and cyclic
+// references get skipped.
+func (g *Generator) anonymizeChildren(parent, rootPK, parentWhere string, path map[string]bool, stmts *[]anonymizeStmt) {
+	refs := append([]*ddl.KeyColumnUsage(nil), g.kcuRev[parent].Data...)
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].ReferencedTableName.Data != refs[j].ReferencedTableName.Data {
+			return refs[i].ReferencedTableName.Data < refs[j].ReferencedTableName.Data
+		}
+		return refs[i].ReferencedColumnName.Data < refs[j].ReferencedColumnName.Data
+	})
+	for _, ref := range refs {
+		// reversed: TableName is the parent and ReferencedTableName the child.
+		child, childCol := ref.ReferencedTableName.Data, ref.ReferencedColumnName.Data
+		ct, ok := g.Tables[child]
+		if !ok || path[child] || ct.Table.IsView() {
+			continue
+		}
+
+		var where string
+		if len(path) == 1 && ref.ColumnName == rootPK {
+			where = "dml.Column(`" + childCol + "`).Equal().PlaceHolder()"
+		} else {
+			where = "dml.Column(`" + childCol + "`).In().Sub(dml.NewSelect(`" + ref.ColumnName + "`).From(TableName" +
+				strs.ToGoCamelCase(parent) + ").Where(" + parentWhere + "))"
+		}
+
+		path[child] = true
+		g.anonymizeChildren(child, rootPK, where, path, stmts)
+		delete(path, child)
+
+		if len(ct.piiColumns) > 0 {
+			*stmts = append(*stmts, anonymizeStmt{table: ct, where: where})
+		}
+	}
+}
+
+func (t *Table) anonymizeStmt(mainGen *codegen.Go, where string) {
+	cols := make([]string, 0, len(t.piiColumns))
+	for c := range t.piiColumns {
+		cols = append(cols, c)
+	}
+	sort.Strings(cols)
+
+	var clauses []string
+	mainGen.Pln(`{ //`, t.Table.Name)
+	{
+		mainGen.In()
+		mainGen.Pln(`args := make([]interface{}, 0, `, strconv.Itoa(len(cols)+1), `)`)
+		for _, cn := range cols {
+			c := t.Table.Columns.ByField(cn)
+			switch strategy := t.piiColumns[cn]; {
+			case strategy == "null":
+				clauses = append(clauses, "dml.Column(`"+cn+"`).Expr(`NULL`),")
+			case strategy == "hash":
+				clauses = append(clauses, "dml.Column(`"+cn+"`).Expr(\"LEFT(SHA2(`"+cn+"`,256),"+strconv.FormatInt(c.CharMaxLength.Int64, 10)+")\"),")
+			default: // faker
+				mainGen.Pln(`if fv, err = dbm.anonymizeFake(`, strconv.Quote(strings.TrimPrefix(strategy, "faker:")), `,`, strconv.FormatInt(c.CharMaxLength.Int64, 10), `); err != nil {`)
+				mainGen.Pln(`	return errors.WithStack(err)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`args = append(args, fv)`)
+				clauses = append(clauses, "dml.Column(`"+cn+"`).PlaceHolder(),")
+			}
+		}
+		mainGen.Pln(`args = append(args, id)`)
+		mainGen.Pln(`if _, err = dbm.Tables.MustTable(TableName`+t.EntityName(), `).Update().AddClauses(`)
+		for _, cl := range clauses {
+			mainGen.Pln(`	` + cl)
+		}
+		mainGen.Pln(`).Where(`)
+		mainGen.Pln(`	` + where + `,`)
+		mainGen.Pln(`).WithDBR().ExecContext(ctx, args...); err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+}
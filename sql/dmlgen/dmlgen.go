@@ -88,6 +88,15 @@ type Generator struct {
 	// "referencedTable.referencedColumn":"mainTable.mainColumn"
 	krsExclude map[string]bool
 	krsInclude map[string]bool
+	// protoPrevious contains the field numbers of the messages of the
+	// previously generated .proto file. Key is the message name.
+	protoPrevious map[string]*protoMessage
+	// typeMappings contains the custom Go types of function WithTypeMapping.
+	// Key is the lower case MySQL column type or data type.
+	typeMappings map[string]*typeMapping
+	// eavEntities contains the EAV entities of WithEAVEntity sorted by type
+	// code.
+	eavEntities []EAVEntity
 }
 
 // Option represents a sortable option for the NewGenerator function. Each option
@@ -131,12 +140,15 @@ func WithTableConfig(tableName string, opt *TableConfig) (o Option) {
 			return errors.NotFound.Newf("[dmlgen] WithTableConfig: Table %q not found.", tableName)
 		}
 		opt.applyEncoders(t, g)
+		opt.applyJSON(t, g)
 		opt.applyStructTags(t, g)
 		opt.applyCustomStructTags(t)
 		opt.applyPrivateFields(t)
 		opt.applyComments(t)
 		opt.applyColumnAliases(t)
 		opt.applyUniquifiedColumns(t)
+		opt.applyPIIColumns(t)
+		opt.applyOptimisticLockColumn(t)
 		t.featuresInclude = opt.FeaturesInclude | g.defaultTableConfig.FeaturesInclude
 		t.featuresExclude = opt.FeaturesExclude | g.defaultTableConfig.FeaturesExclude
 		t.fieldMapFn = opt.FieldMapFn
@@ -364,6 +376,10 @@ func WithTablesFromDB(ctx context.Context, db *dml.ConnPool, tables ...string) (
 type SerializerConfig struct {
 	PackageImportPath string
 	Headers           []string
+	// PreviousProtoFile defines the path to the previously generated .proto
+	// file. If set and the file exists, the field numbers of the messages get
+	// kept stable. See WithProtobuf.
+	PreviousProtoFile string
 }
 
 // WithProtobuf enables protocol buffers as a serialization method. Argument
@@ -372,6 +388,15 @@ type SerializerConfig struct {
 // types. E.g. uint32 minimum instead of uint8/uint16. So if the Generator gets
 // created multiple times to separate the creation of code, the WithProtobuf
 // function must get set for Generator objects. See package store.
+//
+// Field numbers default to the column position in the table. Once the schema
+// changes, e.g. a column gets dropped or added in between, the numbers would
+// change and break the wire compatibility with already serialized data. To
+// keep the numbers stable across regenerations set
+// SerializerConfig.PreviousProtoFile to the path of the former generated
+// .proto file. Existing fields keep their numbers, new fields get the next
+// free number of the message and the numbers of removed fields get reserved.
+// A non-existing file gets ignored, which is the case for the first run.
 func WithProtobuf(sc *SerializerConfig) (opt Option) {
 	_, pkg := filepath.Split(sc.PackageImportPath)
 
@@ -390,7 +415,19 @@ func WithProtobuf(sc *SerializerConfig) (opt Option) {
 				"(gogoproto.goproto_unrecognized_all) = false",
 			}
 		}
-		return nil
+		if sc.PreviousProtoFile == "" {
+			return nil
+		}
+		f, err := os.Open(sc.PreviousProtoFile)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		g.protoPrevious, err = parseProtoFieldNumbers(f)
+		return errors.Wrapf(err, "[dmlgen] WithProtobuf failed to parse file %q", sc.PreviousProtoFile)
 	}
 	return opt
 }
@@ -442,6 +479,94 @@ func WithCustomCodeFunc(marker string, fn func(g *Generator, t *Table, w io.Writ
 	return opt
 }
 
+// WithTypeMapping maps a MySQL type to a custom Go type. Argument sqlType
+// matches either the full column type, e.g. `tinyint(1)` or `decimal(12,4)`,
+// or the data type, e.g. `decimal`. The full column type takes precedence.
+// The goType gets used for the struct fields and all other generated code, for
+// NULL and NOT NULL columns. The importPath, if not empty, gets added to the
+// imports of the generated file. The scanTemplate is a text/template which
+// must generate a Go expression of type *dml.ColumnMap to read and write the
+// field. Available variables are {{.CM}}, the *dml.ColumnMap, and {{.Field}},
+// the struct field. An empty scanTemplate derives the ColumnMap method from
+// the goType, which works for the types supported by dml.ColumnMap, e.g.
+// `bool` or `null.Bool`. Serializer types are not affected.
+//		dmlgen.WithTypeMapping("decimal", "money.Money", "github.com/acme/money",
+//			"{{.CM}}.Decimal((*null.Decimal)(&{{.Field}}))")
+//		dmlgen.WithTypeMapping("tinyint(1)", "bool", "", "")
+func WithTypeMapping(sqlType, goType, importPath, scanTemplate string) (opt Option) {
+	opt.sortOrder = 114
+	opt.fn = func(g *Generator) error {
+		tm, err := newTypeMapping(goType, scanTemplate)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if g.typeMappings == nil {
+			g.typeMappings = make(map[string]*typeMapping)
+		}
+		g.typeMappings[strings.ToLower(sqlType)] = tm
+		if importPath == "" {
+			return nil
+		}
+		for _, ip := range g.ImportPaths {
+			if ip == importPath {
+				return nil
+			}
+		}
+		g.ImportPaths = append(g.ImportPaths, importPath)
+		return nil
+	}
+	return opt
+}
+
+// WithEAVEntity generates for an EAV entity type a flattened Go struct, its
+// collection and the functions DBLoad and DBSave. The struct contains the link
+// field, the store ID and one field per attribute; the attributes with the
+// backend type static get skipped because they are columns of the entity
+// table. Loading falls back to the default value of store zero when the store
+// has no own value. The Go type name derives from the type code, e.g.
+// catalog_product becomes CatalogProduct and must not collide with the
+// generated table entities.
+//		dmlgen.WithEAVEntity(dmlgen.EAVEntity{
+//			TypeID:   4,
+//			TypeCode: "catalog_product",
+//			Table:    "catalog_product_entity",
+//			Attributes: []eav.Attribute{
+//				{Code: "name", BackendType: eav.TypeVarchar},
+//				{Code: "status", BackendType: eav.TypeInt},
+//			},
+//		})
+func WithEAVEntity(entities ...EAVEntity) (opt Option) {
+	opt.sortOrder = 115
+	opt.fn = func(g *Generator) error {
+		for _, e := range entities {
+			if err := g.addEAVEntity(e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	return opt
+}
+
+// WithEAVEntityFromDB loads the EAV entity types and their attributes from the
+// tables eav_entity_type and eav_attribute, see WithEAVEntity.
+func WithEAVEntityFromDB(ctx context.Context, db dml.Querier, entityTypeCodes ...string) (opt Option) {
+	opt.sortOrder = 115
+	opt.fn = func(g *Generator) error {
+		for _, code := range entityTypeCodes {
+			e, err := LoadEAVEntity(ctx, db, code)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if err := g.addEAVEntity(e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	return opt
+}
+
 // NewGenerator creates a new instance of the SQL table code generator. The order
 // of the applied options does not matter as they are getting sorted internally.
 func NewGenerator(packageImportPath string, opts ...Option) (*Generator, error) {
@@ -453,16 +578,21 @@ func NewGenerator(packageImportPath string, opts ...Option) (*Generator, error)
 		ImportPaths: []string{
 			"context",
 			"database/sql",
+			"encoding/base64",
 			"encoding/json",
 			"fmt",
 			"io",
 			"sort",
+			"strconv",
+			"sync",
 			"time",
 
 			"github.com/corestoreio/errors",
 			"github.com/corestoreio/pkg/sql/ddl",
 			"github.com/corestoreio/pkg/sql/dml",
+			"github.com/corestoreio/pkg/sql/dml/eav",
 			"github.com/corestoreio/pkg/storage/null",
+			"github.com/corestoreio/pkg/util/byteconv",
 			"github.com/corestoreio/pkg/util/cstrace",
 			"go.opentelemetry.io/otel/api/trace",
 		},
@@ -681,6 +811,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 		proto.Pln(`message`, t.EntityName(), `{`)
 		{
 			proto.In()
+			pf := newProtoFieldNumbers(g.protoPrevious[t.EntityName()])
 			var lastColumnPos uint64
 			t.Table.Columns.Each(func(c *ddl.Column) {
 				if t.IsFieldPublic(c.Field) {
@@ -693,7 +824,8 @@ func (g *Generator) generateProto(w io.Writer) error {
 						optionConcret = `[` + strings.Join(options, ",") + `]`
 					}
 					// extend here with a custom code option, if someone needs
-					proto.Pln(serType, strs.ToGoCamelCase(c.Field), `=`, c.Pos, optionConcret+`;`)
+					fieldName := strs.ToGoCamelCase(c.Field)
+					proto.Pln(serType, fieldName, `=`, pf.number(fieldName, c.Pos), optionConcret+`;`)
 					lastColumnPos = c.Pos
 				}
 			})
@@ -715,7 +847,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 						hasTable := g.Tables[kcuce.ReferencedTableName.Data] != nil
 						if isOneToMany && hasTable && isRelationAllowed {
 							proto.Pln(collectionName(kcuce.ReferencedTableName.Data), fieldMapFn(collectionName(kcuce.ReferencedTableName.Data)),
-								"=", lastColumnPos, ";",
+								"=", pf.number(fieldMapFn(collectionName(kcuce.ReferencedTableName.Data)), lastColumnPos), ";",
 								"// 1:M", kcuce.TableName+"."+kcuce.ColumnName, "=>", kcuce.ReferencedTableName.Data+"."+kcuce.ReferencedColumnName.Data)
 							lastColumnPos++
 						}
@@ -724,7 +856,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 						isOneToOne := g.krs.IsOneToOne(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data)
 						if isOneToOne && hasTable && isRelationAllowed {
 							proto.Pln(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data), fieldMapFn(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data)),
-								"=", lastColumnPos, ";",
+								"=", pf.number(fieldMapFn(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data)), lastColumnPos), ";",
 								"// 1:1", kcuce.TableName+"."+kcuce.ColumnName, "=>", kcuce.ReferencedTableName.Data+"."+kcuce.ReferencedColumnName.Data)
 							lastColumnPos++
 						}
@@ -744,7 +876,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 						hasTable := g.Tables[kcuce.ReferencedTableName.Data] != nil
 						if isOneToMany && hasTable && isRelationAllowed {
 							proto.Pln(collectionName(kcuce.ReferencedTableName.Data), fieldMapFn(collectionName(kcuce.ReferencedTableName.Data)),
-								"=", lastColumnPos, ";",
+								"=", pf.number(fieldMapFn(collectionName(kcuce.ReferencedTableName.Data)), lastColumnPos), ";",
 								"// Reversed 1:M", kcuce.TableName+"."+kcuce.ColumnName, "=>", kcuce.ReferencedTableName.Data+"."+kcuce.ReferencedColumnName.Data)
 							lastColumnPos++
 						}
@@ -753,7 +885,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 						isOneToOne := g.krs.IsOneToOne(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data)
 						if isOneToOne && hasTable && isRelationAllowed {
 							proto.Pln(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data), fieldMapFn(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data)),
-								"=", lastColumnPos, ";",
+								"=", pf.number(fieldMapFn(strs.ToGoCamelCase(kcuce.ReferencedTableName.Data)), lastColumnPos), ";",
 								"// Reversed 1:1", kcuce.TableName+"."+kcuce.ColumnName, "=>", kcuce.ReferencedTableName.Data+"."+kcuce.ReferencedColumnName.Data)
 							lastColumnPos++
 						}
@@ -767,7 +899,7 @@ func (g *Generator) generateProto(w io.Writer) error {
 						// case MANY-TO-MANY
 						if isRelationAllowed && targetTbl != "" && targetColumn != "" {
 							proto.Pln(collectionName(targetTbl), fieldMapFn(collectionName(targetTbl)),
-								"=", lastColumnPos, ";",
+								"=", pf.number(fieldMapFn(collectionName(targetTbl)), lastColumnPos), ";",
 								"// Reversed M:N", kcuce.TableName+"."+kcuce.ColumnName, "via", kcuce.ReferencedTableName.Data+"."+kcuce.ReferencedColumnName.Data,
 								"=>", targetTbl+"."+targetColumn)
 							lastColumnPos++
@@ -776,6 +908,9 @@ func (g *Generator) generateProto(w io.Writer) error {
 					}
 				}
 			}
+			for _, r := range pf.reserved() {
+				proto.Pln(r)
+			}
 			proto.Out()
 		}
 		proto.Pln(`}`)
@@ -830,6 +965,7 @@ func (g *Generator) GenerateGo(wMain, wTest io.Writer) error {
 		t.fnEntityEmpty(mainGen, g)
 		t.fnEntityIsSet(mainGen, g)
 		t.fnEntityGetSetPrivateFields(mainGen, g)
+		t.fnEntityJSON(mainGen, g)
 		t.fnEntityValidate(mainGen, g)
 		t.fnEntityWriteTo(mainGen, g)
 
@@ -845,11 +981,20 @@ func (g *Generator) GenerateGo(wMain, wTest io.Writer) error {
 		t.fnCollectionEach(mainGen, g)
 		t.fnCollectionFilter(mainGen, g)
 		t.fnCollectionInsert(mainGen, g)
+		t.fnCollectionJSON(mainGen, g)
 		t.fnCollectionSwap(mainGen, g)
 		t.fnCollectionUniqueGetters(mainGen, g)
 		t.fnCollectionUniquifiedGetters(mainGen, g)
 		t.fnCollectionValidate(mainGen, g)
 		t.fnCollectionWriteTo(mainGen, g)
+
+		t.fnDBFilters(mainGen, g)
+		t.fnDBMAnonymize(mainGen, g)
+		t.fnDBLoadRelations(mainGen, g)
+	}
+
+	for _, e := range g.eavEntities {
+		g.fnEAVEntity(mainGen, e)
 	}
 
 	// now figure out all used package names in the buffer.
@@ -901,6 +1046,8 @@ func (g *Generator) fnCreateDBM(mainGen *codegen.Go, tbls tables) {
 		mainGen.Pln(tbls.hasFeature(g, FeatureDBUpdate), `InitUpdateFn         func(*dml.Update) *dml.Update`)
 		mainGen.Pln(tbls.hasFeature(g, FeatureDBDelete), `InitDeleteFn         func(*dml.Delete) *dml.Delete`)
 		mainGen.Pln(tbls.hasFeature(g, FeatureDBInsert|FeatureDBUpsert), `InitInsertFn         func(*dml.Insert) *dml.Insert`)
+		mainGen.Pln(tbls.hasPIIColumns(), `AnonymizeFakerFn     func(category string, maxLen int) (interface{}, error) // see TableConfig.PIIColumns`)
+		mainGen.Pln(tbls.hasRelationLoaders(g), `RelationBatchSize    int // maximum amount of keys in the IN clause of the Load<Relation> functions, defaults to 500`)
 		for _, tbl := range tbls {
 			mainGen.Pln(`event`+tbl.EntityName()+`Func [dml.EventFlagMax][]func(context.Context, `, codegen.SkipWS(`*`, tbl.CollectionName(), `, *`, tbl.EntityName()), `) error`)
 		}
@@ -932,13 +1079,16 @@ It panics if the event argument is larger than dml.EventFlagMax.`)
 		mainGen.Pln(codegen.SkipWS(`func (dbm DBM) event`, tbl.EntityName(), `Func(ctx context.Context, ef dml.EventFlag, ec `, codegen.SkipWS(`*`, tbl.CollectionName()), `, e `, codegen.SkipWS(`*`, tbl.EntityName()), `) error`), ` {`)
 		{
 			mainGen.In()
-			mainGen.Pln(`if len(dbm.option.`, codegen.SkipWS(`event`, tbl.EntityName(), `Func`), `[ef]) == 0 || dml.EventsAreSkipped(ctx) {`)
+			mainGen.Pln(`if dml.EventsAreSkipped(ctx) {`)
 			mainGen.In()
 			{
 				mainGen.Pln(`return nil`)
 			}
 			mainGen.Out()
 			mainGen.Pln(`}`)
+			mainGen.Pln(`if err := `, codegen.SkipWS(`hook`, tbl.EntityName()), `(ctx, ef, ec, e); err != nil {
+				return errors.WithStack(err)
+			}`)
 
 			mainGen.Pln(`for _, fn := range dbm.option.`, codegen.SkipWS(`event`, tbl.EntityName(), `Func`), `[ef] {`)
 			{
@@ -955,6 +1105,73 @@ It panics if the event argument is larger than dml.EventFlagMax.`)
 		mainGen.Pln(`}`)
 	} // </event dispatcher>
 
+	// <entity hooks>
+	for _, tbl := range tbls {
+		en := tbl.EntityName()
+		mainGen.C(codegen.SkipWS(en, `BeforeInserter`), `gets called before inserting a`, en, `or a`, tbl.CollectionName(),
+			`into the database. Implement it in a non-generated file.`)
+		mainGen.Pln(`type `, codegen.SkipWS(en, `BeforeInserter`), ` interface {
+			BeforeInsert(ctx context.Context) error
+		}`)
+		mainGen.C(codegen.SkipWS(en, `BeforeUpdater`), `gets called before updating a`, en, `or a`, tbl.CollectionName(),
+			`in the database. Implement it in a non-generated file.`)
+		mainGen.Pln(`type `, codegen.SkipWS(en, `BeforeUpdater`), ` interface {
+			BeforeUpdate(ctx context.Context) error
+		}`)
+		mainGen.C(codegen.SkipWS(en, `AfterLoader`), `gets called after loading a`, en, `or a`, tbl.CollectionName(),
+			`from the database. Implement it in a non-generated file.`)
+		mainGen.Pln(`type `, codegen.SkipWS(en, `AfterLoader`), ` interface {
+			AfterLoad(ctx context.Context) error
+		}`)
+
+		mainGen.C(codegen.SkipWS(`hook`, en), `calls the optional hook interfaces of each entity. The hooks run before the event functions added via`, codegen.SkipWS(`DBMOption.AddEvent`, en), `and get skipped with dml.EventsAreSkipped.`)
+		mainGen.Pln(codegen.SkipWS(`func hook`, en, `(ctx context.Context, ef dml.EventFlag, ec `, codegen.SkipWS(`*`, tbl.CollectionName()), `, e `, codegen.SkipWS(`*`, en), `) error`), ` {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`if ec != nil {
+				for _, e := range ec.Data {
+					if err := `, codegen.SkipWS(`hook`, en), `(ctx, ef, nil, e); err != nil {
+						return errors.WithStack(err)
+					}
+				}
+				return nil
+			}
+			if e == nil {
+				return nil
+			}
+			switch ef {
+			case dml.EventFlagBeforeInsert:
+				if h, ok := interface{}(e).(`, codegen.SkipWS(en, `BeforeInserter`), `); ok {
+					return errors.WithStack(h.BeforeInsert(ctx))
+				}
+			case dml.EventFlagBeforeUpdate:
+				if h, ok := interface{}(e).(`, codegen.SkipWS(en, `BeforeUpdater`), `); ok {
+					return errors.WithStack(h.BeforeUpdate(ctx))
+				}
+			case dml.EventFlagAfterSelect:
+				if h, ok := interface{}(e).(`, codegen.SkipWS(en, `AfterLoader`), `); ok {
+					return errors.WithStack(h.AfterLoad(ctx))
+				}
+			}
+			return nil`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+	} // </entity hooks>
+
+	if tbls.hasPIIColumns() {
+		mainGen.Pln(`func (dbm DBM) anonymizeFake(category string, maxLen int) (interface{}, error) {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`if dbm.option.AnonymizeFakerFn == nil {`)
+			mainGen.Pln(`	return nil, errors.NotImplemented.Newf("DBMOption.AnonymizeFakerFn not set for category %q", category)`)
+			mainGen.Pln(`}`)
+			mainGen.Pln(`return dbm.option.AnonymizeFakerFn(category, maxLen)`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+	}
+
 	mainGen.C(`NewDBManager returns a goified version of the MySQL/MariaDB table schema for the tables: `, tableNames, `Auto generated by dmlgen.`)
 	mainGen.Pln(`func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {`)
 	{
@@ -970,6 +1187,8 @@ It panics if the event argument is larger than dml.EventFlagMax.`)
 			`	if dbmo.InitDeleteFn == nil { dbmo.InitDeleteFn = func(s *dml.Delete) *dml.Delete { return s; }; } `)
 		mainGen.Pln(tbls.hasFeature(g, FeatureDBInsert|FeatureDBUpsert),
 			`	if dbmo.InitInsertFn == nil { dbmo.InitInsertFn = func(s *dml.Insert) *dml.Insert { return s; }; } `)
+		mainGen.Pln(tbls.hasRelationLoaders(g),
+			`	if dbmo.RelationBatchSize <= 0 { dbmo.RelationBatchSize = 500; } `)
 
 		{
 			mainGen.Pln(`err = tbls.Options(`)
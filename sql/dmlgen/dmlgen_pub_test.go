@@ -88,6 +88,7 @@ func writeFile(t *testing.T, outFile string, wFn func(io.Writer, io.Writer) erro
 // dmltestgenerated directory for manual review for different tables. This test
 // also analyzes the foreign keys pointing to customer_entity.
 func TestNewGenerator_Protobuf_Json(t *testing.T) {
+	dmltest.SkipUnsupported(t, dmltest.FeatureForeignKeys)
 	db := dmltest.MustConnectDB(t)
 	defer dmltest.Close(t, db)
 
@@ -389,6 +390,7 @@ func TestNewGenerator_NoDB(t *testing.T) {
 }
 
 func TestNewGenerator_ReversedForeignKeys(t *testing.T) {
+	dmltest.SkipUnsupported(t, dmltest.FeatureForeignKeys)
 	db := dmltest.MustConnectDB(t)
 	defer dmltest.Close(t, db)
 
@@ -451,6 +453,7 @@ func TestNewGenerator_ReversedForeignKeys(t *testing.T) {
 }
 
 func TestNewGenerator_MToMForeignKeys(t *testing.T) {
+	dmltest.SkipUnsupported(t, dmltest.FeatureForeignKeys)
 	db := dmltest.MustConnectDB(t)
 	defer dmltest.Close(t, db)
 
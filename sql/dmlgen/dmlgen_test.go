@@ -1,9 +1,19 @@
 package dmlgen
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/sql/dml/eav"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/storage/null"
 	"github.com/corestoreio/pkg/util/assert"
 )
 
@@ -56,3 +66,282 @@ func TestGenerator_isAllowedRelationship(t *testing.T) {
 		assert.True(t, g.isAllowedRelationship("athlete_team", "team_id", "athlete_team_member", "team_id"))
 	})
 }
+
+func TestGenerator_PIIColumns(t *testing.T) {
+	newGen := func(pii map[string]string) (*Generator, error) {
+		return NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+			WithTable("customer_entity", ddl.Columns{
+				&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+				&ddl.Column{Field: "email", Pos: 2, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+				&ddl.Column{Field: "firstname", Pos: 3, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+			}),
+			WithTable("customer_address_entity", ddl.Columns{
+				&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+				&ddl.Column{Field: "parent_id", Pos: 2, Null: "YES", DataType: "int", ColumnType: "int(10) unsigned"},
+				&ddl.Column{Field: "telephone", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(64), ColumnType: "varchar(64)"},
+			}),
+			WithTableConfig("customer_entity", &TableConfig{PIIColumns: pii}),
+			WithTableConfig("customer_address_entity", &TableConfig{
+				PIIColumns: map[string]string{"telephone": "faker:phone"},
+			}),
+		)
+	}
+
+	t.Run("column not found", func(t *testing.T) {
+		_, err := newGen(map[string]string{"lastname": "null"})
+		assert.ErrorIsKind(t, errors.NotFound, err)
+	})
+	t.Run("strategy not supported", func(t *testing.T) {
+		_, err := newGen(map[string]string{"email": "rot13"})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+	t.Run("hash requires string", func(t *testing.T) {
+		_, err := newGen(map[string]string{"entity_id": "hash"})
+		assert.ErrorIsKind(t, errors.NotAcceptable, err)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		g, err := newGen(map[string]string{"email": "hash", "firstname": "null"})
+		assert.NoError(t, err)
+		g.kcuRev = ddl.ReverseKeyColumnUsage(map[string]ddl.KeyColumnUsageCollection{
+			"customer_address_entity": {Data: []*ddl.KeyColumnUsage{{
+				ConstraintName:       "CUSTOMER_ADDRESS_ENTITY_PARENT_ID_CUSTOMER_ENTITY_ENTITY_ID",
+				TableName:            "customer_address_entity",
+				ColumnName:           "parent_id",
+				ReferencedTableName:  null.MakeString("customer_entity"),
+				ReferencedColumnName: null.MakeString("entity_id"),
+			}}},
+		})
+
+		var wMain, wTest bytes.Buffer
+		assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+		src := wMain.String()
+		assert.Contains(t, src, "func (dbm DBM) anonymizeFake(category string, maxLen int) (interface{}, error) {")
+		assert.Contains(t, src, "func (dbm DBM) AnonymizeCustomerEntity(ctx context.Context, id uint32) (err error) {")
+		assert.Contains(t, src, "func (dbm DBM) AnonymizeCustomerAddressEntity(ctx context.Context, id uint32) (err error) {")
+		assert.Contains(t, src, "if fv, err = dbm.anonymizeFake(\"phone\", 64); err != nil {")
+		assert.Contains(t, src, "dml.Column(`parent_id`).Equal().PlaceHolder(),")
+		assert.Contains(t, src, "dml.Column(`email`).Expr(\"LEFT(SHA2(`email`,256),255)\"),")
+		assert.Contains(t, src, "dml.Column(`firstname`).Expr(`NULL`),")
+	})
+}
+
+func TestGenerator_LoadRelations(t *testing.T) {
+	g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+		WithTable("customer_entity", ddl.Columns{
+			&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+			&ddl.Column{Field: "email", Pos: 2, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		}),
+		WithTable("customer_address_entity", ddl.Columns{
+			&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+			&ddl.Column{Field: "parent_id", Pos: 2, Null: "YES", DataType: "int", Key: "MUL", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "city", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		}),
+	)
+	assert.NoError(t, err)
+
+	g.kcu = map[string]ddl.KeyColumnUsageCollection{
+		"customer_address_entity": {Data: []*ddl.KeyColumnUsage{{
+			ConstraintName:       "CUSTOMER_ADDRESS_ENTITY_PARENT_ID_CUSTOMER_ENTITY_ENTITY_ID",
+			TableName:            "customer_address_entity",
+			ColumnName:           "parent_id",
+			ReferencedTableName:  null.MakeString("customer_entity"),
+			ReferencedColumnName: null.MakeString("entity_id"),
+		}}},
+	}
+	g.kcuRev = ddl.ReverseKeyColumnUsage(g.kcu)
+
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+	dbMock.ExpectQuery("SELECT TABLE_NAME, COLUMN_KEY, COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "COLUMN_KEY", "FIELD_COUNT"}).
+			AddRow("customer_entity", "PRI", 1).AddRow("customer_entity", "", 1).
+			AddRow("customer_address_entity", "PRI", 1).AddRow("customer_address_entity", "MUL", 1).
+			AddRow("customer_address_entity", "", 1))
+	g.krs, err = ddl.GenerateKeyRelationships(context.TODO(), dbc.DB, g.kcu)
+	assert.NoError(t, err)
+
+	var wMain, wTest bytes.Buffer
+	assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+	src := wMain.String()
+
+	// has-many
+	assert.Contains(t, src, "func (e *CustomerEntity) LoadCustomerAddressEntities(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) error {")
+	assert.Contains(t, src, "func (cc *CustomerEntities) LoadCustomerAddressEntities(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (err error) {")
+	assert.Contains(t, src, "byKey := make(map[uint32][]*CustomerEntity, len(cc.Data))")
+	assert.Contains(t, src, "dml.Column(`parent_id`).In().PlaceHolder(),")
+	assert.Contains(t, src, "if !r.ParentID.Valid {\n\t\t\t\tcontinue\n\t\t\t}\n\t\t\tfor _, e := range byKey[uint32(r.ParentID.Uint32)] {\n\t\t\t\te.CustomerAddressEntities.Data = append(e.CustomerAddressEntities.Data, r)")
+	// belongs-to
+	assert.Contains(t, src, "func (cc *CustomerAddressEntities) LoadCustomerEntity(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (err error) {")
+	assert.Contains(t, src, "if !e.ParentID.Valid {\n\t\t\tcontinue\n\t\t}\n\t\tk := e.ParentID.Uint32")
+	assert.Contains(t, src, "dml.Column(`entity_id`).In().PlaceHolder(),")
+	assert.Contains(t, src, "e.CustomerEntity = r")
+	// batch size
+	assert.Contains(t, src, "RelationBatchSize")
+	assert.Contains(t, src, "if dbmo.RelationBatchSize <= 0 {\n\t\tdbmo.RelationBatchSize = 500\n\t}")
+
+	t.Run("feature excluded", func(t *testing.T) {
+		g.defaultTableConfig.FeaturesExclude = FeatureDBLoadRelations
+		defer func() { g.defaultTableConfig.FeaturesExclude = 0 }()
+		var wMain, wTest bytes.Buffer
+		assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+		assert.NotContains(t, wMain.String(), "LoadCustomerAddressEntities")
+		assert.NotContains(t, wMain.String(), "RelationBatchSize")
+	})
+}
+
+func TestGenerator_EAVEntity(t *testing.T) {
+	dbc, dbMock := dmltest.MockDB(t)
+	defer dmltest.MockClose(t, dbc, dbMock)
+
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_type_id`, `entity_table` FROM `eav_entity_type` WHERE `entity_type_code` = ?")).
+		WithArgs("catalog_category").
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type_id", "entity_table"}).AddRow(3, "catalog_category_entity"))
+	dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `attribute_code`, `backend_type` FROM `eav_attribute` WHERE `entity_type_id` = ? ORDER BY `attribute_code`")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"attribute_code", "backend_type"}).
+			AddRow("is_active", "int").AddRow("name", "varchar").AddRow("path", "static"))
+
+	g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+		WithEAVEntityFromDB(context.TODO(), dbc.DB, "catalog_category"),
+	)
+	assert.NoError(t, err)
+
+	var wMain, wTest bytes.Buffer
+	assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+	src := wMain.String()
+
+	assert.Contains(t, src, "var eavCatalogCategory = eav.Entity{Table: \"catalog_category_entity\", TypeID: 3, LinkField: \"entity_id\"}")
+	assert.Contains(t, src, "type CatalogCategory struct {\n\tEntityID uint64\n\tStoreID  uint32\n\tIsActive null.Int64  // is_active int\n\tName     null.String // name varchar\n\tloaded   catalogCategoryLoaded\n}")
+	assert.NotContains(t, src, "path")
+	assert.Contains(t, src, "cm.NullString(&e.Name)")
+	assert.Contains(t, src, "func (cc *CatalogCategories) DBLoad(ctx context.Context, db dml.QueryExecPreparer, storeID uint32, ids []uint64, opts ...dml.DBRFunc) error {")
+	assert.Contains(t, src, "eavCatalogCategory.JoinAttributes(dml.NewSelect(\"e.entity_id\").FromAlias(\"catalog_category_entity\", \"e\"), int64(storeID), eavCatalogCategoryAttributes...)")
+	assert.Contains(t, src, "eavCatalogCategory.JoinAttributeScopes(sel, int64(storeID), eavCatalogCategoryAttributes...)")
+	assert.Contains(t, src, "case \"name_scope\":\n\t\t\tcm.Bool(&e.loaded.scoped[1])")
+	assert.Contains(t, src, "all := !e.loaded.ok || e.loaded.EntityID != e.EntityID || e.loaded.StoreID != e.StoreID")
+	assert.Contains(t, src, "if all || e.loaded.scoped[1] || e.Name != e.loaded.Name {")
+	assert.Contains(t, src, "INSERT INTO `catalog_category_entity_varchar` (`entity_id`,`attribute_id`,`store_id`,`value`) SELECT ?,`attribute_id`,?,? FROM (SELECT `attribute_id` FROM `eav_attribute` WHERE (`attribute_code` = 'name') AND (`entity_type_id` = 3)) AS `a` ON DUPLICATE KEY UPDATE `value`=VALUES(`value`)")
+	assert.Contains(t, src, "DELETE FROM `catalog_category_entity_int` WHERE `entity_id` = ? AND `store_id` = ? AND `attribute_id` = (SELECT `attribute_id` FROM `eav_attribute` WHERE (`attribute_code` = 'is_active') AND (`entity_type_id` = 3))")
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewGenerator("", WithEAVEntity(EAVEntity{TypeCode: "customer", Table: "customer_entity"}))
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = NewGenerator("", WithEAVEntity(EAVEntity{TypeCode: "customer", Table: "customer_entity",
+			Attributes: []eav.Attribute{{Code: "dob", BackendType: "date"}}}))
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+		_, err = NewGenerator("", WithEAVEntity(
+			EAVEntity{TypeCode: "customer", Table: "customer_entity", Attributes: []eav.Attribute{{Code: "dob", BackendType: "datetime"}}},
+			EAVEntity{TypeCode: "customer", Table: "customer_entity", Attributes: []eav.Attribute{{Code: "dob", BackendType: "datetime"}}},
+		))
+		assert.ErrorIsKind(t, errors.AlreadyExists, err)
+	})
+}
+
+func TestGenerator_EntityHooks(t *testing.T) {
+	g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+		WithTable("core_configuration", ddl.Columns{
+			&ddl.Column{Field: "config_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+			&ddl.Column{Field: "path", Pos: 2, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		}),
+		WithTableConfig("core_configuration", &TableConfig{
+			FeaturesInclude: FeatureEntityStruct | FeatureCollectionStruct | FeatureDB | FeatureDBMapColumns |
+				FeatureDBSelect | FeatureDBInsert | FeatureDBUpdate,
+		}),
+	)
+	assert.NoError(t, err)
+
+	var wMain, wTest bytes.Buffer
+	assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+	src := wMain.String()
+	assert.Contains(t, src, "type CoreConfigurationBeforeInserter interface {\n\tBeforeInsert(ctx context.Context) error\n}")
+	assert.Contains(t, src, "type CoreConfigurationBeforeUpdater interface {\n\tBeforeUpdate(ctx context.Context) error\n}")
+	assert.Contains(t, src, "type CoreConfigurationAfterLoader interface {\n\tAfterLoad(ctx context.Context) error\n}")
+	assert.Contains(t, src, "func hookCoreConfiguration(ctx context.Context, ef dml.EventFlag, ec *CoreConfigurations, e *CoreConfiguration) error {")
+	assert.Contains(t, src, "if h, ok := interface{}(e).(CoreConfigurationAfterLoader); ok {")
+	assert.Contains(t, src, "if err := hookCoreConfiguration(ctx, ef, ec, e); err != nil {")
+}
+
+func TestGenerator_DBFilters(t *testing.T) {
+	g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+		WithTable("customer_entity", ddl.Columns{
+			&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+			&ddl.Column{Field: "website_id", Pos: 2, Null: "YES", DataType: "smallint", Key: "MUL", ColumnType: "smallint(5)"},
+			&ddl.Column{Field: "email", Pos: 3, Null: "YES", DataType: "varchar", Key: "MUL", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+			&ddl.Column{Field: "firstname", Pos: 4, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+			&ddl.Column{Field: "created_at", Pos: 5, Null: "NO", DataType: "timestamp", Key: "MUL", ColumnType: "timestamp"},
+		}),
+		WithTable("customer_grid", ddl.Columns{
+			&ddl.Column{Field: "name", Pos: 1, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		}),
+		WithTableConfig("customer_grid", &TableConfig{
+			FeaturesInclude: FeatureEntityStruct | FeatureDB | FeatureDBFilter,
+		}),
+	)
+	assert.NoError(t, err)
+
+	var wMain, wTest bytes.Buffer
+	assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+	src := wMain.String()
+	assert.Contains(t, src, "func NewCustomerEntityFilters() *CustomerEntityFilters { return &CustomerEntityFilters{} }")
+	assert.Contains(t, src, "func (f *CustomerEntityFilters) WhereEntityIDIn(vs ...uint64) *CustomerEntityFilters {\n\tf.conditions = append(f.conditions, dml.Column(`entity_id`).In().Uint64s(vs...))")
+	assert.Contains(t, src, "func (f *CustomerEntityFilters) WhereWebsiteID(v int64) *CustomerEntityFilters {\n\tf.conditions = append(f.conditions, dml.Column(`website_id`).Equal().Int64(v))")
+	assert.Contains(t, src, "func (f *CustomerEntityFilters) WhereEmailLike(s string) *CustomerEntityFilters {\n\tf.conditions = append(f.conditions, dml.Column(`email`).Like().Str(s))")
+	assert.Contains(t, src, "func (f *CustomerEntityFilters) Select(sel *dml.Select) *dml.Select {")
+	assert.NotContains(t, src, "WhereFirstname")
+	assert.NotContains(t, src, "WhereCreatedAt")
+	assert.NotContains(t, src, "WhereEntityIDLike")
+	assert.NotContains(t, src, "CustomerGridFilters")
+}
+
+func TestGenerator_ProtoFieldNumbersStable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dmlgen_proto")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	protoFile := filepath.Join(dir, "customer.proto")
+
+	genProto := func(cols ddl.Columns) string {
+		g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+			WithTable("customer_entity", cols),
+			WithProtobuf(&SerializerConfig{PreviousProtoFile: protoFile}),
+		)
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		assert.NoError(t, g.generateProto(&buf))
+		assert.NoError(t, ioutil.WriteFile(protoFile, buf.Bytes(), 0644))
+		return buf.String()
+	}
+
+	src := genProto(ddl.Columns{
+		&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+		&ddl.Column{Field: "email", Pos: 2, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		&ddl.Column{Field: "firstname", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+	})
+	assert.Contains(t, src, "uint32 EntityID = 1")
+	assert.Contains(t, src, "string Email = 2")
+	assert.Contains(t, src, "string Firstname = 3")
+	assert.NotContains(t, src, "reserved")
+
+	// email gets dropped and lastname inserted before firstname.
+	src = genProto(ddl.Columns{
+		&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+		&ddl.Column{Field: "lastname", Pos: 2, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		&ddl.Column{Field: "firstname", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+	})
+	assert.Contains(t, src, "uint32 EntityID = 1")
+	assert.Contains(t, src, "string Lastname = 4")
+	assert.Contains(t, src, "string Firstname = 3")
+	assert.Contains(t, src, "reserved 2; // Email")
+	assert.Contains(t, src, "repeated CustomerEntity Data = 1;")
+
+	// email comes back and gets a new number, its old number stays reserved.
+	src = genProto(ddl.Columns{
+		&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+		&ddl.Column{Field: "email", Pos: 2, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		&ddl.Column{Field: "lastname", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		&ddl.Column{Field: "firstname", Pos: 4, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+	})
+	assert.Contains(t, src, "string Email = 5")
+	assert.Contains(t, src, "string Lastname = 4")
+	assert.Contains(t, src, "reserved 2; // Email")
+}
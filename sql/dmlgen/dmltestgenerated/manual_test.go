@@ -176,14 +176,14 @@ func TestNewDBManager_Manual_Tuples(t *testing.T) {
 			"CatalogProductIndexEAVDecimalIDXInsert::INSERT INTO `catalog_product_index_eav_decimal_idx` (`entity_id`,`attribute_id`,`store_id`,`source_id`,`value`) VALUES (?,?,?,?,?)",
 			"CatalogProductIndexEAVDecimalIDXSelectByPK::SELECT `entity_id`, `attribute_id`, `store_id`, `source_id`, `value` FROM `catalog_product_index_eav_decimal_idx` AS `main_table` WHERE ((`entity_id`, `attribute_id`, `store_id`, `source_id`) = /*TUPLES=004*/) LIMIT 0,1000",
 			"CatalogProductIndexEAVDecimalIDXUpdateByPK::UPDATE `catalog_product_index_eav_decimal_idx` SET `entity_id`=?, `attribute_id`=?, `store_id`=?, `source_id`=?, `value`=? WHERE ((`entity_id`, `attribute_id`, `store_id`, `source_id`) IN /*TUPLES=004*/)",
-			"CatalogProductIndexEAVDecimalIDXUpsertByPK::INSERT INTO `catalog_product_index_eav_decimal_idx` (`entity_id`,`attribute_id`,`store_id`,`source_id`,`value`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `entity_id`=VALUES(`entity_id`), `attribute_id`=VALUES(`attribute_id`), `store_id`=VALUES(`store_id`), `source_id`=VALUES(`source_id`), `value`=VALUES(`value`)",
+			"CatalogProductIndexEAVDecimalIDXUpsertByPK::INSERT INTO `catalog_product_index_eav_decimal_idx` (`entity_id`,`attribute_id`,`store_id`,`source_id`,`value`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `value`=VALUES(`value`)",
 			"CatalogProductIndexEAVDecimalIDXesSelectAll::SELECT `entity_id`, `attribute_id`, `store_id`, `source_id`, `value` FROM `catalog_product_index_eav_decimal_idx` AS `main_table` LIMIT 0,1000",
 			"CatalogProductIndexEAVDecimalIDXesSelectByPK::SELECT `entity_id`, `attribute_id`, `store_id`, `source_id`, `value` FROM `catalog_product_index_eav_decimal_idx` AS `main_table` WHERE ((`entity_id`, `attribute_id`, `store_id`, `source_id`) IN /*TUPLES=004*/) LIMIT 0,1000",
 			"CoreConfigurationDeleteByPK::DELETE FROM `core_configuration` WHERE (`config_id` IN ?)",
 			"CoreConfigurationInsert::INSERT INTO `core_configuration` (`scope`,`scope_id`,`expires`,`path`,`value`) VALUES (?,?,?,?,?)",
 			"CoreConfigurationSelectByPK::SELECT `config_id`, `scope`, `scope_id`, `expires`, `path`, `value` FROM `core_configuration` AS `main_table` WHERE (`config_id` = ?) LIMIT 0,1000",
 			"CoreConfigurationUpdateByPK::UPDATE `core_configuration` SET `scope`=?, `scope_id`=?, `expires`=?, `path`=?, `value`=? WHERE (`config_id` IN ?)",
-			"CoreConfigurationUpsertByPK::INSERT INTO `core_configuration` (`scope`,`scope_id`,`expires`,`path`,`value`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `scope`=VALUES(`scope`), `scope_id`=VALUES(`scope_id`), `expires`=VALUES(`expires`), `path`=VALUES(`path`), `value`=VALUES(`value`)",
+			"CoreConfigurationUpsertByPK::INSERT INTO `core_configuration` (`scope`,`scope_id`,`expires`,`path`,`value`,`config_id`) VALUES (?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `scope`=VALUES(`scope`), `scope_id`=VALUES(`scope_id`), `expires`=VALUES(`expires`), `path`=VALUES(`path`), `value`=VALUES(`value`)",
 			"CoreConfigurationsSelectAll::SELECT `config_id`, `scope`, `scope_id`, `expires`, `path`, `value` FROM `core_configuration` AS `main_table` LIMIT 0,1000",
 			"CoreConfigurationsSelectByPK::SELECT `config_id`, `scope`, `scope_id`, `expires`, `path`, `value` FROM `core_configuration` AS `main_table` WHERE (`config_id` IN ?) LIMIT 0,1000",
 			"CustomerAddressEntitiesSelectAll::SELECT `entity_id`, `increment_id`, `parent_id`, `created_at`, `updated_at`, `is_active`, `city`, `company`, `country_id`, `fax`, `firstname`, `lastname`, `middlename`, `postcode`, `prefix`, `region`, `region_id`, `street`, `suffix`, `telephone`, `vat_id`, `vat_is_valid`, `vat_request_date`, `vat_request_id`, `vat_request_success` FROM `customer_address_entity` AS `main_table` LIMIT 0,1000",
@@ -192,26 +192,26 @@ func TestNewDBManager_Manual_Tuples(t *testing.T) {
 			"CustomerAddressEntityInsert::INSERT INTO `customer_address_entity` (`increment_id`,`parent_id`,`is_active`,`city`,`company`,`country_id`,`fax`,`firstname`,`lastname`,`middlename`,`postcode`,`prefix`,`region`,`region_id`,`street`,`suffix`,`telephone`,`vat_id`,`vat_is_valid`,`vat_request_date`,`vat_request_id`,`vat_request_success`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)",
 			"CustomerAddressEntitySelectByPK::SELECT `entity_id`, `increment_id`, `parent_id`, `created_at`, `updated_at`, `is_active`, `city`, `company`, `country_id`, `fax`, `firstname`, `lastname`, `middlename`, `postcode`, `prefix`, `region`, `region_id`, `street`, `suffix`, `telephone`, `vat_id`, `vat_is_valid`, `vat_request_date`, `vat_request_id`, `vat_request_success` FROM `customer_address_entity` AS `main_table` WHERE (`entity_id` = ?) LIMIT 0,1000",
 			"CustomerAddressEntityUpdateByPK::UPDATE `customer_address_entity` SET `increment_id`=?, `parent_id`=?, `is_active`=?, `city`=?, `company`=?, `country_id`=?, `fax`=?, `firstname`=?, `lastname`=?, `middlename`=?, `postcode`=?, `prefix`=?, `region`=?, `region_id`=?, `street`=?, `suffix`=?, `telephone`=?, `vat_id`=?, `vat_is_valid`=?, `vat_request_date`=?, `vat_request_id`=?, `vat_request_success`=? WHERE (`entity_id` IN ?)",
-			"CustomerAddressEntityUpsertByPK::INSERT INTO `customer_address_entity` (`increment_id`,`parent_id`,`is_active`,`city`,`company`,`country_id`,`fax`,`firstname`,`lastname`,`middlename`,`postcode`,`prefix`,`region`,`region_id`,`street`,`suffix`,`telephone`,`vat_id`,`vat_is_valid`,`vat_request_date`,`vat_request_id`,`vat_request_success`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `increment_id`=VALUES(`increment_id`), `parent_id`=VALUES(`parent_id`), `is_active`=VALUES(`is_active`), `city`=VALUES(`city`), `company`=VALUES(`company`), `country_id`=VALUES(`country_id`), `fax`=VALUES(`fax`), `firstname`=VALUES(`firstname`), `lastname`=VALUES(`lastname`), `middlename`=VALUES(`middlename`), `postcode`=VALUES(`postcode`), `prefix`=VALUES(`prefix`), `region`=VALUES(`region`), `region_id`=VALUES(`region_id`), `street`=VALUES(`street`), `suffix`=VALUES(`suffix`), `telephone`=VALUES(`telephone`), `vat_id`=VALUES(`vat_id`), `vat_is_valid`=VALUES(`vat_is_valid`), `vat_request_date`=VALUES(`vat_request_date`), `vat_request_id`=VALUES(`vat_request_id`), `vat_request_success`=VALUES(`vat_request_success`)",
+			"CustomerAddressEntityUpsertByPK::INSERT INTO `customer_address_entity` (`increment_id`,`parent_id`,`is_active`,`city`,`company`,`country_id`,`fax`,`firstname`,`lastname`,`middlename`,`postcode`,`prefix`,`region`,`region_id`,`street`,`suffix`,`telephone`,`vat_id`,`vat_is_valid`,`vat_request_date`,`vat_request_id`,`vat_request_success`,`entity_id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `increment_id`=VALUES(`increment_id`), `parent_id`=VALUES(`parent_id`), `is_active`=VALUES(`is_active`), `city`=VALUES(`city`), `company`=VALUES(`company`), `country_id`=VALUES(`country_id`), `fax`=VALUES(`fax`), `firstname`=VALUES(`firstname`), `lastname`=VALUES(`lastname`), `middlename`=VALUES(`middlename`), `postcode`=VALUES(`postcode`), `prefix`=VALUES(`prefix`), `region`=VALUES(`region`), `region_id`=VALUES(`region_id`), `street`=VALUES(`street`), `suffix`=VALUES(`suffix`), `telephone`=VALUES(`telephone`), `vat_id`=VALUES(`vat_id`), `vat_is_valid`=VALUES(`vat_is_valid`), `vat_request_date`=VALUES(`vat_request_date`), `vat_request_id`=VALUES(`vat_request_id`), `vat_request_success`=VALUES(`vat_request_success`)",
 			"CustomerEntitiesSelectAll::SELECT `entity_id`, `website_id`, `email`, `group_id`, `increment_id`, `store_id`, `created_at`, `updated_at`, `is_active`, `disable_auto_group_change`, `created_in`, `prefix`, `firstname`, `middlename`, `lastname`, `suffix`, `dob`, `password_hash`, `rp_token`, `rp_token_created_at`, `default_billing`, `default_shipping`, `taxvat`, `confirmation`, `gender`, `failures_num`, `first_failure`, `lock_expires` FROM `customer_entity` AS `main_table` LIMIT 0,1000",
 			"CustomerEntitiesSelectByPK::SELECT `entity_id`, `website_id`, `email`, `group_id`, `increment_id`, `store_id`, `created_at`, `updated_at`, `is_active`, `disable_auto_group_change`, `created_in`, `prefix`, `firstname`, `middlename`, `lastname`, `suffix`, `dob`, `password_hash`, `rp_token`, `rp_token_created_at`, `default_billing`, `default_shipping`, `taxvat`, `confirmation`, `gender`, `failures_num`, `first_failure`, `lock_expires` FROM `customer_entity` AS `main_table` WHERE (`entity_id` IN ?) LIMIT 0,1000",
 			"CustomerEntityDeleteByPK::DELETE FROM `customer_entity` WHERE (`entity_id` IN ?)",
 			"CustomerEntityInsert::INSERT INTO `customer_entity` (`website_id`,`email`,`group_id`,`increment_id`,`store_id`,`is_active`,`disable_auto_group_change`,`created_in`,`prefix`,`firstname`,`middlename`,`lastname`,`suffix`,`dob`,`password_hash`,`rp_token`,`rp_token_created_at`,`default_billing`,`default_shipping`,`taxvat`,`confirmation`,`gender`,`failures_num`,`first_failure`,`lock_expires`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)",
 			"CustomerEntitySelectByPK::SELECT `entity_id`, `website_id`, `email`, `group_id`, `increment_id`, `store_id`, `created_at`, `updated_at`, `is_active`, `disable_auto_group_change`, `created_in`, `prefix`, `firstname`, `middlename`, `lastname`, `suffix`, `dob`, `password_hash`, `rp_token`, `rp_token_created_at`, `default_billing`, `default_shipping`, `taxvat`, `confirmation`, `gender`, `failures_num`, `first_failure`, `lock_expires` FROM `customer_entity` AS `main_table` WHERE (`entity_id` = ?) LIMIT 0,1000",
 			"CustomerEntityUpdateByPK::UPDATE `customer_entity` SET `website_id`=?, `email`=?, `group_id`=?, `increment_id`=?, `store_id`=?, `is_active`=?, `disable_auto_group_change`=?, `created_in`=?, `prefix`=?, `firstname`=?, `middlename`=?, `lastname`=?, `suffix`=?, `dob`=?, `password_hash`=?, `rp_token`=?, `rp_token_created_at`=?, `default_billing`=?, `default_shipping`=?, `taxvat`=?, `confirmation`=?, `gender`=?, `failures_num`=?, `first_failure`=?, `lock_expires`=? WHERE (`entity_id` IN ?)",
-			"CustomerEntityUpsertByPK::INSERT INTO `customer_entity` (`website_id`,`email`,`group_id`,`increment_id`,`store_id`,`is_active`,`disable_auto_group_change`,`created_in`,`prefix`,`firstname`,`middlename`,`lastname`,`suffix`,`dob`,`password_hash`,`rp_token`,`rp_token_created_at`,`default_billing`,`default_shipping`,`taxvat`,`confirmation`,`gender`,`failures_num`,`first_failure`,`lock_expires`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `website_id`=VALUES(`website_id`), `email`=VALUES(`email`), `group_id`=VALUES(`group_id`), `increment_id`=VALUES(`increment_id`), `store_id`=VALUES(`store_id`), `is_active`=VALUES(`is_active`), `disable_auto_group_change`=VALUES(`disable_auto_group_change`), `created_in`=VALUES(`created_in`), `prefix`=VALUES(`prefix`), `firstname`=VALUES(`firstname`), `middlename`=VALUES(`middlename`), `lastname`=VALUES(`lastname`), `suffix`=VALUES(`suffix`), `dob`=VALUES(`dob`), `password_hash`=VALUES(`password_hash`), `rp_token`=VALUES(`rp_token`), `rp_token_created_at`=VALUES(`rp_token_created_at`), `default_billing`=VALUES(`default_billing`), `default_shipping`=VALUES(`default_shipping`), `taxvat`=VALUES(`taxvat`), `confirmation`=VALUES(`confirmation`), `gender`=VALUES(`gender`), `failures_num`=VALUES(`failures_num`), `first_failure`=VALUES(`first_failure`), `lock_expires`=VALUES(`lock_expires`)",
+			"CustomerEntityUpsertByPK::INSERT INTO `customer_entity` (`website_id`,`email`,`group_id`,`increment_id`,`store_id`,`is_active`,`disable_auto_group_change`,`created_in`,`prefix`,`firstname`,`middlename`,`lastname`,`suffix`,`dob`,`password_hash`,`rp_token`,`rp_token_created_at`,`default_billing`,`default_shipping`,`taxvat`,`confirmation`,`gender`,`failures_num`,`first_failure`,`lock_expires`,`entity_id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `website_id`=VALUES(`website_id`), `email`=VALUES(`email`), `group_id`=VALUES(`group_id`), `increment_id`=VALUES(`increment_id`), `store_id`=VALUES(`store_id`), `is_active`=VALUES(`is_active`), `disable_auto_group_change`=VALUES(`disable_auto_group_change`), `created_in`=VALUES(`created_in`), `prefix`=VALUES(`prefix`), `firstname`=VALUES(`firstname`), `middlename`=VALUES(`middlename`), `lastname`=VALUES(`lastname`), `suffix`=VALUES(`suffix`), `dob`=VALUES(`dob`), `password_hash`=VALUES(`password_hash`), `rp_token`=VALUES(`rp_token`), `rp_token_created_at`=VALUES(`rp_token_created_at`), `default_billing`=VALUES(`default_billing`), `default_shipping`=VALUES(`default_shipping`), `taxvat`=VALUES(`taxvat`), `confirmation`=VALUES(`confirmation`), `gender`=VALUES(`gender`), `failures_num`=VALUES(`failures_num`), `first_failure`=VALUES(`first_failure`), `lock_expires`=VALUES(`lock_expires`)",
 			"DmlgenTypesCollectionSelectAll::SELECT `id`, `col_bigint_1`, `col_bigint_2`, `col_bigint_3`, `col_bigint_4`, `col_blob`, `col_date_1`, `col_date_2`, `col_datetime_1`, `col_datetime_2`, `col_decimal_10_1`, `col_decimal_12_4`, `price_a_12_4`, `price_b_12_4`, `col_decimal_12_3`, `col_decimal_20_6`, `col_decimal_24_12`, `col_int_1`, `col_int_2`, `col_int_3`, `col_int_4`, `col_longtext_1`, `col_longtext_2`, `col_mediumblob`, `col_mediumtext_1`, `col_mediumtext_2`, `col_smallint_1`, `col_smallint_2`, `col_smallint_3`, `col_smallint_4`, `has_smallint_5`, `is_smallint_5`, `col_text`, `col_timestamp_1`, `col_timestamp_2`, `col_tinyint_1`, `col_varchar_1`, `col_varchar_100`, `col_varchar_16`, `col_char_1`, `col_char_2` FROM `dmlgen_types` AS `main_table` LIMIT 0,1000",
 			"DmlgenTypesCollectionSelectByPK::SELECT `id`, `col_bigint_1`, `col_bigint_2`, `col_bigint_3`, `col_bigint_4`, `col_blob`, `col_date_1`, `col_date_2`, `col_datetime_1`, `col_datetime_2`, `col_decimal_10_1`, `col_decimal_12_4`, `price_a_12_4`, `price_b_12_4`, `col_decimal_12_3`, `col_decimal_20_6`, `col_decimal_24_12`, `col_int_1`, `col_int_2`, `col_int_3`, `col_int_4`, `col_longtext_1`, `col_longtext_2`, `col_mediumblob`, `col_mediumtext_1`, `col_mediumtext_2`, `col_smallint_1`, `col_smallint_2`, `col_smallint_3`, `col_smallint_4`, `has_smallint_5`, `is_smallint_5`, `col_text`, `col_timestamp_1`, `col_timestamp_2`, `col_tinyint_1`, `col_varchar_1`, `col_varchar_100`, `col_varchar_16`, `col_char_1`, `col_char_2` FROM `dmlgen_types` AS `main_table` WHERE (`id` IN ?) LIMIT 0,1000",
 			"DmlgenTypesDeleteByPK::DELETE FROM `dmlgen_types` WHERE (`id` IN ?)",
 			"DmlgenTypesInsert::INSERT INTO `dmlgen_types` (`col_bigint_1`,`col_bigint_2`,`col_bigint_3`,`col_bigint_4`,`col_blob`,`col_date_1`,`col_date_2`,`col_datetime_1`,`col_datetime_2`,`col_decimal_10_1`,`col_decimal_12_4`,`price_a_12_4`,`price_b_12_4`,`col_decimal_12_3`,`col_decimal_20_6`,`col_decimal_24_12`,`col_int_1`,`col_int_2`,`col_int_3`,`col_int_4`,`col_longtext_1`,`col_longtext_2`,`col_mediumblob`,`col_mediumtext_1`,`col_mediumtext_2`,`col_smallint_1`,`col_smallint_2`,`col_smallint_3`,`col_smallint_4`,`has_smallint_5`,`is_smallint_5`,`col_text`,`col_timestamp_2`,`col_tinyint_1`,`col_varchar_1`,`col_varchar_100`,`col_varchar_16`,`col_char_1`,`col_char_2`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)",
 			"DmlgenTypesSelectByPK::SELECT `id`, `col_bigint_1`, `col_bigint_2`, `col_bigint_3`, `col_bigint_4`, `col_blob`, `col_date_1`, `col_date_2`, `col_datetime_1`, `col_datetime_2`, `col_decimal_10_1`, `col_decimal_12_4`, `price_a_12_4`, `price_b_12_4`, `col_decimal_12_3`, `col_decimal_20_6`, `col_decimal_24_12`, `col_int_1`, `col_int_2`, `col_int_3`, `col_int_4`, `col_longtext_1`, `col_longtext_2`, `col_mediumblob`, `col_mediumtext_1`, `col_mediumtext_2`, `col_smallint_1`, `col_smallint_2`, `col_smallint_3`, `col_smallint_4`, `has_smallint_5`, `is_smallint_5`, `col_text`, `col_timestamp_1`, `col_timestamp_2`, `col_tinyint_1`, `col_varchar_1`, `col_varchar_100`, `col_varchar_16`, `col_char_1`, `col_char_2` FROM `dmlgen_types` AS `main_table` WHERE (`id` = ?) LIMIT 0,1000",
 			"DmlgenTypesUpdateByPK::UPDATE `dmlgen_types` SET `col_bigint_1`=?, `col_bigint_2`=?, `col_bigint_3`=?, `col_bigint_4`=?, `col_blob`=?, `col_date_1`=?, `col_date_2`=?, `col_datetime_1`=?, `col_datetime_2`=?, `col_decimal_10_1`=?, `col_decimal_12_4`=?, `price_a_12_4`=?, `price_b_12_4`=?, `col_decimal_12_3`=?, `col_decimal_20_6`=?, `col_decimal_24_12`=?, `col_int_1`=?, `col_int_2`=?, `col_int_3`=?, `col_int_4`=?, `col_longtext_1`=?, `col_longtext_2`=?, `col_mediumblob`=?, `col_mediumtext_1`=?, `col_mediumtext_2`=?, `col_smallint_1`=?, `col_smallint_2`=?, `col_smallint_3`=?, `col_smallint_4`=?, `has_smallint_5`=?, `is_smallint_5`=?, `col_text`=?, `col_timestamp_2`=?, `col_tinyint_1`=?, `col_varchar_1`=?, `col_varchar_100`=?, `col_varchar_16`=?, `col_char_1`=?, `col_char_2`=? WHERE (`id` IN ?)",
-			"DmlgenTypesUpsertByPK::INSERT INTO `dmlgen_types` (`col_bigint_1`,`col_bigint_2`,`col_bigint_3`,`col_bigint_4`,`col_blob`,`col_date_1`,`col_date_2`,`col_datetime_1`,`col_datetime_2`,`col_decimal_10_1`,`col_decimal_12_4`,`price_a_12_4`,`price_b_12_4`,`col_decimal_12_3`,`col_decimal_20_6`,`col_decimal_24_12`,`col_int_1`,`col_int_2`,`col_int_3`,`col_int_4`,`col_longtext_1`,`col_longtext_2`,`col_mediumblob`,`col_mediumtext_1`,`col_mediumtext_2`,`col_smallint_1`,`col_smallint_2`,`col_smallint_3`,`col_smallint_4`,`has_smallint_5`,`is_smallint_5`,`col_text`,`col_timestamp_2`,`col_tinyint_1`,`col_varchar_1`,`col_varchar_100`,`col_varchar_16`,`col_char_1`,`col_char_2`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `col_bigint_1`=VALUES(`col_bigint_1`), `col_bigint_2`=VALUES(`col_bigint_2`), `col_bigint_3`=VALUES(`col_bigint_3`), `col_bigint_4`=VALUES(`col_bigint_4`), `col_blob`=VALUES(`col_blob`), `col_date_1`=VALUES(`col_date_1`), `col_date_2`=VALUES(`col_date_2`), `col_datetime_1`=VALUES(`col_datetime_1`), `col_datetime_2`=VALUES(`col_datetime_2`), `col_decimal_10_1`=VALUES(`col_decimal_10_1`), `col_decimal_12_4`=VALUES(`col_decimal_12_4`), `price_a_12_4`=VALUES(`price_a_12_4`), `price_b_12_4`=VALUES(`price_b_12_4`), `col_decimal_12_3`=VALUES(`col_decimal_12_3`), `col_decimal_20_6`=VALUES(`col_decimal_20_6`), `col_decimal_24_12`=VALUES(`col_decimal_24_12`), `col_int_1`=VALUES(`col_int_1`), `col_int_2`=VALUES(`col_int_2`), `col_int_3`=VALUES(`col_int_3`), `col_int_4`=VALUES(`col_int_4`), `col_longtext_1`=VALUES(`col_longtext_1`), `col_longtext_2`=VALUES(`col_longtext_2`), `col_mediumblob`=VALUES(`col_mediumblob`), `col_mediumtext_1`=VALUES(`col_mediumtext_1`), `col_mediumtext_2`=VALUES(`col_mediumtext_2`), `col_smallint_1`=VALUES(`col_smallint_1`), `col_smallint_2`=VALUES(`col_smallint_2`), `col_smallint_3`=VALUES(`col_smallint_3`), `col_smallint_4`=VALUES(`col_smallint_4`), `has_smallint_5`=VALUES(`has_smallint_5`), `is_smallint_5`=VALUES(`is_smallint_5`), `col_text`=VALUES(`col_text`), `col_timestamp_2`=VALUES(`col_timestamp_2`), `col_tinyint_1`=VALUES(`col_tinyint_1`), `col_varchar_1`=VALUES(`col_varchar_1`), `col_varchar_100`=VALUES(`col_varchar_100`), `col_varchar_16`=VALUES(`col_varchar_16`), `col_char_1`=VALUES(`col_char_1`), `col_char_2`=VALUES(`col_char_2`)",
+			"DmlgenTypesUpsertByPK::INSERT INTO `dmlgen_types` (`col_bigint_1`,`col_bigint_2`,`col_bigint_3`,`col_bigint_4`,`col_blob`,`col_date_1`,`col_date_2`,`col_datetime_1`,`col_datetime_2`,`col_decimal_10_1`,`col_decimal_12_4`,`price_a_12_4`,`price_b_12_4`,`col_decimal_12_3`,`col_decimal_20_6`,`col_decimal_24_12`,`col_int_1`,`col_int_2`,`col_int_3`,`col_int_4`,`col_longtext_1`,`col_longtext_2`,`col_mediumblob`,`col_mediumtext_1`,`col_mediumtext_2`,`col_smallint_1`,`col_smallint_2`,`col_smallint_3`,`col_smallint_4`,`has_smallint_5`,`is_smallint_5`,`col_text`,`col_timestamp_2`,`col_tinyint_1`,`col_varchar_1`,`col_varchar_100`,`col_varchar_16`,`col_char_1`,`col_char_2`,`id`) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `col_bigint_1`=VALUES(`col_bigint_1`), `col_bigint_2`=VALUES(`col_bigint_2`), `col_bigint_3`=VALUES(`col_bigint_3`), `col_bigint_4`=VALUES(`col_bigint_4`), `col_blob`=VALUES(`col_blob`), `col_date_1`=VALUES(`col_date_1`), `col_date_2`=VALUES(`col_date_2`), `col_datetime_1`=VALUES(`col_datetime_1`), `col_datetime_2`=VALUES(`col_datetime_2`), `col_decimal_10_1`=VALUES(`col_decimal_10_1`), `col_decimal_12_4`=VALUES(`col_decimal_12_4`), `price_a_12_4`=VALUES(`price_a_12_4`), `price_b_12_4`=VALUES(`price_b_12_4`), `col_decimal_12_3`=VALUES(`col_decimal_12_3`), `col_decimal_20_6`=VALUES(`col_decimal_20_6`), `col_decimal_24_12`=VALUES(`col_decimal_24_12`), `col_int_1`=VALUES(`col_int_1`), `col_int_2`=VALUES(`col_int_2`), `col_int_3`=VALUES(`col_int_3`), `col_int_4`=VALUES(`col_int_4`), `col_longtext_1`=VALUES(`col_longtext_1`), `col_longtext_2`=VALUES(`col_longtext_2`), `col_mediumblob`=VALUES(`col_mediumblob`), `col_mediumtext_1`=VALUES(`col_mediumtext_1`), `col_mediumtext_2`=VALUES(`col_mediumtext_2`), `col_smallint_1`=VALUES(`col_smallint_1`), `col_smallint_2`=VALUES(`col_smallint_2`), `col_smallint_3`=VALUES(`col_smallint_3`), `col_smallint_4`=VALUES(`col_smallint_4`), `has_smallint_5`=VALUES(`has_smallint_5`), `is_smallint_5`=VALUES(`is_smallint_5`), `col_text`=VALUES(`col_text`), `col_timestamp_2`=VALUES(`col_timestamp_2`), `col_tinyint_1`=VALUES(`col_tinyint_1`), `col_varchar_1`=VALUES(`col_varchar_1`), `col_varchar_100`=VALUES(`col_varchar_100`), `col_varchar_16`=VALUES(`col_varchar_16`), `col_char_1`=VALUES(`col_char_1`), `col_char_2`=VALUES(`col_char_2`)",
 			"SalesOrderStatusStateDeleteByPK::DELETE FROM `sales_order_status_state` WHERE ((`status`, `state`) IN /*TUPLES=002*/)",
 			"SalesOrderStatusStateInsert::INSERT INTO `sales_order_status_state` (`status`,`state`,`is_default`,`visible_on_front`) VALUES (?,?,?,?)",
 			"SalesOrderStatusStateSelectByPK::SELECT `status`, `state`, `is_default`, `visible_on_front` FROM `sales_order_status_state` AS `main_table` WHERE ((`status`, `state`) = /*TUPLES=002*/) LIMIT 0,1000",
 			"SalesOrderStatusStateUpdateByPK::UPDATE `sales_order_status_state` SET `status`=?, `state`=?, `is_default`=?, `visible_on_front`=? WHERE ((`status`, `state`) IN /*TUPLES=002*/)",
-			"SalesOrderStatusStateUpsertByPK::INSERT INTO `sales_order_status_state` (`status`,`state`,`is_default`,`visible_on_front`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `status`=VALUES(`status`), `state`=VALUES(`state`), `is_default`=VALUES(`is_default`), `visible_on_front`=VALUES(`visible_on_front`)",
+			"SalesOrderStatusStateUpsertByPK::INSERT INTO `sales_order_status_state` (`status`,`state`,`is_default`,`visible_on_front`) VALUES (?,?,?,?) ON DUPLICATE KEY UPDATE `is_default`=VALUES(`is_default`), `visible_on_front`=VALUES(`visible_on_front`)",
 			"SalesOrderStatusStatesSelectAll::SELECT `status`, `state`, `is_default`, `visible_on_front` FROM `sales_order_status_state` AS `main_table` LIMIT 0,1000",
 			"SalesOrderStatusStatesSelectByPK::SELECT `status`, `state`, `is_default`, `visible_on_front` FROM `sales_order_status_state` AS `main_table` WHERE ((`status`, `state`) IN /*TUPLES=002*/) LIMIT 0,1000",
 			"ViewCustomerAutoIncrementSelectByPK::SELECT `ce_entity_id`, `email`, `firstname`, `lastname`, `city` FROM `view_customer_auto_increment` AS `main_table` WHERE (`ce_entity_id` = ?) LIMIT 0,1000",
@@ -122,9 +122,12 @@ type DBM struct {
 }
 
 func (dbm DBM) eventCatalogProductIndexEAVDecimalIDXFunc(ctx context.Context, ef dml.EventFlag, ec *CatalogProductIndexEAVDecimalIDXes, e *CatalogProductIndexEAVDecimalIDX) error {
-	if len(dbm.option.eventCatalogProductIndexEAVDecimalIDXFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookCatalogProductIndexEAVDecimalIDX(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventCatalogProductIndexEAVDecimalIDXFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -133,10 +136,67 @@ func (dbm DBM) eventCatalogProductIndexEAVDecimalIDXFunc(ctx context.Context, ef
 	return nil
 }
 
+// CatalogProductIndexEAVDecimalIDXBeforeInserter gets called before inserting a
+// CatalogProductIndexEAVDecimalIDX or a CatalogProductIndexEAVDecimalIDXes into
+// the database. Implement it in a non-generated file.
+type CatalogProductIndexEAVDecimalIDXBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// CatalogProductIndexEAVDecimalIDXBeforeUpdater gets called before updating a
+// CatalogProductIndexEAVDecimalIDX or a CatalogProductIndexEAVDecimalIDXes in
+// the database. Implement it in a non-generated file.
+type CatalogProductIndexEAVDecimalIDXBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// CatalogProductIndexEAVDecimalIDXAfterLoader gets called after loading a
+// CatalogProductIndexEAVDecimalIDX or a CatalogProductIndexEAVDecimalIDXes from
+// the database. Implement it in a non-generated file.
+type CatalogProductIndexEAVDecimalIDXAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookCatalogProductIndexEAVDecimalIDX calls the optional hook interfaces of
+// each entity. The hooks run before the event functions added via
+// DBMOption.AddEventCatalogProductIndexEAVDecimalIDX and get skipped with
+// dml.EventsAreSkipped.
+func hookCatalogProductIndexEAVDecimalIDX(ctx context.Context, ef dml.EventFlag, ec *CatalogProductIndexEAVDecimalIDXes, e *CatalogProductIndexEAVDecimalIDX) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookCatalogProductIndexEAVDecimalIDX(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(CatalogProductIndexEAVDecimalIDXBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(CatalogProductIndexEAVDecimalIDXBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(CatalogProductIndexEAVDecimalIDXAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventCoreConfigurationFunc(ctx context.Context, ef dml.EventFlag, ec *CoreConfigurations, e *CoreConfiguration) error {
-	if len(dbm.option.eventCoreConfigurationFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookCoreConfiguration(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventCoreConfigurationFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -145,10 +205,66 @@ func (dbm DBM) eventCoreConfigurationFunc(ctx context.Context, ef dml.EventFlag,
 	return nil
 }
 
+// CoreConfigurationBeforeInserter gets called before inserting a
+// CoreConfiguration or a CoreConfigurations into the database. Implement it in
+// a non-generated file.
+type CoreConfigurationBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// CoreConfigurationBeforeUpdater gets called before updating a
+// CoreConfiguration or a CoreConfigurations in the database. Implement it in a
+// non-generated file.
+type CoreConfigurationBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// CoreConfigurationAfterLoader gets called after loading a CoreConfiguration or
+// a CoreConfigurations from the database. Implement it in a non-generated file.
+type CoreConfigurationAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookCoreConfiguration calls the optional hook interfaces of each entity. The
+// hooks run before the event functions added via
+// DBMOption.AddEventCoreConfiguration and get skipped with
+// dml.EventsAreSkipped.
+func hookCoreConfiguration(ctx context.Context, ef dml.EventFlag, ec *CoreConfigurations, e *CoreConfiguration) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookCoreConfiguration(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(CoreConfigurationBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(CoreConfigurationBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(CoreConfigurationAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventCustomerAddressEntityFunc(ctx context.Context, ef dml.EventFlag, ec *CustomerAddressEntities, e *CustomerAddressEntity) error {
-	if len(dbm.option.eventCustomerAddressEntityFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookCustomerAddressEntity(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventCustomerAddressEntityFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -157,10 +273,67 @@ func (dbm DBM) eventCustomerAddressEntityFunc(ctx context.Context, ef dml.EventF
 	return nil
 }
 
+// CustomerAddressEntityBeforeInserter gets called before inserting a
+// CustomerAddressEntity or a CustomerAddressEntities into the database.
+// Implement it in a non-generated file.
+type CustomerAddressEntityBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// CustomerAddressEntityBeforeUpdater gets called before updating a
+// CustomerAddressEntity or a CustomerAddressEntities in the database. Implement
+// it in a non-generated file.
+type CustomerAddressEntityBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// CustomerAddressEntityAfterLoader gets called after loading a
+// CustomerAddressEntity or a CustomerAddressEntities from the database.
+// Implement it in a non-generated file.
+type CustomerAddressEntityAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookCustomerAddressEntity calls the optional hook interfaces of each entity.
+// The hooks run before the event functions added via
+// DBMOption.AddEventCustomerAddressEntity and get skipped with
+// dml.EventsAreSkipped.
+func hookCustomerAddressEntity(ctx context.Context, ef dml.EventFlag, ec *CustomerAddressEntities, e *CustomerAddressEntity) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookCustomerAddressEntity(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(CustomerAddressEntityBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(CustomerAddressEntityBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(CustomerAddressEntityAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventCustomerEntityFunc(ctx context.Context, ef dml.EventFlag, ec *CustomerEntities, e *CustomerEntity) error {
-	if len(dbm.option.eventCustomerEntityFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookCustomerEntity(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventCustomerEntityFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -169,10 +342,63 @@ func (dbm DBM) eventCustomerEntityFunc(ctx context.Context, ef dml.EventFlag, ec
 	return nil
 }
 
+// CustomerEntityBeforeInserter gets called before inserting a CustomerEntity or
+// a CustomerEntities into the database. Implement it in a non-generated file.
+type CustomerEntityBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// CustomerEntityBeforeUpdater gets called before updating a CustomerEntity or a
+// CustomerEntities in the database. Implement it in a non-generated file.
+type CustomerEntityBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// CustomerEntityAfterLoader gets called after loading a CustomerEntity or a
+// CustomerEntities from the database. Implement it in a non-generated file.
+type CustomerEntityAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookCustomerEntity calls the optional hook interfaces of each entity. The
+// hooks run before the event functions added via
+// DBMOption.AddEventCustomerEntity and get skipped with dml.EventsAreSkipped.
+func hookCustomerEntity(ctx context.Context, ef dml.EventFlag, ec *CustomerEntities, e *CustomerEntity) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookCustomerEntity(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(CustomerEntityBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(CustomerEntityBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(CustomerEntityAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventDmlgenTypesFunc(ctx context.Context, ef dml.EventFlag, ec *DmlgenTypesCollection, e *DmlgenTypes) error {
-	if len(dbm.option.eventDmlgenTypesFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookDmlgenTypes(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventDmlgenTypesFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -181,10 +407,65 @@ func (dbm DBM) eventDmlgenTypesFunc(ctx context.Context, ef dml.EventFlag, ec *D
 	return nil
 }
 
+// DmlgenTypesBeforeInserter gets called before inserting a DmlgenTypes or a
+// DmlgenTypesCollection into the database. Implement it in a non-generated
+// file.
+type DmlgenTypesBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// DmlgenTypesBeforeUpdater gets called before updating a DmlgenTypes or a
+// DmlgenTypesCollection in the database. Implement it in a non-generated file.
+type DmlgenTypesBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// DmlgenTypesAfterLoader gets called after loading a DmlgenTypes or a
+// DmlgenTypesCollection from the database. Implement it in a non-generated
+// file.
+type DmlgenTypesAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookDmlgenTypes calls the optional hook interfaces of each entity. The hooks
+// run before the event functions added via DBMOption.AddEventDmlgenTypes and
+// get skipped with dml.EventsAreSkipped.
+func hookDmlgenTypes(ctx context.Context, ef dml.EventFlag, ec *DmlgenTypesCollection, e *DmlgenTypes) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookDmlgenTypes(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(DmlgenTypesBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(DmlgenTypesBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(DmlgenTypesAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventSalesOrderStatusStateFunc(ctx context.Context, ef dml.EventFlag, ec *SalesOrderStatusStates, e *SalesOrderStatusState) error {
-	if len(dbm.option.eventSalesOrderStatusStateFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookSalesOrderStatusState(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventSalesOrderStatusStateFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -193,10 +474,67 @@ func (dbm DBM) eventSalesOrderStatusStateFunc(ctx context.Context, ef dml.EventF
 	return nil
 }
 
+// SalesOrderStatusStateBeforeInserter gets called before inserting a
+// SalesOrderStatusState or a SalesOrderStatusStates into the database.
+// Implement it in a non-generated file.
+type SalesOrderStatusStateBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// SalesOrderStatusStateBeforeUpdater gets called before updating a
+// SalesOrderStatusState or a SalesOrderStatusStates in the database. Implement
+// it in a non-generated file.
+type SalesOrderStatusStateBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// SalesOrderStatusStateAfterLoader gets called after loading a
+// SalesOrderStatusState or a SalesOrderStatusStates from the database.
+// Implement it in a non-generated file.
+type SalesOrderStatusStateAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookSalesOrderStatusState calls the optional hook interfaces of each entity.
+// The hooks run before the event functions added via
+// DBMOption.AddEventSalesOrderStatusState and get skipped with
+// dml.EventsAreSkipped.
+func hookSalesOrderStatusState(ctx context.Context, ef dml.EventFlag, ec *SalesOrderStatusStates, e *SalesOrderStatusState) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookSalesOrderStatusState(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(SalesOrderStatusStateBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(SalesOrderStatusStateBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(SalesOrderStatusStateAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventViewCustomerAutoIncrementFunc(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerAutoIncrements, e *ViewCustomerAutoIncrement) error {
-	if len(dbm.option.eventViewCustomerAutoIncrementFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookViewCustomerAutoIncrement(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventViewCustomerAutoIncrementFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -205,10 +543,67 @@ func (dbm DBM) eventViewCustomerAutoIncrementFunc(ctx context.Context, ef dml.Ev
 	return nil
 }
 
+// ViewCustomerAutoIncrementBeforeInserter gets called before inserting a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements into the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// ViewCustomerAutoIncrementBeforeUpdater gets called before updating a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements in the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// ViewCustomerAutoIncrementAfterLoader gets called after loading a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements from the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookViewCustomerAutoIncrement calls the optional hook interfaces of each
+// entity. The hooks run before the event functions added via
+// DBMOption.AddEventViewCustomerAutoIncrement and get skipped with
+// dml.EventsAreSkipped.
+func hookViewCustomerAutoIncrement(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerAutoIncrements, e *ViewCustomerAutoIncrement) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookViewCustomerAutoIncrement(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventViewCustomerNoAutoIncrementFunc(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerNoAutoIncrements, e *ViewCustomerNoAutoIncrement) error {
-	if len(dbm.option.eventViewCustomerNoAutoIncrementFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookViewCustomerNoAutoIncrement(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventViewCustomerNoAutoIncrementFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -217,6 +612,60 @@ func (dbm DBM) eventViewCustomerNoAutoIncrementFunc(ctx context.Context, ef dml.
 	return nil
 }
 
+// ViewCustomerNoAutoIncrementBeforeInserter gets called before inserting a
+// ViewCustomerNoAutoIncrement or a ViewCustomerNoAutoIncrements into the
+// database. Implement it in a non-generated file.
+type ViewCustomerNoAutoIncrementBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// ViewCustomerNoAutoIncrementBeforeUpdater gets called before updating a
+// ViewCustomerNoAutoIncrement or a ViewCustomerNoAutoIncrements in the
+// database. Implement it in a non-generated file.
+type ViewCustomerNoAutoIncrementBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// ViewCustomerNoAutoIncrementAfterLoader gets called after loading a
+// ViewCustomerNoAutoIncrement or a ViewCustomerNoAutoIncrements from the
+// database. Implement it in a non-generated file.
+type ViewCustomerNoAutoIncrementAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookViewCustomerNoAutoIncrement calls the optional hook interfaces of each
+// entity. The hooks run before the event functions added via
+// DBMOption.AddEventViewCustomerNoAutoIncrement and get skipped with
+// dml.EventsAreSkipped.
+func hookViewCustomerNoAutoIncrement(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerNoAutoIncrements, e *ViewCustomerNoAutoIncrement) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookViewCustomerNoAutoIncrement(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(ViewCustomerNoAutoIncrementBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(ViewCustomerNoAutoIncrementBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(ViewCustomerNoAutoIncrementAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 // NewDBManager returns a goified version of the MySQL/MariaDB table schema for
 // the tables:  catalog_product_index_eav_decimal_idx, core_configuration,
 // customer_address_entity, customer_entity, dmlgen_types,
@@ -245,16 +694,22 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Columns(`entity_id`, `attribute_id`, `store_id`, `source_id`).In().Tuples(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Select("*")).Where(
-			dml.Columns(`entity_id`, `attribute_id`, `store_id`, `source_id`).Equal().Tuples(),
+			dml.Column(`entity_id`).Equal().PlaceHolder(),
+			dml.Column(`attribute_id`).Equal().PlaceHolder(),
+			dml.Column(`store_id`).Equal().PlaceHolder(),
+			dml.Column(`source_id`).Equal().PlaceHolder(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXUpdateByPK", dbmo.InitUpdateFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Update().Where(
-			dml.Columns(`entity_id`, `attribute_id`, `store_id`, `source_id`).In().Tuples(),
+			dml.Column(`entity_id`).Equal().PlaceHolder(),
+			dml.Column(`attribute_id`).Equal().PlaceHolder(),
+			dml.Column(`store_id`).Equal().PlaceHolder(),
+			dml.Column(`source_id`).Equal().PlaceHolder(),
 		)).WithDBR()),
 		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXDeleteByPK", dbmo.InitDeleteFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Delete().Where(
 			dml.Columns(`entity_id`, `attribute_id`, `store_id`, `source_id`).In().Tuples(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Insert()).WithDBR()),
-		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("CatalogProductIndexEAVDecimalIDXUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCatalogProductIndexEAVDecimalIDX).Insert().AddOnDuplicateKeyExclude(`entity_id`, `attribute_id`, `store_id`, `source_id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("CoreConfigurationsSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameCoreConfiguration).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("CoreConfigurationsSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameCoreConfiguration).Select("*")).Where(
 			dml.Column(`config_id`).In().PlaceHolder(),
@@ -269,7 +724,7 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Column(`config_id`).In().PlaceHolder(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CoreConfigurationInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert()).WithDBR()),
-		ddl.WithQueryDBR("CoreConfigurationUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("CoreConfigurationUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert().AddColumns(`config_id`).AddOnDuplicateKeyExclude(`config_id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("CustomerAddressEntitiesSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameCustomerAddressEntity).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("CustomerAddressEntitiesSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameCustomerAddressEntity).Select("*")).Where(
 			dml.Column(`entity_id`).In().PlaceHolder(),
@@ -284,7 +739,7 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Column(`entity_id`).In().PlaceHolder(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CustomerAddressEntityInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerAddressEntity).Insert()).WithDBR()),
-		ddl.WithQueryDBR("CustomerAddressEntityUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerAddressEntity).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("CustomerAddressEntityUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerAddressEntity).Insert().AddColumns(`entity_id`).AddOnDuplicateKeyExclude(`entity_id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("CustomerEntitiesSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameCustomerEntity).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("CustomerEntitiesSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameCustomerEntity).Select("*")).Where(
 			dml.Column(`entity_id`).In().PlaceHolder(),
@@ -299,7 +754,7 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Column(`entity_id`).In().PlaceHolder(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CustomerEntityInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerEntity).Insert()).WithDBR()),
-		ddl.WithQueryDBR("CustomerEntityUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerEntity).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("CustomerEntityUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerEntity).Insert().AddColumns(`entity_id`).AddOnDuplicateKeyExclude(`entity_id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("DmlgenTypesCollectionSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameDmlgenTypes).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("DmlgenTypesCollectionSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameDmlgenTypes).Select("*")).Where(
 			dml.Column(`id`).In().PlaceHolder(),
@@ -314,22 +769,24 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Column(`id`).In().PlaceHolder(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("DmlgenTypesInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameDmlgenTypes).Insert()).WithDBR()),
-		ddl.WithQueryDBR("DmlgenTypesUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameDmlgenTypes).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("DmlgenTypesUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameDmlgenTypes).Insert().AddColumns(`id`).AddOnDuplicateKeyExclude(`id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("SalesOrderStatusStatesSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("SalesOrderStatusStatesSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).Where(
 			dml.Columns(`status`, `state`).In().Tuples(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("SalesOrderStatusStateSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).Where(
-			dml.Columns(`status`, `state`).Equal().Tuples(),
+			dml.Column(`status`).Equal().PlaceHolder(),
+			dml.Column(`state`).Equal().PlaceHolder(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("SalesOrderStatusStateUpdateByPK", dbmo.InitUpdateFn(tbls.MustTable(TableNameSalesOrderStatusState).Update().Where(
-			dml.Columns(`status`, `state`).In().Tuples(),
+			dml.Column(`status`).Equal().PlaceHolder(),
+			dml.Column(`state`).Equal().PlaceHolder(),
 		)).WithDBR()),
 		ddl.WithQueryDBR("SalesOrderStatusStateDeleteByPK", dbmo.InitDeleteFn(tbls.MustTable(TableNameSalesOrderStatusState).Delete().Where(
 			dml.Columns(`status`, `state`).In().Tuples(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("SalesOrderStatusStateInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameSalesOrderStatusState).Insert()).WithDBR()),
-		ddl.WithQueryDBR("SalesOrderStatusStateUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameSalesOrderStatusState).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("SalesOrderStatusStateUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameSalesOrderStatusState).Insert().AddOnDuplicateKeyExclude(`status`, `state`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("ViewCustomerAutoIncrementsSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameViewCustomerAutoIncrement).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("ViewCustomerAutoIncrementsSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameViewCustomerAutoIncrement).Select("*")).Where(
 			dml.Column(`ce_entity_id`).In().PlaceHolder(),
@@ -354,11 +811,16 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 // catalog_product_index_eav_decimal_idx. Auto generated.
 // Table comment: Catalog Product EAV Decimal Indexer Index Table
 type CatalogProductIndexEAVDecimalIDX struct {
-	EntityID    uint32       // entity_id int(10) unsigned NOT NULL PRI   "Entity ID"
-	AttributeID uint32       // attribute_id smallint(5) unsigned NOT NULL PRI   "Attribute ID"
-	StoreID     uint32       // store_id smallint(5) unsigned NOT NULL PRI   "Store ID"
-	SourceID    uint32       // source_id int(10) unsigned NOT NULL PRI DEFAULT '0'  "Original entity Id for attribute value"
-	Value       null.Decimal // value decimal(12,4) NOT NULL MUL   "Value"
+	// EntityID Entity ID
+	EntityID uint32 // entity_id int(10) unsigned NOT NULL PRI
+	// AttributeID Attribute ID
+	AttributeID uint32 // attribute_id smallint(5) unsigned NOT NULL PRI
+	// StoreID Store ID
+	StoreID uint32 // store_id smallint(5) unsigned NOT NULL PRI
+	// SourceID Original entity Id for attribute value
+	SourceID uint32 // source_id int(10) unsigned NOT NULL PRI DEFAULT '0'
+	// Value Value
+	Value null.Decimal // value decimal(12,4) NOT NULL MUL
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -430,6 +892,13 @@ func (e *CatalogProductIndexEAVDecimalIDX) Delete(ctx context.Context, dbm *DBM,
 	return res, nil
 }
 
+// CatalogProductIndexEAVDecimalIDXDeleteByPK deletes the row with the primary
+// key without loading it. Auto generated.
+func (dbm *DBM) CatalogProductIndexEAVDecimalIDXDeleteByPK(ctx context.Context, entityID uint32, attributeID uint32, storeID uint32, sourceID uint32) (res sql.Result, err error) {
+	e := &CatalogProductIndexEAVDecimalIDX{EntityID: entityID, AttributeID: attributeID, StoreID: storeID, SourceID: sourceID}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *CatalogProductIndexEAVDecimalIDX) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CatalogProductIndexEAVDecimalIDXUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -653,7 +1122,14 @@ func (cc *CatalogProductIndexEAVDecimalIDXes) DBDelete(ctx context.Context, dbm
 	if err = dbm.eventCatalogProductIndexEAVDecimalIDXFunc(ctx, dml.EventFlagBeforeDelete, cc, nil); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if res, err = dbm.CachedQuery("CatalogProductIndexEAVDecimalIDXDeleteByPK").ApplyCallBacks(opts...).ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+	args := make([]interface{}, 0, len(cc.Data)*4)
+	for _, e := range cc.Data {
+		args = append(args, e.EntityID)
+		args = append(args, e.AttributeID)
+		args = append(args, e.StoreID)
+		args = append(args, e.SourceID)
+	}
+	if res, err = dbm.CachedQuery("CatalogProductIndexEAVDecimalIDXDeleteByPK").ApplyCallBacks(opts...).ExecContext(ctx, args...); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	if err = errors.WithStack(dbm.eventCatalogProductIndexEAVDecimalIDXFunc(ctx, dml.EventFlagAfterDelete, cc, nil)); err != nil {
@@ -662,6 +1138,19 @@ func (cc *CatalogProductIndexEAVDecimalIDXes) DBDelete(ctx context.Context, dbm
 	return res, nil
 }
 
+// CatalogProductIndexEAVDecimalIDXesDeleteByPKs deletes all rows with the
+// primary keys without loading them. Auto generated.
+func (dbm *DBM) CatalogProductIndexEAVDecimalIDXesDeleteByPKs(ctx context.Context, pkIDs ...CatalogProductIndexEAVDecimalIDXesDBLoadArgs) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &CatalogProductIndexEAVDecimalIDXes{Data: make([]*CatalogProductIndexEAVDecimalIDX, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &CatalogProductIndexEAVDecimalIDX{EntityID: pk.EntityID, AttributeID: pk.AttributeID, StoreID: pk.StoreID, SourceID: pk.SourceID})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *CatalogProductIndexEAVDecimalIDXes) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CatalogProductIndexEAVDecimalIDXesUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -680,6 +1169,15 @@ func (cc *CatalogProductIndexEAVDecimalIDXes) DBUpdate(ctx context.Context, dbm
 	return res, nil
 }
 
+// CatalogProductIndexEAVDecimalIDXUpdateByPK updates all columns of the entities
+// matched by their primary key. Auto generated.
+func (dbm *DBM) CatalogProductIndexEAVDecimalIDXUpdateByPK(ctx context.Context, entities ...*CatalogProductIndexEAVDecimalIDX) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&CatalogProductIndexEAVDecimalIDXes{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *CatalogProductIndexEAVDecimalIDXes) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CatalogProductIndexEAVDecimalIDXesInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -716,6 +1214,29 @@ func (cc *CatalogProductIndexEAVDecimalIDXes) DBUpsert(ctx context.Context, dbm
 	return res, nil
 }
 
+// CatalogProductIndexEAVDecimalIDXUpsertByPK inserts new entities or updates all
+// non primary key columns of the existing entities with one INSERT ... ON
+// DUPLICATE KEY UPDATE statement. Entities without an auto increment ID get the
+// new IDs assigned. Auto generated.
+func (dbm *DBM) CatalogProductIndexEAVDecimalIDXUpsertByPK(ctx context.Context, entities ...*CatalogProductIndexEAVDecimalIDX) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "CatalogProductIndexEAVDecimalIDXUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &CatalogProductIndexEAVDecimalIDXes{Data: entities}
+	if err = dbm.eventCatalogProductIndexEAVDecimalIDXFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if res, err = dbm.CachedQuery("CatalogProductIndexEAVDecimalIDXUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = dbm.eventCatalogProductIndexEAVDecimalIDXFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *CatalogProductIndexEAVDecimalIDXes) Delete(i int) *CatalogProductIndexEAVDecimalIDXes {
 	z := cc.Data // copy the slice header
@@ -868,19 +1389,97 @@ func (cc *CatalogProductIndexEAVDecimalIDXes) WriteTo(w io.Writer) (n int64, err
 	return n, nil
 }
 
+// CatalogProductIndexEAVDecimalIDXFilters composes typed WHERE conditions for
+// the indexed columns of table catalog_product_index_eav_decimal_idx and applies
+// them to a SELECT. Auto generated.
+type CatalogProductIndexEAVDecimalIDXFilters struct {
+	conditions dml.Conditions
+}
+
+// NewCatalogProductIndexEAVDecimalIDXFilters creates a new empty filter. Auto
+// generated.
+func NewCatalogProductIndexEAVDecimalIDXFilters() *CatalogProductIndexEAVDecimalIDXFilters {
+	return &CatalogProductIndexEAVDecimalIDXFilters{}
+}
+
+// WhereEntityID adds the condition entity_id = ?. Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereEntityID(v uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereEntityIDIn adds the condition entity_id IN (?). Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereEntityIDIn(vs ...uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereAttributeID adds the condition attribute_id = ?. Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereAttributeID(v uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`attribute_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereAttributeIDIn adds the condition attribute_id IN (?). Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereAttributeIDIn(vs ...uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`attribute_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereStoreID adds the condition store_id = ?. Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereStoreID(v uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`store_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereStoreIDIn adds the condition store_id IN (?). Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereStoreIDIn(vs ...uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`store_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereSourceID adds the condition source_id = ?. Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereSourceID(v uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`source_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereSourceIDIn adds the condition source_id IN (?). Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) WhereSourceIDIn(vs ...uint64) *CatalogProductIndexEAVDecimalIDXFilters {
+	f.conditions = append(f.conditions, dml.Column(`source_id`).In().Uint64s(vs...))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *CatalogProductIndexEAVDecimalIDXFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // CoreConfiguration represents a single row for DB table core_configuration.
 // Auto generated.
 // Table comment: Config Data
 //easyjson:json
 type CoreConfiguration struct {
-	ConfigID  uint32      `json:"config_id,omitempty" max_len:"10"`  // config_id int(10) unsigned NOT NULL PRI  auto_increment "Id"
-	Scope     string      `json:"scope,omitempty" max_len:"8"`       // scope varchar(8) NOT NULL MUL DEFAULT ''default''  "Scope"
-	ScopeID   int32       `json:"scope_id" xml:"scope_id"`           // scope_id int(11) NOT NULL  DEFAULT '0'  "Scope Id"
-	Expires   null.Time   `json:"expires,omitempty" `                // expires datetime NULL  DEFAULT 'NULL'  "Value expiration time"
-	Path      string      `json:"x_path" xml:"y_path" max_len:"255"` // path varchar(255) NOT NULL  DEFAULT ''general''  "Config Path overwritten"
-	Value     null.String `json:"value,omitempty" max_len:"65535"`   // value text NULL  DEFAULT 'NULL'  "Value"
-	VersionTs time.Time   `json:"version_ts,omitempty" `             // version_ts timestamp(6) NOT NULL   STORED GENERATED "Timestamp Start Versioning"
-	VersionTe time.Time   `json:"version_te,omitempty" `             // version_te timestamp(6) NOT NULL PRI  STORED GENERATED "Timestamp End Versioning"
+	// ConfigID Id
+	ConfigID uint32 `json:"config_id,omitempty" max_len:"10"` // config_id int(10) unsigned NOT NULL PRI  auto_increment
+	// Scope Scope
+	Scope string `json:"scope,omitempty" max_len:"8"` // scope varchar(8) NOT NULL MUL DEFAULT ''default''
+	// ScopeID Scope Id
+	ScopeID int32 `json:"scope_id" xml:"scope_id"` // scope_id int(11) NOT NULL  DEFAULT '0'
+	// Expires Value expiration time
+	Expires null.Time `json:"expires,omitempty" ` // expires datetime NULL  DEFAULT 'NULL'
+	// Path Config Path overwritten
+	Path string `json:"x_path" xml:"y_path" max_len:"255"` // path varchar(255) NOT NULL  DEFAULT ''general''
+	// Value Value
+	Value null.String `json:"value,omitempty" max_len:"65535"` // value text NULL  DEFAULT 'NULL'
+	// VersionTs Timestamp Start Versioning
+	VersionTs time.Time `json:"version_ts,omitempty" ` // version_ts timestamp(6) NOT NULL   STORED GENERATED
+	// VersionTe Timestamp End Versioning
+	VersionTe time.Time `json:"version_te,omitempty" ` // version_te timestamp(6) NOT NULL PRI  STORED GENERATED
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -964,6 +1563,13 @@ func (e *CoreConfiguration) Delete(ctx context.Context, dbm *DBM, opts ...dml.DB
 	return res, nil
 }
 
+// CoreConfigurationDeleteByPK deletes the row with the primary key without
+// loading it. Auto generated.
+func (dbm *DBM) CoreConfigurationDeleteByPK(ctx context.Context, configID uint32) (res sql.Result, err error) {
+	e := &CoreConfiguration{ConfigID: configID}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *CoreConfiguration) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CoreConfigurationUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1193,6 +1799,19 @@ func (cc *CoreConfigurations) DBDelete(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationsDeleteByPKs deletes all rows with the primary keys without
+// loading them. Auto generated.
+func (dbm *DBM) CoreConfigurationsDeleteByPKs(ctx context.Context, pkIDs ...uint32) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &CoreConfigurations{Data: make([]*CoreConfiguration, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &CoreConfiguration{ConfigID: pk})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *CoreConfigurations) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CoreConfigurationsUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1211,6 +1830,15 @@ func (cc *CoreConfigurations) DBUpdate(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationUpdateByPK updates all columns of the entities matched by
+// their primary key. Auto generated.
+func (dbm *DBM) CoreConfigurationUpdateByPK(ctx context.Context, entities ...*CoreConfiguration) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&CoreConfigurations{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *CoreConfigurations) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CoreConfigurationsInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1247,6 +1875,46 @@ func (cc *CoreConfigurations) DBUpsert(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationUpsertByPK inserts new entities or updates all non primary
+// key columns of the existing entities with one INSERT ... ON DUPLICATE KEY
+// UPDATE statement. Entities without an auto increment ID get the new IDs
+// assigned. Auto generated.
+func (dbm *DBM) CoreConfigurationUpsertByPK(ctx context.Context, entities ...*CoreConfiguration) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "CoreConfigurationUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &CoreConfigurations{Data: entities}
+	if err = dbm.eventCoreConfigurationFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ids := make([]uint32, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ConfigID
+	}
+	if res, err = dbm.CachedQuery("CoreConfigurationUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.ConfigID = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}
+	if err = dbm.eventCoreConfigurationFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *CoreConfigurations) Delete(i int) *CoreConfigurations {
 	z := cc.Data // copy the slice header
@@ -1372,36 +2040,110 @@ func (cc *CoreConfigurations) WriteTo(w io.Writer) (n int64, err error) {
 	return n, nil
 }
 
+// CoreConfigurationFilters composes typed WHERE conditions for the indexed
+// columns of table core_configuration and applies them to a SELECT. Auto
+// generated.
+type CoreConfigurationFilters struct {
+	conditions dml.Conditions
+}
+
+// NewCoreConfigurationFilters creates a new empty filter. Auto generated.
+func NewCoreConfigurationFilters() *CoreConfigurationFilters { return &CoreConfigurationFilters{} }
+
+// WhereConfigID adds the condition config_id = ?. Auto generated.
+func (f *CoreConfigurationFilters) WhereConfigID(v uint64) *CoreConfigurationFilters {
+	f.conditions = append(f.conditions, dml.Column(`config_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereConfigIDIn adds the condition config_id IN (?). Auto generated.
+func (f *CoreConfigurationFilters) WhereConfigIDIn(vs ...uint64) *CoreConfigurationFilters {
+	f.conditions = append(f.conditions, dml.Column(`config_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereScope adds the condition scope = ?. Auto generated.
+func (f *CoreConfigurationFilters) WhereScope(v string) *CoreConfigurationFilters {
+	f.conditions = append(f.conditions, dml.Column(`scope`).Equal().Str(v))
+	return f
+}
+
+// WhereScopeIn adds the condition scope IN (?). Auto generated.
+func (f *CoreConfigurationFilters) WhereScopeIn(vs ...string) *CoreConfigurationFilters {
+	f.conditions = append(f.conditions, dml.Column(`scope`).In().Strs(vs...))
+	return f
+}
+
+// WhereScopeLike adds the condition scope LIKE ?. Auto generated.
+func (f *CoreConfigurationFilters) WhereScopeLike(s string) *CoreConfigurationFilters {
+	f.conditions = append(f.conditions, dml.Column(`scope`).Like().Str(s))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *CoreConfigurationFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *CoreConfigurationFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // CustomerAddressEntity represents a single row for DB table
 // customer_address_entity. Auto generated.
 // Table comment: Customer Address Entity
 //easyjson:json
 type CustomerAddressEntity struct {
-	EntityID          uint32      `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	IncrementID       null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	ParentID          null.Uint32 `max_len:"10"` // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'  "Parent ID"
-	CreatedAt         time.Time   // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt         time.Time   // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive          bool        `max_len:"5"`     // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	City              string      `max_len:"255"`   // city varchar(255) NOT NULL    "City"
-	Company           null.String `max_len:"255"`   // company varchar(255) NULL  DEFAULT 'NULL'  "Company"
-	CountryID         string      `max_len:"255"`   // country_id varchar(255) NOT NULL    "Country"
-	Fax               null.String `max_len:"255"`   // fax varchar(255) NULL  DEFAULT 'NULL'  "Fax"
-	Firstname         string      `max_len:"255"`   // firstname varchar(255) NOT NULL    "First Name"
-	Lastname          string      `max_len:"255"`   // lastname varchar(255) NOT NULL    "Last Name"
-	Middlename        null.String `max_len:"255"`   // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name"
-	Postcode          null.String `max_len:"255"`   // postcode varchar(255) NULL  DEFAULT 'NULL'  "Zip/Postal Code"
-	Prefix            null.String `max_len:"40"`    // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Region            null.String `max_len:"255"`   // region varchar(255) NULL  DEFAULT 'NULL'  "State/Province"
-	RegionID          null.Uint32 `max_len:"10"`    // region_id int(10) unsigned NULL  DEFAULT 'NULL'  "State/Province"
-	Street            string      `max_len:"65535"` // street text NOT NULL    "Street Address"
-	Suffix            null.String `max_len:"40"`    // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Telephone         string      `max_len:"255"`   // telephone varchar(255) NOT NULL    "Phone Number"
-	VatID             null.String `max_len:"255"`   // vat_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number"
-	VatIsValid        null.Bool   `max_len:"10"`    // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validity"
-	VatRequestDate    null.String `max_len:"255"`   // vat_request_date varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request date"
-	VatRequestID      null.String `max_len:"255"`   // vat_request_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request ID"
-	VatRequestSuccess null.Uint32 `max_len:"10"`    // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validation request success"
+	// EntityID Entity ID
+	EntityID uint32 `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// IncrementID Increment Id
+	IncrementID null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// ParentID Parent ID
+	ParentID null.Uint32 `max_len:"10"` // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool `max_len:"5"` // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// City City
+	City string `max_len:"255"` // city varchar(255) NOT NULL
+	// Company Company
+	Company null.String `max_len:"255"` // company varchar(255) NULL  DEFAULT 'NULL'
+	// CountryID Country
+	CountryID string `max_len:"255"` // country_id varchar(255) NOT NULL
+	// Fax Fax
+	Fax null.String `max_len:"255"` // fax varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string `max_len:"255"` // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string `max_len:"255"` // lastname varchar(255) NOT NULL
+	// Middlename Middle Name
+	Middlename null.String `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Postcode Zip/Postal Code
+	Postcode null.String `max_len:"255"` // postcode varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String `max_len:"40"` // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Region State/Province
+	Region null.String `max_len:"255"` // region varchar(255) NULL  DEFAULT 'NULL'
+	// RegionID State/Province
+	RegionID null.Uint32 `max_len:"10"` // region_id int(10) unsigned NULL  DEFAULT 'NULL'
+	// Street Street Address
+	Street string `max_len:"65535"` // street text NOT NULL
+	// Suffix Name Suffix
+	Suffix null.String `max_len:"40"` // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Telephone Phone Number
+	Telephone string `max_len:"255"` // telephone varchar(255) NOT NULL
+	// VatID VAT number
+	VatID null.String `max_len:"255"` // vat_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatIsValid VAT number validity
+	VatIsValid null.Bool `max_len:"10"` // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'
+	// VatRequestDate VAT number validation request date
+	VatRequestDate null.String `max_len:"255"` // vat_request_date varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestID VAT number validation request ID
+	VatRequestID null.String `max_len:"255"` // vat_request_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestSuccess VAT number validation request success
+	VatRequestSuccess null.Uint32 `max_len:"10"` // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -1519,6 +2261,13 @@ func (e *CustomerAddressEntity) Delete(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CustomerAddressEntityDeleteByPK deletes the row with the primary key without
+// loading it. Auto generated.
+func (dbm *DBM) CustomerAddressEntityDeleteByPK(ctx context.Context, entityID uint32) (res sql.Result, err error) {
+	e := &CustomerAddressEntity{EntityID: entityID}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *CustomerAddressEntity) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerAddressEntityUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1769,6 +2518,19 @@ func (cc *CustomerAddressEntities) DBDelete(ctx context.Context, dbm *DBM, opts
 	return res, nil
 }
 
+// CustomerAddressEntitiesDeleteByPKs deletes all rows with the primary keys
+// without loading them. Auto generated.
+func (dbm *DBM) CustomerAddressEntitiesDeleteByPKs(ctx context.Context, pkIDs ...uint32) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &CustomerAddressEntities{Data: make([]*CustomerAddressEntity, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &CustomerAddressEntity{EntityID: pk})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *CustomerAddressEntities) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerAddressEntitiesUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1787,6 +2549,15 @@ func (cc *CustomerAddressEntities) DBUpdate(ctx context.Context, dbm *DBM, opts
 	return res, nil
 }
 
+// CustomerAddressEntityUpdateByPK updates all columns of the entities matched by
+// their primary key. Auto generated.
+func (dbm *DBM) CustomerAddressEntityUpdateByPK(ctx context.Context, entities ...*CustomerAddressEntity) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&CustomerAddressEntities{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *CustomerAddressEntities) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerAddressEntitiesInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -1823,6 +2594,46 @@ func (cc *CustomerAddressEntities) DBUpsert(ctx context.Context, dbm *DBM, opts
 	return res, nil
 }
 
+// CustomerAddressEntityUpsertByPK inserts new entities or updates all non
+// primary key columns of the existing entities with one INSERT ... ON DUPLICATE
+// KEY UPDATE statement. Entities without an auto increment ID get the new IDs
+// assigned. Auto generated.
+func (dbm *DBM) CustomerAddressEntityUpsertByPK(ctx context.Context, entities ...*CustomerAddressEntity) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "CustomerAddressEntityUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &CustomerAddressEntities{Data: entities}
+	if err = dbm.eventCustomerAddressEntityFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ids := make([]uint32, len(entities))
+	for i, e := range entities {
+		ids[i] = e.EntityID
+	}
+	if res, err = dbm.CachedQuery("CustomerAddressEntityUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.EntityID = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}
+	if err = dbm.eventCustomerAddressEntityFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *CustomerAddressEntities) Delete(i int) *CustomerAddressEntities {
 	z := cc.Data // copy the slice header
@@ -1928,39 +2739,112 @@ func (cc *CustomerAddressEntities) WriteTo(w io.Writer) (n int64, err error) {
 	return n, nil
 }
 
+// CustomerAddressEntityFilters composes typed WHERE conditions for the indexed
+// columns of table customer_address_entity and applies them to a SELECT. Auto
+// generated.
+type CustomerAddressEntityFilters struct {
+	conditions dml.Conditions
+}
+
+// NewCustomerAddressEntityFilters creates a new empty filter. Auto generated.
+func NewCustomerAddressEntityFilters() *CustomerAddressEntityFilters {
+	return &CustomerAddressEntityFilters{}
+}
+
+// WhereEntityID adds the condition entity_id = ?. Auto generated.
+func (f *CustomerAddressEntityFilters) WhereEntityID(v uint64) *CustomerAddressEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereEntityIDIn adds the condition entity_id IN (?). Auto generated.
+func (f *CustomerAddressEntityFilters) WhereEntityIDIn(vs ...uint64) *CustomerAddressEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereParentID adds the condition parent_id = ?. Auto generated.
+func (f *CustomerAddressEntityFilters) WhereParentID(v uint64) *CustomerAddressEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`parent_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereParentIDIn adds the condition parent_id IN (?). Auto generated.
+func (f *CustomerAddressEntityFilters) WhereParentIDIn(vs ...uint64) *CustomerAddressEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`parent_id`).In().Uint64s(vs...))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *CustomerAddressEntityFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *CustomerAddressEntityFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // CustomerEntity represents a single row for DB table customer_entity. Auto
 // generated.
 // Table comment: Customer Entity
 //easyjson:json
 type CustomerEntity struct {
-	EntityID                uint32                   `max_len:"10"`  // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	WebsiteID               null.Uint32              `max_len:"5"`   // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'  "Website ID"
-	Email                   null.String              `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'  "Email"
-	GroupID                 uint32                   `max_len:"5"`   // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'  "Group ID"
-	IncrementID             null.String              `max_len:"50"`  // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	StoreID                 null.Uint32              `max_len:"5"`   // store_id smallint(5) unsigned NULL MUL DEFAULT '0'  "Store ID"
-	CreatedAt               time.Time                // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt               time.Time                // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive                bool                     `max_len:"5"`   // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	DisableAutoGroupChange  uint32                   `max_len:"5"`   // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'  "Disable automatic group change based on VAT ID"
-	CreatedIn               null.String              `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'  "Created From"
-	Prefix                  null.String              `max_len:"40"`  // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Firstname               null.String              `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'  "First Name"
-	Middlename              null.String              `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name/Initial"
-	Lastname                null.String              `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'  "Last Name"
-	Suffix                  null.String              `max_len:"40"`  // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Dob                     null.Time                // dob date NULL  DEFAULT 'NULL'  "Date of Birth"
-	passwordHash            null.String              `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'  "Password_hash"
-	RpToken                 null.String              `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'  "Reset password token"
-	RpTokenCreatedAt        null.Time                // rp_token_created_at datetime NULL  DEFAULT 'NULL'  "Reset password token creation time"
-	DefaultBilling          null.Uint32              `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'  "Default Billing Address"
-	DefaultShipping         null.Uint32              `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'  "Default Shipping Address"
-	Taxvat                  null.String              `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'  "Tax/VAT Number"
-	Confirmation            null.String              `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'  "Is Confirmed"
-	Gender                  null.Uint32              `max_len:"5"`  // gender smallint(5) unsigned NULL  DEFAULT 'NULL'  "Gender"
-	FailuresNum             null.Int32               `max_len:"5"`  // failures_num smallint(6) NULL  DEFAULT '0'  "Failure Number"
-	FirstFailure            null.Time                // first_failure timestamp NULL  DEFAULT 'NULL'  "First Failure"
-	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'  "Lock Expiration Date"
+	// EntityID Entity ID
+	EntityID uint32 `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// WebsiteID Website ID
+	WebsiteID null.Uint32 `max_len:"5"` // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'
+	// Email Email
+	Email null.String `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'
+	// GroupID Group ID
+	GroupID uint32 `max_len:"5"` // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// IncrementID Increment Id
+	IncrementID null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// StoreID Store ID
+	StoreID null.Uint32 `max_len:"5"` // store_id smallint(5) unsigned NULL MUL DEFAULT '0'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool `max_len:"5"` // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// DisableAutoGroupChange Disable automatic group change based on VAT ID
+	DisableAutoGroupChange uint32 `max_len:"5"` // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// CreatedIn Created From
+	CreatedIn null.String `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String `max_len:"40"` // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname null.String `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Middlename Middle Name/Initial
+	Middlename null.String `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Lastname Last Name
+	Lastname null.String `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Suffix Name Suffix
+	Suffix null.String `max_len:"40"` // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Dob Date of Birth
+	Dob null.Time // dob date NULL  DEFAULT 'NULL'
+	// passwordHash Password_hash
+	passwordHash null.String `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'
+	// RpToken Reset password token
+	RpToken null.String `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'
+	// RpTokenCreatedAt Reset password token creation time
+	RpTokenCreatedAt null.Time // rp_token_created_at datetime NULL  DEFAULT 'NULL'
+	// DefaultBilling Default Billing Address
+	DefaultBilling null.Uint32 `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'
+	// DefaultShipping Default Shipping Address
+	DefaultShipping null.Uint32 `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'
+	// Taxvat Tax/VAT Number
+	Taxvat null.String `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'
+	// Confirmation Is Confirmed
+	Confirmation null.String `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'
+	// Gender Gender
+	Gender null.Uint32 `max_len:"5"` // gender smallint(5) unsigned NULL  DEFAULT 'NULL'
+	// FailuresNum Failure Number
+	FailuresNum null.Int32 `max_len:"5"` // failures_num smallint(6) NULL  DEFAULT '0'
+	// FirstFailure First Failure
+	FirstFailure null.Time // first_failure timestamp NULL  DEFAULT 'NULL'
+	// LockExpires Lock Expiration Date
+	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'
 	CustomerAddressEntities *CustomerAddressEntities // Reversed 1:M customer_entity.entity_id => customer_address_entity.parent_id
 }
 
@@ -2085,6 +2969,13 @@ func (e *CustomerEntity) Delete(ctx context.Context, dbm *DBM, opts ...dml.DBRFu
 	return res, nil
 }
 
+// CustomerEntityDeleteByPK deletes the row with the primary key without loading
+// it. Auto generated.
+func (dbm *DBM) CustomerEntityDeleteByPK(ctx context.Context, entityID uint32) (res sql.Result, err error) {
+	e := &CustomerEntity{EntityID: entityID}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *CustomerEntity) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerEntityUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -2344,6 +3235,19 @@ func (cc *CustomerEntities) DBDelete(ctx context.Context, dbm *DBM, opts ...dml.
 	return res, nil
 }
 
+// CustomerEntitiesDeleteByPKs deletes all rows with the primary keys without
+// loading them. Auto generated.
+func (dbm *DBM) CustomerEntitiesDeleteByPKs(ctx context.Context, pkIDs ...uint32) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &CustomerEntities{Data: make([]*CustomerEntity, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &CustomerEntity{EntityID: pk})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *CustomerEntities) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerEntitiesUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -2362,6 +3266,15 @@ func (cc *CustomerEntities) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.
 	return res, nil
 }
 
+// CustomerEntityUpdateByPK updates all columns of the entities matched by their
+// primary key. Auto generated.
+func (dbm *DBM) CustomerEntityUpdateByPK(ctx context.Context, entities ...*CustomerEntity) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&CustomerEntities{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *CustomerEntities) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "CustomerEntitiesInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -2398,6 +3311,46 @@ func (cc *CustomerEntities) DBUpsert(ctx context.Context, dbm *DBM, opts ...dml.
 	return res, nil
 }
 
+// CustomerEntityUpsertByPK inserts new entities or updates all non primary key
+// columns of the existing entities with one INSERT ... ON DUPLICATE KEY UPDATE
+// statement. Entities without an auto increment ID get the new IDs assigned.
+// Auto generated.
+func (dbm *DBM) CustomerEntityUpsertByPK(ctx context.Context, entities ...*CustomerEntity) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "CustomerEntityUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &CustomerEntities{Data: entities}
+	if err = dbm.eventCustomerEntityFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ids := make([]uint32, len(entities))
+	for i, e := range entities {
+		ids[i] = e.EntityID
+	}
+	if res, err = dbm.CachedQuery("CustomerEntityUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.EntityID = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}
+	if err = dbm.eventCustomerEntityFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *CustomerEntities) Delete(i int) *CustomerEntities {
 	z := cc.Data // copy the slice header
@@ -2503,6 +3456,114 @@ func (cc *CustomerEntities) WriteTo(w io.Writer) (n int64, err error) {
 	return n, nil
 }
 
+// CustomerEntityFilters composes typed WHERE conditions for the indexed columns
+// of table customer_entity and applies them to a SELECT. Auto generated.
+type CustomerEntityFilters struct {
+	conditions dml.Conditions
+}
+
+// NewCustomerEntityFilters creates a new empty filter. Auto generated.
+func NewCustomerEntityFilters() *CustomerEntityFilters { return &CustomerEntityFilters{} }
+
+// WhereEntityID adds the condition entity_id = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereEntityID(v uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereEntityIDIn adds the condition entity_id IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereEntityIDIn(vs ...uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`entity_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereWebsiteID adds the condition website_id = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereWebsiteID(v uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`website_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereWebsiteIDIn adds the condition website_id IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereWebsiteIDIn(vs ...uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`website_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereEmail adds the condition email = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereEmail(v string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`email`).Equal().Str(v))
+	return f
+}
+
+// WhereEmailIn adds the condition email IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereEmailIn(vs ...string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`email`).In().Strs(vs...))
+	return f
+}
+
+// WhereEmailLike adds the condition email LIKE ?. Auto generated.
+func (f *CustomerEntityFilters) WhereEmailLike(s string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`email`).Like().Str(s))
+	return f
+}
+
+// WhereStoreID adds the condition store_id = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereStoreID(v uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`store_id`).Equal().Uint64(v))
+	return f
+}
+
+// WhereStoreIDIn adds the condition store_id IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereStoreIDIn(vs ...uint64) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`store_id`).In().Uint64s(vs...))
+	return f
+}
+
+// WhereFirstname adds the condition firstname = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereFirstname(v string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`firstname`).Equal().Str(v))
+	return f
+}
+
+// WhereFirstnameIn adds the condition firstname IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereFirstnameIn(vs ...string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`firstname`).In().Strs(vs...))
+	return f
+}
+
+// WhereFirstnameLike adds the condition firstname LIKE ?. Auto generated.
+func (f *CustomerEntityFilters) WhereFirstnameLike(s string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`firstname`).Like().Str(s))
+	return f
+}
+
+// WhereLastname adds the condition lastname = ?. Auto generated.
+func (f *CustomerEntityFilters) WhereLastname(v string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`lastname`).Equal().Str(v))
+	return f
+}
+
+// WhereLastnameIn adds the condition lastname IN (?). Auto generated.
+func (f *CustomerEntityFilters) WhereLastnameIn(vs ...string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`lastname`).In().Strs(vs...))
+	return f
+}
+
+// WhereLastnameLike adds the condition lastname LIKE ?. Auto generated.
+func (f *CustomerEntityFilters) WhereLastnameLike(s string) *CustomerEntityFilters {
+	f.conditions = append(f.conditions, dml.Column(`lastname`).Like().Str(s))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *CustomerEntityFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *CustomerEntityFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // DmlgenTypes represents a single row for DB table dmlgen_types. Auto generated.
 // // Just another comment.
 //easyjson:json
@@ -2697,6 +3758,13 @@ func (e *DmlgenTypes) Delete(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc)
 	return res, nil
 }
 
+// DmlgenTypesDeleteByPK deletes the row with the primary key without loading it.
+// Auto generated.
+func (dbm *DBM) DmlgenTypesDeleteByPK(ctx context.Context, iD int32) (res sql.Result, err error) {
+	e := &DmlgenTypes{ID: iD}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *DmlgenTypes) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "DmlgenTypesUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -2960,6 +4028,19 @@ func (cc *DmlgenTypesCollection) DBDelete(ctx context.Context, dbm *DBM, opts ..
 	return res, nil
 }
 
+// DmlgenTypesCollectionDeleteByPKs deletes all rows with the primary keys
+// without loading them. Auto generated.
+func (dbm *DBM) DmlgenTypesCollectionDeleteByPKs(ctx context.Context, pkIDs ...int32) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &DmlgenTypesCollection{Data: make([]*DmlgenTypes, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &DmlgenTypes{ID: pk})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *DmlgenTypesCollection) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "DmlgenTypesCollectionUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -2978,6 +4059,15 @@ func (cc *DmlgenTypesCollection) DBUpdate(ctx context.Context, dbm *DBM, opts ..
 	return res, nil
 }
 
+// DmlgenTypesUpdateByPK updates all columns of the entities matched by their
+// primary key. Auto generated.
+func (dbm *DBM) DmlgenTypesUpdateByPK(ctx context.Context, entities ...*DmlgenTypes) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&DmlgenTypesCollection{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *DmlgenTypesCollection) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "DmlgenTypesCollectionInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -3014,6 +4104,46 @@ func (cc *DmlgenTypesCollection) DBUpsert(ctx context.Context, dbm *DBM, opts ..
 	return res, nil
 }
 
+// DmlgenTypesUpsertByPK inserts new entities or updates all non primary key
+// columns of the existing entities with one INSERT ... ON DUPLICATE KEY UPDATE
+// statement. Entities without an auto increment ID get the new IDs assigned.
+// Auto generated.
+func (dbm *DBM) DmlgenTypesUpsertByPK(ctx context.Context, entities ...*DmlgenTypes) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "DmlgenTypesUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &DmlgenTypesCollection{Data: entities}
+	if err = dbm.eventDmlgenTypesFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ids := make([]int32, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ID
+	}
+	if res, err = dbm.CachedQuery("DmlgenTypesUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.ID = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}
+	if err = dbm.eventDmlgenTypesFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *DmlgenTypesCollection) Delete(i int) *DmlgenTypesCollection {
 	z := cc.Data // copy the slice header
@@ -3239,15 +4369,49 @@ func (cc *DmlgenTypesCollection) WriteTo(w io.Writer) (n int64, err error) {
 	return n, nil
 }
 
+// DmlgenTypesFilters composes typed WHERE conditions for the indexed columns of
+// table dmlgen_types and applies them to a SELECT. Auto generated.
+type DmlgenTypesFilters struct {
+	conditions dml.Conditions
+}
+
+// NewDmlgenTypesFilters creates a new empty filter. Auto generated.
+func NewDmlgenTypesFilters() *DmlgenTypesFilters { return &DmlgenTypesFilters{} }
+
+// WhereID adds the condition id = ?. Auto generated.
+func (f *DmlgenTypesFilters) WhereID(v int64) *DmlgenTypesFilters {
+	f.conditions = append(f.conditions, dml.Column(`id`).Equal().Int64(v))
+	return f
+}
+
+// WhereIDIn adds the condition id IN (?). Auto generated.
+func (f *DmlgenTypesFilters) WhereIDIn(vs ...int64) *DmlgenTypesFilters {
+	f.conditions = append(f.conditions, dml.Column(`id`).In().Int64s(vs...))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *DmlgenTypesFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *DmlgenTypesFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // SalesOrderStatusState represents a single row for DB table
 // sales_order_status_state. Auto generated.
 // Table comment: Sales Order Status Table
 //easyjson:json
 type SalesOrderStatusState struct {
-	Status         string `max_len:"32"` // status varchar(32) NOT NULL PRI   "Status"
-	State          string `max_len:"32"` // state varchar(32) NOT NULL PRI   "Label"
-	IsDefault      bool   `max_len:"5"`  // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'  "Is Default"
-	VisibleOnFront uint32 `max_len:"5"`  // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'  "Visible on front"
+	// Status Status
+	Status string `max_len:"32"` // status varchar(32) NOT NULL PRI
+	// State Label
+	State string `max_len:"32"` // state varchar(32) NOT NULL PRI
+	// IsDefault Is Default
+	IsDefault bool `max_len:"5"` // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// VisibleOnFront Visible on front
+	VisibleOnFront uint32 `max_len:"5"` // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -3317,6 +4481,13 @@ func (e *SalesOrderStatusState) Delete(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// SalesOrderStatusStateDeleteByPK deletes the row with the primary key without
+// loading it. Auto generated.
+func (dbm *DBM) SalesOrderStatusStateDeleteByPK(ctx context.Context, status string, state string) (res sql.Result, err error) {
+	e := &SalesOrderStatusState{Status: status, State: state}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *SalesOrderStatusState) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "SalesOrderStatusStateUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -3540,7 +4711,12 @@ func (cc *SalesOrderStatusStates) DBDelete(ctx context.Context, dbm *DBM, opts .
 	if err = dbm.eventSalesOrderStatusStateFunc(ctx, dml.EventFlagBeforeDelete, cc, nil); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	if res, err = dbm.CachedQuery("SalesOrderStatusStateDeleteByPK").ApplyCallBacks(opts...).ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+	args := make([]interface{}, 0, len(cc.Data)*2)
+	for _, e := range cc.Data {
+		args = append(args, e.Status)
+		args = append(args, e.State)
+	}
+	if res, err = dbm.CachedQuery("SalesOrderStatusStateDeleteByPK").ApplyCallBacks(opts...).ExecContext(ctx, args...); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	if err = errors.WithStack(dbm.eventSalesOrderStatusStateFunc(ctx, dml.EventFlagAfterDelete, cc, nil)); err != nil {
@@ -3549,6 +4725,19 @@ func (cc *SalesOrderStatusStates) DBDelete(ctx context.Context, dbm *DBM, opts .
 	return res, nil
 }
 
+// SalesOrderStatusStatesDeleteByPKs deletes all rows with the primary keys
+// without loading them. Auto generated.
+func (dbm *DBM) SalesOrderStatusStatesDeleteByPKs(ctx context.Context, pkIDs ...SalesOrderStatusStatesDBLoadArgs) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &SalesOrderStatusStates{Data: make([]*SalesOrderStatusState, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &SalesOrderStatusState{Status: pk.Status, State: pk.State})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *SalesOrderStatusStates) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "SalesOrderStatusStatesUpdateByPK")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -3567,6 +4756,15 @@ func (cc *SalesOrderStatusStates) DBUpdate(ctx context.Context, dbm *DBM, opts .
 	return res, nil
 }
 
+// SalesOrderStatusStateUpdateByPK updates all columns of the entities matched by
+// their primary key. Auto generated.
+func (dbm *DBM) SalesOrderStatusStateUpdateByPK(ctx context.Context, entities ...*SalesOrderStatusState) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&SalesOrderStatusStates{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *SalesOrderStatusStates) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	ctx, span := dbm.option.Trace.Start(ctx, "SalesOrderStatusStatesInsert")
 	defer func() { cstrace.Status(span, err); span.End() }()
@@ -3603,6 +4801,29 @@ func (cc *SalesOrderStatusStates) DBUpsert(ctx context.Context, dbm *DBM, opts .
 	return res, nil
 }
 
+// SalesOrderStatusStateUpsertByPK inserts new entities or updates all non
+// primary key columns of the existing entities with one INSERT ... ON DUPLICATE
+// KEY UPDATE statement. Entities without an auto increment ID get the new IDs
+// assigned. Auto generated.
+func (dbm *DBM) SalesOrderStatusStateUpsertByPK(ctx context.Context, entities ...*SalesOrderStatusState) (res sql.Result, err error) {
+	ctx, span := dbm.option.Trace.Start(ctx, "SalesOrderStatusStateUpsertByPK")
+	defer func() { cstrace.Status(span, err); span.End() }()
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &SalesOrderStatusStates{Data: entities}
+	if err = dbm.eventSalesOrderStatusStateFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if res, err = dbm.CachedQuery("SalesOrderStatusStateUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = dbm.eventSalesOrderStatusStateFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Delete will remove an item from the slice. Auto generated via dmlgen.
 func (cc *SalesOrderStatusStates) Delete(i int) *SalesOrderStatusStates {
 	z := cc.Data // copy the slice header
@@ -3723,16 +4944,78 @@ func (cc *SalesOrderStatusStates) WriteTo(w io.Writer) (n int64, err error) {
 	return n, nil
 }
 
+// SalesOrderStatusStateFilters composes typed WHERE conditions for the indexed
+// columns of table sales_order_status_state and applies them to a SELECT. Auto
+// generated.
+type SalesOrderStatusStateFilters struct {
+	conditions dml.Conditions
+}
+
+// NewSalesOrderStatusStateFilters creates a new empty filter. Auto generated.
+func NewSalesOrderStatusStateFilters() *SalesOrderStatusStateFilters {
+	return &SalesOrderStatusStateFilters{}
+}
+
+// WhereStatus adds the condition status = ?. Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereStatus(v string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`status`).Equal().Str(v))
+	return f
+}
+
+// WhereStatusIn adds the condition status IN (?). Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereStatusIn(vs ...string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`status`).In().Strs(vs...))
+	return f
+}
+
+// WhereStatusLike adds the condition status LIKE ?. Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereStatusLike(s string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`status`).Like().Str(s))
+	return f
+}
+
+// WhereState adds the condition state = ?. Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereState(v string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`state`).Equal().Str(v))
+	return f
+}
+
+// WhereStateIn adds the condition state IN (?). Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereStateIn(vs ...string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`state`).In().Strs(vs...))
+	return f
+}
+
+// WhereStateLike adds the condition state LIKE ?. Auto generated.
+func (f *SalesOrderStatusStateFilters) WhereStateLike(s string) *SalesOrderStatusStateFilters {
+	f.conditions = append(f.conditions, dml.Column(`state`).Like().Str(s))
+	return f
+}
+
+// Conditions returns the composed conditions. Auto generated.
+func (f *SalesOrderStatusStateFilters) Conditions() dml.Conditions { return f.conditions }
+
+// Select appends the composed conditions to the WHERE clause of sel. Auto
+// generated.
+func (f *SalesOrderStatusStateFilters) Select(sel *dml.Select) *dml.Select {
+	return sel.Where(f.conditions...)
+}
+
 // ViewCustomerAutoIncrement represents a single row for DB table
 // view_customer_auto_increment. Auto generated.
 // Table comment: VIEW
 //easyjson:json
 type ViewCustomerAutoIncrement struct {
-	CeEntityID uint32      `max_len:"10"`  // ce_entity_id int(10) unsigned NOT NULL  DEFAULT '0'  "Entity ID"
-	Email      null.String `max_len:"255"` // email varchar(255) NULL  DEFAULT 'NULL'  "Email"
-	Firstname  string      `max_len:"255"` // firstname varchar(255) NOT NULL    "First Name"
-	Lastname   string      `max_len:"255"` // lastname varchar(255) NOT NULL    "Last Name"
-	City       string      `max_len:"255"` // city varchar(255) NOT NULL    "City"
+	// CeEntityID Entity ID
+	CeEntityID uint32 `max_len:"10"` // ce_entity_id int(10) unsigned NOT NULL  DEFAULT '0'
+	// Email Email
+	Email null.String `max_len:"255"` // email varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string `max_len:"255"` // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string `max_len:"255"` // lastname varchar(255) NOT NULL
+	// City City
+	City string `max_len:"255"` // city varchar(255) NOT NULL
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -4029,10 +5312,14 @@ func (cc *ViewCustomerAutoIncrements) WriteTo(w io.Writer) (n int64, err error)
 // Table comment: VIEW
 //easyjson:json
 type ViewCustomerNoAutoIncrement struct {
-	Email     null.String `max_len:"255"` // email varchar(255) NULL  DEFAULT 'NULL'  "Email"
-	Firstname string      `max_len:"255"` // firstname varchar(255) NOT NULL    "First Name"
-	Lastname  string      `max_len:"255"` // lastname varchar(255) NOT NULL    "Last Name"
-	City      string      `max_len:"255"` // city varchar(255) NOT NULL    "City"
+	// Email Email
+	Email null.String `max_len:"255"` // email varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string `max_len:"255"` // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string `max_len:"255"` // lastname varchar(255) NOT NULL
+	// City City
+	City string `max_len:"255"` // city varchar(255) NOT NULL
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
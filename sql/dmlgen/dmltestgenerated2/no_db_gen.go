@@ -15,14 +15,22 @@ import (
 // Auto generated.
 // Table comment: Config Data
 type CoreConfiguration struct {
-	ConfigID  uint32      // config_id int(10) unsigned NOT NULL PRI  auto_increment "Id"
-	Scope     string      // scope varchar(8) NOT NULL MUL DEFAULT ''default''  "Scope"
-	ScopeID   int32       // scope_id int(11) NOT NULL  DEFAULT '0'  "Scope Id"
-	Expires   null.Time   // expires datetime NULL  DEFAULT 'NULL'  "Value expiration time"
-	Path      string      // path varchar(255) NOT NULL    "Path"
-	Value     null.String // value text NULL  DEFAULT 'NULL'  "Value"
-	VersionTs time.Time   // version_ts timestamp(6) NOT NULL   STORED GENERATED "Timestamp Start Versioning"
-	VersionTe time.Time   // version_te timestamp(6) NOT NULL PRI  STORED GENERATED "Timestamp End Versioning"
+	// ConfigID Id
+	ConfigID uint32 // config_id int(10) unsigned NOT NULL PRI  auto_increment
+	// Scope Scope
+	Scope string // scope varchar(8) NOT NULL MUL DEFAULT ''default''
+	// ScopeID Scope Id
+	ScopeID int32 // scope_id int(11) NOT NULL  DEFAULT '0'
+	// Expires Value expiration time
+	Expires null.Time // expires datetime NULL  DEFAULT 'NULL'
+	// Path Path
+	Path string // path varchar(255) NOT NULL
+	// Value Value
+	Value null.String // value text NULL  DEFAULT 'NULL'
+	// VersionTs Timestamp Start Versioning
+	VersionTs time.Time // version_ts timestamp(6) NOT NULL   STORED GENERATED
+	// VersionTe Timestamp End Versioning
+	VersionTe time.Time // version_te timestamp(6) NOT NULL PRI  STORED GENERATED
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -195,10 +203,14 @@ func (cc *CoreConfigurations) WriteTo(w io.Writer) (n int64, err error) {
 // sales_order_status_state. Auto generated.
 // Table comment: Sales Order Status Table
 type SalesOrderStatusState struct {
-	Status         string // status varchar(32) NOT NULL PRI   "Status"
-	State          string // state varchar(32) NOT NULL PRI   "Label"
-	IsDefault      bool   // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'  "Is Default"
-	VisibleOnFront uint16 // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'  "Visible on front"
+	// Status Status
+	Status string // status varchar(32) NOT NULL PRI
+	// State Label
+	State string // state varchar(32) NOT NULL PRI
+	// IsDefault Is Default
+	IsDefault bool // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// VisibleOnFront Visible on front
+	VisibleOnFront uint16 // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'
 }
 
 // Copy copies the struct and returns a new pointer. TODO use deepcopy tool to
@@ -12,8 +12,10 @@ import (
 // catalog_category_entity. Auto generated.
 // Table comment: Catalog Category Table
 type CatalogCategoryEntity struct {
-	EntityID                uint32                   // entity_id int(10) unsigned NOT NULL MUL   "Entity Id"
-	RowID                   uint32                   // row_id int(10) unsigned NOT NULL PRI  auto_increment "Version Id"
+	// EntityID Entity Id
+	EntityID uint32 // entity_id int(10) unsigned NOT NULL MUL
+	// RowID Version Id
+	RowID                   uint32                   // row_id int(10) unsigned NOT NULL PRI  auto_increment
 	SequenceCatalogCategory *SequenceCatalogCategory // 1:1 catalog_category_entity.entity_id => sequence_catalog_category.sequence_value
 }
 
@@ -37,31 +39,56 @@ func NewCatalogCategoryEntities() *CatalogCategoryEntities {
 // Table comment: Customer Address Entity
 //easyjson:json
 type CustomerAddressEntity struct {
-	EntityID          uint32      `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	IncrementID       null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	ParentID          null.Uint32 `max_len:"10"` // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'  "Parent ID"
-	CreatedAt         time.Time   // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt         time.Time   // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive          bool        `max_len:"5"`     // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	City              string      `max_len:"255"`   // city varchar(255) NOT NULL    "City"
-	Company           null.String `max_len:"255"`   // company varchar(255) NULL  DEFAULT 'NULL'  "Company"
-	CountryID         string      `max_len:"255"`   // country_id varchar(255) NOT NULL    "Country"
-	Fax               null.String `max_len:"255"`   // fax varchar(255) NULL  DEFAULT 'NULL'  "Fax"
-	Firstname         string      `max_len:"255"`   // firstname varchar(255) NOT NULL    "First Name"
-	Lastname          string      `max_len:"255"`   // lastname varchar(255) NOT NULL    "Last Name"
-	Middlename        null.String `max_len:"255"`   // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name"
-	Postcode          null.String `max_len:"255"`   // postcode varchar(255) NULL  DEFAULT 'NULL'  "Zip/Postal Code"
-	Prefix            null.String `max_len:"40"`    // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Region            null.String `max_len:"255"`   // region varchar(255) NULL  DEFAULT 'NULL'  "State/Province"
-	RegionID          null.Uint32 `max_len:"10"`    // region_id int(10) unsigned NULL  DEFAULT 'NULL'  "State/Province"
-	Street            string      `max_len:"65535"` // street text NOT NULL    "Street Address"
-	Suffix            null.String `max_len:"40"`    // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Telephone         string      `max_len:"255"`   // telephone varchar(255) NOT NULL    "Phone Number"
-	VatID             null.String `max_len:"255"`   // vat_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number"
-	VatIsValid        null.Bool   `max_len:"10"`    // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validity"
-	VatRequestDate    null.String `max_len:"255"`   // vat_request_date varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request date"
-	VatRequestID      null.String `max_len:"255"`   // vat_request_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request ID"
-	VatRequestSuccess null.Uint32 `max_len:"10"`    // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validation request success"
+	// EntityID Entity ID
+	EntityID uint32 `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// IncrementID Increment Id
+	IncrementID null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// ParentID Parent ID
+	ParentID null.Uint32 `max_len:"10"` // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool `max_len:"5"` // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// City City
+	City string `max_len:"255"` // city varchar(255) NOT NULL
+	// Company Company
+	Company null.String `max_len:"255"` // company varchar(255) NULL  DEFAULT 'NULL'
+	// CountryID Country
+	CountryID string `max_len:"255"` // country_id varchar(255) NOT NULL
+	// Fax Fax
+	Fax null.String `max_len:"255"` // fax varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string `max_len:"255"` // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string `max_len:"255"` // lastname varchar(255) NOT NULL
+	// Middlename Middle Name
+	Middlename null.String `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Postcode Zip/Postal Code
+	Postcode null.String `max_len:"255"` // postcode varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String `max_len:"40"` // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Region State/Province
+	Region null.String `max_len:"255"` // region varchar(255) NULL  DEFAULT 'NULL'
+	// RegionID State/Province
+	RegionID null.Uint32 `max_len:"10"` // region_id int(10) unsigned NULL  DEFAULT 'NULL'
+	// Street Street Address
+	Street string `max_len:"65535"` // street text NOT NULL
+	// Suffix Name Suffix
+	Suffix null.String `max_len:"40"` // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Telephone Phone Number
+	Telephone string `max_len:"255"` // telephone varchar(255) NOT NULL
+	// VatID VAT number
+	VatID null.String `max_len:"255"` // vat_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatIsValid VAT number validity
+	VatIsValid null.Bool `max_len:"10"` // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'
+	// VatRequestDate VAT number validation request date
+	VatRequestDate null.String `max_len:"255"` // vat_request_date varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestID VAT number validation request ID
+	VatRequestID null.String `max_len:"255"` // vat_request_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestSuccess VAT number validation request success
+	VatRequestSuccess null.Uint32 `max_len:"10"` // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'
 }
 
 // CustomerAddressEntities represents a collection type for DB table
@@ -85,34 +112,62 @@ func NewCustomerAddressEntities() *CustomerAddressEntities {
 // Table comment: Customer Entity
 //easyjson:json
 type CustomerEntity struct {
-	EntityID                uint32                   `max_len:"10"`  // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	WebsiteID               null.Uint16              `max_len:"5"`   // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'  "Website ID"
-	Email                   null.String              `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'  "Email"
-	GroupID                 uint16                   `max_len:"5"`   // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'  "Group ID"
-	IncrementID             null.String              `max_len:"50"`  // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	StoreID                 null.Uint16              `max_len:"5"`   // store_id smallint(5) unsigned NULL MUL DEFAULT '0'  "Store ID"
-	CreatedAt               time.Time                // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt               time.Time                // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive                bool                     `max_len:"5"`   // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	DisableAutoGroupChange  uint16                   `max_len:"5"`   // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'  "Disable automatic group change based on VAT ID"
-	CreatedIn               null.String              `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'  "Created From"
-	Prefix                  null.String              `max_len:"40"`  // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Firstname               null.String              `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'  "First Name"
-	Middlename              null.String              `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name/Initial"
-	Lastname                null.String              `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'  "Last Name"
-	Suffix                  null.String              `max_len:"40"`  // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Dob                     null.Time                // dob date NULL  DEFAULT 'NULL'  "Date of Birth"
-	PasswordHash            null.String              `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'  "Password_hash"
-	RpToken                 null.String              `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'  "Reset password token"
-	RpTokenCreatedAt        null.Time                // rp_token_created_at datetime NULL  DEFAULT 'NULL'  "Reset password token creation time"
-	DefaultBilling          null.Uint32              `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'  "Default Billing Address"
-	DefaultShipping         null.Uint32              `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'  "Default Shipping Address"
-	Taxvat                  null.String              `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'  "Tax/VAT Number"
-	Confirmation            null.String              `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'  "Is Confirmed"
-	Gender                  null.Uint16              `max_len:"5"`  // gender smallint(5) unsigned NULL  DEFAULT 'NULL'  "Gender"
-	FailuresNum             null.Int16               `max_len:"5"`  // failures_num smallint(6) NULL  DEFAULT '0'  "Failure Number"
-	FirstFailure            null.Time                // first_failure timestamp NULL  DEFAULT 'NULL'  "First Failure"
-	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'  "Lock Expiration Date"
+	// EntityID Entity ID
+	EntityID uint32 `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// WebsiteID Website ID
+	WebsiteID null.Uint16 `max_len:"5"` // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'
+	// Email Email
+	Email null.String `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'
+	// GroupID Group ID
+	GroupID uint16 `max_len:"5"` // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// IncrementID Increment Id
+	IncrementID null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// StoreID Store ID
+	StoreID null.Uint16 `max_len:"5"` // store_id smallint(5) unsigned NULL MUL DEFAULT '0'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool `max_len:"5"` // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// DisableAutoGroupChange Disable automatic group change based on VAT ID
+	DisableAutoGroupChange uint16 `max_len:"5"` // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// CreatedIn Created From
+	CreatedIn null.String `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String `max_len:"40"` // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname null.String `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Middlename Middle Name/Initial
+	Middlename null.String `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Lastname Last Name
+	Lastname null.String `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Suffix Name Suffix
+	Suffix null.String `max_len:"40"` // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Dob Date of Birth
+	Dob null.Time // dob date NULL  DEFAULT 'NULL'
+	// PasswordHash Password_hash
+	PasswordHash null.String `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'
+	// RpToken Reset password token
+	RpToken null.String `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'
+	// RpTokenCreatedAt Reset password token creation time
+	RpTokenCreatedAt null.Time // rp_token_created_at datetime NULL  DEFAULT 'NULL'
+	// DefaultBilling Default Billing Address
+	DefaultBilling null.Uint32 `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'
+	// DefaultShipping Default Shipping Address
+	DefaultShipping null.Uint32 `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'
+	// Taxvat Tax/VAT Number
+	Taxvat null.String `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'
+	// Confirmation Is Confirmed
+	Confirmation null.String `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'
+	// Gender Gender
+	Gender null.Uint16 `max_len:"5"` // gender smallint(5) unsigned NULL  DEFAULT 'NULL'
+	// FailuresNum Failure Number
+	FailuresNum null.Int16 `max_len:"5"` // failures_num smallint(6) NULL  DEFAULT '0'
+	// FirstFailure First Failure
+	FirstFailure null.Time // first_failure timestamp NULL  DEFAULT 'NULL'
+	// LockExpires Lock Expiration Date
+	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'
 	Customeraddressentities *CustomerAddressEntities // Reversed 1:M customer_entity.entity_id => customer_address_entity.parent_id
 }
 
@@ -156,15 +211,22 @@ func NewSequenceCatalogCategories() *SequenceCatalogCategories {
 // Table comment: Stores
 //easyjson:json
 type Store struct {
-	StoreID      uint16        `max_len:"5"`   // store_id smallint(5) unsigned NOT NULL PRI  auto_increment "Store Id"
-	Code         null.String   `max_len:"32"`  // code varchar(32) NULL UNI DEFAULT 'NULL'  "Code"
-	WebsiteID    uint16        `max_len:"5"`   // website_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Website Id"
-	GroupID      uint16        `max_len:"5"`   // group_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Group Id"
-	Name         string        `max_len:"255"` // name varchar(255) NOT NULL    "Store Name"
-	SortOrder    uint16        `max_len:"5"`   // sort_order smallint(5) unsigned NOT NULL  DEFAULT '0'  "Store Sort Order"
-	IsActive     bool          `max_len:"5"`   // is_active smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Store Activity"
-	StoreGroup   *StoreGroup   `json:"-"`      // 1:1 store.group_id => store_group.group_id
-	StoreWebsite *StoreWebsite `json:"-"`      // 1:1 store.website_id => store_website.website_id
+	// StoreID Store Id
+	StoreID uint16 `max_len:"5"` // store_id smallint(5) unsigned NOT NULL PRI  auto_increment
+	// Code Code
+	Code null.String `max_len:"32"` // code varchar(32) NULL UNI DEFAULT 'NULL'
+	// WebsiteID Website Id
+	WebsiteID uint16 `max_len:"5"` // website_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// GroupID Group Id
+	GroupID uint16 `max_len:"5"` // group_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// Name Store Name
+	Name string `max_len:"255"` // name varchar(255) NOT NULL
+	// SortOrder Store Sort Order
+	SortOrder uint16 `max_len:"5"` // sort_order smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// IsActive Store Activity
+	IsActive     bool          `max_len:"5"` // is_active smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	StoreGroup   *StoreGroup   `json:"-"`    // 1:1 store.group_id => store_group.group_id
+	StoreWebsite *StoreWebsite `json:"-"`    // 1:1 store.website_id => store_website.website_id
 }
 
 // Stores represents a collection type for DB table store
@@ -184,14 +246,20 @@ func NewStores() *Stores {
 // StoreGroup represents a single row for DB table store_group. Auto generated.
 // Table comment: Store Groups
 type StoreGroup struct {
-	GroupID        uint16        // group_id smallint(5) unsigned NOT NULL PRI  auto_increment "Group Id"
-	WebsiteID      uint16        // website_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Website Id"
-	Name           string        // name varchar(255) NOT NULL    "Store Group Name"
-	RootCategoryID uint32        // root_category_id int(10) unsigned NOT NULL  DEFAULT '0'  "Root Category Id"
-	DefaultStoreID uint16        // default_store_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Default Store Id"
-	Code           null.String   // code varchar(32) NULL UNI DEFAULT 'NULL'  "Store group unique code"
-	StoreWebsite   *StoreWebsite // 1:1 store_group.website_id => store_website.website_id
-	Stores         *Stores       // Reversed 1:M store_group.group_id => store.group_id
+	// GroupID Group Id
+	GroupID uint16 // group_id smallint(5) unsigned NOT NULL PRI  auto_increment
+	// WebsiteID Website Id
+	WebsiteID uint16 // website_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// Name Store Group Name
+	Name string // name varchar(255) NOT NULL
+	// RootCategoryID Root Category Id
+	RootCategoryID uint32 // root_category_id int(10) unsigned NOT NULL  DEFAULT '0'
+	// DefaultStoreID Default Store Id
+	DefaultStoreID uint16 // default_store_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// Code Store group unique code
+	Code         null.String   // code varchar(32) NULL UNI DEFAULT 'NULL'
+	StoreWebsite *StoreWebsite // 1:1 store_group.website_id => store_website.website_id
+	Stores       *Stores       // Reversed 1:M store_group.group_id => store.group_id
 }
 
 // StoreGroups represents a collection type for DB table store_group
@@ -211,14 +279,20 @@ func NewStoreGroups() *StoreGroups {
 // generated.
 // Table comment: Websites
 type StoreWebsite struct {
-	WebsiteID      uint16       // website_id smallint(5) unsigned NOT NULL PRI  auto_increment "Website Id"
-	Code           null.String  // code varchar(32) NULL UNI DEFAULT 'NULL'  "Code"
-	Name           null.String  // name varchar(64) NULL  DEFAULT 'NULL'  "Website Name"
-	SortOrder      uint16       // sort_order smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Sort Order"
-	DefaultGroupID uint16       // default_group_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'  "Default Group Id"
-	IsDefault      null.Bool    // is_default smallint(5) unsigned NULL  DEFAULT '0'  "Defines Is Website Default"
-	Stores         *Stores      // Reversed 1:M store_website.website_id => store.website_id
-	StoreGroups    *StoreGroups // Reversed 1:M store_website.website_id => store_group.website_id
+	// WebsiteID Website Id
+	WebsiteID uint16 // website_id smallint(5) unsigned NOT NULL PRI  auto_increment
+	// Code Code
+	Code null.String // code varchar(32) NULL UNI DEFAULT 'NULL'
+	// Name Website Name
+	Name null.String // name varchar(64) NULL  DEFAULT 'NULL'
+	// SortOrder Sort Order
+	SortOrder uint16 // sort_order smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// DefaultGroupID Default Group Id
+	DefaultGroupID uint16 // default_group_id smallint(5) unsigned NOT NULL MUL DEFAULT '0'
+	// IsDefault Defines Is Website Default
+	IsDefault   null.Bool    // is_default smallint(5) unsigned NULL  DEFAULT '0'
+	Stores      *Stores      // Reversed 1:M store_website.website_id => store.website_id
+	StoreGroups *StoreGroups // Reversed 1:M store_website.website_id => store_group.website_id
 }
 
 // StoreWebsites represents a collection type for DB table store_website
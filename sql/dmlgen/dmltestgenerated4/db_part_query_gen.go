@@ -63,9 +63,12 @@ type DBM struct {
 }
 
 func (dbm DBM) eventCoreConfigurationFunc(ctx context.Context, ef dml.EventFlag, ec *CoreConfigurations, e *CoreConfiguration) error {
-	if len(dbm.option.eventCoreConfigurationFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookCoreConfiguration(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventCoreConfigurationFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -74,10 +77,66 @@ func (dbm DBM) eventCoreConfigurationFunc(ctx context.Context, ef dml.EventFlag,
 	return nil
 }
 
+// CoreConfigurationBeforeInserter gets called before inserting a
+// CoreConfiguration or a CoreConfigurations into the database. Implement it in
+// a non-generated file.
+type CoreConfigurationBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// CoreConfigurationBeforeUpdater gets called before updating a
+// CoreConfiguration or a CoreConfigurations in the database. Implement it in a
+// non-generated file.
+type CoreConfigurationBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// CoreConfigurationAfterLoader gets called after loading a CoreConfiguration or
+// a CoreConfigurations from the database. Implement it in a non-generated file.
+type CoreConfigurationAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookCoreConfiguration calls the optional hook interfaces of each entity. The
+// hooks run before the event functions added via
+// DBMOption.AddEventCoreConfiguration and get skipped with
+// dml.EventsAreSkipped.
+func hookCoreConfiguration(ctx context.Context, ef dml.EventFlag, ec *CoreConfigurations, e *CoreConfiguration) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookCoreConfiguration(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(CoreConfigurationBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(CoreConfigurationBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(CoreConfigurationAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventSalesOrderStatusStateFunc(ctx context.Context, ef dml.EventFlag, ec *SalesOrderStatusStates, e *SalesOrderStatusState) error {
-	if len(dbm.option.eventSalesOrderStatusStateFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookSalesOrderStatusState(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventSalesOrderStatusStateFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -86,10 +145,67 @@ func (dbm DBM) eventSalesOrderStatusStateFunc(ctx context.Context, ef dml.EventF
 	return nil
 }
 
+// SalesOrderStatusStateBeforeInserter gets called before inserting a
+// SalesOrderStatusState or a SalesOrderStatusStates into the database.
+// Implement it in a non-generated file.
+type SalesOrderStatusStateBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// SalesOrderStatusStateBeforeUpdater gets called before updating a
+// SalesOrderStatusState or a SalesOrderStatusStates in the database. Implement
+// it in a non-generated file.
+type SalesOrderStatusStateBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// SalesOrderStatusStateAfterLoader gets called after loading a
+// SalesOrderStatusState or a SalesOrderStatusStates from the database.
+// Implement it in a non-generated file.
+type SalesOrderStatusStateAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookSalesOrderStatusState calls the optional hook interfaces of each entity.
+// The hooks run before the event functions added via
+// DBMOption.AddEventSalesOrderStatusState and get skipped with
+// dml.EventsAreSkipped.
+func hookSalesOrderStatusState(ctx context.Context, ef dml.EventFlag, ec *SalesOrderStatusStates, e *SalesOrderStatusState) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookSalesOrderStatusState(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(SalesOrderStatusStateBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(SalesOrderStatusStateBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(SalesOrderStatusStateAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 func (dbm DBM) eventViewCustomerAutoIncrementFunc(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerAutoIncrements, e *ViewCustomerAutoIncrement) error {
-	if len(dbm.option.eventViewCustomerAutoIncrementFunc[ef]) == 0 || dml.EventsAreSkipped(ctx) {
+	if dml.EventsAreSkipped(ctx) {
 		return nil
 	}
+	if err := hookViewCustomerAutoIncrement(ctx, ef, ec, e); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, fn := range dbm.option.eventViewCustomerAutoIncrementFunc[ef] {
 		if err := fn(ctx, ec, e); err != nil {
 			return errors.WithStack(err)
@@ -98,6 +214,60 @@ func (dbm DBM) eventViewCustomerAutoIncrementFunc(ctx context.Context, ef dml.Ev
 	return nil
 }
 
+// ViewCustomerAutoIncrementBeforeInserter gets called before inserting a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements into the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementBeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// ViewCustomerAutoIncrementBeforeUpdater gets called before updating a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements in the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementBeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// ViewCustomerAutoIncrementAfterLoader gets called after loading a
+// ViewCustomerAutoIncrement or a ViewCustomerAutoIncrements from the database.
+// Implement it in a non-generated file.
+type ViewCustomerAutoIncrementAfterLoader interface {
+	AfterLoad(ctx context.Context) error
+}
+
+// hookViewCustomerAutoIncrement calls the optional hook interfaces of each
+// entity. The hooks run before the event functions added via
+// DBMOption.AddEventViewCustomerAutoIncrement and get skipped with
+// dml.EventsAreSkipped.
+func hookViewCustomerAutoIncrement(ctx context.Context, ef dml.EventFlag, ec *ViewCustomerAutoIncrements, e *ViewCustomerAutoIncrement) error {
+	if ec != nil {
+		for _, e := range ec.Data {
+			if err := hookViewCustomerAutoIncrement(ctx, ef, nil, e); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	switch ef {
+	case dml.EventFlagBeforeInsert:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementBeforeInserter); ok {
+			return errors.WithStack(h.BeforeInsert(ctx))
+		}
+	case dml.EventFlagBeforeUpdate:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementBeforeUpdater); ok {
+			return errors.WithStack(h.BeforeUpdate(ctx))
+		}
+	case dml.EventFlagAfterSelect:
+		if h, ok := interface{}(e).(ViewCustomerAutoIncrementAfterLoader); ok {
+			return errors.WithStack(h.AfterLoad(ctx))
+		}
+	}
+	return nil
+}
+
 // NewDBManager returns a goified version of the MySQL/MariaDB table schema for
 // the tables:  core_configuration, sales_order_status_state,
 // view_customer_auto_increment Auto generated by dmlgen.
@@ -133,13 +303,14 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 			dml.Column(`config_id`).In().PlaceHolder(),
 		)).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("CoreConfigurationInsert", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert()).WithDBR()),
-		ddl.WithQueryDBR("CoreConfigurationUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert()).OnDuplicateKey().WithDBR()),
+		ddl.WithQueryDBR("CoreConfigurationUpsertByPK", dbmo.InitInsertFn(tbls.MustTable(TableNameCoreConfiguration).Insert().AddColumns(`config_id`).AddOnDuplicateKeyExclude(`config_id`)).OnDuplicateKey().WithDBR()),
 		ddl.WithQueryDBR("SalesOrderStatusStatesSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("SalesOrderStatusStatesSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).Where(
 			dml.Columns(`status`, `state`).In().Tuples(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("SalesOrderStatusStateSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameSalesOrderStatusState).Select("*")).Where(
-			dml.Columns(`status`, `state`).Equal().Tuples(),
+			dml.Column(`status`).Equal().PlaceHolder(),
+			dml.Column(`state`).Equal().PlaceHolder(),
 		).WithDBR().Interpolate()),
 		ddl.WithQueryDBR("ViewCustomerAutoIncrementsSelectAll", dbmo.InitSelectFn(tbls.MustTable(TableNameViewCustomerAutoIncrement).Select("*")).WithDBR()),
 		ddl.WithQueryDBR("ViewCustomerAutoIncrementsSelectByPK", dbmo.InitSelectFn(tbls.MustTable(TableNameViewCustomerAutoIncrement).Select("*")).Where(
@@ -162,14 +333,22 @@ func NewDBManager(ctx context.Context, dbmo *DBMOption) (*DBM, error) {
 // Auto generated.
 // Table comment: Config Data
 type CoreConfiguration struct {
-	ConfigID  uint32      `max_len:"10"` // config_id int(10) unsigned NOT NULL PRI  auto_increment "Id"
-	Scope     string      `max_len:"8"`  // scope varchar(8) NOT NULL MUL DEFAULT ''default''  "Scope"
-	ScopeID   int32       `max_len:"10"` // scope_id int(11) NOT NULL  DEFAULT '0'  "Scope Id"
-	Expires   null.Time   // expires datetime NULL  DEFAULT 'NULL'  "Value expiration time"
-	Path      string      `max_len:"255"`   // path varchar(255) NOT NULL    "Path"
-	Value     null.String `max_len:"65535"` // value text NULL  DEFAULT 'NULL'  "Value"
-	VersionTs time.Time   // version_ts timestamp(6) NOT NULL   STORED GENERATED "Timestamp Start Versioning"
-	VersionTe time.Time   // version_te timestamp(6) NOT NULL PRI  STORED GENERATED "Timestamp End Versioning"
+	// ConfigID Id
+	ConfigID uint32 `max_len:"10"` // config_id int(10) unsigned NOT NULL PRI  auto_increment
+	// Scope Scope
+	Scope string `max_len:"8"` // scope varchar(8) NOT NULL MUL DEFAULT ''default''
+	// ScopeID Scope Id
+	ScopeID int32 `max_len:"10"` // scope_id int(11) NOT NULL  DEFAULT '0'
+	// Expires Value expiration time
+	Expires null.Time // expires datetime NULL  DEFAULT 'NULL'
+	// Path Path
+	Path string `max_len:"255"` // path varchar(255) NOT NULL
+	// Value Value
+	Value null.String `max_len:"65535"` // value text NULL  DEFAULT 'NULL'
+	// VersionTs Timestamp Start Versioning
+	VersionTs time.Time // version_ts timestamp(6) NOT NULL   STORED GENERATED
+	// VersionTe Timestamp End Versioning
+	VersionTe time.Time // version_te timestamp(6) NOT NULL PRI  STORED GENERATED
 }
 
 // AssignLastInsertID updates the increment ID field with the last inserted ID
@@ -241,6 +420,13 @@ func (e *CoreConfiguration) Delete(ctx context.Context, dbm *DBM, opts ...dml.DB
 	return res, nil
 }
 
+// CoreConfigurationDeleteByPK deletes the row with the primary key without
+// loading it. Auto generated.
+func (dbm *DBM) CoreConfigurationDeleteByPK(ctx context.Context, configID uint32) (res sql.Result, err error) {
+	e := &CoreConfiguration{ConfigID: configID}
+	return e.Delete(ctx, dbm)
+}
+
 func (e *CoreConfiguration) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	if e == nil {
 		return nil, errors.NotValid.Newf("CoreConfiguration can't be nil")
@@ -395,6 +581,19 @@ func (cc *CoreConfigurations) DBDelete(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationsDeleteByPKs deletes all rows with the primary keys without
+// loading them. Auto generated.
+func (dbm *DBM) CoreConfigurationsDeleteByPKs(ctx context.Context, pkIDs ...uint32) (res sql.Result, err error) {
+	if len(pkIDs) == 0 {
+		return nil, nil
+	}
+	cc := &CoreConfigurations{Data: make([]*CoreConfiguration, 0, len(pkIDs))}
+	for _, pk := range pkIDs {
+		cc.Data = append(cc.Data, &CoreConfiguration{ConfigID: pk})
+	}
+	return cc.DBDelete(ctx, dbm)
+}
+
 func (cc *CoreConfigurations) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	if cc == nil {
 		return nil, errors.NotValid.Newf("CoreConfigurations can't be nil")
@@ -411,6 +610,15 @@ func (cc *CoreConfigurations) DBUpdate(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationUpdateByPK updates all columns of the entities matched by
+// their primary key. Auto generated.
+func (dbm *DBM) CoreConfigurationUpdateByPK(ctx context.Context, entities ...*CoreConfiguration) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	return (&CoreConfigurations{Data: entities}).DBUpdate(ctx, dbm)
+}
+
 func (cc *CoreConfigurations) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {
 	if cc == nil {
 		return nil, errors.NotValid.Newf("CoreConfigurations can't be nil")
@@ -443,6 +651,44 @@ func (cc *CoreConfigurations) DBUpsert(ctx context.Context, dbm *DBM, opts ...dm
 	return res, nil
 }
 
+// CoreConfigurationUpsertByPK inserts new entities or updates all non primary
+// key columns of the existing entities with one INSERT ... ON DUPLICATE KEY
+// UPDATE statement. Entities without an auto increment ID get the new IDs
+// assigned. Auto generated.
+func (dbm *DBM) CoreConfigurationUpsertByPK(ctx context.Context, entities ...*CoreConfiguration) (res sql.Result, err error) {
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &CoreConfigurations{Data: entities}
+	if err = dbm.eventCoreConfigurationFunc(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ids := make([]uint32, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ConfigID
+	}
+	if res, err = dbm.CachedQuery("CoreConfigurationUpsertByPK").ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.ConfigID = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}
+	if err = dbm.eventCoreConfigurationFunc(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // Each will run function f on all items in []* CoreConfiguration . Auto
 // generated via dmlgen.
 func (cc *CoreConfigurations) Each(f func(*CoreConfiguration)) *CoreConfigurations {
@@ -474,10 +720,14 @@ func (cc *CoreConfigurations) ConfigIDs(ret ...uint32) []uint32 {
 // sales_order_status_state. Auto generated.
 // Table comment: Sales Order Status Table
 type SalesOrderStatusState struct {
-	Status         string // status varchar(32) NOT NULL PRI   "Status"
-	State          string // state varchar(32) NOT NULL PRI   "Label"
-	IsDefault      bool   // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'  "Is Default"
-	VisibleOnFront uint16 // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'  "Visible on front"
+	// Status Status
+	Status string // status varchar(32) NOT NULL PRI
+	// State Label
+	State string // state varchar(32) NOT NULL PRI
+	// IsDefault Is Default
+	IsDefault bool // is_default smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// VisibleOnFront Visible on front
+	VisibleOnFront uint16 // visible_on_front smallint(5) unsigned NOT NULL  DEFAULT '0'
 }
 
 // MapColumns implements interface ColumnMapper only partially. Auto generated.
@@ -659,11 +909,16 @@ func (cc *SalesOrderStatusStates) States(ret ...string) []string {
 // view_customer_auto_increment. Auto generated.
 // Table comment: VIEW
 type ViewCustomerAutoIncrement struct {
-	CeEntityID uint32      // ce_entity_id int(10) unsigned NOT NULL  DEFAULT '0'  "Entity ID"
-	Email      null.String // email varchar(255) NULL  DEFAULT 'NULL'  "Email"
-	Firstname  string      // firstname varchar(255) NOT NULL    "First Name"
-	Lastname   string      // lastname varchar(255) NOT NULL    "Last Name"
-	City       string      // city varchar(255) NOT NULL    "City"
+	// CeEntityID Entity ID
+	CeEntityID uint32 // ce_entity_id int(10) unsigned NOT NULL  DEFAULT '0'
+	// Email Email
+	Email null.String // email varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string // lastname varchar(255) NOT NULL
+	// City City
+	City string // city varchar(255) NOT NULL
 }
 
 // MapColumns implements interface ColumnMapper only partially. Auto generated.
@@ -11,9 +11,12 @@ import (
 // Athlete represents a single row for DB table athlete. Auto generated.
 // Table comment: Athletes
 type Athlete struct {
-	AthleteID    uint32        `max_len:"10"`  // athlete_id int(10) unsigned NOT NULL PRI  auto_increment "Athlete ID"
-	Firstname    null.String   `max_len:"340"` // firstname varchar(340) NULL  DEFAULT 'NULL'  "First Name"
-	Lastname     null.String   `max_len:"340"` // lastname varchar(340) NULL  DEFAULT 'NULL'  "Last Name"
+	// AthleteID Athlete ID
+	AthleteID uint32 `max_len:"10"` // athlete_id int(10) unsigned NOT NULL PRI  auto_increment
+	// Firstname First Name
+	Firstname null.String `max_len:"340"` // firstname varchar(340) NULL  DEFAULT 'NULL'
+	// Lastname Last Name
+	Lastname     null.String   `max_len:"340"` // lastname varchar(340) NULL  DEFAULT 'NULL'
 	AthleteTeams *AthleteTeams // Reversed M:N athlete.athlete_id via athlete_team_member.athlete_id => athlete_team.team_id
 }
 
@@ -33,8 +36,9 @@ func NewAthletes() *Athletes {
 // AthleteTeam represents a single row for DB table athlete_team. Auto generated.
 // Table comment: Athlete Team
 type AthleteTeam struct {
-	TeamID   uint32    `max_len:"10"`  // team_id int(10) unsigned NOT NULL PRI  auto_increment ""
-	Name     string    `max_len:"340"` // name varchar(340) NOT NULL    "Team name"
+	TeamID uint32 `max_len:"10"` // team_id int(10) unsigned NOT NULL PRI  auto_increment ""
+	// Name Team name
+	Name     string    `max_len:"340"` // name varchar(340) NOT NULL
 	Athletes *Athletes // Reversed M:N athlete_team.team_id via athlete_team_member.team_id => athlete.athlete_id
 }
 
@@ -55,31 +59,56 @@ func NewAthleteTeams() *AthleteTeams {
 // customer_address_entity. Auto generated.
 // Table comment: Customer Address Entity
 type CustomerAddressEntity struct {
-	EntityID          uint32      // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	IncrementID       null.String // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	ParentID          null.Uint32 // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'  "Parent ID"
-	CreatedAt         time.Time   // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt         time.Time   // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive          bool        // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	City              string      // city varchar(255) NOT NULL    "City"
-	Company           null.String // company varchar(255) NULL  DEFAULT 'NULL'  "Company"
-	CountryID         string      // country_id varchar(255) NOT NULL    "Country"
-	Fax               null.String // fax varchar(255) NULL  DEFAULT 'NULL'  "Fax"
-	Firstname         string      // firstname varchar(255) NOT NULL    "First Name"
-	Lastname          string      // lastname varchar(255) NOT NULL    "Last Name"
-	Middlename        null.String // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name"
-	Postcode          null.String // postcode varchar(255) NULL  DEFAULT 'NULL'  "Zip/Postal Code"
-	Prefix            null.String // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Region            null.String // region varchar(255) NULL  DEFAULT 'NULL'  "State/Province"
-	RegionID          null.Uint32 // region_id int(10) unsigned NULL  DEFAULT 'NULL'  "State/Province"
-	Street            string      // street text NOT NULL    "Street Address"
-	Suffix            null.String // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Telephone         string      // telephone varchar(255) NOT NULL    "Phone Number"
-	VatID             null.String // vat_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number"
-	VatIsValid        null.Bool   // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validity"
-	VatRequestDate    null.String // vat_request_date varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request date"
-	VatRequestID      null.String // vat_request_id varchar(255) NULL  DEFAULT 'NULL'  "VAT number validation request ID"
-	VatRequestSuccess null.Uint32 // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'  "VAT number validation request success"
+	// EntityID Entity ID
+	EntityID uint32 // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// IncrementID Increment Id
+	IncrementID null.String // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// ParentID Parent ID
+	ParentID null.Uint32 // parent_id int(10) unsigned NULL MUL DEFAULT 'NULL'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// City City
+	City string // city varchar(255) NOT NULL
+	// Company Company
+	Company null.String // company varchar(255) NULL  DEFAULT 'NULL'
+	// CountryID Country
+	CountryID string // country_id varchar(255) NOT NULL
+	// Fax Fax
+	Fax null.String // fax varchar(255) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname string // firstname varchar(255) NOT NULL
+	// Lastname Last Name
+	Lastname string // lastname varchar(255) NOT NULL
+	// Middlename Middle Name
+	Middlename null.String // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Postcode Zip/Postal Code
+	Postcode null.String // postcode varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Region State/Province
+	Region null.String // region varchar(255) NULL  DEFAULT 'NULL'
+	// RegionID State/Province
+	RegionID null.Uint32 // region_id int(10) unsigned NULL  DEFAULT 'NULL'
+	// Street Street Address
+	Street string // street text NOT NULL
+	// Suffix Name Suffix
+	Suffix null.String // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Telephone Phone Number
+	Telephone string // telephone varchar(255) NOT NULL
+	// VatID VAT number
+	VatID null.String // vat_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatIsValid VAT number validity
+	VatIsValid null.Bool // vat_is_valid int(10) unsigned NULL  DEFAULT 'NULL'
+	// VatRequestDate VAT number validation request date
+	VatRequestDate null.String // vat_request_date varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestID VAT number validation request ID
+	VatRequestID null.String // vat_request_id varchar(255) NULL  DEFAULT 'NULL'
+	// VatRequestSuccess VAT number validation request success
+	VatRequestSuccess null.Uint32 // vat_request_success int(10) unsigned NULL  DEFAULT 'NULL'
 }
 
 // CustomerAddressEntities represents a collection type for DB table
@@ -101,34 +130,62 @@ func NewCustomerAddressEntities() *CustomerAddressEntities {
 // generated.
 // Table comment: Customer Entity
 type CustomerEntity struct {
-	EntityID                uint32                   `max_len:"10"`  // entity_id int(10) unsigned NOT NULL PRI  auto_increment "Entity ID"
-	WebsiteID               null.Uint16              `max_len:"5"`   // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'  "Website ID"
-	Email                   null.String              `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'  "Email"
-	GroupID                 uint16                   `max_len:"5"`   // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'  "Group ID"
-	IncrementID             null.String              `max_len:"50"`  // increment_id varchar(50) NULL  DEFAULT 'NULL'  "Increment Id"
-	StoreID                 null.Uint16              `max_len:"5"`   // store_id smallint(5) unsigned NULL MUL DEFAULT '0'  "Store ID"
-	CreatedAt               time.Time                // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'  "Created At"
-	UpdatedAt               time.Time                // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp() "Updated At"
-	IsActive                bool                     `max_len:"5"`   // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'  "Is Active"
-	DisableAutoGroupChange  uint16                   `max_len:"5"`   // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'  "Disable automatic group change based on VAT ID"
-	CreatedIn               null.String              `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'  "Created From"
-	Prefix                  null.String              `max_len:"40"`  // prefix varchar(40) NULL  DEFAULT 'NULL'  "Name Prefix"
-	Firstname               null.String              `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'  "First Name"
-	Middlename              null.String              `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'  "Middle Name/Initial"
-	Lastname                null.String              `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'  "Last Name"
-	Suffix                  null.String              `max_len:"40"`  // suffix varchar(40) NULL  DEFAULT 'NULL'  "Name Suffix"
-	Dob                     null.Time                // dob date NULL  DEFAULT 'NULL'  "Date of Birth"
-	PasswordHash            null.String              `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'  "Password_hash"
-	RpToken                 null.String              `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'  "Reset password token"
-	RpTokenCreatedAt        null.Time                // rp_token_created_at datetime NULL  DEFAULT 'NULL'  "Reset password token creation time"
-	DefaultBilling          null.Uint32              `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'  "Default Billing Address"
-	DefaultShipping         null.Uint32              `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'  "Default Shipping Address"
-	Taxvat                  null.String              `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'  "Tax/VAT Number"
-	Confirmation            null.String              `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'  "Is Confirmed"
-	Gender                  null.Uint16              `max_len:"5"`  // gender smallint(5) unsigned NULL  DEFAULT 'NULL'  "Gender"
-	FailuresNum             null.Int16               `max_len:"5"`  // failures_num smallint(6) NULL  DEFAULT '0'  "Failure Number"
-	FirstFailure            null.Time                // first_failure timestamp NULL  DEFAULT 'NULL'  "First Failure"
-	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'  "Lock Expiration Date"
+	// EntityID Entity ID
+	EntityID uint32 `max_len:"10"` // entity_id int(10) unsigned NOT NULL PRI  auto_increment
+	// WebsiteID Website ID
+	WebsiteID null.Uint16 `max_len:"5"` // website_id smallint(5) unsigned NULL MUL DEFAULT 'NULL'
+	// Email Email
+	Email null.String `max_len:"255"` // email varchar(255) NULL MUL DEFAULT 'NULL'
+	// GroupID Group ID
+	GroupID uint16 `max_len:"5"` // group_id smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// IncrementID Increment Id
+	IncrementID null.String `max_len:"50"` // increment_id varchar(50) NULL  DEFAULT 'NULL'
+	// StoreID Store ID
+	StoreID null.Uint16 `max_len:"5"` // store_id smallint(5) unsigned NULL MUL DEFAULT '0'
+	// CreatedAt Created At
+	CreatedAt time.Time // created_at timestamp NOT NULL  DEFAULT 'current_timestamp()'
+	// UpdatedAt Updated At
+	UpdatedAt time.Time // updated_at timestamp NOT NULL  DEFAULT 'current_timestamp()' on update current_timestamp()
+	// IsActive Is Active
+	IsActive bool `max_len:"5"` // is_active smallint(5) unsigned NOT NULL  DEFAULT '1'
+	// DisableAutoGroupChange Disable automatic group change based on VAT ID
+	DisableAutoGroupChange uint16 `max_len:"5"` // disable_auto_group_change smallint(5) unsigned NOT NULL  DEFAULT '0'
+	// CreatedIn Created From
+	CreatedIn null.String `max_len:"255"` // created_in varchar(255) NULL  DEFAULT 'NULL'
+	// Prefix Name Prefix
+	Prefix null.String `max_len:"40"` // prefix varchar(40) NULL  DEFAULT 'NULL'
+	// Firstname First Name
+	Firstname null.String `max_len:"255"` // firstname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Middlename Middle Name/Initial
+	Middlename null.String `max_len:"255"` // middlename varchar(255) NULL  DEFAULT 'NULL'
+	// Lastname Last Name
+	Lastname null.String `max_len:"255"` // lastname varchar(255) NULL MUL DEFAULT 'NULL'
+	// Suffix Name Suffix
+	Suffix null.String `max_len:"40"` // suffix varchar(40) NULL  DEFAULT 'NULL'
+	// Dob Date of Birth
+	Dob null.Time // dob date NULL  DEFAULT 'NULL'
+	// PasswordHash Password_hash
+	PasswordHash null.String `max_len:"128"` // password_hash varchar(128) NULL  DEFAULT 'NULL'
+	// RpToken Reset password token
+	RpToken null.String `max_len:"128"` // rp_token varchar(128) NULL  DEFAULT 'NULL'
+	// RpTokenCreatedAt Reset password token creation time
+	RpTokenCreatedAt null.Time // rp_token_created_at datetime NULL  DEFAULT 'NULL'
+	// DefaultBilling Default Billing Address
+	DefaultBilling null.Uint32 `max_len:"10"` // default_billing int(10) unsigned NULL  DEFAULT 'NULL'
+	// DefaultShipping Default Shipping Address
+	DefaultShipping null.Uint32 `max_len:"10"` // default_shipping int(10) unsigned NULL  DEFAULT 'NULL'
+	// Taxvat Tax/VAT Number
+	Taxvat null.String `max_len:"50"` // taxvat varchar(50) NULL  DEFAULT 'NULL'
+	// Confirmation Is Confirmed
+	Confirmation null.String `max_len:"64"` // confirmation varchar(64) NULL  DEFAULT 'NULL'
+	// Gender Gender
+	Gender null.Uint16 `max_len:"5"` // gender smallint(5) unsigned NULL  DEFAULT 'NULL'
+	// FailuresNum Failure Number
+	FailuresNum null.Int16 `max_len:"5"` // failures_num smallint(6) NULL  DEFAULT '0'
+	// FirstFailure First Failure
+	FirstFailure null.Time // first_failure timestamp NULL  DEFAULT 'NULL'
+	// LockExpires Lock Expiration Date
+	LockExpires             null.Time                // lock_expires timestamp NULL  DEFAULT 'NULL'
 	Customeraddressentities *CustomerAddressEntities // Reversed 1:M customer_entity.entity_id => customer_address_entity.parent_id
 }
 
@@ -0,0 +1,370 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmlgen
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dml/eav"
+	"github.com/corestoreio/pkg/util/codegen"
+	"github.com/corestoreio/pkg/util/strs"
+)
+
+// EAVEntity describes an EAV entity type, usually a row of the table
+// eav_entity_type with its attributes.
+type EAVEntity struct {
+	TypeID int64
+	// TypeCode defines the name of the generated Go type, e.g.
+	// catalog_product.
+	TypeCode string
+	// Table defines the entity table, e.g. catalog_product_entity.
+	Table string
+	// LinkField links the value tables to the entity table. Defaults to
+	// entity_id.
+	LinkField string
+	// AttributeTable defaults to eav_attribute.
+	AttributeTable string
+	Attributes     []eav.Attribute
+}
+
+func (e EAVEntity) eav() eav.Entity {
+	return eav.Entity{Table: e.Table, TypeID: e.TypeID, LinkField: e.LinkField, AttributeTable: e.AttributeTable}
+}
+
+func (e EAVEntity) entityName() string     { return strs.ToGoCamelCase(e.TypeCode) }
+func (e EAVEntity) collectionName() string { return collectionName(e.TypeCode) }
+func (e EAVEntity) varName() string        { return "eav" + e.entityName() }
+
+// LoadEAVEntity loads an EAV entity type and its attributes from the tables
+// eav_entity_type and eav_attribute.
+func LoadEAVEntity(ctx context.Context, db dml.Querier, entityTypeCode string) (_ EAVEntity, err error) {
+	e := EAVEntity{TypeCode: entityTypeCode}
+	rows, err := db.QueryContext(ctx, "SELECT `entity_type_id`, `entity_table` FROM `eav_entity_type` WHERE `entity_type_code` = ?", entityTypeCode)
+	if err != nil {
+		return e, errors.WithStack(err)
+	}
+	for rows.Next() {
+		if err = rows.Scan(&e.TypeID, &e.Table); err != nil {
+			_ = rows.Close()
+			return e, errors.WithStack(err)
+		}
+	}
+	if err = rows.Close(); err != nil {
+		return e, errors.WithStack(err)
+	}
+	if e.TypeID == 0 {
+		return e, errors.NotFound.Newf("[dmlgen] EAV entity type %q not found", entityTypeCode)
+	}
+
+	rows, err = db.QueryContext(ctx, "SELECT `attribute_code`, `backend_type` FROM `eav_attribute` WHERE `entity_type_id` = ? ORDER BY `attribute_code`", e.TypeID)
+	if err != nil {
+		return e, errors.WithStack(err)
+	}
+	defer func() {
+		if errC := rows.Close(); err == nil && errC != nil {
+			err = errors.WithStack(errC)
+		}
+	}()
+	for rows.Next() {
+		var a eav.Attribute
+		if err = rows.Scan(&a.Code, &a.BackendType); err != nil {
+			return e, errors.WithStack(err)
+		}
+		e.Attributes = append(e.Attributes, a)
+	}
+	return e, errors.WithStack(rows.Err())
+}
+
+// addEAVEntity validates the entity, removes the static attributes and sorts
+// the attributes by code.
+func (g *Generator) addEAVEntity(e EAVEntity) error {
+	if e.TypeCode == "" {
+		return errors.Empty.Newf("[dmlgen] EAV entity type code cannot be empty")
+	}
+	for _, ge := range g.eavEntities {
+		if ge.entityName() == e.entityName() {
+			return errors.AlreadyExists.Newf("[dmlgen] EAV entity %q already added", e.TypeCode)
+		}
+	}
+	attrs := make([]eav.Attribute, 0, len(e.Attributes))
+	for _, a := range e.Attributes {
+		if a.BackendType != eav.TypeStatic {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) == 0 {
+		return errors.Empty.Newf("[dmlgen] EAV entity %q requires at least one attribute with a value table", e.TypeCode)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Code < attrs[j].Code })
+	e.Attributes = attrs
+	if e.LinkField == "" {
+		e.LinkField = "entity_id"
+	}
+	if _, err := e.eav().Select(1, attrs...); err != nil {
+		return errors.Wrapf(err, "[dmlgen] EAV entity %q", e.TypeCode)
+	}
+
+	g.eavEntities = append(g.eavEntities, e)
+	sort.Slice(g.eavEntities, func(i, j int) bool { return g.eavEntities[i].TypeCode < g.eavEntities[j].TypeCode })
+	return nil
+}
+
+// eavGoType returns the Go type and the dml.ColumnMap function of a backend
+// type.
+func eavGoType(backendType string) (goType, cmFunc string) {
+	switch backendType {
+	case eav.TypeInt:
+		return "null.Int64", "NullInt64"
+	case eav.TypeDecimal:
+		return "null.Decimal", "Decimal"
+	case eav.TypeDatetime:
+		return "null.Time", "NullTime"
+	}
+	return "null.String", "NullString"
+}
+
+// fnEAVEntity generates the flattened struct of an EAV entity with its
+// collection, the column mapping and the functions DBLoad and DBSave.
+func (g *Generator) fnEAVEntity(mainGen *codegen.Go, e EAVEntity) {
+	en, cn, vn := e.entityName(), e.collectionName(), e.varName()
+	ln := lcFirst(en) + "Loaded"
+	linkField := strs.ToGoCamelCase(e.LinkField)
+
+	mainGen.C(vn, `defines the tables of the EAV entity`, e.TypeCode+`. Auto generated.`)
+	var attrTable string
+	if e.AttributeTable != "" {
+		attrTable = `, AttributeTable: ` + strconv.Quote(e.AttributeTable)
+	}
+	mainGen.Pln(`var `, vn, ` = eav.Entity{Table: `, strconv.Quote(e.Table), `, TypeID: `, strconv.FormatInt(e.TypeID, 10),
+		`, LinkField: `, strconv.Quote(e.LinkField), attrTable, `}`)
+	mainGen.C(vn+`Attributes`, `contains the attributes of`, en+`. Auto generated.`)
+	mainGen.Pln(`var `, vn+`Attributes = []eav.Attribute{`)
+	for _, a := range e.Attributes {
+		mainGen.Pln(`	{Code: `, strconv.Quote(a.Code), `, BackendType: `, strconv.Quote(a.BackendType), `},`)
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(en, `represents the EAV entity`, e.TypeCode, `with the attribute values of a store.`,
+		`A value which the store does not define contains the default value of store 0. Auto generated.`)
+	mainGen.Pln(`type `, en, ` struct {`)
+	{
+		mainGen.In()
+		mainGen.Pln(linkField, ` uint64`)
+		mainGen.Pln(`StoreID uint32`)
+		for _, a := range e.Attributes {
+			gt, _ := eavGoType(a.BackendType)
+			mainGen.Pln(strs.ToGoCamelCase(a.Code), gt, `// `+a.Code, a.BackendType)
+		}
+		mainGen.Pln(`loaded `, ln)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(ln, `contains the attribute values as loaded by DBLoad and whether the store`,
+		`defines them, so DBSave does not write the default values into the store. Auto generated.`)
+	mainGen.Pln(`type `, ln, ` struct {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`ok bool`)
+		mainGen.Pln(linkField, ` uint64`)
+		mainGen.Pln(`StoreID uint32`)
+		mainGen.Pln(`scoped [`, strconv.Itoa(len(e.Attributes)), `]bool`)
+		for _, a := range e.Attributes {
+			gt, _ := eavGoType(a.BackendType)
+			mainGen.Pln(strs.ToGoCamelCase(a.Code), gt)
+		}
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`MapColumns implements interface ColumnMapper only partially. Auto generated.`)
+	mainGen.Pln(`func (e *`, en, `) MapColumns(cm *dml.ColumnMap) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`for cm.Next() {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`switch c := cm.Column(); c {`)
+			mainGen.Pln(`case `, strconv.Quote(e.LinkField), `:`)
+			mainGen.Pln(`	cm.Uint64(&e.`, linkField, `)`)
+			for _, a := range e.Attributes {
+				_, cmFn := eavGoType(a.BackendType)
+				mainGen.Pln(`case `, strconv.Quote(a.Code), `:`)
+				mainGen.Pln(`	cm.`+cmFn+`(&e.`+strs.ToGoCamelCase(a.Code), `)`)
+			}
+			for i, a := range e.Attributes {
+				mainGen.Pln(`case `, strconv.Quote(eav.ScopeColumn(a.Code)), `:`)
+				mainGen.Pln(`	cm.Bool(&e.loaded.scoped[`, strconv.Itoa(i), `])`)
+			}
+			mainGen.Pln(`default:`)
+			mainGen.Pln(`	return errors.NotFound.Newf("[`+g.Package+`]`, en, `Column %q not found", c)`)
+			mainGen.Pln(`}`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+		mainGen.Pln(`return errors.WithStack(cm.Err())`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`DBLoad loads the attribute values of the store for the ID. Auto generated.`)
+	mainGen.Pln(`func (e *`, en, `) DBLoad(ctx context.Context, db dml.QueryExecPreparer, storeID uint32, id uint64, opts ...dml.DBRFunc) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`if e == nil {`)
+		mainGen.Pln(`	return errors.NotValid.Newf(`, codegen.SkipWS(`"`, en), `can't be nil")`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`cc := &`, cn, `{}`)
+		mainGen.Pln(`if err := cc.DBLoad(ctx, db, storeID, []uint64{id}, opts...); err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`if len(cc.Data) == 0 {`)
+		mainGen.Pln(`	return errors.NotFound.Newf("[`+g.Package+`]`, en, `%d not found", id)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`*e = *cc.Data[0]`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`DBSave writes the attribute values into the value tables of the store.`,
+		`A NULL value deletes the value of the store, so the default value of store 0 applies again.`,
+		`After DBLoad only the changed values and the values defined by the store get written,`,
+		`so the inherited default values stay in store 0.`,
+		`Pass a transaction as db to save all values atomically. Auto generated.`)
+	mainGen.Pln(`func (e *`, en, `) DBSave(ctx context.Context, db dml.Execer) (err error) {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`if e == nil {`)
+		mainGen.Pln(`	return errors.NotValid.Newf(`, codegen.SkipWS(`"`, en), `can't be nil")`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`// without DBLoad of the same entity and store all values get written`)
+		mainGen.Pln(`all := !e.loaded.ok || e.loaded.`+linkField, ` != e.`+linkField, ` || e.loaded.StoreID != e.StoreID`)
+		ent := e.eav()
+		for i, a := range e.Attributes {
+			// the errors have been checked in addEAVEntity.
+			attrSQL, _, _ := ent.AttributeID(a.Code).ToSQL()
+			tbl := dml.Quoter.Name(ent.ValueTable(a.BackendType))
+			lf := dml.Quoter.Name(e.LinkField)
+			upsert := "INSERT INTO " + tbl + " (" + lf + ",`attribute_id`,`store_id`,`value`) " +
+				"SELECT ?,`attribute_id`,?,? FROM (" + attrSQL + ") AS `a` ON DUPLICATE KEY UPDATE `value`=VALUES(`value`)"
+			del := "DELETE FROM " + tbl + " WHERE " + lf + " = ? AND `store_id` = ? AND `attribute_id` = (" + attrSQL + ")"
+			fn := strs.ToGoCamelCase(a.Code)
+			field, loaded, scoped := `e.`+fn, `e.loaded.`+fn, `e.loaded.scoped[`+strconv.Itoa(i)+`]`
+			changed := field + ` != ` + loaded
+			if a.BackendType == eav.TypeDatetime {
+				changed = field + `.Valid != ` + loaded + `.Valid || !` + field + `.Time.Equal(` + loaded + `.Time)`
+			}
+
+			mainGen.Pln(`if all || `, scoped, ` || `, changed, ` {`)
+			{
+				mainGen.In()
+				mainGen.Pln(`if `, field, `.Valid {`)
+				mainGen.Pln(`	_, err = db.ExecContext(ctx, `, strconv.Quote(upsert), `, e.`+linkField, `, e.StoreID, `, field, `)`)
+				mainGen.Pln(`} else {`)
+				mainGen.Pln(`	_, err = db.ExecContext(ctx, `, strconv.Quote(del), `, e.`+linkField, `, e.StoreID)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`if err != nil {`)
+				mainGen.Pln(`	return errors.Wrapf(err, "[`+g.Package+`]`, en, `failed to save attribute %q", `, strconv.Quote(a.Code), `)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(loaded, ` = `, field)
+				mainGen.Pln(scoped, ` = `, field, `.Valid`)
+				mainGen.Out()
+			}
+			mainGen.Pln(`}`)
+		}
+		mainGen.Pln(`e.loaded.ok, e.loaded.`+linkField, `, e.loaded.StoreID = true, e.`+linkField, `, e.StoreID`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(cn, `represents a collection type for the EAV entity`, e.TypeCode+`. Auto generated.`)
+	mainGen.Pln(`type `, cn, ` struct {`)
+	mainGen.Pln(`	Data []*`, en)
+	mainGen.Pln(`}`)
+
+	mainGen.C(`MapColumns implements dml.ColumnMapper interface. Auto generated.`)
+	mainGen.Pln(`func (cc *`, cn, `) MapColumns(cm *dml.ColumnMap) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`if m := cm.Mode(); m != dml.ColumnMapScan {`)
+		mainGen.Pln(`	return errors.NotSupported.Newf("[` + g.Package + `] Unknown Mode: %q", string(m))`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`if cm.Count == 0 {`)
+		mainGen.Pln(`	cc.Data = cc.Data[:0]`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`e := new(`, en, `)`)
+		mainGen.Pln(`if err := e.MapColumns(cm); err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`cc.Data = append(cc.Data, e)`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`DBLoad loads the entities of the IDs with the attribute values of the store.`,
+		`Entities without any row in the entity table get skipped. Auto generated.`)
+	mainGen.Pln(`func (cc *`, cn, `) DBLoad(ctx context.Context, db dml.QueryExecPreparer, storeID uint32, ids []uint64, opts ...dml.DBRFunc) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`if cc == nil {`)
+		mainGen.Pln(`	return errors.NotValid.Newf(`, codegen.SkipWS(`"`, cn), `can't be nil")`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`sel, err := `, vn+`.JoinAttributes(dml.NewSelect(`, strconv.Quote("e."+e.LinkField), `).FromAlias(`, strconv.Quote(e.Table), `, "e"), int64(storeID), `, vn+`Attributes...)`)
+		mainGen.Pln(`if err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(vn+`.JoinAttributeScopes(sel, int64(storeID), `, vn+`Attributes...)`)
+		mainGen.Pln(`sel.Where(dml.Column(`, strconv.Quote("e."+e.LinkField), `).In().PlaceHolder())`)
+		mainGen.Pln(`if _, err = sel.WithDBR().WithDB(db).Interpolate().ApplyCallBacks(opts...).Load(ctx, cc, ids); err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`for _, e := range cc.Data {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`e.StoreID = storeID`)
+			mainGen.Pln(`e.loaded.ok, e.loaded.`+linkField, `, e.loaded.StoreID = true, e.`+linkField, `, storeID`)
+			for _, a := range e.Attributes {
+				fn := strs.ToGoCamelCase(a.Code)
+				mainGen.Pln(`e.loaded.`+fn, ` = e.`+fn)
+			}
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`DBSave saves all entities, see`, en+`.DBSave. Auto generated.`)
+	mainGen.Pln(`func (cc *`, cn, `) DBSave(ctx context.Context, db dml.Execer) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`for _, e := range cc.Data {`)
+		mainGen.Pln(`	if err := e.DBSave(ctx, db); err != nil {`)
+		mainGen.Pln(`		return errors.WithStack(err)`)
+		mainGen.Pln(`	}`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+}
@@ -22,7 +22,9 @@ const (
 	FeatureDB
 	FeatureDBAssignLastInsertID
 	FeatureDBDelete
+	FeatureDBFilter // typed WHERE conditions for indexed columns
 	FeatureDBInsert
+	FeatureDBLoadRelations // Load<Relation> functions for the foreign keys
 	FeatureDBMapColumns
 	FeatureDBSelect
 	FeatureDBTracing // opentelemetry tracing
@@ -55,7 +57,9 @@ var featureNames = map[FeatureToggle]string{
 	FeatureDB:                          "FeatureDB",
 	FeatureDBAssignLastInsertID:        "FeatureDBAssignLastInsertID",
 	FeatureDBDelete:                    "FeatureDBDelete",
+	FeatureDBFilter:                    "FeatureDBFilter",
 	FeatureDBInsert:                    "FeatureDBInsert",
+	FeatureDBLoadRelations:             "FeatureDBLoadRelations",
 	FeatureDBMapColumns:                "FeatureDBMapColumns",
 	FeatureDBSelect:                    "FeatureDBSelect",
 	FeatureDBTracing:                   "FeatureDBTracing",
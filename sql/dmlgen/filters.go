@@ -0,0 +1,95 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmlgen
+
+import (
+	"strings"
+
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/util/codegen"
+	"github.com/corestoreio/pkg/util/strs"
+)
+
+// filterType returns for a column the Go type of the filter argument and the
+// name of the dml.Condition functions for a single and multiple values. Only
+// integer and string columns are supported.
+func (g *Generator) filterType(c *ddl.Column) (goType, single, multi string, ok bool) {
+	switch gt := g.goType(c); {
+	case strings.HasPrefix(gt, "uint"):
+		return "uint64", "Uint64", "Uint64s", true
+	case strings.HasPrefix(gt, "int"):
+		return "int64", "Int64", "Int64s", true
+	case gt == "string":
+		return "string", "Str", "Strs", true
+	}
+	return "", "", "", false
+}
+
+// fnDBFilters generates the type <EntityName>Filters with typed functions to
+// compose WHERE conditions for the indexed columns of a table. A table without
+// any supported indexed column gets skipped.
+func (t *Table) fnDBFilters(mainGen *codegen.Go, g *Generator) {
+	if !t.hasFeature(g, FeatureDB|FeatureDBFilter) {
+		return
+	}
+	var cols ddl.Columns
+	for _, c := range t.Table.Columns {
+		if _, _, _, ok := g.filterType(c); ok && c.Key != "" {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == 0 {
+		return
+	}
+
+	ft := t.EntityName() + `Filters`
+	mainGen.C(ft, `composes typed WHERE conditions for the indexed columns of table`, t.Table.Name,
+		`and applies them to a SELECT. Auto generated.`)
+	mainGen.Pln(`type `, ft, ` struct {
+		conditions dml.Conditions
+	}`)
+	mainGen.C(`New`+ft, `creates a new empty filter. Auto generated.`)
+	mainGen.Pln(`func New`+ft, `() *`, ft, ` { return &`, ft, `{} }`)
+
+	for _, c := range cols {
+		goType, single, multi, _ := g.filterType(c)
+		goCamel := strs.ToGoCamelCase(c.Field)
+
+		mainGen.C(`Where`+goCamel, `adds the condition`, c.Field, `= ?. Auto generated.`)
+		mainGen.Pln(`func (f *`, ft, `) Where`+goCamel, `(v `, goType, `) *`, ft, ` {
+			f.conditions = append(f.conditions, dml.Column(`, "`"+c.Field+"`", `).Equal().`, single, `(v))
+			return f
+		}`)
+		mainGen.C(`Where`+goCamel+`In`, `adds the condition`, c.Field, `IN (?). Auto generated.`)
+		mainGen.Pln(`func (f *`, ft, `) Where`+goCamel+`In`, `(vs ...`, goType, `) *`, ft, ` {
+			f.conditions = append(f.conditions, dml.Column(`, "`"+c.Field+"`", `).In().`, multi, `(vs...))
+			return f
+		}`)
+		if goType == "string" {
+			mainGen.C(`Where`+goCamel+`Like`, `adds the condition`, c.Field, `LIKE ?. Auto generated.`)
+			mainGen.Pln(`func (f *`, ft, `) Where`+goCamel+`Like`, `(s string) *`, ft, ` {
+			f.conditions = append(f.conditions, dml.Column(`, "`"+c.Field+"`", `).Like().Str(s))
+			return f
+		}`)
+		}
+	}
+
+	mainGen.C(`Conditions returns the composed conditions. Auto generated.`)
+	mainGen.Pln(`func (f *`, ft, `) Conditions() dml.Conditions { return f.conditions }`)
+	mainGen.C(`Select appends the composed conditions to the WHERE clause of sel. Auto generated.`)
+	mainGen.Pln(`func (f *`, ft, `) Select(sel *dml.Select) *dml.Select {
+		return sel.Where(f.conditions...)
+	}`)
+}
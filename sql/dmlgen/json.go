@@ -4,8 +4,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/util/codegen"
+	"github.com/corestoreio/pkg/util/strs"
 	"github.com/mailru/easyjson/bootstrap"
 	"github.com/mailru/easyjson/parser"
 )
@@ -63,3 +67,262 @@ func GenerateJSON(fileNameOrDirectory, buildTags string, g *bootstrap.Generator)
 	}
 	return nil
 }
+
+// fnEntityJSON writes the MarshalJSON and UnmarshalJSON methods for the
+// encoder jsongen. Only the public columns get encoded, relationships are
+// skipped. The null types get written as their value or JSON null, hence the
+// global JSON functions of package null are not needed.
+func (t *Table) fnEntityJSON(mainGen *codegen.Go, g *Generator) {
+	if !t.HasJSONMarshaler || !g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureEntityStruct) {
+		return
+	}
+
+	mainGen.C(`appendJSON appends the columns as JSON object to buf. Auto generated.`)
+	mainGen.Pln(`func (e *`, t.EntityName(), `) appendJSON(buf []byte) ([]byte, error) {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`buf = append(buf, '{')`)
+		t.Table.Columns.Each(func(c *ddl.Column) {
+			if t.IsFieldPublic(c.Field) {
+				t.jsonAppendField(mainGen, g, c)
+			}
+		})
+		mainGen.Pln(`if buf[len(buf)-1] == ',' {`)
+		mainGen.Pln(`	buf = buf[:len(buf)-1]`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`return append(buf, '}'), nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`MarshalJSON implements json.Marshaler without reflection. Auto generated.`)
+	mainGen.Pln(`func (e *`, t.EntityName(), `) MarshalJSON() ([]byte, error) {`)
+	{
+		mainGen.Pln(`if e == nil {
+			return []byte("null"), nil
+		}
+		return e.appendJSON(make([]byte, 0, `, len(t.Table.Columns)*24, `))`)
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`UnmarshalJSON implements json.Unmarshaler. Unknown keys are ignored. Auto generated.`)
+	mainGen.Pln(`func (e *`, t.EntityName(), `) UnmarshalJSON(data []byte) error {`)
+	{
+		mainGen.In()
+		mainGen.Pln(`var fields map[string]json.RawMessage`)
+		mainGen.Pln(`if err := json.Unmarshal(data, &fields); err != nil {`)
+		mainGen.Pln(`	return errors.WithStack(err)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`for k, v := range fields {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`var err error`)
+			mainGen.Pln(`switch k {`)
+			t.Table.Columns.Each(func(c *ddl.Column) {
+				if t.IsFieldPublic(c.Field) {
+					t.jsonDecodeField(mainGen, g, c)
+				}
+			})
+			mainGen.Pln(`}`)
+			mainGen.Pln(`if err != nil {`)
+			mainGen.Pln(`	return errors.Wrapf(err, "[` + t.Package + `] ` + t.EntityName() + `.UnmarshalJSON failed for key %q", k)`)
+			mainGen.Pln(`}`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+		mainGen.Pln(`return nil`)
+		mainGen.Out()
+	}
+	mainGen.Pln(`}`)
+}
+
+// fnCollectionJSON writes the MarshalJSON and UnmarshalJSON methods for the
+// encoder jsongen. The collection keeps the object with the key data like the
+// struct tag of field Data.
+func (t *Table) fnCollectionJSON(mainGen *codegen.Go, g *Generator) {
+	if !t.HasJSONMarshaler || !g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureEntityStruct) ||
+		!g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureCollectionStruct) {
+		return
+	}
+
+	mainGen.C(`MarshalJSON implements json.Marshaler without reflection. Auto generated.`)
+	mainGen.Pln(`func (cc *`, t.CollectionName(), `) MarshalJSON() (_ []byte, err error) {`)
+	{
+		mainGen.Pln(`if cc == nil {
+			return []byte("null"), nil
+		}
+		buf := make([]byte, 0, 11+len(cc.Data)*`, len(t.Table.Columns)*24, `)
+		buf = append(buf, `+"`"+`{"data":[`+"`"+`...)
+		for i, e := range cc.Data {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if e == nil {
+				buf = append(buf, "null"...)
+				continue
+			}
+			if buf, err = e.appendJSON(buf); err != nil {
+				return nil, errors.Wrapf(err, "[`+t.Package+`] `+t.CollectionName()+`.MarshalJSON failed at index %d", i)
+			}
+		}
+		return append(buf, ']', '}'), nil`)
+	}
+	mainGen.Pln(`}`)
+
+	mainGen.C(`UnmarshalJSON implements json.Unmarshaler and appends the entities. Auto generated.`)
+	mainGen.Pln(`func (cc *`, t.CollectionName(), `) UnmarshalJSON(data []byte) error {`)
+	{
+		mainGen.Pln(`var rows struct {
+			Data []json.RawMessage `+"`"+`json:"data"`+"`"+`
+		}
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return errors.WithStack(err)
+		}
+		for i, r := range rows.Data {
+			e := new(`, t.EntityName(), `)
+			if err := e.UnmarshalJSON(r); err != nil {
+				return errors.Wrapf(err, "[`+t.Package+`] `+t.CollectionName()+`.UnmarshalJSON failed at index %d", i)
+			}
+			cc.Data = append(cc.Data, e)
+		}
+		return nil`)
+	}
+	mainGen.Pln(`}`)
+}
+
+func (t *Table) jsonKey(c *ddl.Column) string {
+	if t.jsonFieldNameCase != "camel" {
+		return c.Field
+	}
+	r := []rune(strs.ToGoCamelCase(c.Field))
+	// EntityID => entityID, URLKey => urlKey, ID => id
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+func (t *Table) jsonAppendField(mainGen *codegen.Go, g *Generator, c *ddl.Column) {
+	goType := g.goTypeNull(c)
+	field := `e.` + strs.ToGoCamelCase(c.Field)
+	value := field
+	var valid string // Go expression to check for NULL
+	switch {
+	case goType == "null.Decimal":
+		valid = field + `.Valid`
+	case strings.HasPrefix(goType, "null."):
+		valid = field + `.Valid`
+		value = `e.` + g.toGoPrimitiveFromNull(c)
+	case goType == "[]byte" && c.IsNull():
+		valid = field + ` != nil`
+	}
+	appendValue := jsonAppendValue(g.goType(c), value)
+	key := "buf = append(buf, `\"" + t.jsonKey(c) + "\":`...)"
+
+	var cond string
+	switch t.jsonOmitEmpty {
+	case "null":
+		cond = valid
+	case "zero":
+		cond = valid
+		if nz := jsonNonZero(g.goType(c), value); nz != "" {
+			if cond != "" {
+				cond += ` && `
+			}
+			cond += nz
+		}
+	}
+
+	switch {
+	case cond != "":
+		mainGen.Pln(`if`, cond, `{`)
+		mainGen.Pln(key)
+		mainGen.Pln(appendValue)
+		mainGen.Pln(`buf = append(buf, ',')`)
+		mainGen.Pln(`}`)
+	case valid != "":
+		mainGen.Pln(key)
+		mainGen.Pln(`if`, valid, `{`)
+		mainGen.Pln(appendValue)
+		mainGen.Pln(`} else {`)
+		mainGen.Pln(`	buf = append(buf, "null"...)`)
+		mainGen.Pln(`}`)
+		mainGen.Pln(`buf = append(buf, ',')`)
+	default:
+		mainGen.Pln(key)
+		mainGen.Pln(appendValue)
+		mainGen.Pln(`buf = append(buf, ',')`)
+	}
+}
+
+// jsonAppendValue returns the Go code which appends the JSON encoded value of
+// expr to the variable buf.
+func jsonAppendValue(goType, expr string) string {
+	switch goType {
+	case "string":
+		return `buf = byteconv.AppendJSONString(buf, ` + expr + `)`
+	case "int8", "int16", "int32", "int64":
+		return `buf = strconv.AppendInt(buf, int64(` + expr + `), 10)`
+	case "uint8", "uint16", "uint32", "uint64":
+		return `buf = strconv.AppendUint(buf, uint64(` + expr + `), 10)`
+	case "float64":
+		return `buf = strconv.AppendFloat(buf, ` + expr + `, 'g', -1, 64)`
+	case "bool":
+		return `buf = strconv.AppendBool(buf, ` + expr + `)`
+	case "time.Time":
+		return "buf = append(buf, '\"')\nbuf = " + expr + ".AppendFormat(buf, time.RFC3339Nano)\nbuf = append(buf, '\"')"
+	case "[]byte":
+		return "buf = append(buf, '\"')\nbuf = append(buf, base64.StdEncoding.EncodeToString(" + expr + ")...)\nbuf = append(buf, '\"')"
+	}
+	// null.Decimal
+	return `b, err := ` + expr + `.MarshalJSON()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buf = append(buf, b...)`
+}
+
+// jsonNonZero returns the Go expression to check expr for a non zero value. An
+// empty string gets returned if the type has no zero check.
+func jsonNonZero(goType, expr string) string {
+	switch goType {
+	case "string":
+		return expr + ` != ""`
+	case "int8", "int16", "int32", "int64", "uint8", "uint16", "uint32", "uint64", "float64":
+		return expr + ` != 0`
+	case "bool":
+		return expr
+	case "time.Time":
+		return `!` + expr + `.IsZero()`
+	case "[]byte":
+		return `len(` + expr + `) > 0`
+	}
+	return ""
+}
+
+func (t *Table) jsonDecodeField(mainGen *codegen.Go, g *Generator, c *ddl.Column) {
+	goType := g.goTypeNull(c)
+	field := `e.` + strs.ToGoCamelCase(c.Field)
+
+	mainGen.Pln(`case "` + t.jsonKey(c) + `":`)
+	switch {
+	case goType == "null.Decimal":
+		mainGen.Pln(`if string(v) == "null" {`)
+		mainGen.Pln(`	`+field, `= null.Decimal{}`)
+		mainGen.Pln(`} else {`)
+		mainGen.Pln(`	err =`, field+`.UnmarshalJSON(v)`)
+		mainGen.Pln(`}`)
+	case strings.HasPrefix(goType, "null."):
+		mainGen.Pln(`if string(v) == "null" {`)
+		mainGen.Pln(`	`+field, `=`, goType+`{}`)
+		mainGen.Pln(`} else {`)
+		mainGen.Pln(`	` + field + `.Valid = true`)
+		mainGen.Pln(`	err = json.Unmarshal(v, &e.` + g.toGoPrimitiveFromNull(c) + `)`)
+		mainGen.Pln(`}`)
+	default:
+		mainGen.Pln(`err = json.Unmarshal(v, &` + field + `)`)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmlgen
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/corestoreio/errors"
+)
+
+var (
+	protoMessageRE  = regexp.MustCompile(`^\s*message\s+(\w+)\s*{`)
+	protoFieldRE    = regexp.MustCompile(`^\s*(?:repeated\s+)?[\w.]+\s+(\w+)\s*=\s*(\d+)`)
+	protoReservedRE = regexp.MustCompile(`^\s*reserved\s+(\d+)\s*;(?:\s*//\s*(\w+))?`)
+)
+
+// protoMessage contains the field numbers of a message of a previously
+// generated .proto file.
+type protoMessage struct {
+	fields map[string]uint64 // field name => number
+	// reserved contains the numbers of removed fields and their names, if
+	// known.
+	reserved map[uint64]string
+}
+
+// parseProtoFieldNumbers reads the field numbers of all top level messages.
+// Nested messages, oneofs and enums are not supported, because generateProto
+// does not create them.
+func parseProtoFieldNumbers(r io.Reader) (map[string]*protoMessage, error) {
+	ret := make(map[string]*protoMessage)
+	var msg *protoMessage
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if m := protoMessageRE.FindStringSubmatch(line); m != nil {
+			msg = &protoMessage{fields: map[string]uint64{}, reserved: map[uint64]string{}}
+			ret[m[1]] = msg
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		if m := protoReservedRE.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.ParseUint(m[1], 10, 64) // regex guarantees digits
+			msg.reserved[n] = m[2]
+			continue
+		}
+		if m := protoFieldRE.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.ParseUint(m[2], 10, 64)
+			msg.fields[m[1]] = n
+			continue
+		}
+		if len(line) > 0 && line[0] == '}' {
+			msg = nil
+		}
+	}
+	return ret, errors.WithStack(s.Err())
+}
+
+// protoFieldNumbers assigns the field numbers of one message. Without a
+// previous message the default numbers get used. With a previous message the
+// fields keep their numbers, new fields get numbers after the highest number
+// ever used and removed fields get reserved.
+type protoFieldNumbers struct {
+	prev    *protoMessage
+	used    map[string]bool
+	highest uint64
+}
+
+func newProtoFieldNumbers(prev *protoMessage) *protoFieldNumbers {
+	pf := &protoFieldNumbers{prev: prev, used: map[string]bool{}}
+	if prev == nil {
+		return pf
+	}
+	for _, n := range prev.fields {
+		if n > pf.highest {
+			pf.highest = n
+		}
+	}
+	for n := range prev.reserved {
+		if n > pf.highest {
+			pf.highest = n
+		}
+	}
+	return pf
+}
+
+// number returns the field number of the field name.
+func (pf *protoFieldNumbers) number(field string, defaultNumber uint64) uint64 {
+	pf.used[field] = true
+	if pf.prev == nil {
+		return defaultNumber
+	}
+	if n, ok := pf.prev.fields[field]; ok {
+		return n
+	}
+	pf.highest++
+	return pf.highest
+}
+
+// reserved returns the reserved statements for the removed fields and the
+// fields reserved in the previous message. Must be called after all fields
+// have been numbered.
+func (pf *protoFieldNumbers) reserved() []string {
+	if pf.prev == nil {
+		return nil
+	}
+	names := make(map[uint64]string, len(pf.prev.reserved))
+	for n, name := range pf.prev.reserved {
+		names[n] = name
+	}
+	for name, n := range pf.prev.fields {
+		if !pf.used[name] {
+			names[n] = name
+		}
+	}
+	numbers := make([]uint64, 0, len(names))
+	for n := range names {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	ret := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		r := "reserved " + strconv.FormatUint(n, 10) + ";"
+		if names[n] != "" {
+			r += " // " + names[n]
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
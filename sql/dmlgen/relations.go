@@ -0,0 +1,214 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmlgen
+
+import (
+	"strings"
+
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/util/codegen"
+	"github.com/corestoreio/pkg/util/strs"
+)
+
+// entityRelation describes a relationship field of the entity struct which
+// can be loaded via a foreign key.
+type entityRelation struct {
+	kind      string      // 1:M, 1:1, Reversed 1:M or Reversed 1:1
+	column    *ddl.Column // column of the current table
+	refTable  *Table
+	refColumn *ddl.Column
+	field     string // name of the struct field
+	many      bool   // field is a collection
+}
+
+// loadableRelations returns the one-to-many and one-to-one relationships of
+// the table, in the same order and with the same rules as the struct fields
+// get generated in function entityStruct. Many-to-many relationships are not
+// supported because they require a join via the link table.
+func (t *Table) loadableRelations(g *Generator) []entityRelation {
+	if !g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureEntityRelationships) ||
+		!g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureDBLoadRelations) ||
+		!t.hasFeature(g, FeatureDBSelect) {
+		return nil
+	}
+	if _, ok := g.customCode["type_"+t.EntityName()]; ok {
+		return nil // unknown struct fields
+	}
+	fieldMapFn := t.fieldMapFunc(g)
+
+	var rels []entityRelation
+	add := func(kcuce *ddl.KeyColumnUsage, kind string, many bool) {
+		rt := g.Tables[kcuce.ReferencedTableName.Data]
+		c := t.Table.Columns.ByField(kcuce.ColumnName)
+		rc := rt.Table.Columns.ByField(kcuce.ReferencedColumnName.Data)
+		if c == nil || rc == nil || !rt.hasFeature(g, FeatureDBSelect) {
+			return
+		}
+		if kt := g.goType(c); strings.HasPrefix(kt, "[]") || kt == "null.Decimal" {
+			return // not usable as map key
+		}
+		field := fieldMapFn(strs.ToGoCamelCase(rt.Table.Name))
+		if many {
+			field = fieldMapFn(collectionName(rt.Table.Name))
+		}
+		rels = append(rels, entityRelation{kind: kind, column: c, refTable: rt, refColumn: rc, field: field, many: many})
+	}
+
+	if kcuc, ok := g.kcu[t.Table.Name]; ok {
+		for _, kcuce := range kcuc.Data {
+			if !kcuce.ReferencedTableName.Valid || g.Tables[kcuce.ReferencedTableName.Data] == nil ||
+				!g.isAllowedRelationship(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) {
+				continue
+			}
+			if g.krs.IsOneToMany(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) {
+				add(kcuce, "1:M", true)
+			}
+			if g.krs.IsOneToOne(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) {
+				add(kcuce, "1:1", false)
+			}
+		}
+	}
+
+	if kcuc, ok := g.kcuRev[t.Table.Name]; ok {
+		relationShipSeen := map[string]bool{}
+		for _, kcuce := range kcuc.Data {
+			if !kcuce.ReferencedTableName.Valid || g.Tables[kcuce.ReferencedTableName.Data] == nil ||
+				!g.isAllowedRelationship(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) {
+				continue
+			}
+			keySeen := fieldMapFn(collectionName(kcuce.ReferencedTableName.Data))
+			if g.krs.IsOneToMany(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) && !relationShipSeen[keySeen] {
+				add(kcuce, "Reversed 1:M", true)
+				relationShipSeen[keySeen] = true
+			}
+			if g.krs.IsOneToOne(kcuce.TableName, kcuce.ColumnName, kcuce.ReferencedTableName.Data, kcuce.ReferencedColumnName.Data) {
+				add(kcuce, "Reversed 1:1", false)
+			}
+		}
+	}
+	return rels
+}
+
+// relationKey returns the Go expression of the not-NULL value of column c of
+// the variable v and the expression which checks for NULL, if any.
+func (g *Generator) relationKey(v string, c *ddl.Column) (key, valid string) {
+	if gtn := g.goTypeNull(c); strings.HasPrefix(gtn, "null.") {
+		return v + `.` + g.toGoPrimitiveFromNull(c), v + `.` + strs.ToGoCamelCase(c.Field) + `.Valid`
+	}
+	return v + `.` + strs.ToGoCamelCase(c.Field), ""
+}
+
+// fnDBLoadRelations generates for each relationship field of the entity the
+// functions Load<Field> for the entity and the collection. The collection
+// function loads the related rows of all entities with batched IN queries to
+// avoid N+1 queries.
+func (t *Table) fnDBLoadRelations(mainGen *codegen.Go, g *Generator) {
+	rels := t.loadableRelations(g)
+	if len(rels) == 0 {
+		return
+	}
+	tracingEnabled := t.hasFeature(g, FeatureDBTracing)
+
+	for _, rel := range rels {
+		rt := rel.refTable
+		fkInfo := t.Table.Name + `.` + rel.column.Field + ` => ` + rt.Table.Name + `.` + rel.refColumn.Field
+		keyType := g.goType(rel.column)
+		fnName := `Load` + rel.field
+
+		mainGen.C(fnName, `loads the`, rel.kind, `relationship`, fkInfo, `into field`, rel.field+`. Auto generated.`)
+		mainGen.Pln(`func (e *`, t.EntityName(), `) `, fnName, `(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) error {`)
+		{
+			mainGen.In()
+			mainGen.Pln(`if e == nil {`)
+			mainGen.Pln(`	return errors.NotValid.Newf(`, codegen.SkipWS(`"`, t.EntityName()), `can't be nil")`)
+			mainGen.Pln(`}`)
+			mainGen.Pln(`cc := &`, t.CollectionName(), `{Data: []*`, t.EntityName(), `{e}}`)
+			mainGen.Pln(`return errors.WithStack(cc.`, fnName, `(ctx, dbm, opts...))`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+
+		mainGen.C(fnName, `loads the`, rel.kind, `relationship`, fkInfo, `for all entities into their field`, rel.field+`.`,
+			`The keys get queried in batches with an IN clause; the batch size can be set in DBMOption.RelationBatchSize. Auto generated.`)
+		mainGen.Pln(`func (cc *`, t.CollectionName(), `) `, fnName, `(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (err error) {`)
+		{
+			mainGen.In()
+			mainGen.Pln(tracingEnabled, `ctx, span := dbm.option.Trace.Start(ctx, `, codegen.SkipWS(`"`, t.CollectionName(), fnName, `"`), `)`)
+			mainGen.Pln(tracingEnabled, `defer func(){ cstrace.Status(span, err); span.End(); }()`)
+			mainGen.Pln(`if cc == nil {`)
+			mainGen.Pln(`	return errors.NotValid.Newf(`, codegen.SkipWS(`"`, t.CollectionName()), `can't be nil")`)
+			mainGen.Pln(`}`)
+			mainGen.Pln(`byKey := make(map[`, keyType, `][]*`, t.EntityName(), `, len(cc.Data))`)
+			mainGen.Pln(`keys := make([]`, keyType, `, 0, len(cc.Data))`)
+			mainGen.Pln(`for _, e := range cc.Data {`)
+			{
+				mainGen.In()
+				if rel.many {
+					mainGen.Pln(`e.`, rel.field, ` = &`, rt.CollectionName(), `{}`)
+				} else {
+					mainGen.Pln(`e.`, rel.field, ` = nil`)
+				}
+				key, valid := g.relationKey(`e`, rel.column)
+				mainGen.Pln(valid != "", `if !`, valid, ` { continue }`)
+				mainGen.Pln(`k := `, key)
+				mainGen.Pln(`if _, ok := byKey[k]; !ok {`)
+				mainGen.Pln(`	keys = append(keys, k)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`byKey[k] = append(byKey[k], e)`)
+				mainGen.Out()
+			}
+			mainGen.Pln(`}`)
+
+			mainGen.Pln(`for len(keys) > 0 {`)
+			{
+				mainGen.In()
+				mainGen.Pln(`batch := keys`)
+				mainGen.Pln(`if len(batch) > dbm.option.RelationBatchSize {`)
+				mainGen.Pln(`	batch = batch[:dbm.option.RelationBatchSize]`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`keys = keys[len(batch):]`)
+				mainGen.Pln(`rel := new(`, rt.CollectionName(), `)`)
+				mainGen.Pln(`if _, err = dbm.option.InitSelectFn(dbm.Tables.MustTable(TableName`+strs.ToGoCamelCase(rt.Table.Name), `).Select("*")).Where(`)
+				mainGen.Pln(`	dml.Column(`, "`"+rel.refColumn.Field+"`", `).In().PlaceHolder(),`)
+				mainGen.Pln(`).WithDBR().Interpolate().ApplyCallBacks(opts...).Load(ctx, rel, batch); err != nil {`)
+				mainGen.Pln(`	return errors.WithStack(err)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`if err = dbm.event` + rt.EntityName() + `Func(ctx, dml.EventFlagAfterSelect, rel, nil); err != nil {`)
+				mainGen.Pln(`	return errors.WithStack(err)`)
+				mainGen.Pln(`}`)
+				mainGen.Pln(`for _, r := range rel.Data {`)
+				{
+					mainGen.In()
+					key, valid := g.relationKey(`r`, rel.refColumn)
+					mainGen.Pln(valid != "", `if !`, valid, ` { continue }`)
+					mainGen.Pln(`for _, e := range byKey[`, keyType, `(`, key, `)] {`)
+					if rel.many {
+						mainGen.Pln(`	e.`, rel.field, `.Data = append(e.`, rel.field, `.Data, r)`)
+					} else {
+						mainGen.Pln(`	e.`, rel.field, ` = r`)
+					}
+					mainGen.Pln(`}`)
+					mainGen.Out()
+				}
+				mainGen.Pln(`}`)
+				mainGen.Out()
+			}
+			mainGen.Pln(`}`)
+			mainGen.Pln(`return nil`)
+			mainGen.Out()
+		}
+		mainGen.Pln(`}`)
+	}
+}
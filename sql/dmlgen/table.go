@@ -33,6 +33,7 @@ type Table struct {
 	Comment              string // Comment above the struct type declaration
 	HasAutoIncrement     uint8  // 0=nil,1=false (has NO auto increment),2=true has auto increment
 	HasEasyJSONMarshaler bool
+	HasJSONMarshaler     bool // writes MarshalJSON and UnmarshalJSON if true
 	HasSerializer        bool // writes the .proto file if true
 
 	// PrivateFields key=snake case name of the DB column, value=true, the field must be private
@@ -42,6 +43,15 @@ type Table struct {
 	featuresExclude       FeatureToggle
 	fieldMapFn            func(dbIdentifier string) (newName string)
 	customStructTagFields map[string]string
+	// piiColumns key=column name, value=anonymization strategy
+	piiColumns map[string]string
+	// optimisticLockColumn if not nil, the version column for UPDATE
+	// statements.
+	optimisticLockColumn *ddl.Column
+	// jsonFieldNameCase and jsonOmitEmpty configure the jsongen encoder, see
+	// TableConfig.
+	jsonFieldNameCase string
+	jsonOmitEmpty     string
 }
 
 func (t *Table) IsFieldPublic(dbColumnName string) bool {
@@ -89,6 +99,10 @@ func (t *Table) collectionStruct(mainGen *codegen.Go, g *Generator) {
 	{
 		mainGen.In()
 		mainGen.Pln(`Data []*`, t.EntityName(), codegen.EncloseBT(`json:"data,omitempty"`))
+		if c := t.optimisticLockColumn; c != nil && t.hasFeature(g, FeatureDBUpdate) {
+			mainGen.Pln(`versionsMu sync.Mutex`)
+			mainGen.Pln(`versions map[*`, t.EntityName(), `]`, g.goTypeNull(c), `// loaded versions for the optimistic lock`)
+		}
 
 		if fn, ok := g.customCode["type_"+t.CollectionName()]; ok {
 			fn(g, t, mainGen)
@@ -116,11 +130,9 @@ func (t *Table) collectionStruct(mainGen *codegen.Go, g *Generator) {
 	mainGen.Pln(`}`)
 }
 
-func (t *Table) entityStruct(mainGen *codegen.Go, g *Generator) {
-	if !g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureEntityStruct) {
-		return
-	}
-
+// fieldMapFunc returns the function which maps the names of the relationship
+// fields.
+func (t *Table) fieldMapFunc(g *Generator) func(string) string {
 	fieldMapFn := g.defaultTableConfig.FieldMapFn
 	if fieldMapFn == nil {
 		fieldMapFn = t.fieldMapFn
@@ -128,6 +140,15 @@ func (t *Table) entityStruct(mainGen *codegen.Go, g *Generator) {
 	if fieldMapFn == nil {
 		fieldMapFn = defaultFieldMapFn
 	}
+	return fieldMapFn
+}
+
+func (t *Table) entityStruct(mainGen *codegen.Go, g *Generator) {
+	if !g.hasFeature(t.featuresInclude, t.featuresExclude, FeatureEntityStruct) {
+		return
+	}
+
+	fieldMapFn := t.fieldMapFunc(g)
 
 	mainGen.C(t.EntityName(), `represents a single row for DB table`, t.Table.Name+`. Auto generated.`)
 	if t.Comment != "" {
@@ -152,7 +173,13 @@ func (t *Table) entityStruct(mainGen *codegen.Go, g *Generator) {
 				if c.StructTag != "" {
 					structTag += "`" + c.StructTag + "`"
 				}
-				mainGen.Pln(t.GoCamelMaybePrivate(c.Field), g.goTypeNull(c), structTag, c.GoComment())
+				goComment := c.GoComment()
+				if c.Comment != "" {
+					mainGen.C(t.GoCamelMaybePrivate(c.Field), c.Comment)
+					// already printed as field documentation
+					goComment = strings.TrimSuffix(goComment, " "+strconv.Quote(c.Comment))
+				}
+				mainGen.Pln(t.GoCamelMaybePrivate(c.Field), g.goTypeNull(c), structTag, goComment)
 			}
 
 			// this part is duplicated in the proto file generation function generateProto.
@@ -418,11 +445,11 @@ func (t *Table) fnEntityDBMapColumns(mainGen *codegen.Go, g *Generator) {
 		mainGen.Pln(`if cm.Mode() == dml.ColumnMapEntityReadAll {`)
 		{
 			mainGen.In()
-			mainGen.P(`return cm`)
+			cm := `cm`
 			t.Table.Columns.Each(func(c *ddl.Column) {
-				mainGen.P(`.`, g.goFuncNull(c), `(&e.`, t.GoCamelMaybePrivate(c.Field), `)`)
+				cm = g.columnMapCall(c, cm, `e.`+t.GoCamelMaybePrivate(c.Field))
 			})
-			mainGen.Pln(`.Err()`)
+			mainGen.Pln(`return`, cm+`.Err()`)
 			mainGen.Out()
 		}
 		mainGen.Pln(`}`)
@@ -438,7 +465,7 @@ func (t *Table) fnEntityDBMapColumns(mainGen *codegen.Go, g *Generator) {
 						mainGen.P(`,`, strconv.Quote(a))
 					}
 					mainGen.Pln(`:`)
-					mainGen.Pln(`cm.`, g.goFuncNull(c), `(&e.`, t.GoCamelMaybePrivate(c.Field), `)`)
+					mainGen.Pln(g.columnMapCall(c, `cm`, `e.`+t.GoCamelMaybePrivate(c.Field)))
 				})
 				mainGen.Pln(`default:`)
 				mainGen.Pln(`return errors.NotFound.Newf("[`+g.Package+`]`, t.EntityName(), `Column %q not found", c)`)
@@ -454,7 +481,13 @@ func (t *Table) fnEntityDBMapColumns(mainGen *codegen.Go, g *Generator) {
 	mainGen.Pln(`}`)
 }
 
+// hasPKAutoInc returns true if the table has a single primary key column with
+// auto increment. Composite and natural keys cannot be assigned from the last
+// insert ID.
 func (t *Table) hasPKAutoInc() bool {
+	if t.Table.Columns.PrimaryKeys().Len() != 1 {
+		return false
+	}
 	var hasPKAutoInc bool
 	t.Table.Columns.Each(func(c *ddl.Column) {
 		if c.IsPK() && c.IsAutoIncrement() {
@@ -820,6 +853,18 @@ func (t *Table) fnCollectionDBMapColumns(mainGen *codegen.Go, g *Generator) {
 			return nil
 		}`)
 
+	if c := t.optimisticLockColumn; c != nil && t.hasFeature(g, FeatureDBUpdate) {
+		mainGen.C(`setVersion remembers the loaded version of an entity for the optimistic lock. Auto generated.`)
+		mainGen.Pln(`func (cc *`, t.CollectionName(), `) setVersion(e *`, t.EntityName(), `, reset bool) {
+			cc.versionsMu.Lock()
+			defer cc.versionsMu.Unlock()
+			if cc.versions == nil || reset {
+				cc.versions = make(map[*`, t.EntityName(), `]`, g.goTypeNull(c), `)
+			}
+			cc.versions[e] = e.`, t.GoCamelMaybePrivate(c.Field), `
+		}`)
+	}
+
 	mainGen.C(`MapColumns implements dml.ColumnMapper interface. Auto generated.`)
 	mainGen.Pln(`func (cc *`, t.CollectionName(), `) MapColumns(cm *dml.ColumnMap) error {`)
 	{
@@ -840,13 +885,16 @@ func (t *Table) fnCollectionDBMapColumns(mainGen *codegen.Go, g *Generator) {
 								return errors.WithStack(err)
 							}
 							cc.Data = append(cc.Data, e)`)
+		mainGen.Pln(t.optimisticLockColumn != nil && t.hasFeature(g, FeatureDBUpdate), `cc.setVersion(e, cm.Count == 0)`)
 
 		mainGen.Pln(`case dml.ColumnMapCollectionReadSet:
 							for cm.Next() {
 								switch c := cm.Column(); c {`)
 
 		t.Table.Columns.UniqueColumns().Each(func(c *ddl.Column) {
-			if !c.IsFloat() {
+			// Custom types with a scanTemplate have no ColumnMap method for
+			// slices.
+			if tm := g.customType(c); !c.IsFloat() && (tm == nil || tm.scan == nil) {
 				mainGen.P(`case`, strconv.Quote(c.Field))
 				for _, a := range c.Aliases {
 					mainGen.P(`,`, strconv.Quote(a))
@@ -981,16 +1029,53 @@ func (t *Table) fnCollectionDBMHandler(mainGen *codegen.Go, g *Generator) {
 
 	mainGen.Pln(dmlEnabled, `if err = dbm.`, entityEventName, `(ctx, dml.EventFlagBeforeDelete, cc, nil); err != nil {
 			return nil, errors.WithStack(err)
-		}
-		if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		}`)
+	if tblPkCols.Len() > 1 { // tuples must be passed row by row
+		mainGen.Pln(dmlEnabled, `args := make([]interface{}, 0, len(cc.Data)*`, tblPkCols.Len(), `)
+		for _, e := range cc.Data {`)
+		tblPkCols.Each(func(c *ddl.Column) {
+			mainGen.Pln(dmlEnabled, `args = append(args, e.`, strs.ToGoCamelCase(c.Field), `)`)
+		})
+		mainGen.Pln(dmlEnabled, `}
+		if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, args...); err != nil {
 			return nil, errors.WithStack(err)
-		}
-		if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterDelete, cc, nil)); err != nil {
+		}`)
+	} else {
+		mainGen.Pln(dmlEnabled, `if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+			return nil, errors.WithStack(err)
+		}`)
+	}
+	mainGen.Pln(dmlEnabled, `if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterDelete, cc, nil)); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return res, nil
 	}`)
 
+	// the struct type for composite primary keys gets only generated with
+	// FeatureDBSelect.
+	dmlEnabled = dmlEnabled && (tblPkCols.Len() == 1 || t.hasFeature(g, FeatureDBSelect))
+	var pkFields strings.Builder
+	tblPkCols.Each(func(c *ddl.Column) {
+		f := strs.ToGoCamelCase(c.Field)
+		if tblPkCols.Len() == 1 {
+			pkFields.WriteString(f + ": pk,")
+		} else {
+			pkFields.WriteString(f + ": pk." + f + ",")
+		}
+	})
+	funcName := codegen.SkipWS(t.CollectionName(), "DeleteByPKs")
+	mainGen.C(dmlEnabled, funcName, `deletes all rows with the primary keys without loading them. Auto generated.`)
+	mainGen.Pln(dmlEnabled, `func (dbm *DBM) `, funcName, `(ctx context.Context, pkIDs ...`, dbLoadStructArgOrSliceName, `) (res sql.Result, err error) {
+		if len(pkIDs) == 0 {
+			return nil, nil
+		}
+		cc := &`, t.CollectionName(), `{Data: make([]*`, t.EntityName(), `, 0, len(pkIDs))}
+		for _, pk := range pkIDs {
+			cc.Data = append(cc.Data, &`, t.EntityName(), `{`, &pkFields, `})
+		}
+		return cc.DBDelete(ctx, dbm)
+	}`)
+
 	dmlEnabled = t.hasFeature(g, FeatureDBUpdate)
 	collectionFuncName = codegen.SkipWS(t.EntityName(), "UpdateByPK")
 	mainGen.Pln(dmlEnabled, `func (cc `, collectionPTRName, `) DBUpdate(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result,err error) {`)
@@ -1002,16 +1087,48 @@ func (t *Table) fnCollectionDBMHandler(mainGen *codegen.Go, g *Generator) {
 
 	mainGen.Pln(dmlEnabled, `if err = dbm.`, entityEventName, `(ctx, dml.EventFlagBeforeUpdate, cc, nil); err != nil {
 			return nil, errors.WithStack(err)
+		}`)
+	if c := t.optimisticLockColumn; c != nil {
+		versionField := t.GoCamelMaybePrivate(c.Field)
+		mainGen.Pln(dmlEnabled, `cc.versionsMu.Lock()
+		defer cc.versionsMu.Unlock()
+		for i, e := range cc.Data {
+			if v, ok := cc.versions[e]; ok && v != e.`, versionField, ` {
+				return nil, errors.Mismatch.Newf("[`+t.Package+`]`, t.CollectionName(), `Entity at index %d has been modified concurrently. Have version %d Want %d", i, e.`, versionField, `, v)
+			}
 		}
-		if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, cc); err != nil {
-			return nil, errors.WithStack(err)
+		if cc.versions == nil {
+			cc.versions = make(map[*`, t.EntityName(), `]`, g.goTypeNull(c), `, len(cc.Data))
 		}
-		if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterUpdate, cc, nil)); err != nil {
+		dbr := dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...)
+		for _, e := range cc.Data {
+			res, err = dbr.ExecContext(ctx, e)
+			if err = dml.ExecValidateOptimisticLock(res, err, 1); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			e.`, versionField, `++
+			cc.versions[e] = e.`, versionField, `
+		}`)
+	} else {
+		mainGen.Pln(dmlEnabled, `if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, collectionFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, cc); err != nil {
+			return nil, errors.WithStack(err)
+		}`)
+	}
+	mainGen.Pln(dmlEnabled, `if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterUpdate, cc, nil)); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return res, nil
 	}`)
 
+	funcName = codegen.SkipWS(t.EntityName(), "UpdateByPK")
+	mainGen.C(dmlEnabled, funcName, `updates all columns of the entities matched by their primary key. Auto generated.`)
+	mainGen.Pln(dmlEnabled, `func (dbm *DBM) `, funcName, `(ctx context.Context, entities ...*`, t.EntityName(), `) (res sql.Result, err error) {
+		if len(entities) == 0 {
+			return nil, nil
+		}
+		return (&`, t.CollectionName(), `{Data: entities}).DBUpdate(ctx, dbm)
+	}`)
+
 	dmlEnabled = t.hasFeature(g, FeatureDBInsert)
 	collectionFuncName = codegen.SkipWS(t.EntityName(), "Insert")
 	mainGen.Pln(dmlEnabled, `func (cc `, collectionPTRName, `) DBInsert(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result,err error) {`)
@@ -1053,6 +1170,66 @@ func (t *Table) fnCollectionDBMHandler(mainGen *codegen.Go, g *Generator) {
 		}
 		return res, nil
 	}`)
+
+	t.fnDBMUpsertByPK(mainGen, g, tblPkCols)
+}
+
+// fnDBMUpsertByPK generates a DBM function to insert or update several
+// entities at once. The auto increment IDs of the entities get saved before the
+// execution because dml.DBR.ExecContext assigns the LastInsertID to all of
+// them.
+func (t *Table) fnDBMUpsertByPK(mainGen *codegen.Go, g *Generator, tblPkCols ddl.Columns) {
+	if !t.hasFeature(g, FeatureDBUpsert) || tblPkCols.Len() == 0 || t.Table.IsView() {
+		return
+	}
+	var autoIncField, autoIncType string
+	if t.hasPKAutoInc() {
+		tblPkCols.Each(func(c *ddl.Column) {
+			autoIncField = t.GoCamelMaybePrivate(c.Field)
+			autoIncType = g.goType(c)
+		})
+	}
+	funcName := codegen.SkipWS(t.EntityName(), "UpsertByPK")
+	entityEventName := codegen.SkipWS(`event`, t.EntityName(), `Func`)
+
+	mainGen.C(funcName, `inserts new entities or updates all non primary key columns of the existing entities with one`,
+		`INSERT ... ON DUPLICATE KEY UPDATE statement. Entities without an auto increment ID get the new IDs assigned.`,
+		`Auto generated.`)
+	mainGen.Pln(`func (dbm *DBM) `, funcName, `(ctx context.Context, entities ...*`, t.EntityName(), `) (res sql.Result, err error) {`)
+	mainGen.Pln(t.hasFeature(g, FeatureDBTracing), `	ctx, span := dbm.option.Trace.Start(ctx, `, codegen.SkipWS(`"`, funcName, `"`), `);
+			defer func(){ cstrace.Status(span, err); span.End(); }()`)
+	mainGen.Pln(`if len(entities) == 0 {
+		return nil, nil
+	}
+	cc := &`, t.CollectionName(), `{Data: entities}
+	if err = dbm.`, entityEventName, `(ctx, dml.EventFlagBeforeUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}`)
+	mainGen.Pln(autoIncField != "", `ids := make([]`, autoIncType, `, len(entities))
+	for i, e := range entities {
+		ids[i] = e.`, autoIncField, `
+	}`)
+	mainGen.Pln(`if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, funcName, `"`), `).ExecContext(ctx, dml.Qualify("", cc)); err != nil {
+		return nil, errors.WithStack(err)
+	}`)
+	mainGen.Pln(autoIncField != "", `lastID, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, e := range entities {
+		switch {
+		case ids[i] != 0:
+			e.`, autoIncField, ` = ids[i]
+		case lastID > 0:
+			e.AssignLastInsertID(lastID)
+			lastID++
+		}
+	}`)
+	mainGen.Pln(`if err = dbm.`, entityEventName, `(ctx, dml.EventFlagAfterUpsert, cc, nil); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}`)
 }
 
 func (t *Table) fnEntityDBMHandler(mainGen *codegen.Go, g *Generator) {
@@ -1064,6 +1241,7 @@ func (t *Table) fnEntityDBMHandler(mainGen *codegen.Go, g *Generator) {
 	var bufPKNameTypes strings.Builder
 	var bufPKNames strings.Builder
 	var bufPKNamesAsArgs strings.Builder
+	var bufPKFields strings.Builder
 	i := 0
 
 	tblCols := t.Table.Columns.PrimaryKeys()
@@ -1087,6 +1265,8 @@ func (t *Table) fnEntityDBMHandler(mainGen *codegen.Go, g *Generator) {
 
 		bufPKNamesAsArgs.WriteString("e.")
 		bufPKNamesAsArgs.WriteString(strs.ToGoCamelCase(c.Field))
+
+		bufPKFields.WriteString(strs.ToGoCamelCase(c.Field) + ": " + goNamedField + ",")
 		i++
 	})
 	if i == 0 {
@@ -1146,6 +1326,12 @@ func (t *Table) fnEntityDBMHandler(mainGen *codegen.Go, g *Generator) {
 		return res, nil
 	}`)
 
+	mainGen.C(dmlEnabled, entityFuncName, `deletes the row with the primary key without loading it. Auto generated.`)
+	mainGen.Pln(dmlEnabled, `func (dbm *DBM) `, entityFuncName, `(ctx context.Context, `, &bufPKNameTypes, `) (res sql.Result, err error) {
+		e := &`, t.EntityName(), `{`, &bufPKFields, `}
+		return e.Delete(ctx, dbm)
+	}`)
+
 	dmlEnabled = t.hasFeature(g, FeatureDBUpdate)
 	entityFuncName = codegen.SkipWS(t.EntityName(), "UpdateByPK")
 	mainGen.Pln(dmlEnabled, `func (e `, entityPTRName, `) Update(ctx context.Context, dbm *DBM, opts ...dml.DBRFunc) (res sql.Result, err error) {`)
@@ -1160,8 +1346,14 @@ func (t *Table) fnEntityDBMHandler(mainGen *codegen.Go, g *Generator) {
 		}
 		if res, err = dbm.CachedQuery(`, codegen.SkipWS(`"`, entityFuncName, `"`), `).ApplyCallBacks(opts...).ExecContext(ctx, e); err != nil {
 			return nil, errors.WithStack(err)
+		}`)
+	if c := t.optimisticLockColumn; c != nil {
+		mainGen.Pln(dmlEnabled, `if err = dml.ExecValidateOptimisticLock(res, nil, 1); err != nil {
+			return nil, errors.WithStack(err)
 		}
-		if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterUpdate, nil, e)); err != nil {
+		e.`, t.GoCamelMaybePrivate(c.Field), `++`)
+	}
+	mainGen.Pln(dmlEnabled, `if err = errors.WithStack(dbm.`, entityEventName, `(ctx, dml.EventFlagAfterUpdate, nil, e)); err != nil {
 			return nil, errors.WithStack(err)
 		}
 		return res, nil
@@ -1228,8 +1420,16 @@ func (t *Table) fnDBMOptionsSQLBuildQueries(mainGen *codegen.Go, g *Generator) {
 	} else {
 		pkWhereIN.WriteString("\ndml.Columns(`" + strings.Join(tblPK.FieldNames(), "`,`") + "`).In().")
 		pkWhereIN.WriteString("Tuples(),\n")
-		pkWhereEQ.WriteString("\ndml.Columns(`" + strings.Join(tblPK.FieldNames(), "`,`") + "`).Equal().")
-		pkWhereEQ.WriteString("Tuples(),\n")
+		// composite keys compare each column to be able to use the entity as
+		// a record in UPDATE statements.
+		pkWhereEQ.WriteByte('\n')
+		for _, fn := range tblPK.FieldNames() {
+			pkWhereEQ.WriteString("dml.Column(`" + fn + "`).Equal().PlaceHolder(),\n")
+		}
+	}
+	pkWhereUpdate := pkWhereIN.String()
+	if tblPKLen > 1 {
+		pkWhereUpdate = pkWhereEQ.String()
 	}
 
 	mainGen.Pln(tblPKLen > 0 && t.hasFeature(g, FeatureDBSelect|FeatureCollectionStruct), `ddl.WithQueryDBR( `,
@@ -1248,18 +1448,31 @@ func (t *Table) fnDBMOptionsSQLBuildQueries(mainGen *codegen.Go, g *Generator) {
 		return
 	}
 
+	var optimisticLock string
+	if c := t.optimisticLockColumn; c != nil {
+		optimisticLock = ".OptimisticLock(`" + c.Field + "`)"
+	}
 	mainGen.Pln(t.hasFeature(g, FeatureDBUpdate|FeatureEntityStruct|FeatureCollectionStruct), `ddl.WithQueryDBR( `,
 		codegen.SkipWS(`"`, t.EntityName(), `UpdateByPK"`),
-		`, dbmo.InitUpdateFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Update().Where(`, pkWhereIN.String(), `)).WithDBR()),`)
+		`, dbmo.InitUpdateFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Update()`, optimisticLock, `.Where(`, pkWhereUpdate, `)).WithDBR()),`)
 	mainGen.Pln(t.hasFeature(g, FeatureDBDelete|FeatureEntityStruct|FeatureCollectionStruct), `ddl.WithQueryDBR( `,
 		codegen.SkipWS(`"`, t.EntityName(), `DeleteByPK"`),
 		`, dbmo.InitDeleteFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Delete().Where(`, pkWhereIN.String(), `)).WithDBR().Interpolate()),`)
 	mainGen.Pln(t.hasFeature(g, FeatureDBInsert|FeatureEntityStruct|FeatureCollectionStruct), `ddl.WithQueryDBR( `,
 		codegen.SkipWS(`"`, t.EntityName(), `Insert"`),
 		`, dbmo.InitInsertFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Insert()).WithDBR()),`)
+	// The auto increment column must be added to match existing rows by their
+	// primary key. The primary key columns never get updated.
+	var upsertPK string
+	if tblPKLen > 0 {
+		if t.hasPKAutoInc() {
+			upsertPK = ".AddColumns(`" + strings.Join(tblPK.FieldNames(), "`,`") + "`)"
+		}
+		upsertPK += ".AddOnDuplicateKeyExclude(`" + strings.Join(tblPK.FieldNames(), "`,`") + "`)"
+	}
 	mainGen.Pln(t.hasFeature(g, FeatureDBUpsert|FeatureEntityStruct|FeatureCollectionStruct), `ddl.WithQueryDBR( `,
 		codegen.SkipWS(`"`, t.EntityName(), `UpsertByPK"`),
-		`, dbmo.InitInsertFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Insert()).OnDuplicateKey().WithDBR()),`)
+		`, dbmo.InitInsertFn(tbls.MustTable(`, codegen.SkipWS(`TableName`, t.EntityName()), `).Insert()`, upsertPK, `).OnDuplicateKey().WithDBR()),`)
 	mainGen.Pln(``)
 }
 
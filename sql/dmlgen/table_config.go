@@ -13,8 +13,19 @@ import (
 type TableConfig struct {
 	// Encoders add method receivers for, each struct, compatible with the
 	// interface declarations in the various encoding packages. Supported
-	// encoder names are: json resp. easyjson.
+	// encoder names are: json resp. easyjson, jsongen, protobuf and fbs.
+	// jsongen writes MarshalJSON and UnmarshalJSON methods for the entity and
+	// the collection without using reflection for the columns, see
+	// JSONFieldNameCase and JSONOmitEmpty. It cannot be combined with easyjson.
 	Encoders []string
+	// JSONFieldNameCase defines the object keys of the jsongen encoder. Either
+	// "snake" (default) which uses the column name or "camel" which uses the
+	// lower camel case Go field name, e.g. entity_id becomes entityID.
+	JSONFieldNameCase string
+	// JSONOmitEmpty defines which fields the jsongen encoder skips. Either
+	// empty (default) to write all fields, "null" to omit NULL values or
+	// "zero" to omit NULL and zero values.
+	JSONOmitEmpty string
 	// StructTags enables struct tags proactively for the whole struct. Allowed
 	// values are: bson, db, env, json, protobuf, toml, yaml and xml. For bson,
 	// json, yaml and xml the omitempty attribute has been set. If you need a
@@ -60,7 +71,24 @@ type TableConfig struct {
 	// table to a new name. dbIdentifier is in most cases the column name and in
 	// cases of foreign keys, it is the table name.
 	FieldMapFn func(dbIdentifier string) (newName string)
-	lastErr    error
+	// PIIColumns marks columns as personally identifiable information. Key is
+	// the column name and value the anonymization strategy:
+	//	- "null" sets the column to NULL, column must be nullable.
+	//	- "hash" replaces a string with its SHA2-256 hash, truncated to the
+	//	  maximum length of the column.
+	//	- "faker:category" replaces the value with fake data of the category,
+	//	  e.g. "faker:email", retrieved via DBMOption.AnonymizeFakerFn.
+	// A table with PII columns gets a generated DBM.Anonymize* function which
+	// also anonymizes the tagged columns of all tables referencing it.
+	PIIColumns map[string]string
+	// OptimisticLockColumn names a non-nullable integer column, e.g. version,
+	// which gets incremented with each generated UPDATE and compared in its
+	// WHERE clause, see dml.Update.OptimisticLock. The generated collection
+	// remembers the loaded version of each entity and returns an error of kind
+	// Mismatch before building the UPDATE, if an entity has been modified
+	// concurrently.
+	OptimisticLockColumn string
+	lastErr              error
 }
 
 func (to *TableConfig) applyEncoders(t *Table, g *Generator) {
@@ -72,12 +100,42 @@ func (to *TableConfig) applyEncoders(t *Table, g *Generator) {
 		switch enc := encoders[i]; enc {
 		case "json", "easyjson":
 			t.HasEasyJSONMarshaler = true
+		case "jsongen":
+			t.HasJSONMarshaler = true
 		case "protobuf", "fbs":
 			t.HasSerializer = true // for now leave it in. maybe later PB gets added to the struct tags.
 		default:
 			to.lastErr = errors.NotSupported.Newf("[dmlgen] WithTableConfig: Table %q Encoder %q not supported", t.Table.Name, enc)
 		}
 	}
+	if to.lastErr == nil && t.HasEasyJSONMarshaler && t.HasJSONMarshaler {
+		to.lastErr = errors.NotAcceptable.Newf("[dmlgen] WithTableConfig: Table %q Encoder easyjson and jsongen cannot be combined", t.Table.Name)
+	}
+}
+
+func (to *TableConfig) applyJSON(t *Table, g *Generator) {
+	if to.lastErr != nil {
+		return
+	}
+	t.jsonFieldNameCase = to.JSONFieldNameCase
+	if t.jsonFieldNameCase == "" {
+		t.jsonFieldNameCase = g.defaultTableConfig.JSONFieldNameCase
+	}
+	t.jsonOmitEmpty = to.JSONOmitEmpty
+	if t.jsonOmitEmpty == "" {
+		t.jsonOmitEmpty = g.defaultTableConfig.JSONOmitEmpty
+	}
+	switch t.jsonFieldNameCase {
+	case "", "snake", "camel":
+	default:
+		to.lastErr = errors.NotSupported.Newf("[dmlgen] WithTableConfig:JSONFieldNameCase: Table %q case %q not supported", t.Table.Name, t.jsonFieldNameCase)
+		return
+	}
+	switch t.jsonOmitEmpty {
+	case "", "null", "zero":
+	default:
+		to.lastErr = errors.NotSupported.Newf("[dmlgen] WithTableConfig:JSONOmitEmpty: Table %q rule %q not supported", t.Table.Name, t.jsonOmitEmpty)
+	}
 }
 
 func (to *TableConfig) applyStructTags(t *Table, g *Generator) {
@@ -228,3 +286,64 @@ func (to *TableConfig) applyUniquifiedColumns(t *Table) {
 		}
 	}
 }
+
+func (to *TableConfig) applyPIIColumns(t *Table) {
+	for colName, strategy := range to.PIIColumns {
+		if to.lastErr != nil {
+			return
+		}
+		c := t.Table.Columns.ByField(colName)
+		if c.Field == "" {
+			to.lastErr = errors.NotFound.Newf("[dmlgen] WithTableConfig:PIIColumns: For table %q the Column %q cannot be found.",
+				t.Table.Name, colName)
+			return
+		}
+		switch {
+		case strategy == "null":
+			if !c.IsNull() {
+				to.lastErr = errors.NotAcceptable.Newf("[dmlgen] WithTableConfig:PIIColumns: For table %q the Column %q is not nullable.",
+					t.Table.Name, colName)
+			}
+		case strategy == "hash":
+			if !c.IsChar() {
+				to.lastErr = errors.NotAcceptable.Newf("[dmlgen] WithTableConfig:PIIColumns: For table %q the Column %q must be a string type to be hashed.",
+					t.Table.Name, colName)
+			}
+		case strings.HasPrefix(strategy, "faker:") && len(strategy) > len("faker:"):
+		default:
+			to.lastErr = errors.NotSupported.Newf("[dmlgen] WithTableConfig:PIIColumns: For table %q the strategy %q of Column %q is not supported.",
+				t.Table.Name, strategy, colName)
+		}
+		if to.lastErr == nil {
+			if t.piiColumns == nil {
+				t.piiColumns = make(map[string]string)
+			}
+			t.piiColumns[colName] = strategy
+		}
+	}
+}
+
+func (to *TableConfig) applyOptimisticLockColumn(t *Table) {
+	if to.lastErr != nil || to.OptimisticLockColumn == "" {
+		return
+	}
+	c := t.Table.Columns.ByField(to.OptimisticLockColumn)
+	if c.Field == "" {
+		to.lastErr = errors.NotFound.Newf("[dmlgen] WithTableConfig:OptimisticLockColumn: For table %q the Column %q cannot be found.",
+			t.Table.Name, to.OptimisticLockColumn)
+		return
+	}
+	switch c.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+	default:
+		to.lastErr = errors.NotAcceptable.Newf("[dmlgen] WithTableConfig:OptimisticLockColumn: For table %q the Column %q must be an integer type.",
+			t.Table.Name, c.Field)
+		return
+	}
+	if c.IsNull() || c.IsPK() {
+		to.lastErr = errors.NotAcceptable.Newf("[dmlgen] WithTableConfig:OptimisticLockColumn: For table %q the Column %q must not be nullable or a primary key.",
+			t.Table.Name, c.Field)
+		return
+	}
+	t.optimisticLockColumn = c
+}
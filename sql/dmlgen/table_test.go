@@ -1,6 +1,16 @@
 package dmlgen
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/ddl"
+	"github.com/corestoreio/pkg/storage/null"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/corestoreio/pkg/util/codegen"
+)
 
 func TestFeatureToggle_String(t *testing.T) {
 	tests := []struct {
@@ -20,3 +30,331 @@ func TestFeatureToggle_String(t *testing.T) {
 		})
 	}
 }
+
+func TestTable_CompositePrimaryKey(t *testing.T) {
+	newTable := func(cols ...*ddl.Column) *Table {
+		return &Table{
+			Package: "testpkg",
+			Table:   ddl.NewTable("catalog_product_website", cols...),
+			featuresInclude: FeatureDB | FeatureDBSelect | FeatureDBUpdate | FeatureDBDelete |
+				FeatureDBAssignLastInsertID | FeatureEntityStruct | FeatureCollectionStruct,
+		}
+	}
+	g := &Generator{}
+	// the generated source has not yet been formatted.
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+
+	t.Run("no LastInsertID for composite keys", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "product_id", Key: "PRI", Extra: "auto_increment", DataType: "int", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "website_id", Key: "PRI", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+		)
+		if tbl.hasPKAutoInc() {
+			t.Error("hasPKAutoInc must return false for a composite primary key")
+		}
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnEntityDBAssignLastInsertID(mainGen, g)
+		if mainGen.Len() > 0 {
+			t.Errorf("AssignLastInsertID must not be generated:\n%s", mainGen.String())
+		}
+	})
+
+	t.Run("LastInsertID for single auto increment key", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "entity_id", Key: "PRI", Extra: "auto_increment", DataType: "int", ColumnType: "int(10) unsigned"},
+		)
+		if !tbl.hasPKAutoInc() {
+			t.Error("hasPKAutoInc must return true for a single auto increment primary key")
+		}
+	})
+
+	t.Run("WHERE clauses", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "product_id", Key: "PRI", DataType: "int", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "website_id", Key: "PRI", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+		)
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnDBMOptionsSQLBuildQueries(mainGen, g)
+		have := stripWS(mainGen.String())
+		for _, want := range []string{
+			"\"CatalogProductWebsitesSelectByPK\", dbmo.InitSelectFn(tbls.MustTable(TableNameCatalogProductWebsite).Select(\"*\")).Where(\ndml.Columns(`product_id`,`website_id`).In().Tuples(),",
+			"\"CatalogProductWebsiteSelectByPK\", dbmo.InitSelectFn(tbls.MustTable(TableNameCatalogProductWebsite).Select(\"*\")).Where(\ndml.Column(`product_id`).Equal().PlaceHolder(),\ndml.Column(`website_id`).Equal().PlaceHolder(),",
+			"\"CatalogProductWebsiteUpdateByPK\", dbmo.InitUpdateFn(tbls.MustTable(TableNameCatalogProductWebsite).Update().Where(\ndml.Column(`product_id`).Equal().PlaceHolder(),\ndml.Column(`website_id`).Equal().PlaceHolder(),",
+			"\"CatalogProductWebsiteDeleteByPK\", dbmo.InitDeleteFn(tbls.MustTable(TableNameCatalogProductWebsite).Delete().Where(\ndml.Columns(`product_id`,`website_id`).In().Tuples(),",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, have)
+			}
+		}
+	})
+
+	t.Run("collection delete passes tuples", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "product_id", Key: "PRI", DataType: "int", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "website_id", Key: "PRI", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+		)
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		have := stripWS(mainGen.String())
+		want := "args = append(args, e.ProductID)\nargs = append(args, e.WebsiteID)\n}\nif res, err = dbm.CachedQuery(\"CatalogProductWebsiteDeleteByPK\").ApplyCallBacks(opts...).ExecContext(ctx, args...); err != nil {"
+		if !strings.Contains(have, stripWS(want)) {
+			t.Errorf("missing:\n%s\nin:\n%s", want, have)
+		}
+	})
+}
+
+func TestTable_OptimisticLock(t *testing.T) {
+	newTable := func() *Table {
+		return &Table{
+			Package: "testpkg",
+			Table: ddl.NewTable("customer_entity",
+				&ddl.Column{Field: "entity_id", Key: "PRI", DataType: "int", ColumnType: "int(10) unsigned"},
+				&ddl.Column{Field: "email", Null: "YES", DataType: "varchar", ColumnType: "varchar(255)"},
+				&ddl.Column{Field: "version", DataType: "int", ColumnType: "int(10) unsigned"},
+			),
+			featuresInclude: FeatureDB | FeatureDBSelect | FeatureDBUpdate | FeatureEntityStruct | FeatureCollectionStruct,
+		}
+	}
+	g := &Generator{}
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+
+	t.Run("invalid column", func(t *testing.T) {
+		for _, col := range []string{"email", "entity_id", "xversion"} {
+			to := &TableConfig{OptimisticLockColumn: col}
+			tbl := newTable()
+			to.applyOptimisticLockColumn(tbl)
+			if to.lastErr == nil || tbl.optimisticLockColumn != nil {
+				t.Errorf("column %q must not be accepted", col)
+			}
+		}
+	})
+
+	t.Run("generated code", func(t *testing.T) {
+		to := &TableConfig{OptimisticLockColumn: "version"}
+		tbl := newTable()
+		to.applyOptimisticLockColumn(tbl)
+		if to.lastErr != nil {
+			t.Fatalf("%+v", to.lastErr)
+		}
+		mainGen := codegen.NewGo("testpkg")
+		tbl.collectionStruct(mainGen, g)
+		tbl.fnCollectionDBMapColumns(mainGen, g)
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		tbl.fnEntityDBMHandler(mainGen, g)
+		tbl.fnDBMOptionsSQLBuildQueries(mainGen, g)
+		have := stripWS(mainGen.String())
+		for _, want := range []string{
+			"versions map[*CustomerEntity]uint32",
+			"cc.setVersion(e, cm.Count == 0)",
+			"if v, ok := cc.versions[e]; ok && v != e.Version {",
+			"res, err = dbr.ExecContext(ctx, e)\nif err = dml.ExecValidateOptimisticLock(res, err, 1); err != nil {",
+			"e.Version++\ncc.versions[e] = e.Version",
+			"if err = dml.ExecValidateOptimisticLock(res, nil, 1); err != nil {\nreturn nil, errors.WithStack(err)\n}\ne.Version++",
+			"\"CustomerEntityUpdateByPK\", dbmo.InitUpdateFn(tbls.MustTable(TableNameCustomerEntity).Update().OptimisticLock(`version`).Where(",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, mainGen.String())
+			}
+		}
+	})
+}
+
+func TestTable_UpsertByPK(t *testing.T) {
+	g := &Generator{}
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+
+	t.Run("auto increment", func(t *testing.T) {
+		tbl := &Table{
+			Package: "testpkg",
+			Table: ddl.NewTable("customer_entity",
+				&ddl.Column{Field: "entity_id", Key: "PRI", Extra: "auto_increment", DataType: "int", ColumnType: "int(10) unsigned"},
+				&ddl.Column{Field: "email", Null: "YES", DataType: "varchar", ColumnType: "varchar(255)"},
+			),
+			featuresInclude: FeatureDB | FeatureDBUpsert | FeatureEntityStruct | FeatureCollectionStruct,
+		}
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		tbl.fnDBMOptionsSQLBuildQueries(mainGen, g)
+		have := stripWS(mainGen.String())
+		for _, want := range []string{
+			"func (dbm *DBM) CustomerEntityUpsertByPK(ctx context.Context, entities ...*CustomerEntity) (res sql.Result, err error) {",
+			"ids := make([]uint32, len(entities))\nfor i, e := range entities {\nids[i] = e.EntityID\n}",
+			"case ids[i] != 0:\ne.EntityID = ids[i]\ncase lastID > 0:\ne.AssignLastInsertID(lastID)\nlastID++",
+			"\"CustomerEntityUpsertByPK\", dbmo.InitInsertFn(tbls.MustTable(TableNameCustomerEntity).Insert().AddColumns(`entity_id`).AddOnDuplicateKeyExclude(`entity_id`)).OnDuplicateKey().WithDBR()),",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, mainGen.String())
+			}
+		}
+	})
+
+	t.Run("composite key", func(t *testing.T) {
+		tbl := &Table{
+			Package: "testpkg",
+			Table: ddl.NewTable("catalog_product_website",
+				&ddl.Column{Field: "product_id", Key: "PRI", DataType: "int", ColumnType: "int(10) unsigned"},
+				&ddl.Column{Field: "website_id", Key: "PRI", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+				&ddl.Column{Field: "position", DataType: "int", ColumnType: "int(10) unsigned"},
+			),
+			featuresInclude: FeatureDB | FeatureDBUpsert | FeatureEntityStruct | FeatureCollectionStruct,
+		}
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		tbl.fnDBMOptionsSQLBuildQueries(mainGen, g)
+		have := mainGen.String()
+		if want := stripWS("func (dbm *DBM) CatalogProductWebsiteUpsertByPK("); !strings.Contains(stripWS(have), want) {
+			t.Errorf("missing:\n%s\nin:\n%s", want, have)
+		}
+		if strings.Contains(have, "LastInsertId") {
+			t.Errorf("LastInsertID must not be assigned:\n%s", have)
+		}
+		if want := stripWS(".Insert().AddOnDuplicateKeyExclude(`product_id`,`website_id`)).OnDuplicateKey()"); !strings.Contains(stripWS(have), want) {
+			t.Errorf("missing:\n%s\nin:\n%s", want, have)
+		}
+	})
+}
+
+func TestTable_DeleteUpdateByPK(t *testing.T) {
+	g := &Generator{}
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+	newTable := func(cols ...*ddl.Column) *Table {
+		return &Table{
+			Package: "testpkg",
+			Table:   ddl.NewTable("catalog_product_website", cols...),
+			featuresInclude: FeatureDB | FeatureDBSelect | FeatureDBDelete | FeatureDBUpdate |
+				FeatureEntityStruct | FeatureCollectionStruct,
+		}
+	}
+
+	t.Run("single key", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "entity_id", Key: "PRI", Extra: "auto_increment", DataType: "int", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "position", DataType: "int", ColumnType: "int(10) unsigned"},
+		)
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnEntityDBMHandler(mainGen, g)
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		have := stripWS(mainGen.String())
+		for _, want := range []string{
+			"func (dbm *DBM) CatalogProductWebsiteDeleteByPK(ctx context.Context, entityID uint32) (res sql.Result, err error) {\ne := &CatalogProductWebsite{EntityID: entityID,}\nreturn e.Delete(ctx, dbm)\n}",
+			"func (dbm *DBM) CatalogProductWebsitesDeleteByPKs(ctx context.Context, pkIDs ...uint32) (res sql.Result, err error) {",
+			"cc.Data = append(cc.Data, &CatalogProductWebsite{EntityID: pk,})\n}\nreturn cc.DBDelete(ctx, dbm)",
+			"func (dbm *DBM) CatalogProductWebsiteUpdateByPK(ctx context.Context, entities ...*CatalogProductWebsite) (res sql.Result, err error) {",
+			"return (&CatalogProductWebsites{Data: entities}).DBUpdate(ctx, dbm)",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, mainGen.String())
+			}
+		}
+	})
+
+	t.Run("composite key", func(t *testing.T) {
+		tbl := newTable(
+			&ddl.Column{Field: "product_id", Key: "PRI", DataType: "int", ColumnType: "int(10) unsigned"},
+			&ddl.Column{Field: "website_id", Key: "PRI", DataType: "smallint", ColumnType: "smallint(5) unsigned"},
+		)
+		mainGen := codegen.NewGo("testpkg")
+		tbl.fnEntityDBMHandler(mainGen, g)
+		tbl.fnCollectionDBMHandler(mainGen, g)
+		have := stripWS(mainGen.String())
+		for _, want := range []string{
+			"func (dbm *DBM) CatalogProductWebsiteDeleteByPK(ctx context.Context, productID uint32,websiteID uint16) (res sql.Result, err error) {\ne := &CatalogProductWebsite{ProductID: productID,WebsiteID: websiteID,}",
+			"func (dbm *DBM) CatalogProductWebsitesDeleteByPKs(ctx context.Context, pkIDs ...CatalogProductWebsitesDBLoadArgs) (res sql.Result, err error) {",
+			"&CatalogProductWebsite{ProductID: pk.ProductID,WebsiteID: pk.WebsiteID,}",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, mainGen.String())
+			}
+		}
+	})
+}
+
+func TestTable_JSONGen(t *testing.T) {
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+	newGen := func(tc *TableConfig) (*Generator, error) {
+		tc.FeaturesInclude = FeatureEntityStruct | FeatureCollectionStruct
+		return NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+			WithTable("customer_entity", ddl.Columns{
+				&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+				&ddl.Column{Field: "email", Pos: 2, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+				&ddl.Column{Field: "firstname", Pos: 3, Null: "NO", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+			}),
+			WithTableConfig("customer_entity", tc),
+		)
+	}
+
+	t.Run("snake case all fields", func(t *testing.T) {
+		g, err := newGen(&TableConfig{Encoders: []string{"jsongen"}})
+		assert.NoError(t, err)
+		var wMain, wTest bytes.Buffer
+		assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+		have := stripWS(wMain.String())
+		for _, want := range []string{
+			"func (e *CustomerEntity) appendJSON(buf []byte) ([]byte, error) {",
+			"buf = append(buf, `\"entity_id\":`...)\nbuf = strconv.AppendUint(buf, uint64(e.EntityID), 10)\nbuf = append(buf, ',')",
+			"buf = append(buf, `\"email\":`...)\nif e.Email.Valid {\nbuf = byteconv.AppendJSONString(buf, e.Email.Data)\n} else {\nbuf = append(buf, \"null\"...)\n}",
+			"case \"email\":\nif string(v) == \"null\" {\ne.Email = null.String{}\n} else {\ne.Email.Valid = true\nerr = json.Unmarshal(v, &e.Email.Data)\n}",
+			"func (cc *CustomerEntities) MarshalJSON() (_ []byte, err error) {",
+			"if buf, err = e.appendJSON(buf); err != nil {",
+			"func (cc *CustomerEntities) UnmarshalJSON(data []byte) error {",
+			`"github.com/corestoreio/pkg/util/byteconv"`,
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, wMain.String())
+			}
+		}
+		assert.NotContains(t, wMain.String(), "//easyjson:json")
+	})
+
+	t.Run("camel case omit zero", func(t *testing.T) {
+		g, err := newGen(&TableConfig{Encoders: []string{"jsongen"}, JSONFieldNameCase: "camel", JSONOmitEmpty: "zero"})
+		assert.NoError(t, err)
+		var wMain, wTest bytes.Buffer
+		assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+		have := stripWS(wMain.String())
+		for _, want := range []string{
+			"if e.EntityID != 0 {\nbuf = append(buf, `\"entityID\":`...)",
+			"if e.Email.Valid && e.Email.Data != \"\" {\nbuf = append(buf, `\"email\":`...)",
+			"if e.Firstname != \"\" {",
+			"case \"entityID\":\nerr = json.Unmarshal(v, &e.EntityID)",
+		} {
+			if !strings.Contains(have, stripWS(want)) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, wMain.String())
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newGen(&TableConfig{Encoders: []string{"jsongen", "easyjson"}})
+		assert.ErrorIsKind(t, errors.NotAcceptable, err)
+		_, err = newGen(&TableConfig{Encoders: []string{"jsongen"}, JSONFieldNameCase: "kebab"})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+		_, err = newGen(&TableConfig{Encoders: []string{"jsongen"}, JSONOmitEmpty: "always"})
+		assert.ErrorIsKind(t, errors.NotSupported, err)
+	})
+}
+
+func TestTable_EntityStructColumnComment(t *testing.T) {
+	g, err := NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+		WithTable("customer_entity", ddl.Columns{
+			&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment", Comment: "Entity ID"},
+			&ddl.Column{Field: "email", Pos: 2, Null: "YES", DataType: "varchar", CharMaxLength: null.MakeInt64(255), ColumnType: "varchar(255)"},
+		}),
+		WithTableConfig("customer_entity", &TableConfig{FeaturesInclude: FeatureEntityStruct}),
+	)
+	assert.NoError(t, err)
+	var wMain, wTest bytes.Buffer
+	assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+	stripWS := func(s string) string { return strings.Join(strings.Fields(s), "") }
+	have := stripWS(wMain.String())
+
+	assert.Exactly(t, 1, strings.Count(wMain.String(), "Entity ID"), "column comment must be printed once:\n%s", wMain.String())
+	for _, want := range []string{
+		"// EntityID Entity ID\nEntityID uint32 // entity_id int(10) unsigned NOT NULL PRI auto_increment\n",
+		"Email null.String // email varchar(255) NULL \"\"\n",
+	} {
+		if !strings.Contains(have, stripWS(want)) {
+			t.Errorf("missing:\n%s\nin:\n%s", want, wMain.String())
+		}
+	}
+}
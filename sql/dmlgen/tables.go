@@ -18,6 +18,24 @@ func (ts tables) hasFeature(g *Generator, feature FeatureToggle) bool {
 	return false
 }
 
+func (ts tables) hasPIIColumns() bool {
+	for _, tbl := range ts {
+		if len(tbl.piiColumns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts tables) hasRelationLoaders(g *Generator) bool {
+	for _, tbl := range ts {
+		if len(tbl.loadableRelations(g)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (ts tables) names() []string {
 	names := make([]string, len(ts))
 	for i, tbl := range ts {
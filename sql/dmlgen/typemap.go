@@ -16,7 +16,9 @@ package dmlgen
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
+	"text/template"
 	"unicode"
 	"unicode/utf8"
 
@@ -409,14 +411,76 @@ func (g *Generator) findType(c *ddl.Column) *TypeDef {
 	return goType
 }
 
+// typeMapping contains a custom Go type for a MySQL type, see function
+// WithTypeMapping.
+type typeMapping struct {
+	goType string
+	scan   *template.Template // nil uses the default ColumnMap method
+}
+
+func newTypeMapping(goType, scanTemplate string) (*typeMapping, error) {
+	if goType == "" {
+		return nil, errors.Empty.Newf("[dmlgen] WithTypeMapping: goType cannot be empty")
+	}
+	tm := &typeMapping{goType: goType}
+	if scanTemplate == "" {
+		return tm, nil
+	}
+	tpl, err := template.New(goType).Parse(scanTemplate)
+	if err != nil {
+		return nil, errors.NotValid.New(err, "[dmlgen] WithTypeMapping: scanTemplate %q for type %q", scanTemplate, goType)
+	}
+	// Executing once detects unknown variables before any code gets written.
+	if err := tpl.Execute(ioutil.Discard, columnMapTplData{CM: "cm", Field: "e.Field"}); err != nil {
+		return nil, errors.NotValid.New(err, "[dmlgen] WithTypeMapping: scanTemplate %q for type %q", scanTemplate, goType)
+	}
+	tm.scan = tpl
+	return tm, nil
+}
+
+// columnMapTplData gets passed to the scanTemplate of a typeMapping.
+type columnMapTplData struct {
+	CM    string // Go expression of type *dml.ColumnMap
+	Field string // Go expression of the struct field
+}
+
+// customType returns the custom type mapping of a column or nil.
+func (g *Generator) customType(c *ddl.Column) *typeMapping {
+	if len(g.typeMappings) == 0 {
+		return nil
+	}
+	if tm, ok := g.typeMappings[strings.ToLower(c.ColumnType)]; ok {
+		return tm
+	}
+	return g.typeMappings[strings.ToLower(c.DataType)]
+}
+
 func (g *Generator) goTypeNull(c *ddl.Column) string { return g.mySQLToGoType(c, true) }
 func (g *Generator) goType(c *ddl.Column) string     { return g.mySQLToGoType(c, false) }
 func (g *Generator) goFuncNull(c *ddl.Column) string { return g.mySQLToGoDmlColumnMap(c, true) }
 
+// columnMapCall returns the Go expression which maps the struct field of a
+// column with the ColumnMap in cm, e.g. cm.NullString(&e.Email).
+func (g *Generator) columnMapCall(c *ddl.Column, cm, field string) string {
+	tm := g.customType(c)
+	if tm == nil || tm.scan == nil {
+		return cm + `.` + g.goFuncNull(c) + `(&` + field + `)`
+	}
+	var buf strings.Builder
+	if err := tm.scan.Execute(&buf, columnMapTplData{CM: cm, Field: field}); err != nil {
+		panic(errors.Fatal.New(err, "[dmlgen] Failed to execute the scanTemplate of column %q", c.Field)) // already checked in newTypeMapping
+	}
+	return buf.String()
+}
+
 // mySQLToGoType calculates the data type of the field DataType. For example
 // bigint, smallint, tinyint will result in "int". If withNull is true the
-// returned type can store a null value.
+// returned type can store a null value. A custom type of WithTypeMapping
+// gets returned for NULL and NOT NULL columns.
 func (g *Generator) mySQLToGoType(c *ddl.Column, withNull bool) string {
+	if tm := g.customType(c); tm != nil {
+		return tm.goType
+	}
 	goType := g.findType(c)
 
 	var t string
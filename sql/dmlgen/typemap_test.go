@@ -15,8 +15,11 @@
 package dmlgen
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/sql/ddl"
 	"github.com/corestoreio/pkg/storage/null"
 	"github.com/corestoreio/pkg/util/assert"
@@ -186,3 +189,57 @@ func TestToGoPrimitive(t *testing.T) {
 		assert.Exactly(t, test.want, have, "IDX:%d %#v", i, test.c)
 	}
 }
+
+func TestWithTypeMapping(t *testing.T) {
+	t.Parallel()
+	newGen := func(opts ...Option) (*Generator, error) {
+		return NewGenerator("github.com/corestoreio/pkg/sql/dmlgen/dmltestgenerated",
+			append(opts,
+				WithTable("catalog_product", ddl.Columns{
+					&ddl.Column{Field: "entity_id", Pos: 1, Null: "NO", DataType: "int", Key: "PRI", ColumnType: "int(10) unsigned", Extra: "auto_increment"},
+					&ddl.Column{Field: "price", Pos: 2, Null: "YES", DataType: "decimal", Precision: null.MakeInt64(12), Scale: null.MakeInt64(4), ColumnType: "decimal(12,4)"},
+					&ddl.Column{Field: "is_active", Pos: 3, Null: "NO", DataType: "tinyint", ColumnType: "tinyint(1)"},
+					&ddl.Column{Field: "weight", Pos: 4, Null: "NO", DataType: "tinyint", ColumnType: "tinyint(3)"},
+				}),
+				WithTableConfig("catalog_product", &TableConfig{FeaturesInclude: FeatureEntityStruct | FeatureDBMapColumns}),
+			)...,
+		)
+	}
+
+	t.Run("custom types", func(t *testing.T) {
+		g, err := newGen(
+			WithTypeMapping("DECIMAL", "money.Money", "github.com/acme/money", "{{.CM}}.Decimal((*null.Decimal)(&{{.Field}}))"),
+			WithTypeMapping("tinyint(1)", "bool", "", ""),
+		)
+		assert.NoError(t, err)
+
+		assert.Exactly(t, "money.Money", g.goTypeNull(g.Tables["catalog_product"].Table.Columns.ByField("price")))
+		assert.Exactly(t, "bool", g.goType(g.Tables["catalog_product"].Table.Columns.ByField("is_active")))
+		assert.Exactly(t, "int8", g.goType(g.Tables["catalog_product"].Table.Columns.ByField("weight")))
+
+		var wMain, wTest bytes.Buffer
+		assert.NoError(t, g.GenerateGo(&wMain, &wTest))
+		have := wMain.String()
+		for _, want := range []string{
+			`"github.com/acme/money"`,
+			"Price    money.Money",
+			"IsActive bool",
+			"return cm.Uint32(&e.EntityID).Decimal((*null.Decimal)(&e.Price)).Bool(&e.IsActive).Int8(&e.Weight).Err()",
+			"case \"price\":\n\t\t\tcm.Decimal((*null.Decimal)(&e.Price))",
+			"case \"is_active\":\n\t\t\tcm.Bool(&e.IsActive)",
+		} {
+			if !strings.Contains(have, want) {
+				t.Errorf("missing:\n%s\nin:\n%s", want, have)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := newGen(WithTypeMapping("decimal", "", "", ""))
+		assert.ErrorIsKind(t, errors.Empty, err)
+		_, err = newGen(WithTypeMapping("decimal", "money.Money", "", "{{.CM"))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		_, err = newGen(WithTypeMapping("decimal", "money.Money", "", "{{.Column}}"))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+	})
+}
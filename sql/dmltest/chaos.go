@@ -0,0 +1,301 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+)
+
+// ErrChaosDroppedResultSet gets returned by the rows of a query when the
+// ChaosDriver drops the result set.
+var ErrChaosDroppedResultSet = errors.ConnectionLost.Newf("[dmltest] ChaosDriver dropped the result set")
+
+// ChaosOptions configures the faults of a ChaosDriver. All faults are counter
+// based and hence deterministic. A zero value disables a fault. The options
+// must not be changed while the driver is in use.
+type ChaosOptions struct {
+	// ErrBadConnOnExec returns driver.ErrBadConn on every Nth exec, including
+	// executions of prepared statements. database/sql retries the exec on a
+	// new connection.
+	ErrBadConnOnExec uint64
+	// ErrBadConnOnQuery returns driver.ErrBadConn on every Nth query,
+	// including queries of prepared statements.
+	ErrBadConnOnQuery uint64
+	// LatencyOnCall delays every Nth exec or query by the duration of field
+	// Latency. The delay respects the cancellation of the context.
+	LatencyOnCall uint64
+	Latency       time.Duration
+	// DropResultSetOnQuery lets every Nth query return rows whose first call to
+	// Next fails with ErrChaosDroppedResultSet.
+	DropResultSetOnQuery uint64
+}
+
+// ChaosStats contains the counters of a ChaosDriver.
+type ChaosStats struct {
+	Execs             uint64
+	Queries           uint64
+	BadConns          uint64
+	Latencies         uint64
+	DroppedResultSets uint64
+}
+
+// ChaosDriver wraps a real driver and injects configurable faults to test
+// retry and reconnect logic of DBR and ConnPool deterministically. Safe for
+// concurrent use.
+type ChaosDriver struct {
+	Driver driver.Driver
+	ChaosOptions
+	stats ChaosStats
+}
+
+// NewChaosDriver creates a new fault injecting driver for the wrapped driver.
+func NewChaosDriver(drv driver.Driver, o ChaosOptions) *ChaosDriver {
+	return &ChaosDriver{
+		Driver:       drv,
+		ChaosOptions: o,
+	}
+}
+
+// Open implements driver.Driver.
+func (cd *ChaosDriver) Open(name string) (driver.Conn, error) {
+	conn, err := cd.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &chaosConn{Conn: conn, cd: cd}, nil
+}
+
+// Connector creates a connector for function sql.OpenDB which opens
+// connections with the DSN.
+func (cd *ChaosDriver) Connector(dsn string) driver.Connector {
+	return chaosConnector{dsn: dsn, cd: cd}
+}
+
+// Stats returns a snapshot of the current counters.
+func (cd *ChaosDriver) Stats() ChaosStats {
+	return ChaosStats{
+		Execs:             atomic.LoadUint64(&cd.stats.Execs),
+		Queries:           atomic.LoadUint64(&cd.stats.Queries),
+		BadConns:          atomic.LoadUint64(&cd.stats.BadConns),
+		Latencies:         atomic.LoadUint64(&cd.stats.Latencies),
+		DroppedResultSets: atomic.LoadUint64(&cd.stats.DroppedResultSets),
+	}
+}
+
+// Reset sets all counters to zero.
+func (cd *ChaosDriver) Reset() {
+	atomic.StoreUint64(&cd.stats.Execs, 0)
+	atomic.StoreUint64(&cd.stats.Queries, 0)
+	atomic.StoreUint64(&cd.stats.BadConns, 0)
+	atomic.StoreUint64(&cd.stats.Latencies, 0)
+	atomic.StoreUint64(&cd.stats.DroppedResultSets, 0)
+}
+
+func isNth(n, every uint64) bool {
+	return every > 0 && n%every == 0
+}
+
+func (cd *ChaosDriver) latency(ctx context.Context) error {
+	if !isNth(atomic.LoadUint64(&cd.stats.Execs)+atomic.LoadUint64(&cd.stats.Queries), cd.LatencyOnCall) {
+		return nil
+	}
+	atomic.AddUint64(&cd.stats.Latencies, 1)
+	t := time.NewTimer(cd.Latency)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (cd *ChaosDriver) beforeExec(ctx context.Context) error {
+	n := atomic.AddUint64(&cd.stats.Execs, 1)
+	if isNth(n, cd.ErrBadConnOnExec) {
+		atomic.AddUint64(&cd.stats.BadConns, 1)
+		return driver.ErrBadConn
+	}
+	return cd.latency(ctx)
+}
+
+func (cd *ChaosDriver) beforeQuery(ctx context.Context) (dropResultSet bool, _ error) {
+	n := atomic.AddUint64(&cd.stats.Queries, 1)
+	if isNth(n, cd.ErrBadConnOnQuery) {
+		atomic.AddUint64(&cd.stats.BadConns, 1)
+		return false, driver.ErrBadConn
+	}
+	if isNth(n, cd.DropResultSetOnQuery) {
+		atomic.AddUint64(&cd.stats.DroppedResultSets, 1)
+		dropResultSet = true
+	}
+	return dropResultSet, cd.latency(ctx)
+}
+
+type chaosConnector struct {
+	dsn string
+	cd  *ChaosDriver
+}
+
+func (c chaosConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.cd.Open(c.dsn)
+}
+
+func (c chaosConnector) Driver() driver.Driver {
+	return c.cd
+}
+
+type chaosConn struct {
+	driver.Conn
+	cd *ChaosDriver
+}
+
+func (c *chaosConn) PrepareContext(ctx context.Context, query string) (stmt driver.Stmt, err error) {
+	if cpc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = cpc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chaosStmt{Stmt: stmt, cd: c.cd}, nil
+}
+
+func (c *chaosConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *chaosConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql falls back to PrepareContext
+	}
+	if err := c.cd.beforeExec(ctx); err != nil {
+		return nil, err
+	}
+	return ec.ExecContext(ctx, query, args)
+}
+
+func (c *chaosConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip // database/sql falls back to PrepareContext
+	}
+	drop, err := c.cd.beforeQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &chaosRows{Rows: rows, drop: drop}, nil
+}
+
+func (c *chaosConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cbt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return cbt.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *chaosConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+type chaosStmt struct {
+	driver.Stmt
+	cd *ChaosDriver
+}
+
+func (s *chaosStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.cd.beforeExec(ctx); err != nil {
+		return nil, err
+	}
+	if sec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return sec.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(namedValueToValue(args))
+}
+
+func (s *chaosStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	drop, err := s.cd.beforeQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var rows driver.Rows
+	if sqc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = sqc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValueToValue(args))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &chaosRows{Rows: rows, drop: drop}, nil
+}
+
+func namedValueToValue(named []driver.NamedValue) []driver.Value {
+	args := make([]driver.Value, len(named))
+	for i, n := range named {
+		args[i] = n.Value
+	}
+	return args
+}
+
+type chaosRows struct {
+	driver.Rows
+	drop bool
+}
+
+func (r *chaosRows) Next(dest []driver.Value) error {
+	if r.drop {
+		return ErrChaosDroppedResultSet
+	}
+	return r.Rows.Next(dest)
+}
+
+var chaosDSNCounter uint64
+
+// MockChaosDB creates a mocked database connection pool whose driver gets
+// wrapped by a ChaosDriver. The returned ChaosDriver allows to inspect the
+// counters.
+func MockChaosDB(t testing.TB, co ChaosOptions, opts ...dml.ConnPoolOption) (*dml.ConnPool, sqlmock.Sqlmock, *ChaosDriver) {
+	if t != nil { // t can be nil in Example functions
+		t.Helper()
+	}
+	dsn := "dmltest_chaos_" + strconv.FormatUint(atomic.AddUint64(&chaosDSNCounter, 1), 10)
+	mockDB, sm, err := sqlmock.NewWithDSN(dsn)
+	FatalIfError(t, err)
+	cd := NewChaosDriver(mockDB.Driver(), co)
+	cfg := []dml.ConnPoolOption{dml.WithDB(sql.OpenDB(cd.Connector(dsn)))}
+	dbc, err := dml.NewConnPool(append(cfg, opts...)...)
+	FatalIfError(t, err)
+	return dbc, sm, cd
+}
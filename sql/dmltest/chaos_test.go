@@ -0,0 +1,89 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmltest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/sql/dmltest"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestChaosDriver(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("ErrBadConn on exec gets retried", func(t *testing.T) {
+		dbc, dbMock, cd := dmltest.MockChaosDB(t, dmltest.ChaosOptions{ErrBadConnOnExec: 2})
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		// the second exec fails and database/sql retries it
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_order`")).WillReturnResult(sqlmock.NewResult(0, 3))
+		dbMock.ExpectExec(dmltest.SQLMockQuoteMeta("DELETE FROM `sales_invoice`")).WillReturnResult(sqlmock.NewResult(0, 4))
+
+		_, err := dbc.DeleteFrom("sales_order").WithDBR().ExecContext(ctx)
+		assert.NoError(t, err)
+		_, err = dbc.DeleteFrom("sales_invoice").WithDBR().ExecContext(ctx)
+		assert.NoError(t, err)
+
+		assert.Exactly(t, dmltest.ChaosStats{Execs: 3, BadConns: 1}, cd.Stats())
+	})
+
+	t.Run("dropped result set", func(t *testing.T) {
+		dbc, dbMock, cd := dmltest.MockChaosDB(t, dmltest.ChaosOptions{DropResultSetOnQuery: 1})
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectQuery(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order`")).
+			WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(1).AddRow(2))
+
+		ids, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").WithDBR().LoadInt64s(ctx, nil)
+		assert.True(t, errors.ConnectionLost.Match(err), "%+v", err)
+		assert.Nil(t, ids)
+		assert.Exactly(t, dmltest.ChaosStats{Queries: 1, DroppedResultSets: 1}, cd.Stats())
+	})
+
+	t.Run("latency respects context", func(t *testing.T) {
+		dbc, dbMock, cd := dmltest.MockChaosDB(t, dmltest.ChaosOptions{LatencyOnCall: 1, Latency: time.Second})
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := dbc.WithRawSQL("SELECT 1").QueryContext(ctx)
+		assert.Error(t, err)
+		assert.Exactly(t, dmltest.ChaosStats{Queries: 1, Latencies: 1}, cd.Stats())
+
+		cd.Reset()
+		assert.Exactly(t, dmltest.ChaosStats{}, cd.Stats())
+	})
+
+	t.Run("prepared statement", func(t *testing.T) {
+		dbc, dbMock, cd := dmltest.MockChaosDB(t, dmltest.ChaosOptions{ErrBadConnOnQuery: 2})
+		defer dmltest.MockClose(t, dbc, dbMock)
+
+		dbMock.ExpectPrepare(dmltest.SQLMockQuoteMeta("SELECT `entity_id` FROM `sales_order` WHERE (`state` = ?)")).
+			ExpectQuery().WithArgs("new").WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(5))
+
+		stmt, err := dbc.SelectFrom("sales_order").AddColumns("entity_id").Where(dml.Column("state").PlaceHolder()).Prepare(ctx)
+		assert.NoError(t, err)
+		ids, err := stmt.WithDBR().LoadInt64s(ctx, nil, "new")
+		assert.NoError(t, err)
+		assert.Exactly(t, []int64{5}, ids)
+		assert.Exactly(t, uint64(1), cd.Stats().Queries)
+	})
+}
@@ -16,7 +16,9 @@ package dmltest
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"io"
+	"math"
 	"os"
 	"testing"
 
@@ -38,12 +40,16 @@ func getDSN(env string) (string, error) {
 }
 
 // MustGetDSN returns the data source name from an environment variable or
-// panics on error.
+// panics on error. If the environment variable contains DSNEmbedded, the
+// embedded engine gets started and its DSN returned.
 func MustGetDSN(t testing.TB) string {
 	d, err := getDSN(EnvDSN)
 	if err != nil {
 		t.Skip(color.MagentaString("%s", err))
 	}
+	if d == DSNEmbedded {
+		return embeddedDSN(t)
+	}
 	return d
 }
 
@@ -74,7 +80,7 @@ func MockDB(t testing.TB, opts ...dml.ConnPoolOption) (*dml.ConnPool, sqlmock.Sq
 	if t != nil { // t can be nil in Example functions
 		t.Helper()
 	}
-	db, sm, err := sqlmock.New()
+	db, sm, err := sqlmock.New(sqlmock.ValueConverterOption(mockValueConverter{}))
 	FatalIfError(t, err)
 	cfg := []dml.ConnPoolOption{dml.WithDB(db)}
 	dbc, err := dml.NewConnPool(append(cfg, opts...)...)
@@ -82,6 +88,17 @@ func MockDB(t testing.TB, opts ...dml.ConnPoolOption) (*dml.ConnPool, sqlmock.Sq
 	return dbc, sm
 }
 
+// mockValueConverter accepts uint64 values with the high bit set like the
+// MySQL driver does.
+type mockValueConverter struct{}
+
+func (mockValueConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if u, ok := v.(uint64); ok && u > math.MaxInt64 {
+		return u, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
 // MockClose for usage in conjunction with defer.
 // 		defer dmltest.MockClose(t, db, dbMock)
 func MockClose(t testing.TB, c io.Closer, m sqlmock.Sqlmock) {
@@ -103,3 +103,15 @@ func TestCheckLastInsertID(t *testing.T) {
 		assert.Exactly(t, int64(0), lid)
 	})
 }
+
+func TestSkipUnsupported(t *testing.T) {
+	if dmltest.IsEmbedded() {
+		t.Run("skips", func(t *testing.T) {
+			dmltest.SkipUnsupported(t, dmltest.FeatureWindowFunctions)
+			t.Fatal("test should have been skipped")
+		})
+		return
+	}
+	dmltest.SkipUnsupported(t, dmltest.FeatureWindowFunctions, dmltest.FeatureBinlog)
+	assert.False(t, t.Skipped())
+}
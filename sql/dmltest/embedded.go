@@ -0,0 +1,93 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmltest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// DSNEmbedded if set as value of the environment variable CS_DSN, runs the
+// integration tests against an embedded pure-Go MySQL compatible engine
+// (github.com/dolthub/go-mysql-server) instead of a MySQL server. Requires the
+// build tag `gms`, which is not part of the tag csall. Useful in CI to execute
+// real SQL without a MySQL service container.
+//
+//	CS_DSN=embedded go test -tags gms ./sql/...
+const DSNEmbedded = "embedded"
+
+// Features not supported by the embedded engine. Integration tests relying on
+// them should call SkipUnsupported.
+const (
+	FeatureWindowFunctions             = "window_functions"
+	FeatureLockingReads                = "locking_reads"
+	FeatureForeignKeys                 = "foreign_keys"
+	FeatureFullText                    = "full_text"
+	FeatureStoredPrograms              = "stored_programs"
+	FeatureInformationSchemaStatistics = "information_schema_statistics"
+	FeatureBinlog                      = "binlog"
+	FeatureKillQuery                   = "kill_query"
+)
+
+// EmbeddedUnsupported contains the features not supported by the embedded
+// engine. Can be modified in a TestMain function when the engine gets
+// updated.
+var EmbeddedUnsupported = map[string]bool{
+	FeatureWindowFunctions:             true,
+	FeatureLockingReads:                true,
+	FeatureForeignKeys:                 true,
+	FeatureFullText:                    true,
+	FeatureStoredPrograms:              true,
+	FeatureInformationSchemaStatistics: true,
+	FeatureBinlog:                      true,
+	FeatureKillQuery:                   true,
+}
+
+// startEmbedded starts the embedded engine once per process and returns its
+// DSN. Gets set by the build tag gms.
+var startEmbedded func(t testing.TB) string
+
+// IsEmbedded returns true if the tests run against the embedded engine.
+func IsEmbedded() bool {
+	return os.Getenv(EnvDSN) == DSNEmbedded
+}
+
+// SkipUnsupported skips the test if it runs against the embedded engine and
+// one of the features is not supported.
+func SkipUnsupported(t testing.TB, features ...string) {
+	t.Helper()
+	if !IsEmbedded() {
+		return
+	}
+	var unsupported []string
+	for _, f := range features {
+		if EmbeddedUnsupported[f] {
+			unsupported = append(unsupported, f)
+		}
+	}
+	if len(unsupported) > 0 {
+		t.Skip(color.MagentaString("Embedded engine does not support: %s", strings.Join(unsupported, ", ")))
+	}
+}
+
+func embeddedDSN(t testing.TB) string {
+	if startEmbedded == nil {
+		t.Skip(color.MagentaString("%s=%s requires the build tag gms", EnvDSN, DSNEmbedded))
+	}
+	return startEmbedded(t)
+}
@@ -0,0 +1,149 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build gms
+
+package dmltest
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/server"
+)
+
+// The embedded engine has been written against
+// github.com/dolthub/go-mysql-server v0.18.1. The build tag gms must be set
+// explicitly, so the engine does not become a dependency of the tag csall.
+
+// EmbeddedDatabaseName defines the name of the database created in the
+// embedded engine.
+const EmbeddedDatabaseName = "coretest"
+
+// embeddedReadyTimeout limits the time to wait for the first successful ping.
+const embeddedReadyTimeout = 10 * time.Second
+
+var embedded struct {
+	once sync.Once
+	dsn  string
+	mu   sync.Mutex
+	err  error
+}
+
+func init() {
+	startEmbedded = func(t testing.TB) string {
+		embedded.once.Do(func() {
+			dsn, err := runEmbedded()
+			embedded.mu.Lock()
+			embedded.dsn = dsn
+			if embedded.err == nil {
+				embedded.err = err
+			}
+			embedded.mu.Unlock()
+		})
+		embedded.mu.Lock()
+		defer embedded.mu.Unlock()
+		if embedded.err != nil {
+			t.Fatalf("%+v", embedded.err)
+		}
+		return embedded.dsn
+	}
+}
+
+// setEmbeddedErr stores the first error of the running server, so all
+// following tests fail.
+func setEmbeddedErr(err error) {
+	embedded.mu.Lock()
+	if embedded.err == nil {
+		embedded.err = err
+	}
+	embedded.mu.Unlock()
+}
+
+// runEmbedded starts the engine on a random local port and waits until it
+// accepts connections. The server runs until the test binary exits. All tables
+// get stored in memory.
+func runEmbedded() (string, error) {
+	pro := memory.NewDBProvider(memory.NewDatabase(EmbeddedDatabaseName))
+	engine := sqle.NewDefault(pro)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	addr := l.Addr().String()
+
+	srv, err := server.NewServer(server.Config{
+		Protocol: "tcp",
+		Address:  addr,
+		Listener: l, // the server takes over the bound port
+	}, engine, memory.NewSessionBuilder(pro), nil)
+	if err != nil {
+		_ = l.Close()
+		return "", errors.Wrapf(err, "[dmltest] Failed to create the embedded server on %q", addr)
+	}
+
+	stopped := make(chan error, 1)
+	go func() {
+		err := srv.Start()
+		if err == nil {
+			err = errors.Aborted.Newf("[dmltest] The embedded server on %q stopped", addr)
+		}
+		err = errors.Wrapf(err, "[dmltest] The embedded server on %q failed", addr)
+		setEmbeddedErr(err)
+		stopped <- err
+	}()
+
+	dsn := "root:@tcp(" + addr + ")/" + EmbeddedDatabaseName + "?parseTime=true&loc=UTC"
+	if err := waitEmbedded(dsn, stopped); err != nil {
+		_ = srv.Close()
+		return "", errors.WithStack(err)
+	}
+	return dsn, nil
+}
+
+// waitEmbedded pings the server until it responds, fails or the timeout
+// expires.
+func waitEmbedded(dsn string, stopped <-chan error) error {
+	dbc, err := dml.NewConnPool(dml.WithDSN(dsn))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = dbc.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), embeddedReadyTimeout)
+	defer cancel()
+	tick := time.NewTicker(20 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		errPing := dbc.DB.PingContext(ctx)
+		if errPing == nil {
+			return nil
+		}
+		select {
+		case err := <-stopped:
+			return err
+		case <-ctx.Done():
+			return errors.Wrapf(errPing, "[dmltest] The embedded server did not become ready within %s", embeddedReadyTimeout)
+		case <-tick.C:
+		}
+	}
+}
@@ -117,14 +117,35 @@ func SQLDumpLoad(t testing.TB, globPattern string, o *SQLDumpOptions) struct{ De
 		o.DSN = MustGetDSN(t)
 	}
 
-	matches, err := filepath.Glob(globPattern)
+	cleanup, err := sqlDumpLoad(context.TODO(), globPattern, o)
 	FatalIfError(t, err)
+
+	return struct {
+		Deferred func()
+	}{
+		Deferred: func() {
+			if cleanup != nil {
+				FatalIfError(t, cleanup())
+			}
+		},
+	}
+}
+
+// sqlDumpLoad loads the files into the database of o.DSN. The returned function
+// runs the cleanup files and removes the temporary defaults file.
+func sqlDumpLoad(ctx context.Context, globPattern string, o *SQLDumpOptions) (func() error, error) {
+	matches, err := filepath.Glob(globPattern)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	if len(matches) == 0 {
-		FatalIfError(t, errors.NotFound.Newf("No files found for glob pattern: %q", globPattern))
+		return nil, errors.NotFound.Newf("No files found for glob pattern: %q", globPattern)
 	}
 
 	cfg, err := mysql.ParseDSN(o.DSN)
-	FatalIfError(t, err)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
 	execCmd := o.execCommandContext
 	if execCmd == nil {
@@ -132,41 +153,42 @@ func SQLDumpLoad(t testing.TB, globPattern string, o *SQLDumpOptions) struct{ De
 	}
 
 	dfFile, err := writeMySQLDefaults(cfg, o)
-	FatalIfError(t, err)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
 	myPath := o.MySQLPath
 	if myPath == "" {
 		myPath = "mysql"
 	}
-	ctx := context.TODO()
 
-	runExec := func(file string) {
+	runExec := func(file string) error {
 		f, err := os.Open(file)
-		FatalIfError(t, err)
-
-		err = execCmd(ctx, f, "bash", "-c", fmt.Sprintf("%s --defaults-file=%s", myPath, dfFile))
-		FatalIfError(t, err)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return execCmd(ctx, f, "bash", "-c", fmt.Sprintf("%s --defaults-file=%s", myPath, dfFile))
 	}
 
 	var cleanUpFiles []string
 	for _, file := range matches {
 		if strings.Contains(file, "cleanup") {
 			cleanUpFiles = append(cleanUpFiles, file)
-		} else {
-			runExec(file)
+		} else if err := runExec(file); err != nil {
+			_ = os.Remove(dfFile)
+			return nil, errors.WithStack(err)
 		}
 	}
 
-	return struct {
-		Deferred func()
-	}{
-		Deferred: func() {
-			defer os.Remove(dfFile)
-			if !o.SkipDBCleanup {
-				for _, file := range cleanUpFiles {
-					runExec(file)
+	return func() error {
+		defer os.Remove(dfFile)
+		if !o.SkipDBCleanup {
+			for _, file := range cleanUpFiles {
+				if err := runExec(file); err != nil {
+					return errors.WithStack(err)
 				}
 			}
-		},
-	}
+		}
+		return nil
+	}, nil
 }
@@ -0,0 +1,246 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmltest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/fatih/color"
+	"github.com/go-sql-driver/mysql"
+)
+
+// maxSchemaNameLength defines the maximum length of a MySQL database name.
+const maxSchemaNameLength = 64
+
+// SchemaOptions configures CreateSchema.
+type SchemaOptions struct {
+	// Prefix of the schema name. A random suffix gets appended. Defaults to
+	// the package name of the caller, e.g. "dml_test".
+	Prefix string
+	// DSN of the server. The database name of the DSN gets replaced by the
+	// name of the new schema. Defaults to the DSN of the environment variable
+	// EnvDSN.
+	DSN string
+	// DumpGlobs contains glob patterns of SQL files loaded into the new
+	// schema, see SQLDumpLoad. Cleanup files are not run because the schema
+	// gets dropped.
+	DumpGlobs []string
+	// DumpOptions sets the MySQL binary path and arguments for loading the
+	// files. Its DSN gets ignored.
+	DumpOptions SQLDumpOptions
+	// Migrate runs after loading the dumps, e.g. to create tables via
+	// ddl.Tables.
+	Migrate func(ctx context.Context, db *dml.ConnPool) error
+	// ConnPoolOptions get applied to the connection of the new schema.
+	ConnPoolOptions []dml.ConnPoolOption
+	// SkipDrop keeps the schema after the tests for debugging.
+	SkipDrop bool
+	// mocked out for testing.
+	connect func(dsn string, opts ...dml.ConnPoolOption) (*dml.ConnPool, error)
+}
+
+// Schema is an isolated database on a shared server. It allows to run
+// integration tests of different packages with `-p` and `-parallel` against
+// one MySQL server without colliding fixtures.
+type Schema struct {
+	// Name of the database.
+	Name string
+	// DSN contains the Name as database.
+	DSN string
+	// DB connects to the database of the schema.
+	DB *dml.ConnPool
+
+	admin    *dml.ConnPool
+	skipDrop bool
+}
+
+func connectSchema(dsn string, opts ...dml.ConnPoolOption) (*dml.ConnPool, error) {
+	return dml.NewConnPool(append([]dml.ConnPoolOption{dml.WithDSN(dsn)}, opts...)...)
+}
+
+// CreateSchema creates a new database with a unique name, loads the SQL dumps
+// and runs the migration. It can be called in a TestMain function. Close must
+// be called to drop the database.
+//		func TestMain(m *testing.M) {
+//			s, err := dmltest.CreateSchema(context.Background(), dmltest.SchemaOptions{
+//				DumpGlobs: []string{"testdata/*.sql"},
+//			})
+//			...
+//			code := m.Run()
+//			_ = s.Close()
+//			os.Exit(code)
+//		}
+func CreateSchema(ctx context.Context, o SchemaOptions) (_ *Schema, err error) {
+	if o.DSN == "" {
+		if o.DSN, err = getDSN(EnvDSN); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if o.Prefix == "" {
+		o.Prefix = callerPackage(2)
+	}
+	if o.connect == nil {
+		o.connect = connectSchema
+	}
+	name, err := makeSchemaName(o.Prefix)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cfg, err := mysql.ParseDSN(o.DSN)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cfg.DBName = "" // the database does not yet exist
+	admin, err := o.connect(cfg.FormatDSN())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	s := &Schema{
+		Name:     name,
+		admin:    admin,
+		skipDrop: o.SkipDrop,
+	}
+	defer func() {
+		if err != nil {
+			_ = s.Close()
+		}
+	}()
+
+	if _, err = admin.DB.ExecContext(ctx, "CREATE DATABASE "+dml.Quoter.Name(name)+" DEFAULT CHARACTER SET='utf8mb4' COLLATE='utf8mb4_unicode_ci'"); err != nil {
+		s.skipDrop = true // not created, nothing to drop
+		return nil, errors.Wrapf(err, "[dmltest] CreateSchema failed to create database %q", name)
+	}
+
+	cfg.DBName = name
+	s.DSN = cfg.FormatDSN()
+
+	for _, glob := range o.DumpGlobs {
+		do := o.DumpOptions
+		do.DSN = s.DSN
+		do.SkipDBCleanup = true
+		var cleanup func() error
+		if cleanup, err = sqlDumpLoad(ctx, glob, &do); err != nil {
+			return nil, errors.Wrapf(err, "[dmltest] CreateSchema failed to load %q into %q", glob, name)
+		}
+		if err = cleanup(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if s.DB, err = o.connect(s.DSN, o.ConnPoolOptions...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if o.Migrate != nil {
+		if err = o.Migrate(ctx, s.DB); err != nil {
+			return nil, errors.Wrapf(err, "[dmltest] CreateSchema failed to migrate %q", name)
+		}
+	}
+	return s, nil
+}
+
+// MustCreateSchema creates a new database like CreateSchema and fails the test
+// on error. It skips the test if the DSN environment variable has not been
+// set. The returned function drops the database and must be run in the defer
+// part of a test.
+//		s, drop := dmltest.MustCreateSchema(t, dmltest.SchemaOptions{
+//			DumpGlobs: []string{"testdata/*.sql"},
+//		})
+//		defer drop()
+func MustCreateSchema(t testing.TB, o SchemaOptions) (*Schema, func()) {
+	t.Helper()
+	if o.DSN == "" {
+		o.DSN = MustGetDSN(t)
+	}
+	if o.Prefix == "" {
+		o.Prefix = callerPackage(2)
+	}
+	s, err := CreateSchema(context.TODO(), o)
+	if err != nil {
+		t.Fatalf("%+v", err)
+		return nil, func() {}
+	}
+	return s, func() {
+		t.Helper()
+		if err := s.Close(); err != nil {
+			t.Errorf("%+v", err)
+		}
+	}
+}
+
+// Close closes the connections and drops the database unless SkipDrop has
+// been set.
+func (s *Schema) Close() error {
+	if s.DB != nil {
+		if err := s.DB.Close(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if !s.skipDrop {
+		if _, err := s.admin.DB.ExecContext(context.TODO(), "DROP DATABASE IF EXISTS "+dml.Quoter.Name(s.Name)); err != nil {
+			return errors.Wrapf(err, "[dmltest] Schema failed to drop database %q", s.Name)
+		}
+	} else if s.DB != nil {
+		color.Magenta("[dmltest] Schema %q has not been dropped", s.Name)
+	}
+	return errors.WithStack(s.admin.Close())
+}
+
+// makeSchemaName appends a random suffix to the prefix and replaces all
+// characters not allowed in an unquoted identifier.
+func makeSchemaName(prefix string) (string, error) {
+	var rnd [5]byte
+	if _, err := rand.Read(rnd[:]); err != nil {
+		return "", errors.WithStack(err)
+	}
+	suffix := "_" + hex.EncodeToString(rnd[:])
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, prefix)
+	if len(name) > maxSchemaNameLength-len(suffix) {
+		name = name[:maxSchemaNameLength-len(suffix)]
+	}
+	return name + suffix, nil
+}
+
+// callerPackage returns the name of the package of the caller, e.g. dml_test
+// of github.com/corestoreio/pkg/sql/dml_test.TestMain.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "dmltest"
+	}
+	fn := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndexByte(fn, '/'); i >= 0 {
+		fn = fn[i+1:]
+	}
+	if i := strings.IndexByte(fn, '.'); i >= 0 {
+		fn = fn[:i]
+	}
+	return fn
+}
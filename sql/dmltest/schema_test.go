@@ -0,0 +1,118 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dmltest
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestCreateSchema(t *testing.T) {
+	t.Parallel()
+
+	const dsn = `cs2:cs2@tcp(localhost:3306)/testDB?parseTime=true&loc=UTC`
+
+	t.Run("load and migrate", func(t *testing.T) {
+		var adminMock, schemaMock sqlmock.Sqlmock
+		var loaded int
+		s, err := CreateSchema(context.Background(), SchemaOptions{
+			DSN:       dsn,
+			DumpGlobs: []string{"testdata/*.sql"},
+			DumpOptions: SQLDumpOptions{
+				execCommandContext: func(ctx context.Context, r io.ReadCloser, cmd string, arg ...string) error {
+					loaded++
+					assert.Contains(t, strings.Join(arg, " "), "--defaults-file=")
+					return r.Close()
+				},
+			},
+			Migrate: func(ctx context.Context, db *dml.ConnPool) error {
+				schemaMock.ExpectExec("CREATE TABLE `core_config_data`").WillReturnResult(sqlmock.NewResult(0, 0))
+				_, err := db.DB.ExecContext(ctx, "CREATE TABLE `core_config_data` (`config_id` int)")
+				return err
+			},
+			connect: func(dsn string, opts ...dml.ConnPoolOption) (*dml.ConnPool, error) {
+				dbc, m := MockDB(t, opts...)
+				if adminMock == nil {
+					assert.NotContains(t, dsn, "testDB")
+					m.ExpectExec("CREATE DATABASE `dmltest_[0-9a-f]{10}` DEFAULT CHARACTER SET").WillReturnResult(sqlmock.NewResult(0, 1))
+					adminMock = m
+				} else {
+					assert.Contains(t, dsn, "/dmltest_")
+					schemaMock = m
+				}
+				return dbc, nil
+			},
+		})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(s.Name, "dmltest_"), "%q", s.Name)
+		assert.Len(t, s.Name, len("dmltest_")+10)
+		assert.Contains(t, s.DSN, "/"+s.Name+"?")
+		assert.Exactly(t, 2, loaded)
+
+		adminMock.ExpectExec("DROP DATABASE IF EXISTS `" + s.Name + "`").WillReturnResult(sqlmock.NewResult(0, 1))
+		schemaMock.ExpectClose()
+		adminMock.ExpectClose()
+		assert.NoError(t, s.Close())
+		assert.NoError(t, adminMock.ExpectationsWereMet())
+		assert.NoError(t, schemaMock.ExpectationsWereMet())
+	})
+
+	t.Run("migration fails drops schema", func(t *testing.T) {
+		var adminMock, schemaMock sqlmock.Sqlmock
+		s, err := CreateSchema(context.Background(), SchemaOptions{
+			DSN:    dsn,
+			Prefix: "config/storage-Test",
+			Migrate: func(ctx context.Context, db *dml.ConnPool) error {
+				return errors.NotImplemented.Newf("Cant handle it")
+			},
+			connect: func(dsn string, opts ...dml.ConnPoolOption) (*dml.ConnPool, error) {
+				dbc, m := MockDB(t, opts...)
+				if adminMock == nil {
+					m.ExpectExec("CREATE DATABASE `config_storage_test_[0-9a-f]{10}`").WillReturnResult(sqlmock.NewResult(0, 1))
+					m.ExpectExec("DROP DATABASE IF EXISTS `config_storage_test_[0-9a-f]{10}`").WillReturnResult(sqlmock.NewResult(0, 1))
+					m.ExpectClose()
+					adminMock = m
+				} else {
+					m.ExpectClose()
+					schemaMock = m
+				}
+				return dbc, nil
+			},
+		})
+		assert.Nil(t, s)
+		assert.True(t, errors.NotImplemented.Match(err), "%+v", err)
+		assert.NoError(t, adminMock.ExpectationsWereMet())
+		assert.NoError(t, schemaMock.ExpectationsWereMet())
+	})
+}
+
+func TestMakeSchemaName(t *testing.T) {
+	t.Parallel()
+
+	n1, err := makeSchemaName(strings.Repeat("a", 80))
+	assert.NoError(t, err)
+	assert.Len(t, n1, maxSchemaNameLength)
+	n2, err := makeSchemaName(strings.Repeat("a", 80))
+	assert.NoError(t, err)
+	assert.NotEqual(t, n1, n2)
+	assert.Exactly(t, "dmltest", callerPackage(1))
+}
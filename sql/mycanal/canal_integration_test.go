@@ -38,6 +38,7 @@ func TestIntegrationNewCanal(t *testing.T) {
 	if !*runIntegration {
 		t.Skip("Skipping integration tests. You can enable them with via CLI option `-integration`")
 	}
+	dmltest.SkipUnsupported(t, dmltest.FeatureBinlog)
 
 	dsn := dmltest.MustGetDSN(t)
 
@@ -10,10 +10,10 @@ package null
 
 import (
 	"database/sql"
-	"strings"
 	"time"
 
 	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/conv"
 )
 
 /******************************************************************************
@@ -67,35 +67,9 @@ func (a *Time) Scan(value interface{}) (err error) {
 	return
 }
 
-// ParseDateTime parses a string into a Time type. Empty string is considered NULL.
+// ParseDateTime parses a string into a Time type. Empty string is considered
+// NULL. See conv.ParseDateTime for the supported formats.
 func ParseDateTime(str string, loc *time.Location) (t Time, err error) {
-	if str == "" {
-		return
-	}
-	zeroBase := "0000-00-00 00:00:00.000000000+00:00"
-	base := "2006-01-02 15:04:05.999999999 07:00"
-	if strings.IndexByte(str, 'T') > 0 {
-		base = time.RFC3339Nano
-	}
-
-	switch lStr := len(str); lStr {
-	case 10, 19, 21, 22, 23, 24, 25, 26, 27, 28, 29, 35: // up to "YYYY-MM-DD HH:MM:SS.MMMMMMM+HH:II"
-		if str == zeroBase[:lStr] {
-			return
-		}
-		t.Time, err = time.Parse(base[:lStr], str) // time.RFC3339Nano cannot be used due to the T
-		t.Valid = err == nil
-	default:
-		err = errors.NotValid.Newf("invalid time string: %q", str)
-		return
-	}
-
-	// Adjust location
-	if err == nil && loc != time.UTC {
-		y, mo, d := t.Time.Date()
-		h, mi, s := t.Time.Clock()
-		t.Time, err = time.Date(y, mo, d, h, mi, s, t.Time.Nanosecond(), loc), nil
-	}
-
+	t.Time, t.Valid, err = conv.ParseDateTime(str, loc)
 	return
 }
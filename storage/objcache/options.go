@@ -35,6 +35,10 @@ type ServiceOptions struct {
 	// information in the cache.
 	PrimeObjects   []interface{}
 	DefaultExpires time.Duration
+	// NegativeExpires defines the time to live of a negative entry written
+	// with function Service.SetNegative. Should be short to pick up newly
+	// created rows fast. Defaults to DefaultNegativeExpires.
+	NegativeExpires time.Duration
 }
 
 // NewCacheSimpleInmemory creates an in-memory map map[string]string as cache
@@ -0,0 +1,100 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objcache
+
+import (
+	"context"
+	"sync"
+)
+
+type keyRequestCache struct{}
+
+// RequestCache memoizes the raw values returned by the storage backends of
+// Service.Get and Service.GetMulti for the lifetime of one request. Repeated
+// lookups of the same key within one request get decoded from the memoized
+// value without querying level1 or level2 again. Set, SetMulti, Delete and
+// Truncate update the RequestCache, so that a request reads its own writes.
+// The RequestCache gets discarded together with its context and requires no
+// expiration. Safe for concurrent use.
+type RequestCache struct {
+	mu     sync.RWMutex
+	values map[string][]byte
+}
+
+// WithRequestCache returns a new context containing an empty RequestCache.
+// Should be called once at the beginning of a request, for example in a HTTP
+// middleware:
+//		r = r.WithContext(objcache.WithRequestCache(r.Context()))
+func WithRequestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyRequestCache{}, &RequestCache{
+		values: make(map[string][]byte),
+	})
+}
+
+// FromContext returns the RequestCache of the context. Returns nil and false if
+// the context does not contain a RequestCache.
+func FromContext(ctx context.Context) (*RequestCache, bool) {
+	rc, ok := ctx.Value(keyRequestCache{}).(*RequestCache)
+	return rc, ok && rc != nil
+}
+
+// Len returns the number of memoized keys.
+func (rc *RequestCache) Len() int {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return len(rc.values)
+}
+
+// get returns the memoized values of all keys. Reports false if at least one
+// key is missing.
+func (rc *RequestCache) get(keys []string) ([][]byte, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		v, ok := rc.values[k]
+		if !ok {
+			return nil, false
+		}
+		values[i] = v
+	}
+	return values, true
+}
+
+// set memoizes the values. Empty values, which represent a cache miss, do not
+// get memoized.
+func (rc *RequestCache) set(keys []string, values [][]byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for i := 0; i < len(keys) && i < len(values); i++ {
+		if values[i] != nil {
+			rc.values[keys[i]] = values[i]
+		}
+	}
+}
+
+func (rc *RequestCache) delete(keys []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, k := range keys {
+		delete(rc.values, k)
+	}
+}
+
+func (rc *RequestCache) truncate() {
+	rc.mu.Lock()
+	rc.values = make(map[string][]byte)
+	rc.mu.Unlock()
+}
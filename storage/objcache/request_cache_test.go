@@ -0,0 +1,88 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objcache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+type countingStorage struct {
+	Storager
+	gets int32
+}
+
+func (cs *countingStorage) Get(ctx context.Context, keys []string) ([][]byte, error) {
+	atomic.AddInt32(&cs.gets, 1)
+	return cs.Storager.Get(ctx, keys)
+}
+
+func TestWithRequestCache(t *testing.T) {
+	t.Parallel()
+
+	mc, _ := NewCacheSimpleInmemory()
+	cs := &countingStorage{Storager: mc}
+	p, err := NewService(nil, func() (Storager, error) { return cs, nil }, &ServiceOptions{Codec: nil})
+	assert.NoError(t, err)
+	defer assert.NoError(t, p.Close())
+
+	assert.NoError(t, p.Set(context.TODO(), "kt", encodingText("Hello"), 0))
+	assert.NoError(t, p.Set(context.TODO(), "kt2", encodingText("World"), 0))
+
+	_, ok := FromContext(context.TODO())
+	assert.False(t, ok)
+
+	ctx := WithRequestCache(context.TODO())
+	rc, ok := FromContext(ctx)
+	assert.True(t, ok)
+
+	for i := 0; i < 3; i++ {
+		var obj encodingText
+		assert.NoError(t, p.Get(ctx, "kt", &obj))
+		assert.Exactly(t, encodingText("Hello"), obj)
+	}
+	assert.Exactly(t, int32(1), atomic.LoadInt32(&cs.gets))
+	assert.Exactly(t, 1, rc.Len())
+
+	t.Run("GetMulti partial hit queries the backend", func(t *testing.T) {
+		var obj1, obj2 encodingText
+		assert.NoError(t, p.GetMulti(ctx, []string{"kt", "kt2"}, []interface{}{&obj1, &obj2}))
+		assert.NoError(t, p.GetMulti(ctx, []string{"kt", "kt2"}, []interface{}{&obj1, &obj2}))
+		assert.Exactly(t, encodingText("World"), obj2)
+		assert.Exactly(t, int32(2), atomic.LoadInt32(&cs.gets))
+	})
+
+	t.Run("new request queries the backend", func(t *testing.T) {
+		var obj encodingText
+		assert.NoError(t, p.Get(context.TODO(), "kt2", &obj))
+		assert.Exactly(t, int32(3), atomic.LoadInt32(&cs.gets))
+	})
+
+	t.Run("reads its own writes", func(t *testing.T) {
+		assert.NoError(t, p.Set(ctx, "kt", encodingText("Hello Gopher"), 0))
+		var obj encodingText
+		assert.NoError(t, p.Get(ctx, "kt", &obj))
+		assert.Exactly(t, encodingText("Hello Gopher"), obj)
+		assert.Exactly(t, int32(3), atomic.LoadInt32(&cs.gets))
+
+		assert.NoError(t, p.Delete(ctx, "kt"))
+		assert.Exactly(t, 1, rc.Len())
+		assert.NoError(t, p.Truncate(ctx))
+		assert.Exactly(t, 0, rc.Len())
+	})
+}
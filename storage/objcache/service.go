@@ -168,12 +168,27 @@ func encodeAll(codec Codecer, ri *rawItems, defaultExpire time.Duration, keys []
 	return ri, nil
 }
 
+// DefaultNegativeExpires defines the default time to live of a negative entry.
+const DefaultNegativeExpires = 30 * time.Second
+
+// negativeEntry gets stored as value for keys whose lookup in the database
+// returned no rows. No encoder writes a leading zero byte plus this text.
+const negativeEntry = "\x00objcache:negative\x00"
+
 func decodeAll(codec Codecer, values [][]byte, keys []string, dst []interface{}) error {
+	var negKeys []string
 	for i, key := range keys {
+		if string(values[i]) == negativeEntry {
+			negKeys = append(negKeys, key)
+			continue
+		}
 		if err := decodeOne(codec, values[i], key, dst[i]); err != nil {
 			return errors.WithStack(err)
 		}
 	}
+	if len(negKeys) > 0 {
+		return errors.NotExists.Newf("[objcache] Negative entry for keys %v", negKeys)
+	}
 	return nil
 }
 
@@ -280,6 +295,9 @@ func (tr *Service) Set(ctx context.Context, key string, src interface{}, expires
 	if err := tr.level2.Set(ctx, ri.keys, ri.values, ri.expires); err != nil {
 		return errors.WithStack(err)
 	}
+	if rc, ok := FromContext(ctx); ok {
+		rc.set(ri.keys, ri.values)
+	}
 	return nil
 }
 
@@ -306,6 +324,55 @@ func (tr *Service) SetMulti(ctx context.Context, keys []string, src []interface{
 	if err := tr.level2.Set(ctx, ri.keys, ri.values, ri.expires); err != nil {
 		return errors.WithStack(err)
 	}
+	if rc, ok := FromContext(ctx); ok {
+		rc.set(ri.keys, ri.values)
+	}
+	return nil
+}
+
+// SetNegative caches the miss of a database lookup, for example a SKU or an
+// email address which does not exist. It protects the database from repeated
+// lookups of non-existent rows, caused by scrapers or attacks. The entries
+// expire after ServiceOptions.NegativeExpires. Get and GetMulti return an error
+// of kind NotExists for a key with a negative entry. Set or Delete remove a
+// negative entry.
+//		err := srv.Get(ctx, "sku_"+sku, &p)
+//		switch {
+//		case errors.NotExists.Match(err):
+//			return nil // cached miss, no database query
+//		case err != nil:
+//			return err
+//		}
+//		// load p from the database and if no rows:
+//		err = srv.SetNegative(ctx, "sku_"+sku)
+func (tr *Service) SetNegative(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	ri := tr.poolGetRawItems()
+	defer tr.poolPutRawItems(ri)
+
+	expires := tr.so.NegativeExpires
+	if expires == 0 {
+		expires = DefaultNegativeExpires
+	}
+	for _, key := range keys {
+		ri.keys = append(ri.keys, key)
+		ri.values = append(ri.values, []byte(negativeEntry))
+		ri.expires = append(ri.expires, expires)
+	}
+
+	if tr.level1 != nil {
+		if err := tr.level1.Set(ctx, ri.keys, ri.values, ri.expires); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := tr.level2.Set(ctx, ri.keys, ri.values, ri.expires); err != nil {
+		return errors.WithStack(err)
+	}
+	if rc, ok := FromContext(ctx); ok {
+		rc.set(ri.keys, ri.values)
+	}
 	return nil
 }
 
@@ -326,7 +393,9 @@ type unmarshaler interface {
 // This type check has precedence before the decoder. You have to check yourself
 // if the returned error is of type NotFound or of any other source. Every
 // caching type defines its own NotFound error. If dst has no pointer property,
-// no error gets returned, instead the passed value stays empty.
+// no error gets returned, instead the passed value stays empty. If the context
+// contains a RequestCache, see WithRequestCache, a key gets looked up only once
+// per request.
 func (tr *Service) Get(ctx context.Context, key string, dst interface{}) (err error) {
 	// If dst is not pointer ... unlucky you, we don't do checks with reflect.
 	// Instead write better tests.
@@ -335,6 +404,14 @@ func (tr *Service) Get(ctx context.Context, key string, dst interface{}) (err er
 
 	ri.keys = append(ri.keys, key)
 
+	rc, hasRC := FromContext(ctx)
+	if hasRC {
+		if vals, ok := rc.get(ri.keys); ok {
+			idst := [1]interface{}{dst}
+			return errors.WithStack(decodeAll(tr.so.Codec, vals, ri.keys, idst[:]))
+		}
+	}
+
 	var vals [][]byte
 	if tr.level1 != nil {
 		vals, err = tr.level1.Get(ctx, ri.keys)
@@ -349,6 +426,9 @@ func (tr *Service) Get(ctx context.Context, key string, dst interface{}) (err er
 		}
 	}
 	if err == nil {
+		if hasRC {
+			rc.set(ri.keys, vals)
+		}
 		idst := [1]interface{}{dst}
 		if err2 := decodeAll(tr.so.Codec, vals, ri.keys, idst[:]); err2 != nil {
 			return errors.WithStack(err2)
@@ -363,6 +443,14 @@ func (tr *Service) GetMulti(ctx context.Context, keys []string, dst []interface{
 	if lk, ld := len(keys), len(dst); lk != ld {
 		return errors.Mismatch.Newf("[objcache] Length of keys (%d) vs length of dst (%d) must be equal", lk, ld)
 	}
+
+	rc, hasRC := FromContext(ctx)
+	if hasRC {
+		if vals, ok := rc.get(keys); ok {
+			return errors.WithStack(decodeAll(tr.so.Codec, vals, keys, dst))
+		}
+	}
+
 	var vals [][]byte
 	if tr.level1 != nil {
 		vals, err = tr.level1.Get(ctx, keys)
@@ -379,6 +467,9 @@ func (tr *Service) GetMulti(ctx context.Context, keys []string, dst []interface{
 	if err != nil && errors.NotFound.Match(err) {
 		return errors.WithStack(err)
 	}
+	if hasRC {
+		rc.set(keys, vals)
+	}
 
 	if err := decodeAll(tr.so.Codec, vals, keys, dst); err != nil {
 		return errors.WithStack(err)
@@ -397,6 +488,9 @@ func (tr *Service) Truncate(ctx context.Context) (err error) {
 	if err := tr.level2.Truncate(ctx); err != nil {
 		return errors.WithStack(err)
 	}
+	if rc, ok := FromContext(ctx); ok {
+		rc.truncate()
+	}
 
 	return nil
 }
@@ -411,6 +505,9 @@ func (tr *Service) Delete(ctx context.Context, key ...string) error {
 	if err := tr.level2.Delete(ctx, key); err != nil {
 		return errors.WithStack(err)
 	}
+	if rc, ok := FromContext(ctx); ok {
+		rc.delete(key)
+	}
 	return nil
 }
 
@@ -402,3 +402,45 @@ func testExpiration(t *testing.T, cb func(), level2 objcache.NewStorageFn, so *o
 	assert.NoError(t, err, "%+v", err)
 	assert.Empty(t, newVal)
 }
+
+func TestService_SetNegative(t *testing.T) {
+	t.Parallel()
+	p, err := objcache.NewService(objcache.NewCacheSimpleInmemory, objcache.NewCacheSimpleInmemory, &objcache.ServiceOptions{
+		NegativeExpires: time.Minute,
+	})
+	assert.NoError(t, err)
+	defer assert.NoError(t, p.Close())
+	ctx := context.TODO()
+
+	assert.NoError(t, p.SetNegative(ctx, "sku_404", "email_404"))
+
+	t.Run("Get", func(t *testing.T) {
+		var ms myString
+		err := p.Get(ctx, "sku_404", &ms)
+		assert.ErrorIsKind(t, errors.NotExists, err)
+		assert.Exactly(t, "", ms.data)
+	})
+
+	t.Run("GetMulti", func(t *testing.T) {
+		assert.NoError(t, p.Set(ctx, "sku_200", &myString{data: "found"}, 0))
+		var ms1, ms2 myString
+		err := p.GetMulti(ctx, []string{"sku_200", "email_404"}, []interface{}{&ms1, &ms2})
+		assert.ErrorIsKind(t, errors.NotExists, err)
+		assert.Exactly(t, "found", ms1.data)
+		assert.Exactly(t, "", ms2.data)
+	})
+
+	t.Run("Set overwrites", func(t *testing.T) {
+		assert.NoError(t, p.Set(ctx, "email_404", &myString{data: "now exists"}, 0))
+		var ms myString
+		assert.NoError(t, p.Get(ctx, "email_404", &ms))
+		assert.Exactly(t, "now exists", ms.data)
+	})
+
+	t.Run("RequestCache", func(t *testing.T) {
+		rctx := objcache.WithRequestCache(ctx)
+		assert.NoError(t, p.SetNegative(rctx, "sku_rc"))
+		var ms myString
+		assert.ErrorIsKind(t, errors.NotExists, p.Get(rctx, "sku_rc", &ms))
+	})
+}
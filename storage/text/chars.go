@@ -0,0 +1,45 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import "bytes"
+
+// Chars avoids storing long string values like labels, comments or hints as
+// strings. Saves the conversion to string for the whole time and reduces the
+// garbage.
+type Chars []byte
+
+// String implements fmt.Stringer.
+func (c Chars) String() string {
+	return string(c)
+}
+
+// IsEmpty reports whether the Chars contains no bytes.
+func (c Chars) IsEmpty() bool {
+	return len(c) == 0
+}
+
+// Equal compares two Chars byte by byte.
+func (c Chars) Equal(b Chars) bool {
+	return bytes.Equal(c, b)
+}
+
+// Clone returns a copy which does not share the underlying array.
+func (c Chars) Clone() Chars {
+	if c == nil {
+		return nil
+	}
+	return append(Chars{}, c...)
+}
@@ -0,0 +1,122 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text
+
+import "sync"
+
+// Default limits of the package level intern pool.
+const (
+	DefaultInternMaxEntries = 1 << 16
+	DefaultInternMaxLength  = 64
+)
+
+var defaultInternPool = NewInternPool(DefaultInternMaxEntries, DefaultInternMaxLength)
+
+// Intern returns the shared Chars of b from the package level pool. See
+// InternPool.InternBytes.
+func Intern(b []byte) Chars {
+	return defaultInternPool.InternBytes(b)
+}
+
+// InternString returns the shared Chars of s from the package level pool. See
+// InternPool.Intern.
+func InternString(s string) Chars {
+	return defaultInternPool.Intern(s)
+}
+
+// InternPool deduplicates frequently repeated short strings like column names,
+// enum values or configuration paths. Loading large collections allocates for
+// each row a new byte slice per column, interning lets all rows share the same
+// backing array and reduces the steady-state heap usage. The number of entries
+// and the length of an interned value are limited, so the pool cannot grow
+// with unique values. Values exceeding the limits get copied. The returned
+// Chars are shared and must not be modified, use Chars.Clone before. Safe for
+// concurrent use.
+type InternPool struct {
+	maxEntries int
+	maxLength  int
+
+	mu   sync.RWMutex
+	pool map[string]Chars
+}
+
+// NewInternPool creates a new pool holding at most maxEntries values with a
+// length of at most maxLength bytes each.
+func NewInternPool(maxEntries, maxLength int) *InternPool {
+	return &InternPool{
+		maxEntries: maxEntries,
+		maxLength:  maxLength,
+		pool:       make(map[string]Chars),
+	}
+}
+
+// InternBytes returns the shared Chars of b. A lookup of an already interned
+// value does not allocate. The content of b gets copied, so b can be reused.
+func (ip *InternPool) InternBytes(b []byte) Chars {
+	if b == nil {
+		return nil
+	}
+	if len(b) > ip.maxLength {
+		return Chars(b).Clone()
+	}
+	ip.mu.RLock()
+	c, ok := ip.pool[string(b)] // does not allocate
+	ip.mu.RUnlock()
+	if ok {
+		return c
+	}
+	return ip.add(string(b))
+}
+
+// Intern returns the shared Chars of s.
+func (ip *InternPool) Intern(s string) Chars {
+	if len(s) > ip.maxLength {
+		return Chars(s)
+	}
+	ip.mu.RLock()
+	c, ok := ip.pool[s]
+	ip.mu.RUnlock()
+	if ok {
+		return c
+	}
+	return ip.add(s)
+}
+
+func (ip *InternPool) add(s string) Chars {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	if c, ok := ip.pool[s]; ok {
+		return c
+	}
+	c := Chars(s)
+	if len(ip.pool) < ip.maxEntries {
+		ip.pool[s] = c
+	}
+	return c
+}
+
+// Len returns the number of interned values.
+func (ip *InternPool) Len() int {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	return len(ip.pool)
+}
+
+// Reset removes all interned values. Already returned Chars stay valid.
+func (ip *InternPool) Reset() {
+	ip.mu.Lock()
+	ip.pool = make(map[string]Chars)
+	ip.mu.Unlock()
+}
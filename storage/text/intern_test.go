@@ -0,0 +1,90 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package text_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/corestoreio/pkg/storage/text"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestInternPool(t *testing.T) {
+	t.Parallel()
+
+	ip := text.NewInternPool(2, 8)
+
+	buf := []byte("general")
+	c1 := ip.InternBytes(buf)
+	buf[0] = 'G' // reusing the buffer must not change the interned value
+	c2 := ip.Intern("general")
+	assert.Exactly(t, "general", c1.String())
+	assert.Same(t, &c1[0], &c2[0], "must share the backing array")
+	assert.Exactly(t, 1, ip.Len())
+
+	long := ip.Intern("websites/1/web/cookie")
+	assert.Exactly(t, "websites/1/web/cookie", long.String())
+	assert.Exactly(t, 1, ip.Len(), "too long values must not be interned")
+
+	ip.Intern("stores")
+	c3 := ip.Intern("default")
+	c4 := ip.Intern("default")
+	assert.Exactly(t, "default", c3.String())
+	assert.NotSame(t, &c3[0], &c4[0], "full pool must copy")
+	assert.Exactly(t, 2, ip.Len())
+
+	assert.Nil(t, ip.InternBytes(nil))
+	assert.Exactly(t, text.Chars{}, ip.InternBytes([]byte{}))
+
+	ip.Reset()
+	assert.Exactly(t, 0, ip.Len())
+	assert.Exactly(t, "general", c1.String())
+}
+
+func TestInternPool_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	ip := text.NewInternPool(text.DefaultInternMaxEntries, text.DefaultInternMaxLength)
+	paths := []string{"web/cookie/lifetime", "web/cookie/path", "general/locale/code"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, p := range paths {
+				assert.Exactly(t, p, ip.InternBytes([]byte(p)).String())
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Exactly(t, len(paths), ip.Len())
+	assert.Exactly(t, "default", text.InternString("default").String())
+	assert.Exactly(t, "default", text.Intern([]byte("default")).String())
+}
+
+func BenchmarkInternPool_InternBytes(b *testing.B) {
+	ip := text.NewInternPool(text.DefaultInternMaxEntries, text.DefaultInternMaxLength)
+	val := []byte("catalog/frontend/list_mode")
+	ip.InternBytes(val)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if c := ip.InternBytes(val); len(c) != len(val) {
+			b.Fatal("length mismatch")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"sync"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/store/scope"
+)
+
+// ConfigPathCatalogPriceScope defines the route to the configuration value
+// which specifies if prices are global or per website. Values: 0 = global, 1 =
+// website, 2 = store.
+const ConfigPathCatalogPriceScope = `catalog/price/scope`
+
+// Those constants define the allowed values of the configuration path
+// ConfigPathCatalogPriceScope.
+const (
+	PriceScopeGlobal = iota
+	PriceScopeWebsite
+	PriceScopeStore
+)
+
+// ConfigScoper creates a hierarchy based configuration retriever based on a
+// website and its store ID. Gets implemented by config.Service and
+// config.FakeService.
+type ConfigScoper interface {
+	Scoped(websiteID, storeID uint32) config.Scoped
+}
+
+// PriceScope resolves in which scope a price or a price related attribute
+// (e.g. tax class) should be read from. The decision depends on the
+// configuration value of ConfigPathCatalogPriceScope which can be set on
+// default or website scope. The resolved decision matrix gets cached per store
+// ID. Safe for concurrent use.
+type PriceScope struct {
+	// Route allows to overwrite the default ConfigPathCatalogPriceScope, e.g.
+	// to resolve the scope of tax related attributes.
+	Route  string
+	stores *Service
+	cfg    ConfigScoper
+
+	mu sync.RWMutex
+	// matrix key is the store ID and value is the resolved scope.
+	matrix map[uint32]scope.TypeID
+}
+
+// NewPriceScope creates a new price scope resolver. The store Service provides
+// the store to website relation and the ConfigScoper the configuration value.
+func NewPriceScope(stores *Service, cfg ConfigScoper) *PriceScope {
+	return &PriceScope{
+		Route:  ConfigPathCatalogPriceScope,
+		stores: stores,
+		cfg:    cfg,
+		matrix: make(map[uint32]scope.TypeID),
+	}
+}
+
+// Resolve returns for a store ID the scope from which the price should be
+// read. Global price scope returns scope.DefaultTypeID, website price scope
+// the website of the store and store price scope the store itself. Error
+// behaviour: NotFound (store) or NotSupported (configuration value).
+func (ps *PriceScope) Resolve(storeID uint32) (scope.TypeID, error) {
+	ps.mu.RLock()
+	tid, ok := ps.matrix[storeID]
+	ps.mu.RUnlock()
+	if ok {
+		return tid, nil
+	}
+
+	st, err := ps.stores.Store(storeID)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	// the config lookup runs without a lock, concurrent callers might resolve
+	// the same store ID twice but the first stored result wins.
+	v := ps.cfg.Scoped(st.WebsiteID, st.StoreID).Get(scope.Website, ps.Route)
+	ps64, _, err := v.Int64()
+	if err != nil {
+		return 0, errors.Wrapf(err, "[store] PriceScope.Resolve failed to read route %q for store ID %d", ps.Route, storeID)
+	}
+
+	switch ps64 {
+	case PriceScopeGlobal:
+		tid = scope.DefaultTypeID
+	case PriceScopeWebsite:
+		tid = scope.Website.WithID(st.WebsiteID)
+	case PriceScopeStore:
+		tid = scope.Store.WithID(st.StoreID)
+	default:
+		return 0, errors.NotSupported.Newf("[store] PriceScope.Resolve value %d of route %q not supported for store ID %d", ps64, ps.Route, storeID)
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if tid2, ok := ps.matrix[storeID]; ok {
+		return tid2, nil
+	}
+	ps.matrix[storeID] = tid
+	return tid, nil
+}
+
+// StoreIDs returns the store IDs which must be queried in an EAV table to
+// retrieve a price for the store ID. The admin store ID 0 is always included
+// as fallback and always on position zero.
+func (ps *PriceScope) StoreIDs(storeID uint32) ([]uint64, error) {
+	tid, err := ps.Resolve(storeID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if tid == scope.DefaultTypeID {
+		return []uint64{0}, nil
+	}
+	// Website price scope: Magento saves a website value into all store views
+	// of that website so querying the current store is sufficient.
+	return []uint64{0, uint64(storeID)}, nil
+}
+
+// StoreIDCondition creates a condition for the dml query builders to restrict
+// an EAV query to the store IDs as returned by function StoreIDs. Argument
+// column is e.g. `store_id` or `cpei.store_id`.
+func (ps *PriceScope) StoreIDCondition(column string, storeID uint32) (*dml.Condition, error) {
+	ids, err := ps.StoreIDs(storeID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(ids) == 1 {
+		return dml.Column(column).Uint64(ids[0]), nil
+	}
+	return dml.Column(column).In().Uint64s(ids...), nil
+}
+
+// WebsiteIDCondition creates a condition for tables containing a website_id
+// column, like price index tables. Global price scope uses website ID 0.
+func (ps *PriceScope) WebsiteIDCondition(column string, storeID uint32) (*dml.Condition, error) {
+	tid, err := ps.Resolve(storeID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var websiteID uint32
+	if tid != scope.DefaultTypeID {
+		st, err := ps.stores.Store(storeID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		websiteID = st.WebsiteID
+	}
+	return dml.Column(column).Uint64(uint64(websiteID)), nil
+}
+
+// ClearCache resets the internal decision matrix. Must be called when the
+// configuration value or the store structure changes.
+func (ps *PriceScope) ClearCache() {
+	ps.mu.Lock()
+	ps.matrix = make(map[uint32]scope.TypeID)
+	ps.mu.Unlock()
+}
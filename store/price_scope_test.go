@@ -0,0 +1,143 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/config/storage"
+	"github.com/corestoreio/pkg/sql/dml"
+	"github.com/corestoreio/pkg/store"
+	"github.com/corestoreio/pkg/store/scope"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestPriceScope_Resolve(t *testing.T) {
+	srv := store.MustNewService(
+		store.WithWebsites(
+			&store.StoreWebsite{WebsiteID: 1, Code: "euro", DefaultGroupID: 1, IsDefault: true},
+			&store.StoreWebsite{WebsiteID: 2, Code: "oz", DefaultGroupID: 2},
+			&store.StoreWebsite{WebsiteID: 3, Code: "us", DefaultGroupID: 3},
+		),
+		store.WithGroups(
+			&store.StoreGroup{GroupID: 1, WebsiteID: 1, DefaultStoreID: 1, Code: "dach"},
+			&store.StoreGroup{GroupID: 2, WebsiteID: 2, DefaultStoreID: 2, Code: "oz"},
+			&store.StoreGroup{GroupID: 3, WebsiteID: 3, DefaultStoreID: 3, Code: "us"},
+		),
+		store.WithStores(
+			&store.Store{StoreID: 1, WebsiteID: 1, GroupID: 1, Code: "de", IsActive: true},
+			&store.Store{StoreID: 2, WebsiteID: 2, GroupID: 2, Code: "au", IsActive: true},
+			&store.Store{StoreID: 3, WebsiteID: 3, GroupID: 3, Code: "us", IsActive: true},
+		),
+	)
+
+	cfg := config.NewFakeService(storage.NewMap(
+		"default/0/"+store.ConfigPathCatalogPriceScope, "0",
+		"websites/2/"+store.ConfigPathCatalogPriceScope, "1",
+		"websites/3/"+store.ConfigPathCatalogPriceScope, "2",
+	))
+	ps := store.NewPriceScope(srv, cfg)
+
+	t.Run("global", func(t *testing.T) {
+		tid, err := ps.Resolve(1)
+		assert.NoError(t, err)
+		assert.Exactly(t, scope.DefaultTypeID, tid)
+
+		ids, err := ps.StoreIDs(1)
+		assert.NoError(t, err)
+		assert.Exactly(t, []uint64{0}, ids)
+
+		cnd, err := ps.StoreIDCondition("store_id", 1)
+		assert.NoError(t, err)
+		sqlStr, _, err := dml.NewSelect("*").From("catalog_product_entity_decimal").Where(cnd).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT * FROM `catalog_product_entity_decimal` WHERE (`store_id` = 0)", sqlStr)
+	})
+	t.Run("website", func(t *testing.T) {
+		tid, err := ps.Resolve(2)
+		assert.NoError(t, err)
+		assert.Exactly(t, scope.Website.WithID(2), tid)
+
+		cnd, err := ps.StoreIDCondition("store_id", 2)
+		assert.NoError(t, err)
+		sqlStr, _, err := dml.NewSelect("*").From("catalog_product_entity_decimal").Where(cnd).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT * FROM `catalog_product_entity_decimal` WHERE (`store_id` IN (0,2))", sqlStr)
+
+		cnd, err = ps.WebsiteIDCondition("website_id", 2)
+		assert.NoError(t, err)
+		sqlStr, _, err = dml.NewSelect("*").From("catalog_product_index_price").Where(cnd).ToSQL()
+		assert.NoError(t, err)
+		assert.Exactly(t, "SELECT * FROM `catalog_product_index_price` WHERE (`website_id` = 2)", sqlStr)
+	})
+	t.Run("store", func(t *testing.T) {
+		tid, err := ps.Resolve(3)
+		assert.NoError(t, err)
+		assert.Exactly(t, scope.Store.WithID(3), tid)
+	})
+	t.Run("store not found", func(t *testing.T) {
+		tid, err := ps.Resolve(4)
+		assert.True(t, errors.NotFound.Match(err), "%+v", err)
+		assert.Exactly(t, scope.TypeID(0), tid)
+	})
+	t.Run("value not supported", func(t *testing.T) {
+		ps2 := store.NewPriceScope(srv, config.NewFakeService(storage.NewMap(
+			"default/0/"+store.ConfigPathCatalogPriceScope, "5",
+		)))
+		_, err := ps2.Resolve(1)
+		assert.True(t, errors.NotSupported.Match(err), "%+v", err)
+	})
+	t.Run("ClearCache", func(t *testing.T) {
+		ps.ClearCache()
+		tid, err := ps.Resolve(2)
+		assert.NoError(t, err)
+		assert.Exactly(t, scope.Website.WithID(2), tid)
+	})
+	t.Run("config lookup without lock", func(t *testing.T) {
+		rs := &resolvingScoper{ConfigScoper: cfg}
+		ps2 := store.NewPriceScope(srv, rs)
+		_, err := ps2.Resolve(1)
+		assert.NoError(t, err)
+		rs.ps = ps2
+
+		done := make(chan error)
+		go func() {
+			_, err := ps2.Resolve(3)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Resolve holds the lock during the config lookup")
+		}
+	})
+}
+
+// resolvingScoper resolves the cached store ID 1 during each config lookup.
+type resolvingScoper struct {
+	store.ConfigScoper
+	ps *store.PriceScope
+}
+
+func (rs *resolvingScoper) Scoped(websiteID, storeID uint32) config.Scoped {
+	if rs.ps != nil {
+		_, _ = rs.ps.Resolve(1)
+	}
+	return rs.ConfigScoper.Scoped(websiteID, storeID)
+}
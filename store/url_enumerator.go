@@ -0,0 +1,238 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/store/scope"
+)
+
+// Those constants define the configuration routes read by the URLEnumerator.
+const (
+	ConfigPathWebSecureBaseURL   = `web/secure/base_url`
+	ConfigPathWebUnsecureBaseURL = `web/unsecure/base_url`
+	ConfigPathGeneralLocaleCode  = `general/locale/code`
+	// ConfigPathWebSEOSuppressAlternates excludes a store view from the
+	// hreflang alternates and the sitemap when set to true.
+	ConfigPathWebSEOSuppressAlternates = `web/seo/suppress_alternates`
+)
+
+// HreflangXDefault defines the hreflang value of the fallback URL which points
+// to the default store view.
+const HreflangXDefault = `x-default`
+
+const placeholderUnsecureBaseURL = `{{unsecure_base_url}}`
+
+// StoreURL defines the URL of a resource in a store view.
+type StoreURL struct {
+	StoreID uint32
+	Code    string
+	// Hreflang contains the locale of the store in the format of the HTML
+	// hreflang attribute, e.g. de-DE. Empty if the store has no locale.
+	Hreflang string
+	URL      string
+}
+
+// SitemapEntry defines a <url> element of a sitemap. Loc contains the URL of
+// the resource in one store view and Alternates the xhtml:link elements to
+// all store views, including the store view of Loc.
+type SitemapEntry struct {
+	StoreID    uint32
+	Loc        string
+	Alternates []StoreURL
+}
+
+type storeURLBase struct {
+	baseURL    string
+	hreflang   string
+	suppressed bool
+}
+
+// URLEnumerator enumerates the URLs of a logical resource, like a product or
+// category path, in all active store views. The base URL, the locale and the
+// suppression flag get read from the configuration per store view and get
+// cached per store ID. The admin store gets always skipped. Safe for concurrent
+// use.
+type URLEnumerator struct {
+	// BaseURLRoute allows to overwrite the default ConfigPathWebSecureBaseURL,
+	// e.g. with ConfigPathWebUnsecureBaseURL.
+	BaseURLRoute string
+	stores       *Service
+	cfg          ConfigScoper
+
+	mu    sync.RWMutex
+	bases map[uint32]storeURLBase
+}
+
+// NewURLEnumerator creates a new URL enumerator. The store Service provides the
+// store views and the ConfigScoper the base URLs, locales and suppression
+// flags.
+func NewURLEnumerator(stores *Service, cfg ConfigScoper) *URLEnumerator {
+	return &URLEnumerator{
+		BaseURLRoute: ConfigPathWebSecureBaseURL,
+		stores:       stores,
+		cfg:          cfg,
+		bases:        make(map[uint32]storeURLBase),
+	}
+}
+
+func (ue *URLEnumerator) base(st *Store) (storeURLBase, error) {
+	ue.mu.RLock()
+	b, ok := ue.bases[st.StoreID]
+	ue.mu.RUnlock()
+	if ok {
+		return b, nil
+	}
+
+	ue.mu.Lock()
+	defer ue.mu.Unlock()
+	if b, ok = ue.bases[st.StoreID]; ok {
+		return b, nil
+	}
+
+	cfg := ue.cfg.Scoped(st.WebsiteID, st.StoreID)
+	baseURL, ok, err := cfg.Get(scope.Store, ue.BaseURLRoute).Str()
+	if err != nil {
+		return b, errors.Wrapf(err, "[store] URLEnumerator failed to read route %q for store ID %d", ue.BaseURLRoute, st.StoreID)
+	}
+	if !ok || baseURL == "" {
+		return b, errors.NotFound.Newf("[store] URLEnumerator base URL of route %q not found for store ID %d", ue.BaseURLRoute, st.StoreID)
+	}
+	if strings.HasPrefix(baseURL, placeholderUnsecureBaseURL) {
+		unsecure, _, err := cfg.Get(scope.Store, ConfigPathWebUnsecureBaseURL).Str()
+		if err != nil {
+			return b, errors.Wrapf(err, "[store] URLEnumerator failed to read route %q for store ID %d", ConfigPathWebUnsecureBaseURL, st.StoreID)
+		}
+		baseURL = unsecure + baseURL[len(placeholderUnsecureBaseURL):]
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	b.baseURL = baseURL
+
+	locale, _, err := cfg.Get(scope.Store, ConfigPathGeneralLocaleCode).Str()
+	if err != nil {
+		return b, errors.Wrapf(err, "[store] URLEnumerator failed to read route %q for store ID %d", ConfigPathGeneralLocaleCode, st.StoreID)
+	}
+	b.hreflang = strings.Replace(locale, "_", "-", -1)
+
+	if b.suppressed, _, err = cfg.Get(scope.Store, ConfigPathWebSEOSuppressAlternates).Bool(); err != nil {
+		return b, errors.Wrapf(err, "[store] URLEnumerator failed to read route %q for store ID %d", ConfigPathWebSEOSuppressAlternates, st.StoreID)
+	}
+
+	ue.bases[st.StoreID] = b
+	return b, nil
+}
+
+// StoreURLs returns the URLs of the resource path in all active and not
+// suppressed store views in the order of the store Service.
+func (ue *URLEnumerator) StoreURLs(path string) ([]StoreURL, error) {
+	path = strings.TrimLeft(path, "/")
+	stores := ue.stores.Stores()
+	ret := make([]StoreURL, 0, len(stores.Data))
+	for _, st := range stores.Data {
+		if st.StoreID == 0 || !st.IsActive {
+			continue
+		}
+		b, err := ue.base(st)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if b.suppressed {
+			continue
+		}
+		ret = append(ret, StoreURL{
+			StoreID:  st.StoreID,
+			Code:     st.Code,
+			Hreflang: b.hreflang,
+			URL:      b.baseURL + path,
+		})
+	}
+	return ret, nil
+}
+
+// Hreflang returns the alternate URLs of the resource path for the HTML link
+// elements with rel="alternate". Store views without a locale get skipped and
+// when several store views share the same locale only the first one gets
+// used. The last entry contains the x-default URL of the default store view,
+// if the default store view has not been suppressed.
+func (ue *URLEnumerator) Hreflang(path string) ([]StoreURL, error) {
+	urls, err := ue.StoreURLs(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ue.hreflang(urls)
+}
+
+func (ue *URLEnumerator) hreflang(urls []StoreURL) ([]StoreURL, error) {
+	dsv, err := ue.stores.DefaultStoreView()
+	if err != nil && !errors.NotFound.Match(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	ret := make([]StoreURL, 0, len(urls)+1)
+	seen := make(map[string]bool, len(urls))
+	var xDefault StoreURL
+	for _, u := range urls {
+		if dsv != nil && u.StoreID == dsv.StoreID {
+			xDefault = u
+			xDefault.Hreflang = HreflangXDefault
+		}
+		if u.Hreflang == "" || seen[u.Hreflang] {
+			continue
+		}
+		seen[u.Hreflang] = true
+		ret = append(ret, u)
+	}
+	if xDefault.URL != "" {
+		ret = append(ret, xDefault)
+	}
+	return ret, nil
+}
+
+// Sitemap returns for each resource path and each store view a sitemap entry
+// including the hreflang alternates.
+func (ue *URLEnumerator) Sitemap(paths ...string) ([]SitemapEntry, error) {
+	var ret []SitemapEntry
+	for _, p := range paths {
+		urls, err := ue.StoreURLs(p)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		alts, err := ue.hreflang(urls)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, u := range urls {
+			ret = append(ret, SitemapEntry{
+				StoreID:    u.StoreID,
+				Loc:        u.URL,
+				Alternates: alts,
+			})
+		}
+	}
+	return ret, nil
+}
+
+// ClearCache resets the cached base URLs, locales and suppression flags. Must
+// be called when the configuration or the store structure changes.
+func (ue *URLEnumerator) ClearCache() {
+	ue.mu.Lock()
+	ue.bases = make(map[uint32]storeURLBase)
+	ue.mu.Unlock()
+}
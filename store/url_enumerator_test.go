@@ -0,0 +1,101 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/config"
+	"github.com/corestoreio/pkg/config/storage"
+	"github.com/corestoreio/pkg/store"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestURLEnumerator(t *testing.T) {
+	srv := store.MustNewService(
+		store.WithWebsites(
+			&store.StoreWebsite{WebsiteID: 0, Code: "admin"},
+			&store.StoreWebsite{WebsiteID: 1, Code: "euro", DefaultGroupID: 1, IsDefault: true},
+			&store.StoreWebsite{WebsiteID: 2, Code: "oz", DefaultGroupID: 2},
+		),
+		store.WithGroups(
+			&store.StoreGroup{GroupID: 0, WebsiteID: 0, DefaultStoreID: 0, Code: "admin"},
+			&store.StoreGroup{GroupID: 1, WebsiteID: 1, DefaultStoreID: 1, Code: "dach"},
+			&store.StoreGroup{GroupID: 2, WebsiteID: 2, DefaultStoreID: 4, Code: "oz"},
+		),
+		store.WithStores(
+			&store.Store{StoreID: 0, WebsiteID: 0, GroupID: 0, Code: "admin", IsActive: true},
+			&store.Store{StoreID: 1, WebsiteID: 1, GroupID: 1, Code: "de", IsActive: true},
+			&store.Store{StoreID: 2, WebsiteID: 1, GroupID: 1, Code: "at", IsActive: true},
+			&store.Store{StoreID: 3, WebsiteID: 1, GroupID: 1, Code: "ch", IsActive: true},
+			&store.Store{StoreID: 4, WebsiteID: 2, GroupID: 2, Code: "au", IsActive: true},
+			&store.Store{StoreID: 5, WebsiteID: 2, GroupID: 2, Code: "nz", IsActive: false},
+		),
+	)
+
+	cfg := config.NewFakeService(storage.NewMap(
+		"default/0/"+store.ConfigPathWebUnsecureBaseURL, "http://shop.test/",
+		"default/0/"+store.ConfigPathWebSecureBaseURL, "{{unsecure_base_url}}",
+		"default/0/"+store.ConfigPathGeneralLocaleCode, "de_DE",
+		"stores/2/"+store.ConfigPathWebSecureBaseURL, "https://shop.test/at",
+		"stores/2/"+store.ConfigPathGeneralLocaleCode, "de_AT",
+		"stores/3/"+store.ConfigPathWebSEOSuppressAlternates, "1",
+		"websites/2/"+store.ConfigPathWebSecureBaseURL, "https://shop.test.au/",
+		"websites/2/"+store.ConfigPathGeneralLocaleCode, "en_AU",
+	))
+	ue := store.NewURLEnumerator(srv, cfg)
+
+	t.Run("StoreURLs", func(t *testing.T) {
+		urls, err := ue.StoreURLs("/shoes/running.html")
+		assert.NoError(t, err)
+		assert.Exactly(t, []store.StoreURL{
+			{StoreID: 1, Code: "de", Hreflang: "de-DE", URL: "http://shop.test/shoes/running.html"},
+			{StoreID: 2, Code: "at", Hreflang: "de-AT", URL: "https://shop.test/at/shoes/running.html"},
+			{StoreID: 4, Code: "au", Hreflang: "en-AU", URL: "https://shop.test.au/shoes/running.html"},
+		}, urls)
+	})
+	t.Run("Hreflang", func(t *testing.T) {
+		urls, err := ue.Hreflang("shoes.html")
+		assert.NoError(t, err)
+		assert.Exactly(t, []store.StoreURL{
+			{StoreID: 1, Code: "de", Hreflang: "de-DE", URL: "http://shop.test/shoes.html"},
+			{StoreID: 2, Code: "at", Hreflang: "de-AT", URL: "https://shop.test/at/shoes.html"},
+			{StoreID: 4, Code: "au", Hreflang: "en-AU", URL: "https://shop.test.au/shoes.html"},
+			{StoreID: 1, Code: "de", Hreflang: store.HreflangXDefault, URL: "http://shop.test/shoes.html"},
+		}, urls)
+	})
+	t.Run("Sitemap", func(t *testing.T) {
+		entries, err := ue.Sitemap("a.html", "b.html")
+		assert.NoError(t, err)
+		assert.Len(t, entries, 6)
+		assert.Exactly(t, "https://shop.test.au/b.html", entries[5].Loc)
+		assert.Exactly(t, uint32(4), entries[5].StoreID)
+		assert.Len(t, entries[5].Alternates, 4)
+		assert.Exactly(t, "https://shop.test/at/b.html", entries[5].Alternates[1].URL)
+	})
+	t.Run("base URL not found", func(t *testing.T) {
+		ue2 := store.NewURLEnumerator(srv, config.NewFakeService(storage.NewMap()))
+		urls, err := ue2.StoreURLs("a.html")
+		assert.True(t, errors.NotFound.Match(err), "%+v", err)
+		assert.Nil(t, urls)
+	})
+	t.Run("ClearCache", func(t *testing.T) {
+		ue.ClearCache()
+		urls, err := ue.StoreURLs("a.html")
+		assert.NoError(t, err)
+		assert.Len(t, urls, 3)
+	})
+}
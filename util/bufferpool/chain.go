@@ -0,0 +1,173 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufferpool
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxChainPoolLength defines the longest chain which gets served by the
+// global pools. Longer chains get allocated on each call to GetChain.
+const maxChainPoolLength = 8
+
+var chainBufferPools [maxChainPoolLength + 1]chainTank
+
+func init() {
+	for n := 1; n <= maxChainPoolLength; n++ {
+		chainBufferPools[n] = NewChain(n, 1024) // estimated *cough* average size
+	}
+}
+
+// BufferChain contains N coordinated buffers. It generalizes TwinBuffer for
+// multi-stage processing where each stage reads from one buffer and writes
+// into the next one.
+type BufferChain struct {
+	Buffers []*bytes.Buffer
+}
+
+// Len returns the number of buffers in the chain.
+func (bc *BufferChain) Len() int {
+	return len(bc.Buffers)
+}
+
+// Buf returns the buffer at index i. Panics if i is out of range.
+func (bc *BufferChain) Buf(i int) *bytes.Buffer {
+	return bc.Buffers[i]
+}
+
+// String prints the buffers content for debug purposes.
+func (bc *BufferChain) String() string {
+	var sb strings.Builder
+	for i, b := range bc.Buffers {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%q", b.String())
+	}
+	return sb.String()
+}
+
+// Write writes to all buffers one after another.
+func (bc *BufferChain) Write(p []byte) (n int, err error) {
+	for _, b := range bc.Buffers {
+		n, err = b.Write(p)
+		if err != nil {
+			return
+		}
+		if n != len(p) {
+			return 0, io.ErrShortWrite
+		}
+	}
+	return n, err
+}
+
+// CopyTo resets the buffer at index j and copies the content from the buffer
+// at index i to the buffer at index j. Buffer i gets eventually reset. Copying
+// a buffer to itself is a no-op.
+func (bc *BufferChain) CopyTo(i, j int) (n int64, err error) {
+	if i == j {
+		return int64(bc.Buffers[i].Len()), nil
+	}
+	bc.Buffers[j].Reset()
+	n, err = bc.Buffers[i].WriteTo(bc.Buffers[j])
+	bc.Buffers[i].Reset()
+	return
+}
+
+// Reset resets all buffers.
+func (bc *BufferChain) Reset() {
+	for _, b := range bc.Buffers {
+		b.Reset()
+	}
+}
+
+func (bc *BufferChain) exceedsMaxSize() bool {
+	const maxSize = 1 << 16 // 64KiB, see PutTwin
+	for _, b := range bc.Buffers {
+		if b.Cap() > maxSize {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChain returns a buffer chain containing n buffers from the pool. Chains
+// with more than eight buffers do not get pooled. Panics if n is smaller than
+// one.
+func GetChain(n int) *BufferChain {
+	if n < 1 {
+		panic(fmt.Sprintf("[bufferpool] GetChain requires at least one buffer, got %d", n))
+	}
+	if n > maxChainPoolLength {
+		return newBufferChain(n, 1024)
+	}
+	return chainBufferPools[n].Get()
+}
+
+// PutChain returns a buffer chain to the pool. The buffers get reset before
+// they are put back into circulation.
+func PutChain(bc *BufferChain) {
+	n := bc.Len()
+	if n < 1 || n > maxChainPoolLength || bc.exceedsMaxSize() {
+		return
+	}
+	chainBufferPools[n].Put(bc)
+}
+
+// chainTank implements a sync.Pool for BufferChain
+type chainTank struct {
+	p *sync.Pool
+}
+
+// Get returns type safe a buffer chain
+func (t chainTank) Get() *BufferChain {
+	return t.p.Get().(*BufferChain)
+}
+
+// Put empties the buffer chain and returns it back to the pool.
+//
+//		bp := NewChain(3, 512)
+//		buf := bp.Get()
+//		defer bp.Put(buf)
+func (t chainTank) Put(bc *BufferChain) {
+	bc.Reset()
+	t.p.Put(bc)
+}
+
+// NewChain instantiates a new BufferChain pool with n buffers and a custom
+// pre-allocated buffer size. All buffers will have the same size.
+func NewChain(n, size int) chainTank {
+	return chainTank{
+		p: &sync.Pool{
+			New: func() interface{} {
+				return newBufferChain(n, size)
+			},
+		},
+	}
+}
+
+func newBufferChain(n, size int) *BufferChain {
+	bc := &BufferChain{
+		Buffers: make([]*bytes.Buffer, n),
+	}
+	for i := range bc.Buffers {
+		bc.Buffers[i] = bytes.NewBuffer(make([]byte, 0, size))
+	}
+	return bc
+}
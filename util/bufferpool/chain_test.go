@@ -0,0 +1,90 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufferpool_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/corestoreio/pkg/util/bufferpool"
+)
+
+func TestBufferChain_CopyTo(t *testing.T) {
+	t.Parallel()
+
+	bc := bufferpool.GetChain(3)
+	defer bufferpool.PutChain(bc)
+	assert.Exactly(t, 3, bc.Len())
+
+	data := []byte(`SELECT * FROM t WHERE id IN ?`)
+	_, err := bc.Buf(0).Write(data)
+	assert.NoError(t, err)
+
+	n, err := bc.CopyTo(0, 2)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(len(data)), n)
+	assert.Exactly(t, "", bc.Buf(0).String())
+	assert.Exactly(t, string(data), bc.Buf(2).String())
+
+	n, err = bc.CopyTo(2, 2)
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(len(data)), n)
+	assert.Exactly(t, string(data), bc.Buf(2).String())
+
+	_, err = bc.CopyTo(2, 1)
+	assert.NoError(t, err)
+	assert.Exactly(t, "\"\"\n\"SELECT * FROM t WHERE id IN ?\"\n\"\"", bc.String())
+}
+
+func TestBufferChain_Write_Reset(t *testing.T) {
+	t.Parallel()
+
+	bc := bufferpool.GetChain(4)
+	defer bufferpool.PutChain(bc)
+
+	n, err := bc.Write([]byte("S1"))
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, n)
+	for i := 0; i < bc.Len(); i++ {
+		assert.Exactly(t, "S1", bc.Buf(i).String())
+	}
+	bc.Reset()
+	for i := 0; i < bc.Len(); i++ {
+		assert.Exactly(t, 0, bc.Buf(i).Len())
+	}
+}
+
+func TestGetChain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unpooled long chain", func(t *testing.T) {
+		bc := bufferpool.GetChain(12)
+		assert.Exactly(t, 12, bc.Len())
+		bufferpool.PutChain(bc)
+	})
+	t.Run("panics on zero", func(t *testing.T) {
+		defer func() {
+			r := recover()
+			assert.NotNil(t, r)
+		}()
+		_ = bufferpool.GetChain(0)
+	})
+	t.Run("custom pool", func(t *testing.T) {
+		bp := bufferpool.NewChain(2, 512)
+		bc := bp.Get()
+		defer bp.Put(bc)
+		assert.Exactly(t, 512, bc.Buf(1).Cap())
+	})
+}
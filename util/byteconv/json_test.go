@@ -0,0 +1,47 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package byteconv
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"Gopher",
+		`Say "Hello" \ World`,
+		"Tab\tNew\nLine\rReturn\x00\x1f",
+		"Umlaut äöü € 🐹",
+		"Line\u2028Para\u2029",
+		"invalid \xff\xfe UTF-8",
+	} {
+		have := AppendJSONString([]byte(`prefix`), s)
+		assert.Exactly(t, "prefix", string(have[:6]), "Input %q", s)
+
+		var want string
+		assert.NoError(t, json.Unmarshal(have[6:], &want), "Input %q: %s", s, have)
+		wantJSON, err := json.Marshal(s)
+		assert.NoError(t, err)
+		var wantDecoded string
+		assert.NoError(t, json.Unmarshal(wantJSON, &wantDecoded))
+		assert.Exactly(t, wantDecoded, want, "Input %q", s)
+	}
+
+	assert.Exactly(t, `"a\"b\\c\n\u0001<>&"`, string(AppendJSONString(nil, "a\"b\\c\n\x01<>&")))
+}
@@ -16,6 +16,12 @@ package codegen
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/corestoreio/errors"
@@ -47,14 +53,148 @@ func (g *common) AddImport(importPath, packageName string) {
 	g.packageNames[importPath] = packageName
 }
 
-// AddImports adds multiple import paths at once. They all must have unique base
-// names.
+// AddImports adds multiple import paths at once. Import paths with the same
+// base name get an alias assigned, see function ImportName.
 func (g *common) AddImports(importPaths ...string) {
 	for _, ip := range importPaths {
 		g.packageNames[ip] = ""
 	}
 }
 
+// ImportName returns the identifier to reference the package of the import path
+// in the generated code. Import paths without a package name and with the same
+// base name get an alias assigned. In the sorted list of import paths the first
+// one keeps its base name, the others get the parent directory as prefix, e.g.
+// "github.com/corestoreio/errors" and "github.com/pkg/errors" result in errors
+// and pkgerrors. The aliases depend only on the set of the import paths, so the
+// result is stable but must be requested after all imports have been added.
+func (g *common) ImportName(importPath string) string {
+	_, aliases := g.canonicalImports()
+	if a := aliases[importPath]; a != "" {
+		return a
+	}
+	return importBaseName(importPath)
+}
+
+// canonicalImports returns the sorted import paths and the alias for each path
+// which requires one. Explicit package names get always used as alias.
+func (g *common) canonicalImports() (paths []string, aliases map[string]string) {
+	paths = make([]string, 0, len(g.packageNames))
+	for p := range g.packageNames {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	aliases = make(map[string]string, len(paths))
+	used := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if a := g.packageNames[p]; a != "" {
+			aliases[p] = a
+			used[a] = true
+		}
+	}
+	for _, p := range paths {
+		if g.packageNames[p] != "" {
+			continue
+		}
+		name := importBaseName(p)
+		if !used[name] {
+			used[name] = true
+			continue
+		}
+		alias := importBaseName(path.Dir(p)) + name
+		for i := 2; used[alias]; i++ {
+			alias = importBaseName(path.Dir(p)) + name + strconv.Itoa(i)
+		}
+		used[alias] = true
+		aliases[p] = alias
+	}
+	return paths, aliases
+}
+
+// importBaseName guesses the package name of an import path. Major version
+// suffixes, the prefix "go-" and the suffix "-go" get removed, e.g.
+// gopkg.in/yaml.v2, github.com/x/y/v3 or github.com/DATA-DOG/go-sqlmock.
+func importBaseName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	if pos := strings.LastIndex(name, ".v"); pos > 0 && isMajorVersion(name[pos+1:]) {
+		name = name[:pos]
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// verifyUnchanged generates the file into a buffer and compares it with the
+// content of r.
+func verifyUnchanged(generate func(io.Writer) error, r io.Reader) error {
+	var have bytes.Buffer
+	if err := generate(&have); err != nil {
+		return errors.WithStack(err)
+	}
+	want, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if bytes.Equal(want, have.Bytes()) {
+		return nil
+	}
+	return errors.Mismatch.Newf("[codegen] The generated code differs from the existing code:\n%s", lineDiff(want, have.Bytes()))
+}
+
+// lineDiff writes the lines between the common prefix and the common suffix of
+// want and have. Lines of want start with a minus and lines of have with a
+// plus. Each side gets truncated after 20 lines.
+func lineDiff(want, have []byte) string {
+	wl := strings.Split(string(want), "\n")
+	hl := strings.Split(string(have), "\n")
+	prefix := 0
+	for prefix < len(wl) && prefix < len(hl) && wl[prefix] == hl[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(wl)-prefix && suffix < len(hl)-prefix && wl[len(wl)-1-suffix] == hl[len(hl)-1-suffix] {
+		suffix++
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "@@ line %d @@\n", prefix+1)
+	write := func(sign string, lines []string) {
+		for i, l := range lines {
+			if i == 20 {
+				fmt.Fprintf(&buf, "%s ... %d more lines\n", sign, len(lines)-i)
+				return
+			}
+			buf.WriteString(sign)
+			buf.WriteString(l)
+			buf.WriteByte('\n')
+		}
+	}
+	write("-", wl[prefix:len(wl)-suffix])
+	write("+", hl[prefix:len(hl)-suffix])
+	return buf.String()
+}
+
 // Writes a multiline comment and formats it to a max width of 80 chars. It adds
 // automatically the comment prefix `//`. It converts all types to string, if it
 // can't it panics. If the first argument is a boolean and true, the subsequent
@@ -72,13 +72,9 @@ func (g *Go) generateInitFunction() {
 
 func (g *Go) generateImports(w io.Writer) {
 	fmt.Fprintln(w, "import (")
-	pkgSorted := make([]string, 0, len(g.packageNames))
-	for key := range g.packageNames {
-		pkgSorted = append(pkgSorted, key)
-	}
-	sort.Strings(pkgSorted)
-	for _, p := range pkgSorted {
-		fmt.Fprintf(w, "\t%s %q\n", g.packageNames[p], p)
+	paths, aliases := g.canonicalImports()
+	for _, p := range paths {
+		fmt.Fprintf(w, "\t%s %q\n", aliases[p], p)
 	}
 	fmt.Fprintln(w, ")")
 }
@@ -101,7 +97,7 @@ func (g *Go) GenerateFile(w io.Writer) error {
 	g.generateImports(&buf)
 	g.generateInitFunction()
 
-	g.Buffer.WriteTo(&buf)
+	buf.Write(g.Buffer.Bytes()) // keeps the Buffer for repeated calls
 
 	fmted, err := format.Source(buf.Bytes())
 	if err != nil {
@@ -113,3 +109,11 @@ func (g *Go) GenerateFile(w io.Writer) error {
 	_, err = w.Write(fmted)
 	return err
 }
+
+// VerifyUnchanged generates the file into a buffer and compares it with the
+// content of r, usually the committed file. Returns an error of kind Mismatch
+// containing the differing lines, if the generated code has changed. Can be
+// used in tests or in CI to check that the committed code is up to date.
+func (g *Go) VerifyUnchanged(r io.Reader) error {
+	return verifyUnchanged(g.GenerateFile, r)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/corestoreio/errors"
 	"github.com/corestoreio/pkg/util/assert"
 	"github.com/corestoreio/pkg/util/codegen"
 )
@@ -82,3 +83,56 @@ type CatalogProductEntity struct {
 }
 `, buf.String())
 }
+
+func TestGo_ImportName(t *testing.T) {
+	t.Parallel()
+
+	newGo := func(paths ...string) *codegen.Go {
+		g := codegen.NewGo("config")
+		g.AddImports(paths...)
+		return g
+	}
+	// the insertion order must not change the aliases
+	for _, g := range []*codegen.Go{
+		newGo("github.com/pkg/errors", "github.com/corestoreio/errors", "gopkg.in/yaml.v2", "github.com/DATA-DOG/go-sqlmock"),
+		newGo("github.com/DATA-DOG/go-sqlmock", "gopkg.in/yaml.v2", "github.com/corestoreio/errors", "github.com/pkg/errors"),
+	} {
+		g.AddImport("github.com/corestoreio/pkg/storage/null", "null")
+		assert.Exactly(t, "errors", g.ImportName("github.com/corestoreio/errors"))
+		assert.Exactly(t, "pkgerrors", g.ImportName("github.com/pkg/errors"))
+		assert.Exactly(t, "yaml", g.ImportName("gopkg.in/yaml.v2"))
+		assert.Exactly(t, "sqlmock", g.ImportName("github.com/DATA-DOG/go-sqlmock"))
+		assert.Exactly(t, "null", g.ImportName("github.com/corestoreio/pkg/storage/null"))
+
+		var buf bytes.Buffer
+		assert.NoError(t, g.GenerateFile(&buf))
+		assert.Exactly(t, `// Code generated by codegen. DO NOT EDIT.
+package config
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/corestoreio/errors"
+	null "github.com/corestoreio/pkg/storage/null"
+	pkgerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+`, buf.String())
+	}
+}
+
+func TestGo_VerifyUnchanged(t *testing.T) {
+	t.Parallel()
+
+	g := codegen.NewGo("config")
+	g.AddImport("fmt", "")
+	g.Pln(`func Hello() { fmt.Println("Hello") }`)
+
+	var buf bytes.Buffer
+	assert.NoError(t, g.GenerateFile(&buf))
+	assert.NoError(t, g.VerifyUnchanged(bytes.NewReader(buf.Bytes())), "regeneration must be idempotent")
+
+	changed := bytes.Replace(buf.Bytes(), []byte(`"Hello"`), []byte(`"Bye"`), 1)
+	err := g.VerifyUnchanged(bytes.NewReader(changed))
+	assert.ErrorIsKind(t, errors.Mismatch, err)
+	assert.Contains(t, err.Error(), "@@ line 8 @@\n-func Hello() { fmt.Println(\"Bye\") }\n+func Hello() { fmt.Println(\"Hello\") }\n")
+}
@@ -75,8 +75,14 @@ func (g *Proto) GenerateFile(w io.Writer) error {
 	g.generateImports(&buf)
 	g.generateOptions(&buf)
 
-	g.Buffer.WriteTo(&buf)
+	buf.Write(g.Buffer.Bytes()) // keeps the Buffer for repeated calls
 
 	_, err := buf.WriteTo(w)
 	return err
 }
+
+// VerifyUnchanged generates the file into a buffer and compares it with the
+// content of r. See Go.VerifyUnchanged.
+func (g *Proto) VerifyUnchanged(r io.Reader) error {
+	return verifyUnchanged(g.GenerateFile, r)
+}
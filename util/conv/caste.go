@@ -140,6 +140,12 @@ func ToBoolE(i interface{}) (bool, error) {
 			return false, errors.NotValid.Newf("[conv] Unable to cast %#v to bool", i)
 		}
 		return b2, nil
+	case []byte:
+		b2, ok, err := ParseBool(b)
+		if err != nil || !ok {
+			return false, errors.NotValid.Newf("[conv] Unable to cast %#v to bool", i)
+		}
+		return b2, nil
 	case iFacer:
 		return b.ToBool(), nil
 	default:
@@ -185,11 +191,11 @@ func ToFloat64E(i interface{}) (float64, error) {
 	case []byte:
 		// real byte encoded floats will fail here
 		// @see https://github.com/golang/go/issues/2632
-		v, err := strconv.ParseFloat(string(s), 64)
-		if err == nil {
-			return float64(v), nil
+		v, ok, err := ParseFloat64(s)
+		if err == nil && ok {
+			return v, nil
 		}
-		return 0.0, errors.NotValid.Newf("[conv] Unable to cast %#v to float. %s", i, err)
+		return 0.0, errors.NotValid.Newf("[conv] Unable to cast %#v to float. %v", i, err)
 	default:
 		return 0.0, errors.NotValid.Newf("[conv] Unable to cast %#v to float", i)
 	}
@@ -330,6 +336,12 @@ func ToInt64E(i interface{}) (int64, error) {
 			return v, nil
 		}
 		return 0, errors.NotValid.Newf("[conv] Unable to cast %#v to int64. %s", i, err)
+	case []byte:
+		v, ok, err := ParseInt64(s)
+		if err == nil && ok {
+			return v, nil
+		}
+		return 0, errors.NotValid.Newf("[conv] Unable to cast %#v to int64. %v", i, err)
 	case float64:
 		return int64(s), nil
 	case float32:
@@ -0,0 +1,91 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conv
+
+import (
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/byteconv"
+)
+
+// ParseDateTime parses the MySQL/MariaDB formats of the types DATE, DATETIME
+// and TIMESTAMP with up to nine fractional digits and an optional time zone
+// offset, also with the RFC3339 `T` separator. Argument `loc` gets applied to
+// the wall clock of the parsed time, if nil, UTC is used. An empty string or a
+// zero date like 0000-00-00 returns ok false. Shared by dml.ColumnMap, the null
+// types and the config service.
+func ParseDateTime(str string, loc *time.Location) (t time.Time, ok bool, err error) {
+	if str == "" {
+		return t, false, nil
+	}
+	const zeroBase = "0000-00-00 00:00:00.000000000+00:00"
+	base := "2006-01-02 15:04:05.999999999 07:00"
+	if strings.IndexByte(str, 'T') > 0 {
+		base = time.RFC3339Nano
+	}
+
+	switch lStr := len(str); lStr {
+	case 10, 19, 21, 22, 23, 24, 25, 26, 27, 28, 29, 35: // up to "YYYY-MM-DD HH:MM:SS.MMMMMMM+HH:II"
+		if str == zeroBase[:lStr] {
+			return t, false, nil
+		}
+		if t, err = time.Parse(base[:lStr], str); err != nil {
+			return t, false, errors.WithStack(err)
+		}
+	default:
+		return t, false, errors.NotValid.Newf("[conv] Invalid length %d in time string: %q", lStr, str)
+	}
+
+	if loc != nil && loc != time.UTC {
+		y, mo, d := t.Date()
+		h, mi, s := t.Clock()
+		t = time.Date(y, mo, d, h, mi, s, t.Nanosecond(), loc)
+	}
+	return t, true, nil
+}
+
+// ParseInt64 parses the text representation of a MySQL/MariaDB integer without
+// allocations. Empty data or NULL returns ok false.
+func ParseInt64(data []byte) (i int64, ok bool, err error) {
+	return byteconv.ParseInt(data)
+}
+
+// ParseUint64 parses the text representation of a MySQL/MariaDB unsigned
+// integer without allocations. Empty data or NULL returns ok false.
+func ParseUint64(data []byte) (i uint64, ok bool, err error) {
+	return ParseUint(data, 64)
+}
+
+// ParseUint parses the text representation of a MySQL/MariaDB unsigned
+// integer which must fit into bitSize, e.g. 8 for a TINYINT UNSIGNED. Empty
+// data or NULL returns ok false.
+func ParseUint(data []byte, bitSize int) (i uint64, ok bool, err error) {
+	return byteconv.ParseUint(data, 10, bitSize)
+}
+
+// ParseFloat64 parses the text representation of a MySQL/MariaDB float or
+// decimal without allocations. Empty data or NULL returns ok false.
+func ParseFloat64(data []byte) (f float64, ok bool, err error) {
+	return byteconv.ParseFloat(data)
+}
+
+// ParseBool parses the text representation of a MySQL/MariaDB boolean, e.g.
+// 0, 1, true or false, without allocations. Empty data or NULL returns ok
+// false.
+func ParseBool(data []byte) (b bool, ok bool, err error) {
+	return byteconv.ParseBool(data)
+}
@@ -0,0 +1,113 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func TestParseDateTime(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone data not available: %s", err)
+	}
+
+	tests := []struct {
+		have    string
+		loc     *time.Location
+		want    time.Time
+		wantOK  bool
+		wantErr errors.Kind
+	}{
+		{"", nil, time.Time{}, false, errors.NoKind},
+		{"0000-00-00", nil, time.Time{}, false, errors.NoKind},
+		{"0000-00-00 00:00:00", nil, time.Time{}, false, errors.NoKind},
+		{"2019-03-04", nil, time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC), true, errors.NoKind},
+		{"2019-03-04 05:06:07", time.UTC, time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC), true, errors.NoKind},
+		{"2019-03-04 05:06:07.123456", nil, time.Date(2019, 3, 4, 5, 6, 7, 123456000, time.UTC), true, errors.NoKind},
+		{"2019-03-04T05:06:07", nil, time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC), true, errors.NoKind},
+		{"2019-03-04 05:06:07", berlin, time.Date(2019, 3, 4, 5, 6, 7, 0, berlin), true, errors.NoKind},
+		{"2019-03-04 05:06", nil, time.Time{}, false, errors.NotValid},
+	}
+	_, ok, err := ParseDateTime("2019-13-04 05:06:07", nil)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	for _, test := range tests {
+		got, ok, err := ParseDateTime(test.have, test.loc)
+		if !test.wantErr.Empty() {
+			assert.ErrorIsKind(t, test.wantErr, err, "Input %q", test.have)
+			assert.False(t, ok, "Input %q", test.have)
+			continue
+		}
+		assert.NoError(t, err, "Input %q", test.have)
+		assert.Exactly(t, test.wantOK, ok, "Input %q", test.have)
+		assert.True(t, test.want.Equal(got), "Input %q: want %s got %s", test.have, test.want, got)
+		assert.Exactly(t, test.want.Location().String(), got.Location().String(), "Input %q", test.have)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	t.Parallel()
+
+	i64, ok, err := ParseInt64([]byte("-123"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Exactly(t, int64(-123), i64)
+
+	u64, ok, err := ParseUint64([]byte("18446744073709551615"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Exactly(t, uint64(18446744073709551615), u64)
+
+	u64, ok, err = ParseUint([]byte("255"), 8)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Exactly(t, uint64(255), u64)
+	_, _, err = ParseUint([]byte("256"), 8)
+	assert.Error(t, err)
+
+	f64, ok, err := ParseFloat64([]byte("3.5"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Exactly(t, 3.5, f64)
+
+	b, ok, err := ParseBool([]byte("1"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, b)
+
+	_, ok, err = ParseInt64(nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	v, err := ToInt64E([]byte("42"))
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(42), v)
+	_, err = ToInt64E([]byte("4x2"))
+	assert.ErrorIsKind(t, errors.NotValid, err)
+
+	bv, err := ToBoolE([]byte("true"))
+	assert.NoError(t, err)
+	assert.True(t, bv)
+	_, err = ToBoolE([]byte("maybe"))
+	assert.ErrorIsKind(t, errors.NotValid, err)
+}
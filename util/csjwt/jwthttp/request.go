@@ -27,6 +27,9 @@ const HTTPHeaderAuthorization = `Authorization`
 // HTTPFormInputName default name for the HTML form field name
 const HTTPFormInputName = `access_token`
 
+// HTTPHeaderDPoP identifies the proof of possession in this header key.
+const HTTPHeaderDPoP = `DPoP`
+
 // NewVerification creates new verification parser with the default signing
 // method HS256, if availableSigners slice argument is empty. Nil arguments are
 // forbidden.
@@ -51,13 +54,43 @@ type Verification struct {
 	// ExtractToken method should return a token string or an error.
 	// This function can be nil
 	ExtractTokenFn func(*http.Request) (string, error)
+	// Proof if set, verifies the proof of possession in header HTTPHeaderDPoP
+	// for bound tokens. See csjwt.BindToken and csjwt.NewProof.
+	Proof *csjwt.ProofVerification
+	// ProofURLFn returns the URL of the request for the proof verification.
+	// If nil, the URL gets built from the request. Must be set if a TLS
+	// terminating proxy runs in front of the application.
+	ProofURLFn func(*http.Request) string
 }
 
 // ParseFromRequest same as Parse but extracts the token from a request. First
 // it searches for the token bearer in the header HTTPHeaderAuthorization. If
 // not found the request POST form gets parsed and the FormInputName gets used
-// to lookup the token value.
+// to lookup the token value. If field Proof has been set, the proof of
+// possession gets verified for bound tokens.
 func (vf *Verification) ParseFromRequest(dst *csjwt.Token, keyFunc csjwt.Keyfunc, req *http.Request) error {
+	if err := vf.parseFromRequest(dst, keyFunc, req); err != nil || vf.Proof == nil {
+		return err
+	}
+	if err := vf.Proof.Verify([]byte(req.Header.Get(HTTPHeaderDPoP)), dst, req.Method, vf.proofURL(req)); err != nil {
+		dst.Valid = false
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (vf *Verification) proofURL(req *http.Request) string {
+	if vf.ProofURLFn != nil {
+		return vf.ProofURLFn(req)
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host + req.URL.Path
+}
+
+func (vf *Verification) parseFromRequest(dst *csjwt.Token, keyFunc csjwt.Keyfunc, req *http.Request) error {
 	if vf.ExtractTokenFn != nil {
 		tkn, err := vf.ExtractTokenFn(req)
 		if err != nil {
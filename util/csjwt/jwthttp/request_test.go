@@ -1,8 +1,12 @@
 package jwthttp_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
@@ -165,3 +169,48 @@ func TestVerification_ParseFromRequest_ExtractTokenFn(t *testing.T) {
 	}
 	assert.Exactly(t, `in the form dude!`, conv.ToString(where))
 }
+
+func TestVerification_ParseFromRequest_Proof(t *testing.T) {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	clientKey := csjwt.WithECPrivateKey(pk)
+	serverKey := csjwt.WithPassword([]byte(`csjwt.SigningMethodHS256!`))
+	hs256 := csjwt.NewSigningMethodHS256()
+
+	clm := jwtclaim.Map{"user_id": "hello_gophers"}
+	assert.NoError(t, csjwt.BindToken(clm, clientKey))
+	tokenString, err := csjwt.NewToken(clm).SignedString(hs256, serverKey)
+	assert.NoError(t, err)
+
+	vf := jwthttp.NewVerification(hs256)
+	vf.Proof = &csjwt.ProofVerification{}
+
+	newRequest := func(proofURL string) *http.Request {
+		r := httptest.NewRequest("POST", "http://shop.example.com/cart?id=3", nil)
+		r.Header.Set("Authorization", "Bearer "+string(tokenString))
+		if proofURL != "" {
+			proof, err := csjwt.NewProof(csjwt.NewSigningMethodES256(), clientKey, "POST", proofURL, tokenString)
+			assert.NoError(t, err)
+			r.Header.Set(jwthttp.HTTPHeaderDPoP, string(proof))
+		}
+		return r
+	}
+
+	t.Run("valid proof", func(t *testing.T) {
+		haveToken := csjwt.NewToken(&jwtclaim.Map{})
+		assert.NoError(t, vf.ParseFromRequest(haveToken, csjwt.NewKeyFunc(hs256, serverKey), newRequest("http://shop.example.com/cart")))
+		assert.True(t, haveToken.Valid)
+	})
+	t.Run("missing proof", func(t *testing.T) {
+		haveToken := csjwt.NewToken(&jwtclaim.Map{})
+		err := vf.ParseFromRequest(haveToken, csjwt.NewKeyFunc(hs256, serverKey), newRequest(""))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		assert.False(t, haveToken.Valid)
+	})
+	t.Run("proof for another URL", func(t *testing.T) {
+		haveToken := csjwt.NewToken(&jwtclaim.Map{})
+		err := vf.ParseFromRequest(haveToken, csjwt.NewKeyFunc(hs256, serverKey), newRequest("http://shop.example.com/account"))
+		assert.ErrorIsKind(t, errors.NotValid, err)
+		assert.False(t, haveToken.Valid)
+	})
+}
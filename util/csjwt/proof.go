@@ -0,0 +1,351 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csjwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/errors"
+)
+
+// Proof of possession, DPoP-style, see RFC 9449 and RFC 7800. A bound token
+// contains the confirmation claim "cnf" with the JWK SHA-256 thumbprint "jkt"
+// of the public key of the client. For each request the client creates a new
+// proof, a short lived JWT signed with its private key, which contains the
+// HTTP method, the URL and the hash of the token. A stolen bound token is
+// useless without the private key.
+const (
+	// ClaimConfirmation identifies the confirmation claim in a token.
+	ClaimConfirmation = `cnf`
+	// ConfirmationJWKThumbprint identifies the thumbprint of the public key
+	// within the confirmation claim.
+	ConfirmationJWKThumbprint = `jkt`
+	// ContentTypeProof defines the typ header of a proof.
+	ContentTypeProof = `dpop+jwt`
+	// DefaultProofMaxAge defines how long a proof is valid after its creation.
+	DefaultProofMaxAge = time.Minute
+)
+
+const errProofInvalid = `[csjwt] proof is invalid: %s`
+
+// JWK represents the public part of an ECDSA or RSA key as JSON Web Key as
+// defined in RFC 7517. It gets embedded into the header of a proof.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// NewJWK creates the JWK of the public ECDSA or RSA key. A private key gets
+// converted to its public key. Error behaviour: NotSupported.
+func NewJWK(key Key) (JWK, error) {
+	if key.Error != nil {
+		return JWK{}, errors.WithStack(key.Error)
+	}
+	ecPub, rsaPub := key.ecdsaKeyPub, key.rsaKeyPub
+	if key.ecdsaKeyPriv != nil {
+		ecPub = &key.ecdsaKeyPriv.PublicKey
+	}
+	if key.rsaKeyPriv != nil {
+		rsaPub = &key.rsaKeyPriv.PublicKey
+	}
+	switch {
+	case ecPub != nil:
+		size := (ecPub.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Crv: ecPub.Curve.Params().Name,
+			X:   string(EncodeSegment(ecPub.X.FillBytes(make([]byte, size)))),
+			Y:   string(EncodeSegment(ecPub.Y.FillBytes(make([]byte, size)))),
+		}, nil
+	case rsaPub != nil:
+		return JWK{
+			Kty: "RSA",
+			N:   string(EncodeSegment(rsaPub.N.Bytes())),
+			E:   string(EncodeSegment(big.NewInt(int64(rsaPub.E)).Bytes())),
+		}, nil
+	}
+	return JWK{}, errors.NotSupported.Newf("[csjwt] NewJWK requires an ECDSA or RSA key")
+}
+
+// Thumbprint returns the base64url encoded SHA-256 thumbprint of the key as
+// defined in RFC 7638.
+func (k JWK) Thumbprint() string {
+	var canonical string
+	switch k.Kty {
+	case "EC":
+		canonical = `{"crv":"` + k.Crv + `","kty":"EC","x":"` + k.X + `","y":"` + k.Y + `"}`
+	case "RSA":
+		canonical = `{"e":"` + k.E + `","kty":"RSA","n":"` + k.N + `"}`
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return string(EncodeSegment(sum[:]))
+}
+
+// Key converts the JWK into a public key for verification. Error behaviour:
+// NotValid, NotSupported.
+func (k JWK) Key() (Key, error) {
+	switch k.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return Key{}, errors.NotSupported.Newf("[csjwt] JWK curve %q not supported", k.Crv)
+		}
+		x, errX := DecodeSegment([]byte(k.X))
+		y, errY := DecodeSegment([]byte(k.Y))
+		if errX != nil || errY != nil {
+			return Key{}, errors.NotValid.Newf(errProofInvalid, "JWK coordinates")
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return Key{}, errors.NotValid.Newf(errProofInvalid, "JWK point not on curve")
+		}
+		return WithECPublicKey(pub), nil
+	case "RSA":
+		n, errN := DecodeSegment([]byte(k.N))
+		e, errE := DecodeSegment([]byte(k.E))
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return Key{}, errors.NotValid.Newf(errProofInvalid, "JWK modulus or exponent")
+		}
+		return WithRSAPublicKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}), nil
+	}
+	return Key{}, errors.NotSupported.Newf("[csjwt] JWK key type %q not supported", k.Kty)
+}
+
+// BindToken adds the confirmation claim with the thumbprint of the public key
+// of the client to the claims. The Claimer must support the key "cnf", like
+// jwtclaim.Map does. Argument key can be the public or private key of the
+// client.
+func BindToken(claims Claimer, key Key) error {
+	jwk, err := NewJWK(key)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(claims.Set(ClaimConfirmation, map[string]interface{}{
+		ConfirmationJWKThumbprint: jwk.Thumbprint(),
+	}))
+}
+
+// BoundThumbprint returns the thumbprint of the confirmation claim. Reports
+// false if the token is not bound to a key.
+func BoundThumbprint(claims Claimer) (string, bool) {
+	if claims == nil {
+		return "", false
+	}
+	v, err := claims.Get(ClaimConfirmation)
+	if err != nil {
+		return "", false
+	}
+	var jkt string
+	switch cnf := v.(type) {
+	case map[string]interface{}:
+		jkt, _ = cnf[ConfirmationJWKThumbprint].(string)
+	case map[string]string:
+		jkt = cnf[ConfirmationJWKThumbprint]
+	}
+	return jkt, jkt != ""
+}
+
+type proofHeader struct {
+	Typ string `json:"typ"`
+	Alg string `json:"alg"`
+	JWK *JWK   `json:"jwk"`
+}
+
+type proofClaims struct {
+	JTI string `json:"jti"`
+	HTM string `json:"htm"`
+	HTU string `json:"htu"`
+	IAT int64  `json:"iat"`
+	ATH string `json:"ath,omitempty"`
+}
+
+// accessTokenHash returns the base64url encoded SHA-256 hash of the token.
+func accessTokenHash(token []byte) string {
+	sum := sha256.Sum256(token)
+	return string(EncodeSegment(sum[:]))
+}
+
+// NewProof creates a proof for one HTTP request, signed with the private key
+// of the client. The client sends the proof in the DPoP header together with
+// the bound token. The signer must use an asymmetric algorithm. Argument
+// token can be empty when requesting a new token.
+func NewProof(s Signer, key Key, httpMethod, httpURL string, token []byte) ([]byte, error) {
+	jwk, err := NewJWK(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var jti [16]byte
+	if _, err := rand.Read(jti[:]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pc := proofClaims{
+		JTI: string(EncodeSegment(jti[:])),
+		HTM: httpMethod,
+		HTU: httpURL,
+		IAT: TimeFunc().Unix(),
+	}
+	if len(token) > 0 {
+		pc.ATH = accessTokenHash(token)
+	}
+	h, err := json.Marshal(proofHeader{Typ: ContentTypeProof, Alg: s.Alg(), JWK: &jwk})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	c, err := json.Marshal(pc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buf := append(EncodeSegment(h), '.')
+	buf = append(buf, EncodeSegment(c)...)
+	sig, err := s.Sign(buf, key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buf = append(buf, '.')
+	return append(buf, sig...), nil
+}
+
+// ProofVerification verifies the proof of a request against a bound token.
+type ProofVerification struct {
+	// Methods contains the allowed signing methods of a proof. If empty, all
+	// asymmetric methods of SigningMethodFactory are allowed.
+	Methods SignerSlice
+	// MaxAge defines how long a proof is valid after its creation. Defaults
+	// to DefaultProofMaxAge. Also used as tolerance for clock skew.
+	MaxAge time.Duration
+	// RequireBinding rejects tokens without the confirmation claim. If false,
+	// unbound tokens do not require a proof.
+	RequireBinding bool
+	// IsReplay optional function to detect reused proofs. Should report true
+	// if the jti has already been seen and store it for the duration.
+	IsReplay func(jti string, expires time.Duration) bool
+}
+
+// Verify checks the signature of the proof, that the proof matches the HTTP
+// method, the URL without query and fragment, the token and that the token has
+// been bound to the key of the proof. Error behaviour: NotValid, NotSupported.
+func (pv ProofVerification) Verify(proof []byte, token *Token, httpMethod, httpURL string) error {
+	jkt, bound := BoundThumbprint(token.Claims)
+	switch {
+	case !bound && !pv.RequireBinding:
+		return nil
+	case !bound:
+		return errors.NotValid.Newf(errProofInvalid, "token not bound to a key")
+	case len(proof) == 0:
+		return errors.NotValid.Newf(errProofInvalid, "missing proof")
+	}
+
+	pos, valid := dotPositions(proof)
+	if !valid {
+		return errors.NotValid.Newf(errTokenInvalidSegmentCounts)
+	}
+	var ph proofHeader
+	var pc proofClaims
+	if err := decodeProofSegment(proof[:pos[0]], &ph); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := decodeProofSegment(proof[pos[0]+1:pos[1]], &pc); err != nil {
+		return errors.WithStack(err)
+	}
+	if ph.Typ != ContentTypeProof || ph.JWK == nil {
+		return errors.NotValid.Newf(errProofInvalid, "header typ or jwk")
+	}
+
+	s, err := pv.method(ph.Alg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	key, err := ph.JWK.Key()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := s.Verify(proof[:pos[1]], proof[pos[1]+1:], key); err != nil {
+		return errors.NotValid.New(err, errProofInvalid, "signature")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(ph.JWK.Thumbprint()), []byte(jkt)) != 1 {
+		return errors.NotValid.Newf(errProofInvalid, "key does not match the bound token")
+	}
+	if subtle.ConstantTimeCompare([]byte(pc.ATH), []byte(accessTokenHash(token.Raw))) != 1 {
+		return errors.NotValid.Newf(errProofInvalid, "token hash mismatch")
+	}
+	if pc.HTM != httpMethod || stripQueryFragment(pc.HTU) != stripQueryFragment(httpURL) {
+		return errors.NotValid.Newf(errProofInvalid, "HTTP method or URL mismatch")
+	}
+	maxAge := pv.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultProofMaxAge
+	}
+	if d := TimeFunc().Sub(time.Unix(pc.IAT, 0)); d > maxAge || d < -maxAge {
+		return errors.NotValid.Newf(errProofInvalid, "expired or issued in the future")
+	}
+	if pc.JTI == "" || (pv.IsReplay != nil && pv.IsReplay(pc.JTI, 2*maxAge)) {
+		return errors.NotValid.Newf(errProofInvalid, "missing or reused jti")
+	}
+	return nil
+}
+
+func (pv ProofVerification) method(alg string) (Signer, error) {
+	if len(pv.Methods) > 0 {
+		for _, m := range pv.Methods {
+			if m.Alg() == alg {
+				return m, nil
+			}
+		}
+		return nil, errors.NotValid.Newf(errAlgorithmNotFound, alg, pv.Methods)
+	}
+	if strings.HasPrefix(alg, HS) {
+		return nil, errors.NotSupported.Newf(errProofInvalid, "symmetric algorithm")
+	}
+	return SigningMethodFactory(alg)
+}
+
+func decodeProofSegment(seg []byte, dst interface{}) error {
+	data, err := DecodeSegment(seg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return errors.NotValid.New(err, errTokenMalformed)
+	}
+	return nil
+}
+
+func stripQueryFragment(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		return u[:i]
+	}
+	return u
+}
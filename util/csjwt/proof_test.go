@@ -0,0 +1,131 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csjwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/corestoreio/pkg/util/csjwt"
+	"github.com/corestoreio/pkg/util/csjwt/jwtclaim"
+)
+
+func TestJWK_Thumbprint(t *testing.T) {
+	// Example from RFC 7638 section 3.1
+	n, err := csjwt.DecodeSegment([]byte("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"))
+	assert.NoError(t, err)
+	jwk, err := csjwt.NewJWK(csjwt.WithRSAPublicKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}))
+	assert.NoError(t, err)
+	assert.Exactly(t, "AQAB", jwk.E)
+	assert.Exactly(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", jwk.Thumbprint())
+
+	_, err = csjwt.NewJWK(csjwt.WithPassword([]byte("secret")))
+	assert.ErrorIsKind(t, errors.NotSupported, err)
+}
+
+func newProofTestKey(t *testing.T) csjwt.Key {
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return csjwt.WithECPrivateKey(pk)
+}
+
+func newBoundToken(t *testing.T, clientKey csjwt.Key) *csjwt.Token {
+	claims := jwtclaim.Map{"sub": "customer-1"}
+	assert.NoError(t, csjwt.BindToken(claims, clientKey))
+	tk := csjwt.NewToken(claims)
+	raw, err := tk.SignedString(csjwt.NewSigningMethodHS256(), csjwt.WithPassword([]byte("server-secret")))
+	assert.NoError(t, err)
+	tk.Raw = raw
+	return tk
+}
+
+func TestProofVerification_Verify(t *testing.T) {
+	clientKey := newProofTestKey(t)
+	es256 := csjwt.NewSigningMethodES256()
+	const url = "https://shop.example.com/checkout"
+	tk := newBoundToken(t, clientKey)
+
+	jkt, ok := csjwt.BoundThumbprint(tk.Claims)
+	assert.True(t, ok)
+	jwk, err := csjwt.NewJWK(clientKey)
+	assert.NoError(t, err)
+	assert.Exactly(t, jwk.Thumbprint(), jkt)
+
+	t.Run("valid", func(t *testing.T) {
+		proof, err := csjwt.NewProof(es256, clientKey, "POST", url, tk.Raw)
+		assert.NoError(t, err)
+		pv := csjwt.ProofVerification{}
+		assert.NoError(t, pv.Verify(proof, tk, "POST", url+"?step=2"))
+	})
+	t.Run("different client key", func(t *testing.T) {
+		proof, err := csjwt.NewProof(es256, newProofTestKey(t), "POST", url, tk.Raw)
+		assert.NoError(t, err)
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "POST", url))
+	})
+	t.Run("different token", func(t *testing.T) {
+		proof, err := csjwt.NewProof(es256, clientKey, "POST", url, []byte("other.token.value"))
+		assert.NoError(t, err)
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "POST", url))
+	})
+	t.Run("different method and URL", func(t *testing.T) {
+		proof, err := csjwt.NewProof(es256, clientKey, "GET", url, tk.Raw)
+		assert.NoError(t, err)
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "POST", url))
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "GET", url+"/other"))
+	})
+	t.Run("tampered signature", func(t *testing.T) {
+		proof, err := csjwt.NewProof(es256, clientKey, "POST", url, tk.Raw)
+		assert.NoError(t, err)
+		proof[len(proof)-3] ^= 'A' ^ 'B'
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "POST", url))
+	})
+	t.Run("expired", func(t *testing.T) {
+		defer func() { csjwt.TimeFunc = time.Now }()
+		csjwt.TimeFunc = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+		proof, err := csjwt.NewProof(es256, clientKey, "POST", url, tk.Raw)
+		assert.NoError(t, err)
+		csjwt.TimeFunc = time.Now
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(proof, tk, "POST", url))
+	})
+	t.Run("replay", func(t *testing.T) {
+		seen := map[string]bool{}
+		pv := csjwt.ProofVerification{
+			IsReplay: func(jti string, _ time.Duration) bool {
+				ok := seen[jti]
+				seen[jti] = true
+				return ok
+			},
+		}
+		proof, err := csjwt.NewProof(es256, clientKey, "POST", url, tk.Raw)
+		assert.NoError(t, err)
+		assert.NoError(t, pv.Verify(proof, tk, "POST", url))
+		assert.ErrorIsKind(t, errors.NotValid, pv.Verify(proof, tk, "POST", url))
+	})
+	t.Run("missing proof", func(t *testing.T) {
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{}.Verify(nil, tk, "POST", url))
+	})
+	t.Run("unbound token", func(t *testing.T) {
+		unbound := csjwt.NewToken(jwtclaim.Map{"sub": "customer-1"})
+		assert.NoError(t, csjwt.ProofVerification{}.Verify(nil, unbound, "POST", url))
+		assert.ErrorIsKind(t, errors.NotValid, csjwt.ProofVerification{RequireBinding: true}.Verify(nil, unbound, "POST", url))
+	})
+}
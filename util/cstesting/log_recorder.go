@@ -0,0 +1,218 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cstesting
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/corestoreio/log"
+)
+
+// Log levels of a LogEntry.
+const (
+	LogLevelDebug = "DEBUG"
+	LogLevelInfo  = "INFO"
+)
+
+// LogEntry represents a captured log message with its structured fields.
+// Nested fields and log.Marshaler get stored as map[string]interface{}.
+type LogEntry struct {
+	Level  string
+	Msg    string
+	Fields map[string]interface{}
+}
+
+// String returns the entry in a stable, human readable format.
+func (le LogEntry) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(le.Level)
+	buf.WriteByte(' ')
+	buf.WriteString(le.Msg)
+	keys := make([]string, 0, len(le.Fields))
+	for k := range le.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, " %s: %#v", k, le.Fields[k])
+	}
+	return buf.String()
+}
+
+// matches reports whether the entry has the level, contains the message and
+// equals all fields. An empty level matches all levels.
+func (le LogEntry) matches(level, msgContains string, fields map[string]interface{}) bool {
+	if (level != "" && le.Level != level) || !strings.Contains(le.Msg, msgContains) {
+		return false
+	}
+	for k, v := range fields {
+		if lv, ok := le.Fields[k]; !ok || !reflect.DeepEqual(lv, v) {
+			return false
+		}
+	}
+	return true
+}
+
+type logRecords struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// LogRecorder implements log.Logger and stores all entries as structured
+// records for assertions in tests, instead of comparing full log lines. Loggers
+// created with function With share the records with their parent. Safe for
+// concurrent use.
+//		rec := cstesting.NewLogRecorder()
+//		// ... run code logging with rec
+//		assert.True(t, rec.HasEntry(cstesting.LogLevelDebug, "Exec", log.String("table", "dml_people")), rec.String())
+type LogRecorder struct {
+	// EnableDebug if false, IsDebug returns false and Debug does not record.
+	EnableDebug bool
+	// EnableInfo if false, IsInfo returns false and Info does not record.
+	EnableInfo bool
+	fields     log.Fields
+	records    *logRecords
+}
+
+// NewLogRecorder creates a new LogRecorder with enabled debug and info level.
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{
+		EnableDebug: true,
+		EnableInfo:  true,
+		records:     new(logRecords),
+	}
+}
+
+func (r *LogRecorder) add(level, msg string, fields []log.Field) {
+	le := LogEntry{
+		Level:  level,
+		Msg:    msg,
+		Fields: fieldsToMap(append(r.fields[:len(r.fields):len(r.fields)], fields...)),
+	}
+	r.records.mu.Lock()
+	r.records.entries = append(r.records.entries, le)
+	r.records.mu.Unlock()
+}
+
+// Debug records a debug entry if EnableDebug has been set.
+func (r *LogRecorder) Debug(msg string, fields ...log.Field) {
+	if r.EnableDebug {
+		r.add(LogLevelDebug, msg, fields)
+	}
+}
+
+// Info records an info entry if EnableInfo has been set.
+func (r *LogRecorder) Info(msg string, fields ...log.Field) {
+	if r.EnableInfo {
+		r.add(LogLevelInfo, msg, fields)
+	}
+}
+
+// IsDebug returns the value of EnableDebug.
+func (r *LogRecorder) IsDebug() bool { return r.EnableDebug }
+
+// IsInfo returns the value of EnableInfo.
+func (r *LogRecorder) IsInfo() bool { return r.EnableInfo }
+
+// With returns a new logger which adds the fields to each entry. The new
+// logger shares the records with r.
+func (r *LogRecorder) With(fields ...log.Field) log.Logger {
+	r2 := *r
+	r2.fields = append(r.fields[:len(r.fields):len(r.fields)], fields...)
+	return &r2
+}
+
+// Entries returns a copy of all recorded entries in their logged order.
+func (r *LogRecorder) Entries() []LogEntry {
+	r.records.mu.Lock()
+	defer r.records.mu.Unlock()
+	return append([]LogEntry(nil), r.records.entries...)
+}
+
+// FindEntries returns all entries with the level, whose message contains
+// msgContains and whose fields equal fieldEquals. An empty level matches all
+// levels, an empty msgContains all messages.
+func (r *LogRecorder) FindEntries(level, msgContains string, fieldEquals ...log.Field) []LogEntry {
+	want := fieldsToMap(fieldEquals)
+	var found []LogEntry
+	for _, le := range r.Entries() {
+		if le.matches(level, msgContains, want) {
+			found = append(found, le)
+		}
+	}
+	return found
+}
+
+// HasEntry reports whether at least one entry matches. See FindEntries.
+func (r *LogRecorder) HasEntry(level, msgContains string, fieldEquals ...log.Field) bool {
+	return len(r.FindEntries(level, msgContains, fieldEquals...)) > 0
+}
+
+// Reset removes all recorded entries, also from the loggers created by With.
+func (r *LogRecorder) Reset() {
+	r.records.mu.Lock()
+	r.records.entries = r.records.entries[:0]
+	r.records.mu.Unlock()
+}
+
+// String returns all entries, one per line. Useful as a message for failed
+// assertions.
+func (r *LogRecorder) String() string {
+	var buf bytes.Buffer
+	for _, le := range r.Entries() {
+		buf.WriteString(le.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func fieldsToMap(fields log.Fields) map[string]interface{} {
+	fm := make(fieldMap, len(fields))
+	if err := fields.AddTo(fm); err != nil {
+		fm["log_recorder_error"] = err.Error()
+	}
+	return fm
+}
+
+// fieldMap implements log.KeyValuer.
+type fieldMap map[string]interface{}
+
+func (fm fieldMap) AddBool(k string, v bool)       { fm[k] = v }
+func (fm fieldMap) AddFloat64(k string, v float64) { fm[k] = v }
+func (fm fieldMap) AddInt(k string, v int)         { fm[k] = v }
+func (fm fieldMap) AddInt64(k string, v int64)     { fm[k] = v }
+func (fm fieldMap) AddUint64(k string, v uint64)   { fm[k] = v }
+func (fm fieldMap) AddString(k string, v string)   { fm[k] = v }
+
+func (fm fieldMap) AddObject(k string, v interface{}) error {
+	fm[k] = v
+	return nil
+}
+
+func (fm fieldMap) AddMarshaler(k string, m log.Marshaler) error {
+	return fm.Nest(k, m.MarshalLog)
+}
+
+func (fm fieldMap) Nest(k string, fn func(log.KeyValuer) error) error {
+	nested := make(fieldMap)
+	err := fn(nested)
+	fm[k] = map[string]interface{}(nested)
+	return err
+}
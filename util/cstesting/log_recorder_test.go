@@ -0,0 +1,53 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cstesting_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/log"
+	"github.com/corestoreio/pkg/util/assert"
+	"github.com/corestoreio/pkg/util/cstesting"
+)
+
+var _ log.Logger = (*cstesting.LogRecorder)(nil)
+
+func TestLogRecorder(t *testing.T) {
+	t.Parallel()
+
+	rec := cstesting.NewLogRecorder()
+	lg := rec.With(log.String("conn_pool_id", "UNIQ04"))
+	lg.Debug("Exec", log.String("table", "dml_people"), log.Int("length_args", 2))
+	lg.With(log.String("tx_id", "UNIQ12")).Info("Commit", log.Bool("ok", true))
+	rec.Debug("Close")
+
+	assert.Len(t, rec.Entries(), 3)
+	assert.True(t, rec.HasEntry(cstesting.LogLevelDebug, "Exec", log.String("conn_pool_id", "UNIQ04"), log.Int("length_args", 2)), rec.String())
+	assert.True(t, rec.HasEntry("", "Com", log.String("tx_id", "UNIQ12")), rec.String())
+	assert.False(t, rec.HasEntry(cstesting.LogLevelInfo, "Exec"), "wrong level")
+	assert.False(t, rec.HasEntry(cstesting.LogLevelDebug, "Exec", log.String("table", "dml_person")), "wrong field value")
+	assert.False(t, rec.HasEntry(cstesting.LogLevelDebug, "Close", log.String("conn_pool_id", "UNIQ04")), "missing field")
+	assert.Len(t, rec.FindEntries(cstesting.LogLevelDebug, ""), 2)
+	assert.Exactly(t, "INFO Commit conn_pool_id: \"UNIQ04\" ok: true tx_id: \"UNIQ12\"", rec.Entries()[1].String())
+
+	rec.EnableDebug = false
+	rec.Debug("Ignored")
+	assert.False(t, rec.IsDebug())
+	assert.Len(t, rec.Entries(), 3)
+
+	rec.Reset()
+	assert.Len(t, rec.Entries(), 0)
+	assert.Exactly(t, "", rec.String())
+}
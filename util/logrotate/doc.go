@@ -0,0 +1,32 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logrotate provides an io.WriteCloser which rotates log files.
+//
+// A Writer rotates its file when the file exceeds a maximum size, compresses
+// the rotated files with gzip and deletes rotated files exceeding a maximum age
+// or count. Reopen, triggered manually or via a SIGHUP signal, closes and
+// reopens the file, which allows an external tool to move the file. A Writer
+// can be passed to any logger accepting an io.Writer, for example to the
+// backends of package github.com/corestoreio/log.
+//		w, err := logrotate.NewWriter(logrotate.Config{
+//			Filename: "/var/log/shop/app.log",
+//			MaxSize:  100 << 20,
+//			MaxAge:   7 * 24 * time.Hour,
+//			Compress: true,
+//		})
+//		defer w.Close()
+//		stop := w.ReopenOnSignal()
+//		defer stop()
+package logrotate
@@ -0,0 +1,404 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logrotate
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/gzippool"
+)
+
+// DefaultMaxSize defines the maximum size of a log file in bytes, if
+// Config.MaxSize is zero.
+const DefaultMaxSize = 100 << 20
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+)
+
+// now gets used in tests to create deterministic file names.
+var now = time.Now
+
+// Config defines the policies of a Writer.
+type Config struct {
+	// Filename of the log file. The directory gets created if it does not
+	// exist. Rotated files are stored in the same directory, named
+	// <name>-<timestamp><ext>, for example app-2020-01-02T15-04-05.000.log.
+	Filename string
+	// FileMode of a newly created log file. Defaults to 0644.
+	FileMode os.FileMode
+	// MaxSize in bytes of the log file before it gets rotated. Defaults to
+	// DefaultMaxSize.
+	MaxSize int64
+	// MaxAge of a rotated file, based on the timestamp in its name. Older
+	// files get deleted. Zero keeps files regardless of their age.
+	MaxAge time.Duration
+	// MaxBackups defines the maximum number of rotated files to keep. Zero
+	// keeps all files.
+	MaxBackups int
+	// Compress compresses rotated files with gzip.
+	Compress bool
+}
+
+// Writer implements io.WriteCloser and writes to a log file, which gets
+// rotated according to the Config. A Writer is safe for concurrent use.
+type Writer struct {
+	cfg Config
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// closed gets set by Close. A nil file of an open Writer gets opened
+	// again by the next Write.
+	closed bool
+
+	// millWG tracks the compression and pruning of rotated files, which runs
+	// in the background.
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+	// stopSignals contains the stop functions of ReopenOnSignal, called by
+	// Close. Protected by mu.
+	stopSignals []func()
+}
+
+// NewWriter creates a new Writer and opens or creates the log file. Data gets
+// appended to an existing log file.
+func NewWriter(cfg Config) (*Writer, error) {
+	if cfg.Filename == "" {
+		return nil, errors.NotValid.Newf("[logrotate] Config.Filename cannot be empty")
+	}
+	if cfg.MaxSize < 0 || cfg.MaxAge < 0 || cfg.MaxBackups < 0 {
+		return nil, errors.NotValid.Newf("[logrotate] Config MaxSize %d, MaxAge %s and MaxBackups %d cannot be negative", cfg.MaxSize, cfg.MaxAge, cfg.MaxBackups)
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = DefaultMaxSize
+	}
+	if cfg.FileMode == 0 {
+		cfg.FileMode = 0644
+	}
+	w := &Writer{cfg: cfg}
+	if err := w.openFile(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return w, nil
+}
+
+// Write implements io.Writer. It rotates the log file before writing, if the
+// data exceeds the maximum size of the file. Data larger than the maximum size
+// gets written into a new file.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, errors.AlreadyClosed.Newf("[logrotate] Writer for file %q already closed", w.cfg.Filename)
+	}
+	if w.file == nil {
+		// a previous rotation could not open the log file
+		if err := w.openFile(); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.cfg.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, errors.WithStack(err)
+}
+
+// Rotate closes the current log file, renames it to a backup file and opens a
+// new log file.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.AlreadyClosed.Newf("[logrotate] Writer for file %q already closed", w.cfg.Filename)
+	}
+	if w.file == nil {
+		if err := w.openFile(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return w.rotate()
+}
+
+// Reopen closes and reopens the log file without rotating it. Reopen supports
+// external tools which move the log file away. The current file stays open,
+// if the log file cannot be opened.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.AlreadyClosed.Newf("[logrotate] Writer for file %q already closed", w.cfg.Filename)
+	}
+	old := w.file
+	if err := w.openFile(); err != nil {
+		return errors.WithStack(err)
+	}
+	if old != nil {
+		return errors.WithStack(old.Close())
+	}
+	return nil
+}
+
+// ReopenOnSignal calls Reopen each time the process receives one of the
+// signals. Without an argument it listens to SIGHUP. The returned function
+// stops listening, Close stops it too. Errors of Reopen are getting ignored
+// because the logger itself cannot report them.
+func (w *Writer) ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sig...)
+	go func() {
+		for {
+			select {
+			case <-c:
+				_ = w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+	w.mu.Lock()
+	w.stopSignals = append(w.stopSignals, stop)
+	w.mu.Unlock()
+	return stop
+}
+
+// Close stops all ReopenOnSignal listeners, closes the log file and waits
+// until all rotated files have been compressed and pruned.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	stopSignals := w.stopSignals
+	w.stopSignals = nil
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	for _, stop := range stopSignals {
+		stop()
+	}
+	w.millWG.Wait()
+	return errors.WithStack(err)
+}
+
+// openFile opens or creates the log file. The mutex must be locked.
+func (w *Writer) openFile() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Filename), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.OpenFile(w.cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.cfg.FileMode)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.WithStack(err)
+	}
+	w.file = f
+	w.size = fi.Size()
+	return nil
+}
+
+// rotate renames the log file and triggers the background compression and
+// pruning. If the rotation fails, the Writer continues to write into the
+// current log file. The mutex must be locked.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	w.file = nil
+	t := now()
+	backup := w.backupName(t)
+	for fileExists(backup) || fileExists(backup+compressSuffix) {
+		// another rotation within the same millisecond
+		t = t.Add(time.Millisecond)
+		backup = w.backupName(t)
+	}
+	if err := os.Rename(w.cfg.Filename, backup); err != nil {
+		return w.reopenAfter(err)
+	}
+	if err := w.openFile(); err != nil {
+		if rErr := os.Rename(backup, w.cfg.Filename); rErr != nil {
+			return errors.Wrapf(err, "[logrotate] Failed to rename %q back to %q: %s", backup, w.cfg.Filename, rErr)
+		}
+		return w.reopenAfter(err)
+	}
+	if w.cfg.Compress || w.cfg.MaxAge > 0 || w.cfg.MaxBackups > 0 {
+		w.millWG.Add(1)
+		go func() {
+			defer w.millWG.Done()
+			_ = w.mill(t)
+		}()
+	}
+	return nil
+}
+
+// reopenAfter opens the log file again after the rotation failed with error
+// err. The mutex must be locked.
+func (w *Writer) reopenAfter(err error) error {
+	if oErr := w.openFile(); oErr != nil {
+		return errors.Wrapf(err, "[logrotate] Failed to reopen %q: %s", w.cfg.Filename, oErr)
+	}
+	return errors.WithStack(err)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Lstat(name)
+	return err == nil
+}
+
+func (w *Writer) filePrefixExt() (prefix, ext string) {
+	base := filepath.Base(w.cfg.Filename)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (w *Writer) backupName(t time.Time) string {
+	prefix, ext := w.filePrefixExt()
+	return filepath.Join(filepath.Dir(w.cfg.Filename), prefix+t.Format(backupTimeFormat)+ext)
+}
+
+type backupFile struct {
+	name      string
+	timestamp time.Time
+}
+
+// backups returns the rotated files sorted by newest first.
+func (w *Writer) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.cfg.Filename)
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	prefix, ext := w.filePrefixExt()
+	bfs := make([]backupFile, 0, len(fis))
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts[len(prefix):], time.Local)
+		if err != nil {
+			continue // not a backup file of this Writer
+		}
+		bfs = append(bfs, backupFile{name: filepath.Join(dir, name), timestamp: t})
+	}
+	sort.Slice(bfs, func(i, j int) bool {
+		return bfs[i].timestamp.After(bfs[j].timestamp)
+	})
+	return bfs, nil
+}
+
+// mill compresses and prunes the rotated files. The MaxAge gets calculated
+// from the rotation time t. Only one mill runs at a time.
+func (w *Writer) mill(t time.Time) error {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	bfs, err := w.backups()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var cutoff time.Time
+	if w.cfg.MaxAge > 0 {
+		cutoff = t.Add(-w.cfg.MaxAge)
+	}
+	for i, bf := range bfs {
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || (!cutoff.IsZero() && bf.timestamp.Before(cutoff)) {
+			if err := os.Remove(bf.name); err != nil && !os.IsNotExist(err) {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+		if w.cfg.Compress && !strings.HasSuffix(bf.name, compressSuffix) {
+			if err := compressFile(bf.name); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile compresses the file into a gzip file and removes the source
+// file after the gzip file has been written and closed successfully.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeGzip(dst, src, filepath.Base(name)); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(name + compressSuffix)
+		return errors.WithStack(err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(name + compressSuffix)
+		return errors.WithStack(err)
+	}
+	if err = src.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Remove(name))
+}
+
+// writeGzip copies src as gzip stream into dst and closes the stream.
+func writeGzip(dst io.Writer, src io.Reader, name string) error {
+	zw := gzippool.GetWriter(dst)
+	zw.Name = name
+	if _, err := io.Copy(zw, src); err != nil {
+		return errors.WithStack(err) // the broken writer does not go back into the pool
+	}
+	if err := zw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	gzippool.PutWriter(zw) // closing again is a no-op
+	return nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2015-present, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logrotate
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/errors"
+	"github.com/corestoreio/pkg/util/assert"
+)
+
+func newTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "logrotate")
+	assert.NoError(t, err)
+	return dir, func() {
+		now = time.Now
+		assert.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+// setNow sets a deterministic clock which advances one minute per call.
+func setNow(start time.Time) {
+	now = func() time.Time {
+		t := start
+		start = start.Add(time.Minute)
+		return t
+	}
+}
+
+func dirFiles(t *testing.T, dir string) []string {
+	fis, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestNewWriter_Errors(t *testing.T) {
+	_, err := NewWriter(Config{})
+	assert.ErrorIsKind(t, errors.NotValid, err)
+	_, err = NewWriter(Config{Filename: "app.log", MaxBackups: -1})
+	assert.ErrorIsKind(t, errors.NotValid, err)
+}
+
+func TestWriter_SizeRotation(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	setNow(time.Date(2020, 1, 2, 15, 4, 5, 0, time.Local))
+
+	w, err := NewWriter(Config{Filename: filepath.Join(dir, "logs", "app.log"), MaxSize: 10})
+	assert.NoError(t, err)
+	for _, s := range []string{"12345", "67890", "abc", "a value larger than max size"} {
+		_, err = w.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("closed"))
+	assert.ErrorIsKind(t, errors.AlreadyClosed, err)
+
+	assert.Exactly(t, []string{
+		"app-2020-01-02T15-04-05.000.log",
+		"app-2020-01-02T15-05-05.000.log",
+		"app.log",
+	}, dirFiles(t, filepath.Join(dir, "logs")))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "logs", "app-2020-01-02T15-04-05.000.log"))
+	assert.NoError(t, err)
+	assert.Exactly(t, "1234567890", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "logs", "app.log"))
+	assert.NoError(t, err)
+	assert.Exactly(t, "a value larger than max size", string(data))
+}
+
+func TestWriter_CompressAndPrune(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	setNow(time.Date(2020, 1, 2, 15, 4, 5, 0, time.Local))
+
+	// a backup older than MaxAge and an unrelated file
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app-2019-12-01T00-00-00.000.log.gz"), nil, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app-config.log"), nil, 0644))
+
+	w, err := NewWriter(Config{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxAge:     24 * time.Hour,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = w.Write([]byte("line"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Rotate())
+	}
+	assert.NoError(t, w.Close())
+
+	assert.Exactly(t, []string{
+		"app-2020-01-02T15-05-05.000.log.gz",
+		"app-2020-01-02T15-06-05.000.log.gz",
+		"app-config.log",
+		"app.log",
+	}, dirFiles(t, dir))
+
+	f, err := os.Open(filepath.Join(dir, "app-2020-01-02T15-06-05.000.log.gz"))
+	assert.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Exactly(t, "line", string(data))
+	assert.Exactly(t, "app-2020-01-02T15-06-05.000.log", zr.Name)
+}
+
+func TestWriter_Reopen(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+
+	name := filepath.Join(dir, "app.log")
+	w, err := NewWriter(Config{Filename: name})
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, w.Close()) }()
+
+	_, err = w.Write([]byte("before"))
+	assert.NoError(t, err)
+	// an external tool moves the file
+	assert.NoError(t, os.Rename(name, name+".1"))
+	assert.NoError(t, w.Reopen())
+	_, err = w.Write([]byte("after"))
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile(name + ".1")
+	assert.NoError(t, err)
+	assert.Exactly(t, "before", string(data))
+	data, err = ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Exactly(t, "after", string(data))
+}
+
+func TestWriter_ReopenFailure(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+
+	name := filepath.Join(dir, "app.log")
+	w, err := NewWriter(Config{Filename: name})
+	assert.NoError(t, err)
+
+	// an external tool moves the file and a directory blocks the new file
+	assert.NoError(t, os.Rename(name, name+".1"))
+	assert.NoError(t, os.Mkdir(name, 0755))
+	assert.Error(t, w.Reopen())
+	_, err = w.Write([]byte("kept"))
+	assert.NoError(t, err, "Writer must keep the old file after a failed Reopen")
+
+	// the log file vanished after a failed rotation
+	assert.NoError(t, os.Remove(name))
+	assert.NoError(t, w.file.Close())
+	w.file = nil
+	_, err = w.Write([]byte("retry"))
+	assert.NoError(t, err, "Write must open the log file again")
+	assert.NoError(t, w.Close())
+
+	data, err := ioutil.ReadFile(name + ".1")
+	assert.NoError(t, err)
+	assert.Exactly(t, "kept", string(data))
+	data, err = ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Exactly(t, "retry", string(data))
+}
+
+func TestWriter_RotateFailure(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	setNow(time.Date(2020, 1, 2, 15, 4, 5, 0, time.Local))
+
+	name := filepath.Join(dir, "app.log")
+	w, err := NewWriter(Config{Filename: name, MaxSize: 5})
+	assert.NoError(t, err)
+
+	_, err = w.Write([]byte("12345"))
+	assert.NoError(t, err)
+	// the missing log file lets the rename fail
+	assert.NoError(t, os.Remove(name))
+	_, err = w.Write([]byte("67890"))
+	assert.Error(t, err)
+	_, err = w.Write([]byte("abc"))
+	assert.NoError(t, err, "Writer must write after a failed rotation")
+	assert.NoError(t, w.Close())
+
+	assert.Exactly(t, []string{"app.log"}, dirFiles(t, dir))
+	data, err := ioutil.ReadFile(name)
+	assert.NoError(t, err)
+	assert.Exactly(t, "abc", string(data))
+}
+
+func TestWriter_RotateSameMillisecond(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+	start := time.Date(2020, 1, 2, 15, 4, 5, 0, time.Local)
+	now = func() time.Time { return start }
+
+	name := filepath.Join(dir, "app.log")
+	w, err := NewWriter(Config{Filename: name, MaxSize: 5})
+	assert.NoError(t, err)
+	for _, s := range []string{"12345", "67890", "abcde"} {
+		_, err = w.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+
+	assert.Exactly(t, []string{"app-2020-01-02T15-04-05.000.log", "app-2020-01-02T15-04-05.001.log", "app.log"}, dirFiles(t, dir))
+	data, err := ioutil.ReadFile(w.backupName(start))
+	assert.NoError(t, err)
+	assert.Exactly(t, "12345", string(data))
+	data, err = ioutil.ReadFile(w.backupName(start.Add(time.Millisecond)))
+	assert.NoError(t, err)
+	assert.Exactly(t, "67890", string(data))
+}
+
+func TestWriter_CloseStopsReopenOnSignal(t *testing.T) {
+	dir, cleanup := newTestDir(t)
+	defer cleanup()
+
+	w, err := NewWriter(Config{Filename: filepath.Join(dir, "app.log")})
+	assert.NoError(t, err)
+	stop := w.ReopenOnSignal()
+	assert.Len(t, w.stopSignals, 1)
+	assert.NoError(t, w.Close())
+	assert.Nil(t, w.stopSignals)
+	stop() // already stopped by Close
+}